@@ -96,28 +96,16 @@ VALUES (?, ?, ?)
 }
 
 func (h *articleHandler) listArticles(w http.ResponseWriter, req *http.Request, db *sql.DB) {
-	rows, err := db.Query(`
+	articles, err := d1.QueryAll[model.Article](req.Context(), db, `
 SELECT id, title, body, created_at FROM articles
 ORDER BY created_at DESC;
    `)
 	if err != nil {
+		log.Println(err)
 		h.handleErr(w, http.StatusInternalServerError,
 			"failed to load article")
 		return
 	}
-
-	articles := []model.Article{}
-	for rows.Next() {
-		var a model.Article
-		err = rows.Scan(&a.ID, &a.Title, &a.Body, &a.CreatedAt)
-		if err != nil {
-			log.Println(err)
-			h.handleErr(w, http.StatusInternalServerError,
-				"failed to scan article")
-			return
-		}
-		articles = append(articles, a)
-	}
 	res := model.ListArticlesResponse{
 		Articles: articles,
 	}
@@ -4,7 +4,7 @@ type Article struct {
 	ID        uint64 `json:"id"`
 	Title     string `json:"title"`
 	Body      string `json:"body"`
-	CreatedAt uint64 `json:"createdAt"`
+	CreatedAt uint64 `json:"createdAt" d1:"created_at"`
 }
 
 type CreateArticleRequest struct {
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/syumai/workers"
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/durableobject"
+)
+
+// Counter is a Durable Object which counts up / down the value stored in the storage.
+type Counter struct {
+	storage *durableobject.Storage
+}
+
+func NewCounter(ctx context.Context, state *durableobject.State) durableobject.DurableObject {
+	return &Counter{storage: state.Storage()}
+}
+
+func (c *Counter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	v, err := c.storage.Get("value", nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	value, _ := v.(float64)
+
+	switch req.URL.Path {
+	case "/increment":
+		value++
+	case "/decrement":
+		value--
+	case "/":
+		// Just serve the current value.
+	default:
+		http.NotFound(w, req)
+		return
+	}
+
+	if err := c.storage.Put("value", value, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fmt.Fprint(w, value)
+}
+
+// RPCMethods implements durableobject.RPCHandler.
+func (c *Counter) RPCMethods() map[string]cloudflare.RPCMethod {
+	return map[string]cloudflare.RPCMethod{
+		"get": func(ctx context.Context, args []any) (any, error) {
+			v, err := c.storage.Get("value", nil)
+			if err != nil {
+				return nil, err
+			}
+			value, _ := v.(float64)
+			return value, nil
+		},
+	}
+}
+
+func main() {
+	durableobject.Register("Counter", NewCounter)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ns, err := cloudflare.NewDurableObjectNamespace(req.Context(), "COUNTER")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		stub, err := ns.Get(ns.IdFromName("A"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if req.URL.Path == "/rpc" {
+			value, err := stub.Call("get")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, "Durable object 'A' count (via RPC): %v", value)
+			return
+		}
+		res, err := stub.Fetch(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		count, err := io.ReadAll(res.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("Durable object 'A' count: " + string(count)))
+	})
+	workers.Serve(handler)
+}
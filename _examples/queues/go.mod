@@ -0,0 +1,7 @@
+module github.com/syumai/workers/_examples/queues
+
+go 1.18
+
+require github.com/syumai/workers v0.0.0
+
+replace github.com/syumai/workers => ../../
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/syumai/workers"
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+type task struct {
+	Text string `json:"text"`
+}
+
+func handler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	producer, err := queues.NewProducer(req.Context(), "QUEUE")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := producer.Send(&task{Text: string(b)}, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func consume(ctx context.Context, batch *queues.MessageBatch) error {
+	for _, msg := range batch.Messages {
+		fmt.Printf("received message %s from %s: %v\n", msg.ID, batch.Queue, msg.Body)
+	}
+	batch.AckAll()
+	return nil
+}
+
+func main() {
+	queues.ConsumeNonBlock(consume)
+	workers.Serve(http.HandlerFunc(handler))
+}
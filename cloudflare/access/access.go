@@ -0,0 +1,124 @@
+// Package access validates requests authenticated by Cloudflare Access.
+//
+// Access adds the signed JWT to requests as the `Cf-Access-Jwt-Assertion` header. Middleware validates the JWT
+// with the certs of the team, and puts the identity claims to the context of the request.
+//   - The JWT is verified by the jwt package, which uses the Web Crypto API of the runtime.
+//   - The certs are stored across requests by CertsStore, which is the Cache API by default (or KV),
+//     since the state of the Go program doesn't outlive the request.
+//   - https://developers.cloudflare.com/cloudflare-one/identity/authorization-cookie/validating-json/
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/jwt"
+)
+
+var (
+	// ErrNoToken is returned when the request doesn't have the JWT of Access.
+	ErrNoToken = errors.New("access: no token")
+	// ErrInvalidToken is returned when the JWT is malformed or its signature is invalid.
+	ErrInvalidToken = errors.New("access: invalid token")
+	// ErrInvalidClaims is returned when the claims of the JWT are not valid for the application (e.g. expired or of other audiences).
+	ErrInvalidClaims = errors.New("access: invalid claims")
+)
+
+// Identity represents the claims of the JWT of Access.
+type Identity struct {
+	// Email is the email address of the user. It's empty for service tokens.
+	Email string `json:"email"`
+	// Subject is the ID of the user. It's empty for service tokens.
+	Subject string `json:"sub"`
+	// CommonName is the Client ID of the service token. It's empty for users.
+	CommonName string `json:"common_name"`
+	// Type is the type of the token, e.g. `app`.
+	Type string `json:"type"`
+	// Country is the country of the user given by the identity provider.
+	Country       string          `json:"country"`
+	IdentityNonce string          `json:"identity_nonce"`
+	Issuer        string          `json:"iss"`
+	Audience      jwt.Audience    `json:"aud"`
+	ExpiresAt     jwt.NumericDate `json:"exp"`
+	IssuedAt      jwt.NumericDate `json:"iat"`
+	NotBefore     jwt.NumericDate `json:"nbf"`
+	// Claims are all claims of the JWT, including custom claims of the identity provider.
+	Claims map[string]any `json:"-"`
+}
+
+// IsServiceToken reports whether the request is authenticated by a service token instead of a user.
+func (id *Identity) IsServiceToken() bool {
+	return id.CommonName != "" && id.Email == ""
+}
+
+type identityKey struct{}
+
+// FromContext returns the identity validated by Middleware.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// NewContext returns the context with the identity, e.g. to test handlers.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// tokenFromRequest returns the JWT given by the header, or the `CF_Authorization` cookie.
+func tokenFromRequest(req *http.Request) (string, error) {
+	if token := req.Header.Get("Cf-Access-Jwt-Assertion"); token != "" {
+		return token, nil
+	}
+	if c, err := req.Cookie("CF_Authorization"); err == nil && c.Value != "" {
+		return c.Value, nil
+	}
+	return "", ErrNoToken
+}
+
+// identityOf decodes the identity of the claims of the token.
+func identityOf(t *jwt.Token) (*Identity, error) {
+	id := &Identity{}
+	if err := t.Decode(id); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := t.Decode(&id.Claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return id, nil
+}
+
+// validateOptions returns the options to validate the claims of the application. Tokens of Access always have `exp`.
+func validateOptions(issuer, aud string, leeway time.Duration, now func() time.Time) jwt.ValidateOptions {
+	return jwt.ValidateOptions{Issuer: issuer, Audience: aud, RequireExpiration: true, Leeway: leeway, Now: now}
+}
+
+// verifyError returns the error of the package for the error of the jwt package.
+//   - Unknown key IDs are reported as ErrInvalidToken, since they are not the keys of the team.
+func verifyError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, jwt.ErrInvalidClaims):
+		return fmt.Errorf("%w: %v", ErrInvalidClaims, err)
+	case errors.Is(err, jwt.ErrInvalidToken), errors.Is(err, jwt.ErrKeyNotFound):
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return err
+}
+
+// CertsStore stores the JSON of certs across requests, so that requests don't fetch the certs each time.
+type CertsStore = jwt.JWKSStore
+
+// CacheStore returns CertsStore which stores the certs by the Cache API (e.g. `cache.New()`).
+func CacheStore(c binding.Cache) CertsStore {
+	return jwt.CacheStore(c)
+}
+
+// KVStore returns CertsStore which stores the certs by the KV namespace.
+func KVStore(kv binding.KV) CertsStore {
+	return jwt.KVStore(kv)
+}
@@ -0,0 +1,120 @@
+package access
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+	"github.com/syumai/workers/cloudflare/jwt"
+)
+
+func encodeSegment(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func TestIdentityOf(t *testing.T) {
+	s := encodeSegment(`{"alg":"RS256","kid":"k1"}`) + "." +
+		encodeSegment(`{"email":"a@example.com","aud":"app","exp":1700000000,"custom":"x"}`) + "." +
+		encodeSegment("sig")
+	tok, err := jwt.ParseUnverified(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := identityOf(tok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Email != "a@example.com" || len(id.Audience) != 1 || id.Audience[0] != "app" || id.ExpiresAt.Unix() != 1700000000 {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+	if id.Claims["custom"] != "x" {
+		t.Errorf("custom claim = %v", id.Claims["custom"])
+	}
+	for _, s := range []string{"", "a.b", "!.e30.e30", encodeSegment("{}") + ".!.e30"} {
+		_, err := jwt.ParseUnverified(s)
+		if err := verifyError(err); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("ParseUnverified(%q): want ErrInvalidToken, got %v", s, err)
+		}
+	}
+}
+
+func TestValidateClaims(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	valid := func() *jwt.RegisteredClaims {
+		return &jwt.RegisteredClaims{
+			Issuer:    "https://team.cloudflareaccess.com",
+			Audience:  jwt.Audience{"other", "app"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			NotBefore: jwt.NewNumericDate(now.Add(-time.Minute)),
+		}
+	}
+	tests := map[string]struct {
+		modify func(c *jwt.RegisteredClaims)
+		leeway time.Duration
+		ok     bool
+	}{
+		"valid":          {modify: func(c *jwt.RegisteredClaims) {}, ok: true},
+		"issuer":         {modify: func(c *jwt.RegisteredClaims) { c.Issuer = "https://other.cloudflareaccess.com" }},
+		"audience":       {modify: func(c *jwt.RegisteredClaims) { c.Audience = jwt.Audience{"other"} }},
+		"expired":        {modify: func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(now) }},
+		"no expiration":  {modify: func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil }},
+		"not yet":        {modify: func(c *jwt.RegisteredClaims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Second)) }},
+		"expired/leeway": {modify: func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(now) }, leeway: time.Second, ok: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+			opts := validateOptions("https://team.cloudflareaccess.com", "app", tt.leeway, func() time.Time { return now })
+			err := verifyError(c.Validate(&opts))
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidClaims) {
+				t.Errorf("want ErrInvalidClaims, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := tokenFromRequest(req); !errors.Is(err, ErrNoToken) {
+		t.Errorf("want ErrNoToken, got %v", err)
+	}
+	req.AddCookie(&http.Cookie{Name: "CF_Authorization", Value: "cookie"})
+	if s, _ := tokenFromRequest(req); s != "cookie" {
+		t.Errorf("want cookie, got %q", s)
+	}
+	req.Header.Set("Cf-Access-Jwt-Assertion", "header")
+	if s, _ := tokenFromRequest(req); s != "header" {
+		t.Errorf("want header, got %q", s)
+	}
+}
+
+func TestCertsStores(t *testing.T) {
+	stores := map[string]CertsStore{
+		"cache": CacheStore(bindingtest.NewCache()),
+		"kv":    KVStore(bindingtest.NewKV()),
+	}
+	const url = "https://team.cloudflareaccess.com/cdn-cgi/access/certs"
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if b, err := store.Get(ctx, url); b != nil || err != nil {
+				t.Errorf("Get() before Put = %q, %v", b, err)
+			}
+			if err := store.Put(ctx, url, []byte(`{"keys":[]}`), time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			if b, err := store.Get(ctx, url); string(b) != `{"keys":[]}` || err != nil {
+				t.Errorf("Get() = %q, %v", b, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+//go:build js && wasm
+
+package access
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/jwt"
+)
+
+// DefaultCertsTTL is the duration for which the certs are cached.
+const DefaultCertsTTL = time.Hour
+
+// Options represents the options of Verifier and Middleware.
+type Options struct {
+	// TeamDomain is the domain of the team, e.g. `myteam.cloudflareaccess.com`.
+	TeamDomain string
+	// Audience is the Application Audience (AUD) tag of the application.
+	Audience string
+	// Client fetches the certs. The default is http.DefaultClient.
+	Client *http.Client
+	// Store stores the certs across requests. The default is the Cache API if it's available.
+	Store CertsStore
+	// CertsTTL is the duration for which the certs are cached. The default is DefaultCertsTTL.
+	CertsTTL time.Duration
+	// Leeway is the allowed clock skew to validate the validity period of tokens.
+	Leeway time.Duration
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Verifier verifies the JWTs of Access with the certs of the team.
+//   - The certs are `jwt.JWKS` of the certs endpoint, which is cached by the Verifier while it's used
+//     (e.g. for tokens of the same request), and is loaded from Store first when it's not cached.
+//   - The certs are fetched again when they are expired, or the token has an unknown key ID (at most once a minute).
+type Verifier struct {
+	keys     *jwt.JWKS
+	validate jwt.ValidateOptions
+}
+
+// NewVerifier returns Verifier of the application.
+//   - This panics if TeamDomain or Audience is not set.
+func NewVerifier(opts *Options) *Verifier {
+	if opts == nil || opts.TeamDomain == "" || opts.Audience == "" {
+		panic("access: TeamDomain and Audience of Options must be set")
+	}
+	domain := strings.TrimSuffix(strings.TrimPrefix(opts.TeamDomain, "https://"), "/")
+	issuer := "https://" + domain
+	ttl := opts.CertsTTL
+	if ttl <= 0 {
+		ttl = DefaultCertsTTL
+	}
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Verifier{
+		keys: jwt.NewJWKS(issuer+"/cdn-cgi/access/certs", &jwt.JWKSOptions{
+			Client: opts.Client,
+			Store:  opts.Store,
+			TTL:    ttl,
+			Now:    now,
+		}),
+		validate: validateOptions(issuer, opts.Audience, opts.Leeway, now),
+	}
+}
+
+// Verify verifies the JWT, and returns the identity of the claims.
+//   - if the JWT is invalid, returns the error which wraps ErrInvalidToken or ErrInvalidClaims.
+func (v *Verifier) Verify(ctx context.Context, s string) (*Identity, error) {
+	t, err := jwt.Verify(ctx, s, v.keys, &jwt.VerifyOptions{
+		ValidateOptions: v.validate,
+		Algorithms:      []jwt.Algorithm{jwt.RS256},
+	})
+	if err != nil {
+		return nil, verifyError(err)
+	}
+	return identityOf(t)
+}
+
+// VerifyRequest verifies the JWT of the request given by the `Cf-Access-Jwt-Assertion` header or the `CF_Authorization` cookie.
+//   - if the request doesn't have the JWT, returns ErrNoToken.
+func (v *Verifier) VerifyRequest(req *http.Request) (*Identity, error) {
+	s, err := tokenFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return v.Verify(req.Context(), s)
+}
+
+// Middleware returns the middleware which rejects requests without valid JWTs of Access with 403 Forbidden.
+//   - The identity of the request can be obtained by FromContext in the handler.
+//   - This panics if TeamDomain or Audience is not set.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	v := NewVerifier(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			id, err := v.VerifyRequest(req)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), id)))
+		})
+	}
+}
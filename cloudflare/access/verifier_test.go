@@ -0,0 +1,154 @@
+//go:build js && wasm
+
+package access
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type testTeam struct {
+	key     *webcrypto.Key
+	certs   string
+	fetches int
+}
+
+func newTestTeam(t *testing.T) *testTeam {
+	t.Helper()
+	pair, err := webcrypto.GenerateKeyPair(&webcrypto.RSASSAPKCS1v15{Hash: webcrypto.SHA256, ModulusLength: 2048}, true, webcrypto.UsageSign, webcrypto.UsageVerify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := webcrypto.ExportKey(webcrypto.FormatJWK, pair.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jwk map[string]any
+	if err := json.Unmarshal(pub, &jwk); err != nil {
+		t.Fatal(err)
+	}
+	jwk["kid"] = "k1"
+	b, _ := json.Marshal(map[string]any{"keys": []any{jwk}})
+	return &testTeam{key: pair.PrivateKey, certs: string(b)}
+}
+
+func (tt *testTeam) client(t *testing.T) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "https://team.cloudflareaccess.com/cdn-cgi/access/certs" {
+			t.Errorf("unexpected URL: %s", req.URL)
+		}
+		tt.fetches++
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(tt.certs))}, nil
+	})}
+}
+
+func (tt *testTeam) sign(t *testing.T, kid string, claims string) string {
+	t.Helper()
+	input := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"alg":"RS256","kid":%q}`, kid))) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(claims))
+	sig, err := webcrypto.Sign(&webcrypto.RSASSAPKCS1v15{Hash: webcrypto.SHA256}, tt.key, []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestVerifier(t *testing.T) {
+	team := newTestTeam(t)
+	now := time.Unix(1700000000, 0)
+	store := KVStore(bindingtest.NewKV())
+	opts := &Options{
+		TeamDomain: "team.cloudflareaccess.com",
+		Audience:   "app",
+		Client:     team.client(t),
+		Store:      store,
+		Now:        func() time.Time { return now },
+	}
+	v := NewVerifier(opts)
+	ctx := context.Background()
+	claims := `{"iss":"https://team.cloudflareaccess.com","aud":["app"],"email":"a@example.com","exp":1700000060}`
+	id, err := v.Verify(ctx, team.sign(t, "k1", claims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.Email != "a@example.com" {
+		t.Errorf("Email = %q", id.Email)
+	}
+	if _, err := v.Verify(ctx, team.sign(t, "k1", claims)); err != nil {
+		t.Fatal(err)
+	}
+	if team.fetches != 1 {
+		t.Errorf("want 1 fetch, got %d", team.fetches)
+	}
+
+	tampered := team.sign(t, "k1", claims)
+	tampered = tampered[:len(tampered)-4] + "AAAA"
+	if _, err := v.Verify(ctx, tampered); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("tampered: want ErrInvalidToken, got %v", err)
+	}
+	if _, err := v.Verify(ctx, team.sign(t, "k1", strings.Replace(claims, `"app"`, `"other"`, 1))); !errors.Is(err, ErrInvalidClaims) {
+		t.Errorf("audience: want ErrInvalidClaims, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := v.Verify(ctx, team.sign(t, "unknown", claims)); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("unknown key: want ErrInvalidToken, got %v", err)
+		}
+	}
+	if team.fetches != 1 {
+		t.Errorf("unknown keys must not refetch the certs within minRefreshInterval, got %d fetches", team.fetches)
+	}
+
+	// another request uses the stored certs.
+	other := NewVerifier(opts)
+	if _, err := other.Verify(ctx, team.sign(t, "k1", claims)); err != nil {
+		t.Fatal(err)
+	}
+	if team.fetches != 1 {
+		t.Errorf("want the stored certs to be used, got %d fetches", team.fetches)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	team := newTestTeam(t)
+	h := Middleware(&Options{
+		TeamDomain: "https://team.cloudflareaccess.com/",
+		Audience:   "app",
+		Client:     team.client(t),
+		Now:        func() time.Time { return time.Unix(1700000000, 0) },
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, _ := FromContext(req.Context())
+		fmt.Fprint(w, id.Email)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("without token: want 403, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Cf-Access-Jwt-Assertion", team.sign(t, "k1", `{"iss":"https://team.cloudflareaccess.com","aud":"app","email":"a@example.com","exp":1700000060}`))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != "a@example.com" {
+		t.Errorf("want 200 a@example.com, got %d %q", rec.Code, rec.Body.String())
+	}
+}
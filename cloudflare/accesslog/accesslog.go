@@ -0,0 +1,88 @@
+// Package accesslog provides the middleware which logs requests served by the handler.
+package accesslog
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/timing"
+)
+
+// Entry represents a log entry of a request.
+type Entry struct {
+	Method string
+	URL    string
+	// Status is the status code of the response.
+	Status int
+	// Size is the number of bytes of the response body written by the handler.
+	Size int64
+	// Duration is the time taken to serve the request, measured by `timing.Stopwatch`.
+	// Since the clock of Workers advances only on I/O, time spent on computation is not included.
+	Duration time.Duration
+	// Ray is the value of the cf-ray header of the request.
+	Ray string
+}
+
+// Options represents the options of Middleware.
+type Options struct {
+	// Log is called with the entry after the request has been served.
+	// if Log is nil, the entry is written by the standard logger.
+	Log func(req *http.Request, e *Entry)
+}
+
+// Middleware returns the middleware which logs requests served by the handler.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	logFunc := defaultLog
+	if opts != nil && opts.Log != nil {
+		logFunc = opts.Log
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			sw := timing.Start()
+			rec := &recorder{ResponseWriter: w}
+			defer func() {
+				status := rec.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				logFunc(req, &Entry{
+					Method:   req.Method,
+					URL:      req.URL.String(),
+					Status:   status,
+					Size:     rec.size,
+					Duration: sw.Elapsed(),
+					Ray:      req.Header.Get("Cf-Ray"),
+				})
+			}()
+			next.ServeHTTP(rec, req)
+		})
+	}
+}
+
+func defaultLog(req *http.Request, e *Entry) {
+	log.Printf("%s %s %d %d %s ray=%s", e.Method, e.URL, e.Status, e.Size, e.Duration, e.Ray)
+}
+
+// recorder is http.ResponseWriter which records the status code and the size of the response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.size += int64(n)
+	return n, err
+}
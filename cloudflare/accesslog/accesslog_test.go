@@ -0,0 +1,39 @@
+package accesslog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var got *Entry
+	h := Middleware(&Options{
+		Log: func(req *http.Request, e *Entry) { got = e },
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/items", nil)
+	req.Header.Set("Cf-Ray", "abc-NRT")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got == nil {
+		t.Fatal("entry was not logged")
+	}
+	want := Entry{Method: http.MethodPost, URL: "https://example.com/items", Status: http.StatusCreated, Size: 5, Ray: "abc-NRT"}
+	got.Duration = 0
+	if *got != want {
+		t.Errorf("want %+v, got %+v", want, *got)
+	}
+}
+
+func TestMiddlewareDefaultStatus(t *testing.T) {
+	var got *Entry
+	h := Middleware(&Options{
+		Log: func(req *http.Request, e *Entry) { got = e },
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got.Status != http.StatusOK {
+		t.Errorf("want status 200, got %d", got.Status)
+	}
+}
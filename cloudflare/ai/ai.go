@@ -0,0 +1,156 @@
+// Package ai provides the binding of Workers AI.
+//   - https://developers.cloudflare.com/workers-ai/
+package ai
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// AI represents the binding of Workers AI.
+//   - https://developers.cloudflare.com/workers-ai/configuration/bindings/
+type AI struct {
+	instance js.Value
+}
+
+// NewAI returns AI for given variable name.
+//   - variable name must be defined in wrangler.toml as ai's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewAI(ctx context.Context, varName string) (*AI, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &AI{instance: inst}, nil
+}
+
+// RunOptions represents the options of AI runs.
+type RunOptions struct {
+	// ExtraHeaders are headers added to the request to the model.
+	ExtraHeaders map[string]string
+	// Gateway routes the request through AI Gateway.
+	Gateway *GatewayOptions
+}
+
+// GatewayOptions represents the options of AI Gateway.
+//   - https://developers.cloudflare.com/ai-gateway/integrations/worker-binding-methods/
+type GatewayOptions struct {
+	// ID is the ID of the gateway.
+	ID string
+	// SkipCache disables the cache of the gateway for the request.
+	SkipCache bool
+	// CacheTTL overrides the cache TTL of the gateway for the request. The value is rounded down to seconds.
+	CacheTTL time.Duration
+	// CacheKey overrides the cache key of the gateway for the request.
+	CacheKey string
+	// Metadata is custom metadata logged with the request. values must be string, numbers or bool.
+	Metadata map[string]any
+}
+
+func (opts *GatewayOptions) toJS() (js.Value, error) {
+	obj := jsutil.NewObject()
+	obj.Set("id", opts.ID)
+	if opts.SkipCache {
+		obj.Set("skipCache", true)
+	}
+	if opts.CacheTTL > 0 {
+		obj.Set("cacheTtl", int(opts.CacheTTL/time.Second))
+	}
+	if opts.CacheKey != "" {
+		obj.Set("cacheKey", opts.CacheKey)
+	}
+	if len(opts.Metadata) > 0 {
+		metadata, err := jsutil.ToJSValue(opts.Metadata)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("ai: error converting gateway metadata: %w", err)
+		}
+		obj.Set("metadata", metadata)
+	}
+	return obj, nil
+}
+
+func (opts *RunOptions) toJS() (js.Value, error) {
+	if opts == nil {
+		return js.Undefined(), nil
+	}
+	obj := jsutil.NewObject()
+	if len(opts.ExtraHeaders) > 0 {
+		headers := jsutil.NewObject()
+		for k, v := range opts.ExtraHeaders {
+			headers.Set(k, v)
+		}
+		obj.Set("extraHeaders", headers)
+	}
+	if opts.Gateway != nil {
+		gateway, err := opts.Gateway.toJS()
+		if err != nil {
+			return js.Value{}, err
+		}
+		obj.Set("gateway", gateway)
+	}
+	return obj, nil
+}
+
+// Run runs the model with the input, and returns the output converted to Go value.
+//   - input is converted to JavaScript value by `jsutil.ToJSValue`. field names of structs can be changed by `json` tag.
+//   - The output is converted to Go value by `jsutil.ToGoValue`.
+//   - Use typed methods (e.g. TextGeneration) for the common tasks.
+//   - https://developers.cloudflare.com/workers-ai/configuration/bindings/#methods
+func (a *AI) Run(model string, input any, opts *RunOptions) (any, error) {
+	inputObj, err := jsutil.ToJSValue(input)
+	if err != nil {
+		return nil, fmt.Errorf("ai: error converting input: %w", err)
+	}
+	v, err := a.run(model, inputObj, opts)
+	if err != nil {
+		return nil, err
+	}
+	return jsutil.ToGoValue(v), nil
+}
+
+// run runs the model with the JavaScript value of the input.
+func (a *AI) run(model string, input js.Value, opts *RunOptions) (js.Value, error) {
+	optsObj, err := opts.toJS()
+	if err != nil {
+		return js.Value{}, err
+	}
+	promise, err := jsutil.TryCall(a.instance, "run", model, input, optsObj)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.AwaitPromise(promise)
+}
+
+// runJSON runs the model with the input converted by `jsutil.ToJSValue`, and decodes the output into out.
+func (a *AI) runJSON(model string, input any, opts *RunOptions, out any) error {
+	inputObj, err := jsutil.ToJSValue(input)
+	if err != nil {
+		return fmt.Errorf("ai: error converting input: %w", err)
+	}
+	v, err := a.run(model, inputObj, opts)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(v, out)
+}
+
+// decodeJSON decodes JavaScript value into out by `jsutil.Decode`.
+func decodeJSON(v js.Value, out any) error {
+	if err := jsutil.Decode(v, out); err != nil {
+		return fmt.Errorf("ai: error decoding output: %w", err)
+	}
+	return nil
+}
+
+// toJSByteArray converts []byte to JavaScript side's Array of numbers, which is the format of binary inputs of models.
+func toJSByteArray(b []byte) js.Value {
+	ua := jsutil.NewUint8Array(len(b))
+	js.CopyBytesToJS(ua, b)
+	return jsutil.ArrayClass().Call("from", ua)
+}
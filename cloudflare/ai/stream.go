@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Stream represents the streamed output of text generation in the Server-Sent Events format.
+//   - Stream can be read as raw SSE bytes by Read, or iterated as parsed events by Next. Don't mix them.
+type Stream struct {
+	reader  io.Reader
+	scanner *bufio.Scanner
+	event   *StreamEvent
+	err     error
+	done    bool
+}
+
+// StreamEvent represents an event of the streamed output.
+type StreamEvent struct {
+	// Response is the generated text of the event (usually a token).
+	Response string `json:"response"`
+	// Usage is the token usage, which is included in the last event by some models.
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+func newStream(r io.Reader) *Stream {
+	return &Stream{reader: r}
+}
+
+// TextGenerationStream generates text by the model with `stream: true`, and returns the streamed output.
+func (a *AI) TextGenerationStream(model string, req *TextGenerationRequest, opts *RunOptions) (*Stream, error) {
+	input, err := jsutil.ToJSValue(req)
+	if err != nil {
+		return nil, fmt.Errorf("ai: error converting input: %w", err)
+	}
+	if input.IsNull() {
+		input = jsutil.NewObject()
+	}
+	input.Set("stream", true)
+	v, err := a.run(model, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newStream(jsutil.ConvertReadableStreamToReader(v)), nil
+}
+
+// Read reads raw SSE bytes of the stream.
+func (s *Stream) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// Next advances the stream to the next event, and reports whether the event exists.
+//   - Next returns false at the end of the stream (`data: [DONE]`) or on error. Check Err after the iteration.
+func (s *Stream) Next() bool {
+	if s.done || s.err != nil {
+		return false
+	}
+	if s.scanner == nil {
+		s.scanner = bufio.NewScanner(s.reader)
+	}
+	for s.scanner.Scan() {
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		data := bytes.TrimPrefix(line, []byte("data:"))
+		if len(data) == len(line) {
+			// skip empty lines and fields other than data.
+			continue
+		}
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			s.done = true
+			return false
+		}
+		var event StreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			s.err = fmt.Errorf("ai: error decoding stream event: %w", err)
+			return false
+		}
+		s.event = &event
+		return true
+	}
+	s.err = s.scanner.Err()
+	return false
+}
+
+// Event returns the current event of the stream.
+func (s *Stream) Event() *StreamEvent {
+	return s.event
+}
+
+// Err returns the error occurred in Next.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// ServeStream writes the stream to w as `text/event-stream` response.
+//   - The stream is sent to the client as it is generated.
+func ServeStream(w http.ResponseWriter, s *Stream) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, err := io.Copy(w, s)
+	return err
+}
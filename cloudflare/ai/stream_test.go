@@ -0,0 +1,43 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStream_Next(t *testing.T) {
+	input := "data: {\"response\":\"Hello\"}\n\n" +
+		": comment\n" +
+		"data: {\"response\":\", world\"}\n\n" +
+		"data: {\"response\":\"\",\"usage\":{\"prompt_tokens\":1,\"completion_tokens\":2,\"total_tokens\":3}}\n\n" +
+		"data: [DONE]\n\n" +
+		"data: {\"response\":\"ignored\"}\n\n"
+	s := newStream(strings.NewReader(input))
+	var got []string
+	var usage *Usage
+	for s.Next() {
+		got = append(got, s.Event().Response)
+		if s.Event().Usage != nil {
+			usage = s.Event().Usage
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if strings.Join(got, "") != "Hello, world" || len(got) != 3 {
+		t.Errorf("events = %q, want [Hello , world ]", got)
+	}
+	if usage == nil || usage.TotalTokens != 3 {
+		t.Errorf("usage = %+v, want total_tokens 3", usage)
+	}
+}
+
+func TestStream_Next_invalid(t *testing.T) {
+	s := newStream(strings.NewReader("data: {invalid\n\n"))
+	if s.Next() {
+		t.Fatal("Next() = true, want false")
+	}
+	if s.Err() == nil {
+		t.Error("Err() = nil, want error")
+	}
+}
@@ -0,0 +1,148 @@
+package ai
+
+import (
+	"fmt"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Message represents a message of chat-style text generation.
+type Message struct {
+	// Role is one of "system", "user", "assistant" and "tool".
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// TextGenerationRequest represents the input of text generation models.
+//   - Either Prompt or Messages must be set.
+//   - https://developers.cloudflare.com/workers-ai/models/#text-generation
+type TextGenerationRequest struct {
+	Prompt            string     `json:"prompt,omitempty"`
+	Messages          []*Message `json:"messages,omitempty"`
+	MaxTokens         int        `json:"max_tokens,omitempty"`
+	Temperature       float64    `json:"temperature,omitempty"`
+	TopP              float64    `json:"top_p,omitempty"`
+	TopK              int        `json:"top_k,omitempty"`
+	Seed              int        `json:"seed,omitempty"`
+	RepetitionPenalty float64    `json:"repetition_penalty,omitempty"`
+	FrequencyPenalty  float64    `json:"frequency_penalty,omitempty"`
+	PresencePenalty   float64    `json:"presence_penalty,omitempty"`
+	// Raw disables applying the chat template of the model to the prompt.
+	Raw bool `json:"raw,omitempty"`
+}
+
+// TextGenerationResponse represents the output of text generation models.
+type TextGenerationResponse struct {
+	Response string `json:"response"`
+	Usage    *Usage `json:"usage,omitempty"`
+}
+
+// Usage represents the token usage of text generation.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// TextGeneration generates text by the model (e.g. `@cf/meta/llama-3.1-8b-instruct`).
+func (a *AI) TextGeneration(model string, req *TextGenerationRequest, opts *RunOptions) (*TextGenerationResponse, error) {
+	var res TextGenerationResponse
+	if err := a.runJSON(model, req, opts, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// EmbeddingsResponse represents the output of text embedding models.
+type EmbeddingsResponse struct {
+	// Shape is the shape of Data, e.g. [number of texts, dimensions].
+	Shape []int
+	// Data are the embeddings of the texts in the order of the input.
+	Data [][]float32
+}
+
+// Embeddings computes embeddings of the texts by the model (e.g. `@cf/baai/bge-base-en-v1.5`).
+//   - https://developers.cloudflare.com/workers-ai/models/#text-embeddings
+func (a *AI) Embeddings(model string, texts []string, opts *RunOptions) (*EmbeddingsResponse, error) {
+	textArr := jsutil.ArrayClass().New(len(texts))
+	for i, t := range texts {
+		textArr.SetIndex(i, t)
+	}
+	input := jsutil.NewObject()
+	input.Set("text", textArr)
+	v, err := a.run(model, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	shapeArr, dataArr := v.Get("shape"), v.Get("data")
+	if dataArr.IsUndefined() {
+		return nil, fmt.Errorf("ai: unexpected output of embeddings")
+	}
+	res := &EmbeddingsResponse{
+		Data: make([][]float32, dataArr.Length()),
+	}
+	if !shapeArr.IsUndefined() {
+		res.Shape = make([]int, shapeArr.Length())
+		for i := range res.Shape {
+			res.Shape[i] = shapeArr.Index(i).Int()
+		}
+	}
+	for i := range res.Data {
+		res.Data[i] = jsutil.ToFloat32Slice(dataArr.Index(i))
+	}
+	return res, nil
+}
+
+// ImageClassification represents a label of image classification.
+type ImageClassification struct {
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// ClassifyImage classifies the image by the model (e.g. `@cf/microsoft/resnet-50`).
+//   - https://developers.cloudflare.com/workers-ai/models/#image-classification
+func (a *AI) ClassifyImage(model string, image []byte, opts *RunOptions) ([]*ImageClassification, error) {
+	input := jsutil.NewObject()
+	input.Set("image", toJSByteArray(image))
+	v, err := a.run(model, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res []*ImageClassification
+	if err := decodeJSON(v, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SpeechRecognitionResponse represents the output of automatic speech recognition models.
+type SpeechRecognitionResponse struct {
+	Text      string  `json:"text"`
+	WordCount int     `json:"word_count,omitempty"`
+	Words     []*Word `json:"words,omitempty"`
+	// VTT is the transcription in WebVTT format, if the model supports it.
+	VTT string `json:"vtt,omitempty"`
+}
+
+// Word represents a recognized word with its time range in seconds.
+type Word struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// SpeechToText transcribes the audio by the model (e.g. `@cf/openai/whisper`).
+//   - https://developers.cloudflare.com/workers-ai/models/#automatic-speech-recognition
+func (a *AI) SpeechToText(model string, audio []byte, opts *RunOptions) (*SpeechRecognitionResponse, error) {
+	input := jsutil.NewObject()
+	input.Set("audio", toJSByteArray(audio))
+	v, err := a.run(model, input, opts)
+	if err != nil {
+		return nil, err
+	}
+	var res SpeechRecognitionResponse
+	if err := decodeJSON(v, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
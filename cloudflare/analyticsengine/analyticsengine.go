@@ -0,0 +1,131 @@
+// Package analyticsengine provides the binding of Workers Analytics Engine datasets.
+//   - https://developers.cloudflare.com/analytics/analytics-engine/
+package analyticsengine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Limits of data points.
+//   - https://developers.cloudflare.com/analytics/analytics-engine/limits/
+const (
+	// MaxBlobs is the maximum number of blobs in a data point.
+	MaxBlobs = 20
+	// MaxDoubles is the maximum number of doubles in a data point.
+	MaxDoubles = 20
+	// MaxIndexes is the maximum number of indexes in a data point.
+	MaxIndexes = 1
+	// MaxBlobsSize is the maximum total size of blobs in a data point in bytes.
+	MaxBlobsSize = 16 * 1024
+	// MaxIndexSize is the maximum size of an index in bytes.
+	MaxIndexSize = 96
+)
+
+var (
+	// ErrTooManyBlobs is returned when the data point has more than MaxBlobs blobs.
+	ErrTooManyBlobs = errors.New("analyticsengine: too many blobs")
+	// ErrTooManyDoubles is returned when the data point has more than MaxDoubles doubles.
+	ErrTooManyDoubles = errors.New("analyticsengine: too many doubles")
+	// ErrTooManyIndexes is returned when the data point has more than MaxIndexes indexes.
+	ErrTooManyIndexes = errors.New("analyticsengine: too many indexes")
+	// ErrBlobsTooLarge is returned when the total size of blobs is larger than MaxBlobsSize.
+	ErrBlobsTooLarge = errors.New("analyticsengine: blobs are too large")
+	// ErrIndexTooLarge is returned when the index is larger than MaxIndexSize.
+	ErrIndexTooLarge = errors.New("analyticsengine: index is too large")
+)
+
+// Dataset represents the binding of an Analytics Engine dataset.
+//   - https://developers.cloudflare.com/analytics/analytics-engine/get-started/
+type Dataset struct {
+	instance js.Value
+}
+
+// NewDataset returns Dataset for given variable name.
+//   - variable name must be defined in wrangler.toml as analytics_engine_datasets's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewDataset(ctx context.Context, varName string) (*Dataset, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Dataset{instance: inst}, nil
+}
+
+// DataPoint represents a data point written to the dataset.
+//   - Blobs are stored as blob1...blob20, and Doubles are stored as double1...double20 in the order.
+//   - Indexes is used as the sampling key. Only one index is supported.
+type DataPoint struct {
+	Blobs   []string
+	Doubles []float64
+	Indexes []string
+}
+
+// Validate validates the data point against the limits of Analytics Engine.
+func (p *DataPoint) Validate() error {
+	if len(p.Blobs) > MaxBlobs {
+		return fmt.Errorf("%w: %d blobs given, the limit is %d", ErrTooManyBlobs, len(p.Blobs), MaxBlobs)
+	}
+	if len(p.Doubles) > MaxDoubles {
+		return fmt.Errorf("%w: %d doubles given, the limit is %d", ErrTooManyDoubles, len(p.Doubles), MaxDoubles)
+	}
+	if len(p.Indexes) > MaxIndexes {
+		return fmt.Errorf("%w: %d indexes given, the limit is %d", ErrTooManyIndexes, len(p.Indexes), MaxIndexes)
+	}
+	var size int
+	for _, b := range p.Blobs {
+		size += len(b)
+	}
+	if size > MaxBlobsSize {
+		return fmt.Errorf("%w: %d bytes given, the limit is %d bytes", ErrBlobsTooLarge, size, MaxBlobsSize)
+	}
+	for i, index := range p.Indexes {
+		if len(index) > MaxIndexSize {
+			return fmt.Errorf("%w: indexes[%d] is %d bytes, the limit is %d bytes", ErrIndexTooLarge, i, len(index), MaxIndexSize)
+		}
+	}
+	return nil
+}
+
+func (p *DataPoint) toJS() js.Value {
+	obj := jsutil.NewObject()
+	if len(p.Blobs) > 0 {
+		blobs := jsutil.ArrayClass().New(len(p.Blobs))
+		for i, b := range p.Blobs {
+			blobs.SetIndex(i, b)
+		}
+		obj.Set("blobs", blobs)
+	}
+	if len(p.Doubles) > 0 {
+		doubles := jsutil.ArrayClass().New(len(p.Doubles))
+		for i, d := range p.Doubles {
+			doubles.SetIndex(i, d)
+		}
+		obj.Set("doubles", doubles)
+	}
+	if len(p.Indexes) > 0 {
+		indexes := jsutil.ArrayClass().New(len(p.Indexes))
+		for i, index := range p.Indexes {
+			indexes.SetIndex(i, index)
+		}
+		obj.Set("indexes", indexes)
+	}
+	return obj
+}
+
+// WriteDataPoint writes the data point to the dataset.
+//   - if the data point exceeds the limits, returns error without writing.
+//   - The data point is written asynchronously, and this method doesn't wait for the completion.
+func (d *Dataset) WriteDataPoint(p *DataPoint) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	_, err := jsutil.TryCall(d.instance, "writeDataPoint", p.toJS())
+	return err
+}
@@ -0,0 +1,65 @@
+package analyticsengine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDataPoint_Validate(t *testing.T) {
+	tests := map[string]struct {
+		point   *DataPoint
+		wantErr error
+	}{
+		"empty": {
+			point: &DataPoint{},
+		},
+		"within limits": {
+			point: &DataPoint{
+				Blobs:   make([]string, MaxBlobs),
+				Doubles: make([]float64, MaxDoubles),
+				Indexes: []string{strings.Repeat("a", MaxIndexSize)},
+			},
+		},
+		"too many blobs": {
+			point:   &DataPoint{Blobs: make([]string, MaxBlobs+1)},
+			wantErr: ErrTooManyBlobs,
+		},
+		"too many doubles": {
+			point:   &DataPoint{Doubles: make([]float64, MaxDoubles+1)},
+			wantErr: ErrTooManyDoubles,
+		},
+		"too many indexes": {
+			point:   &DataPoint{Indexes: []string{"a", "b"}},
+			wantErr: ErrTooManyIndexes,
+		},
+		"blobs too large": {
+			point: &DataPoint{Blobs: []string{
+				strings.Repeat("a", MaxBlobsSize/2),
+				strings.Repeat("b", MaxBlobsSize/2+1),
+			}},
+			wantErr: ErrBlobsTooLarge,
+		},
+		"index too large": {
+			point:   &DataPoint{Indexes: []string{strings.Repeat("a", MaxIndexSize+1)}},
+			wantErr: ErrIndexTooLarge,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.point.Validate()
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package assets provides the binding of static assets deployed with the worker.
+//   - https://developers.cloudflare.com/workers/static-assets/binding/
+package assets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Assets represents the binding of static assets.
+type Assets struct {
+	instance js.Value
+}
+
+// NewAssets returns Assets for given variable name.
+//   - variable name must be defined in wrangler.toml as assets's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewAssets(ctx context.Context, varName string) (*Assets, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Assets{instance: inst}, nil
+}
+
+// Fetch returns the asset for the request. The path of the request URL is used to look up the asset.
+//   - if the asset is not found, the response follows not_found_handling of wrangler.toml (404 by default).
+func (a *Assets) Fetch(req *http.Request) (*http.Response, error) {
+	promise, err := jsutil.TryCall(a.instance, "fetch", jshttp.ToJSRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	jsRes, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return jshttp.ToResponse(jsRes)
+}
+
+// HTTPClient returns *http.Client which fetches assets.
+func (a *Assets) HTTPClient(redirect fetch.RedirectMode) *http.Client {
+	return fetch.NewClient(fetch.WithBinding(a.instance)).HTTPClient(redirect)
+}
+
+// HandlerOptions represents the options of Handler.
+type HandlerOptions struct {
+	// SPA serves /index.html for navigation requests (which accept text/html) to missing assets,
+	// so client side routers of single page applications can handle them.
+	SPA bool
+	// NotFound handles requests to missing assets. if NotFound is nil, the 404 response of the binding is served.
+	NotFound http.Handler
+}
+
+// Handler returns http.Handler which serves the static assets of the binding.
+//   - Handler can be registered as the fallback of routers, e.g. `mux.Handle("/", assets.Handler("ASSETS", nil))`,
+//     so requests unmatched by other routes are served from the assets.
+func Handler(varName string, opts *HandlerOptions) http.Handler {
+	if opts == nil {
+		opts = &HandlerOptions{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		a, err := NewAssets(req.Context(), varName)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		res, err := a.Fetch(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if res.StatusCode == http.StatusNotFound && opts.SPA && isNavigation(req) {
+			res.Body.Close()
+			indexReq := req.Clone(req.Context())
+			indexReq.URL.Path = "/index.html"
+			indexReq.URL.RawPath = ""
+			res, err = a.Fetch(indexReq)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+		}
+		if res.StatusCode == http.StatusNotFound && opts.NotFound != nil {
+			res.Body.Close()
+			opts.NotFound.ServeHTTP(w, req)
+			return
+		}
+		writeResponse(w, res)
+	})
+}
+
+// isNavigation reports whether the request is a navigation of browsers.
+func isNavigation(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
+
+func writeResponse(w http.ResponseWriter, res *http.Response) {
+	defer res.Body.Close()
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, res.Body)
+}
@@ -0,0 +1,163 @@
+// Package background provides the way to run tasks after the response has been sent.
+//   - Tasks are run under `cloudflare.WaitUntil`, so the runtime doesn't terminate the worker while they are running.
+//   - Panics of tasks are recovered, and reported with errors of tasks by Runner's OnError.
+//   - TinyGo can't recover from panics on WebAssembly, so panics of tasks abort the worker on TinyGo.
+//   - Use this package instead of starting goroutines directly in handlers, which can be terminated at any time
+//     after the response has been sent, and abort the worker on panics.
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/errorreport"
+)
+
+// ErrTimeout is the error of tasks which didn't finish within Runner's Timeout.
+var ErrTimeout = errors.New("background: task timed out")
+
+// TaskError represents the failure of a task.
+type TaskError struct {
+	// Err is the error returned by the task, ErrTimeout, or the error describing the panic.
+	Err error
+	// Panic is the value recovered from the panic of the task. Panic is nil if the task didn't panic.
+	Panic any
+	// Stack is the stack trace of the goroutine which panicked. Stack is nil if the task didn't panic.
+	Stack []byte
+	// Duration is the time from the start of the task until the failure.
+	Duration time.Duration
+}
+
+func (e *TaskError) Error() string {
+	return "background: task failed: " + e.Err.Error()
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Runner runs tasks with its options. The zero value is ready to use.
+type Runner struct {
+	// Timeout is the maximum duration of each task. if Timeout is 0, tasks have no timeout.
+	//   - The context given to the task is canceled after Timeout. The task is reported as ErrTimeout
+	//     and stops extending the lifetime of the worker, even if it doesn't return.
+	Timeout time.Duration
+	// OnError is called with the context of the task when the task fails.
+	// if OnError is nil, failures are written by the standard logger.
+	OnError func(ctx context.Context, err *TaskError)
+
+	wg sync.WaitGroup
+}
+
+// DefaultRunner is the Runner used by Go.
+var DefaultRunner = &Runner{}
+
+// Go runs fn after the response by DefaultRunner.
+func Go(ctx context.Context, fn func(ctx context.Context) error) {
+	DefaultRunner.Go(ctx, fn)
+}
+
+// Go runs fn in the background under `cloudflare.WaitUntil`.
+//   - fn receives the context which holds values of ctx (e.g. the runtime context for bindings),
+//     but isn't canceled with ctx.
+func (r *Runner) Go(ctx context.Context, fn func(ctx context.Context) error) {
+	taskCtx := detach(ctx)
+	r.wg.Add(1)
+	waitUntil(ctx, func() {
+		defer r.wg.Done()
+		if err := r.run(taskCtx, fn); err != nil {
+			r.handleError(taskCtx, err)
+		}
+	})
+}
+
+// Wait waits for all tasks started by the Runner. This is useful in tests and outside of Workers.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// run runs fn with the timeout, and returns the failure of fn.
+func (r *Runner) run(ctx context.Context, fn func(ctx context.Context) error) *TaskError {
+	start := time.Now()
+	var cancel context.CancelFunc = func() {}
+	if r.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+	}
+	defer cancel()
+	done := make(chan *TaskError, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- &TaskError{Err: fmt.Errorf("panic: %v", p), Panic: p, Stack: debug.Stack(), Duration: time.Since(start)}
+			}
+		}()
+		if err := fn(ctx); err != nil {
+			done <- &TaskError{Err: err, Duration: time.Since(start)}
+			return
+		}
+		done <- nil
+	}()
+	if r.Timeout == 0 {
+		return <-done
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// prefer the result of the task if it returned at the same time.
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+		return &TaskError{Err: ErrTimeout, Duration: time.Since(start)}
+	}
+}
+
+func (r *Runner) handleError(ctx context.Context, err *TaskError) {
+	if r.OnError != nil {
+		r.OnError(ctx, err)
+		return
+	}
+	if err.Stack != nil {
+		log.Printf("%v\n%s", err, err.Stack)
+		return
+	}
+	log.Printf("%v", err)
+}
+
+// detachedContext is the context which has values of the parent, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (ctx detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (ctx detachedContext) Done() <-chan struct{}       { return nil }
+func (ctx detachedContext) Err() error                  { return nil }
+func (ctx detachedContext) Value(key any) any           { return ctx.parent.Value(key) }
+func (ctx detachedContext) String() string              { return fmt.Sprintf("%v.Detached", ctx.parent) }
+
+// ReportTo returns OnError which reports failures of tasks by the reporter of errorreport.
+//   - Errors of reports are written by the standard logger.
+func ReportTo(reporter errorreport.Reporter) func(ctx context.Context, err *TaskError) {
+	return func(ctx context.Context, err *TaskError) {
+		e := &errorreport.Event{
+			Message: err.Error(),
+			Panic:   err.Panic,
+			Stack:   err.Stack,
+			Time:    time.Now(),
+		}
+		if reportErr := reporter.Report(ctx, e); reportErr != nil {
+			log.Printf("background: error reporting failure of task: %v", reportErr)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build js && wasm
+
+package background
+
+import (
+	"context"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// waitUntil runs the task in a goroutine by `cloudflare.WaitUntil`.
+//   - if ctx doesn't hold the runtime context (e.g. in tests), the task is run in a goroutine without extending the lifetime.
+func waitUntil(ctx context.Context, task func()) {
+	if _, ok := runtimecontext.Extract(ctx); !ok {
+		go task()
+		return
+	}
+	cloudflare.WaitUntil(ctx, task)
+}
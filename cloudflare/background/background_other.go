@@ -0,0 +1,12 @@
+//go:build !(js && wasm)
+
+package background
+
+import (
+	"context"
+)
+
+// waitUntil runs the task in a goroutine, since there is no `waitUntil` outside of Workers.
+func waitUntil(ctx context.Context, task func()) {
+	go task()
+}
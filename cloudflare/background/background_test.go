@@ -0,0 +1,62 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestRunner(t *testing.T) {
+	var failures []*TaskError
+	r := &Runner{
+		Timeout: 50 * time.Millisecond,
+		OnError: func(ctx context.Context, err *TaskError) {
+			failures = append(failures, err)
+		},
+	}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+	cancel()
+
+	var got any
+	r.Go(ctx, func(ctx context.Context) error {
+		if ctx.Err() != nil {
+			t.Error("task context must not be canceled with the parent")
+		}
+		got = ctx.Value(ctxKey{})
+		return nil
+	})
+	r.Wait()
+	if got != "value" {
+		t.Errorf("want value of the parent context, got %v", got)
+	}
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+
+	errTask := errors.New("task error")
+	r.Go(ctx, func(ctx context.Context) error { return errTask })
+	r.Wait()
+	r.Go(ctx, func(ctx context.Context) error { panic("boom") })
+	r.Wait()
+	r.Go(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	r.Wait()
+	if len(failures) != 3 {
+		t.Fatalf("want 3 failures, got %d", len(failures))
+	}
+	if !errors.Is(failures[0], errTask) {
+		t.Errorf("want task error, got %v", failures[0])
+	}
+	if failures[1].Panic != "boom" || len(failures[1].Stack) == 0 {
+		t.Errorf("want recovered panic, got %+v", failures[1])
+	}
+	if !errors.Is(failures[2], ErrTimeout) {
+		t.Errorf("want ErrTimeout, got %v", failures[2])
+	}
+}
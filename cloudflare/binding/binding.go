@@ -0,0 +1,82 @@
+// Package binding provides interfaces of bindings, which are implemented by the types of this module on js/wasm.
+//   - Application code which depends on these interfaces (instead of concrete types) can be built and unit-tested
+//     on any platform, since this package and the option types of bindings don't depend on syscall/js.
+//   - On js/wasm, pass the real bindings (e.g. *cloudflare.KVNamespace) to the application code.
+//     Durable Object namespaces must be adapted by DurableObjectNamespaceOf.
+package binding
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/cache"
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+// KV is the interface of KV namespaces, implemented by *cloudflare.KVNamespace.
+type KV interface {
+	GetString(key string, opts *cloudflare.KVNamespaceGetOptions) (string, error)
+	GetReader(key string, opts *cloudflare.KVNamespaceGetOptions) (io.Reader, error)
+	List(opts *cloudflare.KVNamespaceListOptions) (*cloudflare.KVNamespaceListResult, error)
+	PutString(key string, value string, opts *cloudflare.KVNamespacePutOptions) error
+	PutReader(key string, value io.Reader, opts *cloudflare.KVNamespacePutOptions) error
+	Delete(key string) error
+}
+
+// R2Bucket is the interface of R2 buckets, implemented by *cloudflare.R2Bucket.
+type R2Bucket interface {
+	Head(key string) (*cloudflare.R2Object, error)
+	Get(key string) (*cloudflare.R2Object, error)
+	Put(key string, value io.ReadCloser, opts *cloudflare.R2PutOptions) (*cloudflare.R2Object, error)
+	Delete(key string) error
+	List() (*cloudflare.R2Objects, error)
+}
+
+// D1 is the interface of D1 databases, implemented by *sql.DB opened by `d1.OpenConnector`.
+// Any *sql.DB (e.g. SQLite) can be used in tests.
+type D1 interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+var _ D1 = (*sql.DB)(nil)
+
+// Queue is the interface of queue producers, implemented by *queues.Producer.
+type Queue interface {
+	Send(body any, opts *queues.SendOptions) error
+	SendBatch(messages []*queues.MessageSendRequest, opts *queues.SendBatchOptions) error
+}
+
+// Cache is the interface of the Cache API, implemented by *cache.Cache.
+type Cache interface {
+	Put(req *http.Request, res *http.Response) error
+	Match(req *http.Request, opts *cache.MatchOptions) (*http.Response, error)
+	Delete(req *http.Request, opts *cache.DeleteOptions) error
+}
+
+// DurableObjectNamespace is the interface of Durable Object namespaces.
+// Use DurableObjectNamespaceOf to adapt *cloudflare.DurableObjectNamespace.
+type DurableObjectNamespace interface {
+	IdFromName(name string) DurableObjectID
+	IdFromString(id string) (DurableObjectID, error)
+	NewUniqueId() DurableObjectID
+	Get(id DurableObjectID) (DurableObjectStub, error)
+}
+
+// DurableObjectID is the interface of Durable Object IDs, implemented by *cloudflare.DurableObjectId.
+type DurableObjectID interface {
+	String() string
+	// Name returns the name of the ID created by IdFromName.
+	Name() (string, bool)
+}
+
+// DurableObjectStub is the interface of Durable Object stubs, implemented by *cloudflare.DurableObjectStub.
+type DurableObjectStub interface {
+	Fetch(req *http.Request) (*http.Response, error)
+}
@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package binding
+
+import (
+	"fmt"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/cache"
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+var (
+	_ KV                = (*cloudflare.KVNamespace)(nil)
+	_ R2Bucket          = (*cloudflare.R2Bucket)(nil)
+	_ Queue             = (*queues.Producer)(nil)
+	_ Cache             = (*cache.Cache)(nil)
+	_ DurableObjectID   = (*cloudflare.DurableObjectId)(nil)
+	_ DurableObjectStub = (*cloudflare.DurableObjectStub)(nil)
+)
+
+// DurableObjectNamespaceOf adapts *cloudflare.DurableObjectNamespace to DurableObjectNamespace.
+func DurableObjectNamespaceOf(ns *cloudflare.DurableObjectNamespace) DurableObjectNamespace {
+	return &durableObjectNamespace{ns: ns}
+}
+
+type durableObjectNamespace struct {
+	ns *cloudflare.DurableObjectNamespace
+}
+
+func (n *durableObjectNamespace) IdFromName(name string) DurableObjectID {
+	return n.ns.IdFromName(name)
+}
+
+func (n *durableObjectNamespace) IdFromString(id string) (DurableObjectID, error) {
+	doID, err := n.ns.IdFromString(id)
+	if err != nil {
+		return nil, err
+	}
+	return doID, nil
+}
+
+func (n *durableObjectNamespace) NewUniqueId() DurableObjectID {
+	return n.ns.NewUniqueId()
+}
+
+// Get returns the stub of the ID. id must be created by the namespace.
+func (n *durableObjectNamespace) Get(id DurableObjectID) (DurableObjectStub, error) {
+	doID, ok := id.(*cloudflare.DurableObjectId)
+	if !ok {
+		return nil, fmt.Errorf("binding: ID of type %T is not created by the Durable Object namespace", id)
+	}
+	stub, err := n.ns.Get(doID)
+	if err != nil {
+		return nil, err
+	}
+	return stub, nil
+}
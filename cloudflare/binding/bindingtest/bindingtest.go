@@ -0,0 +1,29 @@
+// Package bindingtest provides in-memory fakes of bindings for tests.
+//   - The fakes implement the interfaces of the binding package, and don't depend on syscall/js,
+//     so handler logic can be tested with plain `go test`.
+//   - The fakes are safe for concurrent use.
+//   - Each fake has the Now field to control the clock used for expiration and delays.
+package bindingtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// clock returns the current time of now, or time.Now if now is nil.
+func clock(now func() time.Time) time.Time {
+	if now != nil {
+		return now()
+	}
+	return time.Now()
+}
+
+// randomHex returns random hex string of n bytes.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
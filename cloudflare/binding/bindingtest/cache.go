@@ -0,0 +1,77 @@
+package bindingtest
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/cache"
+)
+
+// Cache is an in-memory fake of the Cache API.
+//   - Cache keys are the URLs of requests. Vary headers and expiration by Cache-Control are not emulated.
+//   - Put rejects the responses which the Cache API doesn't store. See `cache.CheckCacheable`.
+type Cache struct {
+	mu        sync.Mutex
+	responses map[string][]byte
+}
+
+var _ binding.Cache = (*Cache)(nil)
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{responses: map[string][]byte{}}
+}
+
+// Put stores the response with the request as the key. The body of the response is consumed.
+func (c *Cache) Put(req *http.Request, res *http.Response) error {
+	if err := cache.CheckCacheable(req, res); err != nil {
+		return err
+	}
+	dump, err := httputil.DumpResponse(res, true)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[req.URL.String()] = dump
+	return nil
+}
+
+// Match returns the stored response for the request.
+//   - if the response is not found, returns cache.ErrCacheNotFound.
+func (c *Cache) Match(req *http.Request, opts *cache.MatchOptions) (*http.Response, error) {
+	if !isGet(req) && (opts == nil || !opts.IgnoreMethod) {
+		return nil, cache.ErrCacheNotFound
+	}
+	c.mu.Lock()
+	dump, ok := c.responses[req.URL.String()]
+	c.mu.Unlock()
+	if !ok {
+		return nil, cache.ErrCacheNotFound
+	}
+	return http.ReadResponse(bufio.NewReader(bytes.NewReader(dump)), req)
+}
+
+// Delete deletes the stored response for the request.
+//   - if the response is not found, returns cache.ErrCacheNotFound.
+func (c *Cache) Delete(req *http.Request, opts *cache.DeleteOptions) error {
+	if !isGet(req) && (opts == nil || !opts.IgnoreMethod) {
+		return cache.ErrCacheNotFound
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := req.URL.String()
+	if _, ok := c.responses[key]; !ok {
+		return cache.ErrCacheNotFound
+	}
+	delete(c.responses, key)
+	return nil
+}
+
+func isGet(req *http.Request) bool {
+	return req.Method == "" || req.Method == http.MethodGet
+}
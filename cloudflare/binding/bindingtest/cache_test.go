@@ -0,0 +1,68 @@
+package bindingtest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/cache"
+)
+
+func newResponse(body string, header http.Header) *http.Response {
+	rec := httptest.NewRecorder()
+	for k, v := range header {
+		rec.Header()[k] = v
+	}
+	rec.WriteString(body)
+	return rec.Result()
+}
+
+func TestCache(t *testing.T) {
+	c := NewCache()
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/a", nil)
+
+	if _, err := c.Match(req, nil); !errors.Is(err, cache.ErrCacheNotFound) {
+		t.Fatalf("Match before Put: err = %v, want ErrCacheNotFound", err)
+	}
+	if err := c.Put(req, newResponse("hello", http.Header{"Cache-Control": {"max-age=60"}})); err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Match(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(res.Body); string(b) != "hello" || res.Header.Get("Cache-Control") != "max-age=60" {
+		t.Errorf("Match = %q %v", b, res.Header)
+	}
+
+	head := httptest.NewRequest(http.MethodHead, "https://example.com/a", nil)
+	if _, err := c.Match(head, nil); !errors.Is(err, cache.ErrCacheNotFound) {
+		t.Errorf("Match of HEAD: err = %v, want ErrCacheNotFound", err)
+	}
+	if _, err := c.Match(head, &cache.MatchOptions{IgnoreMethod: true}); err != nil {
+		t.Errorf("Match of HEAD with IgnoreMethod: %v", err)
+	}
+
+	if err := c.Delete(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Delete(req, nil); !errors.Is(err, cache.ErrCacheNotFound) {
+		t.Errorf("second Delete: err = %v, want ErrCacheNotFound", err)
+	}
+}
+
+func TestCache_NotCacheable(t *testing.T) {
+	c := NewCache()
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/a", nil)
+	res := newResponse("", http.Header{"Cache-Control": {"no-store"}})
+	if err := c.Put(req, res); !errors.Is(err, cache.ErrNotCacheable) {
+		t.Errorf("Put: err = %v, want ErrNotCacheable", err)
+	}
+	post := httptest.NewRequest(http.MethodPost, "https://example.com/a", strings.NewReader(""))
+	if err := c.Put(post, newResponse("", nil)); !errors.Is(err, cache.ErrMethodNotAllowed) {
+		t.Errorf("Put of POST: err = %v, want ErrMethodNotAllowed", err)
+	}
+}
@@ -0,0 +1,169 @@
+package bindingtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/d1"
+)
+
+// OpenD1 opens an in-memory database by the SQLite driver registered as driverName, which can be used in place of D1.
+//   - This module has no dependencies, so the driver must be imported by the test (e.g. modernc.org/sqlite, which doesn't require cgo).
+//   - dsn is the data source name of the in-memory database (e.g. `:memory:`).
+//   - Errors of the driver are converted by d1.WrapError, so errors.Is reports the same Err* variables of d1
+//     (e.g. d1.ErrUniqueConstraint) as D1 does.
+//   - The database uses only one connection, since each connection of in-memory SQLite databases has its own database.
+//   - Sessions and bookmarks of read replication (d1.OpenSession) are not emulated, since there is only one database.
+//
+// Example with modernc.org/sqlite:
+//
+//	import _ "modernc.org/sqlite"
+//
+//	db, err := bindingtest.OpenD1("sqlite", ":memory:")
+func OpenD1(driverName, dsn string) (binding.D1, error) {
+	// the driver is looked up by sql.Open, which returns the error of unknown drivers.
+	base, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := base.Driver()
+	base.Close()
+	db := sql.OpenDB(&d1Connector{driver: drv, dsn: dsn})
+	db.SetMaxOpenConns(1)
+	// the database is lost when the connection is closed.
+	db.SetConnMaxLifetime(0)
+	db.SetMaxIdleConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// d1Connector opens connections by the driver, whose errors are converted by d1.WrapError.
+type d1Connector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *d1Connector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &d1Conn{conn}, nil
+}
+
+func (c *d1Connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// d1Conn is driver.Conn which converts errors of statements by d1.WrapError.
+//   - Context methods of the driver are used if they are implemented.
+//     Otherwise driver.ErrSkip is returned, so database/sql falls back to Prepare.
+type d1Conn struct {
+	driver.Conn
+}
+
+var (
+	_ driver.ExecerContext      = (*d1Conn)(nil)
+	_ driver.QueryerContext     = (*d1Conn)(nil)
+	_ driver.ConnPrepareContext = (*d1Conn)(nil)
+	_ driver.ConnBeginTx        = (*d1Conn)(nil)
+)
+
+func (c *d1Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	res, err := execer.ExecContext(ctx, query, args)
+	return res, wrapD1Error(err)
+}
+
+func (c *d1Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	return rows, wrapD1Error(err)
+}
+
+func (c *d1Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, wrapD1Error(err)
+	}
+	return &d1Stmt{stmt}, nil
+}
+
+func (c *d1Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err := beginner.BeginTx(ctx, opts)
+		return tx, wrapD1Error(err)
+	}
+	tx, err := c.Conn.Begin()
+	return tx, wrapD1Error(err)
+}
+
+// d1Stmt is driver.Stmt which converts errors by d1.WrapError.
+type d1Stmt struct {
+	driver.Stmt
+}
+
+func (s *d1Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err := execer.ExecContext(ctx, args)
+		return res, wrapD1Error(err)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Stmt.Exec(values)
+	return res, wrapD1Error(err)
+}
+
+func (s *d1Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err := queryer.QueryContext(ctx, args)
+		return rows, wrapD1Error(err)
+	}
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.Stmt.Query(values)
+	return rows, wrapD1Error(err)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("bindingtest: named parameter %s is not supported by the driver", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// wrapD1Error converts errors other than driver.ErrSkip by d1.WrapError.
+func wrapD1Error(err error) error {
+	if err == driver.ErrSkip {
+		return err
+	}
+	return d1.WrapError(err)
+}
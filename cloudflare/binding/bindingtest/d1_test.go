@@ -0,0 +1,111 @@
+package bindingtest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/d1"
+)
+
+// fakeSQLite is the driver which emulates a table of unique emails, and returns errors in the format of SQLite.
+type fakeSQLite struct {
+	mu     sync.Mutex
+	emails map[string]bool
+}
+
+func (d *fakeSQLite) Open(string) (driver.Conn, error) { return &fakeSQLiteConn{d}, nil }
+
+type fakeSQLiteConn struct{ d *fakeSQLite }
+
+func (c *fakeSQLiteConn) Prepare(query string) (driver.Stmt, error) {
+	switch query {
+	case "INSERT INTO users (email) VALUES (?)", "SELECT COUNT(*) FROM users":
+		return &fakeSQLiteStmt{c.d, query}, nil
+	}
+	return nil, errors.New("no such table: " + query)
+}
+func (c *fakeSQLiteConn) Close() error              { return nil }
+func (c *fakeSQLiteConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type fakeSQLiteStmt struct {
+	d     *fakeSQLite
+	query string
+}
+
+func (s *fakeSQLiteStmt) Close() error  { return nil }
+func (s *fakeSQLiteStmt) NumInput() int { return -1 }
+
+func (s *fakeSQLiteStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	email := args[0].(string)
+	if s.d.emails[email] {
+		return nil, errors.New("constraint failed: UNIQUE constraint failed: users.email (2067)")
+	}
+	s.d.emails[email] = true
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeSQLiteStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return &fakeSQLiteRows{count: int64(len(s.d.emails))}, nil
+}
+
+type fakeSQLiteRows struct {
+	count int64
+	done  bool
+}
+
+func (r *fakeSQLiteRows) Columns() []string { return []string{"COUNT(*)"} }
+func (r *fakeSQLiteRows) Close() error      { return nil }
+
+func (r *fakeSQLiteRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.count
+	r.done = true
+	return nil
+}
+
+func init() {
+	sql.Register("bindingtest-fakesqlite", &fakeSQLite{emails: map[string]bool{}})
+}
+
+func TestOpenD1(t *testing.T) {
+	ctx := context.Background()
+	db, err := OpenD1("bindingtest-fakesqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO users (email) VALUES (?)", "a@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.ExecContext(ctx, "INSERT INTO users (email) VALUES (?)", "a@example.com")
+	if !errors.Is(err, d1.ErrUniqueConstraint) || !errors.Is(err, d1.ErrConstraint) {
+		t.Errorf("want d1.ErrUniqueConstraint, got %v", err)
+	}
+	_, err = db.QueryContext(ctx, "SELECT * FROM articles")
+	if !errors.Is(err, d1.ErrNoSuchTable) {
+		t.Errorf("want d1.ErrNoSuchTable, got %v", err)
+	}
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("want 1 user, got %d", count)
+	}
+}
+
+func TestOpenD1_unknownDriver(t *testing.T) {
+	if _, err := OpenD1("no-such-driver", ":memory:"); err == nil {
+		t.Error("want error for the unknown driver")
+	}
+}
@@ -0,0 +1,97 @@
+package bindingtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+// DurableObjectNamespace is an in-memory fake of Durable Object namespaces.
+//   - Each ID has its own handler created by New on the first Get, so the handler can hold the state of the object.
+//   - Requests to an object are served one at a time.
+//   - IDs created by IdFromName are deterministic, and IDs are 64 hex digits like real IDs.
+type DurableObjectNamespace struct {
+	// New creates the handler of the Durable Object for the ID.
+	New func(id binding.DurableObjectID) http.Handler
+
+	mu      sync.Mutex
+	objects map[string]*durableObjectStub
+}
+
+var _ binding.DurableObjectNamespace = (*DurableObjectNamespace)(nil)
+
+// NewDurableObjectNamespace returns DurableObjectNamespace which creates handlers of objects by newHandler.
+func NewDurableObjectNamespace(newHandler func(id binding.DurableObjectID) http.Handler) *DurableObjectNamespace {
+	return &DurableObjectNamespace{New: newHandler}
+}
+
+// DurableObjectID is an ID of DurableObjectNamespace.
+type DurableObjectID struct {
+	id   string
+	name string
+}
+
+var _ binding.DurableObjectID = (*DurableObjectID)(nil)
+
+func (id *DurableObjectID) String() string {
+	return id.id
+}
+
+func (id *DurableObjectID) Name() (string, bool) {
+	return id.name, id.name != ""
+}
+
+func (ns *DurableObjectNamespace) IdFromName(name string) binding.DurableObjectID {
+	sum := sha256.Sum256([]byte(name))
+	return &DurableObjectID{id: hex.EncodeToString(sum[:]), name: name}
+}
+
+// IdFromString parses the ID.
+//   - if the ID is not 64 hex digits, returns error.
+func (ns *DurableObjectNamespace) IdFromString(id string) (binding.DurableObjectID, error) {
+	if b, err := hex.DecodeString(id); err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("bindingtest: invalid Durable Object ID: %q", id)
+	}
+	return &DurableObjectID{id: id}, nil
+}
+
+func (ns *DurableObjectNamespace) NewUniqueId() binding.DurableObjectID {
+	return &DurableObjectID{id: randomHex(32)}
+}
+
+func (ns *DurableObjectNamespace) Get(id binding.DurableObjectID) (binding.DurableObjectStub, error) {
+	if ns.New == nil {
+		return nil, fmt.Errorf("bindingtest: New of DurableObjectNamespace is nil")
+	}
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	if ns.objects == nil {
+		ns.objects = map[string]*durableObjectStub{}
+	}
+	stub, ok := ns.objects[id.String()]
+	if !ok {
+		stub = &durableObjectStub{handler: ns.New(id)}
+		ns.objects[id.String()] = stub
+	}
+	return stub, nil
+}
+
+type durableObjectStub struct {
+	// mu serializes requests to the object, like the single thread of Durable Objects.
+	mu      sync.Mutex
+	handler http.Handler
+}
+
+// Fetch serves the request by the handler of the object.
+func (s *durableObjectStub) Fetch(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
@@ -0,0 +1,62 @@
+package bindingtest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+// counter is a Durable Object which counts requests.
+type counter struct {
+	n int
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	c.n++
+	fmt.Fprint(w, c.n)
+}
+
+func fetchCount(t *testing.T, ns binding.DurableObjectNamespace, id binding.DurableObjectID) string {
+	t.Helper()
+	stub, err := ns.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := stub.Fetch(httptest.NewRequest(http.MethodGet, "https://do/", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(res.Body)
+	return string(b)
+}
+
+func TestDurableObjectNamespace(t *testing.T) {
+	ns := NewDurableObjectNamespace(func(binding.DurableObjectID) http.Handler {
+		return &counter{}
+	})
+	a := ns.IdFromName("a")
+	if name, ok := a.Name(); !ok || name != "a" {
+		t.Errorf("Name() = %q, %v", name, ok)
+	}
+	fetchCount(t, ns, a)
+	if got := fetchCount(t, ns, ns.IdFromName("a")); got != "2" {
+		t.Errorf("count of a = %s, want 2", got)
+	}
+	parsed, err := ns.IdFromString(a.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fetchCount(t, ns, parsed); got != "3" {
+		t.Errorf("count of parsed a = %s, want 3", got)
+	}
+	if got := fetchCount(t, ns, ns.NewUniqueId()); got != "1" {
+		t.Errorf("count of unique ID = %s, want 1", got)
+	}
+	if _, err := ns.IdFromString("invalid"); err == nil {
+		t.Error("IdFromString(invalid): want error")
+	}
+}
@@ -0,0 +1,141 @@
+package bindingtest
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+// defaultKVListLimit is the default number of keys returned by List of KV.
+const defaultKVListLimit = 1000
+
+// KV is an in-memory fake of KV namespaces.
+//   - Getting a missing or expired key returns an empty string (or an empty reader) without error.
+//   - Cursors returned by List are the last keys of the pages.
+type KV struct {
+	// Now returns the current time used for expiration. if Now is nil, time.Now is used.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*kvEntry
+}
+
+type kvEntry struct {
+	value []byte
+	// expiration is the time when the entry expires. zero means no expiration.
+	expiration time.Time
+}
+
+var _ binding.KV = (*KV)(nil)
+
+// NewKV returns an empty KV.
+func NewKV() *KV {
+	return &KV{entries: map[string]*kvEntry{}}
+}
+
+// lookup returns the entry of the key if it exists and is not expired. kv.mu must be held.
+func (kv *KV) lookup(key string) (*kvEntry, bool) {
+	e, ok := kv.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expiration.IsZero() && !clock(kv.Now).Before(e.expiration) {
+		delete(kv.entries, key)
+		return nil, false
+	}
+	return e, true
+}
+
+func (kv *KV) GetString(key string, _ *cloudflare.KVNamespaceGetOptions) (string, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	e, ok := kv.lookup(key)
+	if !ok {
+		return "", nil
+	}
+	return string(e.value), nil
+}
+
+func (kv *KV) GetReader(key string, _ *cloudflare.KVNamespaceGetOptions) (io.Reader, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	e, ok := kv.lookup(key)
+	if !ok {
+		return bytes.NewReader(nil), nil
+	}
+	return bytes.NewReader(e.value), nil
+}
+
+func (kv *KV) List(opts *cloudflare.KVNamespaceListOptions) (*cloudflare.KVNamespaceListResult, error) {
+	if opts == nil {
+		opts = &cloudflare.KVNamespaceListOptions{}
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultKVListLimit
+	}
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	var keys []string
+	for key := range kv.entries {
+		if _, ok := kv.lookup(key); ok && strings.HasPrefix(key, opts.Prefix) && key > opts.Cursor {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	result := &cloudflare.KVNamespaceListResult{ListComplete: len(keys) <= limit}
+	if !result.ListComplete {
+		keys = keys[:limit]
+		result.Cursor = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		listKey := &cloudflare.KVNamespaceListKey{Name: key}
+		if exp := kv.entries[key].expiration; !exp.IsZero() {
+			listKey.Expiration = int(exp.Unix())
+		}
+		result.Keys = append(result.Keys, listKey)
+	}
+	return result, nil
+}
+
+func (kv *KV) PutString(key string, value string, opts *cloudflare.KVNamespacePutOptions) error {
+	kv.put(key, []byte(value), opts)
+	return nil
+}
+
+func (kv *KV) PutReader(key string, value io.Reader, opts *cloudflare.KVNamespacePutOptions) error {
+	b, err := io.ReadAll(value)
+	if err != nil {
+		return err
+	}
+	kv.put(key, b, opts)
+	return nil
+}
+
+func (kv *KV) put(key string, value []byte, opts *cloudflare.KVNamespacePutOptions) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	e := &kvEntry{value: value}
+	if opts != nil {
+		switch {
+		case opts.ExpirationTTL != 0:
+			e.expiration = clock(kv.Now).Add(time.Duration(opts.ExpirationTTL) * time.Second)
+		case opts.Expiration != 0:
+			e.expiration = time.Unix(int64(opts.Expiration), 0)
+		}
+	}
+	kv.entries[key] = e
+}
+
+func (kv *KV) Delete(key string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	delete(kv.entries, key)
+	return nil
+}
@@ -0,0 +1,114 @@
+package bindingtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+func TestKV(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	kv := NewKV()
+	kv.Now = func() time.Time { return now }
+
+	if err := kv.PutString("a", "1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.PutReader("b", strings.NewReader("2"), &cloudflare.KVNamespacePutOptions{ExpirationTTL: 60}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := kv.GetString("a", nil); got != "1" {
+		t.Errorf("GetString(a) = %q, want 1", got)
+	}
+	r, _ := kv.GetReader("b", nil)
+	if b, _ := io.ReadAll(r); string(b) != "2" {
+		t.Errorf("GetReader(b) = %q, want 2", b)
+	}
+	if got, _ := kv.GetString("missing", nil); got != "" {
+		t.Errorf("GetString(missing) = %q, want empty", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got, _ := kv.GetString("b", nil); got != "" {
+		t.Errorf("GetString(b) after expiration = %q, want empty", got)
+	}
+	if err := kv.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := kv.GetString("a", nil); got != "" {
+		t.Errorf("GetString(a) after Delete = %q, want empty", got)
+	}
+}
+
+func TestKV_List(t *testing.T) {
+	kv := NewKV()
+	for _, key := range []string{"user:3", "user:1", "post:1", "user:2"} {
+		kv.PutString(key, "", nil)
+	}
+	var names []string
+	opts := &cloudflare.KVNamespaceListOptions{Prefix: "user:", Limit: 2}
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatal("List doesn't complete")
+		}
+		res, err := kv.List(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range res.Keys {
+			names = append(names, key.Name)
+		}
+		if res.ListComplete {
+			break
+		}
+		opts.Cursor = res.Cursor
+	}
+	if got, want := strings.Join(names, ","), "user:1,user:2,user:3"; got != want {
+		t.Errorf("listed keys = %s, want %s", got, want)
+	}
+}
+
+func TestKV_ServeHTTP(t *testing.T) {
+	kv := NewKV()
+	do := func(method, target, body string) *http.Response {
+		rec := httptest.NewRecorder()
+		kv.ServeHTTP(rec, httptest.NewRequest(method, "https://fake-host"+target, strings.NewReader(body)))
+		return rec.Result()
+	}
+
+	do(http.MethodPut, "/a%2Fb?urlencoded=true&expiration_ttl=60", "1")
+	if got, _ := kv.GetString("a/b", nil); got != "1" {
+		t.Errorf("value put by HTTP = %q, want 1", got)
+	}
+	if b, _ := io.ReadAll(do(http.MethodGet, "/a%2Fb?urlencoded=true", "").Body); string(b) != "1" {
+		t.Errorf("value got by HTTP = %q, want 1", b)
+	}
+	if res := do(http.MethodGet, "/missing", ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("status of missing key = %d, want 404", res.StatusCode)
+	}
+
+	var list struct {
+		Keys []struct {
+			Name       string `json:"name"`
+			Expiration int    `json:"expiration"`
+		} `json:"keys"`
+		ListComplete bool `json:"list_complete"`
+	}
+	if err := json.NewDecoder(do(http.MethodGet, "/?prefix=a", "").Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Keys) != 1 || list.Keys[0].Name != "a/b" || list.Keys[0].Expiration == 0 || !list.ListComplete {
+		t.Errorf("list = %+v", list)
+	}
+
+	do(http.MethodDelete, "/a%2Fb", "")
+	if got, _ := kv.GetString("a/b", nil); got != "" {
+		t.Errorf("value after DELETE = %q, want empty", got)
+	}
+}
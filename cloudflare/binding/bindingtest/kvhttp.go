@@ -0,0 +1,78 @@
+package bindingtest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+// ServeHTTP serves the HTTP protocol which workerd uses for KV bindings backed by a service,
+// so KV can be the storage of KV namespaces of workerd.
+//   - GET /{key}, PUT /{key} and DELETE /{key} get, put and delete the value.
+//   - GET / lists keys by the prefix, limit and cursor queries.
+//   - Metadata of values is not supported.
+func (kv *KV) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	key, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), "/"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := req.URL.Query()
+	switch {
+	case req.Method == http.MethodGet && key == "":
+		kv.serveList(w, query)
+	case req.Method == http.MethodGet:
+		kv.mu.Lock()
+		e, ok := kv.lookup(key)
+		kv.mu.Unlock()
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(e.value)
+	case req.Method == http.MethodPut:
+		opts := &cloudflare.KVNamespacePutOptions{}
+		opts.Expiration, _ = strconv.Atoi(query.Get("expiration"))
+		opts.ExpirationTTL, _ = strconv.Atoi(query.Get("expiration_ttl"))
+		value, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		kv.put(key, value, opts)
+	case req.Method == http.MethodDelete:
+		kv.Delete(key)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+// kvListKey is the JSON representation of keys listed by ServeHTTP.
+type kvListKey struct {
+	Name       string `json:"name"`
+	Expiration int    `json:"expiration,omitempty"`
+}
+
+func (kv *KV) serveList(w http.ResponseWriter, query url.Values) {
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	res, _ := kv.List(&cloudflare.KVNamespaceListOptions{
+		Prefix: query.Get("prefix"),
+		Limit:  limit,
+		Cursor: query.Get("cursor"),
+	})
+	keys := make([]kvListKey, len(res.Keys))
+	for i, key := range res.Keys {
+		keys[i] = kvListKey{Name: key.Name, Expiration: key.Expiration}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys":          keys,
+		"list_complete": res.ListComplete,
+		"cursor":        res.Cursor,
+	})
+}
@@ -0,0 +1,242 @@
+package bindingtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+// defaultVisibilityTimeout is the default duration in which received messages are invisible.
+const defaultVisibilityTimeout = 30 * time.Second
+
+// Queue is an in-memory fake of queue producers, with the consumer side methods to inspect sent messages.
+//   - Messages are delivered after their delay, and received messages are invisible until they are acknowledged,
+//     retried, or VisibilityTimeout passes.
+//   - Bodies of ContentTypeJSON are decoded into any (e.g. map[string]any), like message bodies received by consumers.
+type Queue struct {
+	// Now returns the current time used for delays and visibility. if Now is nil, time.Now is used.
+	Now func() time.Time
+	// VisibilityTimeout is the duration in which received messages are invisible.
+	// if VisibilityTimeout is 0, 30 seconds is used.
+	VisibilityTimeout time.Duration
+
+	mu       sync.Mutex
+	messages []*QueueMessage
+	nextID   int
+}
+
+// QueueMessage represents a message sent to Queue.
+type QueueMessage struct {
+	ID          string
+	Body        any
+	ContentType queues.ContentType
+	Timestamp   time.Time
+	// Attempts is the number of times the message has been received.
+	Attempts int
+	// visibleAt is the time after which the message can be received.
+	visibleAt time.Time
+}
+
+var _ binding.Queue = (*Queue)(nil)
+
+// NewQueue returns an empty Queue.
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// decodeBody validates the body with the content type, and returns the body as received by consumers.
+func decodeBody(body any, contentType queues.ContentType) (any, queues.ContentType, error) {
+	if contentType == "" {
+		contentType = queues.ContentTypeJSON
+		if _, ok := body.([]byte); ok {
+			contentType = queues.ContentTypeBytes
+		}
+	}
+	var size int
+	switch contentType {
+	case queues.ContentTypeJSON:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("queues: error encoding body: %w", err)
+		}
+		size = len(data)
+		body = nil
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, "", err
+		}
+	case queues.ContentTypeBytes:
+		b, ok := body.([]byte)
+		if !ok {
+			return nil, "", fmt.Errorf("queues: body of content type %s must be []byte, got %T", contentType, body)
+		}
+		size = len(b)
+		body = append([]byte(nil), b...)
+	case queues.ContentTypeText:
+		s, ok := body.(string)
+		if !ok {
+			return nil, "", fmt.Errorf("queues: body of content type %s must be string, got %T", contentType, body)
+		}
+		size = len(s)
+	case queues.ContentTypeV8:
+		// the structured clone algorithm is not emulated, so the body is stored as is.
+	default:
+		return nil, "", fmt.Errorf("queues: unsupported content type: %s", contentType)
+	}
+	if size > queues.MaxMessageSize {
+		return nil, "", queues.ErrMessageTooLarge
+	}
+	return body, contentType, nil
+}
+
+func validateDelay(delaySeconds int) error {
+	if delaySeconds < 0 || delaySeconds > queues.MaxDelaySeconds {
+		return queues.ErrInvalidDelay
+	}
+	return nil
+}
+
+// newMessage returns the message delivered after the delay. q.mu must be held.
+func (q *Queue) newMessage(body any, contentType queues.ContentType, delaySeconds int) *QueueMessage {
+	q.nextID++
+	now := clock(q.Now)
+	return &QueueMessage{
+		ID:          strconv.Itoa(q.nextID),
+		Body:        body,
+		ContentType: contentType,
+		Timestamp:   now,
+		visibleAt:   now.Add(time.Duration(delaySeconds) * time.Second),
+	}
+}
+
+func (q *Queue) Send(body any, opts *queues.SendOptions) error {
+	if opts == nil {
+		opts = &queues.SendOptions{}
+	}
+	if err := validateDelay(opts.DelaySeconds); err != nil {
+		return err
+	}
+	body, contentType, err := decodeBody(body, opts.ContentType)
+	if err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.messages = append(q.messages, q.newMessage(body, contentType, opts.DelaySeconds))
+	return nil
+}
+
+func (q *Queue) SendBatch(messages []*queues.MessageSendRequest, opts *queues.SendBatchOptions) error {
+	if len(messages) > queues.MaxBatchMessages {
+		return queues.ErrTooManyMessages
+	}
+	var batchDelay int
+	if opts != nil {
+		batchDelay = opts.DelaySeconds
+	}
+	if err := validateDelay(batchDelay); err != nil {
+		return err
+	}
+	type decoded struct {
+		body         any
+		contentType  queues.ContentType
+		delaySeconds int
+	}
+	batch := make([]decoded, len(messages))
+	for i, msg := range messages {
+		delay := batchDelay
+		if msg.DelaySeconds != 0 {
+			delay = msg.DelaySeconds
+		}
+		if err := validateDelay(delay); err != nil {
+			return fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		body, contentType, err := decodeBody(msg.Body, msg.ContentType)
+		if err != nil {
+			return fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		batch[i] = decoded{body: body, contentType: contentType, delaySeconds: delay}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, m := range batch {
+		q.messages = append(q.messages, q.newMessage(m.body, m.contentType, m.delaySeconds))
+	}
+	return nil
+}
+
+// Len returns the number of messages which are not acknowledged, including delayed and invisible messages.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.messages)
+}
+
+// Receive returns up to max visible messages in the order of delivery, and makes them invisible for VisibilityTimeout.
+//   - if max is 0 or less, all visible messages are returned.
+//   - Returned messages are copies, and their Attempts are incremented.
+func (q *Queue) Receive(max int) []*QueueMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := clock(q.Now)
+	timeout := q.VisibilityTimeout
+	if timeout == 0 {
+		timeout = defaultVisibilityTimeout
+	}
+	visible := make([]*QueueMessage, 0, len(q.messages))
+	for _, m := range q.messages {
+		if !now.Before(m.visibleAt) {
+			visible = append(visible, m)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].visibleAt.Before(visible[j].visibleAt)
+	})
+	if max > 0 && len(visible) > max {
+		visible = visible[:max]
+	}
+	received := make([]*QueueMessage, len(visible))
+	for i, m := range visible {
+		m.Attempts++
+		m.visibleAt = now.Add(timeout)
+		c := *m
+		received[i] = &c
+	}
+	return received
+}
+
+// Ack removes the message from the queue.
+//   - if the message is not found, returns error.
+func (q *Queue) Ack(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, m := range q.messages {
+		if m.ID == id {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("bindingtest: message %s is not found", id)
+}
+
+// Retry makes the message visible again after delaySeconds.
+//   - if the message is not found, returns error.
+func (q *Queue) Retry(id string, delaySeconds int) error {
+	if err := validateDelay(delaySeconds); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, m := range q.messages {
+		if m.ID == id {
+			m.visibleAt = clock(q.Now).Add(time.Duration(delaySeconds) * time.Second)
+			return nil
+		}
+	}
+	return fmt.Errorf("bindingtest: message %s is not found", id)
+}
@@ -0,0 +1,83 @@
+package bindingtest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+func TestQueue(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	q := NewQueue()
+	q.Now = func() time.Time { return now }
+	q.VisibilityTimeout = 10 * time.Second
+
+	if err := q.Send(map[string]int{"n": 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.SendBatch([]*queues.MessageSendRequest{
+		{Body: "delayed", ContentType: queues.ContentTypeText, DelaySeconds: 60},
+		{Body: []byte("raw")},
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if q.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", q.Len())
+	}
+
+	msgs := q.Receive(0)
+	if len(msgs) != 2 {
+		t.Fatalf("Receive returned %d messages, want 2", len(msgs))
+	}
+	if want := map[string]any{"n": float64(1)}; !reflect.DeepEqual(msgs[0].Body, want) {
+		t.Errorf("JSON body = %#v, want %#v", msgs[0].Body, want)
+	}
+	if msgs[1].ContentType != queues.ContentTypeBytes || string(msgs[1].Body.([]byte)) != "raw" {
+		t.Errorf("bytes message = %s %v", msgs[1].ContentType, msgs[1].Body)
+	}
+	if got := q.Receive(0); len(got) != 0 {
+		t.Errorf("received %d messages during visibility timeout, want 0", len(got))
+	}
+
+	if err := q.Ack(msgs[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Retry(msgs[1].ID, 0); err != nil {
+		t.Fatal(err)
+	}
+	got := q.Receive(0)
+	if len(got) != 1 || got[0].ID != msgs[1].ID || got[0].Attempts != 2 {
+		t.Fatalf("Receive after Retry = %+v, want the retried message with 2 attempts", got)
+	}
+	if err := q.Ack(got[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Minute)
+	got = q.Receive(1)
+	if len(got) != 1 || got[0].Body != "delayed" {
+		t.Errorf("Receive after delay = %+v, want the delayed message", got)
+	}
+	if q.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", q.Len())
+	}
+}
+
+func TestQueue_Errors(t *testing.T) {
+	q := NewQueue()
+	if err := q.Send("x", &queues.SendOptions{DelaySeconds: queues.MaxDelaySeconds + 1}); !errors.Is(err, queues.ErrInvalidDelay) {
+		t.Errorf("Send with invalid delay: err = %v, want ErrInvalidDelay", err)
+	}
+	if err := q.Send(1, &queues.SendOptions{ContentType: queues.ContentTypeText}); err == nil {
+		t.Error("Send of int as text: want error")
+	}
+	if err := q.Send(make([]byte, queues.MaxMessageSize+1), nil); !errors.Is(err, queues.ErrMessageTooLarge) {
+		t.Errorf("Send of large message: err = %v, want ErrMessageTooLarge", err)
+	}
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", q.Len())
+	}
+}
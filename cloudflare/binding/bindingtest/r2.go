@@ -0,0 +1,130 @@
+package bindingtest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+// R2Bucket is an in-memory fake of R2 buckets.
+//   - Head and Get of a missing key return nil without error, like R2.
+//   - ETags are MD5 hashes of the object bodies.
+type R2Bucket struct {
+	// Now returns the time recorded as the upload time of objects. if Now is nil, time.Now is used.
+	Now func() time.Time
+
+	mu      sync.Mutex
+	objects map[string]*r2Entry
+	version int
+}
+
+type r2Entry struct {
+	object *cloudflare.R2Object
+	body   []byte
+}
+
+var _ binding.R2Bucket = (*R2Bucket)(nil)
+
+// NewR2Bucket returns an empty R2Bucket.
+func NewR2Bucket() *R2Bucket {
+	return &R2Bucket{objects: map[string]*r2Entry{}}
+}
+
+// copyObject returns the copy of the object without its body.
+func copyObject(o *cloudflare.R2Object) *cloudflare.R2Object {
+	c := *o
+	if o.CustomMetadata != nil {
+		c.CustomMetadata = make(map[string]string, len(o.CustomMetadata))
+		for k, v := range o.CustomMetadata {
+			c.CustomMetadata[k] = v
+		}
+	}
+	return &c
+}
+
+func (r *R2Bucket) Head(key string) (*cloudflare.R2Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.objects[key]
+	if !ok {
+		return nil, nil
+	}
+	return copyObject(e.object), nil
+}
+
+func (r *R2Bucket) Get(key string) (*cloudflare.R2Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.objects[key]
+	if !ok {
+		return nil, nil
+	}
+	o := copyObject(e.object)
+	o.Body = bytes.NewReader(e.body)
+	return o, nil
+}
+
+// Put stores the object.
+//   - if opts.MD5 is set and doesn't match the body, returns error.
+func (r *R2Bucket) Put(key string, value io.ReadCloser, opts *cloudflare.R2PutOptions) (*cloudflare.R2Object, error) {
+	defer value.Close()
+	body, err := io.ReadAll(value)
+	if err != nil {
+		return nil, err
+	}
+	sum := md5.Sum(body)
+	etag := hex.EncodeToString(sum[:])
+	if opts == nil {
+		opts = &cloudflare.R2PutOptions{}
+	}
+	if opts.MD5 != "" && opts.MD5 != etag {
+		return nil, fmt.Errorf("bindingtest: MD5 of the body doesn't match: %s", opts.MD5)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.version++
+	o := copyObject(&cloudflare.R2Object{
+		Key:            key,
+		Version:        strconv.Itoa(r.version),
+		Size:           len(body),
+		ETag:           etag,
+		HTTPETag:       strconv.Quote(etag),
+		Uploaded:       clock(r.Now),
+		HTTPMetadata:   opts.HTTPMetadata,
+		CustomMetadata: opts.CustomMetadata,
+	})
+	r.objects[key] = &r2Entry{object: o, body: body}
+	return copyObject(o), nil
+}
+
+func (r *R2Bucket) Delete(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.objects, key)
+	return nil
+}
+
+// List returns all objects sorted by key. Bodies of the objects are nil.
+func (r *R2Bucket) List() (*cloudflare.R2Objects, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := make([]string, 0, len(r.objects))
+	for key := range r.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	objects := make([]*cloudflare.R2Object, len(keys))
+	for i, key := range keys {
+		objects[i] = copyObject(r.objects[key].object)
+	}
+	return &cloudflare.R2Objects{Objects: objects}, nil
+}
@@ -0,0 +1,53 @@
+package bindingtest
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+func TestR2Bucket(t *testing.T) {
+	r := NewR2Bucket()
+	opts := &cloudflare.R2PutOptions{
+		HTTPMetadata:   cloudflare.R2HTTPMetadata{ContentType: "text/plain"},
+		CustomMetadata: map[string]string{"owner": "alice"},
+	}
+	put, err := r.Put("b.txt", io.NopCloser(strings.NewReader("hello")), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MD5 of "hello".
+	if put.ETag != "5d41402abc4b2a76b9719d911017c592" || put.Size != 5 || put.Body != nil {
+		t.Errorf("Put = %+v", put)
+	}
+	r.Put("a.txt", io.NopCloser(strings.NewReader("")), nil)
+
+	o, err := r.Get("b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(o.Body); string(b) != "hello" {
+		t.Errorf("body = %q, want hello", b)
+	}
+	if o.HTTPMetadata.ContentType != "text/plain" || o.CustomMetadata["owner"] != "alice" {
+		t.Errorf("metadata = %+v %v", o.HTTPMetadata, o.CustomMetadata)
+	}
+	if o, _ := r.Head("missing"); o != nil {
+		t.Errorf("Head(missing) = %+v, want nil", o)
+	}
+
+	list, _ := r.List()
+	if len(list.Objects) != 2 || list.Objects[0].Key != "a.txt" || list.Objects[1].Key != "b.txt" {
+		t.Errorf("List = %+v", list.Objects)
+	}
+
+	if _, err := r.Put("c.txt", io.NopCloser(strings.NewReader("x")), &cloudflare.R2PutOptions{MD5: "00"}); err == nil {
+		t.Error("Put with wrong MD5: want error")
+	}
+	r.Delete("b.txt")
+	if o, _ := r.Get("b.txt"); o != nil {
+		t.Errorf("Get after Delete = %+v, want nil", o)
+	}
+}
@@ -0,0 +1,161 @@
+// Package botmanagement blocks, challenges and annotates requests by the result of Bot Management and the threat score
+// given by the `cf` property of requests.
+//   - Requests without the result (e.g. of zones without Bot Management, or `wrangler dev`) are not scored, so they are allowed.
+//   - The result is obtained by cloudflare.GetIncomingRequestCF. Use cloudflare.NewIncomingRequestCFContext to test handlers.
+//   - https://developers.cloudflare.com/bots/reference/bot-management-variables/
+package botmanagement
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+// Action represents the action of Middleware for the request.
+type Action int
+
+const (
+	// Allow passes the request to the handler.
+	Allow Action = iota
+	// Challenge responds with the challenge by OnChallenge of Options.
+	Challenge
+	// Block responds with OnBlock of Options.
+	Block
+)
+
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Challenge:
+		return "challenge"
+	case Block:
+		return "block"
+	}
+	return "unknown"
+}
+
+// Names of headers set by Middleware when Annotate of Options is true, which are the same as the bot protection headers
+// of Cloudflare's Managed Transforms.
+//   - https://developers.cloudflare.com/rules/transform/managed-transforms/reference/#add-bot-protection-headers
+const (
+	HeaderBotScore    = "Cf-Bot-Score"
+	HeaderVerifiedBot = "Cf-Verified-Bot"
+	HeaderJA3Hash     = "Cf-Ja3-Hash"
+	HeaderJA4         = "Cf-Ja4"
+)
+
+// Options represents the options of Middleware and Decide.
+type Options struct {
+	// BlockScore blocks requests whose bot scores are BlockScore or lower, e.g. 1 for automated traffic. 0 disables blocking by scores.
+	BlockScore int
+	// ChallengeScore challenges requests whose bot scores are ChallengeScore or lower, e.g. 29 for likely automated traffic.
+	// 0 disables challenges by scores.
+	ChallengeScore int
+	// BlockThreatScore blocks requests whose threat scores are BlockThreatScore or higher. 0 disables blocking by threat scores.
+	BlockThreatScore int
+	// BlockVerifiedBots makes verified bots (e.g. crawlers of search engines) follow the scores. By default, they are allowed.
+	BlockVerifiedBots bool
+	// Annotate sets the result of Bot Management to headers of the request, so the origin receives it when the request is proxied.
+	// Headers given by the client are removed even if Annotate is false, so they can't be spoofed.
+	Annotate bool
+	// OnChallenge writes the response of challenged requests, e.g. the page of the Turnstile widget validated by `turnstile.Middleware`.
+	// The default responds 403 Forbidden.
+	OnChallenge http.Handler
+	// OnBlock writes the response of blocked requests. The default responds 403 Forbidden.
+	OnBlock http.Handler
+}
+
+// Decide returns the action for the `cf` property of the request. if cf is nil, returns Allow.
+//   - Requests of static resources are not scored, so they are blocked only by threat scores.
+func Decide(cf *cloudflare.IncomingRequestCF, opts *Options) Action {
+	if cf == nil {
+		return Allow
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.BlockThreatScore > 0 && cf.ThreatScore >= opts.BlockThreatScore {
+		return Block
+	}
+	bm := cf.BotManagement
+	if bm == nil || bm.Score == 0 || bm.StaticResource {
+		return Allow
+	}
+	if bm.VerifiedBot && !opts.BlockVerifiedBots {
+		return Allow
+	}
+	if bm.Score <= opts.BlockScore {
+		return Block
+	}
+	if bm.Score <= opts.ChallengeScore {
+		return Challenge
+	}
+	return Allow
+}
+
+type actionKey struct{}
+
+// FromContext returns the action of the request decided by Middleware, e.g. to log allowed requests of low scores.
+func FromContext(ctx context.Context) (Action, bool) {
+	a, ok := ctx.Value(actionKey{}).(Action)
+	return a, ok
+}
+
+// Middleware returns the middleware which blocks or challenges requests by the action of Decide.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	onChallenge := opts.OnChallenge
+	if onChallenge == nil {
+		onChallenge = http.HandlerFunc(forbidden)
+	}
+	onBlock := opts.OnBlock
+	if onBlock == nil {
+		onBlock = http.HandlerFunc(forbidden)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			cf, _ := cloudflare.GetIncomingRequestCF(req.Context())
+			action := Decide(cf, opts)
+			annotate(req.Header, cf, opts.Annotate)
+			req = req.WithContext(context.WithValue(req.Context(), actionKey{}, action))
+			switch action {
+			case Challenge:
+				onChallenge.ServeHTTP(w, req)
+			case Block:
+				onBlock.ServeHTTP(w, req)
+			default:
+				next.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+func forbidden(w http.ResponseWriter, req *http.Request) {
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+// annotate removes the headers given by the client, and sets the result of Bot Management if enabled.
+func annotate(header http.Header, cf *cloudflare.IncomingRequestCF, enabled bool) {
+	for _, name := range []string{HeaderBotScore, HeaderVerifiedBot, HeaderJA3Hash, HeaderJA4} {
+		header.Del(name)
+	}
+	if !enabled || cf == nil || cf.BotManagement == nil {
+		return
+	}
+	bm := cf.BotManagement
+	if bm.Score > 0 {
+		header.Set(HeaderBotScore, strconv.Itoa(bm.Score))
+	}
+	header.Set(HeaderVerifiedBot, strconv.FormatBool(bm.VerifiedBot))
+	if bm.JA3Hash != "" {
+		header.Set(HeaderJA3Hash, bm.JA3Hash)
+	}
+	if bm.JA4 != "" {
+		header.Set(HeaderJA4, bm.JA4)
+	}
+}
@@ -0,0 +1,114 @@
+package botmanagement
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+func withScore(score int) *cloudflare.IncomingRequestCF {
+	return &cloudflare.IncomingRequestCF{BotManagement: &cloudflare.BotManagement{Score: score}}
+}
+
+func TestDecide(t *testing.T) {
+	opts := &Options{BlockScore: 1, ChallengeScore: 29, BlockThreatScore: 50}
+	verified := withScore(1)
+	verified.BotManagement.VerifiedBot = true
+	static := withScore(1)
+	static.BotManagement.StaticResource = true
+	threat := withScore(99)
+	threat.ThreatScore = 50
+
+	tests := []struct {
+		name string
+		cf   *cloudflare.IncomingRequestCF
+		opts *Options
+		want Action
+	}{
+		{"no cf", nil, opts, Allow},
+		{"no bot management", &cloudflare.IncomingRequestCF{}, opts, Allow},
+		{"not scored", withScore(0), opts, Allow},
+		{"automated", withScore(1), opts, Block},
+		{"likely automated", withScore(29), opts, Challenge},
+		{"likely human", withScore(30), opts, Allow},
+		{"verified bot", verified, opts, Allow},
+		{"verified bot blocked", verified, &Options{BlockScore: 1, BlockVerifiedBots: true}, Block},
+		{"static resource", static, opts, Allow},
+		{"threat", threat, opts, Block},
+		{"threat below threshold", &cloudflare.IncomingRequestCF{ThreatScore: 49}, opts, Allow},
+		{"no thresholds", withScore(1), nil, Allow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decide(tt.cf, tt.opts); got != tt.want {
+				t.Errorf("Decide: %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		header http.Header
+		action Action
+	)
+	handler := Middleware(&Options{
+		BlockScore:     1,
+		ChallengeScore: 29,
+		Annotate:       true,
+		OnChallenge: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}),
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		header = req.Header.Clone()
+		action, _ = FromContext(req.Context())
+	}))
+
+	serve := func(cf *cloudflare.IncomingRequestCF) int {
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+		req.Header.Set(HeaderBotScore, "99")
+		req.Header.Set(HeaderVerifiedBot, "true")
+		if cf != nil {
+			req = req.WithContext(cloudflare.NewIncomingRequestCFContext(context.Background(), cf))
+		}
+		rec := httptest.NewRecorder()
+		header = nil
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := serve(withScore(1)); code != http.StatusForbidden || header != nil {
+		t.Errorf("blocked: %d", code)
+	}
+	if code := serve(withScore(10)); code != http.StatusTooManyRequests || header != nil {
+		t.Errorf("challenged: %d", code)
+	}
+
+	human := withScore(80)
+	human.BotManagement.JA3Hash = "25b4882c2bcb50cd6b469ff28c596742"
+	human.BotManagement.JA4 = "t13d1516h2_8daaf6152771_b186095e22b6"
+	if code := serve(human); code != http.StatusOK || action != Allow {
+		t.Fatalf("allowed: %d, %v", code, action)
+	}
+	want := map[string]string{
+		HeaderBotScore:    "80",
+		HeaderVerifiedBot: "false",
+		HeaderJA3Hash:     "25b4882c2bcb50cd6b469ff28c596742",
+		HeaderJA4:         "t13d1516h2_8daaf6152771_b186095e22b6",
+	}
+	for name, value := range want {
+		if v := header.Get(name); v != value {
+			t.Errorf("%s: %q, want %q", name, v, value)
+		}
+	}
+
+	if code := serve(nil); code != http.StatusOK {
+		t.Fatalf("without cf: %d", code)
+	}
+	if v := header.Get(HeaderBotScore); v != "" {
+		t.Errorf("header given by the client must be removed: %q", v)
+	}
+}
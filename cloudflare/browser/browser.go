@@ -0,0 +1,189 @@
+// Package browser provides the binding of Browser Rendering, which runs headless Chrome.
+//   - https://developers.cloudflare.com/browser-rendering/
+//   - The browser is controlled by the Chrome DevTools Protocol through the binding, in the same way as @cloudflare/puppeteer.
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// fakeHost is the host of requests to the binding. The binding ignores the host.
+const fakeHost = "http://fake.host"
+
+// Browser represents the binding of Browser Rendering.
+type Browser struct {
+	instance js.Value
+}
+
+// NewBrowser returns Browser for given variable name.
+//   - variable name must be defined in wrangler.toml as browser's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewBrowser(ctx context.Context, varName string) (*Browser, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Browser{instance: inst}, nil
+}
+
+// LaunchOptions represents the options of Launch.
+type LaunchOptions struct {
+	// KeepAlive is the duration to keep the browser alive without any connections. The maximum is 10 minutes.
+	// if KeepAlive is 0, the browser is closed after 1 minute of inactivity.
+	KeepAlive time.Duration
+}
+
+// Launch launches a new browser session and connects to it.
+func (b *Browser) Launch(opts *LaunchOptions) (*Session, error) {
+	path := "/v1/acquire"
+	if opts != nil && opts.KeepAlive > 0 {
+		path += "?keep_alive=" + strconv.FormatInt(opts.KeepAlive.Milliseconds(), 10)
+	}
+	var acquired struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := b.fetchJSON(path, &acquired); err != nil {
+		return nil, err
+	}
+	return b.Connect(acquired.SessionID)
+}
+
+// Connect connects to the existing browser session of sessionID.
+//   - Sessions which are not connected by other workers can be listed by Sessions.
+func (b *Browser) Connect(sessionID string) (*Session, error) {
+	headers := jsutil.NewObject()
+	headers.Set("Upgrade", "websocket")
+	init := jsutil.NewObject()
+	init.Set("headers", headers)
+	res, err := b.fetch("/v1/connectDevtools?browser_session="+url.QueryEscape(sessionID), init)
+	if err != nil {
+		return nil, err
+	}
+	ws := res.Get("webSocket")
+	if ws.IsUndefined() || ws.IsNull() {
+		return nil, fmt.Errorf("browser: failed to connect to session %s: status %d", sessionID, res.Get("status").Int())
+	}
+	if _, err := jsutil.TryCall(ws, "accept"); err != nil {
+		return nil, err
+	}
+	return &Session{
+		ID:   sessionID,
+		conn: newCDPConn(ws),
+	}, nil
+}
+
+// SessionInfo represents a browser session.
+type SessionInfo struct {
+	SessionID string
+	StartTime time.Time
+	// ConnectionID is the ID of the connection to the session. ConnectionID is empty if no worker is connected.
+	ConnectionID        string
+	ConnectionStartTime time.Time
+}
+
+// Sessions returns the running browser sessions.
+func (b *Browser) Sessions() ([]*SessionInfo, error) {
+	var res struct {
+		Sessions []struct {
+			SessionID           string `json:"sessionId"`
+			StartTime           int64  `json:"startTime"`
+			ConnectionID        string `json:"connectionId"`
+			ConnectionStartTime int64  `json:"connectionStartTime"`
+		} `json:"sessions"`
+	}
+	if err := b.fetchJSON("/v1/sessions", &res); err != nil {
+		return nil, err
+	}
+	sessions := make([]*SessionInfo, len(res.Sessions))
+	for i, s := range res.Sessions {
+		info := &SessionInfo{
+			SessionID:    s.SessionID,
+			StartTime:    time.UnixMilli(s.StartTime),
+			ConnectionID: s.ConnectionID,
+		}
+		if s.ConnectionStartTime != 0 {
+			info.ConnectionStartTime = time.UnixMilli(s.ConnectionStartTime)
+		}
+		sessions[i] = info
+	}
+	return sessions, nil
+}
+
+// Limits represents the limits of browser sessions of the account.
+type Limits struct {
+	// ActiveSessionIDs are the IDs of the running sessions.
+	ActiveSessionIDs []string
+	// MaxConcurrentSessions is the maximum number of concurrent sessions.
+	MaxConcurrentSessions int
+	// AllowedBrowserAcquisitions is the number of sessions which can be launched now.
+	AllowedBrowserAcquisitions int
+	// TimeUntilNextAllowedBrowserAcquisition is the duration until a new session can be launched.
+	TimeUntilNextAllowedBrowserAcquisition time.Duration
+}
+
+// Limits returns the limits of browser sessions.
+func (b *Browser) Limits() (*Limits, error) {
+	var res struct {
+		ActiveSessions []struct {
+			ID string `json:"id"`
+		} `json:"activeSessions"`
+		MaxConcurrentSessions                  int   `json:"maxConcurrentSessions"`
+		AllowedBrowserAcquisitions             int   `json:"allowedBrowserAcquisitions"`
+		TimeUntilNextAllowedBrowserAcquisition int64 `json:"timeUntilNextAllowedBrowserAcquisition"`
+	}
+	if err := b.fetchJSON("/v1/limits", &res); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(res.ActiveSessions))
+	for i, s := range res.ActiveSessions {
+		ids[i] = s.ID
+	}
+	return &Limits{
+		ActiveSessionIDs:                       ids,
+		MaxConcurrentSessions:                  res.MaxConcurrentSessions,
+		AllowedBrowserAcquisitions:             res.AllowedBrowserAcquisitions,
+		TimeUntilNextAllowedBrowserAcquisition: time.Duration(res.TimeUntilNextAllowedBrowserAcquisition) * time.Millisecond,
+	}, nil
+}
+
+// fetch sends the request to the binding, and returns JavaScript side's Response.
+func (b *Browser) fetch(path string, init js.Value) (js.Value, error) {
+	promise, err := jsutil.TryCall(b.instance, "fetch", fakeHost+path, init)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.AwaitPromise(promise)
+}
+
+// fetchJSON sends GET request to the binding, and decodes the JSON response into out by `jsutil.Decode`.
+func (b *Browser) fetchJSON(path string, out any) error {
+	res, err := b.fetch(path, js.Undefined())
+	if err != nil {
+		return err
+	}
+	if !res.Get("ok").Bool() {
+		text, err := jsutil.AwaitPromise(res.Call("text"))
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("browser: request to %s failed: status %d: %s", path, res.Get("status").Int(), text.String())
+	}
+	// The response is parsed on JavaScript side, so the JSON text is not transferred to Go.
+	v, err := jsutil.AwaitPromise(res.Call("json"))
+	if err != nil {
+		return fmt.Errorf("browser: error parsing response of %s: %w", path, err)
+	}
+	if err := jsutil.Decode(v, out); err != nil {
+		return fmt.Errorf("browser: error decoding response of %s: %w", path, err)
+	}
+	return nil
+}
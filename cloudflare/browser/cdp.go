@@ -0,0 +1,246 @@
+package browser
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ErrClosed is returned when the connection to the browser is closed.
+var ErrClosed = errors.New("browser: connection is closed")
+
+const (
+	// chunkHeaderSize is the size of the header of chunked messages, which holds the length of the message.
+	chunkHeaderSize = 4
+	// maxChunkSize is the maximum size of WebSocket messages sent to the binding.
+	maxChunkSize = 1048575
+	// pingInterval is the interval of pings, which keep the connection alive.
+	pingInterval = time.Second
+)
+
+// encodeChunks splits the message into chunks sent to the binding.
+//   - The first chunk starts with the length of the message as little endian uint32.
+func encodeChunks(msg []byte) [][]byte {
+	data := make([]byte, chunkHeaderSize+len(msg))
+	binary.LittleEndian.PutUint32(data, uint32(len(msg)))
+	copy(data[chunkHeaderSize:], msg)
+	var chunks [][]byte
+	for len(data) > maxChunkSize {
+		chunks = append(chunks, data[:maxChunkSize])
+		data = data[maxChunkSize:]
+	}
+	return append(chunks, data)
+}
+
+// chunkDecoder joins chunks received from the binding into messages.
+type chunkDecoder struct {
+	buf []byte
+}
+
+// write adds the chunk, and returns the message when all chunks of the message are received.
+func (d *chunkDecoder) write(chunk []byte) ([]byte, bool) {
+	d.buf = append(d.buf, chunk...)
+	if len(d.buf) < chunkHeaderSize {
+		return nil, false
+	}
+	size := int(binary.LittleEndian.Uint32(d.buf))
+	if len(d.buf) < chunkHeaderSize+size {
+		return nil, false
+	}
+	msg := d.buf[chunkHeaderSize : chunkHeaderSize+size]
+	d.buf = nil
+	return msg, true
+}
+
+// cdpMessage represents a message of the Chrome DevTools Protocol.
+//   - https://chromedevtools.github.io/devtools-protocol/
+type cdpMessage struct {
+	ID        int             `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    any             `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *cdpError       `json:"error,omitempty"`
+}
+
+type cdpError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *cdpError) Error() string {
+	return fmt.Sprintf("browser: protocol error (%d): %s", e.Code, e.Message)
+}
+
+// cdpConn is the connection of the Chrome DevTools Protocol over the WebSocket of the binding.
+type cdpConn struct {
+	ws      js.Value
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan *cdpMessage
+	// waiters are channels waiting for events, keyed by the session ID and the method of events.
+	waiters map[string][]chan *cdpMessage
+	decoder chunkDecoder
+	closed  chan struct{}
+	// listeners are event listeners of the WebSocket, keyed by the event type.
+	listeners map[string]js.Func
+}
+
+func newCDPConn(ws js.Value) *cdpConn {
+	c := &cdpConn{
+		ws:      ws,
+		pending: make(map[int]chan *cdpMessage),
+		waiters: make(map[string][]chan *cdpMessage),
+		closed:  make(chan struct{}),
+	}
+	onMessage := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		data := args[0].Get("data")
+		if data.Type() == js.TypeString {
+			return nil
+		}
+		ua := jsutil.Uint8ArrayClass().New(data)
+		chunk := make([]byte, ua.Get("byteLength").Int())
+		js.CopyBytesToGo(chunk, ua)
+		if msg, ok := c.decoder.write(chunk); ok {
+			c.dispatch(msg)
+		}
+		return nil
+	})
+	onClose := js.FuncOf(func(_ js.Value, _ []js.Value) any {
+		c.markClosed()
+		return nil
+	})
+	c.listeners = map[string]js.Func{
+		"message": onMessage,
+		"close":   onClose,
+		"error":   onClose,
+	}
+	for typ, f := range c.listeners {
+		ws.Call("addEventListener", typ, f)
+	}
+	go c.ping()
+	return c
+}
+
+func (c *cdpConn) ping() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			_, _ = jsutil.TryCall(c.ws, "send", "ping")
+		}
+	}
+}
+
+// dispatch delivers the received message to the caller waiting for it.
+func (c *cdpConn) dispatch(data []byte) {
+	var msg cdpMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if msg.ID != 0 {
+		if ch, ok := c.pending[msg.ID]; ok {
+			delete(c.pending, msg.ID)
+			ch <- &msg
+		}
+		return
+	}
+	key := msg.SessionID + "/" + msg.Method
+	for _, ch := range c.waiters[key] {
+		ch <- &msg
+	}
+	delete(c.waiters, key)
+}
+
+func (c *cdpConn) markClosed() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	select {
+	case <-c.closed:
+		return
+	default:
+	}
+	close(c.closed)
+	for typ, f := range c.listeners {
+		c.ws.Call("removeEventListener", typ, f)
+	}
+	// onClose is shared by close and error events, so each function is released once.
+	c.listeners["message"].Release()
+	c.listeners["close"].Release()
+}
+
+// waitEvent returns the channel which receives the next event of the method in the session.
+//   - waitEvent must be called before sending the command which fires the event.
+func (c *cdpConn) waitEvent(sessionID, method string) <-chan *cdpMessage {
+	ch := make(chan *cdpMessage, 1)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := sessionID + "/" + method
+	c.waiters[key] = append(c.waiters[key], ch)
+	return ch
+}
+
+// call sends the command to the session, and decodes the result into result.
+//   - if sessionID is empty, the command is sent to the browser.
+func (c *cdpConn) call(ctx context.Context, sessionID, method string, params any, result any) error {
+	ch := make(chan *cdpMessage, 1)
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(&cdpMessage{ID: id, SessionID: sessionID, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("browser: error encoding %s: %w", method, err)
+	}
+	for _, chunk := range encodeChunks(data) {
+		ua := jsutil.NewUint8Array(len(chunk))
+		js.CopyBytesToJS(ua, chunk)
+		if _, err := jsutil.TryCall(c.ws, "send", ua); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.closed:
+		return ErrClosed
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if result == nil || len(msg.Result) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("browser: error decoding result of %s: %w", method, err)
+		}
+		return nil
+	}
+}
+
+// close closes the WebSocket.
+func (c *cdpConn) close() error {
+	_, err := jsutil.TryCall(c.ws, "close")
+	c.markClosed()
+	return err
+}
@@ -0,0 +1,55 @@
+package browser
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	tests := map[string]struct {
+		size       int
+		wantChunks int
+	}{
+		"empty": {
+			size:       0,
+			wantChunks: 1,
+		},
+		"small": {
+			size:       100,
+			wantChunks: 1,
+		},
+		"exact": {
+			size:       maxChunkSize - chunkHeaderSize,
+			wantChunks: 1,
+		},
+		"large": {
+			size:       maxChunkSize*2 + 1,
+			wantChunks: 3,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			msg := bytes.Repeat([]byte("a"), tc.size)
+			chunks := encodeChunks(msg)
+			if len(chunks) != tc.wantChunks {
+				t.Fatalf("want %d chunks, got %d", tc.wantChunks, len(chunks))
+			}
+			var d chunkDecoder
+			for i, chunk := range chunks {
+				if len(chunk) > maxChunkSize {
+					t.Fatalf("chunks[%d] is too large: %d", i, len(chunk))
+				}
+				got, ok := d.write(chunk)
+				if ok != (i == len(chunks)-1) {
+					t.Fatalf("unexpected completion at chunks[%d]", i)
+				}
+				if ok && !bytes.Equal(got, msg) {
+					t.Fatalf("decoded message differs: want %d bytes, got %d bytes", len(msg), len(got))
+				}
+			}
+		})
+	}
+}
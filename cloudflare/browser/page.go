@@ -0,0 +1,208 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Page represents a page of the browser.
+type Page struct {
+	conn      *cdpConn
+	targetID  string
+	sessionID string
+}
+
+func (p *Page) call(ctx context.Context, method string, params any, result any) error {
+	return p.conn.call(ctx, p.sessionID, method, params, result)
+}
+
+// Navigate navigates the page to the URL, and waits until the page is loaded.
+func (p *Page) Navigate(ctx context.Context, url string) error {
+	loaded := p.conn.waitEvent(p.sessionID, "Page.loadEventFired")
+	var res struct {
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.call(ctx, "Page.navigate", map[string]any{"url": url}, &res); err != nil {
+		return err
+	}
+	if res.ErrorText != "" {
+		return fmt.Errorf("browser: failed to navigate to %s: %s", url, res.ErrorText)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.conn.closed:
+		return ErrClosed
+	case <-loaded:
+		return nil
+	}
+}
+
+// SetViewport sets the size of the viewport of the page in CSS pixels.
+func (p *Page) SetViewport(ctx context.Context, width, height int) error {
+	return p.call(ctx, "Emulation.setDeviceMetricsOverride", map[string]any{
+		"width":             width,
+		"height":            height,
+		"deviceScaleFactor": 1,
+		"mobile":            false,
+	}, nil)
+}
+
+// ImageFormat represents the format of screenshots.
+type ImageFormat string
+
+const (
+	ImageFormatPNG  ImageFormat = "png"
+	ImageFormatJPEG ImageFormat = "jpeg"
+	ImageFormatWebP ImageFormat = "webp"
+)
+
+// ScreenshotOptions represents the options of Screenshot.
+type ScreenshotOptions struct {
+	// Format is the format of the image. The default value is ImageFormatPNG.
+	Format ImageFormat
+	// Quality is the quality of the image in the range [0, 100]. This is ignored for ImageFormatPNG.
+	Quality int
+	// FullPage captures the whole page instead of the viewport.
+	FullPage bool
+}
+
+// Screenshot captures the screenshot of the page.
+func (p *Page) Screenshot(ctx context.Context, opts *ScreenshotOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &ScreenshotOptions{}
+	}
+	params := map[string]any{}
+	if opts.Format != "" {
+		params["format"] = opts.Format
+	}
+	if opts.Quality > 0 && opts.Format != ImageFormatPNG {
+		params["quality"] = opts.Quality
+	}
+	if opts.FullPage {
+		var metrics struct {
+			CSSContentSize struct {
+				Width  float64 `json:"width"`
+				Height float64 `json:"height"`
+			} `json:"cssContentSize"`
+		}
+		if err := p.call(ctx, "Page.getLayoutMetrics", nil, &metrics); err != nil {
+			return nil, err
+		}
+		params["captureBeyondViewport"] = true
+		params["clip"] = map[string]any{
+			"x":      0,
+			"y":      0,
+			"width":  metrics.CSSContentSize.Width,
+			"height": metrics.CSSContentSize.Height,
+			"scale":  1,
+		}
+	}
+	return p.callData(ctx, "Page.captureScreenshot", params)
+}
+
+// PDFOptions represents the options of PDF.
+type PDFOptions struct {
+	Landscape bool
+	// PrintBackground prints the background graphics.
+	PrintBackground bool
+	// Scale is the scale of the rendering. The default value is 1.
+	Scale float64
+	// PaperWidth and PaperHeight are the size of the paper in inches. The default value is 8.5 x 11 (Letter).
+	PaperWidth  float64
+	PaperHeight float64
+	// PageRanges are the pages to print, e.g. `1-5, 8, 11-13`. The default value is all pages.
+	PageRanges string
+}
+
+// PDF prints the page as PDF.
+func (p *Page) PDF(ctx context.Context, opts *PDFOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &PDFOptions{}
+	}
+	params := map[string]any{
+		"landscape":       opts.Landscape,
+		"printBackground": opts.PrintBackground,
+	}
+	if opts.Scale > 0 {
+		params["scale"] = opts.Scale
+	}
+	if opts.PaperWidth > 0 {
+		params["paperWidth"] = opts.PaperWidth
+	}
+	if opts.PaperHeight > 0 {
+		params["paperHeight"] = opts.PaperHeight
+	}
+	if opts.PageRanges != "" {
+		params["pageRanges"] = opts.PageRanges
+	}
+	return p.callData(ctx, "Page.printToPDF", params)
+}
+
+// callData calls the method which returns base64 encoded data.
+func (p *Page) callData(ctx context.Context, method string, params any) ([]byte, error) {
+	var res struct {
+		Data string `json:"data"`
+	}
+	if err := p.call(ctx, method, params, &res); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Data)
+	if err != nil {
+		return nil, fmt.Errorf("browser: error decoding data of %s: %w", method, err)
+	}
+	return data, nil
+}
+
+// Evaluate evaluates the JavaScript expression in the page, and decodes the result into out by encoding/json.
+//   - if the result is a Promise, Evaluate waits until it is settled.
+//   - if out is nil, the result is discarded.
+func (p *Page) Evaluate(ctx context.Context, expression string, out any) error {
+	var res struct {
+		Result struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	if err := p.call(ctx, "Runtime.evaluate", map[string]any{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	}, &res); err != nil {
+		return err
+	}
+	if d := res.ExceptionDetails; d != nil {
+		if d.Exception.Description != "" {
+			return fmt.Errorf("browser: evaluation failed: %s", d.Exception.Description)
+		}
+		return fmt.Errorf("browser: evaluation failed: %s", d.Text)
+	}
+	if out == nil || len(res.Result.Value) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(res.Result.Value, out); err != nil {
+		return fmt.Errorf("browser: error decoding result of evaluation: %w", err)
+	}
+	return nil
+}
+
+// Content returns the HTML content of the page.
+func (p *Page) Content(ctx context.Context) (string, error) {
+	var content string
+	if err := p.Evaluate(ctx, "document.documentElement.outerHTML", &content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// Close closes the page.
+func (p *Page) Close(ctx context.Context) error {
+	return p.conn.call(ctx, "", "Target.closeTarget", map[string]any{"targetId": p.targetID}, nil)
+}
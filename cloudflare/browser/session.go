@@ -0,0 +1,57 @@
+package browser
+
+import (
+	"context"
+	"errors"
+)
+
+// Session represents the connection to a browser session.
+type Session struct {
+	// ID is the ID of the session. The session can be connected again by `Browser.Connect`.
+	ID   string
+	conn *cdpConn
+}
+
+// NewPage opens a new page of the browser.
+func (s *Session) NewPage(ctx context.Context) (*Page, error) {
+	var target struct {
+		TargetID string `json:"targetId"`
+	}
+	if err := s.conn.call(ctx, "", "Target.createTarget", map[string]any{"url": "about:blank"}, &target); err != nil {
+		return nil, err
+	}
+	var attached struct {
+		SessionID string `json:"sessionId"`
+	}
+	if err := s.conn.call(ctx, "", "Target.attachToTarget", map[string]any{"targetId": target.TargetID, "flatten": true}, &attached); err != nil {
+		return nil, err
+	}
+	p := &Page{
+		conn:      s.conn,
+		targetID:  target.TargetID,
+		sessionID: attached.SessionID,
+	}
+	if err := p.call(ctx, "Page.enable", nil, nil); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Disconnect closes the connection to the session without closing the browser.
+//   - The browser is kept alive for the KeepAlive duration of LaunchOptions, and can be connected again.
+func (s *Session) Disconnect() error {
+	return s.conn.close()
+}
+
+// Close closes the browser and the connection.
+func (s *Session) Close(ctx context.Context) error {
+	err := s.conn.call(ctx, "", "Browser.close", nil, nil)
+	// the browser may close the connection before responding to the command.
+	if errors.Is(err, ErrClosed) {
+		err = nil
+	}
+	if closeErr := s.conn.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
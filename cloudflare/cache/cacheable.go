@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrMethodNotAllowed is returned when the request of Put is not GET.
+	ErrMethodNotAllowed = errors.New("cache: only GET requests can be used as cache keys")
+	// ErrPartialContent is returned when the response of Put has the status 206 Partial Content.
+	ErrPartialContent = errors.New("cache: 206 Partial Content response can't be cached")
+	// ErrVaryWildcard is returned when the response of Put has the `Vary: *` header.
+	ErrVaryWildcard = errors.New("cache: response with Vary: * can't be cached")
+	// ErrNotCacheable is returned when the response of Put is not stored by its headers.
+	// e.g. `Cache-Control: no-store`, `Cache-Control: private` or `Set-Cookie` without `Cache-Control: private=Set-Cookie`.
+	ErrNotCacheable = errors.New("cache: response is not cacheable by its headers")
+)
+
+// CheckCacheable reports whether the response can be stored by Put with the request as the key.
+//   - if the response can't be stored, returns one of ErrMethodNotAllowed, ErrPartialContent, ErrVaryWildcard and ErrNotCacheable.
+//   - docs: https://developers.cloudflare.com/workers/runtime-apis/cache/#headers
+func CheckCacheable(req *http.Request, res *http.Response) error {
+	if req.Method != "" && req.Method != http.MethodGet {
+		return ErrMethodNotAllowed
+	}
+	if res.StatusCode == http.StatusPartialContent {
+		return ErrPartialContent
+	}
+	for _, v := range res.Header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if strings.TrimSpace(field) == "*" {
+				return ErrVaryWildcard
+			}
+		}
+	}
+	directives := parseCacheControl(res.Header.Values("Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		return ErrNotCacheable
+	}
+	if private, ok := directives["private"]; ok {
+		// `private` without field names prevents caching of the whole response.
+		if private == "" {
+			return ErrNotCacheable
+		}
+	}
+	if res.Header.Get("Set-Cookie") != "" && !containsField(directives["private"], "Set-Cookie") {
+		return ErrNotCacheable
+	}
+	return nil
+}
+
+// parseCacheControl parses Cache-Control header values into directives and their arguments.
+func parseCacheControl(values []string) map[string]string {
+	directives := map[string]string{}
+	for _, v := range values {
+		for _, d := range splitDirectives(v) {
+			name, arg, _ := strings.Cut(d, "=")
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			directives[name] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return directives
+}
+
+// splitDirectives splits the Cache-Control header value by commas outside of quoted strings.
+func splitDirectives(v string) []string {
+	var (
+		directives []string
+		start      int
+		quoted     bool
+	)
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				directives = append(directives, v[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(directives, v[start:])
+}
+
+// containsField reports whether the comma-separated field names contain the field.
+func containsField(fields, field string) bool {
+	for _, f := range strings.Split(fields, ",") {
+		if strings.EqualFold(strings.TrimSpace(f), field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckCacheable(t *testing.T) {
+	tests := map[string]struct {
+		method string
+		status int
+		header http.Header
+		want   error
+	}{
+		"cacheable": {
+			header: http.Header{"Cache-Control": {"public, max-age=60"}},
+		},
+		"no headers": {
+			header: http.Header{},
+		},
+		"POST": {
+			method: http.MethodPost,
+			header: http.Header{},
+			want:   ErrMethodNotAllowed,
+		},
+		"partial content": {
+			status: http.StatusPartialContent,
+			header: http.Header{},
+			want:   ErrPartialContent,
+		},
+		"vary wildcard": {
+			header: http.Header{"Vary": {"Accept-Encoding, *"}},
+			want:   ErrVaryWildcard,
+		},
+		"vary fields": {
+			header: http.Header{"Vary": {"Accept-Encoding"}},
+		},
+		"no-store": {
+			header: http.Header{"Cache-Control": {"max-age=60, No-Store"}},
+			want:   ErrNotCacheable,
+		},
+		"private": {
+			header: http.Header{"Cache-Control": {"private"}},
+			want:   ErrNotCacheable,
+		},
+		"set-cookie": {
+			header: http.Header{"Set-Cookie": {"a=b"}},
+			want:   ErrNotCacheable,
+		},
+		"set-cookie with private field": {
+			header: http.Header{"Set-Cookie": {"a=b"}, "Cache-Control": {`max-age=60, private="Set-Cookie, X-Foo"`}},
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest(tc.method, "https://example.com/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := tc.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			res := &http.Response{StatusCode: status, Header: tc.header}
+			if err := CheckCacheable(req, res); !errors.Is(err, tc.want) {
+				t.Errorf("CheckCacheable() = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
+//go:build js && wasm
+
 package cache
 
 import (
+	"fmt"
 	"syscall/js"
 
 	"github.com/syumai/workers/internal/jsutil"
 )
 
-var cache = jsutil.Global.Get("caches")
+var cache = jsutil.LazyGlobal("caches")
 
 // Cache
 type Cache struct {
@@ -25,21 +28,36 @@ func (c *Cache) applyOptions(opts []CacheOption) {
 type CacheOption func(*Cache)
 
 // WithNamespace
+//
+// Deprecated: WithNamespace panics when the cache can't be opened. Use Open instead.
 func WithNamespace(namespace string) CacheOption {
 	return func(c *Cache) {
-		v, err := jsutil.AwaitPromise(cache.Call("open", namespace))
+		opened, err := Open(namespace)
 		if err != nil {
 			panic("failed to open cache")
 		}
-		c.instance = v
+		c.instance = opened.instance
 	}
 }
 
+// New returns the default cache (caches.default).
 func New(opts ...CacheOption) *Cache {
 	c := &Cache{
-		instance: cache.Get("default"),
+		instance: cache().Get("default"),
 	}
 	c.applyOptions(opts)
 
 	return c
 }
+
+// Open opens the named cache (caches.open).
+//   - Named caches are separated from the default cache and each other, so they can be used to segment caches per tenant or content type.
+//   - named caches are not shared with the cache used by fetch.
+//   - docs: https://developers.cloudflare.com/workers/runtime-apis/cache/#accessing-cache
+func Open(name string) (*Cache, error) {
+	v, err := jsutil.AwaitPromise(cache().Call("open", name))
+	if err != nil {
+		return nil, fmt.Errorf("cache: failed to open cache %q: %w", name, err)
+	}
+	return &Cache{instance: v}, nil
+}
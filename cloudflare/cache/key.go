@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// DefaultTrackingParams are query parameters which are commonly used for tracking and don't affect responses.
+// Names ending with `*` match parameters which have the prefix.
+var DefaultTrackingParams = []string{
+	"utm_*",
+	"gclid",
+	"fbclid",
+	"msclkid",
+	"yclid",
+	"mc_cid",
+	"mc_eid",
+	"_ga",
+}
+
+// KeyBuilder builds normalized cache keys of requests.
+// The built key is a URL, so it can be used as the key of the Cache API and `fetch.RequestInitCF`'s CacheKey.
+//
+//	builder := &cache.KeyBuilder{StripParams: cache.DefaultTrackingParams, SortQuery: true}
+//	mw := cache.Middleware(&cache.MiddlewareOptions{KeyFunc: builder.Key})
+type KeyBuilder struct {
+	// IgnoreQuery removes the whole query string from the key.
+	IgnoreQuery bool
+	// StripParams are query parameters removed from the key. Names ending with `*` match parameters which have the prefix.
+	StripParams []string
+	// SortQuery sorts query parameters by their names, so the order of parameters doesn't affect the key.
+	SortQuery bool
+	// Headers are request headers whose values are included in the key.
+	Headers []string
+	// Cookies are cookie names whose values are included in the key.
+	Cookies []string
+	// DeviceType includes the device type of the request (mobile, tablet or desktop) in the key.
+	DeviceType bool
+}
+
+// Key returns the cache key of the request.
+func (b *KeyBuilder) Key(req *http.Request) string {
+	u := *req.URL
+	u.Fragment = ""
+	q := u.Query()
+	switch {
+	case b.IgnoreQuery:
+		q = url.Values{}
+	case len(b.StripParams) > 0:
+		for name := range q {
+			if matchParam(b.StripParams, name) {
+				q.Del(name)
+			}
+		}
+	}
+	for _, name := range b.Cookies {
+		var value string
+		if c, err := req.Cookie(name); err == nil {
+			value = c.Value
+		}
+		q.Set("__c_"+name, value)
+	}
+	if b.DeviceType {
+		q.Set("__device", DeviceType(req))
+	}
+	if b.SortQuery || b.IgnoreQuery || len(b.StripParams) > 0 || len(b.Cookies) > 0 || b.DeviceType {
+		// url.Values.Encode sorts parameters by their names.
+		u.RawQuery = q.Encode()
+	}
+	if b.SortQuery && !b.IgnoreQuery {
+		u.RawQuery = sortQuery(u.RawQuery)
+	}
+	return varyKey(u.String(), req.Header, b.Headers)
+}
+
+// matchParam reports whether the name matches one of the patterns.
+func matchParam(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if prefix := strings.TrimSuffix(p, "*"); prefix != p {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+			continue
+		}
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortQuery sorts the query string by names and values, keeping the encoding of each parameter.
+func sortQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// Device types returned by DeviceType.
+const (
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeDesktop = "desktop"
+)
+
+// DeviceType returns the device type of the request.
+//   - if the `CF-Device-Type` header is set by Cloudflare's "Cache by Device Type" setting, returns its value.
+//   - Otherwise, the device type is detected from the User-Agent header.
+func DeviceType(req *http.Request) string {
+	if t := req.Header.Get("CF-Device-Type"); t != "" {
+		return t
+	}
+	ua := strings.ToLower(req.Header.Get("User-Agent"))
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet") ||
+		(strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return DeviceTypeTablet
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return DeviceTypeMobile
+	}
+	return DeviceTypeDesktop
+}
+
+// varyKey returns the key which includes values of the request headers.
+func varyKey(key string, header http.Header, varyHeaders []string) string {
+	if len(varyHeaders) == 0 {
+		return key
+	}
+	u, err := url.Parse(key)
+	if err != nil {
+		return key
+	}
+	names := make([]string, len(varyHeaders))
+	for i, h := range varyHeaders {
+		names[i] = http.CanonicalHeaderKey(h)
+	}
+	sort.Strings(names)
+	q := u.Query()
+	for _, name := range names {
+		q.Set("__h_"+strings.ToLower(name), strings.Join(header.Values(name), ","))
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
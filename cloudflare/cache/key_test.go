@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestKeyBuilder_Key(t *testing.T) {
+	tests := map[string]struct {
+		builder *KeyBuilder
+		url     string
+		header  http.Header
+		want    string
+	}{
+		"no options": {
+			builder: &KeyBuilder{},
+			url:     "https://example.com/a?b=2&a=1#f",
+			want:    "https://example.com/a?b=2&a=1",
+		},
+		"ignore query": {
+			builder: &KeyBuilder{IgnoreQuery: true},
+			url:     "https://example.com/a?b=2&a=1",
+			want:    "https://example.com/a",
+		},
+		"strip tracking params": {
+			builder: &KeyBuilder{StripParams: DefaultTrackingParams},
+			url:     "https://example.com/a?utm_source=x&utm_medium=y&gclid=z&id=1",
+			want:    "https://example.com/a?id=1",
+		},
+		"sort query": {
+			builder: &KeyBuilder{SortQuery: true},
+			url:     "https://example.com/a?b=2&a=1&a=0",
+			want:    "https://example.com/a?a=0&a=1&b=2",
+		},
+		"headers": {
+			builder: &KeyBuilder{Headers: []string{"Accept-Language"}},
+			url:     "https://example.com/a",
+			header:  http.Header{"Accept-Language": {"ja"}},
+			want:    "https://example.com/a?__h_accept-language=ja",
+		},
+		"cookies": {
+			builder: &KeyBuilder{Cookies: []string{"lang"}},
+			url:     "https://example.com/a",
+			header:  http.Header{"Cookie": {"session=s; lang=en"}},
+			want:    "https://example.com/a?__c_lang=en",
+		},
+		"device type": {
+			builder: &KeyBuilder{DeviceType: true},
+			url:     "https://example.com/a",
+			header:  http.Header{"User-Agent": {"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Mobile/15E148"}},
+			want:    "https://example.com/a?__device=mobile",
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.header != nil {
+				req.Header = tc.header
+			}
+			if got := tc.builder.Key(req); got != tc.want {
+				t.Errorf("Key() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDeviceType(t *testing.T) {
+	tests := map[string]struct {
+		header http.Header
+		want   string
+	}{
+		"cf-device-type": {
+			header: http.Header{"Cf-Device-Type": {"tablet"}, "User-Agent": {"iPhone"}},
+			want:   DeviceTypeTablet,
+		},
+		"android mobile": {
+			header: http.Header{"User-Agent": {"Mozilla/5.0 (Linux; Android 14) Mobile Safari/537.36"}},
+			want:   DeviceTypeMobile,
+		},
+		"android tablet": {
+			header: http.Header{"User-Agent": {"Mozilla/5.0 (Linux; Android 14) Safari/537.36"}},
+			want:   DeviceTypeTablet,
+		},
+		"ipad": {
+			header: http.Header{"User-Agent": {"Mozilla/5.0 (iPad; CPU OS 17_0 like Mac OS X)"}},
+			want:   DeviceTypeTablet,
+		},
+		"desktop": {
+			header: http.Header{"User-Agent": {"Mozilla/5.0 (Windows NT 10.0; Win64; x64)"}},
+			want:   DeviceTypeDesktop,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req := &http.Request{Header: tc.header}
+			if got := DeviceType(req); got != tc.want {
+				t.Errorf("DeviceType() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,8 @@
+//go:build js && wasm
+
 package cache
 
 import (
-	"errors"
 	"net/http"
 	"syscall/js"
 
@@ -10,12 +11,18 @@ import (
 )
 
 // Put attempts to add a response to the cache, using the given request as the key.
+// The cache key is the URL of the request. The body of the response is consumed by Put.
 // Returns an error for the following conditions
 // - the request passed is a method other than GET.
 // - the response passed has a status of 206 Partial Content.
+// - the response passed has the `Vary: *` header.
 // - Cache-Control instructs not to cache or if the response is too large.
+// The conditions except for the size are checked by CheckCacheable before calling the Cache API.
 // docs: https://developers.cloudflare.com/workers/runtime-apis/cache/#put
 func (c *Cache) Put(req *http.Request, res *http.Response) error {
+	if err := CheckCacheable(req, res); err != nil {
+		return err
+	}
 	_, err := jsutil.AwaitPromise(c.instance.Call("put", jshttp.ToJSRequest(req), jshttp.ToJSResponse(res)))
 	if err != nil {
 		return err
@@ -23,15 +30,6 @@ func (c *Cache) Put(req *http.Request, res *http.Response) error {
 	return nil
 }
 
-// ErrCacheNotFound is returned when there is no matching cache.
-var ErrCacheNotFound = errors.New("cache not found")
-
-// MatchOptions represents the options of the Match method.
-type MatchOptions struct {
-	// IgnoreMethod - Consider the request method a GET regardless of its actual value.
-	IgnoreMethod bool
-}
-
 // toJS converts MatchOptions to JS object.
 func (opts *MatchOptions) toJS() js.Value {
 	if opts == nil {
@@ -55,12 +53,6 @@ func (c *Cache) Match(req *http.Request, opts *MatchOptions) (*http.Response, er
 	return jshttp.ToResponse(res)
 }
 
-// DeleteOptions represents the options of the Delete method.
-type DeleteOptions struct {
-	// IgnoreMethod - Consider the request method a GET regardless of its actual value.
-	IgnoreMethod bool
-}
-
 // toJS converts DeleteOptions to JS object.
 func (opts *DeleteOptions) toJS() js.Value {
 	if opts == nil {
@@ -0,0 +1,282 @@
+//go:build js && wasm
+
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+// MiddlewareOptions represents the options of Middleware.
+type MiddlewareOptions struct {
+	// Cache is the cache used by the middleware. The default value is the default cache.
+	Cache *Cache
+	// KeyFunc returns the URL used as the cache key of the request. The default value is the URL of the request.
+	// `KeyBuilder.Key` can be used to normalize keys.
+	KeyFunc func(req *http.Request) string
+	// VaryHeaders are request headers included in the cache key.
+	// Responses which vary on headers other than VaryHeaders and `Accept-Encoding` are not cached.
+	VaryHeaders []string
+	// TTL overrides the Cache-Control header of stored responses by `public, max-age=TTL`.
+	// The response sent to the client is not changed. if TTL is 0, the Cache-Control header of the response is used.
+	TTL time.Duration
+	// StatusCodes are status codes of responses to be cached. The default value is [200].
+	StatusCodes []int
+	// MaxBodySize is the maximum size of response bodies to be cached. The default value is 10 MiB.
+	MaxBodySize int
+	// Staleness is how long stale responses are served. if Staleness is zero, `stale-while-revalidate` and `stale-if-error`
+	// of the Cache-Control header of the response are used.
+	Staleness Staleness
+	// StalenessFunc returns the staleness of the request, e.g. to use different windows per route. It overrides Staleness.
+	StalenessFunc func(req *http.Request) Staleness
+}
+
+const defaultMaxBodySize = 10 << 20
+
+// Middleware returns the middleware which serves responses from the cache, and stores responses of the handler to the cache.
+//   - Only GET requests are served from the cache.
+//   - Responses are stored after the response has been sent, by `cloudflare.WaitUntil`.
+//   - Responses which can't be stored by Put (see CheckCacheable) are not stored.
+//   - if the staleness is given, stale responses are served while they are refreshed in the background (stale-while-revalidate),
+//     or when the handler responds with 5xx errors (stale-if-error). Revalidations of the same key are not run concurrently in the isolate.
+//   - Responses of the handler are buffered while stale-if-error responses can be served, to decide which response is sent.
+func Middleware(opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOptions{}
+	}
+	if opts.Cache == nil {
+		return newMiddleware(New(), opts)
+	}
+	return newMiddleware(opts.Cache, opts)
+}
+
+// responseCache is the cache used by the middleware, which is replaced in tests.
+type responseCache interface {
+	Put(req *http.Request, res *http.Response) error
+	Match(req *http.Request, opts *MatchOptions) (*http.Response, error)
+}
+
+func newMiddleware(c responseCache, opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = func(req *http.Request) string {
+			return req.URL.String()
+		}
+	}
+	statusCodes := opts.StatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = []int{http.StatusOK}
+	}
+	maxBodySize := opts.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+	stalenessOf := func(req *http.Request, header http.Header) Staleness {
+		if opts.StalenessFunc != nil {
+			return opts.StalenessFunc(req)
+		}
+		if opts.Staleness != (Staleness{}) {
+			return opts.Staleness
+		}
+		return stalenessFromHeader(header)
+	}
+	// store returns the response to be stored for the recorded response, or nil if it can't be stored.
+	store := func(req, keyReq *http.Request, rec *recorder) *http.Response {
+		if rec.exceeded || !containsStatus(statusCodes, rec.statusCode) || !varyHandled(rec.Header(), opts.VaryHeaders) {
+			return nil
+		}
+		res := &http.Response{
+			StatusCode: rec.statusCode,
+			Header:     rec.Header().Clone(),
+			Body:       io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+		}
+		if err := CheckCacheable(keyReq, res); err != nil {
+			return nil
+		}
+		lifetime := freshLifetime(res.Header)
+		saveCacheControl(res.Header)
+		if opts.TTL > 0 {
+			res.Header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(opts.TTL/time.Second)))
+			lifetime = opts.TTL
+		}
+		markStale(res.Header, time.Now(), lifetime, stalenessOf(req, res.Header))
+		return res
+	}
+	var revalidating sync.Map
+	return func(next http.Handler) http.Handler {
+		// revalidate runs the handler in the background, and stores its response.
+		revalidate := func(req, keyReq *http.Request) {
+			if _, loaded := revalidating.LoadOrStore(keyReq.URL.String(), struct{}{}); loaded {
+				return
+			}
+			cloudflare.WaitUntil(req.Context(), func() {
+				defer revalidating.Delete(keyReq.URL.String())
+				rec := &recorder{ResponseWriter: discardWriter{header: http.Header{}}, statusCode: http.StatusOK, maxBodySize: maxBodySize}
+				next.ServeHTTP(rec, req)
+				if res := store(req, keyReq, rec); res != nil {
+					_ = c.Put(keyReq, res)
+				}
+			})
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodGet {
+				next.ServeHTTP(w, req)
+				return
+			}
+			keyReq, err := http.NewRequest(http.MethodGet, varyKey(keyFunc(req), req.Header, opts.VaryHeaders), nil)
+			if err != nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+			serve := func(w http.ResponseWriter) { next.ServeHTTP(w, req) }
+			if res, err := c.Match(keyReq, nil); err == nil {
+				switch stateOf(res.Header, time.Now()) {
+				case stateFresh:
+					writeResponse(w, res)
+					return
+				case stateRevalidate:
+					writeResponse(w, res)
+					revalidate(req, keyReq)
+					return
+				case stateStaleIfError:
+					buf := newBufferWriter()
+					next.ServeHTTP(buf, req)
+					if buf.statusCode >= http.StatusInternalServerError {
+						writeResponse(w, res)
+						return
+					}
+					res.Body.Close()
+					serve = buf.writeTo
+				default:
+					res.Body.Close()
+				}
+			}
+			rec := &recorder{ResponseWriter: w, statusCode: http.StatusOK, maxBodySize: maxBodySize}
+			serve(rec)
+			if res := store(req, keyReq, rec); res != nil {
+				cloudflare.WaitUntil(req.Context(), func() {
+					_ = c.Put(keyReq, res)
+				})
+			}
+		})
+	}
+}
+
+// bufferWriter is http.ResponseWriter which buffers the response, so it can be discarded or written later.
+type bufferWriter struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newBufferWriter() *bufferWriter {
+	return &bufferWriter{header: http.Header{}, statusCode: http.StatusOK}
+}
+
+func (b *bufferWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferWriter) WriteHeader(statusCode int) {
+	b.statusCode = statusCode
+}
+
+func (b *bufferWriter) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// writeTo writes the buffered response to w.
+func (b *bufferWriter) writeTo(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.statusCode)
+	w.Write(b.body.Bytes())
+}
+
+// discardWriter is http.ResponseWriter which discards the response, used with recorder for revalidations.
+type discardWriter struct {
+	header http.Header
+}
+
+func (d discardWriter) Header() http.Header {
+	return d.header
+}
+
+func (d discardWriter) WriteHeader(statusCode int) {}
+
+func (d discardWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+// recorder is http.ResponseWriter which records the response written to the underlying writer.
+type recorder struct {
+	http.ResponseWriter
+	statusCode  int
+	body        bytes.Buffer
+	maxBodySize int
+	// exceeded reports whether the body exceeded maxBodySize.
+	exceeded bool
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	if !r.exceeded {
+		if r.body.Len()+len(data) > r.maxBodySize {
+			r.exceeded = true
+			r.body.Reset()
+		} else {
+			r.body.Write(data)
+		}
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// writeResponse writes the cached response to w without the state of the entry.
+func writeResponse(w http.ResponseWriter, res *http.Response) {
+	defer res.Body.Close()
+	removeInternalHeaders(res.Header)
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, res.Body)
+}
+
+func containsStatus(statusCodes []int, statusCode int) bool {
+	for _, s := range statusCodes {
+		if s == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// varyHandled reports whether all headers in Vary of the response are included in the cache key.
+func varyHandled(header http.Header, varyHeaders []string) bool {
+	for _, v := range header.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" || strings.EqualFold(field, "Accept-Encoding") {
+				continue
+			}
+			if !containsField(strings.Join(varyHeaders, ","), field) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+//go:build js && wasm
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func TestVaryKey(t *testing.T) {
+	header := http.Header{"Accept-Language": {"ja"}, "X-Device": {"mobile"}}
+	tests := map[string]struct {
+		key         string
+		varyHeaders []string
+		want        string
+	}{
+		"no vary headers": {
+			key:  "https://example.com/a?b=c",
+			want: "https://example.com/a?b=c",
+		},
+		"vary headers": {
+			key:         "https://example.com/a?b=c",
+			varyHeaders: []string{"x-device", "Accept-Language"},
+			want:        "https://example.com/a?__h_accept-language=ja&__h_x-device=mobile&b=c",
+		},
+		"missing header": {
+			key:         "https://example.com/a",
+			varyHeaders: []string{"Cookie"},
+			want:        "https://example.com/a?__h_cookie=",
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := varyKey(tc.key, header, tc.varyHeaders); got != tc.want {
+				t.Errorf("varyKey() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVaryHandled(t *testing.T) {
+	tests := map[string]struct {
+		vary        []string
+		varyHeaders []string
+		want        bool
+	}{
+		"no vary": {
+			want: true,
+		},
+		"accept-encoding": {
+			vary: []string{"Accept-Encoding"},
+			want: true,
+		},
+		"handled": {
+			vary:        []string{"Accept-Encoding, accept-language"},
+			varyHeaders: []string{"Accept-Language"},
+			want:        true,
+		},
+		"not handled": {
+			vary: []string{"Accept-Language"},
+			want: false,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			header := http.Header{"Vary": tc.vary}
+			if got := varyHandled(header, tc.varyHeaders); got != tc.want {
+				t.Errorf("varyHandled() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// memoryCache is responseCache which stores responses in memory.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*http.Response
+	bodies  map[string][]byte
+	put     chan struct{}
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: map[string]*http.Response{}, bodies: map[string][]byte{}, put: make(chan struct{}, 10)}
+}
+
+func (c *memoryCache) Put(req *http.Request, res *http.Response) error {
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.entries[req.URL.String()] = res
+	c.bodies[req.URL.String()] = b
+	c.mu.Unlock()
+	c.put <- struct{}{}
+	return nil
+}
+
+func (c *memoryCache) Match(req *http.Request, opts *MatchOptions) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res, ok := c.entries[req.URL.String()]
+	if !ok {
+		return nil, ErrCacheNotFound
+	}
+	return &http.Response{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(c.bodies[req.URL.String()])),
+	}, nil
+}
+
+// runtimeContext returns the context of the runtime whose waitUntil does nothing, since tasks are run by goroutines.
+func runtimeContext() context.Context {
+	exCtx := jsutil.NewObject()
+	exCtx.Set("waitUntil", js.FuncOf(func(this js.Value, args []js.Value) any { return js.Undefined() }))
+	obj := jsutil.NewObject()
+	obj.Set("ctx", exCtx)
+	return runtimecontext.New(context.Background(), obj)
+}
+
+func TestMiddlewareCacheControlOnHit(t *testing.T) {
+	tests := map[string]struct {
+		opts *MiddlewareOptions
+		cc   string
+	}{
+		"ttl":           {opts: &MiddlewareOptions{TTL: time.Hour}, cc: "no-cache"},
+		"ttl/no header": {opts: &MiddlewareOptions{TTL: time.Hour}},
+		"ttl/staleness": {opts: &MiddlewareOptions{TTL: time.Hour, Staleness: Staleness{WhileRevalidate: time.Minute}}, cc: "max-age=10"},
+		"header":        {opts: &MiddlewareOptions{}, cc: "public, max-age=60"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newMemoryCache()
+			calls := 0
+			handler := newMiddleware(c, tt.opts)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls++
+				if tt.cc != "" {
+					w.Header().Set("Cache-Control", tt.cc)
+				}
+				w.Write([]byte("body"))
+			}))
+			serve := func() *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://example.com/", nil).WithContext(runtimeContext()))
+				return rec
+			}
+			if rec := serve(); rec.Header().Get("Cache-Control") != tt.cc {
+				t.Fatalf("response of the handler: Cache-Control %q", rec.Header().Get("Cache-Control"))
+			}
+			select {
+			case <-c.put:
+			case <-time.After(time.Second):
+				t.Fatal("response is not stored")
+			}
+			rec := serve()
+			if calls != 1 || rec.Body.String() != "body" {
+				t.Fatalf("want cache hit, got %d calls, body %q", calls, rec.Body.String())
+			}
+			if got := rec.Header().Values("Cache-Control"); len(got) > 1 || rec.Header().Get("Cache-Control") != tt.cc {
+				t.Errorf("Cache-Control of cache hit: %q, want %q", got, tt.cc)
+			}
+			for key := range rec.Header() {
+				if strings.HasPrefix(key, internalHeaderPrefix) {
+					t.Errorf("internal header is sent: %s", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package cache
+
+import "errors"
+
+// ErrCacheNotFound is returned when there is no matching cache.
+var ErrCacheNotFound = errors.New("cache not found")
+
+// MatchOptions represents the options of the Match method.
+type MatchOptions struct {
+	// IgnoreMethod - Consider the request method a GET regardless of its actual value.
+	IgnoreMethod bool
+}
+
+// DeleteOptions represents the options of the Delete method.
+type DeleteOptions struct {
+	// IgnoreMethod - Consider the request method a GET regardless of its actual value.
+	IgnoreMethod bool
+}
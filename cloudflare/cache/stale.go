@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Staleness represents how long responses are served from the cache after they become stale.
+//   - Responses are stored until the end of the longest window, and served by the state of the entry.
+//   - https://datatracker.ietf.org/doc/html/rfc5861
+type Staleness struct {
+	// WhileRevalidate is the duration for which stale responses are served immediately,
+	// while the handler refreshes the cache in the background.
+	WhileRevalidate time.Duration
+	// IfError is the duration for which stale responses are served when the handler responds with 5xx errors.
+	IfError time.Duration
+}
+
+// Headers of stored responses, which hold the state of the entry. They are removed from responses served from the cache.
+const (
+	internalHeaderPrefix       = "X-Workers-Cache-"
+	freshUntilHeader           = internalHeaderPrefix + "Fresh-Until"
+	revalidateUntilHeader      = internalHeaderPrefix + "Revalidate-Until"
+	staleIfErrorUntilHeader    = internalHeaderPrefix + "Stale-If-Error-Until"
+	originalCacheControlHeader = internalHeaderPrefix + "Original-Cache-Control"
+)
+
+// entryState represents the state of the stored response.
+type entryState int
+
+const (
+	stateFresh entryState = iota
+	// stateRevalidate is served while the handler refreshes the response in the background.
+	stateRevalidate
+	// stateStaleIfError is served only if the handler fails.
+	stateStaleIfError
+	// stateExpired is not served.
+	stateExpired
+)
+
+// stalenessFromHeader returns the staleness given by `stale-while-revalidate` and `stale-if-error` of the Cache-Control header.
+func stalenessFromHeader(header http.Header) Staleness {
+	directives := parseCacheControl(header.Values("Cache-Control"))
+	return Staleness{
+		WhileRevalidate: directiveSeconds(directives, "stale-while-revalidate"),
+		IfError:         directiveSeconds(directives, "stale-if-error"),
+	}
+}
+
+// freshLifetime returns the freshness lifetime of the response by `s-maxage` or `max-age`. It returns 0 if not given.
+func freshLifetime(header http.Header) time.Duration {
+	directives := parseCacheControl(header.Values("Cache-Control"))
+	if d := directiveSeconds(directives, "s-maxage"); d > 0 {
+		return d
+	}
+	return directiveSeconds(directives, "max-age")
+}
+
+func directiveSeconds(directives map[string]string, name string) time.Duration {
+	sec, err := strconv.Atoi(directives[name])
+	if err != nil || sec <= 0 {
+		return 0
+	}
+	return time.Duration(sec) * time.Second
+}
+
+// saveCacheControl saves the Cache-Control header given by the handler, which is restored by removeInternalHeaders
+// when the response is served from the cache. It must be called before the header is overridden for the cache.
+func saveCacheControl(header http.Header) {
+	header.Set(originalCacheControlHeader, strings.Join(header.Values("Cache-Control"), ", "))
+}
+
+// markStale sets the state of the entry to the header of the response to be stored, and extends its max-age to the end of the windows.
+//   - if the staleness is zero, the header is not changed and returns false.
+//   - The Cache-Control header given by the handler must be saved by saveCacheControl before markStale is called.
+func markStale(header http.Header, now time.Time, lifetime time.Duration, s Staleness) bool {
+	if lifetime <= 0 || (s.WhileRevalidate <= 0 && s.IfError <= 0) {
+		return false
+	}
+	freshUntil := now.Add(lifetime)
+	storedFor := lifetime
+	if s.WhileRevalidate > 0 {
+		header.Set(revalidateUntilHeader, formatUnix(freshUntil.Add(s.WhileRevalidate)))
+		storedFor = lifetime + s.WhileRevalidate
+	}
+	if s.IfError > 0 {
+		header.Set(staleIfErrorUntilHeader, formatUnix(freshUntil.Add(s.IfError)))
+		if lifetime+s.IfError > storedFor {
+			storedFor = lifetime + s.IfError
+		}
+	}
+	header.Set(freshUntilHeader, formatUnix(freshUntil))
+	header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(storedFor/time.Second)))
+	return true
+}
+
+// stateOf returns the state of the stored response at now.
+//   - Responses stored without staleness are always fresh, since the Cache API removes them when they expire.
+func stateOf(header http.Header, now time.Time) entryState {
+	freshUntil, ok := parseUnix(header.Get(freshUntilHeader))
+	if !ok || now.Before(freshUntil) {
+		return stateFresh
+	}
+	if t, ok := parseUnix(header.Get(revalidateUntilHeader)); ok && now.Before(t) {
+		return stateRevalidate
+	}
+	if t, ok := parseUnix(header.Get(staleIfErrorUntilHeader)); ok && now.Before(t) {
+		return stateStaleIfError
+	}
+	return stateExpired
+}
+
+// removeInternalHeaders restores the Cache-Control header of the stored response, and removes the state of the entry.
+func removeInternalHeaders(header http.Header) {
+	if _, ok := header[originalCacheControlHeader]; ok {
+		if cc := header.Get(originalCacheControlHeader); cc != "" {
+			header.Set("Cache-Control", cc)
+		} else {
+			header.Del("Cache-Control")
+		}
+	}
+	for key := range header {
+		if strings.HasPrefix(key, internalHeaderPrefix) {
+			delete(header, key)
+		}
+	}
+}
+
+func formatUnix(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func parseUnix(s string) (time.Time, bool) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStateOf(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	header := http.Header{"Cache-Control": {"max-age=60"}}
+	saveCacheControl(header)
+	if !markStale(header, now, time.Minute, Staleness{WhileRevalidate: time.Minute, IfError: time.Hour}) {
+		t.Fatal("markStale returned false")
+	}
+	if cc := header.Get("Cache-Control"); cc != "public, max-age=3660" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+	tests := []struct {
+		after time.Duration
+		want  entryState
+	}{
+		{30 * time.Second, stateFresh},
+		{90 * time.Second, stateRevalidate},
+		{30 * time.Minute, stateStaleIfError},
+		{2 * time.Hour, stateExpired},
+	}
+	for _, tt := range tests {
+		if got := stateOf(header, now.Add(tt.after)); got != tt.want {
+			t.Errorf("stateOf(+%v) = %v, want %v", tt.after, got, tt.want)
+		}
+	}
+	removeInternalHeaders(header)
+	if len(header) != 1 || header.Get("Cache-Control") != "max-age=60" {
+		t.Errorf("unexpected header: %v", header)
+	}
+	if got := stateOf(header, now.Add(2*time.Hour)); got != stateFresh {
+		t.Errorf("entries without staleness must be fresh, got %v", got)
+	}
+}
+
+func TestMarkStaleWithoutStaleness(t *testing.T) {
+	header := http.Header{"Cache-Control": {"max-age=60"}}
+	if markStale(header, time.Now(), time.Minute, Staleness{}) {
+		t.Error("markStale without staleness returned true")
+	}
+	if markStale(header, time.Now(), 0, Staleness{WhileRevalidate: time.Minute}) {
+		t.Error("markStale without lifetime returned true")
+	}
+	if len(header) != 1 {
+		t.Errorf("header is changed: %v", header)
+	}
+}
+
+func TestStalenessFromHeader(t *testing.T) {
+	header := http.Header{"Cache-Control": {"public, s-maxage=30, max-age=10, stale-while-revalidate=60, stale-if-error=86400"}}
+	if got := freshLifetime(header); got != 30*time.Second {
+		t.Errorf("freshLifetime = %v", got)
+	}
+	want := Staleness{WhileRevalidate: time.Minute, IfError: 24 * time.Hour}
+	if got := stalenessFromHeader(header); got != want {
+		t.Errorf("stalenessFromHeader = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,330 @@
+// Package circuitbreaker stops sending subrequests to failing upstreams.
+//
+// Transport counts consecutive failures of subrequests per key (the host by default), and opens the circuit
+// when they reach FailureThreshold. While the circuit is open, requests fail with ErrOpen without being sent.
+// After OpenTimeout, one probe request is sent (half-open), and its result closes or opens the circuit again.
+//   - Circuits are held by Store (a Durable Object or the Cache API), since the state of the Go program doesn't outlive
+//     the request. Failures of all requests are counted together, and all requests stop sending requests to the upstream
+//     when the circuit is opened.
+//   - Transport caches the circuit loaded from Store for SyncInterval, which matters to requests sending many subrequests.
+//   - Trips and rejections can be counted by metrics, e.g. `registry.Counter("circuit_breaker_trips")`.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by Transport when the circuit of the request is open.
+var ErrOpen = errors.New("circuitbreaker: circuit is open")
+
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultSyncInterval     = 5 * time.Second
+)
+
+// Counter counts events with the key of the circuit as the label. It is implemented by *metrics.Counter.
+type Counter interface {
+	Inc(labels ...string)
+}
+
+// State represents the state of circuits.
+type State int
+
+const (
+	// StateClosed sends requests.
+	StateClosed State = iota
+	// StateOpen rejects requests until OpenTimeout elapses.
+	StateOpen
+	// StateHalfOpen sends one probe request, and rejects others.
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// Circuit represents the circuit of the key held by Store.
+type Circuit struct {
+	// Failures is the number of consecutive failures.
+	Failures int
+	// OpenUntil is the time until which the circuit is open. if the circuit is closed, OpenUntil is the zero time.
+	OpenUntil time.Time
+}
+
+// Store holds circuits across requests.
+//   - Errors of Store are ignored, and Transport continues with the circuit cached by it.
+type Store interface {
+	// Get returns the circuit of the key.
+	Get(ctx context.Context, key string) (*Circuit, error)
+	// Fail counts a failure of the key, and returns the number of consecutive failures.
+	Fail(ctx context.Context, key string) (int, error)
+	// Open opens the circuit of the key until the time, and resets its failures.
+	Open(ctx context.Context, key string, until time.Time) error
+	// Close closes the circuit of the key, and resets its failures.
+	Close(ctx context.Context, key string) error
+}
+
+// Options represents the options of Transport.
+type Options struct {
+	// Base sends requests. The default sends requests by fetch.
+	Base http.RoundTripper
+	// Store holds circuits across requests. Store is required.
+	Store Store
+	// SyncInterval is the interval to load circuits from Store. The default is DefaultSyncInterval.
+	SyncInterval time.Duration
+	// KeyFunc returns the key of the circuit of the request. The default is the host of the URL.
+	KeyFunc func(req *http.Request) string
+	// FailureThreshold is the number of consecutive failures which opens the circuit. The default is DefaultFailureThreshold.
+	FailureThreshold int
+	// OpenTimeout is the duration for which the circuit is open before the probe request. The default is DefaultOpenTimeout.
+	OpenTimeout time.Duration
+	// IsFailure reports whether the result of the request is a failure. The default reports errors and 5xx responses.
+	IsFailure func(res *http.Response, err error) bool
+	// OnStateChange is called when the state of the circuit seen by the Transport changes,
+	// including changes loaded from Store.
+	OnStateChange func(key string, from, to State)
+	// Trips counts circuits opened by the Transport. Circuits loaded from Store are not counted.
+	Trips Counter
+	// Rejections counts requests rejected by open circuits.
+	Rejections Counter
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Transport is http.RoundTripper which rejects requests while their circuits are open.
+type Transport struct {
+	opts Options
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// circuit is the state of the key cached by the Transport.
+type circuit struct {
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openUntil time.Time
+	// probing reports whether the probe request of the half-open circuit is in flight.
+	probing  bool
+	syncing  bool
+	syncedAt time.Time
+}
+
+// NewTransport returns Transport.
+//   - This panics if Store is not set.
+func NewTransport(opts *Options) *Transport {
+	if opts == nil || opts.Store == nil {
+		panic("circuitbreaker: Store of Options must be set")
+	}
+	t := &Transport{opts: *opts, circuits: map[string]*circuit{}}
+	if t.opts.Base == nil {
+		t.opts.Base = defaultTransport()
+	}
+	if t.opts.SyncInterval <= 0 {
+		t.opts.SyncInterval = DefaultSyncInterval
+	}
+	if t.opts.KeyFunc == nil {
+		t.opts.KeyFunc = func(req *http.Request) string {
+			return req.URL.Host
+		}
+	}
+	if t.opts.FailureThreshold <= 0 {
+		t.opts.FailureThreshold = DefaultFailureThreshold
+	}
+	if t.opts.OpenTimeout <= 0 {
+		t.opts.OpenTimeout = DefaultOpenTimeout
+	}
+	if t.opts.IsFailure == nil {
+		t.opts.IsFailure = func(res *http.Response, err error) bool {
+			return err != nil || res.StatusCode >= http.StatusInternalServerError
+		}
+	}
+	if t.opts.Now == nil {
+		t.opts.Now = time.Now
+	}
+	return t
+}
+
+// RoundTrip sends the request if its circuit is closed, or returns ErrOpen.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	key := t.opts.KeyFunc(req)
+	c := t.circuit(key)
+	t.sync(ctx, key, c)
+	probe, ok := t.allow(key, c)
+	if !ok {
+		if t.opts.Rejections != nil {
+			t.opts.Rejections.Inc(key)
+		}
+		return nil, ErrOpen
+	}
+	res, err := t.opts.Base.RoundTrip(req)
+	if errors.Is(err, context.Canceled) {
+		// canceled requests are not the failures of the upstream.
+		if probe {
+			c.mu.Lock()
+			c.probing = false
+			c.mu.Unlock()
+		}
+		return res, err
+	}
+	t.record(ctx, key, c, probe, t.opts.IsFailure(res, err))
+	return res, err
+}
+
+// State returns the state of the circuit of the key seen by the Transport, which may be behind Store by SyncInterval.
+func (t *Transport) State(key string) State {
+	c := t.circuit(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == StateOpen && !t.opts.Now().Before(c.openUntil) {
+		return StateHalfOpen
+	}
+	return c.state
+}
+
+func (t *Transport) circuit(key string) *circuit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c, ok := t.circuits[key]
+	if !ok {
+		c = &circuit{}
+		t.circuits[key] = c
+	}
+	return c
+}
+
+// sync loads the circuit from Store. Only one request of the key loads the circuit at a time.
+func (t *Transport) sync(ctx context.Context, key string, c *circuit) {
+	now := t.opts.Now()
+	c.mu.Lock()
+	due := !c.syncing && now.Sub(c.syncedAt) >= t.opts.SyncInterval
+	if due {
+		c.syncing = true
+	}
+	c.mu.Unlock()
+	if !due {
+		return
+	}
+	shared, err := t.opts.Store.Get(ctx, key)
+	c.mu.Lock()
+	c.syncing = false
+	c.syncedAt = now
+	var from State
+	changed := false
+	switch {
+	case err != nil:
+	case now.Before(shared.OpenUntil) && (c.state == StateClosed || c.openUntil.Before(shared.OpenUntil)):
+		from, changed = c.state, c.state != StateOpen
+		c.state = StateOpen
+		c.openUntil = shared.OpenUntil
+		c.failures = 0
+	case c.state == StateClosed:
+		c.failures = shared.Failures
+	}
+	c.mu.Unlock()
+	if changed {
+		t.notify(key, from, StateOpen)
+	}
+}
+
+// allow reports whether the request can be sent, and whether it's the probe of the half-open circuit.
+func (t *Transport) allow(key string, c *circuit) (probe, ok bool) {
+	c.mu.Lock()
+	var from State
+	switch c.state {
+	case StateClosed:
+		c.mu.Unlock()
+		return false, true
+	case StateOpen:
+		if t.opts.Now().Before(c.openUntil) {
+			c.mu.Unlock()
+			return false, false
+		}
+		from = c.state
+		c.state = StateHalfOpen
+	}
+	if c.probing {
+		c.mu.Unlock()
+		return false, false
+	}
+	c.probing = true
+	c.mu.Unlock()
+	if from == StateOpen {
+		t.notify(key, StateOpen, StateHalfOpen)
+	}
+	return true, true
+}
+
+// record updates the circuit by the result of the request. Failures are counted by Store, so the circuit is opened
+// by failures of all requests.
+func (t *Transport) record(ctx context.Context, key string, c *circuit, probe, failure bool) {
+	if !failure {
+		c.mu.Lock()
+		from, failures := c.state, c.failures
+		c.failures = 0
+		if probe {
+			c.probing = false
+			c.state = StateClosed
+		}
+		c.mu.Unlock()
+		if !probe && failures == 0 {
+			return
+		}
+		t.notify(key, from, StateClosed)
+		waitUntil(ctx, func() {
+			_ = t.opts.Store.Close(context.Background(), key)
+		})
+		return
+	}
+	if probe {
+		c.mu.Lock()
+		c.probing = false
+	} else {
+		failures, err := t.opts.Store.Fail(ctx, key)
+		c.mu.Lock()
+		if err != nil {
+			failures = c.failures + 1
+		}
+		c.failures = failures
+		if c.state != StateClosed || failures < t.opts.FailureThreshold {
+			c.mu.Unlock()
+			return
+		}
+	}
+	from := c.state
+	c.state = StateOpen
+	c.failures = 0
+	until := t.opts.Now().Add(t.opts.OpenTimeout)
+	c.openUntil = until
+	c.mu.Unlock()
+	if t.opts.Trips != nil {
+		t.opts.Trips.Inc(key)
+	}
+	t.notify(key, from, StateOpen)
+	waitUntil(ctx, func() {
+		_ = t.opts.Store.Open(context.Background(), key, until)
+	})
+}
+
+func (t *Transport) notify(key string, from, to State) {
+	if t.opts.OnStateChange != nil && from != to {
+		t.opts.OnStateChange(key, from, to)
+	}
+}
@@ -0,0 +1,265 @@
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// upstream responds with the status held by it, and counts requests.
+type upstream struct {
+	mu       sync.Mutex
+	status   int
+	requests int
+}
+
+func (u *upstream) RoundTrip(req *http.Request) (*http.Response, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.requests++
+	return &http.Response{StatusCode: u.status, Body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+type fakeCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (c *fakeCounter) Inc(labels ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = map[string]int{}
+	}
+	c.counts[strings.Join(labels, ",")]++
+}
+
+func send(tr http.RoundTripper) error {
+	req := httptest.NewRequest(http.MethodGet, "https://api.example.com/", nil)
+	res, err := tr.RoundTrip(req)
+	if err == nil {
+		res.Body.Close()
+	}
+	return err
+}
+
+func TestTransport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	up := &upstream{status: http.StatusInternalServerError}
+	trips, rejections := &fakeCounter{}, &fakeCounter{}
+	var changes []string
+	tr := NewTransport(&Options{
+		Base:             up,
+		Store:            newStore(),
+		FailureThreshold: 3,
+		OpenTimeout:      time.Minute,
+		Trips:            trips,
+		Rejections:       rejections,
+		OnStateChange: func(key string, from, to State) {
+			changes = append(changes, from.String()+">"+to.String())
+		},
+		Now: func() time.Time { return now },
+	})
+	for i := 0; i < 3; i++ {
+		if err := send(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := tr.State("api.example.com"); got != StateOpen {
+		t.Fatalf("state = %v, want open", got)
+	}
+	if err := send(tr); !errors.Is(err, ErrOpen) {
+		t.Errorf("want ErrOpen, got %v", err)
+	}
+	if up.requests != 3 {
+		t.Errorf("requests = %d, want 3", up.requests)
+	}
+
+	// the failed probe opens the circuit again.
+	now = now.Add(time.Minute)
+	send(tr)
+	if got := tr.State("api.example.com"); got != StateOpen {
+		t.Errorf("state = %v, want open", got)
+	}
+
+	// the successful probe closes the circuit.
+	now = now.Add(time.Minute)
+	up.status = http.StatusOK
+	send(tr)
+	if got := tr.State("api.example.com"); got != StateClosed {
+		t.Errorf("state = %v, want closed", got)
+	}
+	if trips.counts["api.example.com"] != 2 || rejections.counts["api.example.com"] != 1 {
+		t.Errorf("trips = %v, rejections = %v", trips.counts, rejections.counts)
+	}
+	want := "closed>open open>half-open half-open>open open>half-open half-open>closed"
+	if got := strings.Join(changes, " "); got != want {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+}
+
+func TestHalfOpenSingleProbe(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	failing := true
+	tr := NewTransport(&Options{
+		Base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if failing {
+				return nil, errors.New("connection refused")
+			}
+			started <- struct{}{}
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		Store:            newStore(),
+		FailureThreshold: 1,
+		Now:              func() time.Time { return now },
+	})
+	send(tr)
+	failing = false
+	now = now.Add(DefaultOpenTimeout)
+	done := make(chan error)
+	go func() { done <- send(tr) }()
+	<-started
+	if err := send(tr); !errors.Is(err, ErrOpen) {
+		t.Errorf("want ErrOpen while probing, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("probe: %v", err)
+	}
+}
+
+func newStore() Store {
+	return DurableObjectStore(bindingtest.NewDurableObjectNamespace(func(id binding.DurableObjectID) http.Handler {
+		return NewDurableObject()
+	}))
+}
+
+func testStores() map[string]Store {
+	return map[string]Store{
+		"durable object": newStore(),
+		"cache":          CacheStore(bindingtest.NewCache()),
+	}
+}
+
+// waitOpen waits for the circuit to be opened in Store, since it's stored in the background.
+func waitOpen(t *testing.T, name string, s Store) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if c, _ := s.Get(context.Background(), "api.example.com"); c != nil && !c.OpenUntil.IsZero() {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s: circuit is not stored", name)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNewTransportWithoutStore(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewTransport must panic without Store")
+		}
+	}()
+	NewTransport(&Options{})
+}
+
+func TestStores(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores() {
+		c, err := s.Get(ctx, "k")
+		if err != nil || c.Failures != 0 || !c.OpenUntil.IsZero() {
+			t.Errorf("%s: Get = %+v, %v", name, c, err)
+		}
+		for i := 1; i <= 2; i++ {
+			if failures, err := s.Fail(ctx, "k"); err != nil || failures != i {
+				t.Errorf("%s: Fail = %d, %v, want %d", name, failures, err, i)
+			}
+		}
+		want := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+		if err := s.Open(ctx, "k", want); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c, err := s.Get(ctx, "k"); err != nil || c.Failures != 0 || !c.OpenUntil.Equal(want) {
+			t.Errorf("%s: Get = %+v, %v, want %v", name, c, err, want)
+		}
+		s.Fail(ctx, "k")
+		if err := s.Close(ctx, "k"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if c, err := s.Get(ctx, "k"); err != nil || c.Failures != 0 || !c.OpenUntil.IsZero() {
+			t.Errorf("%s: Get after Close = %+v, %v", name, c, err)
+		}
+	}
+}
+
+func TestSharedCircuit(t *testing.T) {
+	for name, s := range testStores() {
+		up := &upstream{status: http.StatusBadGateway}
+		first := NewTransport(&Options{Base: up, Store: s, FailureThreshold: 1})
+		send(first)
+		waitOpen(t, name, s)
+		second := NewTransport(&Options{Base: up, Store: s})
+		if err := send(second); !errors.Is(err, ErrOpen) {
+			t.Errorf("%s: want ErrOpen from the shared circuit, got %v", name, err)
+		}
+		if up.requests != 1 {
+			t.Errorf("%s: requests = %d, want 1", name, up.requests)
+		}
+	}
+}
+
+func TestFailuresAcrossRequests(t *testing.T) {
+	for name, s := range testStores() {
+		up := &upstream{status: http.StatusBadGateway}
+		// each request of Workers runs its own Transport, so failures are counted by Store.
+		for i := 0; i < 3; i++ {
+			send(NewTransport(&Options{Base: up, Store: s, FailureThreshold: 3}))
+		}
+		waitOpen(t, name, s)
+		if err := send(NewTransport(&Options{Base: up, Store: s, FailureThreshold: 3})); !errors.Is(err, ErrOpen) {
+			t.Errorf("%s: want ErrOpen, got %v", name, err)
+		}
+		if up.requests != 3 {
+			t.Errorf("%s: requests = %d, want 3", name, up.requests)
+		}
+	}
+}
+
+func TestSuccessResetsFailures(t *testing.T) {
+	s := newStore()
+	up := &upstream{status: http.StatusBadGateway}
+	send(NewTransport(&Options{Base: up, Store: s}))
+	up.status = http.StatusOK
+	send(NewTransport(&Options{Base: up, Store: s}))
+	// failures are reset in the background.
+	deadline := time.Now().Add(time.Second)
+	for {
+		if c, _ := s.Get(context.Background(), "api.example.com"); c != nil && c.Failures == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("failures are not reset")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,245 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/cache"
+)
+
+// DurableObjectStore returns Store which holds circuits by Durable Objects of the namespace, one object per key.
+//   - The class of the namespace must be implemented by NewDurableObject.
+//   - Circuits are shared by all isolates globally.
+func DurableObjectStore(ns binding.DurableObjectNamespace) Store {
+	return &durableObjectStore{ns: ns}
+}
+
+type durableObjectStore struct {
+	ns binding.DurableObjectNamespace
+}
+
+// circuitState is the JSON exchanged with the Durable Object.
+type circuitState struct {
+	// OpenUntil is the time in milliseconds since the Unix epoch. 0 means the circuit is closed.
+	OpenUntil int64 `json:"openUntil"`
+	Failures  int   `json:"failures"`
+}
+
+func (s *circuitState) circuit() *Circuit {
+	return &Circuit{Failures: s.Failures, OpenUntil: fromUnixMilli(s.OpenUntil)}
+}
+
+func (s *durableObjectStore) fetch(ctx context.Context, key, method string, body *circuitState) (*circuitState, error) {
+	stub, err := s.ns.Get(s.ns.IdFromName(key))
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader
+	if body != nil {
+		b, _ := json.Marshal(body)
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "https://circuitbreaker/", r)
+	if err != nil {
+		return nil, err
+	}
+	res, err := stub.Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("circuitbreaker: error fetching Durable Object: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("circuitbreaker: unexpected status of Durable Object: %s", res.Status)
+	}
+	var state circuitState
+	if err := json.NewDecoder(res.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("circuitbreaker: error decoding state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *durableObjectStore) Get(ctx context.Context, key string) (*Circuit, error) {
+	state, err := s.fetch(ctx, key, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	return state.circuit(), nil
+}
+
+func (s *durableObjectStore) Fail(ctx context.Context, key string) (int, error) {
+	state, err := s.fetch(ctx, key, http.MethodPost, nil)
+	if err != nil {
+		return 0, err
+	}
+	return state.Failures, nil
+}
+
+func (s *durableObjectStore) Open(ctx context.Context, key string, until time.Time) error {
+	_, err := s.fetch(ctx, key, http.MethodPut, &circuitState{OpenUntil: until.UnixMilli()})
+	return err
+}
+
+func (s *durableObjectStore) Close(ctx context.Context, key string) error {
+	_, err := s.fetch(ctx, key, http.MethodDelete, nil)
+	return err
+}
+
+// NewDurableObject returns the handler of the Durable Object class used by DurableObjectStore.
+//
+//   - The circuit is held in memory of the object, since it's only valid for OpenTimeout.
+//     if the object is evicted, the circuit is closed and its failures are reset.
+//
+//     durableobject.Register("CircuitBreaker", func(ctx context.Context, state *durableobject.State) durableobject.DurableObject {
+//     return circuitbreaker.NewDurableObject()
+//     })
+func NewDurableObject() http.Handler {
+	return &durableObject{}
+}
+
+type durableObject struct {
+	mu    sync.Mutex
+	state circuitState
+}
+
+func (o *durableObject) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		o.state.Failures++
+	case http.MethodPut:
+		var state circuitState
+		if err := json.NewDecoder(req.Body).Decode(&state); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// the later end wins, so concurrent trips don't shorten the circuit.
+		if state.OpenUntil > o.state.OpenUntil {
+			o.state = circuitState{OpenUntil: state.OpenUntil}
+		}
+	case http.MethodDelete:
+		o.state = circuitState{}
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&o.state)
+}
+
+// CacheStore returns Store which holds circuits by the Cache API (e.g. `cache.New()`).
+//   - The Cache API is local to the data center, so circuits are shared only by requests in the same data center.
+//     Use it when Durable Objects are not available.
+//   - Failures are counted by reading and writing the entry, so concurrent failures may be counted once.
+//     Failures are reset after CacheFailureTTL without failures.
+func CacheStore(c binding.Cache) Store {
+	return &cacheStore{cache: c}
+}
+
+// CacheFailureTTL is the duration for which failures are held by CacheStore.
+const CacheFailureTTL = 10 * time.Minute
+
+type cacheStore struct {
+	cache binding.Cache
+}
+
+func cacheKey(ctx context.Context, key string) (*http.Request, error) {
+	return http.NewRequestWithContext(ctx, http.MethodGet, "https://circuitbreaker.internal/"+url.PathEscape(key), nil)
+}
+
+func (s *cacheStore) Get(ctx context.Context, key string) (*Circuit, error) {
+	state, err := s.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return state.circuit(), nil
+}
+
+func (s *cacheStore) Fail(ctx context.Context, key string) (int, error) {
+	state, err := s.load(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	state.Failures++
+	if err := s.store(ctx, key, state, time.Now().Add(CacheFailureTTL)); err != nil {
+		return 0, err
+	}
+	return state.Failures, nil
+}
+
+func (s *cacheStore) Open(ctx context.Context, key string, until time.Time) error {
+	return s.store(ctx, key, &circuitState{OpenUntil: until.UnixMilli()}, until)
+}
+
+// load returns the state of the entry, or the closed state if the entry is not found.
+func (s *cacheStore) load(ctx context.Context, key string) (*circuitState, error) {
+	req, err := cacheKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.cache.Match(req, nil)
+	if errors.Is(err, cache.ErrCacheNotFound) {
+		return &circuitState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var state circuitState
+	if err := json.NewDecoder(res.Body).Decode(&state); err != nil {
+		return nil, fmt.Errorf("circuitbreaker: malformed cache entry: %w", err)
+	}
+	return &state, nil
+}
+
+// store stores the state as the entry which expires at the time.
+func (s *cacheStore) store(ctx context.Context, key string, state *circuitState, expires time.Time) error {
+	req, err := cacheKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	maxAge := int((time.Until(expires) + time.Second - 1) / time.Second)
+	if maxAge < 1 {
+		maxAge = 1
+	}
+	body, _ := json.Marshal(state)
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":  {"application/json"},
+			"Cache-Control": {"max-age=" + strconv.Itoa(maxAge)},
+		},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+	return s.cache.Put(req, res)
+}
+
+func (s *cacheStore) Close(ctx context.Context, key string) error {
+	req, err := cacheKey(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := s.cache.Delete(req, nil); err != nil && !errors.Is(err, cache.ErrCacheNotFound) {
+		return err
+	}
+	return nil
+}
+
+func fromUnixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
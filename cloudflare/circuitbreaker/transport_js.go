@@ -0,0 +1,25 @@
+//go:build js && wasm
+
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func defaultTransport() http.RoundTripper {
+	return fetch.NewClient().HTTPClient(fetch.RedirectModeFollow).Transport
+}
+
+// waitUntil updates Store by `cloudflare.WaitUntil` without blocking the response, or in a goroutine if ctx doesn't hold the runtime context.
+func waitUntil(ctx context.Context, task func()) {
+	if _, ok := runtimecontext.Extract(ctx); !ok {
+		go task()
+		return
+	}
+	cloudflare.WaitUntil(ctx, task)
+}
@@ -0,0 +1,16 @@
+//go:build !(js && wasm)
+
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+)
+
+func defaultTransport() http.RoundTripper {
+	return http.DefaultTransport
+}
+
+func waitUntil(ctx context.Context, task func()) {
+	go task()
+}
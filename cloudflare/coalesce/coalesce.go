@@ -0,0 +1,200 @@
+// Package coalesce deduplicates identical subrequests to protect origins during traffic spikes.
+//
+// Transport shares responses of GET requests of the same key across requests (and isolates in the same data center)
+// by short-TTL entries of the Cache API.
+//   - Requests are not coalesced in memory, since the state of the Go program doesn't outlive the request.
+//     Requests which miss the cache before the response is stored are all sent to the origin.
+//   - Requests with the `Authorization`, `Cookie` or `Range` header are not coalesced, since their responses are not shared.
+//   - Responses are buffered to be stored. Responses larger than MaxBodySize are streamed, and are not stored.
+//   - Without the Cache API (e.g. outside of Workers), requests are sent to the origin as they are.
+package coalesce
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/cache"
+)
+
+const (
+	// DefaultTTL is the duration for which responses are shared by the cache.
+	DefaultTTL = 5 * time.Second
+	// DefaultMaxBodySize is the maximum size of bodies of shared responses.
+	DefaultMaxBodySize = 10 << 20
+)
+
+// Options represents the options of Transport.
+type Options struct {
+	// Base sends requests to the origin. The default sends requests by fetch.
+	Base http.RoundTripper
+	// Cache shares responses across requests and isolates. The default is the cache opened by `cache.Open("coalesce")`,
+	// which is separated from the cache used by fetch.
+	Cache binding.Cache
+	// TTL is the duration for which responses are stored in the cache. if TTL is not positive, DefaultTTL is used.
+	TTL time.Duration
+	// KeyFunc returns the URL used as the key of the request. The default is the URL of the request.
+	// `cache.KeyBuilder.Key` can be used to normalize keys.
+	KeyFunc func(req *http.Request) string
+	// StatusCodes are status codes of responses stored in the cache. The default is [200].
+	StatusCodes []int
+	// MaxBodySize is the maximum size of bodies of shared responses in bytes. The default is DefaultMaxBodySize.
+	MaxBodySize int
+}
+
+// Transport is http.RoundTripper which shares responses of identical requests by the cache.
+type Transport struct {
+	opts Options
+
+	cacheOnce sync.Once
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport returns Transport.
+func NewTransport(opts *Options) *Transport {
+	t := &Transport{}
+	if opts != nil {
+		t.opts = *opts
+	}
+	if t.opts.Base == nil {
+		t.opts.Base = defaultTransport()
+	}
+	if t.opts.TTL <= 0 {
+		t.opts.TTL = DefaultTTL
+	}
+	if t.opts.KeyFunc == nil {
+		t.opts.KeyFunc = func(req *http.Request) string {
+			return req.URL.String()
+		}
+	}
+	if len(t.opts.StatusCodes) == 0 {
+		t.opts.StatusCodes = []int{http.StatusOK}
+	}
+	if t.opts.MaxBodySize <= 0 {
+		t.opts.MaxBodySize = DefaultMaxBodySize
+	}
+	return t
+}
+
+// RoundTrip returns the response stored in the cache, or sends the request and stores its response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	store := t.cache()
+	if store == nil || !coalescable(req) {
+		return t.opts.Base.RoundTrip(req)
+	}
+	keyReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, t.opts.KeyFunc(req), nil)
+	if err != nil {
+		return t.opts.Base.RoundTrip(req)
+	}
+	if res, err := store.Match(keyReq, nil); err == nil {
+		if shared, err := readShared(res, t.opts.MaxBodySize); err == nil {
+			return shared.response(req), nil
+		}
+	}
+	res, err := t.opts.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if !containsStatus(t.opts.StatusCodes, res.StatusCode) {
+		return res, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, int64(t.opts.MaxBodySize)+1))
+	if err != nil {
+		res.Body.Close()
+		return nil, fmt.Errorf("coalesce: error reading body: %w", err)
+	}
+	if len(body) > t.opts.MaxBodySize {
+		res.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(body), res.Body), Closer: res.Body}
+		return res, nil
+	}
+	res.Body.Close()
+	shared := &sharedResponse{res: res, body: body}
+	stored := shared.response(keyReq)
+	stored.Header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", ttlSeconds(t.opts.TTL)))
+	if cache.CheckCacheable(keyReq, stored) == nil {
+		waitUntil(req.Context(), func() {
+			_ = store.Put(keyReq, stored)
+		})
+	}
+	return shared.response(req), nil
+}
+
+// cache returns the cache of responses. The default cache is opened when it's used first, since it can't be opened in the global scope.
+func (t *Transport) cache() binding.Cache {
+	t.cacheOnce.Do(func() {
+		if t.opts.Cache == nil {
+			t.opts.Cache = defaultCache()
+		}
+	})
+	return t.opts.Cache
+}
+
+// coalescable reports whether the response of the request can be shared with others.
+func coalescable(req *http.Request) bool {
+	if req.Method != "" && req.Method != http.MethodGet {
+		return false
+	}
+	for _, h := range []string{"Authorization", "Cookie", "Range"} {
+		if req.Header.Get(h) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sharedResponse is the buffered response shared by requests.
+type sharedResponse struct {
+	res  *http.Response
+	body []byte
+}
+
+// readShared reads the body of the response, which is not shared if it exceeds maxBodySize.
+func readShared(res *http.Response, maxBodySize int) (*sharedResponse, error) {
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, int64(maxBodySize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxBodySize {
+		return nil, errors.New("coalesce: body is too large")
+	}
+	return &sharedResponse{res: res, body: body}, nil
+}
+
+// response returns the copy of the response for the request. The body is not copied, since it's only read.
+func (s *sharedResponse) response(req *http.Request) *http.Response {
+	res := *s.res
+	res.Header = s.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(s.body))
+	res.ContentLength = int64(len(s.body))
+	res.Request = req
+	return &res
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func containsStatus(statusCodes []int, statusCode int) bool {
+	for _, s := range statusCodes {
+		if s == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// ttlSeconds returns the TTL in seconds, which is at least 1 second.
+func ttlSeconds(ttl time.Duration) int {
+	if s := int(ttl / time.Second); s > 0 {
+		return s
+	}
+	return 1
+}
@@ -0,0 +1,124 @@
+package coalesce
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// origin returns the transport which responds body, and counts requests.
+func origin(body string, count *int32) http.RoundTripper {
+	return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(count, 1)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+}
+
+func get(t *testing.T, rt http.RoundTripper, header http.Header) string {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/a", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	defer res.Body.Close()
+	b, _ := io.ReadAll(res.Body)
+	return string(b)
+}
+
+func TestCoalesceCache(t *testing.T) {
+	var count int32
+	c := bindingtest.NewCache()
+	first := NewTransport(&Options{Base: origin("hello", &count), Cache: c})
+	if got := get(t, first, nil); got != "hello" {
+		t.Errorf("got %q", got)
+	}
+	// the response is stored in the background.
+	deadline := time.Now().Add(time.Second)
+	for {
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/a", nil)
+		if res, err := c.Match(req, nil); err == nil {
+			if cc := res.Header.Get("Cache-Control"); cc != "public, max-age=5" {
+				t.Errorf("Cache-Control = %q", cc)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("response is not stored")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	second := NewTransport(&Options{Base: origin("other", &count), Cache: c})
+	if got := get(t, second, nil); got != "hello" {
+		t.Errorf("got %q from the cache", got)
+	}
+	if count != 1 {
+		t.Errorf("requests to origin = %d, want 1", count)
+	}
+}
+
+func TestNotCoalescable(t *testing.T) {
+	var count int32
+	tr := NewTransport(&Options{Base: origin("hello", &count), Cache: bindingtest.NewCache()})
+	for _, h := range []string{"Authorization", "Cookie", "Range"} {
+		get(t, tr, http.Header{h: {"x"}})
+	}
+	if count != 3 {
+		t.Errorf("requests to origin = %d, want 3", count)
+	}
+}
+
+func TestLargeBody(t *testing.T) {
+	var count int32
+	c := bindingtest.NewCache()
+	tr := NewTransport(&Options{Base: origin("0123456789", &count), Cache: c, MaxBodySize: 4})
+	for i := 0; i < 2; i++ {
+		if got := get(t, tr, nil); got != "0123456789" {
+			t.Errorf("got %q", got)
+		}
+	}
+	if count != 2 {
+		t.Errorf("requests to origin = %d, want 2", count)
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/a", nil)
+	if _, err := c.Match(req, nil); err == nil {
+		t.Error("large bodies must not be stored")
+	}
+}
+
+func TestWithoutCache(t *testing.T) {
+	var count int32
+	// there is no Cache API outside of Workers, so requests are sent as they are.
+	tr := NewTransport(&Options{Base: origin("hello", &count), TTL: -time.Second})
+	if tr.opts.TTL != DefaultTTL {
+		t.Errorf("TTL = %v, want DefaultTTL", tr.opts.TTL)
+	}
+	for i := 0; i < 2; i++ {
+		if got := get(t, tr, nil); got != "hello" {
+			t.Errorf("got %q", got)
+		}
+	}
+	if count != 2 {
+		t.Errorf("requests to origin = %d, want 2", count)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build js && wasm
+
+package coalesce
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/cache"
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func defaultTransport() http.RoundTripper {
+	return fetch.NewClient().HTTPClient(fetch.RedirectModeFollow).Transport
+}
+
+// defaultCache opens the cache of Transport. if the Cache API is not available, responses are not cached.
+func defaultCache() binding.Cache {
+	if !jsutil.HasGlobal("caches") {
+		return nil
+	}
+	c, err := cache.Open("coalesce")
+	if err != nil {
+		return nil
+	}
+	return c
+}
+
+// waitUntil runs the task by `cloudflare.WaitUntil`, or in a goroutine if ctx doesn't hold the runtime context.
+func waitUntil(ctx context.Context, task func()) {
+	if _, ok := runtimecontext.Extract(ctx); !ok {
+		go task()
+		return
+	}
+	cloudflare.WaitUntil(ctx, task)
+}
@@ -0,0 +1,23 @@
+//go:build !(js && wasm)
+
+package coalesce
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+func defaultTransport() http.RoundTripper {
+	return http.DefaultTransport
+}
+
+// defaultCache returns nil, since there is no Cache API outside of Workers.
+func defaultCache() binding.Cache {
+	return nil
+}
+
+func waitUntil(ctx context.Context, task func()) {
+	go task()
+}
@@ -0,0 +1,94 @@
+//go:build js && wasm
+
+// Package compat provides detection of features available in the runtime.
+//   - Compatibility dates and flags are not exposed to workers at runtime,
+//     so features enabled by them are detected by their globals instead.
+//   - Results are cached, since globals of the runtime don't change after the startup.
+package compat
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Feature is a feature of the runtime, represented by the path of the global which defines it.
+type Feature string
+
+const (
+	// BYOBReader is ReadableStreamBYOBReader. Bodies are read into reused buffers when it is available.
+	BYOBReader Feature = "ReadableStreamBYOBReader"
+	// FixedLengthStream is the TransformStream which sets Content-Length of bodies.
+	FixedLengthStream Feature = "FixedLengthStream"
+	// IdentityTransformStream is the TransformStream which passes bytes through.
+	IdentityTransformStream Feature = "IdentityTransformStream"
+	// CompressionStream is the TransformStream which compresses bytes.
+	CompressionStream Feature = "CompressionStream"
+	// DecompressionStream is the TransformStream which decompresses bytes.
+	DecompressionStream Feature = "DecompressionStream"
+	// HTMLRewriter is the streaming HTML parser used by package htmlrewriter.
+	HTMLRewriter Feature = "HTMLRewriter"
+	// WebSocketPair is the pair of WebSockets used to accept WebSocket connections.
+	WebSocketPair Feature = "WebSocketPair"
+	// URLPattern is the URL matcher used by package urlpattern.
+	URLPattern Feature = "URLPattern"
+	// SchedulerWait is `scheduler.wait`, the awaitable alternative of setTimeout.
+	SchedulerWait Feature = "scheduler.wait"
+	// NodeJSCompat is detected by the global `Buffer`, which is defined with the nodejs_compat flag.
+	NodeJSCompat Feature = "Buffer"
+)
+
+// Known is the list of features defined by this package.
+var Known = []Feature{
+	BYOBReader,
+	FixedLengthStream,
+	IdentityTransformStream,
+	CompressionStream,
+	DecompressionStream,
+	HTMLRewriter,
+	WebSocketPair,
+	URLPattern,
+	SchedulerWait,
+	NodeJSCompat,
+}
+
+// Has reports whether the feature is available.
+//   - Features not defined by this package can be given as the path of the global, e.g. `Feature("navigator.userAgent")`.
+func Has(f Feature) bool {
+	return jsutil.HasGlobal(strings.Split(string(f), ".")...)
+}
+
+// Available returns known features available in the runtime. This is useful to log them at the startup.
+func Available() []Feature {
+	var fs []Feature
+	for _, f := range Known {
+		if Has(f) {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
+
+// Require returns the error which lists all features not available, or nil if all of them are available.
+func Require(features ...Feature) error {
+	var missing []string
+	for _, f := range features {
+		if !Has(f) {
+			missing = append(missing, string(f))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("compat: features not available: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// HasBinding reports whether the binding of the name is defined.
+//   - This function panics when a runtime context is not found.
+func HasBinding(ctx context.Context, name string) bool {
+	v := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(name)
+	return !v.IsUndefined() && !v.IsNull()
+}
@@ -0,0 +1,30 @@
+//go:build js && wasm
+
+package compat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHas(t *testing.T) {
+	if !Has(CompressionStream) {
+		t.Error("CompressionStream must be available")
+	}
+	if Has(HTMLRewriter) {
+		t.Error("HTMLRewriter must not be available outside of Workers")
+	}
+	if !Has("Promise.resolve") {
+		t.Error("Promise.resolve must be available")
+	}
+}
+
+func TestRequire(t *testing.T) {
+	if err := Require(CompressionStream, DecompressionStream); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err := Require(CompressionStream, HTMLRewriter, WebSocketPair)
+	if err == nil || !strings.HasSuffix(err.Error(), "HTMLRewriter, WebSocketPair") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package connectrpc provides the adapter which mounts Connect handlers (e.g. generated by connect-go) in workers.
+//
+// Connect handlers are http.Handlers, and Handler maps them onto the request and response model of Workers.
+//   - Unary and server-streaming RPCs of the Connect and gRPC-Web protocols are supported.
+//     Messages of streams are sent as they are flushed by the handler.
+//   - The gRPC protocol is rejected with `grpc-status: 12` (Unimplemented), since Workers can't send HTTP trailers.
+//     Use the Connect or gRPC-Web protocol (e.g. `connect.WithGRPCWeb()` of clients).
+//   - Timeouts of requests (`Connect-Timeout-Ms` and `Grpc-Timeout` headers) are set as deadlines of the contexts,
+//     so bindings and fetch called with the contexts are also canceled.
+//   - Compression is done by handlers. Responses compressed by handlers (with Content-Encoding) are sent as they are,
+//     without being compressed again by the runtime.
+//   - Contexts of requests hold the runtime context, so bindings can be used in handlers.
+//
+// Usage:
+//
+//	path, h := greetv1connect.NewGreetServiceHandler(&greetServer{})
+//	mux := http.NewServeMux()
+//	mux.Handle(path, connectrpc.Handler(h, nil))
+//	workers.Serve(mux)
+package connectrpc
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/syumai/workers/internal/encodebody"
+)
+
+// Options represents the options of Handler.
+type Options struct {
+	// MaxTimeout limits timeouts given by clients. if clients don't give timeouts, MaxTimeout is used.
+	// if MaxTimeout is 0, timeouts are not limited.
+	MaxTimeout time.Duration
+}
+
+// Handler returns the handler which serves h in workers.
+func Handler(h http.Handler, opts *Options) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if isGRPC(req.Header.Get("Content-Type")) {
+			w.Header().Set("Content-Type", req.Header.Get("Content-Type"))
+			w.Header().Set("Grpc-Status", "12")
+			w.Header().Set("Grpc-Message", "gRPC protocol is not supported by Workers; use Connect or gRPC-Web protocol")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		timeout, ok := requestTimeout(req.Header)
+		if opts.MaxTimeout > 0 && (!ok || timeout > opts.MaxTimeout) {
+			timeout, ok = opts.MaxTimeout, true
+		}
+		if ok {
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			defer cancel()
+			req = req.WithContext(ctx)
+		}
+		h.ServeHTTP(&responseWriter{ResponseWriter: w}, req)
+	})
+}
+
+// responseWriter marks responses with Content-Encoding by encodebody.Header, since Connect handlers compress bodies themselves.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if enc := w.Header().Get("Content-Encoding"); enc != "" && enc != "identity" {
+			w.Header().Set(encodebody.Header, encodebody.Manual)
+		}
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends messages of streams written so far.
+func (w *responseWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// isGRPC reports whether the content type is of the gRPC protocol, which is not gRPC-Web.
+func isGRPC(contentType string) bool {
+	return contentType == "application/grpc" || strings.HasPrefix(contentType, "application/grpc+")
+}
+
+// requestTimeout returns the timeout given by the `Connect-Timeout-Ms` or `Grpc-Timeout` header.
+//   - https://connectrpc.com/docs/protocol/#unary-request
+//   - https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md#requests
+func requestTimeout(header http.Header) (time.Duration, bool) {
+	if v := header.Get("Connect-Timeout-Ms"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ms < 0 || len(v) > 10 {
+			return 0, false
+		}
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	v := header.Get("Grpc-Timeout")
+	if len(v) < 2 || len(v) > 9 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v[:len(v)-1], 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	var unit time.Duration
+	switch v[len(v)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, false
+	}
+	if n > int64(math.MaxInt64/unit) {
+		// too long to be represented, so it's treated as no timeout.
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,121 @@
+package connectrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/encodebody"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	tests := map[string]struct {
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		"none":            {header: http.Header{}},
+		"connect":         {header: http.Header{"Connect-Timeout-Ms": {"1500"}}, want: 1500 * time.Millisecond, ok: true},
+		"connect/invalid": {header: http.Header{"Connect-Timeout-Ms": {"-1"}}},
+		"grpc-web":        {header: http.Header{"Grpc-Timeout": {"2S"}}, want: 2 * time.Second, ok: true},
+		"grpc-web/micro":  {header: http.Header{"Grpc-Timeout": {"300u"}}, want: 300 * time.Microsecond, ok: true},
+		"grpc-web/unit":   {header: http.Header{"Grpc-Timeout": {"2x"}}},
+		"grpc-web/long":   {header: http.Header{"Grpc-Timeout": {"99999999H"}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := requestTimeout(tt.header)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("want %v, %v, got %v, %v", tt.want, tt.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	var deadline time.Duration
+	var flushable bool
+	h := Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if d, ok := req.Context().Deadline(); ok {
+			deadline = time.Until(d).Round(time.Second)
+		}
+		_, flushable = w.(http.Flusher)
+		w.WriteHeader(http.StatusOK)
+	}), &Options{MaxTimeout: 10 * time.Second})
+
+	tests := map[string]struct {
+		timeout string
+		want    time.Duration
+	}{
+		"client timeout": {timeout: "3000", want: 3 * time.Second},
+		"limited":        {timeout: "60000", want: 10 * time.Second},
+		"default":        {want: 10 * time.Second},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			deadline = 0
+			req := httptest.NewRequest(http.MethodPost, "/greet.v1.GreetService/Greet", nil)
+			req.Header.Set("Content-Type", "application/proto")
+			if tt.timeout != "" {
+				req.Header.Set("Connect-Timeout-Ms", tt.timeout)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if deadline != tt.want {
+				t.Errorf("want deadline in %v, got %v", tt.want, deadline)
+			}
+			if !flushable {
+				t.Error("want http.Flusher to be passed to the handler for streaming")
+			}
+		})
+	}
+}
+
+func TestHandlerRejectsGRPC(t *testing.T) {
+	called := false
+	h := Handler(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		called = true
+	}), nil)
+	req := httptest.NewRequest(http.MethodPost, "/greet.v1.GreetService/Greet", nil)
+	req.Header.Set("Content-Type", "application/grpc+proto")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if called || rec.Header().Get("Grpc-Status") != "12" {
+		t.Errorf("want gRPC to be rejected as unimplemented, got called=%v, headers=%v", called, rec.Header())
+	}
+
+	req.Header.Set("Content-Type", "application/grpc-web+proto")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if !called {
+		t.Error("want gRPC-Web to be served")
+	}
+}
+
+func TestHandlerMarksEncodedBody(t *testing.T) {
+	tests := map[string]struct {
+		encoding string
+		want     string
+	}{
+		"compressed": {encoding: "gzip", want: encodebody.Manual},
+		"identity":   {encoding: "identity"},
+		"none":       {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			h := Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write([]byte("message"))
+			}), nil)
+			req := httptest.NewRequest(http.MethodPost, "/greet.v1.GreetService/Greet", nil)
+			req.Header.Set("Content-Type", "application/proto")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if got := rec.Header().Get(encodebody.Header); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
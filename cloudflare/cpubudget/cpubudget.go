@@ -0,0 +1,156 @@
+// Package cpubudget provides approximate tracking of CPU time used by requests, with a soft limit,
+// so handlers can degrade gracefully before the runtime's CPU limit is exceeded.
+//
+// CPU time is approximated as the elapsed time in which Go is not waiting for JavaScript promises
+// (e.g. fetch, bindings and reads of bodies). Waiting time is measured by this module around its awaits.
+//   - Awaits bound to the context of the request (e.g. fetch and D1 queries) are counted only for the request.
+//     Awaits of APIs which don't take a context are counted for all requests handled by the instance at the time.
+//   - In Cloudflare Workers, clocks advance only on I/O. Time spent on computation is observed when the next I/O completes,
+//     so computation right before an await is counted as waiting time. Call Checkpoint in CPU-heavy code,
+//     which awaits a zero-delay timer to advance the clock, so preceding computation is counted as CPU time.
+//   - All goroutines of the instance share the CPU, so concurrent requests (e.g. of Durable Objects) count CPU time of each other.
+//     Used never returns a negative value, even if waiting time of concurrent requests overlaps.
+//   - https://developers.cloudflare.com/workers/platform/limits/#cpu-time
+package cpubudget
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Options represents the options of New and Middleware.
+type Options struct {
+	// SoftLimit is the CPU time after which the budget is exceeded. if SoftLimit is 0, the budget is never exceeded.
+	SoftLimit time.Duration
+	// OnSoftLimit is called once when Checkpoint or Exceeded finds that the budget is exceeded.
+	OnSoftLimit func(ctx context.Context, used time.Duration)
+}
+
+// Budget tracks CPU time used from its start.
+//   - Methods of nil *Budget return zero values, so budgets returned by FromContext can be used safely.
+type Budget struct {
+	start        time.Time
+	awaitTracker *jsutil.AwaitTracker
+	awaitStart   time.Duration
+	isolateStart time.Duration
+	softLimit    time.Duration
+	onSoftLimit  func(ctx context.Context, used time.Duration)
+
+	mu       sync.Mutex
+	notified bool
+}
+
+type budgetKey struct{}
+
+// New starts the Budget, and returns the context which holds it.
+//   - This is useful to track handlers other than HTTP, e.g. queue consumers and Cron Triggers.
+func New(ctx context.Context, opts *Options) (context.Context, *Budget) {
+	ctx = jsutil.WithAwaitTracker(ctx)
+	tracker := jsutil.AwaitTrackerFromContext(ctx)
+	b := &Budget{
+		start:        time.Now(),
+		awaitTracker: tracker,
+		awaitStart:   tracker.Total(),
+		isolateStart: jsutil.AwaitTime(),
+	}
+	if opts != nil {
+		b.softLimit = opts.SoftLimit
+		b.onSoftLimit = opts.OnSoftLimit
+	}
+	return context.WithValue(ctx, budgetKey{}, b), b
+}
+
+// FromContext returns the Budget held by ctx. if ctx doesn't hold a Budget, returns nil.
+func FromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(budgetKey{}).(*Budget)
+	return b
+}
+
+// Middleware returns the middleware which starts the Budget of each request.
+//   - The Budget can be accessed by FromContext with the context of the request.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, _ := New(req.Context(), opts)
+			next.ServeHTTP(w, req.WithContext(ctx))
+		})
+	}
+}
+
+// Used returns the approximate CPU time used from the start of the Budget.
+func (b *Budget) Used() time.Duration {
+	if b == nil {
+		return 0
+	}
+	awaited := (b.awaitTracker.Total() - b.awaitStart) + (jsutil.AwaitTime() - b.isolateStart)
+	used := time.Since(b.start) - awaited
+	if used < 0 {
+		return 0
+	}
+	return used
+}
+
+// Remaining returns the CPU time remaining until SoftLimit. if SoftLimit is 0 or the budget is exceeded, returns 0.
+func (b *Budget) Remaining() time.Duration {
+	if b == nil || b.softLimit == 0 {
+		return 0
+	}
+	if remaining := b.softLimit - b.Used(); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Exceeded reports whether the used CPU time is over SoftLimit. OnSoftLimit is called at the first time it's exceeded.
+func (b *Budget) Exceeded(ctx context.Context) bool {
+	if b == nil || b.softLimit == 0 {
+		return false
+	}
+	used := b.Used()
+	if used < b.softLimit {
+		return false
+	}
+	b.mu.Lock()
+	notify := !b.notified && b.onSoftLimit != nil
+	b.notified = true
+	b.mu.Unlock()
+	if notify {
+		b.onSoftLimit(ctx, used)
+	}
+	return true
+}
+
+// Exceeded reports whether the Budget held by ctx is exceeded. if ctx doesn't hold a Budget, returns false.
+func Exceeded(ctx context.Context) bool {
+	return FromContext(ctx).Exceeded(ctx)
+}
+
+// Checkpoint awaits a zero-delay timer to advance the clock, and reports whether the Budget held by ctx is exceeded.
+//   - Other goroutines can run during Checkpoint.
+//   - if ctx doesn't hold a Budget, Checkpoint returns false without waiting.
+func Checkpoint(ctx context.Context) bool {
+	b := FromContext(ctx)
+	if b == nil {
+		return false
+	}
+	waitTimer()
+	return b.Exceeded(ctx)
+}
+
+// waitTimer waits for a zero-delay timer. This is not counted by jsutil.AwaitTime, since the time is spent by computation.
+func waitTimer() {
+	done := make(chan struct{})
+	var cb js.Func
+	cb = js.FuncOf(func(js.Value, []js.Value) any {
+		cb.Release()
+		close(done)
+		return js.Undefined()
+	})
+	jsutil.Global.Call("setTimeout", cb, 0)
+	<-done
+}
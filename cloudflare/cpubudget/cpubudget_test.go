@@ -0,0 +1,100 @@
+package cpubudget
+
+import (
+	"context"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func busy(d time.Duration) {
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
+
+func sleepPromise(d time.Duration) js.Value {
+	return jsutil.Global.Get("Promise").New(js.FuncOf(func(_ js.Value, args []js.Value) any {
+		jsutil.Global.Call("setTimeout", args[0], d.Milliseconds())
+		return js.Undefined()
+	}))
+}
+
+func TestBudget(t *testing.T) {
+	var calls int
+	ctx, b := New(context.Background(), &Options{
+		SoftLimit: 30 * time.Millisecond,
+		OnSoftLimit: func(ctx context.Context, used time.Duration) {
+			calls++
+		},
+	})
+	if _, err := jsutil.AwaitPromise(sleepPromise(100 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if used := b.Used(); used >= 30*time.Millisecond {
+		t.Errorf("waiting time is counted as CPU time: %v", used)
+	}
+	if Checkpoint(ctx) {
+		t.Error("want budget not to be exceeded")
+	}
+	busy(50 * time.Millisecond)
+	if !Checkpoint(ctx) || !Exceeded(ctx) {
+		t.Error("want budget to be exceeded")
+	}
+	if calls != 1 {
+		t.Errorf("want OnSoftLimit to be called once, got %d", calls)
+	}
+	if b.Remaining() != 0 {
+		t.Errorf("want no remaining time, got %v", b.Remaining())
+	}
+}
+
+func TestNoBudget(t *testing.T) {
+	ctx := context.Background()
+	if Checkpoint(ctx) || Exceeded(ctx) || FromContext(ctx).Used() != 0 {
+		t.Error("want zero values without budget")
+	}
+}
+
+func TestOverlappingBudgets(t *testing.T) {
+	ctxA, a := New(context.Background(), nil)
+	_, b := New(context.Background(), nil)
+	promise := sleepPromise(100 * time.Millisecond)
+	var usedB time.Duration
+	done := make(chan struct{})
+	go func() {
+		// runs while a is waiting.
+		busy(50 * time.Millisecond)
+		usedB = b.Used()
+		close(done)
+	}()
+	if _, err := jsutil.AwaitPromiseContext(ctxA, promise); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if usedB < 40*time.Millisecond {
+		t.Errorf("waiting time of another budget is subtracted: %v", usedB)
+	}
+	if used := a.Used(); used >= 30*time.Millisecond {
+		t.Errorf("waiting time is counted as CPU time: %v", used)
+	}
+}
+
+func TestUsedIsNotNegative(t *testing.T) {
+	ctx, b := New(context.Background(), nil)
+	done := make(chan struct{})
+	go func() {
+		if _, err := jsutil.AwaitPromise(sleepPromise(50 * time.Millisecond)); err != nil {
+			t.Error(err)
+		}
+		close(done)
+	}()
+	if _, err := jsutil.AwaitPromiseContext(ctx, sleepPromise(50*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if used := b.Used(); used != 0 {
+		t.Errorf("want 0 for overlapping waiting time, got %v", used)
+	}
+}
@@ -12,11 +12,26 @@ import (
 )
 
 // Event represents information about the Cron that invoked this worker.
+//   - https://developers.cloudflare.com/workers/runtime-apis/handlers/scheduled/
 type Event struct {
-	Cron          string
+	instance js.Value
+	// Cron is the cron expression of the trigger which fired, e.g. `*/5 * * * *`.
+	// This can be used to run different tasks for each trigger.
+	Cron string
+	// ScheduledTime is the time when the trigger was scheduled to fire.
 	ScheduledTime time.Time
 }
 
+// Schedule parses Cron of the event.
+func (e *Event) Schedule() (*Schedule, error) {
+	return Parse(e.Cron)
+}
+
+// NoRetry prevents the event from being retried when the Task returns an error.
+func (e *Event) NoRetry() {
+	e.instance.Call("noRetry")
+}
+
 // toEvent converts JS Object to Go Event struct
 func toEvent(obj js.Value) (*Event, error) {
 	if obj.IsUndefined() {
@@ -25,23 +40,40 @@ func toEvent(obj js.Value) (*Event, error) {
 	cronVal := obj.Get("cron").String()
 	scheduledTimeVal := obj.Get("scheduledTime").Float()
 	return &Event{
+		instance:      obj,
 		Cron:          cronVal,
-		ScheduledTime: time.Unix(int64(scheduledTimeVal)/1000, 0).UTC(),
+		ScheduledTime: time.UnixMilli(int64(scheduledTimeVal)).UTC(),
 	}, nil
 }
 
+// Task is executed when the Cron Trigger fires.
+//   - ctx holds the environment of the worker, so bindings can be accessed by functions in the cloudflare package.
+//     Tasks can be extended beyond the end of Task by `cloudflare.WaitUntil(ctx, ...)`.
+//   - if Task returns an error, the event is reported as failed.
 type Task func(ctx context.Context, event *Event) error
 
 var scheduledTask Task
 
-// ScheduleTask sets the Task to be executed
+// ScheduleTask sets the Task to be executed and starts the worker.
+//   - ScheduleTask blocks forever, so it must be called at the end of main function.
+//     Use ScheduleTaskNonBlock to handle both requests and Cron Triggers by the same worker.
 func ScheduleTask(task Task) {
-	scheduledTask = task
+	ScheduleTaskNonBlock(task)
 	jsutil.Global.Call("ready")
 	select {}
 }
 
+// ScheduleTaskNonBlock sets the Task to be executed without starting the worker.
+//   - ScheduleTaskNonBlock must be called before `workers.Serve` (or other functions which start the worker).
+func ScheduleTaskNonBlock(task Task) {
+	scheduledTask = task
+	registerRunScheduler()
+}
+
 func runScheduler(eventObj js.Value, runtimeCtxObj js.Value) error {
+	if scheduledTask == nil {
+		return errors.New("cron: ScheduleTask must be called before runScheduler")
+	}
 	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
 	event, err := toEvent(eventObj)
 	if err != nil {
@@ -54,29 +86,17 @@ func runScheduler(eventObj js.Value, runtimeCtxObj js.Value) error {
 	return nil
 }
 
-func init() {
-	runSchedulerCallback := js.FuncOf(func(_ js.Value, args []js.Value) any {
-		if len(args) != 2 {
-			panic(fmt.Errorf("invalid number of arguments given to runScheduler: %d", len(args)))
+// registerRunScheduler sets runScheduler called by the JavaScript side.
+var registerRunScheduler = jsutil.LazyRegister("runScheduler", func(_ js.Value, args []js.Value) any {
+	if len(args) != 2 {
+		panic(fmt.Errorf("invalid number of arguments given to runScheduler: %d", len(args)))
+	}
+	event := args[0]
+	runtimeCtx := args[1]
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		if err := runScheduler(event, runtimeCtx); err != nil {
+			return js.Value{}, err
 		}
-		event := args[0]
-		runtimeCtx := args[1]
-
-		var cb js.Func
-		cb = js.FuncOf(func(_ js.Value, pArgs []js.Value) any {
-			defer cb.Release()
-			resolve := pArgs[0]
-			go func() {
-				err := runScheduler(event, runtimeCtx)
-				if err != nil {
-					panic(err)
-				}
-				resolve.Invoke(js.Undefined())
-			}()
-			return js.Undefined()
-		})
-
-		return jsutil.NewPromise(cb)
+		return js.Undefined(), nil
 	})
-	jsutil.Global.Set("runScheduler", runSchedulerCallback)
-}
+})
@@ -0,0 +1,54 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+)
+
+// TaskMux dispatches events to Tasks by the cron expression of the trigger which fired.
+//
+//	mux := cron.NewTaskMux()
+//	mux.Handle("*/5 * * * *", syncTask)
+//	mux.Handle("0 0 * * *", cleanupTask)
+//	cron.ScheduleTask(mux.Task)
+type TaskMux struct {
+	tasks map[string]Task
+}
+
+// NewTaskMux returns an empty TaskMux.
+func NewTaskMux() *TaskMux {
+	return &TaskMux{tasks: map[string]Task{}}
+}
+
+// Handle registers the Task for the cron expression. The expression must be the same as the one in wrangler.toml.
+func (m *TaskMux) Handle(cron string, task Task) {
+	m.tasks[cron] = task
+}
+
+// Task runs the Task registered for the cron expression of the event.
+//   - if no Task is registered for the same expression, the Task of the equivalent expression is run
+//     (e.g. `0 0 * * SUN` and `0 0 * * 1`).
+//   - if no Task is registered for the expression, returns an error.
+func (m *TaskMux) Task(ctx context.Context, event *Event) error {
+	task, ok := m.tasks[event.Cron]
+	if !ok {
+		task, ok = m.lookupEquivalent(event.Cron)
+	}
+	if !ok {
+		return fmt.Errorf("cron: no task is registered for %q", event.Cron)
+	}
+	return task(ctx, event)
+}
+
+func (m *TaskMux) lookupEquivalent(cron string) (Task, bool) {
+	s, err := Parse(cron)
+	if err != nil {
+		return nil, false
+	}
+	for expr, task := range m.tasks {
+		if other, err := Parse(expr); err == nil && s.Equal(other) {
+			return task, true
+		}
+	}
+	return nil, false
+}
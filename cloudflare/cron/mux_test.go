@@ -0,0 +1,52 @@
+package cron
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTaskMux(t *testing.T) {
+	mux := NewTaskMux()
+	var called []string
+	for _, c := range []string{"*/5 * * * *", "0 0 * * *"} {
+		c := c
+		mux.Handle(c, func(ctx context.Context, event *Event) error {
+			called = append(called, c)
+			return nil
+		})
+	}
+	tests := map[string]struct {
+		cron    string
+		want    string
+		wantErr bool
+	}{
+		"every 5 minutes": {cron: "*/5 * * * *"},
+		"daily":           {cron: "0 0 * * *"},
+		"equivalent":      {cron: "0-59/5 * * * *", want: "*/5 * * * *"},
+		"unknown":         {cron: "0 12 * * *", wantErr: true},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			called = nil
+			err := mux.Task(context.Background(), &Event{Cron: tc.cron})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := tc.want
+			if want == "" {
+				want = tc.cron
+			}
+			if len(called) != 1 || called[0] != want {
+				t.Errorf("want task of %q called, got %v", want, called)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpression is returned by Parse when the cron expression is invalid.
+var ErrInvalidExpression = errors.New("cron: invalid expression")
+
+// Schedule represents a parsed cron expression of Cron Triggers.
+//   - The expression has 5 fields: minute (0-59), hour (0-23), day of month (1-31), month (1-12 or JAN-DEC)
+//     and day of week (1-7 or SUN-SAT, where 1 is Sunday).
+//   - Each field supports `*`, lists (`,`), ranges (`-`) and steps (`/`).
+//   - Day of month supports `L` (last day), `L-n` (n days before the last day), `LW` (last weekday)
+//     and `nW` (the weekday nearest to day n in the same month).
+//   - Day of week supports `nL` (the last weekday n of the month) and `n#k` (the k-th weekday n of the month).
+//   - if both day of month and day of week are restricted, days matching either of them match.
+//   - Times are in UTC, as Cron Triggers are.
+//   - https://developers.cloudflare.com/workers/configuration/cron-triggers/#supported-cron-expressions
+type Schedule struct {
+	expr    string
+	minutes uint64
+	hours   uint64
+	months  uint64
+	dom     dayOfMonth
+	dow     dayOfWeek
+}
+
+type dayOfMonth struct {
+	any  bool
+	days uint64
+	// lastOffsets are n of `L-n`. `L` is 0.
+	lastOffsets []int
+	lastWeekday bool
+	// nearestWeekdays are n of `nW`.
+	nearestWeekdays []int
+}
+
+type dayOfWeek struct {
+	any bool
+	// weekdays are bits of time.Weekday.
+	weekdays uint64
+	// lastWeekdays are bits of time.Weekday of `nL`.
+	lastWeekdays uint64
+	// nth are pairs of time.Weekday and k of `n#k`.
+	nth [][2]int
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var weekdayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+// Parse parses the cron expression.
+func Parse(expr string) (*Schedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: %q must have 5 fields, got %d", ErrInvalidExpression, expr, len(fields))
+	}
+	s := &Schedule{expr: strings.Join(fields, " ")}
+	var err error
+	if s.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("%w: minute: %v", ErrInvalidExpression, err)
+	}
+	if s.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("%w: hour: %v", ErrInvalidExpression, err)
+	}
+	if s.dom, err = parseDayOfMonth(fields[2]); err != nil {
+		return nil, fmt.Errorf("%w: day of month: %v", ErrInvalidExpression, err)
+	}
+	if s.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("%w: month: %v", ErrInvalidExpression, err)
+	}
+	if s.dow, err = parseDayOfWeek(fields[4]); err != nil {
+		return nil, fmt.Errorf("%w: day of week: %v", ErrInvalidExpression, err)
+	}
+	return s, nil
+}
+
+// MustParse is like Parse but panics if the expression is invalid.
+func MustParse(expr string) *Schedule {
+	s, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// String returns the expression of the Schedule.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Equal reports whether both Schedules match the same times, e.g. `0 0 * * SUN` and `0 0 * * 1`.
+func (s *Schedule) Equal(other *Schedule) bool {
+	return s.canonical() == other.canonical()
+}
+
+func (s *Schedule) canonical() string {
+	return fmt.Sprintf("%x %x %x %v %+v", s.minutes, s.hours, s.months, s.dom, s.dow)
+}
+
+// parseField parses the field of numbers from min to max into bits.
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		b, err := parseRange(part, min, max, names)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseRange parses `*`, `n`, `a-b` with an optional step `/s`.
+func parseRange(part string, min, max int, names map[string]int) (uint64, error) {
+	rangePart, step := part, 1
+	if i := strings.IndexByte(part, '/'); i >= 0 {
+		rangePart = part[:i]
+		var err error
+		step, err = strconv.Atoi(part[i+1:])
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", part[i+1:])
+		}
+	}
+	start, end := min, max
+	switch {
+	case rangePart == "*":
+	case strings.Contains(rangePart, "-"):
+		i := strings.IndexByte(rangePart, '-')
+		var err error
+		if start, err = parseValue(rangePart[:i], min, max, names); err != nil {
+			return 0, err
+		}
+		if end, err = parseValue(rangePart[i+1:], min, max, names); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q", rangePart)
+		}
+	default:
+		v, err := parseValue(rangePart, min, max, names)
+		if err != nil {
+			return 0, err
+		}
+		start = v
+		if step == 1 {
+			end = v
+		}
+	}
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+func parseDayOfMonth(field string) (dayOfMonth, error) {
+	var dom dayOfMonth
+	if field == "*" {
+		dom.any = true
+		return dom, nil
+	}
+	for _, part := range strings.Split(field, ",") {
+		switch upper := strings.ToUpper(part); {
+		case upper == "L":
+			dom.lastOffsets = append(dom.lastOffsets, 0)
+		case upper == "LW":
+			dom.lastWeekday = true
+		case strings.HasPrefix(upper, "L-"):
+			n, err := strconv.Atoi(upper[2:])
+			if err != nil || n < 0 || n > 30 {
+				return dom, fmt.Errorf("invalid offset %q", part)
+			}
+			dom.lastOffsets = append(dom.lastOffsets, n)
+		case strings.HasSuffix(upper, "W"):
+			n, err := parseValue(upper[:len(upper)-1], 1, 31, nil)
+			if err != nil {
+				return dom, err
+			}
+			dom.nearestWeekdays = append(dom.nearestWeekdays, n)
+		default:
+			b, err := parseRange(part, 1, 31, nil)
+			if err != nil {
+				return dom, err
+			}
+			dom.days |= b
+		}
+	}
+	return dom, nil
+}
+
+func parseDayOfWeek(field string) (dayOfWeek, error) {
+	var dow dayOfWeek
+	if field == "*" {
+		dow.any = true
+		return dow, nil
+	}
+	for _, part := range strings.Split(field, ",") {
+		upper := strings.ToUpper(part)
+		switch {
+		case strings.Contains(upper, "#"):
+			i := strings.IndexByte(upper, '#')
+			wd, err := parseValue(upper[:i], 1, 7, weekdayNames)
+			if err != nil {
+				return dow, err
+			}
+			k, err := strconv.Atoi(upper[i+1:])
+			if err != nil || k < 1 || k > 5 {
+				return dow, fmt.Errorf("invalid occurrence %q", part)
+			}
+			dow.nth = append(dow.nth, [2]int{wd - 1, k})
+		case upper == "L":
+			dow.weekdays |= 1 << uint(time.Saturday)
+		case strings.HasSuffix(upper, "L"):
+			wd, err := parseValue(upper[:len(upper)-1], 1, 7, weekdayNames)
+			if err != nil {
+				return dow, err
+			}
+			dow.lastWeekdays |= 1 << uint(wd-1)
+		default:
+			b, err := parseRange(part, 1, 7, weekdayNames)
+			if err != nil {
+				return dow, err
+			}
+			// shift 1-7 (SUN-SAT) to time.Weekday.
+			dow.weekdays |= b >> 1
+		}
+	}
+	return dow, nil
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns the weekday nearest to day n in the month, without crossing the month.
+//   - n must be a day of the month.
+func nearestWeekday(year int, month time.Month, n int) int {
+	last := daysIn(year, month)
+	switch time.Date(year, month, n, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if n == 1 {
+			return 3
+		}
+		return n - 1
+	case time.Sunday:
+		if n == last {
+			return n - 2
+		}
+		return n + 1
+	}
+	return n
+}
+
+func (dom *dayOfMonth) matches(t time.Time) bool {
+	day, last := t.Day(), daysIn(t.Year(), t.Month())
+	if dom.days&(1<<uint(day)) != 0 {
+		return true
+	}
+	for _, offset := range dom.lastOffsets {
+		if day == last-offset {
+			return true
+		}
+	}
+	if dom.lastWeekday && day == nearestWeekday(t.Year(), t.Month(), last) {
+		return true
+	}
+	for _, n := range dom.nearestWeekdays {
+		if n <= last && day == nearestWeekday(t.Year(), t.Month(), n) {
+			return true
+		}
+	}
+	return false
+}
+
+func (dow *dayOfWeek) matches(t time.Time) bool {
+	wd, day := t.Weekday(), t.Day()
+	if dow.weekdays&(1<<uint(wd)) != 0 {
+		return true
+	}
+	if dow.lastWeekdays&(1<<uint(wd)) != 0 && day+7 > daysIn(t.Year(), t.Month()) {
+		return true
+	}
+	for _, nth := range dow.nth {
+		if int(wd) == nth[0] && (day-1)/7+1 == nth[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	switch {
+	case s.dom.any && s.dow.any:
+		return true
+	case s.dom.any:
+		return s.dow.matches(t)
+	case s.dow.any:
+		return s.dom.matches(t)
+	}
+	return s.dom.matches(t) || s.dow.matches(t)
+}
+
+// Matches reports whether the minute of t matches the Schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	t = t.UTC()
+	return s.months&(1<<uint(t.Month())) != 0 && s.dayMatches(t) &&
+		s.hours&(1<<uint(t.Hour())) != 0 && s.minutes&(1<<uint(t.Minute())) != 0
+}
+
+// searchYears is the range of the search of Next and Prev. Schedules which never match (e.g. `0 0 30 2 *`) return the zero time.
+const searchYears = 5
+
+// Next returns the first time matching the Schedule after t. The result is in UTC.
+//   - if the Schedule never matches, returns the zero time.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(searchYears, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the last time matching the Schedule before t. The result is in UTC.
+//   - This is useful to compute the previous run of the trigger, e.g. from the ScheduledTime of Event.
+//   - if the Schedule never matches, returns the zero time.
+func (s *Schedule) Prev(t time.Time) time.Time {
+	u := t.UTC()
+	t = u.Truncate(time.Minute)
+	if t.Equal(u) {
+		t = t.Add(-time.Minute)
+	}
+	limit := t.AddDate(-searchYears, 0, 0)
+	for t.After(limit) {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Add(-time.Minute)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Add(-time.Minute)
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(-time.Minute)
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(-time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Between returns times matching the Schedule in (from, to], up to limit times.
+//   - This is useful to compute runs missed since the last run (catch-up windows).
+//   - if limit is 0 or less, the number of times is not limited.
+func (s *Schedule) Between(from, to time.Time, limit int) []time.Time {
+	var times []time.Time
+	for t := s.Next(from); !t.IsZero() && !t.After(to); t = s.Next(t) {
+		times = append(times, t)
+		if limit > 0 && len(times) >= limit {
+			break
+		}
+	}
+	return times
+}
@@ -0,0 +1,108 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSchedule_Next(t *testing.T) {
+	tests := map[string]struct {
+		expr string
+		from string
+		want string
+	}{
+		"every 5 minutes":       {expr: "*/5 * * * *", from: "2024-01-01 00:03", want: "2024-01-01 00:05"},
+		"exclusive":             {expr: "*/5 * * * *", from: "2024-01-01 00:05", want: "2024-01-01 00:10"},
+		"hour range with step":  {expr: "0 9-17/4 * * *", from: "2024-01-01 13:30", want: "2024-01-01 17:00"},
+		"daily rolls over":      {expr: "30 23 * * *", from: "2024-12-31 23:30", want: "2025-01-01 23:30"},
+		"month names":           {expr: "0 0 1 jan,JUL *", from: "2024-02-01 00:00", want: "2024-07-01 00:00"},
+		"weekday names":         {expr: "0 12 * * MON-FRI", from: "2024-01-06 00:00", want: "2024-01-08 12:00"},
+		"sunday is 1":           {expr: "0 0 * * 1", from: "2024-01-01 00:00", want: "2024-01-07 00:00"},
+		"last day of month":     {expr: "0 0 L * *", from: "2024-02-01 00:00", want: "2024-02-29 00:00"},
+		"days before last day":  {expr: "0 0 L-2 * *", from: "2024-04-01 00:00", want: "2024-04-28 00:00"},
+		"last weekday":          {expr: "59 23 LW * *", from: "2024-08-01 00:00", want: "2024-08-30 23:59"},
+		"nearest weekday":       {expr: "0 0 15W * *", from: "2024-06-01 00:00", want: "2024-06-14 00:00"},
+		"nearest weekday start": {expr: "0 0 1W * *", from: "2024-05-31 00:00", want: "2024-06-03 00:00"},
+		"last friday":           {expr: "0 0 * * 6L", from: "2024-03-01 00:00", want: "2024-03-29 00:00"},
+		"second monday":         {expr: "0 0 * * MON#2", from: "2024-01-01 00:00", want: "2024-01-08 00:00"},
+		"dom or dow":            {expr: "0 0 13 * FRI", from: "2024-01-06 00:00", want: "2024-01-12 00:00"},
+		"leap day":              {expr: "0 0 29 2 *", from: "2024-03-01 00:00", want: "2028-02-29 00:00"},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			s, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tc.expr, err)
+			}
+			got := s.Next(mustTime(t, tc.from))
+			if want := mustTime(t, tc.want); !got.Equal(want) {
+				t.Errorf("Next() = %v, want %v", got, want)
+			}
+			if !s.Matches(got) {
+				t.Errorf("Matches(%v) = false", got)
+			}
+			if prev := s.Prev(got.Add(time.Minute)); !prev.Equal(got) {
+				t.Errorf("Prev() = %v, want %v", prev, got)
+			}
+		})
+	}
+}
+
+func TestSchedule_NeverMatches(t *testing.T) {
+	s := MustParse("0 0 30 2 *")
+	if got := s.Next(mustTime(t, "2024-01-01 00:00")); !got.IsZero() {
+		t.Errorf("want zero time, got %v", got)
+	}
+}
+
+func TestSchedule_Between(t *testing.T) {
+	s := MustParse("0 */6 * * *")
+	got := s.Between(mustTime(t, "2024-01-01 00:00"), mustTime(t, "2024-01-02 00:00"), 0)
+	if len(got) != 4 || !got[3].Equal(mustTime(t, "2024-01-02 00:00")) {
+		t.Errorf("unexpected times: %v", got)
+	}
+	if got := s.Between(mustTime(t, "2024-01-01 00:00"), mustTime(t, "2024-01-02 00:00"), 2); len(got) != 2 {
+		t.Errorf("want 2 times by limit, got %v", got)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 0",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * * MON#6",
+		"* * 32W * *",
+		"* * * FOO *",
+	} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidExpression", expr, err)
+		}
+	}
+}
+
+func TestSchedule_Equal(t *testing.T) {
+	if !MustParse("0 0 * * SUN").Equal(MustParse("0 0 * * 1")) {
+		t.Error("want equivalent expressions to be equal")
+	}
+	if MustParse("0 0 * * SUN").Equal(MustParse("0 0 * * MON")) {
+		t.Error("want different expressions not to be equal")
+	}
+}
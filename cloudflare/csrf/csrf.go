@@ -0,0 +1,147 @@
+// Package csrf protects form and fetch requests from cross-site request forgery.
+//
+// Middleware keeps a random secret for each client, and accepts unsafe requests (e.g. POST) only if they have
+// the token signed with the secret, which is given to the page by Token.
+//   - By default, the secret is stored in the cookie (signed double-submit cookie pattern).
+//     With the Session option, it's stored in the session of session.Middleware (synchronizer token pattern).
+//   - Tokens are signed by HMAC of the Web Crypto API of the runtime, and change in each response, so they can't be
+//     recovered by compression attacks such as BREACH.
+//   - The cookie has the `__Host-` prefix, so it can't be set by other hosts (e.g. subdomains). Tokens are only as strong as
+//     the secret: a site which can set the cookie can pair its own secret with the token obtained for it.
+//     Use the Session option if the prefix can't be used.
+//   - The token is read from the form field of form requests (urlencoded or multipart), or the header of fetch requests.
+//   - https://cheatsheetseries.owasp.org/cheatsheets/Cross-Site_Request_Forgery_Prevention_Cheat_Sheet.html
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	// ErrNoToken is returned when the request doesn't have the token or the secret.
+	ErrNoToken = errors.New("csrf: no token")
+	// ErrInvalidToken is returned when the token is malformed or not signed with the secret of the client.
+	ErrInvalidToken = errors.New("csrf: invalid token")
+	// ErrOriginMismatch is returned when the request is sent from other origins.
+	ErrOriginMismatch = errors.New("csrf: origin mismatch")
+)
+
+const (
+	// DefaultField is the name of the form field of the token.
+	DefaultField = "csrf_token"
+	// DefaultHeader is the name of the header of the token, which is used by fetch of SPAs.
+	DefaultHeader = "X-CSRF-Token"
+	// DefaultCookieName is the name of the cookie of the secret.
+	DefaultCookieName = hostPrefix + "csrf"
+	// InsecureCookieName is the name of the cookie of the secret when the cookie is not Secure, which can't have the `__Host-` prefix.
+	InsecureCookieName = "csrf"
+	// SessionKey is the key of the secret in the session.
+	SessionKey = "csrf.secret"
+)
+
+// hostPrefix is the prefix of cookies which are accepted by browsers only if they are Secure, have the path `/`
+// and don't have Domain, so they can't be set by other hosts.
+const hostPrefix = "__Host-"
+
+// secretSize and nonceSize are the sizes of random bytes of secrets and nonces of tokens.
+const (
+	secretSize = 32
+	nonceSize  = 16
+)
+
+type tokenKey struct{}
+
+// Token returns the token of the request given by Middleware, which should be embedded in forms as the field
+// (e.g. `<input type="hidden" name="csrf_token" value="...">`), or sent by the header.
+//   - if the request isn't handled by Middleware, returns an empty string.
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(tokenKey{}).(string)
+	return token
+}
+
+// isSafeMethod reports whether the method doesn't change the state of the server, so it's not protected.
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// checkOrigin validates the `Origin` header of the request, which is sent by browsers for unsafe requests.
+//   - Requests without the header (e.g. of old browsers or non-browser clients) are validated only by the token.
+func checkOrigin(req *http.Request, trusted []string) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err == nil && u.Host == req.Host {
+		return nil
+	}
+	for _, o := range trusted {
+		if strings.EqualFold(o, origin) {
+			return nil
+		}
+	}
+	return ErrOriginMismatch
+}
+
+// tokenFromRequest returns the token of the form field, or the header.
+func tokenFromRequest(req *http.Request, field, header string) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if err := req.ParseForm(); err != nil {
+			return "", err
+		}
+	case "multipart/form-data":
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return "", err
+		}
+	}
+	if token := req.PostFormValue(field); token != "" {
+		return token, nil
+	}
+	return req.Header.Get(header), nil
+}
+
+// newRandom returns the random bytes encoded by base64url.
+func newRandom(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// isSecret reports whether s is the secret generated by newRandom.
+func isSecret(s string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	return err == nil && len(b) == secretSize
+}
+
+// parseToken returns the nonce and the signature of the token: `nonce.signature`.
+func parseToken(token string) (nonce string, signature []byte, err error) {
+	nonce, encoded, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return "", nil, ErrInvalidToken
+	}
+	signature, err = base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, ErrInvalidToken
+	}
+	return nonce, signature, nil
+}
+
+// signingInput returns the data signed by the token.
+func signingInput(secret, nonce string) []byte {
+	return []byte(secret + "." + nonce)
+}
@@ -0,0 +1,87 @@
+package csrf
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		want   error
+	}{
+		{"", nil},
+		{"https://example.com", nil},
+		{"https://app.example.com", nil},
+		{"HTTPS://APP.EXAMPLE.COM", nil},
+		{"https://evil.example", ErrOriginMismatch},
+		{"null", ErrOriginMismatch},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if err := checkOrigin(req, []string{"https://app.example.com"}); err != tt.want {
+			t.Errorf("checkOrigin(%q): %v, want %v", tt.origin, err, tt.want)
+		}
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField(DefaultField, "multipart-token")
+	mw.WriteField("name", "alice")
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		header      string
+		want        string
+	}{
+		{"urlencoded", "application/x-www-form-urlencoded", DefaultField + "=form-token&name=alice", "", "form-token"},
+		{"multipart", mw.FormDataContentType(), multipartBody.String(), "", "multipart-token"},
+		{"header", "application/json", `{"name":"alice"}`, "header-token", "header-token"},
+		{"form without field", "application/x-www-form-urlencoded", "name=alice", "header-token", "header-token"},
+		{"none", "text/plain", "name=alice", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.header != "" {
+				req.Header.Set(DefaultHeader, tt.header)
+			}
+			got, err := tokenFromRequest(req, DefaultField, DefaultHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("token: %q, want %q", got, tt.want)
+			}
+			if strings.Contains(tt.contentType, "form") && req.FormValue("name") != "alice" {
+				t.Error("form must be readable by the handler")
+			}
+		})
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	nonce, signature, err := parseToken("bm9uY2U.c2ln")
+	if err != nil || nonce != "bm9uY2U" || string(signature) != "sig" {
+		t.Errorf("parseToken: %q, %q, %v", nonce, signature, err)
+	}
+	for _, token := range []string{"", "nonce", ".c2ln", "nonce.!!"} {
+		if _, _, err := parseToken(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("parseToken(%q): %v", token, err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+//go:build js && wasm
+
+package csrf
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/syumai/workers/cloudflare/session"
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+// Options represents the options of Middleware.
+type Options struct {
+	// Keys are the secret keys of HMAC. The first key signs tokens, and all keys are tried to verify them,
+	// so keys can be rotated by adding the new key to the head.
+	Keys [][]byte
+	// Session stores the secret in the session of session.Middleware, which must wrap Middleware.
+	Session bool
+	// Field is the name of the form field of the token. The default is DefaultField.
+	Field string
+	// Header is the name of the header of the token. The default is DefaultHeader.
+	Header string
+	// CookieName is the name of the cookie of the secret. The default is DefaultCookieName, or InsecureCookieName if Insecure is true.
+	// The name must have the `__Host-` prefix unless Insecure is true.
+	CookieName string
+	// Insecure disables the Secure attribute and the `__Host-` prefix of the cookie, e.g. for `wrangler dev` on http.
+	// Then the cookie can be set by other hosts (e.g. subdomains), which can forge tokens with the secret of their cookies.
+	Insecure bool
+	// TrustedOrigins are origins other than the host of the request allowed to send requests, e.g. `https://app.example.com`.
+	TrustedOrigins []string
+	// OnError writes the response when the request is rejected. The default responds 403 Forbidden,
+	// or 400 Bad Request if the form can't be parsed.
+	OnError func(w http.ResponseWriter, req *http.Request, err error)
+}
+
+// Middleware returns the middleware which rejects unsafe requests without valid tokens.
+//   - Requests of safe methods (GET, HEAD, OPTIONS and TRACE) are not validated.
+//   - The token for the response can be obtained by Token in the handler.
+//   - Forms of unsafe requests are parsed by the middleware, so the handler can read them by FormValue.
+//   - This panics if Keys is empty, or CookieName doesn't have the `__Host-` prefix while Insecure is false.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	if opts == nil || len(opts.Keys) == 0 {
+		panic("csrf: Keys of Options must be set")
+	}
+	p := &protector{opts: *opts}
+	if p.opts.Field == "" {
+		p.opts.Field = DefaultField
+	}
+	if p.opts.Header == "" {
+		p.opts.Header = DefaultHeader
+	}
+	switch {
+	case p.opts.CookieName == "" && p.opts.Insecure:
+		p.opts.CookieName = InsecureCookieName
+	case p.opts.CookieName == "":
+		p.opts.CookieName = DefaultCookieName
+	case !p.opts.Insecure && !strings.HasPrefix(p.opts.CookieName, hostPrefix):
+		panic("csrf: CookieName of Options must have the __Host- prefix unless Insecure is set")
+	}
+	if p.opts.OnError == nil {
+		p.opts.OnError = defaultOnError
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := p.importKeys(); err != nil {
+				log.Printf("%v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			secret, err := p.secret(req)
+			if err != nil {
+				log.Printf("%v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			if !isSafeMethod(req.Method) {
+				if err := p.validate(req, secret); err != nil {
+					p.opts.OnError(w, req, err)
+					return
+				}
+			}
+			if secret == "" {
+				if secret, err = p.newSecret(w, req); err != nil {
+					log.Printf("%v", err)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+			}
+			token, err := p.sign(secret)
+			if err != nil {
+				log.Printf("%v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), tokenKey{}, token)))
+		})
+	}
+}
+
+func defaultOnError(w http.ResponseWriter, req *http.Request, err error) {
+	if errors.Is(err, ErrNoToken) || errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrOriginMismatch) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+}
+
+// protector signs and validates tokens with the keys.
+type protector struct {
+	opts Options
+
+	once sync.Once
+	keys []*webcrypto.Key
+	err  error
+}
+
+var hmacSHA256 = &webcrypto.HMAC{Hash: webcrypto.SHA256}
+
+// importKeys imports the keys at the first request, so Middleware can be created at the start up of the worker.
+func (p *protector) importKeys() error {
+	p.once.Do(func() {
+		for _, k := range p.opts.Keys {
+			key, err := webcrypto.ImportKey(webcrypto.FormatRaw, k, hmacSHA256, false, webcrypto.UsageSign, webcrypto.UsageVerify)
+			if err != nil {
+				p.err = fmt.Errorf("csrf: error importing key: %w", err)
+				return
+			}
+			p.keys = append(p.keys, key)
+		}
+	})
+	return p.err
+}
+
+// secret returns the secret of the client. if the client doesn't have the secret, returns an empty string.
+func (p *protector) secret(req *http.Request) (string, error) {
+	if p.opts.Session {
+		s, ok := session.FromContext(req.Context())
+		if !ok {
+			return "", errors.New("csrf: session is not found; session.Middleware must wrap Middleware")
+		}
+		return s.Get(SessionKey), nil
+	}
+	c, err := req.Cookie(p.opts.CookieName)
+	if err != nil || !isSecret(c.Value) {
+		return "", nil
+	}
+	return c.Value, nil
+}
+
+// newSecret generates the secret of the client, and stores it in the session or the cookie.
+func (p *protector) newSecret(w http.ResponseWriter, req *http.Request) (string, error) {
+	secret, err := newRandom(secretSize)
+	if err != nil {
+		return "", err
+	}
+	if p.opts.Session {
+		s, _ := session.FromContext(req.Context())
+		s.Set(SessionKey, secret)
+		return secret, nil
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     p.opts.CookieName,
+		Value:    secret,
+		Path:     "/",
+		Secure:   !p.opts.Insecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return secret, nil
+}
+
+// validate validates the origin and the token of the request with the secret.
+func (p *protector) validate(req *http.Request, secret string) error {
+	if err := checkOrigin(req, p.opts.TrustedOrigins); err != nil {
+		return err
+	}
+	token, err := tokenFromRequest(req, p.opts.Field, p.opts.Header)
+	if err != nil {
+		return fmt.Errorf("csrf: error parsing form: %w", err)
+	}
+	if token == "" || secret == "" {
+		return ErrNoToken
+	}
+	nonce, signature, err := parseToken(token)
+	if err != nil {
+		return err
+	}
+	for _, key := range p.keys {
+		if ok, err := webcrypto.Verify(hmacSHA256, key, signature, signingInput(secret, nonce)); err == nil && ok {
+			return nil
+		}
+	}
+	return ErrInvalidToken
+}
+
+// sign returns the new token of the secret: `nonce.signature`.
+func (p *protector) sign(secret string) (string, error) {
+	nonce, err := newRandom(nonceSize)
+	if err != nil {
+		return "", err
+	}
+	signature, err := webcrypto.Sign(hmacSHA256, p.keys[0], signingInput(secret, nonce))
+	if err != nil {
+		return "", fmt.Errorf("csrf: error signing token: %w", err)
+	}
+	return nonce + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
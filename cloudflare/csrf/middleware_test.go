@@ -0,0 +1,196 @@
+//go:build js && wasm
+
+package csrf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/session"
+)
+
+// client sends requests to the handler with the cookies of the previous responses.
+type client struct {
+	t       *testing.T
+	handler http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func (c *client) do(req *http.Request) *httptest.ResponseRecorder {
+	c.t.Helper()
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	for _, cookie := range rec.Result().Cookies() {
+		c.cookies[cookie.Name] = cookie
+	}
+	return rec
+}
+
+// form returns the token given by the form page.
+func (c *client) form() string {
+	c.t.Helper()
+	rec := c.do(httptest.NewRequest(http.MethodGet, "https://example.com/form", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() == "" {
+		c.t.Fatalf("form: %d %q", rec.Code, rec.Body.String())
+	}
+	return rec.Body.String()
+}
+
+func (c *client) post(token string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	form := url.Values{"name": {"alice"}}
+	if token != "" {
+		form.Set(DefaultField, token)
+	}
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/submit", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+var testHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost {
+		w.Write([]byte("hello " + req.FormValue("name")))
+		return
+	}
+	w.Write([]byte(Token(req.Context())))
+})
+
+func testMiddleware(t *testing.T, handler http.Handler) {
+	c := &client{t: t, handler: handler, cookies: map[string]*http.Cookie{}}
+	if rec := c.post(""); rec.Code != http.StatusForbidden {
+		t.Fatalf("post without secret: %d", rec.Code)
+	}
+
+	token := c.form()
+	if other := c.form(); other == token {
+		t.Error("tokens must change in each response")
+	}
+	if rec := c.post(token); rec.Code != http.StatusOK || rec.Body.String() != "hello alice" {
+		t.Fatalf("post with token: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := c.post(""); rec.Code != http.StatusForbidden {
+		t.Errorf("post without token: %d", rec.Code)
+	}
+	if rec := c.post(strings.SplitN(token, ".", 2)[0] + ".AAAA"); rec.Code != http.StatusForbidden {
+		t.Errorf("post with forged token: %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/submit", strings.NewReader("name=alice"))
+	req.Header.Set(DefaultHeader, token)
+	if rec := c.do(req); rec.Code != http.StatusOK {
+		t.Errorf("post with header: %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "https://example.com/submit", nil)
+	req.Header.Set(DefaultHeader, token)
+	req.Header.Set("Origin", "https://evil.example")
+	if rec := c.do(req); rec.Code != http.StatusForbidden {
+		t.Errorf("post from other origin: %d", rec.Code)
+	}
+
+	other := &client{t: t, handler: handler, cookies: map[string]*http.Cookie{}}
+	other.form()
+	if rec := other.post(token); rec.Code != http.StatusForbidden {
+		t.Errorf("token of other client: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareCookie(t *testing.T) {
+	testMiddleware(t, Middleware(&Options{Keys: [][]byte{[]byte("secret")}})(testHandler))
+}
+
+func TestMiddlewareSession(t *testing.T) {
+	handler := session.Middleware(&session.Options{Keys: [][]byte{[]byte("session")}})(
+		Middleware(&Options{Keys: [][]byte{[]byte("secret")}, Session: true})(testHandler),
+	)
+	testMiddleware(t, handler)
+}
+
+func TestMiddlewareKeyRotation(t *testing.T) {
+	c := &client{t: t, handler: Middleware(&Options{Keys: [][]byte{[]byte("old")}})(testHandler), cookies: map[string]*http.Cookie{}}
+	token := c.form()
+	c.handler = Middleware(&Options{Keys: [][]byte{[]byte("new"), []byte("old")}})(testHandler)
+	if rec := c.post(token); rec.Code != http.StatusOK {
+		t.Errorf("token of the old key: %d", rec.Code)
+	}
+	c.handler = Middleware(&Options{Keys: [][]byte{[]byte("new")}})(testHandler)
+	if rec := c.post(token); rec.Code != http.StatusForbidden {
+		t.Errorf("token of the removed key: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareOnError(t *testing.T) {
+	var got error
+	handler := Middleware(&Options{
+		Keys: [][]byte{[]byte("secret")},
+		OnError: func(w http.ResponseWriter, req *http.Request, err error) {
+			got = err
+			w.WriteHeader(http.StatusTeapot)
+		},
+	})(testHandler)
+	c := &client{t: t, handler: handler, cookies: map[string]*http.Cookie{}}
+	c.form()
+	if rec := c.post("malformed"); rec.Code != http.StatusTeapot || !errors.Is(got, ErrInvalidToken) {
+		t.Errorf("OnError: %d, %v", rec.Code, got)
+	}
+}
+
+func TestMiddlewareInjectedCookie(t *testing.T) {
+	handler := Middleware(&Options{Keys: [][]byte{[]byte("secret")}})(testHandler)
+
+	// the attacker obtains a secret and its token from the site.
+	attacker := &client{t: t, handler: handler, cookies: map[string]*http.Cookie{}}
+	token := attacker.form()
+	secret := attacker.cookies[DefaultCookieName].Value
+
+	// other hosts can set cookies without the __Host- prefix only.
+	victim := &client{t: t, handler: handler, cookies: map[string]*http.Cookie{
+		InsecureCookieName: {Name: InsecureCookieName, Value: secret},
+	}}
+	if rec := victim.post(token); rec.Code != http.StatusForbidden {
+		t.Errorf("injected cookie: %d", rec.Code)
+	}
+
+	// the token signed by the key of the attacker for the chosen secret.
+	chosen := &client{t: t, handler: Middleware(&Options{Keys: [][]byte{[]byte("attacker")}})(testHandler), cookies: map[string]*http.Cookie{}}
+	forged := chosen.form()
+	victim = &client{t: t, handler: handler, cookies: map[string]*http.Cookie{DefaultCookieName: chosen.cookies[DefaultCookieName]}}
+	if rec := victim.post(forged); rec.Code != http.StatusForbidden {
+		t.Errorf("self-signed token: %d", rec.Code)
+	}
+
+	victim = &client{t: t, handler: handler, cookies: map[string]*http.Cookie{
+		DefaultCookieName: {Name: DefaultCookieName, Value: "chosen"},
+	}}
+	if rec := victim.post("nonce.c2ln"); rec.Code != http.StatusForbidden {
+		t.Errorf("malformed secret: %d", rec.Code)
+	}
+}
+
+func TestMiddlewareCookieName(t *testing.T) {
+	c := &client{t: t, handler: Middleware(&Options{Keys: [][]byte{[]byte("secret")}, Insecure: true})(testHandler), cookies: map[string]*http.Cookie{}}
+	c.form()
+	if cookie := c.cookies[InsecureCookieName]; cookie == nil || cookie.Secure {
+		t.Errorf("insecure cookie: %+v", cookie)
+	}
+
+	c = &client{t: t, handler: Middleware(&Options{Keys: [][]byte{[]byte("secret")}})(testHandler), cookies: map[string]*http.Cookie{}}
+	c.form()
+	if cookie := c.cookies[DefaultCookieName]; cookie == nil || !cookie.Secure || cookie.Path != "/" || cookie.Domain != "" {
+		t.Errorf("cookie: %+v", cookie)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic for CookieName without the __Host- prefix")
+		}
+	}()
+	Middleware(&Options{Keys: [][]byte{[]byte("secret")}, CookieName: "csrf"})
+}
@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package d1
 
 import (
@@ -9,6 +11,8 @@ import (
 
 type Conn struct {
 	dbObj js.Value
+	// session is set when the Conn is created by Session.
+	session *Session
 }
 
 var (
@@ -21,6 +25,7 @@ func (c *Conn) Prepare(query string) (driver.Stmt, error) {
 	stmtObj := c.dbObj.Call("prepare", query)
 	return &stmt{
 		stmtObj: stmtObj,
+		session: c.session,
 	}, nil
 }
 
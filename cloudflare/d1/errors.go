@@ -2,8 +2,116 @@ package d1
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
 	ErrDatabaseNotFound = errors.New("d1: database not found")
+
+	// ErrConstraint is matched by all constraint violation errors.
+	ErrConstraint = errors.New("d1: constraint failed")
+	// ErrUniqueConstraint reports UNIQUE (and PRIMARY KEY) constraint violations.
+	ErrUniqueConstraint = errors.New("d1: UNIQUE constraint failed")
+	// ErrNotNullConstraint reports NOT NULL constraint violations.
+	ErrNotNullConstraint = errors.New("d1: NOT NULL constraint failed")
+	// ErrForeignKeyConstraint reports FOREIGN KEY constraint violations.
+	ErrForeignKeyConstraint = errors.New("d1: FOREIGN KEY constraint failed")
+	// ErrCheckConstraint reports CHECK constraint violations.
+	ErrCheckConstraint = errors.New("d1: CHECK constraint failed")
+	// ErrNoSuchTable is returned when the query refers to a table which doesn't exist.
+	ErrNoSuchTable = errors.New("d1: no such table")
+	// ErrTooManyRequests is returned when D1 is overloaded with queued requests. The query can be retried.
+	ErrTooManyRequests = errors.New("d1: too many requests")
+	// ErrStatementTooLong is returned when the SQL statement exceeds the length limit of D1.
+	ErrStatementTooLong = errors.New("d1: statement too long")
 )
+
+// Error represents an error returned by D1.
+//   - if the error is classified, errors.Is reports true for the corresponding Err* variable.
+//   - https://developers.cloudflare.com/d1/observability/debug-d1/#error-list
+type Error struct {
+	// Message is the error message returned by D1.
+	Message string
+	kind    error
+}
+
+func (e *Error) Error() string {
+	return "d1: " + e.Message
+}
+
+// Unwrap returns the classified Err* variable, or nil if the error is not classified.
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// Is reports whether the error is a constraint violation when target is ErrConstraint.
+func (e *Error) Is(target error) bool {
+	if target != ErrConstraint {
+		return false
+	}
+	switch e.kind {
+	case ErrUniqueConstraint, ErrNotNullConstraint, ErrForeignKeyConstraint, ErrCheckConstraint:
+		return true
+	}
+	return false
+}
+
+// errorKinds is a list of substrings of D1 error messages and corresponding error kinds.
+var errorKinds = []struct {
+	substr string
+	kind   error
+}{
+	{"unique constraint failed", ErrUniqueConstraint},
+	{"not null constraint failed", ErrNotNullConstraint},
+	{"foreign key constraint failed", ErrForeignKeyConstraint},
+	{"check constraint failed", ErrCheckConstraint},
+	{"no such table", ErrNoSuchTable},
+	{"too many requests", ErrTooManyRequests},
+	{"statement too long", ErrStatementTooLong},
+	{"sqlite_toobig", ErrStatementTooLong},
+}
+
+// promiseErrorPrefixes are the prefixes added to JavaScript errors on the way to Go.
+var promiseErrorPrefixes = []string{
+	"failed on promise: ",
+	"Error: ",
+	"D1_ERROR: ",
+}
+
+// toError converts the error occurred on D1 call into *Error.
+func toError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, prefix := range promiseErrorPrefixes {
+		msg = strings.TrimPrefix(msg, prefix)
+	}
+	return &Error{
+		Message: msg,
+		kind:    classifyErrorMessage(msg),
+	}
+}
+
+// WrapError converts the error of a SQL driver into *Error, which is classified in the same way as errors of D1.
+//   - This is useful to use SQLite drivers in place of D1 in tests, since D1 returns messages of SQLite.
+//   - nil and errors which are already *Error are returned as they are.
+func WrapError(err error) error {
+	var d1Err *Error
+	if err == nil || errors.As(err, &d1Err) {
+		return err
+	}
+	return toError(err)
+}
+
+// classifyErrorMessage returns the error kind of given D1 error message.
+// If the error message was not classified, returns nil.
+func classifyErrorMessage(msg string) error {
+	lower := strings.ToLower(msg)
+	for _, k := range errorKinds {
+		if strings.Contains(lower, k.substr) {
+			return k.kind
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package d1
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_toError(t *testing.T) {
+	tests := map[string]struct {
+		err         error
+		wantMessage string
+		wantIs      []error
+		wantIsNot   []error
+	}{
+		"unique constraint": {
+			err:         errors.New("failed on promise: Error: D1_ERROR: UNIQUE constraint failed: users.email: SQLITE_CONSTRAINT"),
+			wantMessage: "UNIQUE constraint failed: users.email: SQLITE_CONSTRAINT",
+			wantIs:      []error{ErrConstraint, ErrUniqueConstraint},
+			wantIsNot:   []error{ErrNotNullConstraint, ErrNoSuchTable},
+		},
+		"not null constraint": {
+			err:       errors.New("failed on promise: Error: D1_ERROR: NOT NULL constraint failed: users.name: SQLITE_CONSTRAINT"),
+			wantIs:    []error{ErrConstraint, ErrNotNullConstraint},
+			wantIsNot: []error{ErrUniqueConstraint},
+		},
+		"foreign key constraint": {
+			err:    errors.New("failed on promise: Error: D1_ERROR: FOREIGN KEY constraint failed: SQLITE_CONSTRAINT"),
+			wantIs: []error{ErrConstraint, ErrForeignKeyConstraint},
+		},
+		"check constraint": {
+			err:    errors.New("failed on promise: Error: D1_ERROR: CHECK constraint failed: age > 0: SQLITE_CONSTRAINT"),
+			wantIs: []error{ErrConstraint, ErrCheckConstraint},
+		},
+		"no such table": {
+			err:         errors.New("failed on promise: Error: D1_ERROR: no such table: articles: SQLITE_ERROR"),
+			wantMessage: "no such table: articles: SQLITE_ERROR",
+			wantIs:      []error{ErrNoSuchTable},
+			wantIsNot:   []error{ErrConstraint},
+		},
+		"too many requests": {
+			err:    errors.New("failed on promise: Error: D1 DB is overloaded. Too many requests queued."),
+			wantIs: []error{ErrTooManyRequests},
+		},
+		"statement too long": {
+			err:    errors.New("failed on promise: Error: D1_ERROR: Statement too long: SQLITE_TOOBIG"),
+			wantIs: []error{ErrStatementTooLong},
+		},
+		"unclassified": {
+			err:         errors.New("failed on promise: Error: D1_ERROR: near \"SELEC\": syntax error: SQLITE_ERROR"),
+			wantMessage: "near \"SELEC\": syntax error: SQLITE_ERROR",
+			wantIsNot:   []error{ErrConstraint, ErrNoSuchTable, ErrTooManyRequests, ErrStatementTooLong},
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := toError(tc.err)
+			var d1Err *Error
+			if !errors.As(err, &d1Err) {
+				t.Fatalf("toError() = %T, want *Error", err)
+			}
+			if tc.wantMessage != "" && d1Err.Message != tc.wantMessage {
+				t.Errorf("Message = %q, want %q", d1Err.Message, tc.wantMessage)
+			}
+			for _, target := range tc.wantIs {
+				if !errors.Is(err, target) {
+					t.Errorf("errors.Is(err, %v) = false, want true", target)
+				}
+			}
+			for _, target := range tc.wantIsNot {
+				if errors.Is(err, target) {
+					t.Errorf("errors.Is(err, %v) = true, want false", target)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapError(t *testing.T) {
+	if err := WrapError(nil); err != nil {
+		t.Errorf("want nil, got %v", err)
+	}
+	err := WrapError(errors.New("constraint failed: UNIQUE constraint failed: users.email (2067)"))
+	if !errors.Is(err, ErrUniqueConstraint) || !errors.Is(err, ErrConstraint) {
+		t.Errorf("want ErrUniqueConstraint, got %v", err)
+	}
+	if wrapped := WrapError(err); wrapped != err {
+		t.Errorf("*Error must be returned as it is, got %v", wrapped)
+	}
+}
@@ -0,0 +1,230 @@
+// Package migration applies SQL migration files to D1 databases.
+//
+// Migration files are `*.sql` files in the root of the given fs.FS (typically an embed.FS).
+// They are applied in lexical order of their names (e.g. `0000_create_articles_table.sql`), so
+// the `migrations` directory created by `wrangler d1 migrations create` can be used as it is.
+// Applied migrations are recorded in the `schema_migrations` table.
+//
+// A migration is claimed by inserting its record before running its statements, and completed by setting `completed_at`.
+// When a worker stops while applying a migration, its record remains uncompleted and Apply returns ErrInProgress
+// until the record is removed manually (e.g. `DELETE FROM schema_migrations WHERE completed_at IS NULL`).
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/d1"
+)
+
+// ErrInProgress is returned by Apply when a migration has been claimed by another worker but is not completed yet.
+//   - Later migrations are not applied, since they may depend on the schema changed by the migration.
+var ErrInProgress = errors.New("migration: migration is in progress")
+
+// DefaultTableName is the default name of the table which records applied migrations.
+const DefaultTableName = "schema_migrations"
+
+// Migrator applies migrations to D1 database.
+type Migrator struct {
+	db        *sql.DB
+	fsys      fs.FS
+	tableName string
+
+	mu      sync.Mutex
+	applied bool
+}
+
+// Option is a type that represents an optional function.
+type Option func(*Migrator)
+
+// WithTableName changes the name of the table which records applied migrations.
+func WithTableName(name string) Option {
+	return func(m *Migrator) {
+		m.tableName = name
+	}
+}
+
+// New returns new Migrator which applies migration files in fsys to db.
+//   - use fs.Sub to specify a sub directory of embed.FS.
+func New(db *sql.DB, fsys fs.FS, opts ...Option) *Migrator {
+	m := &Migrator{
+		db:        db,
+		fsys:      fsys,
+		tableName: DefaultTableName,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Migration represents a migration file.
+type Migration struct {
+	// Name is the file name of the migration.
+	Name string
+	// AppliedAt is the time the migration was completed. This is zero if the migration is pending or in progress.
+	AppliedAt time.Time
+	// InProgress reports whether the migration has been claimed but is not completed.
+	InProgress bool
+}
+
+// Status returns all migrations with their applied time.
+func (m *Migrator) Status(ctx context.Context) ([]*Migration, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	names, err := m.migrationNames()
+	if err != nil {
+		return nil, err
+	}
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Migration, len(names))
+	for i, name := range names {
+		result[i] = &Migration{Name: name}
+		if r, ok := records[name]; ok {
+			result[i].InProgress = r.CompletedAt == nil
+			if r.CompletedAt != nil {
+				result[i].AppliedAt = *r.CompletedAt
+			}
+		}
+	}
+	return result, nil
+}
+
+// Apply applies all pending migrations in order and returns the names of applied migrations.
+//   - Migrations which were already applied are skipped, so calling Apply repeatedly is safe.
+//   - When a migration has been claimed by another worker but is not completed, Apply stops there
+//     and returns ErrInProgress.
+//   - When a statement of a migration fails, its record is removed and Apply returns the error.
+//     D1 doesn't support transactions, so statements executed before the failure are not rolled back.
+func (m *Migrator) Apply(ctx context.Context) ([]string, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	names, err := m.migrationNames()
+	if err != nil {
+		return nil, err
+	}
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, name := range names {
+		if r, ok := records[name]; ok {
+			if r.CompletedAt == nil {
+				return result, fmt.Errorf("%w: %s", ErrInProgress, name)
+			}
+			continue
+		}
+		if err := m.apply(ctx, name); err != nil {
+			return result, err
+		}
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// ApplyOnce calls Apply until it succeeds once in the lifetime of the Migrator.
+//   - This is useful to apply migrations on the first request handled by the worker.
+//   - Calls after the success return nil without accessing the database.
+//     Errors (e.g. ErrInProgress or transient errors of D1) are not cached, so the next call applies migrations again.
+func (m *Migrator) ApplyOnce(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.applied {
+		return nil
+	}
+	if _, err := m.Apply(ctx); err != nil {
+		return err
+	}
+	m.applied = true
+	return nil
+}
+
+// apply claims and applies the migration of given name.
+// If the migration was claimed by another worker, this returns ErrInProgress.
+func (m *Migrator) apply(ctx context.Context, name string) error {
+	b, err := fs.ReadFile(m.fsys, name)
+	if err != nil {
+		return err
+	}
+	_, err = m.db.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (name, claimed_at) VALUES (?, ?)", m.tableName),
+		name, time.Now().Unix())
+	if errors.Is(err, d1.ErrUniqueConstraint) {
+		return fmt.Errorf("%w: %s", ErrInProgress, name)
+	}
+	if err != nil {
+		return fmt.Errorf("migration: failed to record %s: %w", name, err)
+	}
+	for _, stmt := range SplitStatements(string(b)) {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			_, _ = m.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE name = ?", m.tableName), name)
+			return fmt.Errorf("migration: failed to apply %s: %w", name, err)
+		}
+	}
+	_, err = m.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET completed_at = ? WHERE name = ?", m.tableName),
+		time.Now().Unix(), name)
+	if err != nil {
+		return fmt.Errorf("migration: failed to record completion of %s: %w", name, err)
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (name TEXT PRIMARY KEY, claimed_at INTEGER NOT NULL, completed_at INTEGER)", m.tableName))
+	if err != nil {
+		return fmt.Errorf("migration: failed to create %s table: %w", m.tableName, err)
+	}
+	return nil
+}
+
+// migrationNames returns sorted names of migration files.
+func (m *Migrator) migrationNames() ([]string, error) {
+	entries, err := fs.ReadDir(m.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// record is the record of the claimed migration.
+type record struct {
+	Name        string     `d1:"name"`
+	ClaimedAt   time.Time  `d1:"claimed_at"`
+	CompletedAt *time.Time `d1:"completed_at"`
+}
+
+// records returns a map of claimed migration names to their records.
+func (m *Migrator) records(ctx context.Context) (map[string]*record, error) {
+	records, err := d1.QueryAll[record](ctx, m.db, fmt.Sprintf("SELECT name, claimed_at, completed_at FROM %s", m.tableName))
+	if err != nil {
+		return nil, fmt.Errorf("migration: failed to load applied migrations: %w", err)
+	}
+	result := make(map[string]*record, len(records))
+	for i := range records {
+		result[records[i].Name] = &records[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,209 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/syumai/workers/cloudflare/d1"
+)
+
+// fakeDB is the in-memory database which understands statements of Migrator on the migrations table.
+// Other statements are recorded in execs.
+type fakeDB struct {
+	mu      sync.Mutex
+	records map[string][]driver.Value
+	names   []string
+	execs   []string
+	// failExec makes the statement fail.
+	failExec map[string]error
+}
+
+func newFakeDB(t *testing.T) (*fakeDB, *sql.DB) {
+	f := &fakeDB{records: map[string][]driver.Value{}, failExec: map[string]error{}}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { db.Close() })
+	return f, db
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+var (
+	insertRe = regexp.MustCompile(`^INSERT INTO \w+ \(name, claimed_at\)`)
+	updateRe = regexp.MustCompile(`^UPDATE \w+ SET completed_at`)
+	deleteRe = regexp.MustCompile(`^DELETE FROM \w+ WHERE name`)
+	createRe = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS`)
+)
+
+func (f *fakeDB) exec(query string, args []driver.NamedValue) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case createRe.MatchString(query):
+	case insertRe.MatchString(query):
+		name := args[0].Value.(string)
+		if _, ok := f.records[name]; ok {
+			return fmt.Errorf("UNIQUE constraint failed: %w", d1.ErrUniqueConstraint)
+		}
+		f.records[name] = []driver.Value{name, args[1].Value, nil}
+		f.names = append(f.names, name)
+	case updateRe.MatchString(query):
+		f.records[args[1].Value.(string)][2] = args[0].Value
+	case deleteRe.MatchString(query):
+		delete(f.records, args[0].Value.(string))
+	default:
+		if err := f.failExec[query]; err != nil {
+			return err
+		}
+		f.execs = append(f.execs, query)
+	}
+	return nil
+}
+
+func (f *fakeDB) claim(name string, completed bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var completedAt driver.Value
+	if completed {
+		completedAt = int64(1)
+	}
+	f.records[name] = []driver.Value{name, int64(1), completedAt}
+	f.names = append(f.names, name)
+}
+
+type fakeConn struct{ f *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) { return &fakeStmt{c.f, query}, nil }
+func (c *fakeConn) Close() error                              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type fakeStmt struct {
+	f     *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *fakeStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.f.exec(s.query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) QueryContext(context.Context, []driver.NamedValue) (driver.Rows, error) {
+	s.f.mu.Lock()
+	defer s.f.mu.Unlock()
+	r := &fakeRows{}
+	for _, name := range s.f.names {
+		if rec, ok := s.f.records[name]; ok {
+			r.values = append(r.values, rec)
+		}
+	}
+	return r, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"name", "claimed_at", "completed_at"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+var testFS = fstest.MapFS{
+	"0000_create_users.sql":    {Data: []byte("CREATE TABLE users (id INTEGER)")},
+	"0001_create_articles.sql": {Data: []byte("CREATE TABLE articles (id INTEGER)")},
+	"0002_add_title.sql":       {Data: []byte("ALTER TABLE articles ADD COLUMN title TEXT")},
+}
+
+func TestApply(t *testing.T) {
+	f, db := newFakeDB(t)
+	f.claim("0000_create_users.sql", true)
+	applied, err := New(db, testFS).Apply(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprint([]string{"0001_create_articles.sql", "0002_add_title.sql"}); fmt.Sprint(applied) != want {
+		t.Errorf("applied: want %s, got %v", want, applied)
+	}
+	if len(f.execs) != 2 {
+		t.Errorf("unexpected statements: %v", f.execs)
+	}
+	status, err := New(db, testFS).Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range status {
+		if s.AppliedAt.IsZero() || s.InProgress {
+			t.Errorf("%s must be applied: %+v", s.Name, s)
+		}
+	}
+}
+
+func TestApply_inProgress(t *testing.T) {
+	f, db := newFakeDB(t)
+	f.claim("0000_create_users.sql", true)
+	f.claim("0001_create_articles.sql", false)
+	applied, err := New(db, testFS).Apply(context.Background())
+	if !errors.Is(err, ErrInProgress) {
+		t.Fatalf("want ErrInProgress, got %v", err)
+	}
+	if len(applied) != 0 || len(f.execs) != 0 {
+		t.Errorf("migrations after the one in progress must not be applied: applied %v, statements %v", applied, f.execs)
+	}
+	status, err := New(db, testFS).Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status[1].InProgress || !status[1].AppliedAt.IsZero() {
+		t.Errorf("unexpected status: %+v", status[1])
+	}
+}
+
+func TestApplyOnce_retriesAfterError(t *testing.T) {
+	f, db := newFakeDB(t)
+	errTransient := errors.New("D1_ERROR: too many requests")
+	f.failExec["CREATE TABLE users (id INTEGER)"] = errTransient
+	m := New(db, testFS)
+	if err := m.ApplyOnce(context.Background()); !errors.Is(err, errTransient) {
+		t.Fatalf("want the transient error, got %v", err)
+	}
+	if _, ok := f.records["0000_create_users.sql"]; ok {
+		t.Error("the record of the failed migration must be removed")
+	}
+	delete(f.failExec, "CREATE TABLE users (id INTEGER)")
+	if err := m.ApplyOnce(context.Background()); err != nil {
+		t.Fatalf("ApplyOnce must retry after the error: %v", err)
+	}
+	if len(f.execs) != 3 {
+		t.Errorf("unexpected statements: %v", f.execs)
+	}
+	f.failExec["CREATE TABLE users (id INTEGER)"] = errTransient
+	if err := m.ApplyOnce(context.Background()); err != nil {
+		t.Errorf("ApplyOnce must not apply again after the success: %v", err)
+	}
+}
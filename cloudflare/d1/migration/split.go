@@ -0,0 +1,142 @@
+package migration
+
+import (
+	"strings"
+)
+
+// SplitStatements splits SQL text into statements separated by semicolons.
+//   - semicolons in string literals, quoted identifiers and comments are not treated as separators.
+//   - semicolons in the body (BEGIN ... END) of CREATE TRIGGER statements are not treated as separators.
+//   - comments are kept as a part of statements, and empty statements are removed.
+func SplitStatements(sql string) []string {
+	var (
+		stmts []string
+		start int
+		// words holds upper-cased leading words of current statement to detect CREATE TRIGGER.
+		words     []string
+		inTrigger bool
+		caseDepth int
+		lastWord  string
+	)
+	flush := func(end int) {
+		stmt := strings.TrimSpace(sql[start:end])
+		if stmt != "" && !isCommentOnly(stmt) {
+			stmts = append(stmts, stmt)
+		}
+		start = end + 1
+		words = words[:0]
+		inTrigger = false
+		caseDepth = 0
+		lastWord = ""
+	}
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i, c)
+		case c == '[':
+			if j := strings.IndexByte(sql[i:], ']'); j >= 0 {
+				i += j
+			} else {
+				i = len(sql)
+			}
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(sql)
+			}
+		case isWordChar(c):
+			j := i
+			for j < len(sql) && isWordChar(sql[j]) {
+				j++
+			}
+			word := strings.ToUpper(sql[i:j])
+			if len(words) < 4 {
+				words = append(words, word)
+				inTrigger = inTrigger || isCreateTrigger(words)
+			}
+			switch word {
+			case "CASE":
+				caseDepth++
+			case "END":
+				if caseDepth > 0 {
+					caseDepth--
+					word = ""
+				}
+			}
+			lastWord = word
+			i = j - 1
+		case c == ';':
+			if inTrigger && lastWord != "END" {
+				continue
+			}
+			flush(i)
+		}
+	}
+	if start < len(sql) {
+		flush(len(sql))
+	}
+	return stmts
+}
+
+// skipQuoted returns the index of the closing quote of the quoted text which starts at i.
+// Doubled quotes are treated as escaped quotes.
+func skipQuoted(sql string, i int, quote byte) int {
+	for j := i + 1; j < len(sql); j++ {
+		if sql[j] != quote {
+			continue
+		}
+		if j+1 < len(sql) && sql[j+1] == quote {
+			j++
+			continue
+		}
+		return j
+	}
+	return len(sql)
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// isCreateTrigger reports whether the leading words are `CREATE [TEMP|TEMPORARY] TRIGGER`.
+func isCreateTrigger(words []string) bool {
+	if len(words) < 2 || words[0] != "CREATE" {
+		return false
+	}
+	if words[1] == "TRIGGER" {
+		return true
+	}
+	return len(words) >= 3 && (words[1] == "TEMP" || words[1] == "TEMPORARY") && words[2] == "TRIGGER"
+}
+
+// isCommentOnly reports whether the statement consists of comments only.
+func isCommentOnly(stmt string) bool {
+	for len(stmt) > 0 {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			j := strings.IndexByte(stmt, '\n')
+			if j < 0 {
+				return true
+			}
+			stmt = stmt[j+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			j := strings.Index(stmt, "*/")
+			if j < 0 {
+				return true
+			}
+			stmt = stmt[j+2:]
+		default:
+			return stmt == ""
+		}
+	}
+	return true
+}
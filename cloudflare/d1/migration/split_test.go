@@ -0,0 +1,81 @@
+package migration
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := map[string]struct {
+		sql  string
+		want []string
+	}{
+		"single statement without semicolon": {
+			sql:  "CREATE TABLE a (id INTEGER)",
+			want: []string{"CREATE TABLE a (id INTEGER)"},
+		},
+		"multiple statements": {
+			sql: `
+CREATE TABLE a (id INTEGER);
+CREATE INDEX idx_a ON a (id);
+`,
+			want: []string{
+				"CREATE TABLE a (id INTEGER)",
+				"CREATE INDEX idx_a ON a (id)",
+			},
+		},
+		"semicolons in literals and identifiers": {
+			sql: `INSERT INTO "a;b" VALUES ('x;y', 'it''s;', [c;d], ` + "`e;f`" + `); SELECT 1;`,
+			want: []string{
+				`INSERT INTO "a;b" VALUES ('x;y', 'it''s;', [c;d], ` + "`e;f`" + `)`,
+				"SELECT 1",
+			},
+		},
+		"semicolons in comments": {
+			sql: `-- comment; here
+SELECT 1; /* block; comment */ SELECT 2;
+-- trailing comment`,
+			want: []string{
+				"-- comment; here\nSELECT 1",
+				"/* block; comment */ SELECT 2",
+			},
+		},
+		"trigger": {
+			sql: `CREATE TRIGGER t AFTER INSERT ON a
+BEGIN
+  UPDATE b SET n = CASE WHEN n IS NULL THEN 1 ELSE n + 1 END;
+  INSERT INTO c VALUES (1);
+END;
+SELECT 1;`,
+			want: []string{
+				`CREATE TRIGGER t AFTER INSERT ON a
+BEGIN
+  UPDATE b SET n = CASE WHEN n IS NULL THEN 1 ELSE n + 1 END;
+  INSERT INTO c VALUES (1);
+END`,
+				"SELECT 1",
+			},
+		},
+		"temporary trigger": {
+			sql: `CREATE TEMP TRIGGER t AFTER DELETE ON a BEGIN DELETE FROM b; END; SELECT 1`,
+			want: []string{
+				"CREATE TEMP TRIGGER t AFTER DELETE ON a BEGIN DELETE FROM b; END",
+				"SELECT 1",
+			},
+		},
+		"empty statements": {
+			sql:  ";;  ; -- only comment\n",
+			want: nil,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := SplitStatements(tc.sql); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("SplitStatements() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,11 @@
+//go:build js && wasm
+
 package d1
 
 import (
 	"database/sql/driver"
 	"errors"
 	"io"
-	"math"
 	"sync"
 	"syscall/js"
 
@@ -35,7 +36,7 @@ func (r *rows) Columns() []string {
 			// return nothing when row count is zero.
 			return
 		}
-		colsArray := jsutil.ObjectClass.Call("keys", r.rowsObj.Index(0))
+		colsArray := jsutil.ObjectClass().Call("keys", r.rowsObj.Index(0))
 		colsLen := colsArray.Length()
 		cols := make([]string, colsLen)
 		for i := 0; i < colsLen; i++ {
@@ -51,15 +52,6 @@ func (r *rows) Close() error {
 	return nil
 }
 
-// isIntegralNumber returns if given float64 value is integral value or not.
-func isIntegralNumber(f float64) bool {
-	// If the value is NaN or Inf, returns the value to avoid being mistakenly treated as an integral value.
-	if math.IsNaN(f) || math.IsInf(f, 0) {
-		return false
-	}
-	return f == math.Trunc(f)
-}
-
 // convertRowColumnValueToDriverValue converts row column's value in JS to Go's driver.Value.
 // row column value is `null | Number | String | ArrayBuffer`.
 // see: https://developers.cloudflare.com/d1/platform/client-api/#type-conversion
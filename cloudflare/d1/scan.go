@@ -0,0 +1,342 @@
+package d1
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Queryer is an interface to run queries.
+// *sql.DB, *sql.Conn and *sql.Tx satisfy this interface.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// QueryAll runs the query and scans all result rows into a slice of T.
+//   - T must be a struct type. see ScanRows for the rules of column mapping.
+//   - if no rows were found, this returns an empty slice.
+func QueryAll[T any](ctx context.Context, q Queryer, query string, args ...any) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ScanRows[T](rows)
+}
+
+// QueryFirst runs the query and scans the first result row into T.
+//   - T must be a struct type. see ScanRows for the rules of column mapping.
+//   - if no rows were found, this returns sql.ErrNoRows.
+func QueryFirst[T any](ctx context.Context, q Queryer, query string, args ...any) (T, error) {
+	var zero T
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return zero, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return zero, err
+		}
+		return zero, sql.ErrNoRows
+	}
+	var v T
+	if err := scanRow(rows, &v); err != nil {
+		return zero, err
+	}
+	return v, nil
+}
+
+// ScanRows scans all rows into a slice of T and closes rows.
+// Each column is mapped onto a struct field by the following rules.
+//   - a field tagged with `d1:"name"` receives the column `name`.
+//   - a field without tag receives the column whose name equals to the field name (case-insensitive).
+//   - a field tagged with `d1:"-"` is ignored, as well as unexported fields.
+//   - fields of embedded structs are treated as fields of the outer struct.
+//   - columns without a corresponding field are ignored.
+//
+// Column values are converted as follows.
+//   - NULL is set as nil to pointer fields. scanning NULL into a non-pointer field returns error.
+//   - time.Time fields accept text (RFC 3339 or SQLite's `YYYY-MM-DD HH:MM:SS` format) and integers (UNIX seconds).
+//   - bool fields accept integers (0 is false, others are true).
+//   - fields implementing sql.Scanner receive the raw column value.
+func ScanRows[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+	result := []T{}
+	for rows.Next() {
+		var v T
+		if err := scanRow(rows, &v); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scanRow scans current row of given rows into dest.
+//   - dest must be a pointer to struct.
+func scanRow(rows *sql.Rows, dest any) error {
+	rv := reflect.ValueOf(dest).Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("d1: scan destination must be a struct, but got %s", rv.Type())
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]any, len(cols))
+	valuePtrs := make([]any, len(cols))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return err
+	}
+	fields := structFieldsOf(rv.Type())
+	for i, col := range cols {
+		index, ok := fields[strings.ToLower(col)]
+		if !ok {
+			continue
+		}
+		field := rv.FieldByIndex(index)
+		if err := assignValue(field, values[i]); err != nil {
+			return fmt.Errorf("d1: failed to scan column %q into %s: %w", col, rv.Type(), err)
+		}
+	}
+	return nil
+}
+
+// structFieldsCache caches the result of structFieldsOf.
+var structFieldsCache sync.Map // map[reflect.Type]map[string][]int
+
+// structFieldsOf returns a map of lower-cased column names to field indexes of given struct type.
+func structFieldsOf(t reflect.Type) map[string][]int {
+	if v, ok := structFieldsCache.Load(t); ok {
+		return v.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	collectStructFields(t, nil, fields)
+	structFieldsCache.Store(t, fields)
+	return fields
+}
+
+func collectStructFields(t reflect.Type, parentIndex []int, fields map[string][]int) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("d1")
+		if tag == "-" {
+			continue
+		}
+		index := make([]int, len(parentIndex)+1)
+		copy(index, parentIndex)
+		index[len(parentIndex)] = i
+		if f.Anonymous && !hasTag && f.Type.Kind() == reflect.Struct {
+			collectStructFields(f.Type, index, fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag != "" {
+			name = tag
+		}
+		name = strings.ToLower(name)
+		// fields of the outer struct take precedence over embedded ones.
+		if existing, ok := fields[name]; ok && len(existing) <= len(index) {
+			continue
+		}
+		fields[name] = index
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// sqliteTimeLayouts are text formats of time values which SQLite uses.
+//   - https://www.sqlite.org/lang_datefunc.html
+var sqliteTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseTime parses text value of time column.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range sqliteTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %q", s)
+}
+
+// assignValue assigns a column value which came from driver into the field.
+// src is one of `nil | int64 | float64 | string | []byte`.
+func assignValue(field reflect.Value, src any) error {
+	// type assertion is used instead of reflect.Type.Implements, which TinyGo doesn't support.
+	if field.CanAddr() {
+		if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(src)
+		}
+	}
+	if field.Kind() == reflect.Pointer {
+		if src == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		v := reflect.New(field.Type().Elem())
+		if err := assignValue(v.Elem(), src); err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
+	if src == nil {
+		return fmt.Errorf("cannot assign NULL to non-pointer type %s", field.Type())
+	}
+
+	if field.Type() == timeType {
+		var t time.Time
+		switch v := src.(type) {
+		case int64:
+			t = time.Unix(v, 0).UTC()
+		case float64:
+			t = time.UnixMilli(int64(v * 1000)).UTC()
+		case string:
+			var err error
+			t, err = parseTime(v)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cannot assign %T to time.Time", src)
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		switch v := src.(type) {
+		case string:
+			field.SetString(v)
+		case []byte:
+			field.SetString(string(v))
+		case int64:
+			field.SetString(strconv.FormatInt(v, 10))
+		case float64:
+			field.SetString(strconv.FormatFloat(v, 'g', -1, 64))
+		default:
+			return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+		}
+		return nil
+	case reflect.Bool:
+		switch v := src.(type) {
+		case int64:
+			field.SetBool(v != 0)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+		default:
+			return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch v := src.(type) {
+		case int64:
+			i = v
+		case float64:
+			if !isIntegralNumber(v) {
+				return fmt.Errorf("cannot assign non-integral number %v to %s", v, field.Type())
+			}
+			i = int64(v)
+		case string:
+			var err error
+			i, err = strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+		}
+		if field.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %s", i, field.Type())
+		}
+		field.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var i int64
+		switch v := src.(type) {
+		case int64:
+			i = v
+		case float64:
+			if !isIntegralNumber(v) {
+				return fmt.Errorf("cannot assign non-integral number %v to %s", v, field.Type())
+			}
+			i = int64(v)
+		default:
+			return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+		}
+		if i < 0 || field.OverflowUint(uint64(i)) {
+			return fmt.Errorf("value %d overflows %s", i, field.Type())
+		}
+		field.SetUint(uint64(i))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		switch v := src.(type) {
+		case int64:
+			field.SetFloat(float64(v))
+		case float64:
+			field.SetFloat(v)
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return err
+			}
+			field.SetFloat(f)
+		default:
+			return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+		}
+		return nil
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		switch v := src.(type) {
+		case []byte:
+			b := make([]byte, len(v))
+			copy(b, v)
+			field.SetBytes(b)
+		case string:
+			field.SetBytes([]byte(v))
+		default:
+			return fmt.Errorf("cannot assign %T to %s", src, field.Type())
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported field type %s", field.Type())
+}
+
+// isIntegralNumber returns if given float64 value is integral value or not.
+func isIntegralNumber(f float64) bool {
+	// If the value is NaN or Inf, returns the value to avoid being mistakenly treated as an integral value.
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return false
+	}
+	return f == math.Trunc(f)
+}
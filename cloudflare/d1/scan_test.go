@@ -0,0 +1,130 @@
+package d1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_structFieldsOf(t *testing.T) {
+	type Base struct {
+		ID        int64
+		CreatedAt time.Time `d1:"created_at"`
+	}
+	type Article struct {
+		Base
+		Title   string
+		Body    *string `d1:"content"`
+		Ignored string  `d1:"-"`
+		private string
+	}
+	got := structFieldsOf(reflect.TypeOf(Article{}))
+	want := map[string][]int{
+		"id":         {0, 0},
+		"created_at": {0, 1},
+		"title":      {1},
+		"content":    {2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("structFieldsOf() = %v, want %v", got, want)
+	}
+}
+
+func Test_assignValue(t *testing.T) {
+	str := "hello"
+	tests := map[string]struct {
+		dest    any
+		src     any
+		want    any
+		wantErr bool
+	}{
+		"string from text": {
+			dest: new(string),
+			src:  "hello",
+			want: "hello",
+		},
+		"int from integer": {
+			dest: new(int),
+			src:  int64(42),
+			want: 42,
+		},
+		"int overflow": {
+			dest:    new(int8),
+			src:     int64(1000),
+			wantErr: true,
+		},
+		"uint from negative integer": {
+			dest:    new(uint),
+			src:     int64(-1),
+			wantErr: true,
+		},
+		"float from integer": {
+			dest: new(float64),
+			src:  int64(1),
+			want: float64(1),
+		},
+		"bool from integer": {
+			dest: new(bool),
+			src:  int64(1),
+			want: true,
+		},
+		"pointer from NULL": {
+			dest: new(*string),
+			src:  nil,
+			want: (*string)(nil),
+		},
+		"pointer from text": {
+			dest: new(*string),
+			src:  "hello",
+			want: &str,
+		},
+		"non-pointer from NULL": {
+			dest:    new(string),
+			src:     nil,
+			wantErr: true,
+		},
+		"time from UNIX seconds": {
+			dest: new(time.Time),
+			src:  int64(1672531200),
+			want: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"time from SQLite datetime": {
+			dest: new(time.Time),
+			src:  "2023-01-01 12:34:56",
+			want: time.Date(2023, 1, 1, 12, 34, 56, 0, time.UTC),
+		},
+		"time from RFC 3339": {
+			dest: new(time.Time),
+			src:  "2023-01-01T12:34:56Z",
+			want: time.Date(2023, 1, 1, 12, 34, 56, 0, time.UTC),
+		},
+		"time from invalid text": {
+			dest:    new(time.Time),
+			src:     "yesterday",
+			wantErr: true,
+		},
+		"bytes from text": {
+			dest: new([]byte),
+			src:  "abc",
+			want: []byte("abc"),
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			field := reflect.ValueOf(tc.dest).Elem()
+			err := assignValue(field, tc.src)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("assignValue() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := field.Interface(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("assignValue() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
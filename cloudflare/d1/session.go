@@ -0,0 +1,106 @@
+//go:build js && wasm
+
+package d1
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+)
+
+// Session constraints which can be given to OpenSession instead of bookmarks.
+//   - https://developers.cloudflare.com/d1/best-practices/read-replication/#use-sessions-api
+const (
+	// SessionFirstPrimary directs the first query of the session to the primary database.
+	SessionFirstPrimary = "first-primary"
+	// SessionFirstUnconstrained allows the first query of the session to be served by any replica.
+	SessionFirstUnconstrained = "first-unconstrained"
+)
+
+// Session represents D1 database session which provides sequential consistency with read replication.
+//   - Session implements driver.Connector, so it can be used with sql.OpenDB.
+//   - https://developers.cloudflare.com/d1/worker-api/d1-database/#withsession
+type Session struct {
+	sessionObj js.Value
+
+	mu       sync.Mutex
+	servedBy ServedBy
+}
+
+var (
+	_ driver.Connector = (*Session)(nil)
+)
+
+// ServedBy represents the information of the database instance which served a query.
+type ServedBy struct {
+	// Region is the region of the database instance. e.g. "WEUR".
+	Region string
+	// Primary reports whether the query was served by the primary database instance.
+	Primary bool
+}
+
+// OpenSession starts a new session of D1 for given binding name.
+//   - constraintOrBookmark is one of SessionFirstPrimary, SessionFirstUnconstrained or a bookmark returned by Session.Bookmark.
+//   - if constraintOrBookmark is empty, SessionFirstUnconstrained is used.
+//   - This method checks DB existence. If DB was not found, this function returns error.
+func OpenSession(ctx context.Context, name string, constraintOrBookmark string) (*Session, error) {
+	v := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(name)
+	if v.IsUndefined() {
+		return nil, ErrDatabaseNotFound
+	}
+	var sessionObj js.Value
+	if constraintOrBookmark == "" {
+		sessionObj = v.Call("withSession")
+	} else {
+		sessionObj = v.Call("withSession", constraintOrBookmark)
+	}
+	return &Session{sessionObj: sessionObj}, nil
+}
+
+// Connect returns Conn of D1 bound to the session.
+func (s *Session) Connect(context.Context) (driver.Conn, error) {
+	return &Conn{dbObj: s.sessionObj, session: s}, nil
+}
+
+func (s *Session) Driver() driver.Driver {
+	return &Driver{}
+}
+
+// Bookmark returns the latest bookmark of the session.
+//   - Passing the bookmark to OpenSession in subsequent requests (e.g. via cookies or headers) keeps sequential consistency.
+//   - if no query has been executed in the session, this returns empty string.
+func (s *Session) Bookmark() string {
+	v := s.sessionObj.Call("getBookmark")
+	if v.IsNull() || v.IsUndefined() {
+		return ""
+	}
+	return v.String()
+}
+
+// ServedBy returns the information of the database instance which served the latest query of the session.
+func (s *Session) ServedBy() ServedBy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.servedBy
+}
+
+// recordMeta records `meta` object of the query result.
+//   - https://developers.cloudflare.com/d1/worker-api/return-object/#d1result
+func (s *Session) recordMeta(metaObj js.Value) {
+	if s == nil || metaObj.IsUndefined() || metaObj.IsNull() {
+		return
+	}
+	var servedBy ServedBy
+	if v := metaObj.Get("served_by_region"); v.Type() == js.TypeString {
+		servedBy.Region = v.String()
+	}
+	if v := metaObj.Get("served_by_primary"); v.Type() == js.TypeBoolean {
+		servedBy.Primary = v.Bool()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.servedBy = servedBy
+}
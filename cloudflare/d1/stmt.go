@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package d1
 
 import (
@@ -11,6 +13,7 @@ import (
 
 type stmt struct {
 	stmtObj js.Value
+	session *Session
 }
 
 var (
@@ -35,16 +38,17 @@ func (s *stmt) Exec([]driver.Value) (driver.Result, error) {
 
 // ExecContext executes prepared statement.
 // Given []drier.NamedValue's `Name` field will be ignored because Cloudflare D1 client doesn't support it.
-func (s *stmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	argValues := make([]any, len(args))
 	for i, arg := range args {
 		argValues[i] = arg.Value
 	}
 	resultPromise := s.stmtObj.Call("bind", argValues...).Call("run")
-	resultObj, err := jsutil.AwaitPromise(resultPromise)
+	resultObj, err := jsutil.AwaitPromiseContext(ctx, resultPromise)
 	if err != nil {
-		return nil, err
+		return nil, toError(err)
 	}
+	s.session.recordMeta(resultObj.Get("meta"))
 	return &result{
 		resultObj: resultObj,
 	}, nil
@@ -54,19 +58,20 @@ func (s *stmt) Query([]driver.Value) (driver.Rows, error) {
 	return nil, errors.New("d1: Query is deprecated and not implemented")
 }
 
-func (s *stmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	argValues := make([]any, len(args))
 	for i, arg := range args {
-		argValues[i] = arg
+		argValues[i] = arg.Value
 	}
 	resultPromise := s.stmtObj.Call("bind", argValues...).Call("all")
-	rowsObj, err := jsutil.AwaitPromise(resultPromise)
+	rowsObj, err := jsutil.AwaitPromiseContext(ctx, resultPromise)
 	if err != nil {
-		return nil, err
+		return nil, toError(err)
 	}
 	if !rowsObj.Get("success").Bool() {
 		return nil, errors.New("d1: failed to query")
 	}
+	s.session.recordMeta(rowsObj.Get("meta"))
 	return &rows{
 		rowsObj: rowsObj.Get("results"),
 	}, nil
@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package d1
+
+import (
+	"context"
+	"database/sql/driver"
+	"syscall/js"
+	"testing"
+)
+
+// newFakeStmtObj returns D1PreparedStatement which records values given to bind into bound.
+func newFakeStmtObj(bound *[]js.Value) js.Value {
+	newObj := js.Global().Get("Function").New("record", `
+		const result = { success: true, results: [], meta: {} };
+		return {
+			bind(...args) {
+				record(...args);
+				return {
+					all: () => Promise.resolve(result),
+					run: () => Promise.resolve(result),
+				};
+			},
+		};`)
+	record := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		*bound = append(*bound, args...)
+		return nil
+	})
+	return newObj.Invoke(record)
+}
+
+func TestStmt_bindsArgValues(t *testing.T) {
+	args := []driver.NamedValue{
+		{Ordinal: 1, Value: "alice"},
+		{Ordinal: 2, Value: int64(42)},
+	}
+	for name, call := range map[string]func(s *stmt) error{
+		"QueryContext": func(s *stmt) error {
+			_, err := s.QueryContext(context.Background(), args)
+			return err
+		},
+		"ExecContext": func(s *stmt) error {
+			_, err := s.ExecContext(context.Background(), args)
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var bound []js.Value
+			s := &stmt{stmtObj: newFakeStmtObj(&bound)}
+			if err := call(s); err != nil {
+				t.Fatal(err)
+			}
+			if len(bound) != 2 || bound[0].String() != "alice" || bound[1].Int() != 42 {
+				t.Errorf("unexpected bound values: %v", bound)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+//go:build js && wasm
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// DispatchNamespace represents the binding of a dispatch namespace of Workers for Platforms.
+//   - https://developers.cloudflare.com/cloudflare-for-platforms/workers-for-platforms/
+type DispatchNamespace struct {
+	instance js.Value
+}
+
+// NewDispatchNamespace returns DispatchNamespace for given variable name.
+//   - variable name must be defined in wrangler.toml as dispatch_namespaces's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewDispatchNamespace(ctx context.Context, varName string) (*DispatchNamespace, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &DispatchNamespace{instance: inst}, nil
+}
+
+// DispatchLimits represents the custom limits of user workers.
+//   - https://developers.cloudflare.com/cloudflare-for-platforms/workers-for-platforms/configuration/custom-limits/
+type DispatchLimits struct {
+	// CPUMs is the maximum CPU time of a request in milliseconds.
+	CPUMs int
+	// SubRequests is the maximum number of subrequests of a request.
+	SubRequests int
+}
+
+// DispatchOptions represents the options of Get.
+type DispatchOptions struct {
+	Limits *DispatchLimits
+	// Outbound are the parameters passed to the outbound worker of the namespace.
+	// values are converted to JavaScript values by `jsutil.ToJSValue`.
+	//   - https://developers.cloudflare.com/cloudflare-for-platforms/workers-for-platforms/configuration/outbound-workers/
+	Outbound map[string]any
+}
+
+func (opts *DispatchOptions) toJS() (js.Value, error) {
+	if opts == nil {
+		return js.Undefined(), nil
+	}
+	obj := jsutil.NewObject()
+	if opts.Limits != nil {
+		limits := jsutil.NewObject()
+		if opts.Limits.CPUMs > 0 {
+			limits.Set("cpuMs", opts.Limits.CPUMs)
+		}
+		if opts.Limits.SubRequests > 0 {
+			limits.Set("subRequests", opts.Limits.SubRequests)
+		}
+		obj.Set("limits", limits)
+	}
+	if opts.Outbound != nil {
+		outbound, err := jsutil.ToJSValue(opts.Outbound)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("error converting outbound: %w", err)
+		}
+		obj.Set("outbound", outbound)
+	}
+	return obj, nil
+}
+
+// Get returns the user worker of the name in the namespace.
+//   - Get doesn't check existence of the user worker. Requests to non-existent workers fail with an error.
+func (ns *DispatchNamespace) Get(name string, opts *DispatchOptions) (*DispatchedWorker, error) {
+	optsObj, err := opts.toJS()
+	if err != nil {
+		return nil, err
+	}
+	inst, err := jsutil.TryCall(ns.instance, "get", name, jsutil.NewObject(), optsObj)
+	if err != nil {
+		return nil, err
+	}
+	return &DispatchedWorker{instance: inst}, nil
+}
+
+// DispatchedWorker represents a user worker in the dispatch namespace.
+type DispatchedWorker struct {
+	instance js.Value
+}
+
+// Fetch sends the request to the user worker.
+func (w *DispatchedWorker) Fetch(req *http.Request) (*http.Response, error) {
+	promise, err := jsutil.TryCall(w.instance, "fetch", jshttp.ToJSRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	jsRes, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return jshttp.ToResponse(jsRes)
+}
+
+// HTTPClient returns *http.Client which sends requests to the user worker.
+func (w *DispatchedWorker) HTTPClient(redirect fetch.RedirectMode) *http.Client {
+	return fetch.NewClient(fetch.WithBinding(w.instance)).HTTPClient(redirect)
+}
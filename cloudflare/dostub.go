@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package cloudflare
 
 import (
@@ -36,14 +38,140 @@ func (ns *DurableObjectNamespace) IdFromName(name string) *DurableObjectId {
 	return &DurableObjectId{val: id}
 }
 
+// IdFromString returns a `DurableObjectId` parsed from the string representation of the ID.
+//   - The string must be a hex string created by `DurableObjectId`'s string conversion.
+//   - if the given string is not a valid ID for the namespace, returns error.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#idfromstring
+func (ns *DurableObjectNamespace) IdFromString(id string) (*DurableObjectId, error) {
+	v, err := jsutil.TryCall(ns.instance, "idFromString", id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid durable object id %q: %w", id, err)
+	}
+	return &DurableObjectId{val: v}, nil
+}
+
+// NewUniqueId returns a new random `DurableObjectId`.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#newuniqueid
+func (ns *DurableObjectNamespace) NewUniqueId() *DurableObjectId {
+	id := ns.instance.Call("newUniqueId")
+	return &DurableObjectId{val: id}
+}
+
+// DurableObjectJurisdiction represents the jurisdiction where Durable Objects run and store data.
+//
+// https://developers.cloudflare.com/durable-objects/reference/data-location/#restrict-durable-objects-to-a-jurisdiction
+type DurableObjectJurisdiction string
+
+const (
+	// DurableObjectJurisdictionEU restricts Durable Objects to the European Union.
+	DurableObjectJurisdictionEU DurableObjectJurisdiction = "eu"
+	// DurableObjectJurisdictionFedRAMP restricts Durable Objects to FedRAMP-compliant data centers.
+	DurableObjectJurisdictionFedRAMP DurableObjectJurisdiction = "fedramp"
+)
+
+// DurableObjectNewUniqueIdOptions represents the options of NewUniqueIdWithOptions.
+type DurableObjectNewUniqueIdOptions struct {
+	// Jurisdiction restricts the object of the ID to the jurisdiction.
+	Jurisdiction DurableObjectJurisdiction
+}
+
+func (opts *DurableObjectNewUniqueIdOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.Jurisdiction != "" {
+		obj.Set("jurisdiction", string(opts.Jurisdiction))
+	}
+	return obj
+}
+
+// NewUniqueIdWithOptions returns a new random `DurableObjectId` with the options.
+//   - if the jurisdiction is invalid, returns error.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#newuniqueid
+func (ns *DurableObjectNamespace) NewUniqueIdWithOptions(opts *DurableObjectNewUniqueIdOptions) (*DurableObjectId, error) {
+	v, err := jsutil.TryCall(ns.instance, "newUniqueId", opts.toJS())
+	if err != nil {
+		return nil, err
+	}
+	return &DurableObjectId{val: v}, nil
+}
+
+// Jurisdiction returns the subnamespace restricted to the jurisdiction.
+//   - All IDs created by the subnamespace (including IdFromName) are restricted to the jurisdiction.
+//   - if the jurisdiction is invalid, returns error.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#jurisdiction
+func (ns *DurableObjectNamespace) Jurisdiction(jurisdiction DurableObjectJurisdiction) (*DurableObjectNamespace, error) {
+	v, err := jsutil.TryCall(ns.instance, "jurisdiction", string(jurisdiction))
+	if err != nil {
+		return nil, err
+	}
+	return &DurableObjectNamespace{instance: v}, nil
+}
+
 // Get obtains the durable object stub for `id`.
 //
 // https://developers.cloudflare.com/workers/runtime-apis/durable-objects/#obtaining-an-object-stub
 func (ns *DurableObjectNamespace) Get(id *DurableObjectId) (*DurableObjectStub, error) {
+	return ns.GetWithOptions(id, nil)
+}
+
+// DurableObjectLocationHint represents the location where a Durable Object is preferred to be created.
+//
+// https://developers.cloudflare.com/durable-objects/reference/data-location/#provide-a-location-hint
+type DurableObjectLocationHint string
+
+const (
+	// DurableObjectLocationHintWNAM is Western North America.
+	DurableObjectLocationHintWNAM DurableObjectLocationHint = "wnam"
+	// DurableObjectLocationHintENAM is Eastern North America.
+	DurableObjectLocationHintENAM DurableObjectLocationHint = "enam"
+	// DurableObjectLocationHintSAM is South America.
+	DurableObjectLocationHintSAM DurableObjectLocationHint = "sam"
+	// DurableObjectLocationHintWEUR is Western Europe.
+	DurableObjectLocationHintWEUR DurableObjectLocationHint = "weur"
+	// DurableObjectLocationHintEEUR is Eastern Europe.
+	DurableObjectLocationHintEEUR DurableObjectLocationHint = "eeur"
+	// DurableObjectLocationHintAPAC is Asia-Pacific.
+	DurableObjectLocationHintAPAC DurableObjectLocationHint = "apac"
+	// DurableObjectLocationHintOC is Oceania.
+	DurableObjectLocationHintOC DurableObjectLocationHint = "oc"
+	// DurableObjectLocationHintAFR is Africa.
+	DurableObjectLocationHintAFR DurableObjectLocationHint = "afr"
+	// DurableObjectLocationHintME is Middle East.
+	DurableObjectLocationHintME DurableObjectLocationHint = "me"
+)
+
+// DurableObjectGetOptions represents the options of GetWithOptions.
+type DurableObjectGetOptions struct {
+	// LocationHint is the location where the object is preferred to be created.
+	// This only takes effect when the object is created for the first time.
+	LocationHint DurableObjectLocationHint
+}
+
+func (opts *DurableObjectGetOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.LocationHint != "" {
+		obj.Set("locationHint", string(opts.LocationHint))
+	}
+	return obj
+}
+
+// GetWithOptions obtains the durable object stub for `id` with the options.
+//
+// https://developers.cloudflare.com/durable-objects/api/namespace/#get
+func (ns *DurableObjectNamespace) GetWithOptions(id *DurableObjectId, opts *DurableObjectGetOptions) (*DurableObjectStub, error) {
 	if id == nil || id.val.IsUndefined() {
 		return nil, fmt.Errorf("invalid UniqueGlobalId")
 	}
-	stub := ns.instance.Call("get", id.val)
+	stub := ns.instance.Call("get", id.val, opts.toJS())
 	return &DurableObjectStub{val: stub}, nil
 }
 
@@ -52,6 +180,50 @@ type DurableObjectId struct {
 	val js.Value
 }
 
+// String returns the string representation of the ID (64 hex digits).
+//   - The string can be parsed by `DurableObjectNamespace.IdFromString`.
+func (id *DurableObjectId) String() string {
+	return id.val.Call("toString").String()
+}
+
+// Equals reports whether the IDs represent the same Durable Object.
+func (id *DurableObjectId) Equals(other *DurableObjectId) bool {
+	if other == nil {
+		return false
+	}
+	return id.val.Call("equals", other.val).Bool()
+}
+
+// Name returns the name of the ID given to `DurableObjectNamespace.IdFromName`.
+//   - if the ID was not created from a name (e.g. NewUniqueId, IdFromString), returns false.
+func (id *DurableObjectId) Name() (string, bool) {
+	name := id.val.Get("name")
+	if name.Type() != js.TypeString {
+		return "", false
+	}
+	return name.String(), true
+}
+
+type durableObjectIdContextKey struct{}
+
+// WithDurableObjectId returns a copy of the context which holds the ID.
+//   - This is useful to pass the ID resolved by middleware to handlers.
+func WithDurableObjectId(ctx context.Context, id *DurableObjectId) context.Context {
+	return context.WithValue(ctx, durableObjectIdContextKey{}, id)
+}
+
+// DurableObjectIdFromContext returns the ID held by the context with WithDurableObjectId.
+func DurableObjectIdFromContext(ctx context.Context) (*DurableObjectId, bool) {
+	id, ok := ctx.Value(durableObjectIdContextKey{}).(*DurableObjectId)
+	return id, ok
+}
+
+// DurableObjectIdFromJS wraps JavaScript sides DurableObjectId.
+//   - This is used to expose IDs given by the runtime (e.g. `state.id` of Durable Objects).
+func DurableObjectIdFromJS(v js.Value) *DurableObjectId {
+	return &DurableObjectId{val: v}
+}
+
 // DurableObjectStub represents the stub to communicate with the durable object.
 type DurableObjectStub struct {
 	val js.Value
@@ -0,0 +1,100 @@
+package durableobject
+
+import (
+	"context"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Alarmer is the interface that Durable Objects handling alarms must satisfy.
+//   - https://developers.cloudflare.com/durable-objects/api/alarms/#alarm
+type Alarmer interface {
+	// Alarm is called when the alarm set by the object fires.
+	// If Alarm returns an error, the runtime retries the alarm.
+	Alarm(ctx context.Context, info *AlarmInvocationInfo) error
+}
+
+// AlarmInvocationInfo represents the information of the alarm invocation.
+type AlarmInvocationInfo struct {
+	// RetryCount is the number of retries of the alarm. This is 0 at the first invocation.
+	RetryCount int
+	// IsRetry reports whether the invocation is a retry.
+	IsRetry bool
+}
+
+// toAlarmInvocationInfo converts JavaScript side's AlarmInvocationInfo to *AlarmInvocationInfo.
+//   - the argument may be undefined on older compatibility dates.
+func toAlarmInvocationInfo(v js.Value) *AlarmInvocationInfo {
+	info := &AlarmInvocationInfo{}
+	if v.IsUndefined() || v.IsNull() {
+		return info
+	}
+	if retryCount := v.Get("retryCount"); retryCount.Type() == js.TypeNumber {
+		info.RetryCount = retryCount.Int()
+	}
+	if isRetry := v.Get("isRetry"); isRetry.Type() == js.TypeBoolean {
+		info.IsRetry = isRetry.Bool()
+	}
+	return info
+}
+
+// AlarmFunc is an adapter to use ordinary functions as Alarmer.
+type AlarmFunc func(ctx context.Context, info *AlarmInvocationInfo) error
+
+// Alarm calls f(ctx, info).
+func (f AlarmFunc) Alarm(ctx context.Context, info *AlarmInvocationInfo) error {
+	return f(ctx, info)
+}
+
+// AlarmOptions represents the options of alarm methods.
+//   - https://developers.cloudflare.com/durable-objects/api/alarms/#getalarm
+type AlarmOptions struct {
+	// AllowConcurrency disables the input gate while getting the alarm. This is only effective for GetAlarm.
+	AllowConcurrency bool
+	// AllowUnconfirmed disables the output gate while setting or deleting the alarm.
+	AllowUnconfirmed bool
+}
+
+func (opts *AlarmOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.AllowConcurrency {
+		obj.Set("allowConcurrency", true)
+	}
+	if opts.AllowUnconfirmed {
+		obj.Set("allowUnconfirmed", true)
+	}
+	return obj
+}
+
+// GetAlarm returns the scheduled time of the alarm.
+//   - if no alarm is set, returns zero time.
+func (s *Storage) GetAlarm(opts *AlarmOptions) (time.Time, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("getAlarm", opts.toJS()))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v.IsNull() || v.IsUndefined() {
+		return time.Time{}, nil
+	}
+	return time.UnixMilli(int64(v.Float())), nil
+}
+
+// SetAlarm sets the alarm to fire at scheduledTime.
+//   - The object must implement Alarmer to handle the alarm.
+//   - Each object can have only one alarm. Setting a new alarm overrides the existing one.
+//   - if scheduledTime is in the past, the alarm fires immediately.
+func (s *Storage) SetAlarm(scheduledTime time.Time, opts *AlarmOptions) error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("setAlarm", scheduledTime.UnixMilli(), opts.toJS()))
+	return err
+}
+
+// DeleteAlarm deletes the alarm if exists.
+func (s *Storage) DeleteAlarm(opts *AlarmOptions) error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("deleteAlarm", opts.toJS()))
+	return err
+}
@@ -0,0 +1,94 @@
+package durableobject
+
+import (
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Container represents the container attached to the Durable Object.
+//   - The container must be configured in `containers` of wrangler.toml.
+//   - https://developers.cloudflare.com/containers/
+type Container struct {
+	instance js.Value
+}
+
+// Container returns the container attached to the Durable Object.
+//   - if no container is attached to the Durable Object, returns false.
+func (s *State) Container() (*Container, bool) {
+	v := s.instance.Get("container")
+	if v.IsUndefined() || v.IsNull() {
+		return nil, false
+	}
+	return &Container{instance: v}, true
+}
+
+// Running reports whether the container is running.
+func (c *Container) Running() bool {
+	return c.instance.Get("running").Bool()
+}
+
+// ContainerStartOptions represents the options of Start.
+type ContainerStartOptions struct {
+	// Entrypoint overrides the entrypoint of the container image.
+	Entrypoint []string
+	// EnableInternet allows the container to access the internet.
+	EnableInternet bool
+	// Env is environment variables given to the container.
+	Env map[string]string
+}
+
+func (opts *ContainerStartOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if len(opts.Entrypoint) > 0 {
+		obj.Set("entrypoint", toJSStringArray(opts.Entrypoint))
+	}
+	if opts.EnableInternet {
+		obj.Set("enableInternet", true)
+	}
+	if len(opts.Env) > 0 {
+		env := jsutil.NewObject()
+		for k, v := range opts.Env {
+			env.Set(k, v)
+		}
+		obj.Set("env", env)
+	}
+	return obj
+}
+
+// Start starts the container.
+//   - This doesn't wait for the container to be ready. Use Monitor to watch the container exits.
+func (c *Container) Start(opts *ContainerStartOptions) error {
+	_, err := jsutil.TryCall(c.instance, "start", opts.toJS())
+	return err
+}
+
+// Monitor waits until the container exits.
+//   - if the container exits with an error, returns the error.
+func (c *Container) Monitor() error {
+	_, err := jsutil.AwaitPromise(c.instance.Call("monitor"))
+	return err
+}
+
+// Destroy stops the container.
+func (c *Container) Destroy() error {
+	_, err := jsutil.AwaitPromise(c.instance.Call("destroy"))
+	return err
+}
+
+// Signal sends the signal (e.g. 15 for SIGTERM) to the container.
+func (c *Container) Signal(signo int) error {
+	_, err := jsutil.TryCall(c.instance, "signal", signo)
+	return err
+}
+
+// GetTCPPort returns the client which sends requests to the port of the container.
+//
+//	res, err := container.GetTCPPort(8080).HTTPClient(fetch.RedirectModeManual).Do(req)
+func (c *Container) GetTCPPort(port int) *fetch.Client {
+	return fetch.NewClient(fetch.WithBinding(c.instance.Call("getTcpPort", port)))
+}
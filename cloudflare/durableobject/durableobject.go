@@ -0,0 +1,122 @@
+// Package durableobject provides the way to implement Durable Object classes in Go.
+//
+// A Durable Object class is registered with its class name by Register, and the class must be
+// exported from the worker's entry point. workers-assets-gen generates the exports with the
+// `-durable-object` flag:
+//
+//	go run github.com/syumai/workers/cmd/workers-assets-gen -durable-object Counter
+//
+// RPC methods of the class are given after the class name (see RPCHandler):
+//
+//	go run github.com/syumai/workers/cmd/workers-assets-gen -durable-object Counter:increment,get
+//
+// The class name must also be declared in the `durable_objects` bindings and `migrations` of wrangler.toml.
+//   - https://developers.cloudflare.com/durable-objects/
+package durableobject
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// DurableObject is the interface that Durable Objects implemented in Go must satisfy.
+//   - ServeHTTP handles requests sent by `DurableObjectStub.Fetch`.
+//   - The context of the request holds the environment of the object, so bindings can be
+//     accessed by functions in the cloudflare package (e.g. `cloudflare.NewKVNamespace`).
+type DurableObject interface {
+	http.Handler
+}
+
+// Constructor creates a DurableObject.
+//   - ctx holds the environment of the object. It is also given to all handlers of the object.
+//   - Constructor is called once for each instance of the Durable Object, before the first request or alarm is handled.
+type Constructor func(ctx context.Context, state *State) DurableObject
+
+var (
+	constructorsMu sync.RWMutex
+	constructors   = map[string]Constructor{}
+)
+
+// Register registers the Constructor of the Durable Object class with className.
+//   - Register must be called before `workers.Serve` is called.
+//   - if Register is called twice with the same className, it panics.
+func Register(className string, ctor Constructor) {
+	constructorsMu.Lock()
+	defer constructorsMu.Unlock()
+	if _, ok := constructors[className]; ok {
+		panic(fmt.Errorf("durableobject: class %s is already registered", className))
+	}
+	constructors[className] = ctor
+	registerNewDurableObject()
+}
+
+func getConstructor(className string) (Constructor, bool) {
+	constructorsMu.RLock()
+	defer constructorsMu.RUnlock()
+	ctor, ok := constructors[className]
+	return ctor, ok
+}
+
+// instance holds a DurableObject and its context.
+type instance struct {
+	ctx    context.Context
+	object DurableObject
+}
+
+// newInstanceObj creates an instance of the class and returns JavaScript object which has handler methods.
+func newInstanceObj(className string, stateObj js.Value, runtimeCtxObj js.Value) (js.Value, error) {
+	ctor, ok := getConstructor(className)
+	if !ok {
+		return js.Value{}, fmt.Errorf("durableobject: class %s is not registered", className)
+	}
+	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
+	inst := &instance{
+		ctx:    ctx,
+		object: ctor(ctx, &State{instance: stateObj}),
+	}
+	obj := jsutil.NewObject()
+	obj.Set("fetch", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		reqObj := args[0]
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			return jshttp.ServeJSRequest(inst.object, reqObj, runtimeCtxObj)
+		})
+	}))
+	obj.Set("alarm", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		info := js.Undefined()
+		if len(args) > 0 {
+			info = args[0]
+		}
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			alarmer, ok := inst.object.(Alarmer)
+			if !ok {
+				return js.Value{}, fmt.Errorf("durableobject: class %s doesn't implement Alarm", className)
+			}
+			if err := alarmer.Alarm(inst.ctx, toAlarmInvocationInfo(info)); err != nil {
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	}))
+	setWebSocketHandlers(obj, className, inst)
+	setRPCHandler(obj, className, inst)
+	return obj, nil
+}
+
+// registerNewDurableObject sets newDurableObject called by the JavaScript side.
+var registerNewDurableObject = jsutil.LazyRegister("newDurableObject", func(_ js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		panic(fmt.Errorf("invalid number of arguments given to newDurableObject: %d", len(args)))
+	}
+	className, stateObj, runtimeCtxObj := args[0].String(), args[1], args[2]
+	// Constructor may call asynchronous APIs, so it must be run in a goroutine.
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		return newInstanceObj(className, stateObj, runtimeCtxObj)
+	})
+})
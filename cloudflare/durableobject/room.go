@@ -0,0 +1,282 @@
+package durableobject
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// RoomCodec encodes and decodes messages sent in Room.
+//   - Implement this to use other encodings than JSON (e.g. MessagePack or Protocol Buffers as binary messages).
+type RoomCodec interface {
+	Encode(v any) (*WebSocketMessage, error)
+	Decode(msg *WebSocketMessage, v any) error
+}
+
+// JSONCodec encodes messages as JSON text messages. This is the default RoomCodec.
+type JSONCodec struct{}
+
+var _ RoomCodec = JSONCodec{}
+
+// Encode encodes v as a JSON text message.
+func (JSONCodec) Encode(v any) (*WebSocketMessage, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return &WebSocketMessage{Type: TextMessage, Data: b}, nil
+}
+
+// Decode decodes the JSON message (text or binary) into v.
+func (JSONCodec) Decode(msg *WebSocketMessage, v any) error {
+	return json.Unmarshal(msg.Data, v)
+}
+
+// RoomOptions represents the options of Room.
+type RoomOptions struct {
+	// Codec encodes and decodes messages. The default is JSONCodec.
+	Codec RoomCodec
+	// OnJoin is called when a member joins, e.g. to broadcast the presence.
+	OnJoin func(ctx context.Context, r *Room, m *RoomMember) error
+	// OnLeave is called when a member leaves. The member is already excluded from Members and Presence.
+	OnLeave func(ctx context.Context, r *Room, m *RoomMember) error
+}
+
+// RoomMember represents a connection of a member of Room.
+//   - A member can have multiple connections (e.g. tabs of browsers) with the same ID.
+type RoomMember struct {
+	// ID identifies the member, e.g. the user ID.
+	ID string
+	// Meta is the application data of the member (e.g. the display name).
+	Meta map[string]string
+	// WebSocket is the connection of the member.
+	WebSocket *WebSocket
+}
+
+// Room is the pub/sub room of WebSocket connections accepted by the Durable Object with the hibernation API.
+//   - Members are kept by the tags and the attachments of WebSockets, so the room survives hibernation.
+//     Create the room in the Constructor of the object, and it restores the members after the object wakes up.
+//   - Delegate the WebSocketClose handler of the object to Leave.
+//   - The serialized IDs and Meta of members must be 2,048 bytes or less in total.
+//
+// Usage:
+//
+//	func (c *chat) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+//		c.room.Join(w, req, req.URL.Query().Get("user"), nil)
+//	}
+//
+//	func (c *chat) WebSocketMessage(ctx context.Context, ws *durableobject.WebSocket, msg *durableobject.WebSocketMessage) error {
+//		var in chatMessage
+//		if err := c.room.Decode(msg, &in); err != nil {
+//			return err
+//		}
+//		return c.room.Broadcast(&in)
+//	}
+//
+//	func (c *chat) WebSocketClose(ctx context.Context, ws *durableobject.WebSocket, code int, reason string, wasClean bool) error {
+//		return c.room.Leave(ctx, ws, code, reason)
+//	}
+type Room struct {
+	state *State
+	opts  RoomOptions
+}
+
+// NewRoom returns the room of WebSockets accepted by state.
+func NewRoom(state *State, opts *RoomOptions) *Room {
+	r := &Room{state: state}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Codec == nil {
+		r.opts.Codec = JSONCodec{}
+	}
+	return r
+}
+
+const (
+	roomMemberTagPrefix = "room:member:"
+	// maxTagLength is the maximum length of tags of hibernatable WebSockets.
+	maxTagLength = 256
+
+	attachmentID   = "room.id"
+	attachmentMeta = "room.meta"
+)
+
+// Join accepts the WebSocket upgrade request as a connection of the member, and calls OnJoin.
+//   - w and req must be given to the Durable Object's ServeHTTP. The handler should return soon after calling this.
+//   - if the request is not a WebSocket upgrade request, returns ErrNotWebSocketUpgrade.
+func (r *Room) Join(w http.ResponseWriter, req *http.Request, id string, meta map[string]string) (*RoomMember, error) {
+	if id == "" || len(roomMemberTagPrefix)+len(id) > maxTagLength {
+		return nil, fmt.Errorf("durableobject: member ID must be 1 to %d bytes", maxTagLength-len(roomMemberTagPrefix))
+	}
+	ws, err := r.state.UpgradeWebSocket(w, req, roomMemberTagPrefix+id)
+	if err != nil {
+		return nil, err
+	}
+	attachment := map[string]any{attachmentID: id}
+	if len(meta) > 0 {
+		m := make(map[string]any, len(meta))
+		for k, v := range meta {
+			m[k] = v
+		}
+		attachment[attachmentMeta] = m
+	}
+	if err := ws.SerializeAttachment(attachment); err != nil {
+		_ = ws.Close(1011, "error serializing attachment")
+		return nil, err
+	}
+	m := &RoomMember{ID: id, Meta: meta, WebSocket: ws}
+	if r.opts.OnJoin != nil {
+		if err := r.opts.OnJoin(req.Context(), r, m); err != nil {
+			_ = ws.Close(1011, "error joining room")
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Leave closes the connection of the member, and calls OnLeave.
+//   - Call this in the WebSocketClose handler of the object. code and reason are sent back to complete the closing handshake.
+func (r *Room) Leave(ctx context.Context, ws *WebSocket, code int, reason string) error {
+	if code < 1000 || code == 1005 || code == 1006 || code == 1015 || code > 4999 {
+		// these codes must not be sent by close().
+		code = 1000
+	}
+	m, ok := r.Member(ws)
+	if err := ws.Close(code, reason); err != nil {
+		return err
+	}
+	if !ok || r.opts.OnLeave == nil {
+		return nil
+	}
+	return r.opts.OnLeave(ctx, r, m)
+}
+
+// Member returns the member of the WebSocket. if the WebSocket isn't joined by Join, returns false.
+func (r *Room) Member(ws *WebSocket) (*RoomMember, bool) {
+	attachment, ok := ws.DeserializeAttachment().(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	id, ok := attachment[attachmentID].(string)
+	if !ok {
+		return nil, false
+	}
+	m := &RoomMember{ID: id, WebSocket: ws}
+	if meta, ok := attachment[attachmentMeta].(map[string]any); ok {
+		m.Meta = make(map[string]string, len(meta))
+		for k, v := range meta {
+			m.Meta[k], _ = v.(string)
+		}
+	}
+	return m, true
+}
+
+// webSocketOpen is the readyState of open WebSockets.
+const webSocketOpen = 1
+
+// members returns the members of open connections which have the tag.
+func (r *Room) members(tag string) []*RoomMember {
+	var members []*RoomMember
+	for _, ws := range r.state.GetWebSockets(tag) {
+		if ws.instance.Get("readyState").Int() != webSocketOpen {
+			continue
+		}
+		if m, ok := r.Member(ws); ok {
+			members = append(members, m)
+		}
+	}
+	return members
+}
+
+// Members returns the open connections of all members.
+func (r *Room) Members() []*RoomMember {
+	return r.members("")
+}
+
+// Connections returns the open connections of the member.
+func (r *Room) Connections(id string) []*RoomMember {
+	if id == "" || len(roomMemberTagPrefix)+len(id) > maxTagLength {
+		return nil
+	}
+	return r.members(roomMemberTagPrefix + id)
+}
+
+// Presence returns the sorted IDs of members which have open connections.
+func (r *Room) Presence() []string {
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, m := range r.Members() {
+		if !seen[m.ID] {
+			seen[m.ID] = true
+			ids = append(ids, m.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Decode decodes the message received from a member by the codec.
+func (r *Room) Decode(msg *WebSocketMessage, v any) error {
+	return r.opts.Codec.Decode(msg, v)
+}
+
+// Send sends v to the connection of the member.
+func (r *Room) Send(m *RoomMember, v any) error {
+	msg, err := r.opts.Codec.Encode(v)
+	if err != nil {
+		return fmt.Errorf("durableobject: error encoding message: %w", err)
+	}
+	return sendMessage(m.WebSocket, msg)
+}
+
+// SendTo sends v to all connections of the member.
+func (r *Room) SendTo(id string, v any) error {
+	return r.broadcast(r.Connections(id), v, nil)
+}
+
+// Broadcast sends v to all connections except the given WebSockets (e.g. the sender).
+//   - The message is encoded once. if sending to some connections fails, the others still receive it, and the first error is returned.
+func (r *Room) Broadcast(v any, except ...*WebSocket) error {
+	return r.broadcast(r.Members(), v, except)
+}
+
+func (r *Room) broadcast(members []*RoomMember, v any, except []*WebSocket) error {
+	msg, err := r.opts.Codec.Encode(v)
+	if err != nil {
+		return fmt.Errorf("durableobject: error encoding message: %w", err)
+	}
+	var firstErr error
+	for _, m := range members {
+		if containsWebSocket(except, m.WebSocket) {
+			continue
+		}
+		if err := sendMessage(m.WebSocket, msg); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("durableobject: error sending message to %s: %w", m.ID, err)
+		}
+	}
+	return firstErr
+}
+
+func containsWebSocket(sockets []*WebSocket, ws *WebSocket) bool {
+	for _, s := range sockets {
+		if s.instance.Equal(ws.instance) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendMessage sends the message as the text or binary message.
+func sendMessage(ws *WebSocket, msg *WebSocketMessage) error {
+	switch msg.Type {
+	case TextMessage:
+		return ws.Send(string(msg.Data))
+	case BinaryMessage:
+		return ws.SendBinary(msg.Data)
+	}
+	return errors.New("durableobject: unknown message type")
+}
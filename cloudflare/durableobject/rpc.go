@@ -0,0 +1,45 @@
+package durableobject
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// RPCHandler is the interface that Durable Objects providing RPC methods must satisfy.
+//   - The method names must also be given to workers-assets-gen (e.g. `-durable-object Counter:increment,get`),
+//     so the generated class has the methods.
+//   - RPC methods of Durable Objects require compatibility date 2024-04-03 or later.
+//   - https://developers.cloudflare.com/durable-objects/best-practices/create-durable-object-stubs-and-send-requests/
+type RPCHandler interface {
+	// RPCMethods returns the RPC methods of the object by their names.
+	RPCMethods() map[string]cloudflare.RPCMethod
+}
+
+// setRPCHandler sets the method which dispatches RPC calls to the instance object.
+func setRPCHandler(obj js.Value, className string, inst *instance) {
+	obj.Set("call", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		name, argsArr := args[0].String(), args[1]
+		methodArgs := make([]any, argsArr.Length())
+		for i := range methodArgs {
+			methodArgs[i] = jsutil.ToGoValue(argsArr.Index(i))
+		}
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			h, ok := inst.object.(RPCHandler)
+			if !ok {
+				return js.Value{}, fmt.Errorf("durableobject: class %s doesn't implement RPCHandler", className)
+			}
+			method, ok := h.RPCMethods()[name]
+			if !ok {
+				return js.Value{}, fmt.Errorf("durableobject: class %s doesn't have RPC method %s", className, name)
+			}
+			result, err := method(inst.ctx, methodArgs)
+			if err != nil {
+				return js.Value{}, err
+			}
+			return jsutil.ToJSValue(result)
+		})
+	}))
+}
@@ -0,0 +1,248 @@
+package durableobject
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// SQLStorage represents the SQL API of SQLite-backed Durable Objects.
+//   - The Durable Object class must be declared in `new_sqlite_classes` of wrangler.toml's migrations.
+//   - All methods of SQLStorage are synchronous.
+//   - https://developers.cloudflare.com/durable-objects/api/sql-storage/
+type SQLStorage struct {
+	instance js.Value
+}
+
+// SQL returns the SQL API of the storage.
+func (s *Storage) SQL() *SQLStorage {
+	return &SQLStorage{instance: s.instance.Get("sql")}
+}
+
+// Exec executes the query with bound parameters and returns the cursor of the results.
+//   - args can be nil, bool, string, numbers, []byte and time.Time. time.Time is bound as RFC 3339 text.
+//   - The query can contain multiple statements separated by semicolons. Then the cursor returns the results of the last statement.
+//   - if the query is invalid, returns error.
+func (s *SQLStorage) Exec(query string, args ...any) (*SQLCursor, error) {
+	jsArgs := make([]any, len(args)+1)
+	jsArgs[0] = query
+	for i, arg := range args {
+		v, err := toSQLValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("error converting args[%d]: %w", i, err)
+		}
+		jsArgs[i+1] = v
+	}
+	cursorObj, err := jsutil.TryCall(s.instance, "exec", jsArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return &SQLCursor{
+		instance: cursorObj,
+		rawIter:  cursorObj.Call("raw"),
+	}, nil
+}
+
+// DatabaseSize returns the current size of the database in bytes.
+func (s *SQLStorage) DatabaseSize() int64 {
+	return int64(s.instance.Get("databaseSize").Float())
+}
+
+// toSQLValue converts Go value to JavaScript value which can be bound to SQL statements.
+func toSQLValue(v any) (js.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return jsutil.Null, nil
+	case bool:
+		if v {
+			return js.ValueOf(1), nil
+		}
+		return js.ValueOf(0), nil
+	case []byte:
+		ua := jsutil.NewUint8Array(len(v))
+		js.CopyBytesToJS(ua, v)
+		return ua.Get("buffer"), nil
+	case time.Time:
+		return js.ValueOf(v.Format(time.RFC3339Nano)), nil
+	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return js.ValueOf(v), nil
+	}
+	return js.Value{}, fmt.Errorf("unsupported type: %T", v)
+}
+
+// SQLCursor represents the cursor of results of SQLStorage's Exec.
+//   - https://developers.cloudflare.com/durable-objects/api/sql-storage/#returns
+type SQLCursor struct {
+	instance js.Value
+	rawIter  js.Value
+	current  []any
+	err      error
+	columns  []string
+}
+
+// ColumnNames returns the column names of the results.
+func (c *SQLCursor) ColumnNames() []string {
+	if c.columns != nil {
+		return c.columns
+	}
+	namesObj := c.instance.Get("columnNames")
+	c.columns = make([]string, namesObj.Length())
+	for i := range c.columns {
+		c.columns[i] = namesObj.Index(i).String()
+	}
+	return c.columns
+}
+
+// Next advances the cursor to the next row, and reports whether the row exists.
+//
+//	for cursor.Next() {
+//		values := cursor.Values()
+//	}
+//	if err := cursor.Err(); err != nil { ... }
+func (c *SQLCursor) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	result, err := jsutil.TryCall(c.rawIter, "next")
+	if err != nil {
+		c.err = err
+		return false
+	}
+	if result.Get("done").Bool() {
+		c.current = nil
+		return false
+	}
+	row := result.Get("value")
+	values := make([]any, row.Length())
+	for i := range values {
+		v, err := toSQLColumnValue(row.Index(i))
+		if err != nil {
+			c.err = err
+			return false
+		}
+		values[i] = v
+	}
+	c.current = values
+	return true
+}
+
+// Values returns the values of the current row in the order of ColumnNames.
+//   - values are one of `nil | int64 | float64 | string | []byte`.
+func (c *SQLCursor) Values() []any {
+	return c.current
+}
+
+// Err returns the error occurred in Next.
+func (c *SQLCursor) Err() error {
+	return c.err
+}
+
+// All returns all remaining rows as maps of column names to values.
+func (c *SQLCursor) All() ([]map[string]any, error) {
+	cols := c.ColumnNames()
+	var rows []map[string]any
+	for c.Next() {
+		row := make(map[string]any, len(cols))
+		for i, v := range c.current {
+			row[cols[i]] = v
+		}
+		rows = append(rows, row)
+	}
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ErrNotExactlyOneRow is returned by One when the result doesn't have exactly one row.
+var ErrNotExactlyOneRow = errors.New("durableobject: result doesn't have exactly one row")
+
+// One returns the only row of the results.
+//   - if the result doesn't have exactly one row, returns ErrNotExactlyOneRow.
+func (c *SQLCursor) One() (map[string]any, error) {
+	rows, err := c.All()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != 1 {
+		return nil, ErrNotExactlyOneRow
+	}
+	return rows[0], nil
+}
+
+// RowsRead returns the number of rows read so far by the query.
+func (c *SQLCursor) RowsRead() int {
+	return c.instance.Get("rowsRead").Int()
+}
+
+// RowsWritten returns the number of rows written so far by the query.
+func (c *SQLCursor) RowsWritten() int {
+	return c.instance.Get("rowsWritten").Int()
+}
+
+// toSQLColumnValue converts column value of SQL results to Go value.
+// column value is `null | Number | String | ArrayBuffer`.
+func toSQLColumnValue(v js.Value) (any, error) {
+	switch v.Type() {
+	case js.TypeNull:
+		return nil, nil
+	case js.TypeNumber:
+		f := v.Float()
+		if !math.IsNaN(f) && !math.IsInf(f, 0) && f == math.Trunc(f) {
+			return int64(f), nil
+		}
+		return f, nil
+	case js.TypeString:
+		return v.String(), nil
+	case js.TypeObject:
+		ua := jsutil.Uint8ArrayClass().New(v)
+		b := make([]byte, ua.Get("byteLength").Int())
+		js.CopyBytesToGo(b, ua)
+		return b, nil
+	}
+	return nil, errors.New("durableobject: unexpected column value type")
+}
+
+// GetCurrentBookmark returns a bookmark which represents the current point in time of the storage.
+//   - This is only available for SQLite-backed Durable Objects.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#getcurrentbookmark
+func (s *Storage) GetCurrentBookmark() (string, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("getCurrentBookmark"))
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+// GetBookmarkForTime returns a bookmark which represents the given point in time of the storage.
+//   - The time must be within the last 30 days.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#getbookmarkfortime
+func (s *Storage) GetBookmarkForTime(t time.Time) (string, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("getBookmarkForTime", t.UnixMilli()))
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+// OnNextSessionRestoreBookmark configures the storage to be restored to the bookmark when the object restarts.
+//   - This returns a bookmark which can be used to undo the restore.
+//   - Call `State.Abort` after this to restart the object.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#onnextsessionrestorebookmark
+func (s *Storage) OnNextSessionRestoreBookmark(bookmark string) (string, error) {
+	v, err := jsutil.AwaitPromise(s.instance.Call("onNextSessionRestoreBookmark", bookmark))
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
+
+// Abort resets the Durable Object. The object will be recreated on the next request.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#abort
+func (s *State) Abort(reason string) {
+	_, _ = jsutil.TryCall(s.instance, "abort", reason)
+}
@@ -0,0 +1,210 @@
+package durableobject
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// SQLConnector is a database/sql connector of SQLStorage.
+//
+//	db := sql.OpenDB(durableobject.NewSQLConnector(state.Storage()))
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM users WHERE id = ?", id)
+//
+// Queries are executed synchronously, so context cancellation is not supported.
+// Transactions by BeginTx are not supported. Use Storage.TransactionSync instead.
+type SQLConnector struct {
+	sql *SQLStorage
+}
+
+var _ driver.Connector = (*SQLConnector)(nil)
+
+// NewSQLConnector returns a database/sql connector of the storage's SQL API.
+func NewSQLConnector(s *Storage) *SQLConnector {
+	return &SQLConnector{sql: s.SQL()}
+}
+
+// Connect returns a connection of SQLStorage. This never returns errors.
+func (c *SQLConnector) Connect(context.Context) (driver.Conn, error) {
+	return &sqlConn{sql: c.sql}, nil
+}
+
+func (c *SQLConnector) Driver() driver.Driver {
+	return sqlDriver{}
+}
+
+type sqlDriver struct{}
+
+var _ driver.Driver = sqlDriver{}
+
+func (sqlDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("durableobject: Open is not supported. use durableobject.NewSQLConnector and sql.OpenDB instead")
+}
+
+type sqlConn struct {
+	sql *SQLStorage
+}
+
+var (
+	_ driver.Conn               = (*sqlConn)(nil)
+	_ driver.ConnBeginTx        = (*sqlConn)(nil)
+	_ driver.ConnPrepareContext = (*sqlConn)(nil)
+	_ driver.ExecerContext      = (*sqlConn)(nil)
+	_ driver.QueryerContext     = (*sqlConn)(nil)
+)
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return &sqlStmt{conn: c, query: query}, nil
+}
+
+func (c *sqlConn) PrepareContext(_ context.Context, query string) (driver.Stmt, error) {
+	return c.Prepare(query)
+}
+
+func (c *sqlConn) Close() error {
+	// do nothing
+	return nil
+}
+
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("durableobject: Begin is deprecated and not implemented")
+}
+
+func (c *sqlConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return nil, errors.New("durableobject: transaction is not supported. use Storage.TransactionSync instead")
+}
+
+// ExecContext executes the query and returns its result.
+// Given []driver.NamedValue's `Name` field is not supported because SQLStorage only supports positional parameters.
+func (c *sqlConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	argValues, err := namedValuesToArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := c.sql.Exec(query, argValues...)
+	if err != nil {
+		return nil, err
+	}
+	// consume the cursor to execute all statements in the query.
+	for cursor.Next() {
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	res := &sqlResult{rowsAffected: int64(cursor.RowsWritten())}
+	idCursor, err := c.sql.Exec("SELECT last_insert_rowid()")
+	if err != nil {
+		return nil, err
+	}
+	if idCursor.Next() {
+		if id, ok := idCursor.Values()[0].(int64); ok {
+			res.lastInsertID = id
+		}
+	}
+	return res, nil
+}
+
+// QueryContext executes the query and returns its rows.
+func (c *sqlConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	argValues, err := namedValuesToArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := c.sql.Exec(query, argValues...)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlRows{cursor: cursor}, nil
+}
+
+func namedValuesToArgs(args []driver.NamedValue) ([]any, error) {
+	argValues := make([]any, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("durableobject: named parameters are not supported")
+		}
+		argValues[i] = arg.Value
+	}
+	return argValues, nil
+}
+
+type sqlStmt struct {
+	conn  *sqlConn
+	query string
+}
+
+var (
+	_ driver.Stmt             = (*sqlStmt)(nil)
+	_ driver.StmtExecContext  = (*sqlStmt)(nil)
+	_ driver.StmtQueryContext = (*sqlStmt)(nil)
+)
+
+func (s *sqlStmt) Close() error {
+	// do nothing
+	return nil
+}
+
+// NumInput is not supported and always returns -1.
+func (s *sqlStmt) NumInput() int {
+	return -1
+}
+
+func (s *sqlStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("durableobject: Exec is deprecated and not implemented")
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *sqlStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("durableobject: Query is deprecated and not implemented")
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+type sqlRows struct {
+	cursor *SQLCursor
+}
+
+var _ driver.Rows = (*sqlRows)(nil)
+
+func (r *sqlRows) Columns() []string {
+	return r.cursor.ColumnNames()
+}
+
+func (r *sqlRows) Close() error {
+	// do nothing
+	return nil
+}
+
+func (r *sqlRows) Next(dest []driver.Value) error {
+	if !r.cursor.Next() {
+		if err := r.cursor.Err(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+	for i, v := range r.cursor.Values() {
+		dest[i] = v
+	}
+	return nil
+}
+
+type sqlResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+var _ driver.Result = (*sqlResult)(nil)
+
+func (r *sqlResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+func (r *sqlResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
@@ -0,0 +1,18 @@
+package durableobject
+
+import (
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+// State represents the state of a Durable Object instance.
+//   - https://developers.cloudflare.com/durable-objects/api/state/
+type State struct {
+	instance js.Value
+}
+
+// ID returns the ID of the Durable Object instance.
+func (s *State) ID() *cloudflare.DurableObjectId {
+	return cloudflare.DurableObjectIdFromJS(s.instance.Get("id"))
+}
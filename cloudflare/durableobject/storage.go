@@ -0,0 +1,266 @@
+package durableobject
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Storage represents the transactional storage of a Durable Object.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/
+//
+// Values are converted between Go and JavaScript by the following rules.
+//   - Put accepts nil, bool, string, numbers, []byte, time.Time, slices, maps with string keys, structs and pointers to them.
+//     struct field names can be changed by `json` tag.
+//   - Get returns nil, bool, string, float64, []byte, time.Time, []any or map[string]any.
+type Storage struct {
+	instance js.Value
+}
+
+// Storage returns the storage of the Durable Object.
+func (s *State) Storage() *Storage {
+	return &Storage{instance: s.instance.Get("storage")}
+}
+
+// StorageGetOptions represents the options of Get, GetMultiple and List.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#supported-options
+type StorageGetOptions struct {
+	// AllowConcurrency disables the input gate while the operation is in progress.
+	AllowConcurrency bool
+	// NoCache doesn't cache the value in memory.
+	NoCache bool
+}
+
+func (opts *StorageGetOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.AllowConcurrency {
+		obj.Set("allowConcurrency", true)
+	}
+	if opts.NoCache {
+		obj.Set("noCache", true)
+	}
+	return obj
+}
+
+// StoragePutOptions represents the options of Put, PutMultiple, Delete, DeleteMultiple and DeleteAll.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#supported-options-1
+type StoragePutOptions struct {
+	// AllowUnconfirmed disables the output gate, so the response can be sent before the write is confirmed.
+	AllowUnconfirmed bool
+	// NoCache evicts the value from memory cache after the write completes.
+	NoCache bool
+}
+
+func (opts *StoragePutOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.AllowUnconfirmed {
+		obj.Set("allowUnconfirmed", true)
+	}
+	if opts.NoCache {
+		obj.Set("noCache", true)
+	}
+	return obj
+}
+
+// StorageListOptions represents the options of List.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#list
+type StorageListOptions struct {
+	// Start is the key to start listing from (inclusive).
+	Start string
+	// StartAfter is the key to start listing after (exclusive). This cannot be used with Start.
+	StartAfter string
+	// End is the key to stop listing at (exclusive).
+	End string
+	// Prefix restricts results to keys which start with the prefix.
+	Prefix string
+	// Reverse lists keys in descending order.
+	Reverse bool
+	// Limit is the maximum number of entries. The value `0` means no limit.
+	Limit            int
+	AllowConcurrency bool
+	NoCache          bool
+}
+
+func (opts *StorageListOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.Start != "" {
+		obj.Set("start", opts.Start)
+	}
+	if opts.StartAfter != "" {
+		obj.Set("startAfter", opts.StartAfter)
+	}
+	if opts.End != "" {
+		obj.Set("end", opts.End)
+	}
+	if opts.Prefix != "" {
+		obj.Set("prefix", opts.Prefix)
+	}
+	if opts.Reverse {
+		obj.Set("reverse", true)
+	}
+	if opts.Limit != 0 {
+		obj.Set("limit", opts.Limit)
+	}
+	if opts.AllowConcurrency {
+		obj.Set("allowConcurrency", true)
+	}
+	if opts.NoCache {
+		obj.Set("noCache", true)
+	}
+	return obj
+}
+
+// StorageEntry represents a key-value pair in the storage.
+type StorageEntry struct {
+	Key   string
+	Value any
+}
+
+// Get returns the value stored with the key.
+//   - if the key doesn't exist, returns nil.
+func (s *Storage) Get(key string, opts *StorageGetOptions) (any, error) {
+	return storageGet(s.instance, key, opts)
+}
+
+// GetMultiple returns the values stored with the keys.
+//   - keys which don't exist are not included in the result.
+//   - the number of keys must be 128 or less.
+func (s *Storage) GetMultiple(keys []string, opts *StorageGetOptions) (map[string]any, error) {
+	return storageGetMultiple(s.instance, keys, opts)
+}
+
+// Put stores the value with the key.
+func (s *Storage) Put(key string, value any, opts *StoragePutOptions) error {
+	return storagePut(s.instance, key, value, opts)
+}
+
+// PutMultiple stores all key-value pairs of the entries.
+//   - the number of entries must be 128 or less.
+func (s *Storage) PutMultiple(entries map[string]any, opts *StoragePutOptions) error {
+	return storagePutMultiple(s.instance, entries, opts)
+}
+
+// Delete deletes the key and reports whether the key existed.
+func (s *Storage) Delete(key string, opts *StoragePutOptions) (bool, error) {
+	return storageDelete(s.instance, key, opts)
+}
+
+// DeleteMultiple deletes the keys and returns the number of deleted keys.
+//   - the number of keys must be 128 or less.
+func (s *Storage) DeleteMultiple(keys []string, opts *StoragePutOptions) (int, error) {
+	return storageDeleteMultiple(s.instance, keys, opts)
+}
+
+// DeleteAll deletes all keys stored in the storage.
+func (s *Storage) DeleteAll(opts *StoragePutOptions) error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("deleteAll", opts.toJS()))
+	return err
+}
+
+// List returns entries in the storage in the order of keys (UTF-8 byte order).
+func (s *Storage) List(opts *StorageListOptions) ([]*StorageEntry, error) {
+	return storageList(s.instance, opts)
+}
+
+// storageGet calls `get` of Storage or Transaction with a key.
+func storageGet(obj js.Value, key string, opts *StorageGetOptions) (any, error) {
+	v, err := jsutil.AwaitPromise(obj.Call("get", key, opts.toJS()))
+	if err != nil {
+		return nil, err
+	}
+	return jsutil.ToGoValue(v), nil
+}
+
+// storageGetMultiple calls `get` of Storage or Transaction with keys.
+func storageGetMultiple(obj js.Value, keys []string, opts *StorageGetOptions) (map[string]any, error) {
+	v, err := jsutil.AwaitPromise(obj.Call("get", toJSStringArray(keys), opts.toJS()))
+	if err != nil {
+		return nil, err
+	}
+	entries := mapToEntries(v)
+	result := make(map[string]any, len(entries))
+	for _, e := range entries {
+		result[e.Key] = e.Value
+	}
+	return result, nil
+}
+
+// storagePut calls `put` of Storage or Transaction with a key.
+func storagePut(obj js.Value, key string, value any, opts *StoragePutOptions) error {
+	jsValue, err := jsutil.ToJSValue(value)
+	if err != nil {
+		return fmt.Errorf("error converting value: %w", err)
+	}
+	_, err = jsutil.AwaitPromise(obj.Call("put", key, jsValue, opts.toJS()))
+	return err
+}
+
+// storagePutMultiple calls `put` of Storage or Transaction with entries.
+func storagePutMultiple(obj js.Value, entries map[string]any, opts *StoragePutOptions) error {
+	entriesObj, err := jsutil.ToJSValue(entries)
+	if err != nil {
+		return fmt.Errorf("error converting entries: %w", err)
+	}
+	_, err = jsutil.AwaitPromise(obj.Call("put", entriesObj, opts.toJS()))
+	return err
+}
+
+// storageDelete calls `delete` of Storage or Transaction with a key.
+func storageDelete(obj js.Value, key string, opts *StoragePutOptions) (bool, error) {
+	v, err := jsutil.AwaitPromise(obj.Call("delete", key, opts.toJS()))
+	if err != nil {
+		return false, err
+	}
+	return v.Bool(), nil
+}
+
+// storageDeleteMultiple calls `delete` of Storage or Transaction with keys.
+func storageDeleteMultiple(obj js.Value, keys []string, opts *StoragePutOptions) (int, error) {
+	v, err := jsutil.AwaitPromise(obj.Call("delete", toJSStringArray(keys), opts.toJS()))
+	if err != nil {
+		return 0, err
+	}
+	return v.Int(), nil
+}
+
+// storageList calls `list` of Storage or Transaction.
+func storageList(obj js.Value, opts *StorageListOptions) ([]*StorageEntry, error) {
+	v, err := jsutil.AwaitPromise(obj.Call("list", opts.toJS()))
+	if err != nil {
+		return nil, err
+	}
+	return mapToEntries(v), nil
+}
+
+// toJSStringArray converts []string to JavaScript side's Array.
+func toJSStringArray(strs []string) js.Value {
+	arr := jsutil.ArrayClass().New(len(strs))
+	for i, s := range strs {
+		arr.SetIndex(i, s)
+	}
+	return arr
+}
+
+// mapToEntries converts JavaScript side's Map to entries keeping its order.
+func mapToEntries(m js.Value) []*StorageEntry {
+	entriesArr := jsutil.ArrayFrom(m.Call("entries"))
+	entries := make([]*StorageEntry, entriesArr.Length())
+	for i := range entries {
+		entry := entriesArr.Index(i)
+		entries[i] = &StorageEntry{
+			Key:   entry.Index(0).String(),
+			Value: jsutil.ToGoValue(entry.Index(1)),
+		}
+	}
+	return entries
+}
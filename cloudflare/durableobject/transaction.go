@@ -0,0 +1,142 @@
+package durableobject
+
+import (
+	"errors"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Transaction represents an explicit transaction of the storage.
+//   - All operations in the transaction are committed atomically when the transaction function returns nil.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#transaction
+type Transaction struct {
+	instance js.Value
+}
+
+// Transaction runs fn in an explicit transaction.
+//   - if fn returns an error or calls Rollback, all writes in the transaction are discarded and the error is returned.
+//   - Operations of the storage are automatically coalesced into atomic writes while the output gate is closed,
+//     so explicit transactions are only needed when reads and writes must be atomic across awaits of other I/O.
+func (s *Storage) Transaction(fn func(txn *Transaction) error) error {
+	var fnErr error
+	cb := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		txn := &Transaction{instance: args[0]}
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			if err := fn(txn); err != nil {
+				fnErr = err
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	})
+	defer cb.Release()
+	_, err := jsutil.AwaitPromise(s.instance.Call("transaction", cb))
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// Get returns the value stored with the key.
+//   - if the key doesn't exist, returns nil.
+func (t *Transaction) Get(key string, opts *StorageGetOptions) (any, error) {
+	return storageGet(t.instance, key, opts)
+}
+
+// GetMultiple returns the values stored with the keys.
+func (t *Transaction) GetMultiple(keys []string, opts *StorageGetOptions) (map[string]any, error) {
+	return storageGetMultiple(t.instance, keys, opts)
+}
+
+// Put stores the value with the key.
+func (t *Transaction) Put(key string, value any, opts *StoragePutOptions) error {
+	return storagePut(t.instance, key, value, opts)
+}
+
+// PutMultiple stores all key-value pairs of the entries.
+func (t *Transaction) PutMultiple(entries map[string]any, opts *StoragePutOptions) error {
+	return storagePutMultiple(t.instance, entries, opts)
+}
+
+// Delete deletes the key and reports whether the key existed.
+func (t *Transaction) Delete(key string, opts *StoragePutOptions) (bool, error) {
+	return storageDelete(t.instance, key, opts)
+}
+
+// DeleteMultiple deletes the keys and returns the number of deleted keys.
+func (t *Transaction) DeleteMultiple(keys []string, opts *StoragePutOptions) (int, error) {
+	return storageDeleteMultiple(t.instance, keys, opts)
+}
+
+// List returns entries in the storage in the order of keys.
+func (t *Transaction) List(opts *StorageListOptions) ([]*StorageEntry, error) {
+	return storageList(t.instance, opts)
+}
+
+// Rollback discards all writes in the transaction.
+//   - The transaction function should return soon after calling Rollback.
+func (t *Transaction) Rollback() {
+	t.instance.Call("rollback")
+}
+
+// ErrSyncCallbackNotSupported is returned when the entry point of the worker doesn't support synchronous callbacks
+// which can throw errors. Regenerate the entry point by workers-assets-gen.
+var ErrSyncCallbackNotSupported = errors.New("durableobject: synchronous callback is not supported by the worker entry point")
+
+// TransactionSync runs fn synchronously in a transaction.
+//   - This is only available for SQLite-backed Durable Objects.
+//   - fn is called on the JavaScript event loop, so it must not call asynchronous APIs (it causes deadlock).
+//     Use synchronous APIs like SQL storage inside fn.
+//   - if fn returns an error, the transaction is rolled back and the error is returned.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#transactionsync
+func (s *Storage) TransactionSync(fn func() error) error {
+	throwOnError := jsutil.Global.Get("throwOnError")
+	if throwOnError.Type() != js.TypeFunction {
+		return ErrSyncCallbackNotSupported
+	}
+	var fnErr error
+	cb := js.FuncOf(func(js.Value, []js.Value) any {
+		if err := fn(); err != nil {
+			fnErr = err
+			return jsutil.ErrorClass().New(err.Error())
+		}
+		return js.Undefined()
+	})
+	defer cb.Release()
+	_, err := jsutil.TryCall(s.instance, "transactionSync", throwOnError.Invoke(cb))
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
+
+// Sync waits for all pending writes to be confirmed.
+//   - https://developers.cloudflare.com/durable-objects/api/storage-api/#sync
+func (s *Storage) Sync() error {
+	_, err := jsutil.AwaitPromise(s.instance.Call("sync"))
+	return err
+}
+
+// BlockConcurrencyWhile runs fn while blocking delivery of other events (requests, alarms, etc.) to the object.
+//   - This is typically used in Constructor to initialize the object from the storage before handling requests.
+//   - if fn returns an error, the object is reset and the error is returned.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#blockconcurrencywhile
+func (s *State) BlockConcurrencyWhile(fn func() error) error {
+	var fnErr error
+	cb := js.FuncOf(func(js.Value, []js.Value) any {
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			if err := fn(); err != nil {
+				fnErr = err
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	})
+	defer cb.Release()
+	_, err := jsutil.AwaitPromise(s.instance.Call("blockConcurrencyWhile", cb))
+	if fnErr != nil {
+		return fnErr
+	}
+	return err
+}
@@ -0,0 +1,219 @@
+package durableobject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	webSocketPairClass                = jsutil.LazyGlobal("WebSocketPair")
+	webSocketRequestResponsePairClass = jsutil.LazyGlobal("WebSocketRequestResponsePair")
+)
+
+// WebSocket represents the server side of a WebSocket connection accepted by the Durable Object.
+//   - https://developers.cloudflare.com/durable-objects/best-practices/websockets/
+type WebSocket struct {
+	instance js.Value
+}
+
+// Send sends a text message.
+func (ws *WebSocket) Send(text string) error {
+	_, err := jsutil.TryCall(ws.instance, "send", text)
+	return err
+}
+
+// SendBinary sends a binary message.
+func (ws *WebSocket) SendBinary(data []byte) error {
+	ua := jsutil.NewUint8Array(len(data))
+	js.CopyBytesToJS(ua, data)
+	_, err := jsutil.TryCall(ws.instance, "send", ua)
+	return err
+}
+
+// Close closes the connection with the code and the reason.
+func (ws *WebSocket) Close(code int, reason string) error {
+	_, err := jsutil.TryCall(ws.instance, "close", code, reason)
+	return err
+}
+
+// SerializeAttachment keeps the value with the WebSocket, so it survives hibernation of the object.
+//   - value is converted by the same rules as Storage's Put.
+//   - The serialized value must be 2,048 bytes or less.
+func (ws *WebSocket) SerializeAttachment(value any) error {
+	v, err := jsutil.ToJSValue(value)
+	if err != nil {
+		return fmt.Errorf("error converting attachment: %w", err)
+	}
+	_, err = jsutil.TryCall(ws.instance, "serializeAttachment", v)
+	return err
+}
+
+// DeserializeAttachment returns the value kept by SerializeAttachment.
+//   - if no value is kept, returns nil.
+func (ws *WebSocket) DeserializeAttachment() any {
+	return jsutil.ToGoValue(ws.instance.Call("deserializeAttachment"))
+}
+
+// ErrNotWebSocketUpgrade is returned by UpgradeWebSocket when the request is not a WebSocket upgrade request.
+var ErrNotWebSocketUpgrade = errors.New("durableobject: request is not a WebSocket upgrade request")
+
+// UpgradeWebSocket accepts the WebSocket upgrade request with the hibernation API and writes `101 Switching Protocols` response.
+//   - The handler should return soon after calling this. Messages are delivered to WebSocketMessageHandler of the object.
+//   - tags can be used to look up the WebSocket by GetWebSockets.
+//   - w must be the http.ResponseWriter given to the Durable Object's ServeHTTP.
+func (s *State) UpgradeWebSocket(w http.ResponseWriter, r *http.Request, tags ...string) (*WebSocket, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil, ErrNotWebSocketUpgrade
+	}
+	rw, ok := w.(*jshttp.ResponseWriter)
+	if !ok {
+		return nil, errors.New("durableobject: UpgradeWebSocket must be called with the ResponseWriter of the Durable Object")
+	}
+	pair := webSocketPairClass().New()
+	client, server := pair.Get("0"), pair.Get("1")
+	ws := &WebSocket{instance: server}
+	if err := s.AcceptWebSocket(ws, tags...); err != nil {
+		return nil, err
+	}
+	rw.WebSocket = client
+	rw.Ready()
+	return ws, nil
+}
+
+// AcceptWebSocket accepts the WebSocket with the hibernation API.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#acceptwebsocket
+func (s *State) AcceptWebSocket(ws *WebSocket, tags ...string) error {
+	_, err := jsutil.TryCall(s.instance, "acceptWebSocket", ws.instance, toJSStringArray(tags))
+	return err
+}
+
+// GetWebSockets returns WebSockets accepted by the object.
+//   - if tag is not empty, returns only WebSockets which have the tag.
+func (s *State) GetWebSockets(tag string) []*WebSocket {
+	var arr js.Value
+	if tag == "" {
+		arr = s.instance.Call("getWebSockets")
+	} else {
+		arr = s.instance.Call("getWebSockets", tag)
+	}
+	sockets := make([]*WebSocket, arr.Length())
+	for i := range sockets {
+		sockets[i] = &WebSocket{instance: arr.Index(i)}
+	}
+	return sockets
+}
+
+// GetTags returns the tags of the WebSocket given to AcceptWebSocket.
+func (s *State) GetTags(ws *WebSocket) []string {
+	arr := s.instance.Call("getTags", ws.instance)
+	tags := make([]string, arr.Length())
+	for i := range tags {
+		tags[i] = arr.Index(i).String()
+	}
+	return tags
+}
+
+// SetWebSocketAutoResponse sets the pair of request and response messages which are handled without waking up the object.
+//   - This is typically used for ping/pong messages.
+//   - https://developers.cloudflare.com/durable-objects/api/state/#setwebsocketautoresponse
+func (s *State) SetWebSocketAutoResponse(request, response string) {
+	pairObj := webSocketRequestResponsePairClass().New(request, response)
+	s.instance.Call("setWebSocketAutoResponse", pairObj)
+}
+
+// WebSocketMessageType represents the type of WebSocket messages.
+type WebSocketMessageType int
+
+const (
+	TextMessage WebSocketMessageType = iota
+	BinaryMessage
+)
+
+// WebSocketMessage represents a message received from a WebSocket.
+type WebSocketMessage struct {
+	Type WebSocketMessageType
+	// Data is the text or binary content of the message.
+	Data []byte
+}
+
+// toWebSocketMessage converts JavaScript side's message (string or ArrayBuffer) to *WebSocketMessage.
+func toWebSocketMessage(v js.Value) *WebSocketMessage {
+	if v.Type() == js.TypeString {
+		return &WebSocketMessage{Type: TextMessage, Data: []byte(v.String())}
+	}
+	ua := jsutil.Uint8ArrayClass().New(v)
+	data := make([]byte, ua.Get("byteLength").Int())
+	js.CopyBytesToGo(data, ua)
+	return &WebSocketMessage{Type: BinaryMessage, Data: data}
+}
+
+// WebSocketMessageHandler is the interface that Durable Objects receiving messages of hibernatable WebSockets must satisfy.
+//   - https://developers.cloudflare.com/durable-objects/api/base/#websocketmessage
+type WebSocketMessageHandler interface {
+	WebSocketMessage(ctx context.Context, ws *WebSocket, msg *WebSocketMessage) error
+}
+
+// WebSocketCloseHandler is the interface that Durable Objects handling closes of hibernatable WebSockets must satisfy.
+//   - The handler should call ws.Close to complete the closing handshake.
+//   - https://developers.cloudflare.com/durable-objects/api/base/#websocketclose
+type WebSocketCloseHandler interface {
+	WebSocketClose(ctx context.Context, ws *WebSocket, code int, reason string, wasClean bool) error
+}
+
+// WebSocketErrorHandler is the interface that Durable Objects handling errors of hibernatable WebSockets must satisfy.
+//   - https://developers.cloudflare.com/durable-objects/api/base/#websocketerror
+type WebSocketErrorHandler interface {
+	WebSocketError(ctx context.Context, ws *WebSocket, err error) error
+}
+
+// setWebSocketHandlers sets handler methods of hibernatable WebSockets to the instance object.
+func setWebSocketHandlers(obj js.Value, className string, inst *instance) {
+	obj.Set("webSocketMessage", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		ws, msg := &WebSocket{instance: args[0]}, toWebSocketMessage(args[1])
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			h, ok := inst.object.(WebSocketMessageHandler)
+			if !ok {
+				return js.Value{}, fmt.Errorf("durableobject: class %s doesn't implement WebSocketMessage", className)
+			}
+			if err := h.WebSocketMessage(inst.ctx, ws, msg); err != nil {
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	}))
+	obj.Set("webSocketClose", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		ws := &WebSocket{instance: args[0]}
+		code, reason, wasClean := args[1].Int(), args[2].String(), args[3].Bool()
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			h, ok := inst.object.(WebSocketCloseHandler)
+			if !ok {
+				return js.Undefined(), nil
+			}
+			if err := h.WebSocketClose(inst.ctx, ws, code, reason, wasClean); err != nil {
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	}))
+	obj.Set("webSocketError", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		ws := &WebSocket{instance: args[0]}
+		wsErr := errors.New(jsutil.StringFunc().Invoke(args[1]).String())
+		return jsutil.RunAsPromise(func() (js.Value, error) {
+			h, ok := inst.object.(WebSocketErrorHandler)
+			if !ok {
+				return js.Undefined(), nil
+			}
+			if err := h.WebSocketError(inst.ctx, ws, wsErr); err != nil {
+				return js.Value{}, err
+			}
+			return js.Undefined(), nil
+		})
+	}))
+}
@@ -0,0 +1,142 @@
+//go:build js && wasm
+
+package email
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// toJS converts Message to JavaScript side's EmailMessage by the given class.
+func (m *Message) toJS(emailMessageClass js.Value) (js.Value, error) {
+	raw := jsutil.ConvertReaderToReadableStream(io.NopCloser(m.Raw))
+	obj, err := jsutil.TryNew(emailMessageClass, m.From, m.To, raw)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return obj, nil
+}
+
+// ForwardableMessage represents an incoming email message.
+//   - https://developers.cloudflare.com/email-routing/email-workers/runtime-api/#forwardableemailmessage-definition
+type ForwardableMessage struct {
+	instance          js.Value
+	emailMessageClass js.Value
+	// From is the envelope From address of the message.
+	From string
+	// To is the envelope To address of the message.
+	To string
+	// Headers are the headers of the message.
+	Headers http.Header
+	// Raw is the raw content of the message. Raw can be read only once.
+	Raw io.Reader
+	// RawSize is the size of the raw content in bytes.
+	RawSize int
+}
+
+func newForwardableMessage(obj js.Value, runtimeCtxObj js.Value) *ForwardableMessage {
+	return &ForwardableMessage{
+		instance:          obj,
+		emailMessageClass: runtimeCtxObj.Get("EmailMessage"),
+		From:              obj.Get("from").String(),
+		To:                obj.Get("to").String(),
+		Headers:           jshttp.ToHeader(obj.Get("headers")),
+		Raw:               jsutil.ConvertReadableStreamToReader(obj.Get("raw")),
+		RawSize:           obj.Get("rawSize").Int(),
+	}
+}
+
+// SetReject rejects the message with the reason. The reason is sent to the SMTP server as a permanent error.
+func (m *ForwardableMessage) SetReject(reason string) {
+	m.instance.Call("setReject", reason)
+}
+
+// NewReader returns MessageReader which parses Raw of the message as a stream. Raw can't be read by others after this.
+func (m *ForwardableMessage) NewReader(opts *ReadOptions) (*MessageReader, error) {
+	return NewMessageReader(m.Raw, opts)
+}
+
+// Forward forwards the message to the address.
+//   - The address must be a verified destination address of Email Routing.
+//   - headers are added to the forwarded message. Only X-* headers can be added.
+func (m *ForwardableMessage) Forward(rcptTo string, headers http.Header) error {
+	headersObj := js.Undefined()
+	if headers != nil {
+		headersObj = jshttp.ToJSHeader(headers)
+	}
+	promise, err := jsutil.TryCall(m.instance, "forward", rcptTo, headersObj)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(promise)
+	return err
+}
+
+// Reply replies to the message with msg.
+//   - msg must have the In-Reply-To header which is the Message-ID of the incoming message.
+//   - https://developers.cloudflare.com/email-routing/email-workers/reply-email-workers/
+func (m *ForwardableMessage) Reply(msg *Message) error {
+	msgObj, err := msg.toJS(m.emailMessageClass)
+	if err != nil {
+		return err
+	}
+	promise, err := jsutil.TryCall(m.instance, "reply", msgObj)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(promise)
+	return err
+}
+
+// Handler handles the incoming email message.
+//   - if Handler returns an error, the message is rejected by Email Routing.
+//   - ctx holds the environment of the worker, so bindings can be accessed by functions in the cloudflare package.
+type Handler func(ctx context.Context, msg *ForwardableMessage) error
+
+var handler Handler
+
+// Handle sets the Handler and starts the worker.
+//   - the worker must be bound to an address by Email Routing.
+//   - Handle blocks forever, so it must be called at the end of main function.
+//     Use HandleNonBlock to handle both requests and emails by the same worker.
+func Handle(h Handler) {
+	HandleNonBlock(h)
+	jsutil.Global.Call("ready")
+	select {}
+}
+
+// HandleNonBlock sets the Handler without starting the worker.
+//   - HandleNonBlock must be called before `workers.Serve` (or other functions which start the worker).
+func HandleNonBlock(h Handler) {
+	handler = h
+	registerHandleEmail()
+}
+
+func handleEmail(msgObj js.Value, runtimeCtxObj js.Value) error {
+	if handler == nil {
+		return fmt.Errorf("email: Handle must be called before handleEmail")
+	}
+	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
+	return handler(ctx, newForwardableMessage(msgObj, runtimeCtxObj))
+}
+
+// registerHandleEmail sets handleEmail called by the JavaScript side.
+var registerHandleEmail = jsutil.LazyRegister("handleEmail", func(_ js.Value, args []js.Value) any {
+	if len(args) != 2 {
+		panic(fmt.Errorf("invalid number of arguments given to handleEmail: %d", len(args)))
+	}
+	msgObj, runtimeCtxObj := args[0], args[1]
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		if err := handleEmail(msgObj, runtimeCtxObj); err != nil {
+			return js.Value{}, err
+		}
+		return js.Undefined(), nil
+	})
+})
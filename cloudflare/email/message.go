@@ -0,0 +1,17 @@
+// Package email provides the way to handle incoming emails by Email Workers.
+//   - https://developers.cloudflare.com/email-routing/email-workers/
+package email
+
+import "io"
+
+// Message represents an email message created in Go, e.g. a reply to the incoming message.
+//   - Raw must be a RFC 5322 formatted message.
+//   - https://developers.cloudflare.com/email-routing/email-workers/runtime-api/#emailmessage-definition
+type Message struct {
+	// From is the envelope From address of the message.
+	From string
+	// To is the envelope To address of the message.
+	To string
+	// Raw is the raw content of the message.
+	Raw io.Reader
+}
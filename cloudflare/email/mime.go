@@ -0,0 +1,339 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoRecipients is returned when the message has no recipients.
+	ErrNoRecipients = errors.New("email: message has no recipients")
+	// ErrNoSender is returned when the message has no From address.
+	ErrNoSender = errors.New("email: message has no sender")
+	// ErrInvalidHeader is returned when a header contains line breaks, which can inject other headers.
+	ErrInvalidHeader = errors.New("email: header contains line breaks")
+)
+
+// maxLineLength is the recommended maximum length of lines of messages, excluding CRLF.
+//   - https://www.rfc-editor.org/rfc/rfc5322#section-2.1.1
+const maxLineLength = 78
+
+// Attachment represents a file attached to MIMEMessage.
+type Attachment struct {
+	// Filename is the name of the file.
+	Filename string
+	// ContentType is the media type of the file. if ContentType is empty, it is detected by the extension of Filename.
+	ContentType string
+	// Data is the content of the file.
+	Data []byte
+}
+
+// MIMEMessage represents a RFC 5322 message with MIME parts.
+//   - if both Text and HTML are given, they are sent as multipart/alternative.
+//   - Headers are written in the fixed order and lines are folded to 78 characters,
+//     so that the message is not modified on the way (which breaks DKIM signatures).
+type MIMEMessage struct {
+	From    *mail.Address
+	To      []*mail.Address
+	Cc      []*mail.Address
+	ReplyTo []*mail.Address
+	Subject string
+	// MessageID is the Message-ID of the message including angle brackets. if MessageID is empty, it is generated from the domain of From.
+	MessageID string
+	// InReplyTo is the Message-ID of the message replied to. This is required to reply to incoming messages.
+	InReplyTo string
+	// Date is the date of the message. if Date is zero, the current time is used.
+	Date time.Time
+	// Headers are extra headers of the message.
+	Headers textproto.MIMEHeader
+	// Text is the plain text body of the message.
+	Text string
+	// HTML is the HTML body of the message.
+	HTML        string
+	Attachments []*Attachment
+}
+
+// Message returns Message to be sent by Sender or replied by ForwardableMessage.
+//   - The envelope addresses are From and the first address of To.
+func (m *MIMEMessage) Message() (*Message, error) {
+	raw, err := m.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		From: m.From.Address,
+		To:   m.To[0].Address,
+		Raw:  bytes.NewReader(raw),
+	}, nil
+}
+
+// Bytes builds the raw content of the message.
+func (m *MIMEMessage) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo writes the raw content of the message to w.
+func (m *MIMEMessage) WriteTo(w io.Writer) (int64, error) {
+	if m.From == nil {
+		return 0, ErrNoSender
+	}
+	if len(m.To) == 0 {
+		return 0, ErrNoRecipients
+	}
+	h, err := m.header()
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	if err := writeBody(&buf, h, m); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// header builds the top level headers of the message except Content-Type.
+func (m *MIMEMessage) header() (*headerWriter, error) {
+	h := &headerWriter{}
+	h.add("From", m.From.String())
+	h.add("To", formatAddressList(m.To))
+	if len(m.Cc) > 0 {
+		h.add("Cc", formatAddressList(m.Cc))
+	}
+	if len(m.ReplyTo) > 0 {
+		h.add("Reply-To", formatAddressList(m.ReplyTo))
+	}
+	h.add("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	h.add("Date", date.Format(time.RFC1123Z))
+	messageID := m.MessageID
+	if messageID == "" {
+		id, err := generateMessageID(m.From.Address)
+		if err != nil {
+			return nil, err
+		}
+		messageID = id
+	}
+	h.add("Message-ID", messageID)
+	if m.InReplyTo != "" {
+		h.add("In-Reply-To", m.InReplyTo)
+		h.add("References", m.InReplyTo)
+	}
+	keys := make([]string, 0, len(m.Headers))
+	for k := range m.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range m.Headers[k] {
+			h.add(k, v)
+		}
+	}
+	h.add("MIME-Version", "1.0")
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// writeBody writes the headers and the body of the message. The structure of the body is:
+//   - multipart/mixed (only when there are attachments)
+//   - multipart/alternative (only when there are both Text and HTML)
+//   - text/plain and text/html
+func writeBody(w *bytes.Buffer, h *headerWriter, m *MIMEMessage) error {
+	textHeader, textBody, err := textPart(m)
+	if err != nil {
+		return err
+	}
+	if len(m.Attachments) == 0 {
+		h.fields = append(h.fields, textHeader.fields...)
+		h.writeTo(w)
+		w.WriteString("\r\n")
+		w.Write(textBody)
+		return nil
+	}
+	mw := multipart.NewWriter(w)
+	h.add("Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": mw.Boundary()}))
+	h.writeTo(w)
+	w.WriteString("\r\n")
+	ph := textproto.MIMEHeader{}
+	for _, f := range textHeader.fields {
+		ph.Add(f[0], f[1])
+	}
+	pw, err := mw.CreatePart(ph)
+	if err != nil {
+		return err
+	}
+	if _, err := pw.Write(textBody); err != nil {
+		return err
+	}
+	for _, a := range m.Attachments {
+		if err := writeAttachment(mw, a); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// textPart builds the headers and the body of the text part of the message.
+func textPart(m *MIMEMessage) (*headerWriter, []byte, error) {
+	h := &headerWriter{}
+	var body bytes.Buffer
+	if m.Text == "" || m.HTML == "" {
+		contentType, text := "text/plain", m.Text
+		if m.HTML != "" {
+			contentType, text = "text/html", m.HTML
+		}
+		h.add("Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"}))
+		h.add("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&body, text); err != nil {
+			return nil, nil, err
+		}
+		return h, body.Bytes(), nil
+	}
+	mw := multipart.NewWriter(&body)
+	h.add("Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	for _, part := range []struct{ contentType, text string }{
+		{"text/plain", m.Text},
+		{"text/html", m.HTML},
+	} {
+		ph := textproto.MIMEHeader{}
+		ph.Set("Content-Type", mime.FormatMediaType(part.contentType, map[string]string{"charset": "utf-8"}))
+		ph.Set("Content-Transfer-Encoding", "quoted-printable")
+		pw, err := mw.CreatePart(ph)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.text); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return h, body.Bytes(), nil
+}
+
+func writeQuotedPrintable(w io.Writer, text string) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write([]byte(text)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+func writeAttachment(mw *multipart.Writer, a *Attachment) error {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(a.Filename))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+	ph := textproto.MIMEHeader{}
+	ph.Set("Content-Type", contentType)
+	ph.Set("Content-Transfer-Encoding", "base64")
+	ph.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	pw, err := mw.CreatePart(ph)
+	if err != nil {
+		return err
+	}
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(pw, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(pw, encoded+"\r\n")
+	return err
+}
+
+func formatAddressList(addrs []*mail.Address) string {
+	formatted := make([]string, len(addrs))
+	for i, addr := range addrs {
+		formatted[i] = addr.String()
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func generateMessageID(from string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("email: error generating Message-ID: %w", err)
+	}
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">", nil
+}
+
+// headerWriter writes headers in the order of addition.
+type headerWriter struct {
+	fields [][2]string
+}
+
+func (h *headerWriter) add(key, value string) {
+	h.fields = append(h.fields, [2]string{textproto.CanonicalMIMEHeaderKey(key), value})
+}
+
+func (h *headerWriter) validate() error {
+	for _, f := range h.fields {
+		if strings.ContainsAny(f[0], "\r\n:") || strings.ContainsAny(f[1], "\r\n") {
+			return fmt.Errorf("%w: %s", ErrInvalidHeader, f[0])
+		}
+	}
+	return nil
+}
+
+func (h *headerWriter) writeTo(w *bytes.Buffer) {
+	for _, f := range h.fields {
+		w.WriteString(foldHeader(f[0] + ": " + f[1]))
+		w.WriteString("\r\n")
+	}
+}
+
+// foldHeader folds the header line at spaces, so that each line is at most maxLineLength characters if possible.
+//   - https://www.rfc-editor.org/rfc/rfc5322#section-2.2.3
+func foldHeader(line string) string {
+	var b strings.Builder
+	for len(line) > maxLineLength {
+		// the first character is skipped, since it is the space of the previous fold.
+		i := strings.LastIndex(line[1:maxLineLength+1], " ") + 1
+		if i == 0 {
+			// no space to fold before the limit, so the line is folded at the next space.
+			j := strings.Index(line[1:], " ")
+			if j < 0 {
+				break
+			}
+			i = j + 1
+		}
+		b.WriteString(line[:i])
+		b.WriteString("\r\n")
+		line = line[i:]
+	}
+	b.WriteString(line)
+	return b.String()
+}
@@ -0,0 +1,241 @@
+package email
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMIMEMessage_Bytes(t *testing.T) {
+	from := &mail.Address{Name: "Sender", Address: "sender@example.com"}
+	to := []*mail.Address{{Address: "to@example.com"}}
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := map[string]struct {
+		msg       *MIMEMessage
+		wantType  string
+		wantParts []string
+	}{
+		"text": {
+			msg:       &MIMEMessage{From: from, To: to, Subject: "hello", Date: date, Text: "hello, world"},
+			wantType:  "text/plain",
+			wantParts: []string{"text/plain"},
+		},
+		"html": {
+			msg:       &MIMEMessage{From: from, To: to, Subject: "hello", Date: date, HTML: "<p>hello</p>"},
+			wantType:  "text/html",
+			wantParts: []string{"text/html"},
+		},
+		"alternative": {
+			msg:       &MIMEMessage{From: from, To: to, Subject: "hello", Date: date, Text: "hello", HTML: "<p>hello</p>"},
+			wantType:  "multipart/alternative",
+			wantParts: []string{"text/plain", "text/html"},
+		},
+		"attachments": {
+			msg: &MIMEMessage{
+				From: from, To: to, Subject: "hello", Date: date, Text: "hello", HTML: "<p>hello</p>",
+				Attachments: []*Attachment{
+					{Filename: "a.txt", Data: []byte("a")},
+					{Filename: "b.bin", ContentType: "application/x-test", Data: bytes.Repeat([]byte("b"), 100)},
+				},
+			},
+			wantType:  "multipart/mixed",
+			wantParts: []string{"multipart/alternative", "text/plain; charset=utf-8", "application/x-test"},
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			raw, err := tc.msg.Bytes()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, line := range strings.Split(string(raw), "\r\n") {
+				if strings.Contains(line, "\n") {
+					t.Fatalf("line contains bare LF: %q", line)
+				}
+			}
+			m, err := mail.ReadMessage(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("failed to parse message: %v", err)
+			}
+			if got := m.Header.Get("Subject"); got != "hello" {
+				t.Errorf("want Subject hello, got %s", got)
+			}
+			if got := m.Header.Get("Date"); got != date.Format(time.RFC1123Z) {
+				t.Errorf("want Date %s, got %s", date.Format(time.RFC1123Z), got)
+			}
+			if m.Header.Get("Message-ID") == "" {
+				t.Errorf("Message-ID is empty")
+			}
+			mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatalf("failed to parse Content-Type: %v", err)
+			}
+			if mediaType != tc.wantType {
+				t.Fatalf("want Content-Type %s, got %s", tc.wantType, mediaType)
+			}
+			if !strings.HasPrefix(mediaType, "multipart/") {
+				return
+			}
+			var gotParts []string
+			r := multipart.NewReader(m.Body, params["boundary"])
+			for {
+				p, err := r.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("failed to read part: %v", err)
+				}
+				partType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+				gotParts = append(gotParts, partType)
+			}
+			if len(gotParts) != len(tc.wantParts) {
+				t.Fatalf("want %d parts, got %v", len(tc.wantParts), gotParts)
+			}
+			for i, want := range tc.wantParts {
+				wantType, _, _ := mime.ParseMediaType(want)
+				if gotParts[i] != wantType {
+					t.Errorf("want parts[%d] %s, got %s", i, wantType, gotParts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMIMEMessage_Bytes_Encoding(t *testing.T) {
+	subject := strings.Repeat("こんにちは", 10)
+	text := strings.Repeat("日本語のテキスト。", 20)
+	msg := &MIMEMessage{
+		From:    &mail.Address{Name: "送信者", Address: "sender@example.com"},
+		To:      []*mail.Address{{Address: "to@example.com"}},
+		Subject: subject,
+		Text:    text,
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 998 {
+			t.Fatalf("line is too long: %d", len(line))
+		}
+		for i := 0; i < len(line); i++ {
+			if line[i] >= 0x80 {
+				t.Fatalf("line contains non-ASCII character: %q", line)
+			}
+		}
+	}
+	m, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("failed to parse message: %v", err)
+	}
+	var dec mime.WordDecoder
+	gotSubject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("failed to decode Subject: %v", err)
+	}
+	if gotSubject != subject {
+		t.Errorf("want Subject %s, got %s", subject, gotSubject)
+	}
+	gotFrom, err := m.Header.AddressList("From")
+	if err != nil {
+		t.Fatalf("failed to parse From: %v", err)
+	}
+	if gotFrom[0].Name != "送信者" {
+		t.Errorf("want From name 送信者, got %s", gotFrom[0].Name)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(m.Body))
+	if err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if string(body) != text {
+		t.Errorf("want body %s, got %s", text, body)
+	}
+}
+
+func TestMIMEMessage_Bytes_Error(t *testing.T) {
+	from := &mail.Address{Address: "sender@example.com"}
+	to := []*mail.Address{{Address: "to@example.com"}}
+	tests := map[string]struct {
+		msg     *MIMEMessage
+		wantErr error
+	}{
+		"no sender": {
+			msg:     &MIMEMessage{To: to},
+			wantErr: ErrNoSender,
+		},
+		"no recipients": {
+			msg:     &MIMEMessage{From: from},
+			wantErr: ErrNoRecipients,
+		},
+		"header injection in subject": {
+			msg:     &MIMEMessage{From: from, To: to, Subject: "a\r\nBcc: evil@example.com"},
+			wantErr: nil,
+		},
+		"header injection in extra headers": {
+			msg:     &MIMEMessage{From: from, To: to, Headers: textproto.MIMEHeader{"X-Test": {"a\r\nBcc: evil@example.com"}}},
+			wantErr: ErrInvalidHeader,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			raw, err := tc.msg.Bytes()
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if bytes.Contains(raw, []byte("\r\nBcc:")) {
+					t.Fatalf("header is injected: %s", raw)
+				}
+				return
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("want error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFoldHeader(t *testing.T) {
+	tests := map[string]struct {
+		line string
+		want string
+	}{
+		"short": {
+			line: "Subject: hello",
+			want: "Subject: hello",
+		},
+		"long": {
+			line: "To: " + strings.Repeat("abcdefghi ", 10),
+			want: "To: abcdefghi abcdefghi abcdefghi abcdefghi abcdefghi abcdefghi abcdefghi\r\n abcdefghi abcdefghi abcdefghi ",
+		},
+		"no space": {
+			line: "X-Test: " + strings.Repeat("a", 100),
+			want: "X-Test:\r\n " + strings.Repeat("a", 100),
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := foldHeader(tc.line); got != tc.want {
+				t.Errorf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
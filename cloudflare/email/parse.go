@@ -0,0 +1,269 @@
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrUnknownCharset is returned when the charset of a text can't be decoded.
+	// Set ReadOptions.CharsetReader to decode other charsets.
+	ErrUnknownCharset = errors.New("email: unknown charset")
+	// ErrTooDeep is returned when multipart parts are nested deeper than maxPartDepth.
+	ErrTooDeep = errors.New("email: multipart is nested too deep")
+)
+
+// maxPartDepth is the maximum depth of nested multipart parts.
+const maxPartDepth = 16
+
+// ReadOptions represents the options of NewMessageReader.
+type ReadOptions struct {
+	// CharsetReader returns the reader which converts input of the charset into UTF-8.
+	// It's called for charsets other than UTF-8, US-ASCII, ISO-8859-1 and Windows-1252, which are decoded by MessageReader.
+	//   - e.g. `charset.NewReaderLabel` of golang.org/x/net/html/charset.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+}
+
+// MessageReader reads the raw content of a message (e.g. ForwardableMessage.Raw) as a stream.
+//   - The header is read by NewMessageReader, and the leaf parts of the body are read by NextPart one by one,
+//     so the message isn't held in the memory at once.
+type MessageReader struct {
+	// Header is the header of the message.
+	Header mail.Header
+
+	body          io.Reader
+	started       bool
+	stack         []*multipart.Reader
+	charsetReader func(charset string, input io.Reader) (io.Reader, error)
+	wordDecoder   *mime.WordDecoder
+}
+
+// NewMessageReader reads the header of the message from r, and returns MessageReader to read the body.
+func NewMessageReader(r io.Reader, opts *ReadOptions) (*MessageReader, error) {
+	if opts == nil {
+		opts = &ReadOptions{}
+	}
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("email: error reading header: %w", err)
+	}
+	m := &MessageReader{
+		Header:        msg.Header,
+		body:          msg.Body,
+		charsetReader: opts.CharsetReader,
+	}
+	m.wordDecoder = &mime.WordDecoder{CharsetReader: m.newCharsetReader}
+	return m, nil
+}
+
+// DecodeHeader returns the value of the header decoded by RFC 2047 (e.g. `=?UTF-8?B?...?=` of Subject).
+//   - if the value can't be decoded, returns the value as it is.
+func (m *MessageReader) DecodeHeader(key string) string {
+	v := m.Header.Get(key)
+	decoded, err := m.wordDecoder.DecodeHeader(v)
+	if err != nil {
+		return v
+	}
+	return decoded
+}
+
+// Subject returns the decoded Subject of the message.
+func (m *MessageReader) Subject() string {
+	return m.DecodeHeader("Subject")
+}
+
+// NextPart returns the next leaf part of the message, walking into multipart parts depth-first.
+//   - Body of the returned part is valid until the next call of NextPart. The rest of the body is skipped.
+//   - If the message is not multipart, the body of the message is returned as the only part.
+//   - Encapsulated messages (message/rfc822) are returned as parts, which can be read by NewMessageReader.
+//   - io.EOF is returned when no parts remain.
+func (m *MessageReader) NextPart() (*Part, error) {
+	for {
+		var (
+			header textproto.MIMEHeader
+			body   io.Reader
+		)
+		if !m.started {
+			m.started = true
+			header, body = textproto.MIMEHeader(m.Header), m.body
+		} else {
+			if len(m.stack) == 0 {
+				return nil, io.EOF
+			}
+			p, err := m.stack[len(m.stack)-1].NextRawPart()
+			if err == io.EOF {
+				m.stack = m.stack[:len(m.stack)-1]
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("email: error reading part: %w", err)
+			}
+			header, body = p.Header, p
+		}
+		mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+		if err != nil {
+			// the default of RFC 2045.
+			mediaType, params = "text/plain", map[string]string{"charset": "us-ascii"}
+		}
+		if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+			if len(m.stack) >= maxPartDepth {
+				return nil, ErrTooDeep
+			}
+			m.stack = append(m.stack, multipart.NewReader(body, params["boundary"]))
+			continue
+		}
+		return m.newPart(header, mediaType, params, body), nil
+	}
+}
+
+func (m *MessageReader) newPart(header textproto.MIMEHeader, mediaType string, params map[string]string, body io.Reader) *Part {
+	p := &Part{
+		Header:      header,
+		ContentType: mediaType,
+		Params:      params,
+		Body:        decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body),
+		reader:      m,
+	}
+	if disposition, dparams, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		p.Disposition = disposition
+		p.Filename = dparams["filename"]
+	}
+	if p.Filename == "" {
+		p.Filename = params["name"]
+	}
+	if decoded, err := m.wordDecoder.DecodeHeader(p.Filename); err == nil {
+		p.Filename = decoded
+	}
+	return p
+}
+
+// decodeTransferEncoding returns the reader which decodes body by Content-Transfer-Encoding.
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// newCharsetReader returns the reader which converts input of the charset into UTF-8.
+func (m *MessageReader) newCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "l1":
+		return &singleByteReader{r: bufio.NewReader(input)}, nil
+	case "windows-1252", "cp1252":
+		return &singleByteReader{r: bufio.NewReader(input), table: &windows1252}, nil
+	}
+	if m.charsetReader != nil {
+		return m.charsetReader(charset, input)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownCharset, charset)
+}
+
+// Part represents a leaf part of the message.
+type Part struct {
+	// Header is the header of the part.
+	Header textproto.MIMEHeader
+	// ContentType is the lower-cased media type of the part (e.g. `text/plain`).
+	ContentType string
+	// Params are the parameters of Content-Type (e.g. `charset`).
+	Params map[string]string
+	// Disposition is the disposition type of Content-Disposition (e.g. `attachment` or `inline`). It's empty if the header is absent.
+	Disposition string
+	// Filename is the decoded file name given by Content-Disposition or Content-Type.
+	Filename string
+	// Body is the content of the part decoded by Content-Transfer-Encoding. The charset of texts is not converted. Use Text to read texts.
+	Body io.Reader
+
+	reader *MessageReader
+}
+
+// IsAttachment reports whether the part is an attachment rather than the body of the message.
+func (p *Part) IsAttachment() bool {
+	return p.Disposition == "attachment" || (p.Disposition == "" && p.Filename != "")
+}
+
+// Charset returns the charset of the part. The default is `us-ascii`.
+func (p *Part) Charset() string {
+	if charset := p.Params["charset"]; charset != "" {
+		return charset
+	}
+	return "us-ascii"
+}
+
+// TextReader returns the reader of Body converted from the charset into UTF-8.
+func (p *Part) TextReader() (io.Reader, error) {
+	return p.reader.newCharsetReader(p.Charset(), p.Body)
+}
+
+// Text reads Body converted from the charset into UTF-8.
+func (p *Part) Text() (string, error) {
+	r, err := p.TextReader()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if _, err := io.Copy(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// singleByteReader converts single byte charsets into UTF-8. if table is nil, it's ISO-8859-1.
+type singleByteReader struct {
+	r     *bufio.Reader
+	table *[32]rune
+	// rest is the encoded rune which didn't fit into p of the last Read.
+	rest []byte
+}
+
+func (r *singleByteReader) Read(p []byte) (int, error) {
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+	for n < len(p) {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		rn := rune(c)
+		if r.table != nil && 0x80 <= c && c < 0xA0 {
+			rn = r.table[c-0x80]
+		}
+		if rn < utf8.RuneSelf {
+			p[n] = byte(rn)
+			n++
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], rn)
+		copied := copy(p[n:], buf[:size])
+		n += copied
+		if copied < size {
+			r.rest = append(r.rest[:0], buf[copied:size]...)
+		}
+	}
+	return n, nil
+}
+
+// windows1252 maps 0x80-0x9F of Windows-1252, which differ from ISO-8859-1. Undefined bytes are mapped to U+FFFD.
+var windows1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
@@ -0,0 +1,184 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+const rawMultipart = "From: =?UTF-8?B?w4lsaXNl?= <elise@example.com>\r\n" +
+	"To: to@example.com\r\n" +
+	"Subject: =?ISO-8859-1?Q?caf=E9?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"preamble\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=iso-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"caf=E9 =80\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=windows-1252\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<p>=80</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/octet-stream; name=\"ignored.bin\"\r\n" +
+	"Content-Disposition: attachment; filename*=UTF-8''%C3%A9t%C3%A9.bin\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"AAEC\r\n" +
+	"AwQ=\r\n" +
+	"--outer\r\n" +
+	"Content-Type: text/plain; charset=shift_jis\r\n" +
+	"\r\n" +
+	"unknown\r\n" +
+	"--outer--\r\n"
+
+func TestMessageReader(t *testing.T) {
+	r, err := NewMessageReader(strings.NewReader(rawMultipart), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Subject(); got != "café" {
+		t.Errorf("Subject() = %q, want café", got)
+	}
+	if from, err := r.Header.AddressList("From"); err != nil || from[0].Name != "Élise" {
+		t.Errorf("From = %v, %v", from, err)
+	}
+
+	part, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := part.Text(); err != nil || text != "café \u0080" || part.IsAttachment() {
+		t.Errorf("text/plain = %q, %v, attachment %v", text, err, part.IsAttachment())
+	}
+
+	part, err = r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := part.Text(); err != nil || text != "<p>€</p>" || part.ContentType != "text/html" {
+		t.Errorf("%s = %q, %v", part.ContentType, text, err)
+	}
+
+	part, err = r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(part.Body)
+	if err != nil || !bytes.Equal(data, []byte{0, 1, 2, 3, 4}) {
+		t.Errorf("attachment = %v, %v", data, err)
+	}
+	if !part.IsAttachment() || part.Filename != "été.bin" {
+		t.Errorf("attachment: %v, Filename = %q", part.IsAttachment(), part.Filename)
+	}
+
+	part, err = r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Text(); !errors.Is(err, ErrUnknownCharset) {
+		t.Errorf("want ErrUnknownCharset, got %v", err)
+	}
+
+	if _, err := r.NextPart(); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+}
+
+func TestMessageReader_CharsetReader(t *testing.T) {
+	raw := "Subject: hi\r\nContent-Type: text/plain; charset=x-upper\r\n\r\nhello"
+	r, err := NewMessageReader(strings.NewReader(raw), &ReadOptions{
+		CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+			b, err := io.ReadAll(input)
+			return strings.NewReader(strings.ToUpper(string(b))), err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	part, err := r.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := part.Text(); err != nil || text != "HELLO" {
+		t.Errorf("Text() = %q, %v", text, err)
+	}
+	if _, err := r.NextPart(); err != io.EOF {
+		t.Errorf("want io.EOF, got %v", err)
+	}
+}
+
+func TestMessageReader_MIMEMessage(t *testing.T) {
+	msg := &MIMEMessage{
+		From:    &mail.Address{Address: "sender@example.com"},
+		To:      []*mail.Address{{Address: "to@example.com"}},
+		Subject: "こんにちは",
+		Text:    "hello",
+		HTML:    "<p>hello</p>",
+		Attachments: []*Attachment{
+			{Filename: "a.txt", Data: []byte("attached")},
+		},
+	}
+	raw, err := msg.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewMessageReader(bytes.NewReader(raw), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Subject() != "こんにちは" {
+		t.Errorf("Subject() = %q", r.Subject())
+	}
+	var got []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := part.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, part.ContentType+":"+part.Filename+":"+text)
+	}
+	want := "text/plain::hello,text/html::<p>hello</p>,text/plain:a.txt:attached"
+	if strings.Join(got, ",") != want {
+		t.Errorf("parts = %q, want %q", strings.Join(got, ","), want)
+	}
+}
+
+func TestSingleByteReader(t *testing.T) {
+	r := &singleByteReader{r: bufioReader("\xe9\x80"), table: &windows1252}
+	var out []byte
+	p := make([]byte, 1)
+	for {
+		n, err := r.Read(p)
+		out = append(out, p[:n]...)
+		if err == io.EOF {
+			break
+		}
+	}
+	if string(out) != "é€" {
+		t.Errorf("got %q, want é€", out)
+	}
+}
+
+func bufioReader(s string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(s))
+}
@@ -0,0 +1,50 @@
+//go:build js && wasm
+
+package email
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Sender represents the send_email binding, which sends emails to verified addresses.
+//   - https://developers.cloudflare.com/email-routing/email-workers/send-email-workers/
+type Sender struct {
+	instance          js.Value
+	emailMessageClass js.Value
+}
+
+// NewSender returns Sender for given variable name.
+//   - variable name must be defined in wrangler.toml as send_email's name.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewSender(ctx context.Context, varName string) (*Sender, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	emailMessageClass, err := cfruntimecontext.GetRuntimeContextValue(ctx, "EmailMessage")
+	if err != nil {
+		return nil, fmt.Errorf("email: EmailMessage is not available: %w", err)
+	}
+	return &Sender{instance: inst, emailMessageClass: emailMessageClass}, nil
+}
+
+// Send sends the message.
+//   - msg.Raw can be built by MIMEMessage.
+func (s *Sender) Send(msg *Message) error {
+	msgObj, err := msg.toJS(s.emailMessageClass)
+	if err != nil {
+		return err
+	}
+	promise, err := jsutil.TryCall(s.instance, "send", msgObj)
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(promise)
+	return err
+}
@@ -0,0 +1,100 @@
+// Package entrypoint provides the way to export named entrypoints (WorkerEntrypoint) implemented in Go.
+//   - Other workers can bind to the entrypoints by service bindings with `entrypoint = "Name"`.
+//   - https://developers.cloudflare.com/workers/runtime-apis/bindings/service-bindings/rpc/#named-entrypoints
+package entrypoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// Entrypoint represents a named entrypoint of the worker.
+//   - The name and the method names must also be given to workers-assets-gen (e.g. `-entrypoint Admin:getUser,deleteUser`),
+//     so the generated class has the methods.
+//   - RPC methods require compatibility date 2024-04-03 or later.
+type Entrypoint struct {
+	// Handler handles requests sent by `fetch()` of service bindings. if Handler is nil, requests fail with 404.
+	Handler http.Handler
+	// Methods are the RPC methods of the entrypoint by their names.
+	Methods map[string]cloudflare.RPCMethod
+}
+
+var entrypoints = map[string]*Entrypoint{}
+
+// Register registers the Entrypoint with the name.
+//   - Register must be called before `workers.Serve` (or other functions which start the worker).
+func Register(name string, ep *Entrypoint) {
+	entrypoints[name] = ep
+	registerHandleEntrypointRequest()
+	registerCallEntrypoint()
+}
+
+func lookup(name string) (*Entrypoint, error) {
+	ep, ok := entrypoints[name]
+	if !ok {
+		return nil, fmt.Errorf("entrypoint: %s is not registered", name)
+	}
+	return ep, nil
+}
+
+func handleEntrypointRequest(name string, reqObj js.Value, runtimeCtxObj js.Value) (js.Value, error) {
+	ep, err := lookup(name)
+	if err != nil {
+		return js.Value{}, err
+	}
+	handler := ep.Handler
+	if handler == nil {
+		handler = http.NotFoundHandler()
+	}
+	return jshttp.ServeJSRequest(handler, reqObj, runtimeCtxObj)
+}
+
+func callEntrypoint(name, method string, args []any, runtimeCtxObj js.Value) (js.Value, error) {
+	ep, err := lookup(name)
+	if err != nil {
+		return js.Value{}, err
+	}
+	m, ok := ep.Methods[method]
+	if !ok {
+		return js.Value{}, fmt.Errorf("entrypoint: %s doesn't have RPC method %s", name, method)
+	}
+	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
+	result, err := m(ctx, args)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.ToJSValue(result)
+}
+
+// registerHandleEntrypointRequest sets handleEntrypointRequest called by the JavaScript side.
+var registerHandleEntrypointRequest = jsutil.LazyRegister("handleEntrypointRequest", func(_ js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		panic(fmt.Errorf("invalid number of arguments given to handleEntrypointRequest: %d", len(args)))
+	}
+	name, reqObj, runtimeCtxObj := args[0].String(), args[1], args[2]
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		return handleEntrypointRequest(name, reqObj, runtimeCtxObj)
+	})
+})
+
+// registerCallEntrypoint sets callEntrypoint called by the JavaScript side.
+var registerCallEntrypoint = jsutil.LazyRegister("callEntrypoint", func(_ js.Value, args []js.Value) any {
+	if len(args) != 4 {
+		panic(fmt.Errorf("invalid number of arguments given to callEntrypoint: %d", len(args)))
+	}
+	name, method, argsArr, runtimeCtxObj := args[0].String(), args[1].String(), args[2], args[3]
+	methodArgs := make([]any, argsArr.Length())
+	for i := range methodArgs {
+		methodArgs[i] = jsutil.ToGoValue(argsArr.Index(i))
+	}
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		return callEntrypoint(name, method, methodArgs, runtimeCtxObj)
+	})
+})
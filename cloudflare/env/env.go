@@ -0,0 +1,200 @@
+// Package env provides typed accessors of environment variables and secrets, with required and default semantics.
+//
+// Accessors record errors of missing or invalid variables instead of failing one by one,
+// so all problems of the configuration can be reported at once by Err.
+//
+//	e := env.New(ctx)
+//	cfg := Config{
+//		APIURL:  e.String("API_URL"),
+//		APIKey:  e.String("API_KEY"),
+//		Timeout: e.DurationOr("TIMEOUT", 5*time.Second),
+//		Debug:   e.BoolOr("DEBUG", false),
+//	}
+//	if err := e.Err(); err != nil {
+//		// e.g. `env: missing required variables: API_URL, API_KEY`
+//	}
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationError represents all missing and invalid variables found by an Env.
+type ValidationError struct {
+	// Missing are the names of required variables which are not set.
+	Missing []string
+	// Invalid are the errors of variables which can't be parsed by their names.
+	Invalid map[string]error
+}
+
+func (e *ValidationError) Error() string {
+	var msgs []string
+	if len(e.Missing) > 0 {
+		msgs = append(msgs, "missing required variables: "+strings.Join(e.Missing, ", "))
+	}
+	names := make([]string, 0, len(e.Invalid))
+	for name := range e.Invalid {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("invalid %s: %v", name, e.Invalid[name]))
+	}
+	return "env: " + strings.Join(msgs, "; ")
+}
+
+// Env reads variables by the lookup function, and records missing and invalid ones.
+//   - Env is not safe for concurrent use. Create an Env for each use (e.g. on the first request).
+type Env struct {
+	lookup  func(name string) (string, bool)
+	missing []string
+	invalid map[string]error
+}
+
+// FromLookup returns Env which reads variables by lookup, e.g. `os.LookupEnv` outside of Workers.
+func FromLookup(lookup func(name string) (string, bool)) *Env {
+	return &Env{lookup: lookup, invalid: map[string]error{}}
+}
+
+// FromMap returns Env which reads variables from m. This is useful in tests.
+func FromMap(m map[string]string) *Env {
+	return FromLookup(func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	})
+}
+
+// Err returns *ValidationError of all missing and invalid variables read so far, or nil if there is none.
+func (e *Env) Err() error {
+	if len(e.missing) == 0 && len(e.invalid) == 0 {
+		return nil
+	}
+	invalid := make(map[string]error, len(e.invalid))
+	for name, err := range e.invalid {
+		invalid[name] = err
+	}
+	return &ValidationError{Missing: append([]string(nil), e.missing...), Invalid: invalid}
+}
+
+// Has reports whether the variable is set.
+func (e *Env) Has(name string) bool {
+	_, ok := e.lookup(name)
+	return ok
+}
+
+// Require records variables which are not set as missing, without reading them.
+//   - This is useful to validate all variables used later at once.
+func (e *Env) Require(names ...string) {
+	for _, name := range names {
+		if !e.Has(name) {
+			e.addMissing(name)
+		}
+	}
+}
+
+func (e *Env) addMissing(name string) {
+	for _, m := range e.missing {
+		if m == name {
+			return
+		}
+	}
+	e.missing = append(e.missing, name)
+}
+
+// get returns the value of the required variable. if it's not set, records it as missing.
+func (e *Env) get(name string) (string, bool) {
+	v, ok := e.lookup(name)
+	if !ok {
+		e.addMissing(name)
+	}
+	return v, ok
+}
+
+// parse parses the value of the variable by fn. ok is false if the variable is not set or invalid.
+func parse[T any](e *Env, name string, required bool, fn func(string) (T, error)) (value T, ok bool) {
+	var v string
+	if required {
+		v, ok = e.get(name)
+	} else {
+		v, ok = e.lookup(name)
+	}
+	if !ok {
+		return value, false
+	}
+	value, err := fn(v)
+	if err != nil {
+		e.invalid[name] = err
+		return value, false
+	}
+	return value, true
+}
+
+// String returns the value of the required variable. if it's not set, returns empty.
+func (e *Env) String(name string) string {
+	v, _ := e.get(name)
+	return v
+}
+
+// StringOr returns the value of the variable, or def if it's not set.
+func (e *Env) StringOr(name, def string) string {
+	if v, ok := e.lookup(name); ok {
+		return v
+	}
+	return def
+}
+
+// Int returns the value of the required variable parsed as int. if it's not set or invalid, returns 0.
+func (e *Env) Int(name string) int {
+	v, _ := parse(e, name, true, strconv.Atoi)
+	return v
+}
+
+// IntOr returns the value of the variable parsed as int, or def if it's not set or invalid.
+func (e *Env) IntOr(name string, def int) int {
+	if v, ok := parse(e, name, false, strconv.Atoi); ok {
+		return v
+	}
+	return def
+}
+
+// Bool returns the value of the required variable parsed by strconv.ParseBool. if it's not set or invalid, returns false.
+func (e *Env) Bool(name string) bool {
+	v, _ := parse(e, name, true, strconv.ParseBool)
+	return v
+}
+
+// BoolOr returns the value of the variable parsed by strconv.ParseBool, or def if it's not set or invalid.
+func (e *Env) BoolOr(name string, def bool) bool {
+	if v, ok := parse(e, name, false, strconv.ParseBool); ok {
+		return v
+	}
+	return def
+}
+
+// Duration returns the value of the required variable parsed by time.ParseDuration. if it's not set or invalid, returns 0.
+func (e *Env) Duration(name string) time.Duration {
+	v, _ := parse(e, name, true, time.ParseDuration)
+	return v
+}
+
+// DurationOr returns the value of the variable parsed by time.ParseDuration, or def if it's not set or invalid.
+func (e *Env) DurationOr(name string, def time.Duration) time.Duration {
+	if v, ok := parse(e, name, false, time.ParseDuration); ok {
+		return v
+	}
+	return def
+}
+
+// JSON decodes the value of the required variable into v by encoding/json.
+//   - Variables defined as objects in wrangler.toml can be decoded, since they are given as JSON.
+//   - To make the variable optional, check Has before calling JSON.
+func (e *Env) JSON(name string, v any) {
+	parse(e, name, true, func(s string) (struct{}, error) {
+		return struct{}{}, json.Unmarshal([]byte(s), v)
+	})
+}
@@ -0,0 +1,32 @@
+//go:build js && wasm
+
+package env
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// New returns Env which reads variables and secrets of the worker.
+//   - Values other than strings (e.g. objects of `[vars]` in wrangler.toml) are read as JSON.
+//   - This function panics when a runtime context is not found.
+func New(ctx context.Context) *Env {
+	envObj := cfruntimecontext.GetRuntimeContextEnv(ctx)
+	return FromLookup(func(name string) (string, bool) {
+		v := envObj.Get(name)
+		switch v.Type() {
+		case js.TypeUndefined, js.TypeNull:
+			return "", false
+		case js.TypeString:
+			return v.String(), true
+		}
+		s, err := jsutil.TryCall(jsutil.JSONClass(), "stringify", v)
+		if err != nil || s.Type() != js.TypeString {
+			return "", false
+		}
+		return s.String(), true
+	})
+}
@@ -0,0 +1,76 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEnv(t *testing.T) {
+	e := FromMap(map[string]string{
+		"NAME":    "worker",
+		"PORT":    "8080",
+		"DEBUG":   "true",
+		"TIMEOUT": "3s",
+		"CONFIG":  `{"a":1}`,
+	})
+	if got := e.String("NAME"); got != "worker" {
+		t.Errorf("String: got %q", got)
+	}
+	if got := e.Int("PORT"); got != 8080 {
+		t.Errorf("Int: got %d", got)
+	}
+	if got := e.Bool("DEBUG"); !got {
+		t.Errorf("Bool: got %v", got)
+	}
+	if got := e.Duration("TIMEOUT"); got != 3*time.Second {
+		t.Errorf("Duration: got %v", got)
+	}
+	var cfg struct{ A int }
+	e.JSON("CONFIG", &cfg)
+	if cfg.A != 1 {
+		t.Errorf("JSON: got %+v", cfg)
+	}
+	if got := e.StringOr("REGION", "auto"); got != "auto" {
+		t.Errorf("StringOr: got %q", got)
+	}
+	if got := e.IntOr("PORT", 1); got != 8080 {
+		t.Errorf("IntOr: got %d", got)
+	}
+	if err := e.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEnvErr(t *testing.T) {
+	e := FromMap(map[string]string{
+		"PORT":  "http",
+		"DEBUG": "maybe",
+	})
+	e.String("API_URL")
+	if got := e.IntOr("PORT", 80); got != 80 {
+		t.Errorf("IntOr: got %d", got)
+	}
+	e.Bool("DEBUG")
+	e.Duration("TIMEOUT")
+	e.Require("API_KEY", "API_URL")
+
+	err := e.Err()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("want *ValidationError, got %v", err)
+	}
+	if want := []string{"API_URL", "TIMEOUT", "API_KEY"}; !reflect.DeepEqual(verr.Missing, want) {
+		t.Errorf("Missing: want %v, got %v", want, verr.Missing)
+	}
+	if len(verr.Invalid) != 2 || verr.Invalid["PORT"] == nil || verr.Invalid["DEBUG"] == nil {
+		t.Errorf("Invalid: got %v", verr.Invalid)
+	}
+	want := `env: missing required variables: API_URL, TIMEOUT, API_KEY; ` +
+		`invalid DEBUG: strconv.ParseBool: parsing "maybe": invalid syntax; ` +
+		`invalid PORT: strconv.Atoi: parsing "http": invalid syntax`
+	if err.Error() != want {
+		t.Errorf("Error:\nwant %s\ngot  %s", want, err.Error())
+	}
+}
@@ -0,0 +1,169 @@
+// Package errorreport provides the middleware which reports panics and 5xx responses of handlers to error trackers.
+//   - Reports are sent after the response has been sent, by `cloudflare.WaitUntil`.
+//   - SentryReporter sends reports to Sentry, or services compatible with the Sentry API.
+//   - TinyGo can't recover from panics on WebAssembly, so only 5xx responses are reported on TinyGo.
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// Event represents an error reported by Middleware.
+type Event struct {
+	// Message is the message of the error, e.g. `panic: boom` or `500 Internal Server Error`.
+	Message string
+	// Panic is the value recovered from the panic. Panic is nil for 5xx responses.
+	Panic any
+	// Stack is the stack trace of the goroutine which panicked. Stack is nil for 5xx responses.
+	Stack []byte
+	// Status is the status code of the response.
+	Status int
+	// Time is the time when the error happened.
+	Time time.Time
+	// Request is the metadata of the request which caused the error.
+	Request RequestInfo
+	// Version is the ID of the version of the worker, or empty if the version metadata binding is not set.
+	Version string
+}
+
+// RequestInfo represents the metadata of the request which caused the error.
+type RequestInfo struct {
+	Method string
+	URL    string
+	// Header is the header of the request. Headers with credentials (e.g. Authorization and Cookie) are removed.
+	Header http.Header
+	// Ray is the value of the cf-ray header of the request.
+	Ray string
+}
+
+// Reporter reports errors to error trackers.
+type Reporter interface {
+	Report(ctx context.Context, e *Event) error
+}
+
+// ReporterFunc is an adapter to use the function as Reporter.
+type ReporterFunc func(ctx context.Context, e *Event) error
+
+// Report calls f(ctx, e).
+func (f ReporterFunc) Report(ctx context.Context, e *Event) error {
+	return f(ctx, e)
+}
+
+// Options represents the options of Middleware.
+type Options struct {
+	// VersionMetadata is the binding name of the version metadata, used to set Version of events.
+	// if VersionMetadata is empty, Version is not set.
+	VersionMetadata string
+	// RepanicOnPanic makes Middleware panic again after reporting panics.
+	// By default, panics are recovered and `500 Internal Server Error` is sent if the response hasn't been written yet.
+	RepanicOnPanic bool
+	// ErrorHandler is called with errors of reports.
+	// if ErrorHandler is nil, errors are written by the standard logger.
+	ErrorHandler func(err error)
+}
+
+// sensitiveHeaders are removed from headers of reported requests.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Cf-Access-Jwt-Assertion"}
+
+// Middleware returns the middleware which reports panics and 5xx responses of the handler by the reporter.
+func Middleware(reporter Reporter, opts *Options) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	errorHandler := opts.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = func(err error) {
+			log.Printf("errorreport: %v", err)
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rec := &recorder{ResponseWriter: w}
+			defer func() {
+				r := recover()
+				var e *Event
+				switch {
+				case r != nil:
+					e = &Event{
+						Message: fmt.Sprintf("panic: %v", r),
+						Panic:   r,
+						Stack:   debug.Stack(),
+						Status:  http.StatusInternalServerError,
+					}
+					if rec.status == 0 && !opts.RepanicOnPanic {
+						http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					}
+				case rec.status >= 500:
+					e = &Event{
+						Message: fmt.Sprintf("%d %s", rec.status, http.StatusText(rec.status)),
+						Status:  rec.status,
+					}
+				}
+				if e != nil {
+					e.Time = time.Now()
+					e.Request = newRequestInfo(req)
+					if opts.VersionMetadata != "" {
+						e.Version = versionID(req.Context(), opts.VersionMetadata)
+					}
+					ctx := req.Context()
+					waitUntil(ctx, func() {
+						if err := reporter.Report(ctx, e); err != nil {
+							errorHandler(err)
+						}
+					})
+				}
+				if r != nil && opts.RepanicOnPanic {
+					panic(r)
+				}
+			}()
+			next.ServeHTTP(rec, req)
+		})
+	}
+}
+
+func newRequestInfo(req *http.Request) RequestInfo {
+	header := req.Header.Clone()
+	for _, key := range sensitiveHeaders {
+		header.Del(key)
+	}
+	return RequestInfo{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: header,
+		Ray:    req.Header.Get("Cf-Ray"),
+	}
+}
+
+// recorder is http.ResponseWriter which records the status code of the response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+// firstLine returns the first line of s.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+//go:build js && wasm
+
+package errorreport
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func defaultTransport() http.RoundTripper {
+	return fetch.NewClient().HTTPClient(fetch.RedirectModeFollow).Transport
+}
+
+// waitUntil runs the task by `cloudflare.WaitUntil`.
+//   - if ctx doesn't hold the runtime context (e.g. in tests), the task is run before returning.
+func waitUntil(ctx context.Context, task func()) {
+	if _, ok := runtimecontext.Extract(ctx); !ok {
+		task()
+		return
+	}
+	cloudflare.WaitUntil(ctx, task)
+}
+
+// versionID returns the ID of the version metadata binding, or empty if it's not available.
+func versionID(ctx context.Context, varName string) string {
+	if _, ok := runtimecontext.Extract(ctx); !ok {
+		return ""
+	}
+	m, err := cloudflare.NewVersionMetadata(ctx, varName)
+	if err != nil {
+		return ""
+	}
+	return m.ID
+}
@@ -0,0 +1,22 @@
+//go:build !(js && wasm)
+
+package errorreport
+
+import (
+	"context"
+	"net/http"
+)
+
+func defaultTransport() http.RoundTripper {
+	return http.DefaultTransport
+}
+
+// waitUntil runs the task before returning, since there is no `waitUntil` outside of Workers.
+func waitUntil(ctx context.Context, task func()) {
+	task()
+}
+
+// versionID returns empty, since there is no version metadata outside of Workers.
+func versionID(ctx context.Context, varName string) string {
+	return ""
+}
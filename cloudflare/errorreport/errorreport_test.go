@@ -0,0 +1,61 @@
+package errorreport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(t *testing.T, h http.HandlerFunc) (*httptest.ResponseRecorder, []*Event) {
+	t.Helper()
+	var events []*Event
+	reporter := ReporterFunc(func(ctx context.Context, e *Event) error {
+		events = append(events, e)
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/items", nil)
+	req.Header.Set("Cf-Ray", "abc-NRT")
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	Middleware(reporter, nil)(h).ServeHTTP(rec, req)
+	return rec, events
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	rec, events := serve(t, func(w http.ResponseWriter, req *http.Request) {
+		panic("boom")
+	})
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("want status 500, got %d", rec.Code)
+	}
+	if len(events) != 1 {
+		t.Fatalf("want 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Message != "panic: boom" || e.Panic != "boom" || len(e.Stack) == 0 || e.Status != http.StatusInternalServerError {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e.Request.Method != http.MethodGet || e.Request.URL != "https://example.com/items" || e.Request.Ray != "abc-NRT" {
+		t.Errorf("unexpected request info: %+v", e.Request)
+	}
+	if e.Request.Header.Get("Authorization") != "" || e.Request.Header.Get("Accept") != "application/json" {
+		t.Errorf("unexpected header: %v", e.Request.Header)
+	}
+}
+
+func TestMiddleware_Status(t *testing.T) {
+	_, events := serve(t, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	if len(events) != 1 || events[0].Message != "502 Bad Gateway" || events[0].Panic != nil {
+		t.Errorf("unexpected events: %+v", events)
+	}
+	_, events = serve(t, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	if len(events) != 0 {
+		t.Errorf("want no events for 4xx, got %+v", events)
+	}
+}
@@ -0,0 +1,167 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidDSN is returned by NewSentryReporter when the DSN is invalid.
+var ErrInvalidDSN = errors.New("errorreport: invalid Sentry DSN")
+
+// SentryReporter is the Reporter which sends events to the store endpoint of the Sentry API.
+//   - https://develop.sentry.dev/sdk/store/
+type SentryReporter struct {
+	storeURL    string
+	publicKey   string
+	environment string
+	httpClient  *http.Client
+}
+
+// SentryOption is a type that represents an optional function of NewSentryReporter.
+type SentryOption func(*SentryReporter)
+
+// WithEnvironment sets the environment of events, e.g. `production`.
+func WithEnvironment(env string) SentryOption {
+	return func(r *SentryReporter) {
+		r.environment = env
+	}
+}
+
+// WithHTTPClient changes the HTTP client used to send events.
+//   - The default client sends requests by the Fetch API of Workers.
+func WithHTTPClient(c *http.Client) SentryOption {
+	return func(r *SentryReporter) {
+		r.httpClient = c
+	}
+}
+
+// NewSentryReporter returns SentryReporter which sends events to the project of the DSN.
+//   - dsn has the form of `https://<public key>@<host>/<project ID>`.
+func NewSentryReporter(dsn string, opts ...SentryOption) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDSN, err)
+	}
+	projectID := strings.TrimPrefix(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || projectID == "" {
+		return nil, ErrInvalidDSN
+	}
+	path := "/"
+	if i := strings.LastIndex(projectID, "/"); i >= 0 {
+		path += projectID[:i+1]
+		projectID = projectID[i+1:]
+	}
+	r := &SentryReporter{
+		storeURL:   u.Scheme + "://" + u.Host + path + "api/" + projectID + "/store/",
+		publicKey:  u.User.Username(),
+		httpClient: &http.Client{Transport: defaultTransport()},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// sentryEvent is the JSON encoding of events of the Sentry API.
+//   - https://develop.sentry.dev/sdk/event-payloads/
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Exception   *sentryExceptions `json:"exception,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	Request     sentryRequest     `json:"request"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sentryRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+func (r *SentryReporter) encode(e *Event) *sentryEvent {
+	var id [16]byte
+	rand.Read(id[:])
+	se := &sentryEvent{
+		EventID:     hex.EncodeToString(id[:]),
+		Timestamp:   e.Time.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "errorreport",
+		Release:     e.Version,
+		Environment: r.environment,
+		Request: sentryRequest{
+			URL:    e.Request.URL,
+			Method: e.Request.Method,
+		},
+		Tags: map[string]string{"status": fmt.Sprint(e.Status)},
+	}
+	if e.Request.Ray != "" {
+		se.Tags["ray"] = e.Request.Ray
+	}
+	if len(e.Request.Header) > 0 {
+		se.Request.Headers = make(map[string]string, len(e.Request.Header))
+		for key, values := range e.Request.Header {
+			se.Request.Headers[key] = strings.Join(values, ", ")
+		}
+	}
+	if e.Panic != nil {
+		se.Level = "fatal"
+		se.Exception = &sentryExceptions{Values: []sentryException{{
+			Type:  fmt.Sprintf("panic (%T)", e.Panic),
+			Value: firstLine(fmt.Sprint(e.Panic)),
+		}}}
+		se.Extra = map[string]string{"stack": string(e.Stack)}
+	} else {
+		se.Message = e.Message
+	}
+	return se
+}
+
+// Report sends the event to Sentry.
+func (r *SentryReporter) Report(ctx context.Context, e *Event) error {
+	body, err := json.Marshal(r.encode(e))
+	if err != nil {
+		return fmt.Errorf("errorreport: error encoding event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=syumai-workers/1.0, sentry_key="+r.publicKey)
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("errorreport: unexpected status %d from Sentry: %s", res.StatusCode, b)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package errorreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func TestNewSentryReporter(t *testing.T) {
+	tests := map[string]string{
+		"https://key@o1.ingest.sentry.io/123":     "https://o1.ingest.sentry.io/api/123/store/",
+		"https://key@sentry.example.com/path/456": "https://sentry.example.com/path/api/456/store/",
+	}
+	for dsn, want := range tests {
+		r, err := NewSentryReporter(dsn)
+		if err != nil {
+			t.Fatalf("NewSentryReporter(%q) error = %v", dsn, err)
+		}
+		if r.storeURL != want || r.publicKey != "key" {
+			t.Errorf("NewSentryReporter(%q) = %s (key %s), want %s", dsn, r.storeURL, r.publicKey, want)
+		}
+	}
+	for _, dsn := range []string{"https://o1.ingest.sentry.io/123", "https://key@o1.ingest.sentry.io/", "::"} {
+		if _, err := NewSentryReporter(dsn); !errors.Is(err, ErrInvalidDSN) {
+			t.Errorf("NewSentryReporter(%q) error = %v, want ErrInvalidDSN", dsn, err)
+		}
+	}
+}
+
+func TestSentryReporter_Report(t *testing.T) {
+	var (
+		gotReq  *http.Request
+		gotBody map[string]any
+	)
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		gotReq = req
+		json.NewDecoder(req.Body).Decode(&gotBody)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Header: http.Header{}}
+	})}
+	r, err := NewSentryReporter("https://key@o1.ingest.sentry.io/123", WithHTTPClient(client), WithEnvironment("production"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = r.Report(context.Background(), &Event{
+		Message: "panic: boom",
+		Panic:   errors.New("boom"),
+		Stack:   []byte("goroutine 1 [running]:"),
+		Status:  http.StatusInternalServerError,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Request: RequestInfo{Method: http.MethodGet, URL: "https://example.com/", Ray: "abc-NRT"},
+		Version: "v1",
+	})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if gotReq.URL.String() != "https://o1.ingest.sentry.io/api/123/store/" {
+		t.Errorf("unexpected URL: %s", gotReq.URL)
+	}
+	if auth := gotReq.Header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=key") {
+		t.Errorf("unexpected X-Sentry-Auth: %s", auth)
+	}
+	if gotBody["level"] != "fatal" || gotBody["release"] != "v1" || gotBody["environment"] != "production" ||
+		gotBody["timestamp"] != "2024-01-02T03:04:05.000Z" {
+		t.Errorf("unexpected body: %v", gotBody)
+	}
+	exception := gotBody["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)
+	if exception["type"] != "panic (*errors.errorString)" || exception["value"] != "boom" {
+		t.Errorf("unexpected exception: %v", exception)
+	}
+	if tags := gotBody["tags"].(map[string]any); tags["ray"] != "abc-NRT" || tags["status"] != "500" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
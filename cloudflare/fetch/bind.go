@@ -23,7 +23,7 @@ func fetch(namespace js.Value, req *http.Request, init *RequestInit) (*http.Resp
 		// Docs: https://developers.cloudflare.com/workers/runtime-apis/request#requestinit
 		init.ToJS(),
 	)
-	jsRes, err := jsutil.AwaitPromise(promise)
+	jsRes, err := jsutil.AwaitPromiseContext(req.Context(), promise)
 	if err != nil {
 		return nil, err
 	}
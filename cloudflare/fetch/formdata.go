@@ -0,0 +1,79 @@
+package fetch
+
+import (
+	"context"
+	"io"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// FormData represents FormData of the Fetch API, which builds multipart/form-data bodies natively on JavaScript side.
+//   - https://developer.mozilla.org/docs/Web/API/FormData
+type FormData struct {
+	instance js.Value
+}
+
+var formDataClass = jsutil.LazyGlobal("FormData")
+
+// NewFormData returns an empty FormData.
+func NewFormData() *FormData {
+	return &FormData{instance: formDataClass().New()}
+}
+
+// Append appends the string field. Existing fields with the same name are kept.
+func (f *FormData) Append(name, value string) {
+	f.instance.Call("append", name, value)
+}
+
+// Set sets the string field. Existing fields with the same name are replaced.
+func (f *FormData) Set(name, value string) {
+	f.instance.Call("set", name, value)
+}
+
+// Delete deletes all fields with the name.
+func (f *FormData) Delete(name string) {
+	f.instance.Call("delete", name)
+}
+
+// AppendFile appends the file part with the filename and the content type read from r.
+//   - The content of r is streamed into a Blob on JavaScript side. if r implements io.Closer, r is closed after reading.
+//   - if contentType is empty, `application/octet-stream` is used.
+func (f *FormData) AppendFile(name, filename, contentType string, r io.Reader) error {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	rc, ok := r.(io.ReadCloser)
+	if !ok {
+		rc = io.NopCloser(r)
+	}
+	init := jsutil.NewObject()
+	headers := jsutil.NewObject()
+	headers.Set("Content-Type", contentType)
+	init.Set("headers", headers)
+	res := jsutil.ResponseClass().New(jsutil.ConvertReaderToReadableStream(rc), init)
+	blob, err := jsutil.AwaitPromise(res.Call("blob"))
+	if err != nil {
+		return err
+	}
+	f.instance.Call("append", name, blob, filename)
+	return nil
+}
+
+// Encode encodes the form as multipart/form-data, and returns the body and its Content-Type (including the boundary).
+func (f *FormData) Encode() (body io.ReadCloser, contentType string) {
+	res := jsutil.ResponseClass().New(f.instance)
+	return jshttp.ToBody(res.Get("body")), res.Get("headers").Call("get", "Content-Type").String()
+}
+
+// NewFormRequest returns new Request which sends the form as its body.
+func NewFormRequest(ctx context.Context, method string, url string, form *FormData) (*Request, error) {
+	body, contentType := form.Encode()
+	req, err := NewRequest(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
@@ -0,0 +1,62 @@
+package fetch
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestFormDataEncode(t *testing.T) {
+	form := NewFormData()
+	form.Append("name", "gopher")
+	form.Append("tag", "a")
+	form.Append("tag", "b")
+	form.Set("name", "workers")
+	if err := form.AppendFile("file", "hello.txt", "text/plain", strings.NewReader("hello, world")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, contentType := form.Encode()
+	defer body.Close()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mediaType != "multipart/form-data" {
+		t.Fatalf("want multipart/form-data, got %s", mediaType)
+	}
+	mr := multipart.NewReader(body, params["boundary"])
+	type part struct {
+		name, filename, contentType, data string
+	}
+	var got []part
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, part{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), string(data)})
+	}
+	want := []part{
+		{"name", "", "", "workers"},
+		{"tag", "", "", "a"},
+		{"tag", "", "", "b"},
+		{"file", "hello.txt", "text/plain", "hello, world"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d parts, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("part %d: want %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
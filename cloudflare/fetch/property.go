@@ -38,10 +38,28 @@ func (init *RequestInit) ToJS() js.Value {
 	if init.Redirect.IsValid() {
 		obj.Set("redirect", init.Redirect.String())
 	}
+	if init.CF != nil {
+		obj.Set("cf", init.CF.ToJS())
+	}
 	return obj
 }
 
 // RequestInitCF represents the Cloudflare-specific options passed to a fetch() request.
+//   - https://developers.cloudflare.com/workers/runtime-apis/request/#the-cf-property-requestinitcfproperties
 type RequestInitCF struct {
-	/* TODO: implement */
+	// CacheKey is the cache key of the request used by Cloudflare's cache instead of the URL.
+	// `cache.KeyBuilder` can be used to build normalized cache keys.
+	CacheKey string
+}
+
+// ToJS converts RequestInitCF to JS object.
+func (cf *RequestInitCF) ToJS() js.Value {
+	if cf == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if cf.CacheKey != "" {
+		obj.Set("cacheKey", cf.CacheKey)
+	}
+	return obj
 }
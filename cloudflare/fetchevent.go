@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package cloudflare
 
 import (
@@ -13,14 +15,12 @@ import (
 // see: https://developers.cloudflare.com/workers/runtime-apis/fetch-event/#waituntil
 func WaitUntil(ctx context.Context, task func()) {
 	exCtx := cfruntimecontext.GetExecutionContext(ctx)
-	exCtx.Call("waitUntil", jsutil.NewPromise(js.FuncOf(func(this js.Value, pArgs []js.Value) any {
-		resolve := pArgs[0]
-		go func() {
-			task()
-			resolve.Invoke(js.Undefined())
-		}()
-		return js.Undefined()
-	})))
+	promise, resolve, _ := jsutil.NewPromiseWithResolvers()
+	go func() {
+		task()
+		resolve.Invoke(js.Undefined())
+	}()
+	exCtx.Call("waitUntil", promise)
 }
 
 // PassThroughOnException prevents a runtime error response when the Worker script throws an unhandled exception.
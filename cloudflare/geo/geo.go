@@ -0,0 +1,184 @@
+// Package geo makes routing decisions from the location of clients given by the `cf` property of requests.
+//   - Nearest selects the origin nearest to the client, and Table looks up the configuration of the region of the client.
+//   - Middleware sets the location of the client to headers of the request, so origins receive it when the request is proxied.
+//   - The location is obtained by cloudflare.GetIncomingRequestCF. Use cloudflare.NewIncomingRequestCFContext to test handlers.
+package geo
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+// earthRadius is the mean radius of the Earth in kilometers.
+const earthRadius = 6371.0
+
+// Point represents the coordinates in degrees.
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// PointOf returns the coordinates of the client. ok is false if cf is nil or doesn't have the coordinates.
+func PointOf(cf *cloudflare.IncomingRequestCF) (p Point, ok bool) {
+	if cf == nil {
+		return Point{}, false
+	}
+	lat, lon, ok := cf.Coordinates()
+	return Point{Lat: lat, Lon: lon}, ok
+}
+
+// Distance returns the great-circle distance between the points in kilometers.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Origin represents the location of the origin server.
+type Origin struct {
+	// URL is the URL of the origin, e.g. `https://us-east.example.com`.
+	URL string
+	// Point is the coordinates of the origin.
+	Point Point
+	// Continent is the continent code of the origin, which is used when the coordinates of the client are not given.
+	Continent string
+}
+
+// Nearest returns the origin nearest to the client.
+//   - if the coordinates of the client are not given, returns the first origin of the continent of the client,
+//     or the first origin if no origin is in the continent.
+//   - ok is false if origins is empty.
+func Nearest(cf *cloudflare.IncomingRequestCF, origins []Origin) (origin Origin, ok bool) {
+	if len(origins) == 0 {
+		return Origin{}, false
+	}
+	if p, ok := PointOf(cf); ok {
+		nearest, min := 0, math.Inf(1)
+		for i, o := range origins {
+			if d := Distance(p, o.Point); d < min {
+				nearest, min = i, d
+			}
+		}
+		return origins[nearest], true
+	}
+	if cf != nil && cf.Continent != "" {
+		for _, o := range origins {
+			if o.Continent == cf.Continent {
+				return o, true
+			}
+		}
+	}
+	return origins[0], true
+}
+
+// Table holds values of configurations for regions. Keys of maps are codes given by the `cf` property.
+//   - Lookup returns the value of the most specific region: Colos, Regions, Countries, Continents, then Default.
+type Table[T any] struct {
+	// Default is the value for clients whose regions are not in the table.
+	Default T
+	// Colos are values by IATA codes of data centers, e.g. `NRT`.
+	Colos map[string]T
+	// Regions are values by ISO 3166-2 codes of regions, e.g. `US-CA`.
+	Regions map[string]T
+	// Countries are values by ISO 3166-1 Alpha 2 codes of countries, e.g. `JP`.
+	Countries map[string]T
+	// Continents are values by continent codes, e.g. `EU`.
+	Continents map[string]T
+}
+
+// Lookup returns the value for the client. if cf is nil, returns Default.
+func (t *Table[T]) Lookup(cf *cloudflare.IncomingRequestCF) T {
+	if cf == nil {
+		return t.Default
+	}
+	if v, ok := lookup(t.Colos, cf.Colo); ok {
+		return v
+	}
+	if cf.Country != "" && cf.RegionCode != "" {
+		if v, ok := lookup(t.Regions, cf.Country+"-"+cf.RegionCode); ok {
+			return v
+		}
+	}
+	if v, ok := lookup(t.Countries, cf.Country); ok {
+		return v
+	}
+	if v, ok := lookup(t.Continents, cf.Continent); ok {
+		return v
+	}
+	return t.Default
+}
+
+func lookup[T any](m map[string]T, key string) (T, bool) {
+	if key == "" {
+		var zero T
+		return zero, false
+	}
+	v, ok := m[key]
+	return v, ok
+}
+
+// Names of headers set by Middleware, which are the same as the visitor location headers of Cloudflare's Managed Transforms.
+//   - https://developers.cloudflare.com/rules/transform/managed-transforms/reference/#add-visitor-location-headers
+const (
+	HeaderCity       = "Cf-Ipcity"
+	HeaderCountry    = "Cf-Ipcountry"
+	HeaderContinent  = "Cf-Ipcontinent"
+	HeaderLatitude   = "Cf-Iplatitude"
+	HeaderLongitude  = "Cf-Iplongitude"
+	HeaderRegion     = "Cf-Region"
+	HeaderRegionCode = "Cf-Region-Code"
+	HeaderMetroCode  = "Cf-Metro-Code"
+	HeaderPostalCode = "Cf-Postal-Code"
+	HeaderTimezone   = "Cf-Timezone"
+)
+
+// Middleware sets the location of the client to the headers of the request, so the origin receives it when the
+// request is proxied (e.g. by http.DefaultTransport.RoundTrip(req)).
+//   - Headers given by the client are removed, so they can't be spoofed. Headers of unknown values are not set.
+//   - Values with non-ASCII characters (e.g. names of cities) are percent-encoded, since header values must be ASCII.
+//   - if the request doesn't have the `cf` property, headers are only removed.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cf, ok := cloudflare.GetIncomingRequestCF(req.Context())
+		if !ok {
+			cf = &cloudflare.IncomingRequestCF{}
+		}
+		for _, h := range []struct {
+			name  string
+			value string
+		}{
+			{HeaderCity, cf.City},
+			{HeaderCountry, cf.Country},
+			{HeaderContinent, cf.Continent},
+			{HeaderLatitude, cf.Latitude},
+			{HeaderLongitude, cf.Longitude},
+			{HeaderRegion, cf.Region},
+			{HeaderRegionCode, cf.RegionCode},
+			{HeaderMetroCode, cf.MetroCode},
+			{HeaderPostalCode, cf.PostalCode},
+			{HeaderTimezone, cf.Timezone},
+		} {
+			req.Header.Del(h.name)
+			if h.value != "" {
+				req.Header.Set(h.name, headerValue(h.value))
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// headerValue percent-encodes the value if it has non-ASCII characters.
+func headerValue(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return url.PathEscape(s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+package geo
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare"
+)
+
+var (
+	tokyo     = Point{Lat: 35.6895, Lon: 139.6917}
+	london    = Point{Lat: 51.5074, Lon: -0.1278}
+	newYork   = Point{Lat: 40.7128, Lon: -74.0060}
+	sanJose   = Point{Lat: 37.3382, Lon: -121.8863}
+	frankfurt = Point{Lat: 50.1109, Lon: 8.6821}
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b Point
+		want float64
+	}{
+		{tokyo, tokyo, 0},
+		{london, newYork, 5570},
+		{tokyo, sanJose, 8337},
+		{Point{Lat: 0, Lon: 0}, Point{Lat: 0, Lon: 180}, math.Pi * earthRadius},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); math.Abs(got-tt.want) > 20 {
+			t.Errorf("Distance(%v, %v) = %f, want about %f", tt.a, tt.b, got, tt.want)
+		}
+		if got, rev := Distance(tt.a, tt.b), Distance(tt.b, tt.a); math.Abs(got-rev) > 1e-9 {
+			t.Errorf("Distance must be symmetric: %f, %f", got, rev)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	origins := []Origin{
+		{URL: "https://us.example.com", Point: sanJose, Continent: "NA"},
+		{URL: "https://eu.example.com", Point: frankfurt, Continent: "EU"},
+		{URL: "https://ap.example.com", Point: tokyo, Continent: "AS"},
+	}
+	tests := []struct {
+		name string
+		cf   *cloudflare.IncomingRequestCF
+		want string
+	}{
+		{"coordinates", &cloudflare.IncomingRequestCF{Latitude: "51.5074", Longitude: "-0.1278", Continent: "NA"}, "https://eu.example.com"},
+		{"coordinates near the origin", &cloudflare.IncomingRequestCF{Latitude: "37.5665", Longitude: "126.9780"}, "https://ap.example.com"},
+		{"continent", &cloudflare.IncomingRequestCF{Continent: "AS"}, "https://ap.example.com"},
+		{"malformed coordinates", &cloudflare.IncomingRequestCF{Latitude: "x", Longitude: "0", Continent: "EU"}, "https://eu.example.com"},
+		{"unknown continent", &cloudflare.IncomingRequestCF{Continent: "AF"}, "https://us.example.com"},
+		{"no cf", nil, "https://us.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Nearest(tt.cf, origins)
+			if !ok || got.URL != tt.want {
+				t.Errorf("Nearest: %v %v, want %s", got.URL, ok, tt.want)
+			}
+		})
+	}
+	if _, ok := Nearest(&cloudflare.IncomingRequestCF{}, nil); ok {
+		t.Error("Nearest of no origins must be false")
+	}
+}
+
+func TestTable(t *testing.T) {
+	table := &Table[string]{
+		Default:    "default",
+		Colos:      map[string]string{"NRT": "colo"},
+		Regions:    map[string]string{"US-CA": "region"},
+		Countries:  map[string]string{"US": "country", "": "empty"},
+		Continents: map[string]string{"EU": "continent"},
+	}
+	tests := []struct {
+		name string
+		cf   *cloudflare.IncomingRequestCF
+		want string
+	}{
+		{"colo", &cloudflare.IncomingRequestCF{Colo: "NRT", Country: "US", RegionCode: "CA"}, "colo"},
+		{"region", &cloudflare.IncomingRequestCF{Colo: "SJC", Country: "US", RegionCode: "CA"}, "region"},
+		{"country", &cloudflare.IncomingRequestCF{Country: "US", RegionCode: "TX"}, "country"},
+		{"region code of other country", &cloudflare.IncomingRequestCF{Country: "CA", RegionCode: "CA", Continent: "NA"}, "default"},
+		{"continent", &cloudflare.IncomingRequestCF{Country: "FR", Continent: "EU"}, "continent"},
+		{"empty", &cloudflare.IncomingRequestCF{}, "default"},
+		{"nil", nil, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := table.Lookup(tt.cf); got != tt.want {
+				t.Errorf("Lookup: %q, want %q", got, tt.want)
+			}
+		})
+	}
+	var empty Table[int]
+	if got := empty.Lookup(&cloudflare.IncomingRequestCF{Country: "US"}); got != 0 {
+		t.Errorf("Lookup of empty table: %d", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got http.Header
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Clone()
+	}))
+
+	cf := &cloudflare.IncomingRequestCF{
+		City:       "São Paulo",
+		Country:    "BR",
+		Continent:  "SA",
+		Latitude:   "-23.5475",
+		Longitude:  "-46.63611",
+		Region:     "São Paulo",
+		RegionCode: "SP",
+		Timezone:   "America/Sao_Paulo",
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	req.Header.Set(HeaderCountry, "US")
+	req.Header.Set(HeaderPostalCode, "spoofed")
+	handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(cloudflare.NewIncomingRequestCFContext(context.Background(), cf)))
+	want := map[string]string{
+		HeaderCity:       "S%C3%A3o%20Paulo",
+		HeaderCountry:    "BR",
+		HeaderContinent:  "SA",
+		HeaderLatitude:   "-23.5475",
+		HeaderLongitude:  "-46.63611",
+		HeaderRegion:     "S%C3%A3o%20Paulo",
+		HeaderRegionCode: "SP",
+		HeaderTimezone:   "America/Sao_Paulo",
+		HeaderPostalCode: "",
+		HeaderMetroCode:  "",
+	}
+	for name, value := range want {
+		if v := got.Get(name); v != value {
+			t.Errorf("%s: %q, want %q", name, v, value)
+		}
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+	req.Header.Set(HeaderCountry, "US")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	if v := got.Get(HeaderCountry); v != "" {
+		t.Errorf("header given by the client must be removed: %q", v)
+	}
+}
@@ -0,0 +1,242 @@
+package htmlrewriter
+
+import (
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ContentOptions represents the options of content insertions.
+type ContentOptions struct {
+	// HTML inserts the content as raw HTML. Otherwise the content is escaped as text.
+	HTML bool
+}
+
+func (opts *ContentOptions) toJS() js.Value {
+	obj := jsutil.NewObject()
+	if opts != nil && opts.HTML {
+		obj.Set("html", true)
+	}
+	return obj
+}
+
+// content provides the mutations shared by elements, text chunks and comments.
+type content struct {
+	instance js.Value
+}
+
+// Before inserts the content before the node.
+func (c *content) Before(content string, opts *ContentOptions) {
+	c.instance.Call("before", content, opts.toJS())
+}
+
+// After inserts the content after the node.
+func (c *content) After(content string, opts *ContentOptions) {
+	c.instance.Call("after", content, opts.toJS())
+}
+
+// Replace replaces the node with the content.
+func (c *content) Replace(content string, opts *ContentOptions) {
+	c.instance.Call("replace", content, opts.toJS())
+}
+
+// Remove removes the node.
+func (c *content) Remove() {
+	c.instance.Call("remove")
+}
+
+// Removed reports whether the node has been removed or replaced.
+func (c *content) Removed() bool {
+	return c.instance.Get("removed").Bool()
+}
+
+// Attribute represents an attribute of an element.
+type Attribute struct {
+	Name  string
+	Value string
+}
+
+// Element represents an HTML element matched by the selector.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#element
+type Element struct {
+	content
+	t *transformation
+}
+
+// TagName returns the lowercase name of the tag.
+func (e *Element) TagName() string {
+	return e.instance.Get("tagName").String()
+}
+
+// SetTagName changes the name of the tag.
+func (e *Element) SetTagName(name string) {
+	e.instance.Set("tagName", name)
+}
+
+// NamespaceURI returns the namespace URI of the element.
+func (e *Element) NamespaceURI() string {
+	return e.instance.Get("namespaceURI").String()
+}
+
+// Attributes returns the attributes of the element in the order of the source.
+func (e *Element) Attributes() []*Attribute {
+	arr := jsutil.ArrayFrom(e.instance.Get("attributes"))
+	attrs := make([]*Attribute, arr.Length())
+	for i := range attrs {
+		entry := arr.Index(i)
+		attrs[i] = &Attribute{
+			Name:  entry.Index(0).String(),
+			Value: entry.Index(1).String(),
+		}
+	}
+	return attrs
+}
+
+// GetAttribute returns the value of the attribute. if the attribute doesn't exist, ok is false.
+func (e *Element) GetAttribute(name string) (value string, ok bool) {
+	v := e.instance.Call("getAttribute", name)
+	if v.IsNull() {
+		return "", false
+	}
+	return v.String(), true
+}
+
+// HasAttribute reports whether the element has the attribute.
+func (e *Element) HasAttribute(name string) bool {
+	return e.instance.Call("hasAttribute", name).Bool()
+}
+
+// SetAttribute sets the value of the attribute.
+func (e *Element) SetAttribute(name, value string) {
+	e.instance.Call("setAttribute", name, value)
+}
+
+// RemoveAttribute removes the attribute.
+func (e *Element) RemoveAttribute(name string) {
+	e.instance.Call("removeAttribute", name)
+}
+
+// Prepend inserts the content right after the start tag of the element.
+func (e *Element) Prepend(content string, opts *ContentOptions) {
+	e.instance.Call("prepend", content, opts.toJS())
+}
+
+// Append inserts the content right before the end tag of the element.
+func (e *Element) Append(content string, opts *ContentOptions) {
+	e.instance.Call("append", content, opts.toJS())
+}
+
+// SetInnerContent replaces the content of the element with the content.
+func (e *Element) SetInnerContent(content string, opts *ContentOptions) {
+	e.instance.Call("setInnerContent", content, opts.toJS())
+}
+
+// RemoveAndKeepContent removes the element, but keeps its content.
+func (e *Element) RemoveAndKeepContent() {
+	e.instance.Call("removeAndKeepContent")
+}
+
+// OnEndTag registers the handler called on the end tag of the element.
+func (e *Element) OnEndTag(handler func(tag *EndTag) error) {
+	e.instance.Call("onEndTag", e.t.wrap(func(v js.Value) error {
+		return handler(&EndTag{instance: v})
+	}))
+}
+
+// EndTag represents the end tag of an element.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#endtag
+type EndTag struct {
+	instance js.Value
+}
+
+// Name returns the name of the tag.
+func (e *EndTag) Name() string {
+	return e.instance.Get("name").String()
+}
+
+// SetName changes the name of the tag.
+func (e *EndTag) SetName(name string) {
+	e.instance.Set("name", name)
+}
+
+// Before inserts the content before the end tag.
+func (e *EndTag) Before(content string, opts *ContentOptions) {
+	e.instance.Call("before", content, opts.toJS())
+}
+
+// After inserts the content after the end tag.
+func (e *EndTag) After(content string, opts *ContentOptions) {
+	e.instance.Call("after", content, opts.toJS())
+}
+
+// Remove removes the end tag.
+func (e *EndTag) Remove() {
+	e.instance.Call("remove")
+}
+
+// Text represents a chunk of text. A text node can be split into multiple chunks.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#text-chunks
+type Text struct {
+	content
+}
+
+// Text returns the content of the chunk.
+func (t *Text) Text() string {
+	return t.instance.Get("text").String()
+}
+
+// LastInTextNode reports whether the chunk is the last chunk of the text node.
+func (t *Text) LastInTextNode() bool {
+	return t.instance.Get("lastInTextNode").Bool()
+}
+
+// Comment represents an HTML comment.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#comments
+type Comment struct {
+	content
+}
+
+// Text returns the content of the comment.
+func (c *Comment) Text() string {
+	return c.instance.Get("text").String()
+}
+
+// SetText changes the content of the comment.
+func (c *Comment) SetText(text string) {
+	c.instance.Set("text", text)
+}
+
+// Doctype represents the doctype of the document.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#doctype
+type Doctype struct {
+	Name     string
+	PublicID string
+	SystemID string
+}
+
+func newDoctype(v js.Value) *Doctype {
+	return &Doctype{
+		Name:     maybeString(v.Get("name")),
+		PublicID: maybeString(v.Get("publicId")),
+		SystemID: maybeString(v.Get("systemId")),
+	}
+}
+
+// maybeString returns the string value, or empty string if the value is null or undefined.
+func maybeString(v js.Value) string {
+	if v.IsNull() || v.IsUndefined() {
+		return ""
+	}
+	return v.String()
+}
+
+// DocumentEnd represents the end of the document.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#end
+type DocumentEnd struct {
+	instance js.Value
+}
+
+// Append inserts the content at the end of the document.
+func (d *DocumentEnd) Append(content string, opts *ContentOptions) {
+	d.instance.Call("append", content, opts.toJS())
+}
@@ -0,0 +1,82 @@
+package htmlrewriter
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+)
+
+// Middleware returns the middleware which transforms HTML responses of the handler by the Rewriter.
+//   - Only responses with the `text/html` Content-Type are transformed. Other responses are passed through.
+//   - The response is streamed from the handler to the client through the Rewriter.
+func Middleware(r *Rewriter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			pr, pw := io.Pipe()
+			rec := &pipeResponseWriter{header: http.Header{}, writer: pw, ready: make(chan struct{})}
+			go func() {
+				defer pw.Close()
+				// WriteHeader is called for handlers which write nothing.
+				defer rec.WriteHeader(http.StatusOK)
+				next.ServeHTTP(rec, req)
+			}()
+			<-rec.ready
+			res := &http.Response{
+				StatusCode: rec.statusCode,
+				Header:     rec.writtenHeader,
+				Body:       pr,
+			}
+			if isHTML(res.Header) {
+				transformed, err := r.Transform(res)
+				if err != nil {
+					pr.CloseWithError(err)
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				res = transformed
+			}
+			defer res.Body.Close()
+			for key, values := range res.Header {
+				for _, value := range values {
+					w.Header().Add(key, value)
+				}
+			}
+			w.WriteHeader(res.StatusCode)
+			io.Copy(w, res.Body)
+		})
+	}
+}
+
+func isHTML(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && mediaType == "text/html"
+}
+
+// pipeResponseWriter is http.ResponseWriter which writes the body to the pipe.
+type pipeResponseWriter struct {
+	header        http.Header
+	writtenHeader http.Header
+	statusCode    int
+	writer        *io.PipeWriter
+	ready         chan struct{}
+	once          sync.Once
+}
+
+func (w *pipeResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the status code and the header. Only the first call has effect.
+func (w *pipeResponseWriter) WriteHeader(statusCode int) {
+	w.once.Do(func() {
+		w.statusCode = statusCode
+		w.writtenHeader = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *pipeResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.writer.Write(data)
+}
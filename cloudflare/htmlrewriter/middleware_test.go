@@ -0,0 +1,34 @@
+package htmlrewriter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestIsHTML(t *testing.T) {
+	tests := map[string]struct {
+		contentType string
+		want        bool
+	}{
+		"html":             {contentType: "text/html", want: true},
+		"html with params": {contentType: "text/html; charset=utf-8", want: true},
+		"uppercase":        {contentType: "Text/HTML", want: true},
+		"json":             {contentType: "application/json"},
+		"xhtml":            {contentType: "application/xhtml+xml"},
+		"empty":            {},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			header := http.Header{}
+			if tc.contentType != "" {
+				header.Set("Content-Type", tc.contentType)
+			}
+			if got := isHTML(header); got != tc.want {
+				t.Errorf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
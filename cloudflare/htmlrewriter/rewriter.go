@@ -0,0 +1,206 @@
+// Package htmlrewriter provides HTMLRewriter, which transforms streaming HTML responses.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/
+package htmlrewriter
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ErrSyncCallbackNotSupported is returned when the worker entry point doesn't support synchronous callbacks.
+var ErrSyncCallbackNotSupported = errors.New("htmlrewriter: synchronous callback is not supported by the worker entry point")
+
+// ElementHandlers represents the handlers of elements matched by the selector, and their contents.
+//   - Handlers are called on the JavaScript event loop, so they must not call asynchronous APIs (it causes deadlock).
+//   - if a handler returns an error, the transformation fails with the error.
+type ElementHandlers struct {
+	Element  func(el *Element) error
+	Comments func(c *Comment) error
+	Text     func(t *Text) error
+}
+
+// DocumentHandlers represents the handlers of the whole document.
+//   - Handlers have the same restrictions as ElementHandlers.
+type DocumentHandlers struct {
+	Doctype  func(d *Doctype) error
+	Comments func(c *Comment) error
+	Text     func(t *Text) error
+	End      func(end *DocumentEnd) error
+}
+
+type selectorHandlers struct {
+	selector string
+	handlers *ElementHandlers
+}
+
+// Rewriter transforms HTML by the registered handlers.
+//   - Rewriter can be reused for multiple responses.
+type Rewriter struct {
+	elements  []*selectorHandlers
+	documents []*DocumentHandlers
+}
+
+// New returns an empty Rewriter.
+func New() *Rewriter {
+	return &Rewriter{}
+}
+
+// On registers the handlers for elements matched by the CSS selector.
+//   - https://developers.cloudflare.com/workers/runtime-apis/html-rewriter/#selectors
+func (r *Rewriter) On(selector string, handlers *ElementHandlers) *Rewriter {
+	r.elements = append(r.elements, &selectorHandlers{selector: selector, handlers: handlers})
+	return r
+}
+
+// OnDocument registers the handlers for the whole document.
+func (r *Rewriter) OnDocument(handlers *DocumentHandlers) *Rewriter {
+	r.documents = append(r.documents, handlers)
+	return r
+}
+
+// transformation holds the callbacks of a transformation of a response.
+type transformation struct {
+	throwOnError js.Value
+	funcs        []js.Func
+}
+
+// wrap converts the handler to JavaScript function which throws the error returned by the handler.
+func (t *transformation) wrap(handler func(v js.Value) error) js.Value {
+	f := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		var handlerErr error
+		if err := jsutil.Try(func() {
+			handlerErr = handler(args[0])
+		}); err != nil {
+			handlerErr = err
+		}
+		if handlerErr != nil {
+			return jsutil.ErrorClass().New(handlerErr.Error())
+		}
+		return js.Undefined()
+	})
+	t.funcs = append(t.funcs, f)
+	return t.throwOnError.Invoke(f)
+}
+
+func (t *transformation) release() {
+	for _, f := range t.funcs {
+		f.Release()
+	}
+	t.funcs = nil
+}
+
+var htmlRewriterClass = jsutil.LazyGlobal("HTMLRewriter")
+
+// newJS creates JavaScript side's HTMLRewriter with the handlers.
+func (r *Rewriter) newJS(t *transformation) js.Value {
+	rw := htmlRewriterClass().New()
+	for _, e := range r.elements {
+		h := e.handlers
+		obj := jsutil.NewObject()
+		if h.Element != nil {
+			obj.Set("element", t.wrap(func(v js.Value) error {
+				return h.Element(&Element{content: content{instance: v}, t: t})
+			}))
+		}
+		if h.Comments != nil {
+			obj.Set("comments", t.wrap(func(v js.Value) error {
+				return h.Comments(&Comment{content{instance: v}})
+			}))
+		}
+		if h.Text != nil {
+			obj.Set("text", t.wrap(func(v js.Value) error {
+				return h.Text(&Text{content{instance: v}})
+			}))
+		}
+		rw = rw.Call("on", e.selector, obj)
+	}
+	for _, h := range r.documents {
+		h := h
+		obj := jsutil.NewObject()
+		if h.Doctype != nil {
+			obj.Set("doctype", t.wrap(func(v js.Value) error {
+				return h.Doctype(newDoctype(v))
+			}))
+		}
+		if h.Comments != nil {
+			obj.Set("comments", t.wrap(func(v js.Value) error {
+				return h.Comments(&Comment{content{instance: v}})
+			}))
+		}
+		if h.Text != nil {
+			obj.Set("text", t.wrap(func(v js.Value) error {
+				return h.Text(&Text{content{instance: v}})
+			}))
+		}
+		if h.End != nil {
+			obj.Set("end", t.wrap(func(v js.Value) error {
+				return h.End(&DocumentEnd{instance: v})
+			}))
+		}
+		rw = rw.Call("onDocument", obj)
+	}
+	return rw
+}
+
+// Transform returns the response whose body is transformed by the handlers.
+//   - The body is transformed while it is read, without buffering the whole body.
+//   - The handlers are called while the body of the returned response is read.
+func (r *Rewriter) Transform(res *http.Response) (*http.Response, error) {
+	throwOnError := jsutil.Global.Get("throwOnError")
+	if throwOnError.Type() != js.TypeFunction {
+		return nil, ErrSyncCallbackNotSupported
+	}
+	if res.Body == nil {
+		res.Body = http.NoBody
+	}
+	t := &transformation{throwOnError: throwOnError}
+	out, err := jsutil.TryCall(r.newJS(t), "transform", jshttp.ToJSResponse(res))
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+	status := out.Get("status").Int()
+	header := jshttp.ToHeader(out.Get("headers"))
+	header.Del("Content-Length")
+	var body io.ReadCloser = http.NoBody
+	if b := out.Get("body"); !b.IsNull() {
+		body = &releasingReader{
+			Reader: jsutil.ConvertReadableStreamToReader(b),
+			t:      t,
+		}
+	} else {
+		t.release()
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Header:        header,
+		Body:          body,
+		ContentLength: -1,
+	}, nil
+}
+
+// releasingReader releases the callbacks of the transformation when the body is read to the end.
+//   - The callbacks are not released when the body is closed before the end, since JavaScript side may still call them.
+type releasingReader struct {
+	io.Reader
+	t *transformation
+}
+
+func (r *releasingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.t.release()
+	}
+	return n, err
+}
+
+func (r *releasingReader) Close() error {
+	return nil
+}
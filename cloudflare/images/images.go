@@ -0,0 +1,304 @@
+// Package images provides the binding of Cloudflare Images, which transforms images in workers.
+//   - https://developers.cloudflare.com/images/transform-images/bindings/
+package images
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Images represents the binding of Cloudflare Images.
+type Images struct {
+	instance js.Value
+}
+
+// NewImages returns Images for given variable name.
+//   - variable name must be defined in wrangler.toml as images's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewImages(ctx context.Context, varName string) (*Images, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Images{instance: inst}, nil
+}
+
+// Info represents the information of an image.
+type Info struct {
+	// Format is the media type of the image, e.g. `image/png`.
+	Format string
+	// FileSize is the size of the image in bytes. FileSize is 0 for SVG images.
+	FileSize int
+	// Width and Height are the size of the image in pixels. They are 0 for SVG images.
+	Width  int
+	Height int
+}
+
+// Info returns the information of the image read from r.
+func (i *Images) Info(r io.Reader) (*Info, error) {
+	promise, err := jsutil.TryCall(i.instance, "info", toReadableStream(r))
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	info := &Info{Format: v.Get("format").String()}
+	if fileSize := v.Get("fileSize"); fileSize.Type() == js.TypeNumber {
+		info.FileSize = fileSize.Int()
+	}
+	if width := v.Get("width"); width.Type() == js.TypeNumber {
+		info.Width = width.Int()
+	}
+	if height := v.Get("height"); height.Type() == js.TypeNumber {
+		info.Height = height.Int()
+	}
+	return info, nil
+}
+
+// Input returns Transformer of the image read from r.
+//   - The image is read when Output is called.
+func (i *Images) Input(r io.Reader) *Transformer {
+	return &Transformer{images: i, input: r}
+}
+
+func toReadableStream(r io.Reader) js.Value {
+	rc, ok := r.(io.ReadCloser)
+	if !ok {
+		rc = io.NopCloser(r)
+	}
+	return jsutil.ConvertReaderToReadableStream(rc)
+}
+
+// Fit represents how the image is resized to fit Width and Height.
+//   - https://developers.cloudflare.com/images/transform-images/transform-via-workers/#fit
+type Fit string
+
+const (
+	FitScaleDown Fit = "scale-down"
+	FitContain   Fit = "contain"
+	FitCover     Fit = "cover"
+	FitCrop      Fit = "crop"
+	FitPad       Fit = "pad"
+)
+
+// TransformOptions represents the options of Transform.
+//   - zero values are not sent, so the image is not changed by them.
+type TransformOptions struct {
+	// Width and Height are the maximum size of the output in pixels.
+	Width  int
+	Height int
+	Fit    Fit
+	// Gravity is the point to keep when cropping, e.g. `auto`, `left` or `0.5x0.5`.
+	Gravity string
+	// Rotate rotates the image clockwise by 90, 180 or 270 degrees.
+	Rotate int
+	// Blur is the radius of the blur in the range [1, 250].
+	Blur float64
+	// Brightness, Contrast and Gamma are factors of the adjustments, where 1 means no change.
+	Brightness float64
+	Contrast   float64
+	Gamma      float64
+	// Sharpen is the strength of sharpening in the range [0, 10].
+	Sharpen float64
+	// Background is the background color of padding and transparent images, in CSS format.
+	Background string
+}
+
+func (opts *TransformOptions) toJS() js.Value {
+	obj := jsutil.NewObject()
+	if opts.Width > 0 {
+		obj.Set("width", opts.Width)
+	}
+	if opts.Height > 0 {
+		obj.Set("height", opts.Height)
+	}
+	if opts.Fit != "" {
+		obj.Set("fit", string(opts.Fit))
+	}
+	if opts.Gravity != "" {
+		obj.Set("gravity", opts.Gravity)
+	}
+	if opts.Rotate != 0 {
+		obj.Set("rotate", opts.Rotate)
+	}
+	if opts.Blur > 0 {
+		obj.Set("blur", opts.Blur)
+	}
+	if opts.Brightness > 0 {
+		obj.Set("brightness", opts.Brightness)
+	}
+	if opts.Contrast > 0 {
+		obj.Set("contrast", opts.Contrast)
+	}
+	if opts.Gamma > 0 {
+		obj.Set("gamma", opts.Gamma)
+	}
+	if opts.Sharpen > 0 {
+		obj.Set("sharpen", opts.Sharpen)
+	}
+	if opts.Background != "" {
+		obj.Set("background", opts.Background)
+	}
+	return obj
+}
+
+// DrawOptions represents the options of Draw.
+//   - The overlay is placed at the center if no position is given.
+type DrawOptions struct {
+	// Opacity is the opacity of the overlay in the range [0, 1]. The default value is 1.
+	Opacity float64
+	// Repeat repeats the overlay: `true` for both directions, `x` or `y` for one direction.
+	Repeat string
+	// Top, Left, Bottom and Right are the offsets of the overlay in pixels.
+	Top    *int
+	Left   *int
+	Bottom *int
+	Right  *int
+}
+
+func (opts *DrawOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.Opacity > 0 {
+		obj.Set("opacity", opts.Opacity)
+	}
+	switch opts.Repeat {
+	case "":
+	case "true":
+		obj.Set("repeat", true)
+	default:
+		obj.Set("repeat", opts.Repeat)
+	}
+	for key, v := range map[string]*int{"top": opts.Top, "left": opts.Left, "bottom": opts.Bottom, "right": opts.Right} {
+		if v != nil {
+			obj.Set(key, *v)
+		}
+	}
+	return obj
+}
+
+// Transformer represents the chain of transformations of an image.
+//   - Transformations are applied in the order of calls.
+type Transformer struct {
+	images *Images
+	input  io.Reader
+	steps  []func(t js.Value) (js.Value, error)
+}
+
+// Transform adds the transformation to the chain.
+func (t *Transformer) Transform(opts *TransformOptions) *Transformer {
+	t.steps = append(t.steps, func(v js.Value) (js.Value, error) {
+		return v.Call("transform", opts.toJS()), nil
+	})
+	return t
+}
+
+// Draw draws the overlay on the image, e.g. for watermarks. The overlay can be transformed before drawing.
+func (t *Transformer) Draw(overlay *Transformer, opts *DrawOptions) *Transformer {
+	t.steps = append(t.steps, func(v js.Value) (js.Value, error) {
+		overlayObj, err := overlay.toJS()
+		if err != nil {
+			return js.Value{}, fmt.Errorf("error building overlay: %w", err)
+		}
+		return v.Call("draw", overlayObj, opts.toJS()), nil
+	})
+	return t
+}
+
+// toJS builds JavaScript side's ImageTransformer.
+func (t *Transformer) toJS() (js.Value, error) {
+	v, err := jsutil.TryCall(t.images.instance, "input", toReadableStream(t.input))
+	for _, step := range t.steps {
+		if err != nil {
+			return js.Value{}, err
+		}
+		var stepErr error
+		err = jsutil.Try(func() {
+			v, stepErr = step(v)
+		})
+		if err == nil {
+			err = stepErr
+		}
+	}
+	if err != nil {
+		return js.Value{}, err
+	}
+	return v, nil
+}
+
+// OutputFormat represents the media type of output images.
+type OutputFormat string
+
+const (
+	OutputFormatAVIF OutputFormat = "image/avif"
+	OutputFormatWebP OutputFormat = "image/webp"
+	OutputFormatJPEG OutputFormat = "image/jpeg"
+	OutputFormatPNG  OutputFormat = "image/png"
+	OutputFormatGIF  OutputFormat = "image/gif"
+)
+
+// OutputOptions represents the options of Output.
+type OutputOptions struct {
+	Format OutputFormat
+	// Quality is the quality of lossy formats in the range [1, 100].
+	Quality int
+}
+
+func (opts *OutputOptions) toJS() js.Value {
+	obj := jsutil.NewObject()
+	obj.Set("format", string(opts.Format))
+	if opts.Quality > 0 {
+		obj.Set("quality", opts.Quality)
+	}
+	return obj
+}
+
+// Output applies the transformations and encodes the image.
+func (t *Transformer) Output(opts *OutputOptions) (*Result, error) {
+	v, err := t.toJS()
+	if err != nil {
+		return nil, err
+	}
+	promise, err := jsutil.TryCall(v, "output", opts.toJS())
+	if err != nil {
+		return nil, err
+	}
+	res, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{instance: res}, nil
+}
+
+// Result represents the output image.
+type Result struct {
+	instance js.Value
+}
+
+// ContentType returns the media type of the image.
+func (r *Result) ContentType() string {
+	return r.instance.Call("contentType").String()
+}
+
+// Image returns the stream of the image. The stream can be read only once.
+func (r *Result) Image() io.Reader {
+	return jsutil.ConvertReadableStreamToReader(r.instance.Call("image"))
+}
+
+// Response returns the image as *http.Response with the Content-Type header.
+//   - The body can be served by copying it to http.ResponseWriter.
+func (r *Result) Response() (*http.Response, error) {
+	return jshttp.ToResponse(r.instance.Call("response"))
+}
@@ -0,0 +1,229 @@
+// Package jsonrpc provides a JSON-RPC 2.0 server over http.Handler.
+//   - Methods are registered to Server, which serves single and batch requests sent by POST.
+//   - Requests of a batch are handled concurrently, so awaits of bindings and fetch overlap.
+//   - Notifications (requests without id) are handled without responses.
+//   - https://www.jsonrpc.org/specification
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Error codes defined by the specification. Codes from -32000 to -32099 are reserved for servers.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// Error is the error object of responses.
+//   - Handlers return *Error to respond with the code. Other errors are responded as CodeInternalError with their messages.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	// Data is additional information about the error. Data is omitted if it's nil.
+	Data any `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: error %d: %s", e.Code, e.Message)
+}
+
+// Handler handles the params of a request, and returns the result. params is nil if the request doesn't have params.
+type Handler func(ctx context.Context, params json.RawMessage) (result any, err error)
+
+// Method returns Handler which decodes params into P by encoding/json.
+//   - if params can't be decoded, the request is responded with CodeInvalidParams.
+func Method[P, R any](fn func(ctx context.Context, params P) (R, error)) Handler {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var params P
+		if raw != nil {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, &Error{Code: CodeInvalidParams, Message: "Invalid params", Data: err.Error()}
+			}
+		}
+		return fn(ctx, params)
+	}
+}
+
+// Server dispatches requests to registered methods.
+type Server struct {
+	// MaxBatchSize is the maximum number of requests in a batch. if MaxBatchSize is 0, the number is not limited.
+	MaxBatchSize int
+	// MaxBodySize is the maximum size of request bodies in bytes. if MaxBodySize is 0, the size is not limited.
+	MaxBodySize int64
+
+	mu      sync.RWMutex
+	methods map[string]Handler
+}
+
+// NewServer returns Server without methods.
+func NewServer() *Server {
+	return &Server{methods: map[string]Handler{}}
+}
+
+// Register registers h to the method. The method registered before is replaced.
+func (s *Server) Register(method string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.methods == nil {
+		s.methods = map[string]Handler{}
+	}
+	s.methods[method] = h
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	// ID is nil for notifications. null ID is kept as `null`.
+	ID *json.RawMessage `json:"id"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+var null = json.RawMessage("null")
+
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	return &response{JSONRPC: "2.0", Error: &Error{Code: code, Message: message}, ID: id}
+}
+
+// ServeHTTP handles the JSON-RPC request of the body.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body := req.Body
+	if s.MaxBodySize > 0 {
+		body = http.MaxBytesReader(w, body, s.MaxBodySize)
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		writeJSON(w, errorResponse(null, CodeParseError, "Parse error"))
+		return
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '[' {
+		s.serveBatch(w, req.Context(), b)
+		return
+	}
+	if res := s.handle(req.Context(), b); res != nil {
+		writeJSON(w, res)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveBatch(w http.ResponseWriter, ctx context.Context, b []byte) {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(b, &raws); err != nil {
+		writeJSON(w, errorResponse(null, CodeParseError, "Parse error"))
+		return
+	}
+	if len(raws) == 0 {
+		writeJSON(w, errorResponse(null, CodeInvalidRequest, "Invalid Request"))
+		return
+	}
+	if s.MaxBatchSize > 0 && len(raws) > s.MaxBatchSize {
+		writeJSON(w, errorResponse(null, CodeInvalidRequest, fmt.Sprintf("Invalid Request: batch exceeds %d requests", s.MaxBatchSize)))
+		return
+	}
+	results := make([]*response, len(raws))
+	var wg sync.WaitGroup
+	for i, raw := range raws {
+		wg.Add(1)
+		go func(i int, raw json.RawMessage) {
+			defer wg.Done()
+			results[i] = s.handle(ctx, raw)
+		}(i, raw)
+	}
+	wg.Wait()
+	responses := make([]*response, 0, len(results))
+	for _, res := range results {
+		if res != nil {
+			responses = append(responses, res)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, responses)
+}
+
+// handle handles a request, and returns the response. if the request is a notification, returns nil.
+func (s *Server) handle(ctx context.Context, raw json.RawMessage) *response {
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return errorResponse(null, CodeParseError, "Parse error")
+		}
+		return errorResponse(null, CodeInvalidRequest, "Invalid Request")
+	}
+	id := null
+	if req.ID != nil {
+		id = *req.ID
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" || !validID(id) {
+		return errorResponse(null, CodeInvalidRequest, "Invalid Request")
+	}
+	s.mu.RLock()
+	h, ok := s.methods[req.Method]
+	s.mu.RUnlock()
+	if !ok {
+		if req.ID == nil {
+			return nil
+		}
+		return errorResponse(id, CodeMethodNotFound, "Method not found")
+	}
+	result, err := h(ctx, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+		return &response{JSONRPC: "2.0", Error: rpcErr, ID: id}
+	}
+	if result == nil {
+		// result is required for successful responses.
+		result = null
+	}
+	return &response{JSONRPC: "2.0", Result: result, ID: id}
+}
+
+// validID reports whether id is a string, a number or null.
+func validID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return false
+	}
+	switch c := id[0]; {
+	case c == '"', c == '-', '0' <= c && c <= '9':
+		return true
+	}
+	return bytes.Equal(id, null)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
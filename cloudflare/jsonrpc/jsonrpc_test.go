@@ -0,0 +1,110 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type addParams struct {
+	A, B int
+}
+
+func newTestServer() *Server {
+	s := NewServer()
+	s.Register("add", Method(func(ctx context.Context, p addParams) (int, error) {
+		return p.A + p.B, nil
+	}))
+	s.Register("fail", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, &Error{Code: -32000, Message: "failed", Data: "detail"}
+	})
+	s.Register("broken", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, errors.New("broken")
+	})
+	s.Register("nothing", func(ctx context.Context, params json.RawMessage) (any, error) {
+		return nil, nil
+	})
+	return s
+}
+
+func serve(t *testing.T, s *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want string
+	}{
+		"result":           {body: `{"jsonrpc":"2.0","method":"add","params":{"A":1,"B":2},"id":1}`, want: `{"jsonrpc":"2.0","result":3,"id":1}`},
+		"string id":        {body: `{"jsonrpc":"2.0","method":"add","params":{"A":1,"B":2},"id":"x"}`, want: `{"jsonrpc":"2.0","result":3,"id":"x"}`},
+		"null result":      {body: `{"jsonrpc":"2.0","method":"nothing","id":1}`, want: `{"jsonrpc":"2.0","result":null,"id":1}`},
+		"typed error":      {body: `{"jsonrpc":"2.0","method":"fail","id":1}`, want: `{"jsonrpc":"2.0","error":{"code":-32000,"message":"failed","data":"detail"},"id":1}`},
+		"internal error":   {body: `{"jsonrpc":"2.0","method":"broken","id":1}`, want: `{"jsonrpc":"2.0","error":{"code":-32603,"message":"broken"},"id":1}`},
+		"method not found": {body: `{"jsonrpc":"2.0","method":"missing","id":1}`, want: `{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":1}`},
+		"parse error":      {body: `{"jsonrpc":"2.0","method"`, want: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`},
+		"invalid request":  {body: `{"jsonrpc":"1.0","method":"add","id":1}`, want: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"invalid id":       {body: `{"jsonrpc":"2.0","method":"add","id":{}}`, want: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"empty batch":      {body: `[]`, want: `{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`},
+		"batch": {
+			body: `[{"jsonrpc":"2.0","method":"add","params":{"A":1,"B":2},"id":1},{"jsonrpc":"2.0","method":"add","params":{"A":1}},1,{"jsonrpc":"2.0","method":"add","params":"x","id":2}]`,
+			want: `[{"jsonrpc":"2.0","result":3,"id":1},{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null},{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"json: cannot unmarshal string into Go value of type jsonrpc.addParams"},"id":2}]`,
+		},
+	}
+	s := newTestServer()
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := serve(t, s, tt.body)
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+				t.Errorf("want %s, got %s", tt.want, got)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("want application/json, got %q", got)
+			}
+		})
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	var called int32
+	s := NewServer()
+	s.Register("notify", func(ctx context.Context, params json.RawMessage) (any, error) {
+		atomic.AddInt32(&called, 1)
+		return nil, errors.New("ignored")
+	})
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","method":"notify"}`,
+		`[{"jsonrpc":"2.0","method":"notify"},{"jsonrpc":"2.0","method":"missing"}]`,
+	} {
+		rec := serve(t, s, body)
+		if rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+			t.Errorf("%s: want empty 204, got %d %q", body, rec.Code, rec.Body.String())
+		}
+	}
+	if called != 2 {
+		t.Errorf("want 2 calls, got %d", called)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s := newTestServer()
+	s.MaxBatchSize = 1
+	rec := serve(t, s, `[{"jsonrpc":"2.0","method":"nothing","id":1},{"jsonrpc":"2.0","method":"nothing","id":2}]`)
+	if !strings.Contains(rec.Body.String(), `"code":-32600`) {
+		t.Errorf("want invalid request, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("want 405, got %d", rec.Code)
+	}
+}
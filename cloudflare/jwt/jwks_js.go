@@ -0,0 +1,194 @@
+//go:build js && wasm
+
+package jwt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/cache"
+	"github.com/syumai/workers/cloudflare/webcrypto"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+const (
+	// DefaultJWKSTTL is the duration for which JWKS is cached when the response doesn't have max-age.
+	DefaultJWKSTTL = time.Hour
+	// minRefreshInterval limits refreshes of JWKS by unknown key IDs, so forged tokens don't make requests to the endpoint.
+	minRefreshInterval = time.Minute
+)
+
+// JWKSOptions represents the options of JWKS.
+type JWKSOptions struct {
+	// Client fetches JWKS. The default is http.DefaultClient.
+	Client *http.Client
+	// Store stores JWKS across requests. The default is the Cache API if it's available.
+	Store JWKSStore
+	// TTL is the duration for which JWKS is cached. The default is max-age of the response, or DefaultJWKSTTL.
+	TTL time.Duration
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// JWKS is KeySet of the keys fetched from the JWKS endpoint, e.g. `https://example.com/.well-known/jwks.json`.
+//   - The keys are cached by the JWKS while it's used (e.g. for tokens of the same request),
+//     and are loaded from Store first when they are not cached.
+//   - The keys are fetched again when they are expired, or the token has an unknown key ID (at most once a minute).
+type JWKS struct {
+	url  string
+	opts JWKSOptions
+
+	mu        sync.Mutex
+	keys      []*jwk
+	imported  map[string]*webcrypto.Key
+	expiresAt time.Time
+	fetchedAt time.Time
+}
+
+// NewJWKS returns JWKS of the endpoint.
+func NewJWKS(url string, opts *JWKSOptions) *JWKS {
+	s := &JWKS{url: url}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.Client == nil {
+		s.opts.Client = http.DefaultClient
+	}
+	if s.opts.Store == nil && jsutil.HasGlobal("caches") {
+		s.opts.Store = CacheStore(cache.New())
+	}
+	if s.opts.Now == nil {
+		s.opts.Now = time.Now
+	}
+	return s
+}
+
+// Key returns the key of the key ID for the algorithm. The key is imported when it's used first.
+func (s *JWKS) Key(ctx context.Context, header *Header) (*webcrypto.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.opts.Now()
+	if now.Before(s.expiresAt) {
+		if key, err := s.find(header); err == nil {
+			return key, nil
+		}
+	} else if err := s.loadStored(ctx, now); err == nil {
+		// JWKS stored by other requests is tried first.
+		if key, err := s.find(header); err == nil {
+			return key, nil
+		}
+	}
+	if now.Sub(s.fetchedAt) >= minRefreshInterval {
+		if err := s.fetch(ctx, now); err != nil {
+			return nil, err
+		}
+	}
+	return s.find(header)
+}
+
+// find returns the imported key of the header.
+func (s *JWKS) find(header *Header) (*webcrypto.Key, error) {
+	for _, k := range s.keys {
+		if !k.matches(header.Algorithm, header.KeyID) {
+			continue
+		}
+		id := k.Kid + "\x00" + string(header.Algorithm)
+		if key, ok := s.imported[id]; ok {
+			return key, nil
+		}
+		key, err := importKey(header.Algorithm, webcrypto.FormatJWK, k.raw, webcrypto.UsageVerify)
+		if err != nil {
+			return nil, err
+		}
+		s.imported[id] = key
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: no key of ID %q for %s", ErrKeyNotFound, header.KeyID, header.Algorithm)
+}
+
+// loadStored loads JWKS from the store.
+func (s *JWKS) loadStored(ctx context.Context, now time.Time) error {
+	if s.opts.Store == nil {
+		return errNotStored
+	}
+	b, err := s.opts.Store.Get(ctx, s.url)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return errNotStored
+	}
+	ttl := s.opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultJWKSTTL
+	}
+	return s.setKeys(b, now.Add(ttl))
+}
+
+var errNotStored = errors.New("jwt: JWKS is not stored")
+
+// fetch fetches JWKS from the endpoint, and stores it.
+func (s *JWKS) fetch(ctx context.Context, now time.Time) error {
+	s.fetchedAt = now
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.opts.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jwt: error fetching JWKS: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("jwt: error fetching JWKS: %s", res.Status)
+	}
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("jwt: error fetching JWKS: %w", err)
+	}
+	ttl := s.opts.TTL
+	if ttl <= 0 {
+		ttl = maxAge(res.Header.Get("Cache-Control"), DefaultJWKSTTL)
+	}
+	if err := s.setKeys(b, now.Add(ttl)); err != nil {
+		return err
+	}
+	if s.opts.Store != nil {
+		// failures of the store only make other requests fetch JWKS.
+		_ = s.opts.Store.Put(ctx, s.url, b, ttl)
+	}
+	return nil
+}
+
+// setKeys sets the keys of JWKS, which are imported when they are used.
+func (s *JWKS) setKeys(b []byte, expiresAt time.Time) error {
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.imported = make(map[string]*webcrypto.Key, len(keys))
+	s.expiresAt = expiresAt
+	return nil
+}
+
+// maxAge returns max-age of the Cache-Control header, or def if it's not given.
+func maxAge(cacheControl string, def time.Duration) time.Duration {
+	for _, d := range strings.Split(cacheControl, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if sec, err := strconv.Atoi(arg); err == nil && sec > 0 {
+			return time.Duration(sec) * time.Second
+		}
+	}
+	return def
+}
@@ -0,0 +1,284 @@
+// Package jwt signs and verifies JSON Web Tokens by the Web Crypto API of the runtime.
+//
+// Pure Go implementations of JWT depend on crypto packages which are slow and large on Wasm,
+// so keys are imported by webcrypto and signatures are computed natively.
+//   - HS256, RS256 and ES256 (and their SHA-384 and SHA-512 variants) are supported. ES512 uses the P-521 curve.
+//   - Keys of issuers are fetched from JWKS endpoints by JWKS, which stores them across requests by the Cache API.
+//   - https://datatracker.ietf.org/doc/html/rfc7519
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when the token is malformed or its signature is invalid.
+	ErrInvalidToken = errors.New("jwt: invalid token")
+	// ErrInvalidClaims is returned when the registered claims are not valid (e.g. expired or of other audiences).
+	ErrInvalidClaims = errors.New("jwt: invalid claims")
+	// ErrKeyNotFound is returned when the key set doesn't have the key of the token.
+	ErrKeyNotFound = errors.New("jwt: key not found")
+)
+
+// Algorithm represents the `alg` header of tokens.
+type Algorithm string
+
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	RS384 Algorithm = "RS384"
+	RS512 Algorithm = "RS512"
+	ES256 Algorithm = "ES256"
+	ES384 Algorithm = "ES384"
+	ES512 Algorithm = "ES512"
+)
+
+// keyType returns the `kty` of JWKs for the algorithm.
+func (a Algorithm) keyType() string {
+	switch a {
+	case HS256, HS384, HS512:
+		return "oct"
+	case RS256, RS384, RS512:
+		return "RSA"
+	case ES256, ES384, ES512:
+		return "EC"
+	}
+	return ""
+}
+
+// Header represents the JOSE header of tokens.
+type Header struct {
+	Algorithm Algorithm `json:"alg"`
+	Type      string    `json:"typ,omitempty"`
+	KeyID     string    `json:"kid,omitempty"`
+}
+
+// NumericDate is the time in seconds since the Unix epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate returns NumericDate of t truncated to seconds.
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{Time: t.Truncate(time.Second)}
+}
+
+func (d *NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprint(d.Unix())), nil
+}
+
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var sec float64
+	if err := json.Unmarshal(b, &sec); err != nil {
+		return err
+	}
+	d.Time = time.Unix(int64(sec), 0)
+	return nil
+}
+
+// Audience is the `aud` claim, which is a string or an array of strings.
+//   - Audience of a single value is encoded as a string.
+type Audience []string
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*a = Audience{s}
+		return nil
+	}
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+// Contains reports whether the audience includes aud.
+func (a Audience) Contains(aud string) bool {
+	for _, v := range a {
+		if v == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisteredClaims represents the registered claims of tokens.
+//   - Embed RegisteredClaims in the struct of custom claims to sign and verify them together.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// ValidateOptions represents the options to validate registered claims.
+type ValidateOptions struct {
+	// Issuer is the expected issuer. if Issuer is empty, the issuer is not validated.
+	Issuer string
+	// Audience is the expected audience. if Audience is empty, the audience is not validated.
+	Audience string
+	// RequireExpiration rejects tokens without the `exp` claim.
+	RequireExpiration bool
+	// Leeway is the allowed clock skew to validate the validity period.
+	Leeway time.Duration
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Validate validates the issuer, the audience and the validity period of the claims.
+//   - if the claims are not valid, returns the error which wraps ErrInvalidClaims.
+func (c *RegisteredClaims) Validate(opts *ValidateOptions) error {
+	if opts == nil {
+		opts = &ValidateOptions{}
+	}
+	now := time.Now()
+	if opts.Now != nil {
+		now = opts.Now()
+	}
+	if opts.Issuer != "" && c.Issuer != opts.Issuer {
+		return fmt.Errorf("%w: issuer %q is not %q", ErrInvalidClaims, c.Issuer, opts.Issuer)
+	}
+	if opts.Audience != "" && !c.Audience.Contains(opts.Audience) {
+		return fmt.Errorf("%w: audience doesn't include %q", ErrInvalidClaims, opts.Audience)
+	}
+	if c.ExpiresAt == nil {
+		if opts.RequireExpiration {
+			return fmt.Errorf("%w: token doesn't have exp", ErrInvalidClaims)
+		}
+	} else if !now.Before(c.ExpiresAt.Add(opts.Leeway)) {
+		return fmt.Errorf("%w: token is expired", ErrInvalidClaims)
+	}
+	if c.NotBefore != nil && now.Add(opts.Leeway).Before(c.NotBefore.Time) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidClaims)
+	}
+	return nil
+}
+
+// Token represents the parsed token.
+type Token struct {
+	Header Header
+	// Claims are the registered claims of the payload.
+	Claims RegisteredClaims
+	// payload is the decoded JSON of the payload.
+	payload []byte
+	// signingInput is the signed part of the token: `header.payload`.
+	signingInput []byte
+	signature    []byte
+}
+
+// Decode decodes the payload into v, e.g. the struct of custom claims.
+func (t *Token) Decode(v any) error {
+	if err := json.Unmarshal(t.payload, v); err != nil {
+		return fmt.Errorf("jwt: error decoding claims: %w", err)
+	}
+	return nil
+}
+
+// ParseUnverified parses the compact serialization of the token without verifying the signature.
+//   - Claims of unverified tokens must not be trusted. Use Verify unless the token is verified by others.
+func ParseUnverified(s string) (*Token, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	t := &Token{signingInput: []byte(parts[0] + "." + parts[1])}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(headerJSON, &t.Header) != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	if t.payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(t.payload, &t.Claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed payload", ErrInvalidToken)
+	}
+	if t.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	return t, nil
+}
+
+// encodeSigningInput returns `header.payload` of the token to be signed.
+func encodeSigningInput(header *Header, claims any) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("jwt: error encoding claims: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// jwk is the key of JWKS.
+type jwk struct {
+	Kid string    `json:"kid"`
+	Kty string    `json:"kty"`
+	Alg Algorithm `json:"alg"`
+	Use string    `json:"use"`
+	Crv string    `json:"crv"`
+	// raw is the JSON of the key to be imported.
+	raw []byte
+}
+
+// matches reports whether the key can be used to verify tokens of the algorithm and the key ID.
+func (k *jwk) matches(alg Algorithm, kid string) bool {
+	if kid != "" && k.Kid != kid {
+		return false
+	}
+	if k.Use != "" && k.Use != "sig" {
+		return false
+	}
+	if k.Alg != "" {
+		return k.Alg == alg
+	}
+	if k.Kty != alg.keyType() {
+		return false
+	}
+	switch alg {
+	case ES256:
+		return k.Crv == "P-256"
+	case ES384:
+		return k.Crv == "P-384"
+	case ES512:
+		return k.Crv == "P-521"
+	}
+	return true
+}
+
+// parseJWKS parses the JSON of JWKS.
+func parseJWKS(b []byte) ([]*jwk, error) {
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("jwt: error decoding JWKS: %w", err)
+	}
+	keys := make([]*jwk, 0, len(set.Keys))
+	for _, raw := range set.Keys {
+		k := &jwk{raw: raw}
+		if err := json.Unmarshal(raw, k); err != nil {
+			return nil, fmt.Errorf("jwt: error decoding JWKS: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
@@ -0,0 +1,133 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func encodeSegment(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func TestParseUnverified(t *testing.T) {
+	s := encodeSegment(`{"alg":"ES256","kid":"k1","typ":"JWT"}`) + "." +
+		encodeSegment(`{"iss":"me","aud":"app","exp":1700000000,"name":"x"}`) + "." +
+		encodeSegment("sig")
+	tok, err := ParseUnverified(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok.Header.Algorithm != ES256 || tok.Header.KeyID != "k1" || string(tok.signature) != "sig" {
+		t.Errorf("unexpected token: %+v", tok)
+	}
+	if tok.Claims.Issuer != "me" || !tok.Claims.Audience.Contains("app") || tok.Claims.ExpiresAt.Unix() != 1700000000 {
+		t.Errorf("unexpected claims: %+v", tok.Claims)
+	}
+	var custom struct {
+		RegisteredClaims
+		Name string `json:"name"`
+	}
+	if err := tok.Decode(&custom); err != nil {
+		t.Fatal(err)
+	}
+	if custom.Name != "x" || custom.Issuer != "me" {
+		t.Errorf("unexpected custom claims: %+v", custom)
+	}
+	for _, s := range []string{"", "a.b", "!.e30.e30", encodeSegment("{}") + ".!.e30", "e30.e30.!"} {
+		if _, err := ParseUnverified(s); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("ParseUnverified(%q): want ErrInvalidToken, got %v", s, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	claims := &RegisteredClaims{
+		Issuer:    "me",
+		Audience:  Audience{"a", "b"},
+		ExpiresAt: NewNumericDate(now.Add(time.Minute)),
+		NotBefore: NewNumericDate(now.Add(-time.Minute)),
+	}
+	opts := func(modify func(o *ValidateOptions)) *ValidateOptions {
+		o := &ValidateOptions{Issuer: "me", Audience: "b", Now: func() time.Time { return now }}
+		if modify != nil {
+			modify(o)
+		}
+		return o
+	}
+	if err := claims.Validate(opts(nil)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for name, o := range map[string]*ValidateOptions{
+		"issuer":   opts(func(o *ValidateOptions) { o.Issuer = "other" }),
+		"audience": opts(func(o *ValidateOptions) { o.Audience = "c" }),
+		"expired":  opts(func(o *ValidateOptions) { o.Now = func() time.Time { return now.Add(time.Hour) } }),
+		"not yet":  opts(func(o *ValidateOptions) { o.Now = func() time.Time { return now.Add(-time.Hour) } }),
+	} {
+		if err := claims.Validate(o); !errors.Is(err, ErrInvalidClaims) {
+			t.Errorf("%s: want ErrInvalidClaims, got %v", name, err)
+		}
+	}
+	if err := claims.Validate(opts(func(o *ValidateOptions) {
+		o.Now = func() time.Time { return now.Add(2 * time.Minute) }
+		o.Leeway = 2 * time.Minute
+	})); err != nil {
+		t.Errorf("leeway: unexpected error: %v", err)
+	}
+	if err := (&RegisteredClaims{}).Validate(&ValidateOptions{RequireExpiration: true}); !errors.Is(err, ErrInvalidClaims) {
+		t.Errorf("want ErrInvalidClaims without exp, got %v", err)
+	}
+}
+
+func TestMarshalClaims(t *testing.T) {
+	b, err := json.Marshal(&RegisteredClaims{Subject: "u", Audience: Audience{"app"}, IssuedAt: NewNumericDate(time.Unix(1700000000, 5))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"sub":"u","aud":"app","iat":1700000000}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+	b, _ = json.Marshal(Audience{"a", "b"})
+	if string(b) != `["a","b"]` {
+		t.Errorf("got %s", b)
+	}
+}
+
+func TestJWKMatches(t *testing.T) {
+	keys, err := parseJWKS([]byte(`{"keys":[
+		{"kid":"r","kty":"RSA"},
+		{"kid":"e","kty":"EC","crv":"P-256"},
+		{"kid":"enc","kty":"RSA","use":"enc"},
+		{"kid":"a","kty":"RSA","alg":"RS512"},
+		{"kid":"p521","kty":"EC","crv":"P-521"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key  int
+		alg  Algorithm
+		kid  string
+		want bool
+	}{
+		{0, RS256, "r", true},
+		{0, RS256, "", true},
+		{0, RS256, "x", false},
+		{0, ES256, "r", false},
+		{1, ES256, "e", true},
+		{1, ES384, "e", false},
+		{2, RS256, "enc", false},
+		{3, RS256, "a", false},
+		{3, RS512, "a", true},
+		{4, ES512, "p521", true},
+		{4, ES384, "p521", false},
+	}
+	for _, tt := range tests {
+		if got := keys[tt.key].matches(tt.alg, tt.kid); got != tt.want {
+			t.Errorf("key %d matches(%s, %q) = %v, want %v", tt.key, tt.alg, tt.kid, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+//go:build js && wasm
+
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+// webcryptoAlgorithm returns the algorithm of webcrypto to import keys and compute signatures of alg.
+//   - ECDSA signatures of webcrypto are the concatenation of r and s, which is the same as JWS.
+func webcryptoAlgorithm(alg Algorithm) (webcrypto.Algorithm, error) {
+	switch alg {
+	case HS256:
+		return &webcrypto.HMAC{Hash: webcrypto.SHA256}, nil
+	case HS384:
+		return &webcrypto.HMAC{Hash: webcrypto.SHA384}, nil
+	case HS512:
+		return &webcrypto.HMAC{Hash: webcrypto.SHA512}, nil
+	case RS256:
+		return &webcrypto.RSASSAPKCS1v15{Hash: webcrypto.SHA256}, nil
+	case RS384:
+		return &webcrypto.RSASSAPKCS1v15{Hash: webcrypto.SHA384}, nil
+	case RS512:
+		return &webcrypto.RSASSAPKCS1v15{Hash: webcrypto.SHA512}, nil
+	case ES256:
+		return &webcrypto.ECDSA{Hash: webcrypto.SHA256, NamedCurve: "P-256"}, nil
+	case ES384:
+		return &webcrypto.ECDSA{Hash: webcrypto.SHA384, NamedCurve: "P-384"}, nil
+	case ES512:
+		return &webcrypto.ECDSA{Hash: webcrypto.SHA512, NamedCurve: "P-521"}, nil
+	}
+	return nil, fmt.Errorf("jwt: unsupported algorithm %q", alg)
+}
+
+// ImportSecret imports the secret of HMAC algorithms (HS256, HS384 and HS512) to sign and verify tokens.
+func ImportSecret(alg Algorithm, secret []byte) (*webcrypto.Key, error) {
+	if alg.keyType() != "oct" {
+		return nil, fmt.Errorf("jwt: %s is not an HMAC algorithm", alg)
+	}
+	return importKey(alg, webcrypto.FormatRaw, secret, webcrypto.UsageSign, webcrypto.UsageVerify)
+}
+
+// ImportJWK imports the JSON encoded JWK for the algorithm.
+//   - Private keys (and secrets) can sign tokens, and public keys can verify them.
+func ImportJWK(alg Algorithm, b []byte) (*webcrypto.Key, error) {
+	var k struct {
+		Kty string `json:"kty"`
+		D   string `json:"d"`
+	}
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, fmt.Errorf("jwt: error decoding JWK: %w", err)
+	}
+	switch {
+	case k.Kty == "oct":
+		return importKey(alg, webcrypto.FormatJWK, b, webcrypto.UsageSign, webcrypto.UsageVerify)
+	case k.D != "":
+		return importKey(alg, webcrypto.FormatJWK, b, webcrypto.UsageSign)
+	}
+	return importKey(alg, webcrypto.FormatJWK, b, webcrypto.UsageVerify)
+}
+
+// ImportPKCS8 imports the DER encoded PKCS #8 private key of RSA or ECDSA algorithms to sign tokens.
+func ImportPKCS8(alg Algorithm, der []byte) (*webcrypto.Key, error) {
+	return importKey(alg, webcrypto.FormatPKCS8, der, webcrypto.UsageSign)
+}
+
+// ImportSPKI imports the DER encoded SubjectPublicKeyInfo of RSA or ECDSA algorithms to verify tokens.
+func ImportSPKI(alg Algorithm, der []byte) (*webcrypto.Key, error) {
+	return importKey(alg, webcrypto.FormatSPKI, der, webcrypto.UsageVerify)
+}
+
+func importKey(alg Algorithm, format webcrypto.KeyFormat, data []byte, usages ...webcrypto.KeyUsage) (*webcrypto.Key, error) {
+	a, err := webcryptoAlgorithm(alg)
+	if err != nil {
+		return nil, err
+	}
+	key, err := webcrypto.ImportKey(format, data, a, false, usages...)
+	if err != nil {
+		return nil, fmt.Errorf("jwt: error importing key: %w", err)
+	}
+	return key, nil
+}
+
+// SignOptions represents the options of Sign.
+type SignOptions struct {
+	// KeyID is the `kid` header, which identifies the key in JWKS.
+	KeyID string
+	// Type is the `typ` header. The default is `JWT`.
+	Type string
+}
+
+// Sign encodes claims as JSON, and returns the token signed with the key.
+//   - The key must be imported for the algorithm with the sign usage, e.g. by ImportSecret or ImportPKCS8.
+func Sign(alg Algorithm, key *webcrypto.Key, claims any, opts *SignOptions) (string, error) {
+	if opts == nil {
+		opts = &SignOptions{}
+	}
+	a, err := webcryptoAlgorithm(alg)
+	if err != nil {
+		return "", err
+	}
+	header := &Header{Algorithm: alg, Type: opts.Type, KeyID: opts.KeyID}
+	if header.Type == "" {
+		header.Type = "JWT"
+	}
+	signingInput, err := encodeSigningInput(header, claims)
+	if err != nil {
+		return "", err
+	}
+	signature, err := webcrypto.Sign(a, key, []byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("jwt: error signing token: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// KeySet returns the key to verify tokens.
+type KeySet interface {
+	// Key returns the key for the algorithm and the key ID of the header.
+	// if the key is not found, Key should return the error which wraps ErrKeyNotFound.
+	Key(ctx context.Context, header *Header) (*webcrypto.Key, error)
+}
+
+// StaticKey returns KeySet of the single key, which verifies only tokens of the algorithm.
+func StaticKey(alg Algorithm, key *webcrypto.Key) KeySet {
+	return &staticKey{alg: alg, key: key}
+}
+
+type staticKey struct {
+	alg Algorithm
+	key *webcrypto.Key
+}
+
+func (s *staticKey) Key(ctx context.Context, header *Header) (*webcrypto.Key, error) {
+	if header.Algorithm != s.alg {
+		return nil, fmt.Errorf("%w: algorithm %q is not %q", ErrKeyNotFound, header.Algorithm, s.alg)
+	}
+	return s.key, nil
+}
+
+// VerifyOptions represents the options of Verify.
+type VerifyOptions struct {
+	ValidateOptions
+	// Algorithms are the allowed algorithms. if Algorithms is empty, all supported algorithms are allowed,
+	// and the key set decides which algorithm is used for the key.
+	Algorithms []Algorithm
+}
+
+// Verify verifies the signature of the token with the key of the key set, and validates the registered claims.
+//   - if the token is invalid, returns the error which wraps ErrInvalidToken, ErrInvalidClaims or ErrKeyNotFound.
+//   - Custom claims can be decoded by Decode of the returned token.
+func Verify(ctx context.Context, s string, keys KeySet, opts *VerifyOptions) (*Token, error) {
+	if opts == nil {
+		opts = &VerifyOptions{}
+	}
+	t, err := ParseUnverified(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Algorithms) > 0 && !containsAlgorithm(opts.Algorithms, t.Header.Algorithm) {
+		return nil, fmt.Errorf("%w: algorithm %q is not allowed", ErrInvalidToken, t.Header.Algorithm)
+	}
+	a, err := webcryptoAlgorithm(t.Header.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, t.Header.Algorithm)
+	}
+	key, err := keys.Key(ctx, &t.Header)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := webcrypto.Verify(a, key, t.signature, t.signingInput)
+	if err != nil {
+		// keys of other algorithms (e.g. RSA keys for HS256) can't be used by webcrypto.
+		return nil, fmt.Errorf("%w: error verifying signature: %v", ErrInvalidToken, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: signature is invalid", ErrInvalidToken)
+	}
+	if err := t.Claims.Validate(&opts.ValidateOptions); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func containsAlgorithm(algs []Algorithm, alg Algorithm) bool {
+	for _, a := range algs {
+		if a == alg {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+//go:build js && wasm
+
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type testClaims struct {
+	RegisteredClaims
+	Role string `json:"role"`
+}
+
+func newClaims() *testClaims {
+	return &testClaims{
+		RegisteredClaims: RegisteredClaims{Issuer: "me", ExpiresAt: NewNumericDate(time.Now().Add(time.Hour))},
+		Role:             "admin",
+	}
+}
+
+func generateKeyPair(t *testing.T, alg Algorithm) *webcrypto.KeyPair {
+	t.Helper()
+	a, err := webcryptoAlgorithm(alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsa, ok := a.(*webcrypto.RSASSAPKCS1v15); ok {
+		rsa.ModulusLength = 2048
+	}
+	pair, err := webcrypto.GenerateKeyPair(a, true, webcrypto.UsageSign, webcrypto.UsageVerify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pair
+}
+
+func TestSignVerify(t *testing.T) {
+	ctx := context.Background()
+	secret, err := ImportSecret(HS256, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsa := generateKeyPair(t, RS256)
+	ec := generateKeyPair(t, ES256)
+	p521 := generateKeyPair(t, ES512)
+	tests := []struct {
+		alg          Algorithm
+		signKey, key *webcrypto.Key
+	}{
+		{HS256, secret, secret},
+		{RS256, rsa.PrivateKey, rsa.PublicKey},
+		{ES256, ec.PrivateKey, ec.PublicKey},
+		{ES512, p521.PrivateKey, p521.PublicKey},
+	}
+	for _, tt := range tests {
+		s, err := Sign(tt.alg, tt.signKey, newClaims(), &SignOptions{KeyID: "k1"})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		tok, err := Verify(ctx, s, StaticKey(tt.alg, tt.key), &VerifyOptions{ValidateOptions: ValidateOptions{Issuer: "me"}})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		var claims testClaims
+		if err := tok.Decode(&claims); err != nil {
+			t.Fatal(err)
+		}
+		if claims.Role != "admin" || tok.Header.KeyID != "k1" || tok.Header.Type != "JWT" {
+			t.Errorf("%s: unexpected token: %+v %+v", tt.alg, tok.Header, claims)
+		}
+		tampered := s[:len(s)-2] + "AA"
+		if _, err := Verify(ctx, tampered, StaticKey(tt.alg, tt.key), nil); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: want ErrInvalidToken for tampered token, got %v", tt.alg, err)
+		}
+	}
+
+	s, _ := Sign(HS256, secret, newClaims(), nil)
+	if _, err := Verify(ctx, s, StaticKey(RS256, rsa.PublicKey), nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("want ErrKeyNotFound for other algorithm, got %v", err)
+	}
+	if _, err := Verify(ctx, s, StaticKey(HS256, secret), &VerifyOptions{Algorithms: []Algorithm{RS256}}); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("want ErrInvalidToken for disallowed algorithm, got %v", err)
+	}
+	expired := newClaims()
+	expired.ExpiresAt = NewNumericDate(time.Now().Add(-time.Hour))
+	s, _ = Sign(HS256, secret, expired, nil)
+	if _, err := Verify(ctx, s, StaticKey(HS256, secret), nil); !errors.Is(err, ErrInvalidClaims) {
+		t.Errorf("want ErrInvalidClaims for expired token, got %v", err)
+	}
+}
+
+func TestES512(t *testing.T) {
+	// the example of RFC 7515 Appendix A.4, whose payload is not JSON.
+	key, err := ImportJWK(ES512, []byte(`{"kty":"EC","crv":"P-521",`+
+		`"x":"AekpBQ8ST8a8VcfVOTNl353vSrDCLLJXmPk06wTjxrrjcBpXp5EOnYG_NjFZ6OvLFV1jSfS9tsz4qUxcWceqwQGk",`+
+		`"y":"ADSmRA43Z1DSNx_RvcLI87cdL07l6jQyyBXMoxVg_l2Th-x3S1WDhjDly79ajL4Kkd0AZMaZmh9ubmf63e3kyMj2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const signingInput = "eyJhbGciOiJFUzUxMiJ9.UGF5bG9hZA"
+	signature, _ := base64.RawURLEncoding.DecodeString("AdwMgeerwtHoh-l192l60hp9wAHZFVJbLfD_UxMi70cwnZOYaRI1bKPWROc-mZZqwqT2SI-KGDKB34XO0aw_" +
+		"7XdtAG8GaSwFKdCAPZgoXD2YBJZCPEX3xKpRwcdOO8KpEHwJjyqOgzDO7iKvU8vcnwNrmxYbSW9ERBXukOXolLzeO_Jn")
+	a, err := webcryptoAlgorithm(ES512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := webcrypto.Verify(a, key, signature, []byte(signingInput))
+	if err != nil || !ok {
+		t.Errorf("Verify() = %v, %v, want true", ok, err)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	ctx := context.Background()
+	pair := generateKeyPair(t, ES256)
+	pub, err := webcrypto.ExportKey(webcrypto.FormatJWK, pair.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k map[string]any
+	if err := json.Unmarshal(pub, &k); err != nil {
+		t.Fatal(err)
+	}
+	k["kid"] = "k1"
+	b, _ := json.Marshal(map[string]any{"keys": []any{k}})
+	fetches := 0
+	now := time.Now()
+	opts := &JWKSOptions{
+		Client: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fetches++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Cache-Control": {"public, max-age=600"}},
+				Body:       io.NopCloser(strings.NewReader(string(b))),
+			}, nil
+		})},
+		Now: func() time.Time { return now },
+	}
+	set := NewJWKS("https://example.com/jwks.json", opts)
+
+	s, err := Sign(ES256, pair.PrivateKey, newClaims(), &SignOptions{KeyID: "k1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Verify(ctx, s, set, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+
+	// unknown key IDs don't fetch JWKS within minRefreshInterval.
+	s, _ = Sign(ES256, pair.PrivateKey, newClaims(), &SignOptions{KeyID: "k2"})
+	if _, err := Verify(ctx, s, set, nil); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("want ErrKeyNotFound, got %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("fetches = %d, want 1", fetches)
+	}
+
+	// JWKS is fetched again after max-age.
+	now = now.Add(11 * time.Minute)
+	s, _ = Sign(ES256, pair.PrivateKey, newClaims(), &SignOptions{KeyID: "k1"})
+	if _, err := Verify(ctx, s, set, nil); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+
+	// another request uses the stored JWKS.
+	opts.Store = KVStore(bindingtest.NewKV())
+	for i := 0; i < 2; i++ {
+		if _, err := Verify(ctx, s, NewJWKS("https://example.com/jwks.json", opts), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 3 {
+		t.Errorf("want the stored JWKS to be used, got %d fetches", fetches)
+	}
+}
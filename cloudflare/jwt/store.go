@@ -0,0 +1,92 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/cache"
+)
+
+// JWKSStore stores the JSON of JWKS across requests, so that requests don't fetch JWKS each time.
+type JWKSStore interface {
+	// Get returns the stored JWKS. if JWKS is not found, returns nil without error.
+	Get(ctx context.Context, url string) ([]byte, error)
+	Put(ctx context.Context, url string, jwks []byte, ttl time.Duration) error
+}
+
+// CacheStore returns JWKSStore which stores JWKS by the Cache API (e.g. `cache.New()`).
+func CacheStore(c binding.Cache) JWKSStore {
+	return &cacheStore{cache: c}
+}
+
+type cacheStore struct {
+	cache binding.Cache
+}
+
+func (s *cacheStore) Get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.cache.Match(req, nil)
+	if errors.Is(err, cache.ErrCacheNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return io.ReadAll(res.Body)
+}
+
+func (s *cacheStore) Put(ctx context.Context, url string, jwks []byte, ttl time.Duration) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	res := &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			"Content-Type":  {"application/json"},
+			"Cache-Control": {fmt.Sprintf("max-age=%d", int(ttl/time.Second))},
+		},
+		Body:          io.NopCloser(bytes.NewReader(jwks)),
+		ContentLength: int64(len(jwks)),
+	}
+	return s.cache.Put(req, res)
+}
+
+// KVStore returns JWKSStore which stores JWKS by the KV namespace.
+func KVStore(kv binding.KV) JWKSStore {
+	return &kvStore{kv: kv}
+}
+
+type kvStore struct {
+	kv binding.KV
+}
+
+// minKVExpirationTTL is the minimum expirationTtl of KV.
+const minKVExpirationTTL = 60
+
+func (s *kvStore) Get(ctx context.Context, url string) ([]byte, error) {
+	v, err := s.kv.GetString(url, nil)
+	if err != nil || v == "" {
+		return nil, err
+	}
+	return []byte(v), nil
+}
+
+func (s *kvStore) Put(ctx context.Context, url string, jwks []byte, ttl time.Duration) error {
+	ttlSeconds := int(ttl / time.Second)
+	if ttlSeconds < minKVExpirationTTL {
+		ttlSeconds = minKVExpirationTTL
+	}
+	return s.kv.PutString(url, string(jwks), &cloudflare.KVNamespacePutOptions{ExpirationTTL: ttlSeconds})
+}
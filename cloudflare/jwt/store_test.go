@@ -0,0 +1,31 @@
+package jwt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+func TestJWKSStores(t *testing.T) {
+	stores := map[string]JWKSStore{
+		"cache": CacheStore(bindingtest.NewCache()),
+		"kv":    KVStore(bindingtest.NewKV()),
+	}
+	const url = "https://example.com/.well-known/jwks.json"
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if b, err := store.Get(ctx, url); b != nil || err != nil {
+				t.Errorf("Get() before Put = %q, %v", b, err)
+			}
+			if err := store.Put(ctx, url, []byte(`{"keys":[]}`), time.Hour); err != nil {
+				t.Fatal(err)
+			}
+			if b, err := store.Get(ctx, url); string(b) != `{"keys":[]}` || err != nil {
+				t.Errorf("Get() = %q, %v", b, err)
+			}
+		})
+	}
+}
@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package cloudflare
 
 import (
@@ -30,12 +32,6 @@ func NewKVNamespace(ctx context.Context, varName string) (*KVNamespace, error) {
 	return &KVNamespace{instance: inst}, nil
 }
 
-// KVNamespaceGetOptions represents Cloudflare KV namespace get options.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L930
-type KVNamespaceGetOptions struct {
-	CacheTTL int
-}
-
 func (opts *KVNamespaceGetOptions) toJS(type_ string) js.Value {
 	obj := jsutil.NewObject()
 	obj.Set("type", type_)
@@ -59,6 +55,25 @@ func (kv *KVNamespace) GetString(key string, opts *KVNamespaceGetOptions) (strin
 	return v.String(), nil
 }
 
+// GetJSON gets the value stored as JSON by the specified key, and decodes it into out by `jsutil.Decode`.
+//   - The value is parsed on JavaScript side (`json` type of KV), so the JSON text is not transferred to Go.
+//   - if the key doesn't exist, out is not modified and found is false.
+//   - if a network error happens, returns error.
+func (kv *KVNamespace) GetJSON(key string, out any, opts *KVNamespaceGetOptions) (found bool, err error) {
+	p := kv.instance.Call("get", key, opts.toJS("json"))
+	v, err := jsutil.AwaitPromise(p)
+	if err != nil {
+		return false, err
+	}
+	if v.IsNull() {
+		return false, nil
+	}
+	if err := jsutil.Decode(v, out); err != nil {
+		return false, fmt.Errorf("error decoding value of %s: %w", key, err)
+	}
+	return true, nil
+}
+
 // GetReader gets stream value by the specified key.
 //   - if a network error happens, returns error.
 func (kv *KVNamespace) GetReader(key string, opts *KVNamespaceGetOptions) (io.Reader, error) {
@@ -67,18 +82,9 @@ func (kv *KVNamespace) GetReader(key string, opts *KVNamespaceGetOptions) (io.Re
 	if err != nil {
 		return nil, err
 	}
-	jsutil.Global.Get("console").Call("log", v)
 	return jsutil.ConvertStreamReaderToReader(v.Call("getReader")), nil
 }
 
-// KVNamespaceListOptions represents Cloudflare KV namespace list options.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L946
-type KVNamespaceListOptions struct {
-	Limit  int
-	Prefix string
-	Cursor string
-}
-
 func (opts *KVNamespaceListOptions) toJS() js.Value {
 	if opts == nil {
 		return js.Undefined()
@@ -96,15 +102,6 @@ func (opts *KVNamespaceListOptions) toJS() js.Value {
 	return obj
 }
 
-// KVNamespaceListKey represents Cloudflare KV namespace list key.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L940
-type KVNamespaceListKey struct {
-	Name string
-	// Expiration is an expiration of KV value cache. The value `0` means no expiration.
-	Expiration int
-	// Metadata   map[string]any // TODO: implement
-}
-
 // toKVNamespaceListResult converts JavaScript side's KVNamespaceListKey to *KVNamespaceListKey.
 //   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L940
 func toKVNamespaceListKey(v js.Value) (*KVNamespaceListKey, error) {
@@ -120,14 +117,6 @@ func toKVNamespaceListKey(v js.Value) (*KVNamespaceListKey, error) {
 	}, nil
 }
 
-// KVNamespaceListResult represents Cloudflare KV namespace list result.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L952
-type KVNamespaceListResult struct {
-	Keys         []*KVNamespaceListKey
-	ListComplete bool
-	Cursor       string
-}
-
 // toKVNamespaceListResult converts JavaScript side's KVNamespaceListResult to *KVNamespaceListResult.
 //   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L952
 func toKVNamespaceListResult(v js.Value) (*KVNamespaceListResult, error) {
@@ -164,14 +153,6 @@ func (kv *KVNamespace) List(opts *KVNamespaceListOptions) (*KVNamespaceListResul
 	return toKVNamespaceListResult(v)
 }
 
-// KVNamespacePutOptions represents Cloudflare KV namespace put options.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L958
-type KVNamespacePutOptions struct {
-	Expiration    int
-	ExpirationTTL int
-	// Metadata // TODO: implement
-}
-
 func (opts *KVNamespacePutOptions) toJS() js.Value {
 	if opts == nil {
 		return js.Undefined()
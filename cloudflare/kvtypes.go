@@ -0,0 +1,40 @@
+package cloudflare
+
+// KVNamespaceGetOptions represents Cloudflare KV namespace get options.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L930
+type KVNamespaceGetOptions struct {
+	CacheTTL int
+}
+
+// KVNamespaceListOptions represents Cloudflare KV namespace list options.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L946
+type KVNamespaceListOptions struct {
+	Limit  int
+	Prefix string
+	Cursor string
+}
+
+// KVNamespaceListKey represents Cloudflare KV namespace list key.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L940
+type KVNamespaceListKey struct {
+	Name string
+	// Expiration is an expiration of KV value cache. The value `0` means no expiration.
+	Expiration int
+	// Metadata   map[string]any // TODO: implement
+}
+
+// KVNamespaceListResult represents Cloudflare KV namespace list result.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L952
+type KVNamespaceListResult struct {
+	Keys         []*KVNamespaceListKey
+	ListComplete bool
+	Cursor       string
+}
+
+// KVNamespacePutOptions represents Cloudflare KV namespace put options.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L958
+type KVNamespacePutOptions struct {
+	Expiration    int
+	ExpirationTTL int
+	// Metadata // TODO: implement
+}
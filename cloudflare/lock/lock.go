@@ -0,0 +1,230 @@
+// Package lock provides distributed locks held by Durable Objects, one object per lock.
+//
+// Locks are leases which expire after their TTL unless they are renewed, so locks held by crashed workers are released.
+// Each acquisition has a fencing token which increases monotonically, so resources can reject writes of stale holders.
+//   - Cron Triggers and workers of multiple data centers can run exclusive work by Do.
+//   - Leader election: workers call Do with the same name periodically, and only the leader runs the function.
+package lock
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+var (
+	// ErrLocked is returned by Acquire when the lock is held by another owner.
+	ErrLocked = errors.New("lock: locked by another owner")
+	// ErrLeaseLost is returned when the lease is expired or held by another owner.
+	ErrLeaseLost = errors.New("lock: lease is lost")
+)
+
+const (
+	// DefaultTTL is the TTL of leases.
+	DefaultTTL = 30 * time.Second
+	// DefaultRetryInterval is the interval of retries while waiting for the lock.
+	DefaultRetryInterval = 100 * time.Millisecond
+)
+
+// Lease represents the lock held by the owner.
+type Lease struct {
+	Name  string
+	Owner string
+	// Token is the fencing token, which increases on each acquisition of the lock.
+	// Resources protected by the lock should reject writes with tokens less than the latest one they've seen.
+	Token     int64
+	ExpiresAt time.Time
+}
+
+// Locker acquires locks of the Durable Object namespace.
+//   - The class of the namespace must be implemented by NewDurableObject.
+type Locker struct {
+	ns binding.DurableObjectNamespace
+}
+
+// NewLocker returns Locker of the namespace.
+func NewLocker(ns binding.DurableObjectNamespace) *Locker {
+	return &Locker{ns: ns}
+}
+
+// AcquireOptions represents the options of Acquire and Do.
+type AcquireOptions struct {
+	// Owner identifies the holder. The default is a random ID.
+	// Acquire by the owner of the lock renews the lease with the same token.
+	Owner string
+	// TTL is the duration until the lease expires. The default is DefaultTTL.
+	TTL time.Duration
+	// Wait is the maximum duration to wait for the lock. if Wait is 0, Acquire fails immediately when the lock is held.
+	Wait time.Duration
+	// RetryInterval is the interval of retries while waiting. The default is DefaultRetryInterval.
+	RetryInterval time.Duration
+}
+
+// Acquire acquires the lock of the name.
+//   - if the lock is held by another owner after Wait, returns ErrLocked.
+func (l *Locker) Acquire(ctx context.Context, name string, opts *AcquireOptions) (*Lease, error) {
+	o := AcquireOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Owner == "" {
+		owner, err := randomID()
+		if err != nil {
+			return nil, err
+		}
+		o.Owner = owner
+	}
+	if o.TTL <= 0 {
+		o.TTL = DefaultTTL
+	}
+	if o.RetryInterval <= 0 {
+		o.RetryInterval = DefaultRetryInterval
+	}
+	deadline := time.Now().Add(o.Wait)
+	for {
+		lease, err := l.call(ctx, name, &command{Op: opAcquire, Owner: o.Owner, TTLMs: o.TTL.Milliseconds()})
+		if !errors.Is(err, ErrLocked) || !time.Now().Add(o.RetryInterval).Before(deadline) {
+			return lease, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(o.RetryInterval):
+		}
+	}
+}
+
+// Renew extends the lease by ttl from now. if the lease is lost, returns ErrLeaseLost.
+func (l *Locker) Renew(ctx context.Context, lease *Lease, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return l.call(ctx, lease.Name, &command{Op: opRenew, Owner: lease.Owner, Token: lease.Token, TTLMs: ttl.Milliseconds()})
+}
+
+// Release releases the lease. if the lease is already lost, returns ErrLeaseLost.
+func (l *Locker) Release(ctx context.Context, lease *Lease) error {
+	_, err := l.call(ctx, lease.Name, &command{Op: opRelease, Owner: lease.Owner, Token: lease.Token})
+	return err
+}
+
+// Holder returns the current lease of the lock. if the lock is not held, returns nil.
+func (l *Locker) Holder(ctx context.Context, name string) (*Lease, error) {
+	lease, err := l.call(ctx, name, &command{Op: opGet})
+	if errors.Is(err, ErrLeaseLost) {
+		return nil, nil
+	}
+	return lease, err
+}
+
+// Do runs fn while holding the lock, and releases it after fn returns.
+//   - The lease is renewed every 1/3 of TTL while fn runs. if the lease is lost, the context given to fn is canceled.
+//   - if the lock is held by another owner, returns ErrLocked without running fn.
+func (l *Locker) Do(ctx context.Context, name string, opts *AcquireOptions, fn func(ctx context.Context, lease *Lease) error) error {
+	lease, err := l.Acquire(ctx, name, opts)
+	if err != nil {
+		return err
+	}
+	ttl := DefaultTTL
+	if opts != nil && opts.TTL > 0 {
+		ttl = opts.TTL
+	}
+	fnCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if _, err := l.Renew(fnCtx, lease, ttl); errors.Is(err, ErrLeaseLost) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	err = fn(fnCtx, lease)
+	close(stop)
+	<-renewed
+	if releaseErr := l.Release(context.Background(), lease); err == nil && !errors.Is(releaseErr, ErrLeaseLost) {
+		err = releaseErr
+	}
+	return err
+}
+
+const (
+	opAcquire = "acquire"
+	opRenew   = "renew"
+	opRelease = "release"
+	opGet     = "get"
+)
+
+// command is the request to the object.
+type command struct {
+	Op    string `json:"op"`
+	Owner string `json:"owner,omitempty"`
+	Token int64  `json:"token,omitempty"`
+	TTLMs int64  `json:"ttlMs,omitempty"`
+}
+
+// state is the lease stored by the object, and the response of commands.
+type state struct {
+	Owner string `json:"owner"`
+	Token int64  `json:"token"`
+	// ExpiresAt is the time in milliseconds since the Unix epoch.
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+func (l *Locker) call(ctx context.Context, name string, cmd *command) (*Lease, error) {
+	stub, err := l.ns.Get(l.ns.IdFromName(name))
+	if err != nil {
+		return nil, err
+	}
+	b, _ := json.Marshal(cmd)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://lock/", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	res, err := stub.Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("lock: error fetching Durable Object: %w", err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusConflict:
+		if cmd.Op == opAcquire {
+			return nil, ErrLocked
+		}
+		return nil, ErrLeaseLost
+	default:
+		return nil, fmt.Errorf("lock: unexpected status of Durable Object: %s", res.Status)
+	}
+	var s state
+	if err := json.NewDecoder(res.Body).Decode(&s); err != nil {
+		return nil, fmt.Errorf("lock: error decoding lease: %w", err)
+	}
+	return &Lease{Name: name, Owner: s.Owner, Token: s.Token, ExpiresAt: time.UnixMilli(s.ExpiresAt)}, nil
+}
+
+func randomID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,186 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+type memoryStorage struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *memoryStorage) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key], nil
+}
+
+func (s *memoryStorage) Put(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	return nil
+}
+
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestLocker returns Locker whose objects use the clock and the storage.
+func newTestLocker(clock *testClock, storage *memoryStorage) *Locker {
+	if storage == nil {
+		storage = &memoryStorage{values: map[string]string{}}
+	}
+	ns := bindingtest.NewDurableObjectNamespace(func(id binding.DurableObjectID) http.Handler {
+		o := NewDurableObject(storage).(*durableObject)
+		o.now = clock.Now
+		return o
+	})
+	return NewLocker(ns)
+}
+
+func TestAcquireRenewRelease(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	l := newTestLocker(clock, nil)
+	a, err := l.Acquire(ctx, "job", &AcquireOptions{Owner: "a", TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Token != 1 || a.Owner != "a" || !a.ExpiresAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("unexpected lease: %+v", a)
+	}
+	if _, err := l.Acquire(ctx, "job", &AcquireOptions{Owner: "b"}); !errors.Is(err, ErrLocked) {
+		t.Errorf("want ErrLocked, got %v", err)
+	}
+	if h, err := l.Holder(ctx, "job"); err != nil || h.Owner != "a" {
+		t.Errorf("Holder = %+v, %v", h, err)
+	}
+	clock.Add(30 * time.Second)
+	if a, err = l.Renew(ctx, a, time.Minute); err != nil || !a.ExpiresAt.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("Renew = %+v, %v", a, err)
+	}
+	if err := l.Release(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Release(ctx, a); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("want ErrLeaseLost, got %v", err)
+	}
+	b, err := l.Acquire(ctx, "job", &AcquireOptions{Owner: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Token != 2 {
+		t.Errorf("token = %d, want 2", b.Token)
+	}
+}
+
+func TestExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	l := newTestLocker(clock, nil)
+	a, _ := l.Acquire(ctx, "job", &AcquireOptions{Owner: "a", TTL: time.Second})
+	clock.Add(2 * time.Second)
+	if h, err := l.Holder(ctx, "job"); err != nil || h != nil {
+		t.Errorf("Holder = %+v, %v", h, err)
+	}
+	b, err := l.Acquire(ctx, "job", &AcquireOptions{Owner: "b"})
+	if err != nil || b.Token != 2 {
+		t.Fatalf("Acquire = %+v, %v", b, err)
+	}
+	if _, err := l.Renew(ctx, a, time.Second); !errors.Is(err, ErrLeaseLost) {
+		t.Errorf("want ErrLeaseLost for the stale lease, got %v", err)
+	}
+}
+
+func TestTokenPersisted(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	storage := &memoryStorage{values: map[string]string{}}
+	l := newTestLocker(clock, storage)
+	a, _ := l.Acquire(ctx, "job", nil)
+	l.Release(ctx, a)
+	// the object is evicted, and created again with the same storage.
+	evicted := newTestLocker(clock, storage)
+	b, err := evicted.Acquire(ctx, "job", nil)
+	if err != nil || b.Token != 2 {
+		t.Errorf("Acquire = %+v, %v", b, err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Now()}
+	l := newTestLocker(clock, nil)
+	a, _ := l.Acquire(ctx, "job", &AcquireOptions{Owner: "a"})
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		l.Release(ctx, a)
+	}()
+	if _, err := l.Acquire(ctx, "job", &AcquireOptions{Owner: "b", Wait: time.Second, RetryInterval: 10 * time.Millisecond}); err != nil {
+		t.Errorf("Acquire with Wait: %v", err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	l := newTestLocker(clock, nil)
+	ran := false
+	err := l.Do(ctx, "job", &AcquireOptions{Owner: "leader"}, func(ctx context.Context, lease *Lease) error {
+		ran = true
+		if err := l.Do(ctx, "job", &AcquireOptions{Owner: "follower"}, func(ctx context.Context, lease *Lease) error {
+			t.Error("follower must not run")
+			return nil
+		}); !errors.Is(err, ErrLocked) {
+			t.Errorf("want ErrLocked, got %v", err)
+		}
+		return nil
+	})
+	if err != nil || !ran {
+		t.Errorf("Do = %v, ran = %v", err, ran)
+	}
+	if h, _ := l.Holder(ctx, "job"); h != nil {
+		t.Errorf("lock is not released: %+v", h)
+	}
+}
+
+func TestDoLost(t *testing.T) {
+	ctx := context.Background()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	l := newTestLocker(clock, nil)
+	err := l.Do(ctx, "job", &AcquireOptions{TTL: 30 * time.Millisecond}, func(ctx context.Context, lease *Lease) error {
+		// the lease expires before it's renewed.
+		clock.Add(time.Second)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("context is not canceled")
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package lock
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Storage persists the lease in the object, so fencing tokens keep increasing after the object is evicted.
+// Use DurableObjectStorage to adapt *durableobject.Storage.
+type Storage interface {
+	// Get returns the value of the key. if the key doesn't exist, returns an empty string.
+	Get(key string) (string, error)
+	Put(key, value string) error
+}
+
+// storageKey is the key of the lease in the storage.
+const storageKey = "lock"
+
+// NewDurableObject returns the handler of the Durable Object class used by Locker.
+//
+//	durableobject.Register("Lock", func(ctx context.Context, state *durableobject.State) durableobject.DurableObject {
+//		return lock.NewDurableObject(lock.DurableObjectStorage(state.Storage()))
+//	})
+func NewDurableObject(storage Storage) http.Handler {
+	return &durableObject{storage: storage, now: time.Now}
+}
+
+type durableObject struct {
+	storage Storage
+	now     func() time.Time
+
+	mu     sync.Mutex
+	state  *state
+	loaded bool
+}
+
+func (o *durableObject) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	var cmd command
+	if err := json.NewDecoder(req.Body).Decode(&cmd); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.load(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	now := o.now().UnixMilli()
+	s := o.state
+	held := s.Owner != "" && now < s.ExpiresAt
+	switch cmd.Op {
+	case opGet:
+		if !held {
+			http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+			return
+		}
+		writeState(w, s)
+		return
+	case opAcquire:
+		if held && s.Owner != cmd.Owner {
+			http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+			return
+		}
+		if !held {
+			s.Owner = cmd.Owner
+			s.Token++
+		}
+		s.ExpiresAt = now + cmd.TTLMs
+	case opRenew, opRelease:
+		if !held || s.Owner != cmd.Owner || s.Token != cmd.Token {
+			http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+			return
+		}
+		if cmd.Op == opRenew {
+			s.ExpiresAt = now + cmd.TTLMs
+		} else {
+			// the token is kept, so the next acquisition has the greater token.
+			s.Owner = ""
+			s.ExpiresAt = 0
+		}
+	default:
+		http.Error(w, "unknown op", http.StatusBadRequest)
+		return
+	}
+	if err := o.save(); err != nil {
+		// the state is loaded again, since the change may not be persisted.
+		o.loaded = false
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeState(w, s)
+}
+
+func (o *durableObject) load() error {
+	if o.loaded {
+		return nil
+	}
+	v, err := o.storage.Get(storageKey)
+	if err != nil {
+		return err
+	}
+	s := &state{}
+	if v != "" {
+		if err := json.Unmarshal([]byte(v), s); err != nil {
+			return err
+		}
+	}
+	o.state = s
+	o.loaded = true
+	return nil
+}
+
+func (o *durableObject) save() error {
+	b, _ := json.Marshal(o.state)
+	return o.storage.Put(storageKey, string(b))
+}
+
+func writeState(w http.ResponseWriter, s *state) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
@@ -0,0 +1,34 @@
+//go:build js && wasm
+
+package lock
+
+import (
+	"fmt"
+
+	"github.com/syumai/workers/cloudflare/durableobject"
+)
+
+// DurableObjectStorage adapts the storage of the Durable Object to Storage.
+func DurableObjectStorage(s *durableobject.Storage) Storage {
+	return &durableObjectStorage{s: s}
+}
+
+type durableObjectStorage struct {
+	s *durableobject.Storage
+}
+
+func (s *durableObjectStorage) Get(key string) (string, error) {
+	v, err := s.s.Get(key, nil)
+	if err != nil || v == nil {
+		return "", err
+	}
+	str, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("lock: unexpected value of type %T in storage", v)
+	}
+	return str, nil
+}
+
+func (s *durableObjectStorage) Put(key, value string) error {
+	return s.s.Put(key, value, nil)
+}
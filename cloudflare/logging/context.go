@@ -0,0 +1,44 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+type attrsKey struct{}
+
+// WithAttrs returns the context with the attributes, which are written to all records logged with the context by Handler.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	parent := contextAttrs(ctx)
+	merged := make([]slog.Attr, 0, len(parent)+len(attrs))
+	merged = append(merged, parent...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// Middleware returns the middleware which adds request-scoped attributes to the context of requests.
+//   - `ray` (the cf-ray header), `method` and `path` are added.
+//   - Use the context-aware methods of slog.Logger (e.g. InfoContext) with the context of the request to write the attributes.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+		}
+		if ray := req.Header.Get("Cf-Ray"); ray != "" {
+			attrs = append(attrs, slog.String("ray", ray))
+		}
+		next.ServeHTTP(w, req.WithContext(WithAttrs(req.Context(), attrs...)))
+	})
+}
@@ -0,0 +1,6 @@
+// Package logging provides slog.Handler which writes structured logs to the console of Cloudflare Workers.
+//   - Records are written as JavaScript objects by console.log, console.warn and console.error,
+//     so their fields can be queried in Workers Logs and Logpush.
+//   - This package requires Go 1.21 or later.
+//   - https://developers.cloudflare.com/workers/observability/logs/workers-logs/
+package logging
@@ -0,0 +1,187 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var console = jsutil.LazyGlobal("console")
+
+// HandlerOptions represents the options of Handler.
+type HandlerOptions struct {
+	// Level is the minimum level of records to be written. The default value is slog.LevelInfo.
+	Level slog.Leveler
+	// AddSource adds the source position of the log statement as `source`.
+	AddSource bool
+}
+
+// Handler is slog.Handler which writes records as JavaScript objects to the console.
+//   - Records of slog.LevelError and above are written by console.error, slog.LevelWarn by console.warn,
+//     and others by console.log (console.debug for slog.LevelDebug and below).
+//   - Attributes added to the context by WithAttrs and Middleware are written at the top level of records.
+type Handler struct {
+	opts  HandlerOptions
+	attrs []groupedAttr
+	// groups are names of groups opened by WithGroup.
+	groups []string
+}
+
+// groupedAttr is an attribute added by slog.Handler.WithAttrs in the groups.
+type groupedAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a new Handler.
+func NewHandler(opts *HandlerOptions) *Handler {
+	h := &Handler{}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the level is enabled.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle writes the record to the console.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	obj := jsutil.NewObject()
+	obj.Set("level", r.Level.String())
+	obj.Set("message", r.Message)
+	if !r.Time.IsZero() {
+		obj.Set("time", r.Time.UTC().Format(time.RFC3339Nano))
+	}
+	if h.opts.AddSource && r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		f, _ := frames.Next()
+		obj.Set("source", fmt.Sprintf("%s:%d", f.File, f.Line))
+	}
+	for _, a := range contextAttrs(ctx) {
+		setAttr(obj, nil, a)
+	}
+	for _, ga := range h.attrs {
+		setAttr(obj, ga.groups, ga.attr)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		setAttr(obj, h.groups, a)
+		return true
+	})
+	method := "log"
+	switch {
+	case r.Level >= slog.LevelError:
+		method = "error"
+	case r.Level >= slog.LevelWarn:
+		method = "warn"
+	case r.Level <= slog.LevelDebug:
+		method = "debug"
+	}
+	console().Call(method, obj)
+	return nil
+}
+
+// WithAttrs returns a new Handler with the attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = make([]groupedAttr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(h2.attrs, h.attrs)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{groups: h.groups, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup returns a new Handler which writes following attributes in the group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = make([]string, len(h.groups), len(h.groups)+1)
+	copy(h2.groups, h.groups)
+	h2.groups = append(h2.groups, name)
+	return &h2
+}
+
+// setAttr sets the attribute to the object in the groups.
+// Objects of groups are created only when non-empty attributes are set, so empty groups are omitted.
+func setAttr(obj js.Value, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, ga := range attrs {
+			setAttr(obj, groups, ga)
+		}
+		return
+	}
+	for _, g := range groups {
+		child := obj.Get(g)
+		if child.Type() != js.TypeObject {
+			child = jsutil.NewObject()
+			obj.Set(g, child)
+		}
+		obj = child
+	}
+	obj.Set(a.Key, toJSValue(a.Value))
+}
+
+// toJSValue converts slog.Value to the JavaScript value.
+//   - Durations are written in milliseconds, and times are written in RFC 3339.
+func toJSValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		return v.Float64()
+	case slog.KindBool:
+		return v.Bool()
+	case slog.KindDuration:
+		return float64(v.Duration()) / float64(time.Millisecond)
+	case slog.KindTime:
+		return v.Time().UTC().Format(time.RFC3339Nano)
+	}
+	switch a := v.Any().(type) {
+	case error:
+		return a.Error()
+	case fmt.Stringer:
+		return a.String()
+	default:
+		jsValue, err := jsutil.ToJSValue(a)
+		if err != nil {
+			return fmt.Sprint(a)
+		}
+		return jsValue
+	}
+}
@@ -0,0 +1,110 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+type logged struct {
+	method string
+	obj    string
+}
+
+// captureConsole replaces console with the fake which records written objects as JSON.
+func captureConsole(t *testing.T) *[]logged {
+	t.Helper()
+	var records []logged
+	fake := jsutil.NewObject()
+	var funcs []js.Func
+	for _, method := range []string{"log", "debug", "warn", "error"} {
+		method := method
+		fn := js.FuncOf(func(_ js.Value, args []js.Value) any {
+			records = append(records, logged{method: method, obj: jsutil.Global.Get("JSON").Call("stringify", args[0]).String()})
+			return nil
+		})
+		funcs = append(funcs, fn)
+		fake.Set(method, fn)
+	}
+	orig := console
+	console = func() js.Value { return fake }
+	t.Cleanup(func() {
+		console = orig
+		for _, fn := range funcs {
+			fn.Release()
+		}
+	})
+	return &records
+}
+
+func TestHandler(t *testing.T) {
+	records := captureConsole(t)
+	logger := slog.New(NewHandler(nil)).With("service", "api").WithGroup("req")
+	ctx := WithAttrs(context.Background(), slog.String("ray", "abc"))
+	logger.ErrorContext(ctx, "failed", "status", 500, "err", errors.New("boom"), "took", 1500*time.Microsecond, slog.Group("empty"))
+	logger.Debug("ignored")
+	if len(*records) != 1 {
+		t.Fatalf("want 1 record, got %d", len(*records))
+	}
+	got := (*records)[0]
+	if got.method != "error" {
+		t.Errorf("want console.error, got console.%s", got.method)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(got.obj), &obj); err != nil {
+		t.Fatal(err)
+	}
+	delete(obj, "time")
+	want := `{"level":"ERROR","message":"failed","ray":"abc","req":{"err":"boom","status":500,"took":1.5},"service":"api"}`
+	if s := jsonMarshal(obj); s != want {
+		t.Errorf("want %s, got %s", want, s)
+	}
+}
+
+func TestHandlerLevel(t *testing.T) {
+	records := captureConsole(t)
+	logger := slog.New(NewHandler(&HandlerOptions{Level: slog.LevelDebug}))
+	logger.Debug("debug")
+	logger.Warn("warn")
+	logger.Info("info")
+	var methods []string
+	for _, r := range *records {
+		methods = append(methods, r.method)
+	}
+	if got, want := jsonMarshal(methods), `["debug","warn","log"]`; got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var attrs []slog.Attr
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attrs = contextAttrs(req.Context())
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/items?id=1", nil)
+	req.Header.Set("Cf-Ray", "abc-NRT")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	got := map[string]string{}
+	for _, a := range attrs {
+		got[a.Key] = a.Value.String()
+	}
+	want := map[string]string{"method": "GET", "path": "/items", "ray": "abc-NRT"}
+	if jsonMarshal(got) != jsonMarshal(want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func jsonMarshal(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
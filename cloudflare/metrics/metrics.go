@@ -0,0 +1,292 @@
+// Package metrics provides counters, gauges and histograms which are aggregated in memory,
+// and flushed as data points of Workers Analytics Engine.
+//
+// Each series (a metric and its label values) is written as one data point per flush with the following layout,
+// so metrics can be queried by the SQL API without an external push gateway.
+//   - blob1 is the name of the metric, blob2 is the type (`counter`, `gauge` or `histogram`),
+//     and blob3 and later are the label values. index1 is the name of the metric.
+//   - Counter: double1 is the sum of increments since the last flush.
+//   - Gauge: double1 is the last value set.
+//   - Histogram: double1 is the count, double2 is the sum, double3 is the minimum and double4 is the maximum of observations
+//     since the last flush. double5 and later are the cumulative counts of observations less than or equal to the buckets.
+//   - Analytics Engine samples data points, so counts and sums should be weighted by `_sample_interval` in queries.
+//   - https://developers.cloudflare.com/analytics/analytics-engine/sql-api/
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/analyticsengine"
+)
+
+// MaxLabels is the maximum number of label values of a series. blob1 and blob2 are used by the name and the type.
+const MaxLabels = analyticsengine.MaxBlobs - 2
+
+// MaxBuckets is the maximum number of buckets of a histogram. double1 to double4 are used by the summary.
+const MaxBuckets = analyticsengine.MaxDoubles - 4
+
+// DataPointWriter writes data points. It is implemented by *analyticsengine.Dataset.
+type DataPointWriter interface {
+	WriteDataPoint(p *analyticsengine.DataPoint) error
+}
+
+// Options represents the options of NewRegistry.
+type Options struct {
+	// FlushInterval is the minimum interval between flushes by Middleware.
+	// if FlushInterval is 0, metrics are flushed after every request.
+	//   - Metrics are held in memory of the Go instance, which may be created again for a later event.
+	//     Metrics aggregated after the last flush are lost in that case, so keep the interval short.
+	FlushInterval time.Duration
+}
+
+// Registry holds metrics and aggregates their values until Flush.
+type Registry struct {
+	flushInterval time.Duration
+
+	mu        sync.Mutex
+	metrics   map[string]*metric
+	names     []string
+	lastFlush time.Time
+}
+
+// NewRegistry returns the empty Registry.
+func NewRegistry(opts *Options) *Registry {
+	r := &Registry{metrics: map[string]*metric{}}
+	if opts != nil {
+		r.flushInterval = opts.FlushInterval
+	}
+	return r
+}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+type metric struct {
+	name    string
+	typ     string
+	buckets []float64
+	series  map[string]*series
+	keys    []string
+}
+
+// series holds the values of a metric with the label values aggregated since the last flush.
+type series struct {
+	labels []string
+	// value is the sum of counters, or the last value of gauges.
+	value   float64
+	updated bool
+	// count, sum, min, max and bucketCounts are the summary of histograms.
+	count, sum, min, max float64
+	bucketCounts         []float64
+}
+
+// register returns the metric of the name, or registers a new one.
+//   - This panics if the metric is already registered with another type or buckets.
+func (r *Registry) register(name, typ string, buckets []float64) *metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m, ok := r.metrics[name]; ok {
+		if m.typ != typ || !equalBuckets(m.buckets, buckets) {
+			panic(fmt.Errorf("metrics: %s is already registered as another %s", name, m.typ))
+		}
+		return m
+	}
+	m := &metric{name: name, typ: typ, buckets: buckets, series: map[string]*series{}}
+	r.metrics[name] = m
+	r.names = append(r.names, name)
+	return m
+}
+
+// update calls fn with the series of the label values under the lock of the registry.
+func (r *Registry) update(m *metric, labels []string, fn func(s *series)) {
+	if len(labels) > MaxLabels {
+		panic(fmt.Errorf("metrics: %d labels given to %s, the limit is %d", len(labels), m.name, MaxLabels))
+	}
+	key := strings.Join(labels, "\x00")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labels: append([]string(nil), labels...)}
+		m.series[key] = s
+		m.keys = append(m.keys, key)
+	}
+	s.updated = true
+	fn(s)
+}
+
+// Counter is a metric whose value only increases. The sum of increments is written on each flush.
+type Counter struct {
+	r *Registry
+	m *metric
+}
+
+// Counter returns the Counter of the name. Calls with the same name return the same Counter.
+func (r *Registry) Counter(name string) *Counter {
+	return &Counter{r: r, m: r.register(name, typeCounter, nil)}
+}
+
+// Inc adds 1 to the series of the label values.
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add adds v to the series of the label values. v must not be negative.
+func (c *Counter) Add(v float64, labels ...string) {
+	if v < 0 {
+		panic(fmt.Errorf("metrics: negative value %v added to counter %s", v, c.m.name))
+	}
+	c.r.update(c.m, labels, func(s *series) {
+		s.value += v
+	})
+}
+
+// Gauge is a metric whose value can go up and down. The last value is written on each flush.
+type Gauge struct {
+	r *Registry
+	m *metric
+}
+
+// Gauge returns the Gauge of the name. Calls with the same name return the same Gauge.
+func (r *Registry) Gauge(name string) *Gauge {
+	return &Gauge{r: r, m: r.register(name, typeGauge, nil)}
+}
+
+// Set sets the value of the series of the label values.
+func (g *Gauge) Set(v float64, labels ...string) {
+	g.r.update(g.m, labels, func(s *series) {
+		s.value = v
+	})
+}
+
+// Add adds v to the value of the series of the label values. v can be negative.
+func (g *Gauge) Add(v float64, labels ...string) {
+	g.r.update(g.m, labels, func(s *series) {
+		s.value += v
+	})
+}
+
+// Histogram is a metric which counts observations in buckets. The summary since the last flush is written on each flush.
+type Histogram struct {
+	r *Registry
+	m *metric
+}
+
+// DefaultBuckets are the buckets of histograms for durations in milliseconds.
+var DefaultBuckets = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// Histogram returns the Histogram of the name with the upper bounds of buckets. Calls with the same name return the same Histogram.
+//   - if buckets is empty, DefaultBuckets is used.
+//   - This panics if more than MaxBuckets buckets are given.
+func (r *Registry) Histogram(name string, buckets []float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	if len(buckets) > MaxBuckets {
+		panic(fmt.Errorf("metrics: %d buckets given to %s, the limit is %d", len(buckets), name, MaxBuckets))
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{r: r, m: r.register(name, typeHistogram, buckets)}
+}
+
+// Observe adds the observation v to the series of the label values.
+func (h *Histogram) Observe(v float64, labels ...string) {
+	h.r.update(h.m, labels, func(s *series) {
+		if s.count == 0 {
+			s.min, s.max = v, v
+			s.bucketCounts = make([]float64, len(h.m.buckets))
+		}
+		s.count++
+		s.sum += v
+		s.min = math.Min(s.min, v)
+		s.max = math.Max(s.max, v)
+		for i, le := range h.m.buckets {
+			if v <= le {
+				s.bucketCounts[i]++
+			}
+		}
+	})
+}
+
+// ObserveDuration adds d in milliseconds to the series of the label values.
+func (h *Histogram) ObserveDuration(d time.Duration, labels ...string) {
+	h.Observe(float64(d)/float64(time.Millisecond), labels...)
+}
+
+// Collect returns data points of series updated since the last call, and resets counters and histograms.
+//   - Flush writes these data points. Collect is useful to write them by other ways.
+func (r *Registry) Collect() []*analyticsengine.DataPoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var points []*analyticsengine.DataPoint
+	for _, name := range r.names {
+		m := r.metrics[name]
+		for _, key := range m.keys {
+			s := m.series[key]
+			if !s.updated {
+				continue
+			}
+			p := &analyticsengine.DataPoint{
+				Blobs:   append([]string{m.name, m.typ}, s.labels...),
+				Indexes: []string{m.name},
+			}
+			switch m.typ {
+			case typeCounter:
+				p.Doubles = []float64{s.value}
+				s.value = 0
+			case typeGauge:
+				p.Doubles = []float64{s.value}
+			case typeHistogram:
+				p.Doubles = append([]float64{s.count, s.sum, s.min, s.max}, s.bucketCounts...)
+				s.count, s.sum = 0, 0
+			}
+			s.updated = false
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Flush writes data points of series updated since the last flush to w.
+//   - Data points which exceed the limits of Analytics Engine (e.g. too large label values) are skipped,
+//     and the first error is returned after writing others.
+func (r *Registry) Flush(w DataPointWriter) error {
+	r.mu.Lock()
+	r.lastFlush = time.Now()
+	r.mu.Unlock()
+	var firstErr error
+	for _, p := range r.Collect() {
+		if err := w.WriteDataPoint(p); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("metrics: error writing %s: %w", p.Blobs[0], err)
+		}
+	}
+	return firstErr
+}
+
+// flushDue reports whether FlushInterval has elapsed since the last flush.
+func (r *Registry) flushDue() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.flushInterval == 0 || time.Since(r.lastFlush) >= r.flushInterval
+}
+
+func equalBuckets(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/syumai/workers/cloudflare/analyticsengine"
+)
+
+type fakeWriter struct {
+	points []*analyticsengine.DataPoint
+}
+
+func (w *fakeWriter) WriteDataPoint(p *analyticsengine.DataPoint) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	w.points = append(w.points, p)
+	return nil
+}
+
+func TestRegistry_Flush(t *testing.T) {
+	r := NewRegistry(nil)
+	requests := r.Counter("requests")
+	requests.Inc("GET", "200")
+	requests.Add(2, "GET", "200")
+	requests.Inc("POST", "500")
+	r.Gauge("connections").Set(3)
+	latency := r.Histogram("latency_ms", []float64{100, 10})
+	latency.Observe(5)
+	latency.Observe(50)
+	latency.Observe(500)
+
+	w := &fakeWriter{}
+	if err := r.Flush(w); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	want := []*analyticsengine.DataPoint{
+		{Blobs: []string{"requests", "counter", "GET", "200"}, Doubles: []float64{3}, Indexes: []string{"requests"}},
+		{Blobs: []string{"requests", "counter", "POST", "500"}, Doubles: []float64{1}, Indexes: []string{"requests"}},
+		{Blobs: []string{"connections", "gauge"}, Doubles: []float64{3}, Indexes: []string{"connections"}},
+		{Blobs: []string{"latency_ms", "histogram"}, Doubles: []float64{3, 555, 5, 500, 1, 2}, Indexes: []string{"latency_ms"}},
+	}
+	if !reflect.DeepEqual(w.points, want) {
+		t.Errorf("unexpected data points:\n got: %+v\nwant: %+v", w.points, want)
+	}
+
+	// only series updated after the last flush are written.
+	requests.Inc("GET", "200")
+	w = &fakeWriter{}
+	if err := r.Flush(w); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	want = []*analyticsengine.DataPoint{
+		{Blobs: []string{"requests", "counter", "GET", "200"}, Doubles: []float64{1}, Indexes: []string{"requests"}},
+	}
+	if !reflect.DeepEqual(w.points, want) {
+		t.Errorf("unexpected data points after flush:\n got: %+v\nwant: %+v", w.points, want)
+	}
+}
+
+func TestRegistry_FlushError(t *testing.T) {
+	r := NewRegistry(nil)
+	c := r.Counter("requests")
+	c.Inc(strings.Repeat("a", analyticsengine.MaxBlobsSize))
+	c.Inc("ok")
+	w := &fakeWriter{}
+	if err := r.Flush(w); !errors.Is(err, analyticsengine.ErrBlobsTooLarge) {
+		t.Errorf("want ErrBlobsTooLarge, got %v", err)
+	}
+	if len(w.points) != 1 {
+		t.Errorf("want valid data point to be written, got %d points", len(w.points))
+	}
+}
+
+func TestRegistry_RegisterConflict(t *testing.T) {
+	r := NewRegistry(nil)
+	if r.Counter("requests").m != r.Counter("requests").m {
+		t.Error("want the same metric for the same name")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic for the name registered with another type")
+		}
+	}()
+	r.Gauge("requests")
+}
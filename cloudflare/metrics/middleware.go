@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/analyticsengine"
+)
+
+// Middleware returns the middleware which flushes metrics of the registry to the dataset after requests.
+//   - varName is the binding name of the Analytics Engine dataset.
+//   - Metrics are flushed after the response has been sent, by `cloudflare.WaitUntil`.
+//     if FlushInterval is set, metrics are flushed only when the interval has elapsed since the last flush.
+//   - Errors are written by the standard logger. To flush metrics on a schedule instead (e.g. by Cron Triggers),
+//     call Flush with the dataset directly.
+func Middleware(r *Registry, varName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req)
+			if !r.flushDue() {
+				return
+			}
+			ctx := req.Context()
+			cloudflare.WaitUntil(ctx, func() {
+				dataset, err := analyticsengine.NewDataset(ctx, varName)
+				if err != nil {
+					log.Printf("metrics: %v", err)
+					return
+				}
+				if err := r.Flush(dataset); err != nil {
+					log.Printf("%v", err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package pages provides the EventContext of Cloudflare Pages Functions.
+//   - Serve the handler by `workers.Serve`, and export `onRequest` of build/shim.mjs from the function file.
+//   - The same binary can be used for middleware (`_middleware.js`); call Next or use NextHandler to run the next function.
+//   - Environment variables and bindings can be used in the same way as Workers (e.g. `cloudflare.Getenv`).
+//   - https://developers.cloudflare.com/pages/functions/api-reference/#eventcontext
+package pages
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// eventContext returns the EventContext, which is given as the execution context of the runtime context.
+func eventContext(ctx context.Context) js.Value {
+	return cfruntimecontext.GetExecutionContext(ctx)
+}
+
+// Params returns the params of the dynamic route (e.g. `[id].js` and `[[path]].js`).
+//   - Values of single segment params (e.g. `[id]`) have one element.
+//   - Values of catch-all params (e.g. `[[path]]`) have elements for each segment.
+//   - This function panics when a runtime context is not found.
+func Params(ctx context.Context) map[string][]string {
+	params := map[string][]string{}
+	obj := eventContext(ctx).Get("params")
+	if obj.Type() != js.TypeObject {
+		return params
+	}
+	keys := jsutil.ObjectClass().Call("keys", obj)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		v := obj.Get(key)
+		if v.Type() == js.TypeString {
+			params[key] = []string{v.String()}
+			continue
+		}
+		values := make([]string, v.Length())
+		for j := range values {
+			values[j] = v.Index(j).String()
+		}
+		params[key] = values
+	}
+	return params
+}
+
+// Param returns the param of the dynamic route. Segments of catch-all params are joined by `/`.
+//   - if the param doesn't exist, returns empty string.
+//   - This function panics when a runtime context is not found.
+func Param(ctx context.Context, name string) string {
+	return strings.Join(Params(ctx)[name], "/")
+}
+
+// FunctionPath returns the path of the function which handles the request (e.g. `/api/[[routes]]`).
+//   - This function panics when a runtime context is not found.
+func FunctionPath(ctx context.Context) string {
+	return jsutil.MaybeString(eventContext(ctx).Get("functionPath"))
+}
+
+// GetData returns the value of the data shared between middleware and functions for the request.
+//   - The value is converted to Go value by `jsutil.ToGoValue`. if the key doesn't exist, returns nil.
+//   - This function panics when a runtime context is not found.
+func GetData(ctx context.Context, key string) any {
+	data := eventContext(ctx).Get("data")
+	if data.Type() != js.TypeObject {
+		return nil
+	}
+	v := data.Get(key)
+	if v.IsUndefined() {
+		return nil
+	}
+	return jsutil.ToGoValue(v)
+}
+
+// SetData sets the value of the data shared between middleware and functions for the request.
+//   - The value is converted to JavaScript value by `jsutil.ToJSValue`.
+//   - This function panics when a runtime context is not found.
+func SetData(ctx context.Context, key string, value any) error {
+	v, err := jsutil.ToJSValue(value)
+	if err != nil {
+		return fmt.Errorf("pages: error converting data: %w", err)
+	}
+	eventContext(ctx).Get("data").Set(key, v)
+	return nil
+}
+
+// Next runs the next function (or serves the static asset) for the request, and returns its response.
+//   - req must have the context of the request given to the handler.
+//   - The body of req must not have been read.
+//   - This function panics when a runtime context is not found.
+func Next(req *http.Request) (*http.Response, error) {
+	next := eventContext(req.Context()).Get("next")
+	if next.Type() != js.TypeFunction {
+		return nil, fmt.Errorf("pages: next is not available on the execution context")
+	}
+	var promise js.Value
+	if err := jsutil.Try(func() {
+		promise = next.Invoke(jshttp.ToJSRequest(req))
+	}); err != nil {
+		return nil, err
+	}
+	jsRes, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return jshttp.ToResponse(jsRes)
+}
+
+// NextHandler is http.Handler which serves the response of Next.
+// This is useful to terminate middleware chains of Go, e.g. `workers.Serve(auth(pages.NextHandler))`.
+var NextHandler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	res, err := Next(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer res.Body.Close()
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	io.Copy(w, res.Body)
+})
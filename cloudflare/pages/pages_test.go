@@ -0,0 +1,88 @@
+package pages
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// newContext returns the context with the runtime context which holds the EventContext.
+func newContext(eventCtx js.Value) context.Context {
+	rc := jsutil.NewObject()
+	rc.Set("env", jsutil.NewObject())
+	rc.Set("ctx", eventCtx)
+	return runtimecontext.New(context.Background(), rc)
+}
+
+func TestParams(t *testing.T) {
+	eventCtx := jsutil.NewObject()
+	params := jsutil.NewObject()
+	params.Set("id", "42")
+	path := jsutil.ArrayClass().New()
+	path.Call("push", "a", "b")
+	params.Set("path", path)
+	eventCtx.Set("params", params)
+	eventCtx.Set("functionPath", "/users/[id]/[[path]]")
+	ctx := newContext(eventCtx)
+
+	want := map[string][]string{"id": {"42"}, "path": {"a", "b"}}
+	if got := Params(ctx); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got := Param(ctx, "path"); got != "a/b" {
+		t.Errorf("want a/b, got %s", got)
+	}
+	if got := Param(ctx, "missing"); got != "" {
+		t.Errorf("want empty string, got %s", got)
+	}
+	if got := FunctionPath(ctx); got != "/users/[id]/[[path]]" {
+		t.Errorf("unexpected function path: %s", got)
+	}
+}
+
+func TestData(t *testing.T) {
+	eventCtx := jsutil.NewObject()
+	eventCtx.Set("data", jsutil.NewObject())
+	ctx := newContext(eventCtx)
+	if err := SetData(ctx, "user", "gopher"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := GetData(ctx, "user"); got != "gopher" {
+		t.Errorf("want gopher, got %v", got)
+	}
+	if got := GetData(ctx, "missing"); got != nil {
+		t.Errorf("want nil, got %v", got)
+	}
+}
+
+func TestNextHandler(t *testing.T) {
+	eventCtx := jsutil.NewObject()
+	var gotURL string
+	next := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		gotURL = args[0].Get("url").String()
+		init := jsutil.NewObject()
+		init.Set("status", http.StatusAccepted)
+		return jsutil.PromiseClass().Call("resolve", jsutil.ResponseClass().New("from next", init))
+	})
+	defer next.Release()
+	eventCtx.Set("next", next)
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/page", nil).WithContext(newContext(eventCtx))
+	rec := httptest.NewRecorder()
+	NextHandler.ServeHTTP(rec, req)
+	if gotURL != "https://example.com/page" {
+		t.Errorf("unexpected URL given to next: %s", gotURL)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("want status 202, got %d", rec.Code)
+	}
+	if body, _ := io.ReadAll(rec.Body); string(body) != "from next" {
+		t.Errorf("unexpected body: %s", body)
+	}
+}
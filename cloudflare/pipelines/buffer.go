@@ -0,0 +1,159 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrRecordTooLarge is returned when the encoded record is larger than MaxBytes of the Buffer.
+var ErrRecordTooLarge = errors.New("pipelines: record is too large")
+
+const (
+	defaultMaxRecords  = 100
+	defaultMaxBytes    = 1 << 20
+	defaultMaxInFlight = 4
+)
+
+// BufferOptions represents the options of Buffer.
+type BufferOptions struct {
+	// MaxRecords is the maximum number of records in a batch. The default value is 100.
+	MaxRecords int
+	// MaxBytes is the maximum total size of encoded records in a batch. The default value is 1 MiB.
+	MaxBytes int
+	// MaxInFlight is the maximum number of batches being sent at once. The default value is 4.
+	// Add blocks while MaxInFlight batches are being sent, so records are not buffered unboundedly.
+	MaxInFlight int
+}
+
+// Buffer buffers records, and sends them to the pipeline in batches.
+//   - Buffer is safe for concurrent use.
+//   - Flush must be called before the worker returns the response, or in `cloudflare.WaitUntil`.
+type Buffer struct {
+	pipeline *Pipeline
+	batcher  *batcher
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	// err is the first error occurred on sending batches.
+	err error
+}
+
+// NewBuffer returns Buffer which sends records to the pipeline.
+func NewBuffer(p *Pipeline, opts *BufferOptions) *Buffer {
+	if opts == nil {
+		opts = &BufferOptions{}
+	}
+	maxInFlight := opts.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+	return &Buffer{
+		pipeline: p,
+		batcher:  newBatcher(opts.MaxRecords, opts.MaxBytes),
+		inFlight: make(chan struct{}, maxInFlight),
+	}
+}
+
+// Add adds the record to the buffer. The record is encoded by encoding/json.
+//   - When the batch is full, the batch is sent in background.
+//   - Errors of sending are returned by Flush.
+func (b *Buffer) Add(record any) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("pipelines: error encoding record: %w", err)
+	}
+	b.mu.Lock()
+	batch, err := b.batcher.add(data)
+	b.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if batch != nil {
+		b.send(batch)
+	}
+	return nil
+}
+
+// Flush sends the buffered records, and waits until all batches are sent.
+//   - returns the first error occurred on sending batches since the last Flush.
+func (b *Buffer) Flush() error {
+	b.mu.Lock()
+	batch := b.batcher.flush()
+	b.mu.Unlock()
+	if batch != nil {
+		b.send(batch)
+	}
+	b.wg.Wait()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// send sends the batch in background. send blocks while MaxInFlight batches are being sent.
+func (b *Buffer) send(batch [][]byte) {
+	b.inFlight <- struct{}{}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer func() { <-b.inFlight }()
+		if err := b.pipeline.sendEncoded(batch); err != nil {
+			b.mu.Lock()
+			if b.err == nil {
+				b.err = err
+			}
+			b.mu.Unlock()
+		}
+	}()
+}
+
+// batcher splits encoded records into batches.
+type batcher struct {
+	maxRecords int
+	maxBytes   int
+	records    [][]byte
+	size       int
+}
+
+func newBatcher(maxRecords, maxBytes int) *batcher {
+	if maxRecords <= 0 {
+		maxRecords = defaultMaxRecords
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+	return &batcher{maxRecords: maxRecords, maxBytes: maxBytes}
+}
+
+// add adds the record, and returns the batch which is ready to be sent.
+func (b *batcher) add(record []byte) ([][]byte, error) {
+	if len(record) > b.maxBytes {
+		return nil, ErrRecordTooLarge
+	}
+	var ready [][]byte
+	if b.size+len(record) > b.maxBytes {
+		ready = b.flush()
+	}
+	b.records = append(b.records, record)
+	b.size += len(record)
+	// the batch is flushed by the count only if it was not flushed by the size,
+	// since a batch flushed by the size leaves a single record, which is checked on the previous add when maxRecords is 1.
+	if ready == nil && len(b.records) >= b.maxRecords {
+		ready = b.flush()
+	}
+	return ready, nil
+}
+
+// flush returns the buffered records as a batch. returns nil if no records are buffered.
+func (b *batcher) flush() [][]byte {
+	if len(b.records) == 0 {
+		return nil
+	}
+	batch := b.records
+	b.records = nil
+	b.size = 0
+	return batch
+}
@@ -0,0 +1,86 @@
+package pipelines
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBatcher(t *testing.T) {
+	tests := map[string]struct {
+		maxRecords  int
+		maxBytes    int
+		records     []string
+		wantBatches [][]string
+		wantRest    []string
+	}{
+		"by count": {
+			maxRecords:  2,
+			maxBytes:    100,
+			records:     []string{"a", "b", "c", "d", "e"},
+			wantBatches: [][]string{{"a", "b"}, {"c", "d"}},
+			wantRest:    []string{"e"},
+		},
+		"by size": {
+			maxRecords:  10,
+			maxBytes:    5,
+			records:     []string{"aa", "bb", "cc", "dddd", "e"},
+			wantBatches: [][]string{{"aa", "bb"}, {"cc"}},
+			wantRest:    []string{"dddd", "e"},
+		},
+		"single record batches": {
+			maxRecords:  1,
+			maxBytes:    5,
+			records:     []string{"aaa", "bbb"},
+			wantBatches: [][]string{{"aaa"}, {"bbb"}},
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			b := newBatcher(tc.maxRecords, tc.maxBytes)
+			var got [][]string
+			for _, r := range tc.records {
+				batch, err := b.add([]byte(r))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if batch != nil {
+					got = append(got, toStrings(batch))
+				}
+			}
+			if strings.Join(flatten(got), "|") != strings.Join(flatten(tc.wantBatches), "|") || len(got) != len(tc.wantBatches) {
+				t.Errorf("want batches %v, got %v", tc.wantBatches, got)
+			}
+			rest := toStrings(b.flush())
+			if strings.Join(rest, "|") != strings.Join(tc.wantRest, "|") {
+				t.Errorf("want rest %v, got %v", tc.wantRest, rest)
+			}
+		})
+	}
+}
+
+func TestBatcher_RecordTooLarge(t *testing.T) {
+	b := newBatcher(10, 3)
+	if _, err := b.add([]byte("aaaa")); !errors.Is(err, ErrRecordTooLarge) {
+		t.Fatalf("want ErrRecordTooLarge, got %v", err)
+	}
+}
+
+func toStrings(batch [][]byte) []string {
+	s := make([]string, len(batch))
+	for i, r := range batch {
+		s[i] = string(r)
+	}
+	return s
+}
+
+func flatten(batches [][]string) []string {
+	var s []string
+	for _, batch := range batches {
+		s = append(s, strings.Join(batch, ","))
+	}
+	return s
+}
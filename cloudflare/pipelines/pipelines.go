@@ -0,0 +1,65 @@
+// Package pipelines provides the binding of Cloudflare Pipelines, which ingests records into R2.
+//   - https://developers.cloudflare.com/pipelines/
+package pipelines
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Pipeline represents the binding of a pipeline.
+//   - https://developers.cloudflare.com/pipelines/build-with-pipelines/sources/workers-apis/
+type Pipeline struct {
+	instance js.Value
+}
+
+// NewPipeline returns Pipeline for given variable name.
+//   - variable name must be defined in wrangler.toml as pipelines's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewPipeline(ctx context.Context, varName string) (*Pipeline, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Pipeline{instance: inst}, nil
+}
+
+// Send sends the records to the pipeline.
+//   - records are encoded by encoding/json. Each record must be encoded as a JSON object.
+//   - Use Buffer to send records in batches.
+func (p *Pipeline) Send(records []any) error {
+	encoded := make([][]byte, len(records))
+	for i, r := range records {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("pipelines: error encoding records[%d]: %w", i, err)
+		}
+		encoded[i] = b
+	}
+	return p.sendEncoded(encoded)
+}
+
+// sendEncoded sends the records encoded as JSON.
+func (p *Pipeline) sendEncoded(records [][]byte) error {
+	data := make([]byte, 0, 2+len(records)*2)
+	data = append(data, '[')
+	for i, r := range records {
+		if i > 0 {
+			data = append(data, ',')
+		}
+		data = append(data, r...)
+	}
+	data = append(data, ']')
+	promise, err := jsutil.TryCall(p.instance, "send", jsutil.JSONClass().Call("parse", string(data)))
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(promise)
+	return err
+}
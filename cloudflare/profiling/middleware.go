@@ -0,0 +1,131 @@
+//go:build js && wasm
+
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/timing"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Options represents the options of Middleware.
+type Options struct {
+	// Name returns the name of the request. The default is `METHOD /path`.
+	// Set this to aggregate requests of routes with parameters (e.g. `GET /users/{id}`) by Recorder.
+	Name func(req *http.Request) string
+	// Recorder records profiles of requests if it's not nil.
+	Recorder *Recorder
+	// OnProfile is called with the profile when the handler returns, e.g. to write it as a field of logs.
+	OnProfile func(ctx context.Context, p *Profile)
+	// Marks records each request by performance.measure of the runtime if it's available,
+	// so the profile can be inspected by DevTools (e.g. of `wrangler dev`).
+	Marks bool
+}
+
+var performance = jsutil.LazyGlobal("performance")
+
+// measurement measures a request from its start.
+type measurement struct {
+	name         string
+	start        time.Time
+	perfStart    time.Duration
+	tracker      *jsutil.AwaitTracker
+	awaitStart   time.Duration
+	isolateStart time.Duration
+	countStart   int
+}
+
+type measurementKey struct{}
+
+func start(ctx context.Context, name string) (context.Context, *measurement) {
+	ctx = jsutil.WithAwaitTracker(ctx)
+	tracker := jsutil.AwaitTrackerFromContext(ctx)
+	isolate := jsutil.IsolateAwaitTracker()
+	m := &measurement{
+		name:         name,
+		start:        time.Now(),
+		perfStart:    timing.Now(),
+		tracker:      tracker,
+		awaitStart:   tracker.Total(),
+		isolateStart: isolate.Total(),
+		countStart:   tracker.Count() + isolate.Count(),
+	}
+	return context.WithValue(ctx, measurementKey{}, m), m
+}
+
+// profile returns the profile measured so far.
+func (m *measurement) profile() *Profile {
+	isolate := jsutil.IsolateAwaitTracker()
+	total := time.Since(m.start)
+	interop := (m.tracker.Total() - m.awaitStart) + (isolate.Total() - m.isolateStart)
+	if interop > total {
+		// waiting time of awaits bound to the context and of other awaits can overlap.
+		interop = total
+	}
+	return &Profile{
+		Name:    m.name,
+		Total:   total,
+		Interop: interop,
+		Go:      total - interop,
+		Awaits:  m.tracker.Count() + isolate.Count() - m.countStart,
+	}
+}
+
+// Current returns the profile of the request measured so far. if ctx is not measured by Middleware, returns nil.
+func Current(ctx context.Context) *Profile {
+	m, ok := ctx.Value(measurementKey{}).(*measurement)
+	if !ok {
+		return nil
+	}
+	return m.profile()
+}
+
+// Middleware returns the middleware which profiles each request until the handler returns.
+//   - Bodies streamed after the handler returns are not included.
+//   - The profile of the request in progress can be obtained by Current.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &Options{}
+	}
+	nameOf := opts.Name
+	if nameOf == nil {
+		nameOf = func(req *http.Request) string {
+			return req.Method + " " + req.URL.Path
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, m := start(req.Context(), nameOf(req))
+			next.ServeHTTP(w, req.WithContext(ctx))
+			p := m.profile()
+			if opts.Marks {
+				mark(m, p)
+			}
+			if opts.Recorder != nil {
+				opts.Recorder.Record(p)
+			}
+			if opts.OnProfile != nil {
+				opts.OnProfile(ctx, p)
+			}
+		})
+	}
+}
+
+// mark records the profile by performance.measure. Errors are ignored, since marks are only for debugging.
+func mark(m *measurement, p *Profile) {
+	if !jsutil.HasGlobal("performance", "measure") {
+		return
+	}
+	detail := jsutil.NewObject()
+	detail.Set("interop", float64(p.Interop)/float64(time.Millisecond))
+	detail.Set("go", float64(p.Go)/float64(time.Millisecond))
+	detail.Set("awaits", p.Awaits)
+	opts := jsutil.NewObject()
+	opts.Set("start", float64(m.perfStart)/float64(time.Millisecond))
+	opts.Set("end", float64(timing.Now())/float64(time.Millisecond))
+	opts.Set("detail", detail)
+	_, _ = jsutil.TryCall(performance(), "measure", p.Name, opts)
+}
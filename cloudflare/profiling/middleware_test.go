@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package profiling
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"syscall/js"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func sleepPromise(d time.Duration) js.Value {
+	return jsutil.PromiseClass().New(js.FuncOf(func(_ js.Value, args []js.Value) any {
+		jsutil.Global.Call("setTimeout", args[0], d.Milliseconds())
+		return js.Undefined()
+	}))
+}
+
+func TestMiddleware(t *testing.T) {
+	var (
+		r       Recorder
+		got     *Profile
+		current *Profile
+	)
+	handler := Middleware(&Options{
+		Recorder: &r,
+		Marks:    true,
+		OnProfile: func(ctx context.Context, p *Profile) {
+			got = p
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, err := jsutil.AwaitPromiseContext(req.Context(), sleepPromise(50*time.Millisecond)); err != nil {
+			t.Error(err)
+		}
+		current = Current(req.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if got == nil || got.Name != "GET /users" {
+		t.Fatalf("unexpected profile %+v", got)
+	}
+	if got.Awaits != 1 || got.Interop < 40*time.Millisecond || got.Go >= 30*time.Millisecond || got.Go+got.Interop != got.Total {
+		t.Errorf("unexpected profile %+v", got)
+	}
+	if current == nil || current.Awaits != 1 {
+		t.Errorf("want current profile in the handler, got %+v", current)
+	}
+	if stats := r.Stats(); len(stats) != 1 || stats[0].Requests != 1 {
+		t.Errorf("want the profile to be recorded, got %+v", stats)
+	}
+	if Current(context.Background()) != nil {
+		t.Error("want nil for context without profile")
+	}
+}
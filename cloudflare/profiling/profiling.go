@@ -0,0 +1,108 @@
+// Package profiling records time spent by requests in JavaScript interop and in Go code.
+//
+// Middleware measures each request, reports it by OnProfile, and records it to Recorder,
+// which serves aggregated profiles as a debug endpoint.
+//   - Interop time is the time in which Go is waiting for JavaScript promises (e.g. fetch, bindings and reads of bodies).
+//     Synchronous calls of JavaScript functions are counted as Go time.
+//   - In Cloudflare Workers, clocks advance only on I/O, so computation right before an await is counted as interop time.
+//     See cpubudget for the details.
+//   - https://developers.cloudflare.com/workers/runtime-apis/performance/
+package profiling
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Profile represents time spent by a request.
+type Profile struct {
+	// Name identifies the request, e.g. `GET /users`.
+	Name string `json:"name"`
+	// Total is the elapsed time of the request.
+	Total time.Duration `json:"total"`
+	// Interop is the time in which Go was waiting for JavaScript promises.
+	Interop time.Duration `json:"interop"`
+	// Go is the time spent by Go code, which is Total excluding Interop.
+	Go time.Duration `json:"go"`
+	// Awaits is the number of promises awaited.
+	Awaits int `json:"awaits"`
+}
+
+// Stats represents aggregated profiles of requests of the same name.
+type Stats struct {
+	Name     string        `json:"name"`
+	Requests int           `json:"requests"`
+	Total    time.Duration `json:"total"`
+	Interop  time.Duration `json:"interop"`
+	Go       time.Duration `json:"go"`
+	Awaits   int           `json:"awaits"`
+	// MaxTotal is the maximum Total of the requests.
+	MaxTotal time.Duration `json:"maxTotal"`
+}
+
+// Recorder aggregates profiles by their names.
+//   - Recorder is an http.Handler which serves the aggregated profiles as JSON, so it can be mounted as a debug endpoint.
+//   - Profiles are kept in the memory of the instance, so they are reset when the instance is evicted.
+//   - The zero value is ready to use.
+type Recorder struct {
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// Record adds the profile to the stats of its name.
+func (r *Recorder) Record(p *Profile) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stats == nil {
+		r.stats = map[string]*Stats{}
+	}
+	s, ok := r.stats[p.Name]
+	if !ok {
+		s = &Stats{Name: p.Name}
+		r.stats[p.Name] = s
+	}
+	s.Requests++
+	s.Total += p.Total
+	s.Interop += p.Interop
+	s.Go += p.Go
+	s.Awaits += p.Awaits
+	if p.Total > s.MaxTotal {
+		s.MaxTotal = p.Total
+	}
+}
+
+// Stats returns the aggregated profiles sorted by their names.
+func (r *Recorder) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats := make([]Stats, 0, len(r.stats))
+	for _, s := range r.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Reset removes all recorded profiles.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = nil
+}
+
+// ServeHTTP serves the aggregated profiles as JSON. Durations are in nanoseconds.
+//   - DELETE request resets the profiles.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodDelete {
+		r.Reset()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(r.Stats())
+}
@@ -0,0 +1,40 @@
+package profiling
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	var r Recorder
+	r.Record(&Profile{Name: "GET /b", Total: 3 * time.Millisecond, Interop: 2 * time.Millisecond, Go: time.Millisecond, Awaits: 1})
+	r.Record(&Profile{Name: "GET /a", Total: time.Millisecond, Go: time.Millisecond})
+	r.Record(&Profile{Name: "GET /b", Total: 5 * time.Millisecond, Interop: time.Millisecond, Go: 4 * time.Millisecond, Awaits: 2})
+	want := []Stats{
+		{Name: "GET /a", Requests: 1, Total: time.Millisecond, Go: time.Millisecond, MaxTotal: time.Millisecond},
+		{Name: "GET /b", Requests: 2, Total: 8 * time.Millisecond, Interop: 3 * time.Millisecond, Go: 5 * time.Millisecond, Awaits: 3, MaxTotal: 5 * time.Millisecond},
+	}
+	if got := r.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/profiles", nil))
+	var served []Stats
+	if err := json.NewDecoder(rec.Body).Decode(&served); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(served, want) {
+		t.Errorf("want served %+v, got %+v", want, served)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/debug/profiles", nil))
+	if rec.Code != http.StatusNoContent || len(r.Stats()) != 0 {
+		t.Errorf("want profiles to be reset, got %d %v", rec.Code, r.Stats())
+	}
+}
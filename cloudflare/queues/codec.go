@@ -0,0 +1,90 @@
+package queues
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// Codec encodes and decodes bodies of messages in a binary format (e.g. MessagePack, Protocol Buffers or CBOR).
+//   - Messages encoded by codecs are sent as ContentTypeBytes, so they skip JSON entirely.
+//   - The body is tagged with the content type of the codec, and decoded by the codec in Message.Unmarshal.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[ContentType]Codec{}
+)
+
+// RegisterCodec registers the codec with the content type, which is given to SendOptions and MessageSendRequest.
+//   - Both producers and consumers must register the codec with the same content type.
+//   - The content type must be 1 to 255 bytes, and must not be the content types of Cloudflare Queues.
+//   - if RegisterCodec is called twice with the same content type, it panics.
+func RegisterCodec(contentType ContentType, c Codec) {
+	switch contentType {
+	case ContentTypeJSON, ContentTypeBytes, ContentTypeText, ContentTypeV8:
+		panic(fmt.Sprintf("queues: content type %s is reserved", contentType))
+	}
+	if len(contentType) == 0 || len(contentType) > 255 {
+		panic(fmt.Sprintf("queues: invalid length of content type %q", contentType))
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if _, ok := codecs[contentType]; ok {
+		panic(fmt.Sprintf("queues: codec %s is already registered", contentType))
+	}
+	codecs[contentType] = c
+}
+
+func getCodec(contentType ContentType) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[contentType]
+	return c, ok
+}
+
+// codecMagic precedes bodies encoded by codecs. It's followed by the length of the content type, the content type and the payload.
+var codecMagic = []byte("\x00QCD")
+
+// tagBody prepends the tag of the content type to the payload.
+func tagBody(contentType ContentType, payload []byte) []byte {
+	b := make([]byte, 0, len(codecMagic)+1+len(contentType)+len(payload))
+	b = append(b, codecMagic...)
+	b = append(b, byte(len(contentType)))
+	b = append(b, contentType...)
+	return append(b, payload...)
+}
+
+// ParseTaggedBody returns the content type and the payload of the body encoded by a codec.
+//   - if the body is not tagged, returns false.
+func ParseTaggedBody(body []byte) (contentType ContentType, payload []byte, ok bool) {
+	if !bytes.HasPrefix(body, codecMagic) || len(body) <= len(codecMagic) {
+		return "", nil, false
+	}
+	rest := body[len(codecMagic):]
+	n := int(rest[0])
+	if n == 0 || len(rest) < 1+n {
+		return "", nil, false
+	}
+	return ContentType(rest[1 : 1+n]), rest[1+n:], true
+}
+
+// UnmarshalTaggedBody decodes the body encoded by a codec into v, and reports whether the body is tagged.
+//   - if the codec of the tag is not registered, returns an error.
+func UnmarshalTaggedBody(body []byte, v any) (bool, error) {
+	contentType, payload, ok := ParseTaggedBody(body)
+	if !ok {
+		return false, nil
+	}
+	c, ok := getCodec(contentType)
+	if !ok {
+		return true, fmt.Errorf("queues: codec %s is not registered", contentType)
+	}
+	if err := c.Unmarshal(payload, v); err != nil {
+		return true, fmt.Errorf("queues: error decoding body by codec %s: %w", contentType, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,75 @@
+package queues
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+)
+
+// gobCodec is a binary codec for tests.
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+const contentTypeGob ContentType = "application/x-gob"
+
+func init() {
+	RegisterCodec(contentTypeGob, gobCodec{})
+}
+
+type codecMessage struct {
+	Name  string
+	Count int
+}
+
+func TestCodec(t *testing.T) {
+	m, err := encodeMessage(&codecMessage{Name: "a", Count: 2}, contentTypeGob, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.contentType != ContentTypeBytes {
+		t.Errorf("content type = %s, want bytes", m.contentType)
+	}
+	contentType, _, ok := ParseTaggedBody(m.data)
+	if !ok || contentType != contentTypeGob {
+		t.Errorf("ParseTaggedBody() = %s, %v", contentType, ok)
+	}
+	var got codecMessage
+	if tagged, err := UnmarshalTaggedBody(m.data, &got); !tagged || err != nil {
+		t.Fatalf("UnmarshalTaggedBody() = %v, %v", tagged, err)
+	}
+	if got != (codecMessage{Name: "a", Count: 2}) {
+		t.Errorf("got %+v", got)
+	}
+
+	if tagged, err := UnmarshalTaggedBody([]byte(`{"a":1}`), &got); tagged || err != nil {
+		t.Errorf("untagged body: %v, %v", tagged, err)
+	}
+	if tagged, err := UnmarshalTaggedBody(tagBody("application/x-unknown", nil), &got); !tagged || err == nil {
+		t.Errorf("unknown codec: %v, %v", tagged, err)
+	}
+	if _, err := encodeMessage("a", "application/x-unknown", 0); err == nil {
+		t.Error("unregistered content type: want error")
+	}
+}
+
+func TestRegisterCodec_Panics(t *testing.T) {
+	for _, contentType := range []ContentType{ContentTypeJSON, contentTypeGob, ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterCodec(%q): want panic", contentType)
+				}
+			}()
+			RegisterCodec(contentType, gobCodec{})
+		}()
+	}
+}
@@ -0,0 +1,200 @@
+//go:build js && wasm
+
+package queues
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// Message represents a message of the batch delivered to the consumer.
+//   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#message
+type Message struct {
+	instance js.Value
+	// ID is the unique ID of the message.
+	ID string
+	// Timestamp is the time when the message was sent.
+	Timestamp time.Time
+	// Attempts is the number of times the message has been delivered, starting at 1.
+	Attempts int
+	// Body is the body of the message converted to Go value.
+	// See `jsutil.ToGoValue` for the conversion rules.
+	Body any
+}
+
+func newMessage(obj js.Value) (*Message, error) {
+	timestamp, err := jsutil.DateToTime(obj.Get("timestamp"))
+	if err != nil {
+		return nil, fmt.Errorf("error converting timestamp: %w", err)
+	}
+	return &Message{
+		instance:  obj,
+		ID:        obj.Get("id").String(),
+		Timestamp: timestamp,
+		Attempts:  obj.Get("attempts").Int(),
+		Body:      jsutil.ToGoValue(obj.Get("body")),
+	}, nil
+}
+
+// Bytes returns the body of the message sent as ContentTypeBytes or ContentTypeText.
+//   - bytes are returned without conversion.
+func (m *Message) Bytes() ([]byte, error) {
+	switch body := m.Body.(type) {
+	case []byte:
+		return body, nil
+	case string:
+		return []byte(body), nil
+	}
+	return nil, fmt.Errorf("queues: body of type %T can't be read as bytes", m.Body)
+}
+
+// Text returns the body of the message sent as ContentTypeText or ContentTypeBytes.
+func (m *Message) Text() (string, error) {
+	switch body := m.Body.(type) {
+	case string:
+		return body, nil
+	case []byte:
+		return string(body), nil
+	}
+	return "", fmt.Errorf("queues: body of type %T can't be read as text", m.Body)
+}
+
+// Unmarshal decodes the body of the message into v.
+//   - if the body is encoded by a codec registered by RegisterCodec, it's decoded by the codec.
+//   - if the body is other bytes, they are decoded as JSON text by encoding/json. Otherwise the body is decoded by `jsutil.Decode`.
+//   - This is useful to decode messages sent as ContentTypeJSON into structs.
+func (m *Message) Unmarshal(v any) error {
+	if body, ok := m.Body.([]byte); ok {
+		if tagged, err := UnmarshalTaggedBody(body, v); tagged {
+			return err
+		}
+		return json.Unmarshal(body, v)
+	}
+	if err := jsutil.Decode(m.instance.Get("body"), v); err != nil {
+		return fmt.Errorf("queues: error decoding body: %w", err)
+	}
+	return nil
+}
+
+// Ack marks the message as successfully delivered, so it is not retried even if the Consumer returns an error.
+func (m *Message) Ack() {
+	m.instance.Call("ack")
+}
+
+// RetryOptions represents the options of Retry and RetryAll.
+//   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#queueretryoptions
+type RetryOptions struct {
+	// DelaySeconds is the number of seconds to delay the retry. The maximum is 43200 (12 hours).
+	// if DelaySeconds is 0, the retry_delay of the consumer setting is used.
+	DelaySeconds int
+}
+
+func (opts *RetryOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.DelaySeconds != 0 {
+		obj.Set("delaySeconds", opts.DelaySeconds)
+	}
+	return obj
+}
+
+// Retry marks the message to be retried.
+//   - This is useful to retry only failed messages of the batch, e.g. with exponential backoff based on Attempts.
+func (m *Message) Retry(opts *RetryOptions) {
+	m.instance.Call("retry", opts.toJS())
+}
+
+// MessageBatch represents the batch of messages delivered to the consumer.
+//   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#messagebatch
+type MessageBatch struct {
+	instance js.Value
+	// Queue is the name of the queue which the batch belongs to.
+	Queue string
+	// Messages are the messages of the batch.
+	Messages []*Message
+}
+
+func newMessageBatch(obj js.Value) (*MessageBatch, error) {
+	messagesArr := obj.Get("messages")
+	messages := make([]*Message, messagesArr.Length())
+	for i := range messages {
+		m, err := newMessage(messagesArr.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("error converting messages[%d]: %w", i, err)
+		}
+		messages[i] = m
+	}
+	return &MessageBatch{
+		instance: obj,
+		Queue:    obj.Get("queue").String(),
+		Messages: messages,
+	}, nil
+}
+
+// AckAll marks all messages of the batch as successfully delivered.
+func (b *MessageBatch) AckAll() {
+	b.instance.Call("ackAll")
+}
+
+// RetryAll marks all messages of the batch to be retried.
+func (b *MessageBatch) RetryAll(opts *RetryOptions) {
+	b.instance.Call("retryAll", opts.toJS())
+}
+
+// Consumer consumes the batch of messages.
+//   - if Consumer returns an error, all messages of the batch which are not acknowledged are retried.
+//   - ctx holds the environment of the worker, so bindings can be accessed by functions in the cloudflare package.
+type Consumer func(ctx context.Context, batch *MessageBatch) error
+
+var consumer Consumer
+
+// Consume sets the Consumer and starts the worker.
+//   - the queue must be defined in wrangler.toml as queues.consumers.
+//   - Consume blocks forever, so it must be called at the end of main function.
+//     Use ConsumeNonBlock to handle both requests and messages by the same worker.
+func Consume(c Consumer) {
+	ConsumeNonBlock(c)
+	jsutil.Global.Call("ready")
+	select {}
+}
+
+// ConsumeNonBlock sets the Consumer without starting the worker.
+//   - ConsumeNonBlock must be called before `workers.Serve` (or other functions which start the worker).
+func ConsumeNonBlock(c Consumer) {
+	consumer = c
+	registerHandleQueue()
+}
+
+func handleQueue(batchObj js.Value, runtimeCtxObj js.Value) error {
+	if consumer == nil {
+		return fmt.Errorf("queues: Consume must be called before handleQueue")
+	}
+	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
+	batch, err := newMessageBatch(batchObj)
+	if err != nil {
+		return err
+	}
+	return consumer(ctx, batch)
+}
+
+// registerHandleQueue sets handleQueue called by the JavaScript side.
+var registerHandleQueue = jsutil.LazyRegister("handleQueue", func(_ js.Value, args []js.Value) any {
+	if len(args) != 2 {
+		panic(fmt.Errorf("invalid number of arguments given to handleQueue: %d", len(args)))
+	}
+	batchObj, runtimeCtxObj := args[0], args[1]
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		if err := handleQueue(batchObj, runtimeCtxObj); err != nil {
+			return js.Value{}, err
+		}
+		return js.Undefined(), nil
+	})
+})
@@ -0,0 +1,140 @@
+//go:build js && wasm
+
+package queues
+
+import (
+	"context"
+	"time"
+)
+
+// ExceedsAttempts reports whether the message has been delivered maxAttempts times or more.
+func (m *Message) ExceedsAttempts(maxAttempts int) bool {
+	return m.Attempts >= maxAttempts
+}
+
+// MessageHandler handles a message of the batch.
+type MessageHandler func(ctx context.Context, msg *Message) error
+
+// DeadLetter represents the body of a message routed to the dead letter queue.
+type DeadLetter struct {
+	// Queue is the name of the queue which the message was consumed from.
+	Queue string `json:"queue"`
+	// MessageID is the ID of the original message.
+	MessageID string `json:"messageId"`
+	// Timestamp is the time when the original message was sent.
+	Timestamp time.Time `json:"timestamp"`
+	// Attempts is the number of delivery attempts of the original message.
+	Attempts int `json:"attempts"`
+	// Error is the error message returned by the MessageHandler at the last attempt.
+	Error string `json:"error"`
+	// Body is the body of the original message.
+	Body any `json:"body"`
+}
+
+// BatchResult represents the result of a batch consumed by WithDeadLetterQueue.
+type BatchResult struct {
+	Queue string
+	// Acked is the number of messages handled successfully.
+	Acked int
+	// Retried is the number of messages marked to be retried.
+	Retried int
+	// DeadLettered is the number of messages routed to the dead letter queue.
+	DeadLettered int
+	// Attempts is the number of messages by their delivery attempts.
+	Attempts map[int]int
+}
+
+// DeadLetterOptions represents the options of WithDeadLetterQueue.
+type DeadLetterOptions struct {
+	// MaxAttempts is the number of attempts after which failed messages are routed to the dead letter queue.
+	// The default value is 3.
+	MaxAttempts int
+	// RetryDelay returns the delay in seconds of the retry for the attempts of the failed message.
+	// if RetryDelay is nil, messages are retried with the retry_delay of the consumer setting.
+	RetryDelay func(attempts int) int
+	// OnResult is called with the result of each batch, e.g. to record metrics of retries.
+	OnResult func(ctx context.Context, result *BatchResult)
+}
+
+const defaultMaxAttempts = 3
+
+// WithDeadLetterQueue returns Consumer which handles each message by handler and routes poisoned messages to dlq.
+//   - Messages handled successfully are acknowledged.
+//   - Failed messages are retried until they are delivered MaxAttempts times. Then they are sent to dlq as DeadLetter and acknowledged.
+//   - if sending to dlq fails, the message is retried.
+//
+// This is useful when failure metadata is needed in the dead letter queue.
+// Otherwise, `dead_letter_queue` of the consumer setting in wrangler.toml can be used.
+func WithDeadLetterQueue(handler MessageHandler, dlq *Producer, opts *DeadLetterOptions) Consumer {
+	if opts == nil {
+		opts = &DeadLetterOptions{}
+	}
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	return func(ctx context.Context, batch *MessageBatch) error {
+		result := &BatchResult{
+			Queue:    batch.Queue,
+			Attempts: map[int]int{},
+		}
+		for _, msg := range batch.Messages {
+			result.Attempts[msg.Attempts]++
+			err := handler(ctx, msg)
+			if err == nil {
+				msg.Ack()
+				result.Acked++
+				continue
+			}
+			if msg.ExceedsAttempts(maxAttempts) {
+				if sendErr := dlq.Send(newDeadLetter(batch.Queue, msg, err), nil); sendErr == nil {
+					msg.Ack()
+					result.DeadLettered++
+					continue
+				}
+			}
+			var retryOpts *RetryOptions
+			if opts.RetryDelay != nil {
+				retryOpts = &RetryOptions{DelaySeconds: opts.RetryDelay(msg.Attempts)}
+			}
+			msg.Retry(retryOpts)
+			result.Retried++
+		}
+		if opts.OnResult != nil {
+			opts.OnResult(ctx, result)
+		}
+		return nil
+	}
+}
+
+func newDeadLetter(queue string, msg *Message, err error) *DeadLetter {
+	return &DeadLetter{
+		Queue:     queue,
+		MessageID: msg.ID,
+		Timestamp: msg.Timestamp,
+		Attempts:  msg.Attempts,
+		Error:     err.Error(),
+		Body:      msg.Body,
+	}
+}
+
+// ExponentialBackoff returns RetryDelay which doubles the delay from baseSeconds for each attempt, up to maxSeconds.
+//   - maxSeconds is capped by MaxDelaySeconds.
+func ExponentialBackoff(baseSeconds, maxSeconds int) func(attempts int) int {
+	if maxSeconds > MaxDelaySeconds {
+		maxSeconds = MaxDelaySeconds
+	}
+	return func(attempts int) int {
+		delay := baseSeconds
+		for i := 1; i < attempts; i++ {
+			delay *= 2
+			if delay >= maxSeconds {
+				return maxSeconds
+			}
+		}
+		if delay > maxSeconds {
+			return maxSeconds
+		}
+		return delay
+	}
+}
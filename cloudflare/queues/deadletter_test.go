@@ -0,0 +1,51 @@
+//go:build js && wasm
+
+package queues
+
+import "testing"
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(10, 60)
+	tests := map[string]struct {
+		attempts int
+		want     int
+	}{
+		"first attempt": {
+			attempts: 1,
+			want:     10,
+		},
+		"second attempt": {
+			attempts: 2,
+			want:     20,
+		},
+		"third attempt": {
+			attempts: 3,
+			want:     40,
+		},
+		"capped": {
+			attempts: 4,
+			want:     60,
+		},
+		"many attempts": {
+			attempts: 100,
+			want:     60,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			if got := backoff(tc.attempts); got != tc.want {
+				t.Errorf("backoff(%d) = %d, want %d", tc.attempts, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff_maxDelay(t *testing.T) {
+	backoff := ExponentialBackoff(MaxDelaySeconds, MaxDelaySeconds*2)
+	if got := backoff(2); got != MaxDelaySeconds {
+		t.Errorf("backoff(2) = %d, want %d", got, MaxDelaySeconds)
+	}
+}
@@ -0,0 +1,165 @@
+// Package queues provides the way to send messages to Cloudflare Queues.
+//   - https://developers.cloudflare.com/queues/
+package queues
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Limits of Cloudflare Queues.
+//   - https://developers.cloudflare.com/queues/platform/limits/
+const (
+	// MaxMessageSize is the maximum size of a message in bytes.
+	MaxMessageSize = 128 * 1024
+	// MaxBatchSize is the maximum total size of messages sent by SendBatch in bytes.
+	MaxBatchSize = 256 * 1024
+	// MaxBatchMessages is the maximum number of messages sent by SendBatch.
+	MaxBatchMessages = 100
+	// MaxDelaySeconds is the maximum delay of messages in seconds (12 hours).
+	MaxDelaySeconds = 43200
+)
+
+var (
+	// ErrMessageTooLarge is returned when the encoded message is larger than MaxMessageSize.
+	ErrMessageTooLarge = errors.New("queues: message is too large")
+	// ErrBatchTooLarge is returned when the total size of encoded messages is larger than MaxBatchSize.
+	ErrBatchTooLarge = errors.New("queues: batch is too large")
+	// ErrTooManyMessages is returned when the batch has more than MaxBatchMessages messages.
+	ErrTooManyMessages = errors.New("queues: too many messages in batch")
+	// ErrInvalidDelay is returned when the delay is negative or larger than MaxDelaySeconds.
+	ErrInvalidDelay = errors.New("queues: invalid delay seconds")
+)
+
+// ContentType represents the format of message bodies.
+//   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#queuescontenttype
+type ContentType string
+
+const (
+	// ContentTypeJSON encodes the body by encoding/json. The body must be JSON-serializable.
+	ContentTypeJSON ContentType = "json"
+	// ContentTypeBytes sends the body as raw bytes. The body must be []byte.
+	ContentTypeBytes ContentType = "bytes"
+	// ContentTypeText sends the body as text. The body must be string.
+	ContentTypeText ContentType = "text"
+	// ContentTypeV8 sends the body by the structured clone algorithm. See `jsutil.ToJSValue` for the conversion rules.
+	// The size of the body is not validated before sending, since it is only known after serialization on JavaScript side.
+	ContentTypeV8 ContentType = "v8"
+)
+
+// SendOptions represents the options of Send.
+type SendOptions struct {
+	// ContentType is the format of the body.
+	// if ContentType is empty, []byte is sent as ContentTypeBytes and other values are sent as ContentTypeJSON.
+	// The content types of codecs registered by RegisterCodec can be also used.
+	ContentType ContentType
+	// DelaySeconds is the number of seconds to delay the delivery of the message.
+	// if DelaySeconds is 0, the delivery_delay of the queue setting is used.
+	DelaySeconds int
+}
+
+// MessageSendRequest represents a message sent by SendBatch.
+type MessageSendRequest struct {
+	Body any
+	// ContentType is the format of the body. See SendOptions for details.
+	ContentType ContentType
+	// DelaySeconds is the number of seconds to delay the delivery of the message.
+	// This overrides SendBatchOptions' DelaySeconds.
+	DelaySeconds int
+}
+
+// SendBatchOptions represents the options of SendBatch.
+type SendBatchOptions struct {
+	// DelaySeconds is the number of seconds to delay the delivery of all messages in the batch.
+	DelaySeconds int
+}
+
+// encodedMessage represents a message body encoded in Go.
+type encodedMessage struct {
+	contentType  ContentType
+	data         []byte
+	delaySeconds int
+	// value is the body of ContentTypeV8. it is converted to JavaScript value when sending.
+	value any
+}
+
+// validateDelay validates the delay of messages.
+func validateDelay(delaySeconds int) error {
+	if delaySeconds < 0 || delaySeconds > MaxDelaySeconds {
+		return ErrInvalidDelay
+	}
+	return nil
+}
+
+// encodeMessage encodes the body with the content type and validates its size and delay.
+func encodeMessage(body any, contentType ContentType, delaySeconds int) (*encodedMessage, error) {
+	if err := validateDelay(delaySeconds); err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		contentType = ContentTypeJSON
+		if _, ok := body.([]byte); ok {
+			contentType = ContentTypeBytes
+		}
+	}
+	m := &encodedMessage{contentType: contentType, delaySeconds: delaySeconds}
+	switch contentType {
+	case ContentTypeJSON:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("queues: error encoding body: %w", err)
+		}
+		m.data = data
+	case ContentTypeBytes:
+		data, ok := body.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("queues: body of content type %s must be []byte, got %T", contentType, body)
+		}
+		m.data = data
+	case ContentTypeText:
+		text, ok := body.(string)
+		if !ok {
+			return nil, fmt.Errorf("queues: body of content type %s must be string, got %T", contentType, body)
+		}
+		m.data = []byte(text)
+	case ContentTypeV8:
+		m.value = body
+	default:
+		c, ok := getCodec(contentType)
+		if !ok {
+			return nil, fmt.Errorf("queues: unsupported content type: %s", contentType)
+		}
+		payload, err := c.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("queues: error encoding body by codec %s: %w", contentType, err)
+		}
+		m.contentType = ContentTypeBytes
+		m.data = tagBody(contentType, payload)
+	}
+	if len(m.data) > MaxMessageSize {
+		return nil, ErrMessageTooLarge
+	}
+	return m, nil
+}
+
+// encodeBatch encodes the messages and validates the limits of the batch.
+func encodeBatch(messages []*MessageSendRequest) ([]*encodedMessage, error) {
+	if len(messages) > MaxBatchMessages {
+		return nil, ErrTooManyMessages
+	}
+	encoded := make([]*encodedMessage, len(messages))
+	var total int
+	for i, msg := range messages {
+		m, err := encodeMessage(msg.Body, msg.ContentType, msg.DelaySeconds)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		total += len(m.data)
+		if total > MaxBatchSize {
+			return nil, ErrBatchTooLarge
+		}
+		encoded[i] = m
+	}
+	return encoded, nil
+}
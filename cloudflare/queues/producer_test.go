@@ -0,0 +1,141 @@
+package queues
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncodeMessage(t *testing.T) {
+	tests := map[string]struct {
+		body            any
+		contentType     ContentType
+		delaySeconds    int
+		wantContentType ContentType
+		wantData        []byte
+		wantErr         error
+	}{
+		"json by default": {
+			body:            map[string]int{"a": 1},
+			wantContentType: ContentTypeJSON,
+			wantData:        []byte(`{"a":1}`),
+		},
+		"bytes by default": {
+			body:            []byte("abc"),
+			wantContentType: ContentTypeBytes,
+			wantData:        []byte("abc"),
+		},
+		"bytes as json": {
+			body:            []byte("abc"),
+			contentType:     ContentTypeJSON,
+			wantContentType: ContentTypeJSON,
+			wantData:        []byte(`"YWJj"`),
+		},
+		"text": {
+			body:            "abc",
+			contentType:     ContentTypeText,
+			wantContentType: ContentTypeText,
+			wantData:        []byte("abc"),
+		},
+		"v8": {
+			body:            map[string]any{"a": 1},
+			contentType:     ContentTypeV8,
+			wantContentType: ContentTypeV8,
+		},
+		"too large": {
+			body:    make([]byte, MaxMessageSize+1),
+			wantErr: ErrMessageTooLarge,
+		},
+		"delayed": {
+			body:            "a",
+			delaySeconds:    MaxDelaySeconds,
+			wantContentType: ContentTypeJSON,
+			wantData:        []byte(`"a"`),
+		},
+		"negative delay": {
+			body:         "a",
+			delaySeconds: -1,
+			wantErr:      ErrInvalidDelay,
+		},
+		"too long delay": {
+			body:         "a",
+			delaySeconds: MaxDelaySeconds + 1,
+			wantErr:      ErrInvalidDelay,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			got, err := encodeMessage(tc.body, tc.contentType, tc.delaySeconds)
+			if tc.wantErr != nil {
+				if !errors.Is(err, tc.wantErr) {
+					t.Fatalf("encodeMessage() error = %v, want %v", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encodeMessage() error = %v", err)
+			}
+			if got.contentType != tc.wantContentType {
+				t.Errorf("contentType = %s, want %s", got.contentType, tc.wantContentType)
+			}
+			if !bytes.Equal(got.data, tc.wantData) {
+				t.Errorf("data = %s, want %s", got.data, tc.wantData)
+			}
+		})
+	}
+}
+
+func TestEncodeMessage_invalid(t *testing.T) {
+	if _, err := encodeMessage("abc", ContentTypeBytes, 0); err == nil {
+		t.Error("encodeMessage() error = nil, want error for non-[]byte body")
+	}
+	if _, err := encodeMessage([]byte("abc"), ContentTypeText, 0); err == nil {
+		t.Error("encodeMessage() error = nil, want error for non-string body")
+	}
+	if _, err := encodeMessage("abc", "unknown", 0); err == nil {
+		t.Error("encodeMessage() error = nil, want error for unknown content type")
+	}
+	if _, err := encodeMessage(make(chan int), "", 0); err == nil {
+		t.Error("encodeMessage() error = nil, want error for non-JSON-serializable body")
+	}
+}
+
+func TestEncodeBatch(t *testing.T) {
+	newBatch := func(n, size int) []*MessageSendRequest {
+		messages := make([]*MessageSendRequest, n)
+		for i := range messages {
+			messages[i] = &MessageSendRequest{Body: make([]byte, size)}
+		}
+		return messages
+	}
+	tests := map[string]struct {
+		messages []*MessageSendRequest
+		wantErr  error
+	}{
+		"valid": {
+			messages: newBatch(MaxBatchMessages, 10),
+		},
+		"too many messages": {
+			messages: newBatch(MaxBatchMessages+1, 10),
+			wantErr:  ErrTooManyMessages,
+		},
+		"too large": {
+			messages: newBatch(3, MaxMessageSize),
+			wantErr:  ErrBatchTooLarge,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := encodeBatch(tc.messages)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("encodeBatch() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+// Package pull provides the client of the HTTP pull API of Cloudflare Queues.
+//
+// Pull consumers are useful when push consumers (queues.Consume) can't be used,
+// e.g. consuming messages from scheduled handlers or outside of Workers.
+//   - The queue must be configured with an HTTP pull consumer.
+//   - The API token must have the `Queues Edit` permission.
+//   - https://developers.cloudflare.com/queues/configuration/pull-consumers/
+package pull
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+const defaultBaseURL = "https://api.cloudflare.com/client/v4"
+
+// Client is the client of the HTTP pull API of a queue.
+type Client struct {
+	accountID  string
+	queueID    string
+	apiToken   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// Option is a type that represents an optional function of NewClient.
+type Option func(*Client)
+
+// WithHTTPClient changes the HTTP client used to send requests.
+//   - On TinyGo, use `fetch.NewClient().HTTPClient(fetch.RedirectModeFollow)`.
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = c
+	}
+}
+
+// WithBaseURL changes the base URL of the Cloudflare API.
+func WithBaseURL(u string) Option {
+	return func(client *Client) {
+		client.baseURL = strings.TrimSuffix(u, "/")
+	}
+}
+
+// NewClient returns Client of the queue.
+func NewClient(accountID, queueID, apiToken string, opts ...Option) *Client {
+	c := &Client{
+		accountID:  accountID,
+		queueID:    queueID,
+		apiToken:   apiToken,
+		baseURL:    defaultBaseURL,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// PullOptions represents the options of Pull.
+type PullOptions struct {
+	// BatchSize is the maximum number of messages to pull. The default value is 5, and the maximum is 100.
+	BatchSize int
+	// VisibilityTimeout is the duration of the leases of pulled messages. The default value is 30 seconds.
+	// Messages which are not acknowledged or retried until the leases expire are delivered again.
+	VisibilityTimeout time.Duration
+}
+
+// Message represents a message pulled from the queue.
+type Message struct {
+	ID        string
+	Timestamp time.Time
+	Attempts  int
+	// LeaseID is the ID used to acknowledge or retry the message.
+	LeaseID string
+	// LeaseExpiresAt is the time when the lease of the message expires, calculated from the time of Pull.
+	LeaseExpiresAt time.Time
+	// Metadata is the metadata of the message, e.g. `CF-Content-Type`.
+	Metadata map[string]string
+	// Body is the raw body of the message. Use Bytes or Unmarshal to decode it.
+	Body string
+}
+
+// ContentType returns the content type of the message (json, text or bytes).
+func (m *Message) ContentType() string {
+	return m.Metadata["CF-Content-Type"]
+}
+
+// Bytes returns the decoded body of the message.
+//   - bodies of the content type bytes are decoded from base64.
+func (m *Message) Bytes() ([]byte, error) {
+	if m.ContentType() == "bytes" {
+		return base64.StdEncoding.DecodeString(m.Body)
+	}
+	return []byte(m.Body), nil
+}
+
+// Unmarshal decodes the JSON body of the message into v.
+//   - if the body is encoded by a codec registered by `queues.RegisterCodec`, it's decoded by the codec.
+func (m *Message) Unmarshal(v any) error {
+	b, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	if tagged, err := queues.UnmarshalTaggedBody(b, v); tagged {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// LeaseExpired reports whether the lease of the message has expired at the time.
+func (m *Message) LeaseExpired(now time.Time) bool {
+	return !now.Before(m.LeaseExpiresAt)
+}
+
+// APIError represents the error returned by the Cloudflare API.
+type APIError struct {
+	StatusCode int
+	Errors     []*APIErrorDetail
+}
+
+// APIErrorDetail represents a detail of APIError.
+type APIErrorDetail struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, d := range e.Errors {
+		msgs[i] = fmt.Sprintf("%d: %s", d.Code, d.Message)
+	}
+	return fmt.Sprintf("pull: API error (status %d): %s", e.StatusCode, strings.Join(msgs, ", "))
+}
+
+// Batch represents messages pulled at once, and holds acknowledgements and retries of them until Flush is called.
+type Batch struct {
+	Messages []*Message
+	acks     []string
+	retries  []*retryRequest
+}
+
+// Ack marks the message to be acknowledged at Flush.
+func (b *Batch) Ack(msg *Message) {
+	b.acks = append(b.acks, msg.LeaseID)
+}
+
+// Retry marks the message to be retried after delaySeconds at Flush.
+func (b *Batch) Retry(msg *Message, delaySeconds int) {
+	b.retries = append(b.retries, &retryRequest{LeaseID: msg.LeaseID, DelaySeconds: delaySeconds})
+}
+
+// AckAll marks all messages of the batch to be acknowledged at Flush.
+func (b *Batch) AckAll() {
+	for _, msg := range b.Messages {
+		b.Ack(msg)
+	}
+}
+
+type pullRequest struct {
+	BatchSize           int   `json:"batch_size,omitempty"`
+	VisibilityTimeoutMs int64 `json:"visibility_timeout_ms,omitempty"`
+}
+
+type pullResult struct {
+	Messages []*struct {
+		ID          string            `json:"id"`
+		Body        string            `json:"body"`
+		TimestampMs int64             `json:"timestamp_ms"`
+		Attempts    int               `json:"attempts"`
+		LeaseID     string            `json:"lease_id"`
+		Metadata    map[string]string `json:"metadata"`
+	} `json:"messages"`
+}
+
+const defaultVisibilityTimeout = 30 * time.Second
+
+// Pull pulls messages from the queue.
+//   - The messages must be acknowledged or retried by Batch's methods and Flush before their leases expire.
+func (c *Client) Pull(ctx context.Context, opts *PullOptions) (*Batch, error) {
+	if opts == nil {
+		opts = &PullOptions{}
+	}
+	visibilityTimeout := opts.VisibilityTimeout
+	if visibilityTimeout == 0 {
+		visibilityTimeout = defaultVisibilityTimeout
+	}
+	pulledAt := time.Now()
+	var result pullResult
+	req := &pullRequest{
+		BatchSize:           opts.BatchSize,
+		VisibilityTimeoutMs: visibilityTimeout.Milliseconds(),
+	}
+	if err := c.post(ctx, "pull", req, &result); err != nil {
+		return nil, err
+	}
+	batch := &Batch{Messages: make([]*Message, len(result.Messages))}
+	for i, m := range result.Messages {
+		batch.Messages[i] = &Message{
+			ID:             m.ID,
+			Timestamp:      time.UnixMilli(m.TimestampMs),
+			Attempts:       m.Attempts,
+			LeaseID:        m.LeaseID,
+			LeaseExpiresAt: pulledAt.Add(visibilityTimeout),
+			Metadata:       m.Metadata,
+			Body:           m.Body,
+		}
+	}
+	return batch, nil
+}
+
+type ackRequest struct {
+	Acks    []*ackLease     `json:"acks"`
+	Retries []*retryRequest `json:"retries"`
+}
+
+type ackLease struct {
+	LeaseID string `json:"lease_id"`
+}
+
+type retryRequest struct {
+	LeaseID      string `json:"lease_id"`
+	DelaySeconds int    `json:"delay_seconds,omitempty"`
+}
+
+// FlushResult represents the result of Flush.
+type FlushResult struct {
+	AckCount   int      `json:"ackCount"`
+	RetryCount int      `json:"retryCount"`
+	Warnings   []string `json:"warnings"`
+}
+
+// Flush sends acknowledgements and retries marked by Ack and Retry.
+//   - acknowledgements and retries of expired leases are reported as warnings of FlushResult.
+func (c *Client) Flush(ctx context.Context, b *Batch) (*FlushResult, error) {
+	req := &ackRequest{
+		Acks:    make([]*ackLease, len(b.acks)),
+		Retries: b.retries,
+	}
+	for i, leaseID := range b.acks {
+		req.Acks[i] = &ackLease{LeaseID: leaseID}
+	}
+	if req.Retries == nil {
+		req.Retries = []*retryRequest{}
+	}
+	var result FlushResult
+	if err := c.post(ctx, "ack", req, &result); err != nil {
+		return nil, err
+	}
+	b.acks, b.retries = nil, nil
+	return &result, nil
+}
+
+type apiResponse struct {
+	Success bool              `json:"success"`
+	Errors  []*APIErrorDetail `json:"errors"`
+	Result  json.RawMessage   `json:"result"`
+}
+
+// post sends the request to the messages API and decodes the result.
+func (c *Client) post(ctx context.Context, action string, body, result any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	u := fmt.Sprintf("%s/accounts/%s/queues/%s/messages/%s", c.baseURL, c.accountID, c.queueID, action)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	var apiRes apiResponse
+	if err := json.NewDecoder(res.Body).Decode(&apiRes); err != nil {
+		return fmt.Errorf("pull: error decoding response (status %d): %w", res.StatusCode, err)
+	}
+	if !apiRes.Success || res.StatusCode >= 400 {
+		return &APIError{StatusCode: res.StatusCode, Errors: apiRes.Errors}
+	}
+	return json.Unmarshal(apiRes.Result, result)
+}
@@ -0,0 +1,142 @@
+package pull
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func newTestClient(t *testing.T, wantPath string, wantBody string, resBody string, status int) *Client {
+	t.Helper()
+	httpClient := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) *http.Response {
+			if req.URL.Path != wantPath {
+				t.Errorf("path = %s, want %s", req.URL.Path, wantPath)
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer token" {
+				t.Errorf("Authorization = %s, want Bearer token", got)
+			}
+			b, _ := io.ReadAll(req.Body)
+			if string(b) != wantBody {
+				t.Errorf("body = %s, want %s", b, wantBody)
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader(resBody)),
+				Header:     http.Header{},
+			}
+		}),
+	}
+	return NewClient("account", "queue", "token", WithHTTPClient(httpClient))
+}
+
+func TestClient_Pull(t *testing.T) {
+	c := newTestClient(t,
+		"/client/v4/accounts/account/queues/queue/messages/pull",
+		`{"batch_size":10,"visibility_timeout_ms":1000}`,
+		`{"success":true,"errors":[],"result":{"messages":[
+			{"id":"1","body":"{\"a\":1}","timestamp_ms":1700000000000,"attempts":1,"lease_id":"l1","metadata":{"CF-Content-Type":"json"}},
+			{"id":"2","body":"YWJj","timestamp_ms":1700000000000,"attempts":2,"lease_id":"l2","metadata":{"CF-Content-Type":"bytes"}}
+		]}}`,
+		http.StatusOK,
+	)
+	batch, err := c.Pull(context.Background(), &PullOptions{BatchSize: 10, VisibilityTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if len(batch.Messages) != 2 {
+		t.Fatalf("len(Messages) = %d, want 2", len(batch.Messages))
+	}
+	var v struct{ A int }
+	if err := batch.Messages[0].Unmarshal(&v); err != nil || v.A != 1 {
+		t.Errorf("Unmarshal() = %v, %v, want {1}, nil", v, err)
+	}
+	if b, err := batch.Messages[1].Bytes(); err != nil || string(b) != "abc" {
+		t.Errorf("Bytes() = %s, %v, want abc, nil", b, err)
+	}
+	if got := batch.Messages[0].Timestamp.UnixMilli(); got != 1700000000000 {
+		t.Errorf("Timestamp = %d, want 1700000000000", got)
+	}
+	if batch.Messages[0].LeaseExpired(time.Now()) {
+		t.Error("LeaseExpired() = true, want false")
+	}
+	if !batch.Messages[0].LeaseExpired(time.Now().Add(time.Second)) {
+		t.Error("LeaseExpired() = false, want true")
+	}
+}
+
+func TestClient_Flush(t *testing.T) {
+	c := newTestClient(t,
+		"/client/v4/accounts/account/queues/queue/messages/ack",
+		`{"acks":[{"lease_id":"l1"}],"retries":[{"lease_id":"l2","delay_seconds":10}]}`,
+		`{"success":true,"errors":[],"result":{"ackCount":1,"retryCount":1,"warnings":[]}}`,
+		http.StatusOK,
+	)
+	batch := &Batch{Messages: []*Message{{LeaseID: "l1"}, {LeaseID: "l2"}}}
+	batch.Ack(batch.Messages[0])
+	batch.Retry(batch.Messages[1], 10)
+	result, err := c.Flush(context.Background(), batch)
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if result.AckCount != 1 || result.RetryCount != 1 {
+		t.Errorf("Flush() = %+v, want 1 ack and 1 retry", result)
+	}
+}
+
+func TestClient_apiError(t *testing.T) {
+	c := newTestClient(t,
+		"/client/v4/accounts/account/queues/queue/messages/pull",
+		`{"visibility_timeout_ms":30000}`,
+		`{"success":false,"errors":[{"code":10000,"message":"Authentication error"}],"result":null}`,
+		http.StatusForbidden,
+	)
+	_, err := c.Pull(context.Background(), nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Pull() error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || len(apiErr.Errors) != 1 || apiErr.Errors[0].Code != 10000 {
+		b, _ := json.Marshal(apiErr)
+		t.Errorf("APIError = %s", b)
+	}
+}
+
+// upperCodec encodes strings in upper case for tests.
+type upperCodec struct{}
+
+func (upperCodec) Marshal(v any) ([]byte, error) {
+	return []byte(strings.ToUpper(v.(string))), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v any) error {
+	*v.(*string) = strings.ToLower(string(data))
+	return nil
+}
+
+func TestMessage_UnmarshalCodec(t *testing.T) {
+	queues.RegisterCodec("application/x-upper", upperCodec{})
+	tagged := append([]byte("\x00QCD\x13application/x-upper"), "HELLO"...)
+	msg := &Message{
+		Metadata: map[string]string{"CF-Content-Type": "bytes"},
+		Body:     base64.StdEncoding.EncodeToString(tagged),
+	}
+	var got string
+	if err := msg.Unmarshal(&got); err != nil || got != "hello" {
+		t.Errorf("Unmarshal() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build js && wasm
+
+package queues
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Producer represents the producer binding of a queue.
+//   - https://developers.cloudflare.com/queues/configuration/javascript-apis/#producer
+type Producer struct {
+	instance js.Value
+}
+
+// NewProducer returns Producer for given variable name.
+//   - variable name must be defined in wrangler.toml as queues.producers's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewProducer(ctx context.Context, varName string) (*Producer, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Producer{instance: inst}, nil
+}
+
+func (opts *SendBatchOptions) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	if opts.DelaySeconds != 0 {
+		obj.Set("delaySeconds", opts.DelaySeconds)
+	}
+	return obj
+}
+
+// Send sends the body to the queue.
+//   - if the encoded body is larger than MaxMessageSize, returns ErrMessageTooLarge without sending.
+func (p *Producer) Send(body any, opts *SendOptions) error {
+	if opts == nil {
+		opts = &SendOptions{}
+	}
+	m, err := encodeMessage(body, opts.ContentType, opts.DelaySeconds)
+	if err != nil {
+		return err
+	}
+	bodyObj, err := m.body()
+	if err != nil {
+		return err
+	}
+	_, err = jsutil.AwaitPromise(p.instance.Call("send", bodyObj, m.options()))
+	return err
+}
+
+// SendBatch sends the messages to the queue at once.
+//   - if the batch exceeds the limits of Cloudflare Queues, returns error without sending.
+func (p *Producer) SendBatch(messages []*MessageSendRequest, opts *SendBatchOptions) error {
+	if opts != nil {
+		if err := validateDelay(opts.DelaySeconds); err != nil {
+			return err
+		}
+	}
+	encoded, err := encodeBatch(messages)
+	if err != nil {
+		return err
+	}
+	arr := jsutil.ArrayClass().New(len(encoded))
+	for i, m := range encoded {
+		body, err := m.body()
+		if err != nil {
+			return fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		obj := jsutil.NewObject()
+		obj.Set("body", body)
+		obj.Set("contentType", string(m.contentType))
+		if m.delaySeconds != 0 {
+			obj.Set("delaySeconds", m.delaySeconds)
+		}
+		arr.SetIndex(i, obj)
+	}
+	_, err = jsutil.AwaitPromise(p.instance.Call("sendBatch", arr, opts.toJS()))
+	return err
+}
+
+// body converts the encoded data to the JavaScript value sent to the queue.
+func (m *encodedMessage) body() (js.Value, error) {
+	switch m.contentType {
+	case ContentTypeJSON:
+		return jsutil.JSONClass().Call("parse", string(m.data)), nil
+	case ContentTypeText:
+		return js.ValueOf(string(m.data)), nil
+	case ContentTypeV8:
+		v, err := jsutil.ToJSValue(m.value)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("queues: error converting body: %w", err)
+		}
+		return v, nil
+	}
+	ua := jsutil.NewUint8Array(len(m.data))
+	js.CopyBytesToJS(ua, m.data)
+	return ua, nil
+}
+
+func (m *encodedMessage) options() js.Value {
+	obj := jsutil.NewObject()
+	obj.Set("contentType", string(m.contentType))
+	if m.delaySeconds != 0 {
+		obj.Set("delaySeconds", m.delaySeconds)
+	}
+	return obj
+}
@@ -1,45 +1,15 @@
+//go:build js && wasm
+
 package cloudflare
 
 import (
-	"errors"
 	"fmt"
 	"io"
 	"syscall/js"
-	"time"
 
 	"github.com/syumai/workers/internal/jsutil"
 )
 
-// R2Object represents Cloudflare R2 object.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1094
-type R2Object struct {
-	instance       js.Value
-	Key            string
-	Version        string
-	Size           int
-	ETag           string
-	HTTPETag       string
-	Uploaded       time.Time
-	HTTPMetadata   R2HTTPMetadata
-	CustomMetadata map[string]string
-	// Body is a body of R2Object.
-	// This value is nil for the result of the `Head` or `Put` method.
-	Body io.Reader
-}
-
-// TODO: implement
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1106
-// func (o *R2Object) WriteHTTPMetadata(headers http.Header) {
-// }
-
-func (o *R2Object) BodyUsed() (bool, error) {
-	v := o.instance.Get("bodyUsed")
-	if v.IsUndefined() {
-		return false, errors.New("bodyUsed doesn't exist for this R2Object")
-	}
-	return v.Bool(), nil
-}
-
 // toR2Object converts JavaScript side's R2Object to *R2Object.
 //   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1094
 func toR2Object(v js.Value) (*R2Object, error) {
@@ -57,7 +27,13 @@ func toR2Object(v js.Value) (*R2Object, error) {
 		body = jsutil.ConvertStreamReaderToReader(v.Get("body").Call("getReader"))
 	}
 	return &R2Object{
-		instance:       v,
+		bodyUsed: func() (bool, error) {
+			v := v.Get("bodyUsed")
+			if v.IsUndefined() {
+				return false, errBodyUsedNotExist
+			}
+			return v.Bool(), nil
+		},
 		Key:            v.Get("key").String(),
 		Version:        v.Get("version").String(),
 		Size:           v.Get("size").Int(),
@@ -70,17 +46,6 @@ func toR2Object(v js.Value) (*R2Object, error) {
 	}, nil
 }
 
-// R2HTTPMetadata represents metadata of R2Object.
-//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1053
-type R2HTTPMetadata struct {
-	ContentType        string
-	ContentLanguage    string
-	ContentDisposition string
-	ContentEncoding    string
-	CacheControl       string
-	CacheExpiry        time.Time
-}
-
 func toR2HTTPMetadata(v js.Value) (R2HTTPMetadata, error) {
 	cacheExpiry, err := jsutil.MaybeDate(v.Get("cacheExpiry"))
 	if err != nil {
@@ -0,0 +1,70 @@
+package cloudflare
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// R2Object represents Cloudflare R2 object.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1094
+type R2Object struct {
+	// bodyUsed reports whether the body of the JavaScript side's R2Object has been used.
+	bodyUsed       func() (bool, error)
+	Key            string
+	Version        string
+	Size           int
+	ETag           string
+	HTTPETag       string
+	Uploaded       time.Time
+	HTTPMetadata   R2HTTPMetadata
+	CustomMetadata map[string]string
+	// Body is a body of R2Object.
+	// This value is nil for the result of the `Head` or `Put` method.
+	Body io.Reader
+}
+
+var errBodyUsedNotExist = errors.New("bodyUsed doesn't exist for this R2Object")
+
+// TODO: implement
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1106
+// func (o *R2Object) WriteHTTPMetadata(headers http.Header) {
+// }
+
+// BodyUsed reports whether the body of R2Object has been used.
+func (o *R2Object) BodyUsed() (bool, error) {
+	if o.bodyUsed == nil {
+		return false, errBodyUsedNotExist
+	}
+	return o.bodyUsed()
+}
+
+// R2HTTPMetadata represents metadata of R2Object.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1053
+type R2HTTPMetadata struct {
+	ContentType        string
+	ContentLanguage    string
+	ContentDisposition string
+	ContentEncoding    string
+	CacheControl       string
+	CacheExpiry        time.Time
+}
+
+// R2Objects represents Cloudflare R2 objects.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1121
+type R2Objects struct {
+	Objects   []*R2Object
+	Truncated bool
+	// Cursor indicates next cursor of R2Objects.
+	//   - This becomes empty string if cursor doesn't exist.
+	Cursor            string
+	DelimitedPrefixes []string
+}
+
+// R2PutOptions represents Cloudflare R2 put options.
+//   - https://github.com/cloudflare/workers-types/blob/3012f263fb1239825e5f0061b267c8650d01b717/index.d.ts#L1128
+type R2PutOptions struct {
+	HTTPMetadata   R2HTTPMetadata
+	CustomMetadata map[string]string
+	MD5            string
+}
@@ -0,0 +1,249 @@
+// Package distributed provides the rate limiter whose counters are held by Durable Objects, one object per key.
+//
+// Unlike the Rate Limiting binding, the limit and the period are given in code, can differ per Limiter,
+// and counters are consistent globally.
+//   - TokenBucket allows bursts up to Burst, and refills Limit tokens per Period.
+//   - SlidingWindow allows Limit requests in the sliding window of Period, approximated by two fixed windows.
+//   - Each request is counted by the object, because state of the Go program doesn't outlive the request.
+//   - Counters are held in memory of the objects. if an object is evicted after being idle, its counter is reset.
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/ratelimit"
+)
+
+// Algorithm represents the algorithm of the rate limiter.
+type Algorithm int
+
+const (
+	TokenBucket Algorithm = iota
+	SlidingWindow
+)
+
+// Options represents the options of Limiter.
+type Options struct {
+	Algorithm Algorithm
+	// Limit is the number of requests allowed per Period.
+	Limit int
+	// Period is the period of Limit.
+	Period time.Duration
+	// Burst is the capacity of the bucket of TokenBucket. The default is Limit.
+	Burst int
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Result represents the result of Allow.
+type Result struct {
+	// Allowed reports whether the request is within the limit.
+	Allowed bool
+	// Remaining is the number of requests allowed after this request.
+	Remaining int
+	// RetryAfter is the duration after which the request may be allowed, if it's not allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter limits requests by keys with the Durable Object namespace.
+//   - The class of the namespace must be implemented by NewDurableObject.
+type Limiter struct {
+	ns   binding.DurableObjectNamespace
+	opts Options
+}
+
+var _ ratelimit.Limiter = (*Limiter)(nil)
+
+// NewLimiter returns Limiter.
+//   - This panics if Limit or Period is not positive.
+func NewLimiter(ns binding.DurableObjectNamespace, opts *Options) *Limiter {
+	if opts == nil || opts.Limit <= 0 || opts.Period <= 0 {
+		panic("distributed: Limit and Period of Options must be positive")
+	}
+	l := &Limiter{ns: ns, opts: *opts}
+	if l.opts.Burst <= 0 {
+		l.opts.Burst = l.opts.Limit
+	}
+	if l.opts.Now == nil {
+		l.opts.Now = time.Now
+	}
+	return l
+}
+
+// Allow counts a request for the key.
+func (l *Limiter) Allow(ctx context.Context, key string) (*Result, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN counts n requests (e.g. the cost of the request) for the key. All or none of n are allowed.
+func (l *Limiter) AllowN(ctx context.Context, key string, n int) (*Result, error) {
+	res, err := l.take(ctx, key, &takeRequest{
+		Algorithm: l.opts.Algorithm,
+		Limit:     l.opts.Limit,
+		Burst:     l.opts.Burst,
+		PeriodMs:  l.opts.Period.Milliseconds(),
+		N:         n,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Allowed:    res.Allowed,
+		Remaining:  res.Remaining,
+		RetryAfter: time.Duration(res.RetryAfterMs) * time.Millisecond,
+	}, nil
+}
+
+// Limit counts a request for the key, so Limiter can be used as the Limiter of `ratelimit.Middleware`.
+func (l *Limiter) Limit(key string) (*ratelimit.Outcome, error) {
+	res, err := l.Allow(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	return &ratelimit.Outcome{Success: res.Allowed}, nil
+}
+
+// takeRequest is the request to take tokens from the object. The options are sent with each request,
+// so the object doesn't need to be configured.
+type takeRequest struct {
+	Algorithm Algorithm `json:"algorithm"`
+	Limit     int       `json:"limit"`
+	Burst     int       `json:"burst"`
+	PeriodMs  int64     `json:"periodMs"`
+	// N is the number of tokens to take. All or none of N are taken.
+	N int `json:"n"`
+}
+
+type takeResponse struct {
+	Allowed      bool  `json:"allowed"`
+	Remaining    int   `json:"remaining"`
+	RetryAfterMs int64 `json:"retryAfterMs"`
+}
+
+func (l *Limiter) take(ctx context.Context, key string, body *takeRequest) (*takeResponse, error) {
+	stub, err := l.ns.Get(l.ns.IdFromName(key))
+	if err != nil {
+		return nil, err
+	}
+	b, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://ratelimit/", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	res, err := stub.Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("distributed: error fetching Durable Object: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("distributed: unexpected status of Durable Object: %s", res.Status)
+	}
+	var tr takeResponse
+	if err := json.NewDecoder(res.Body).Decode(&tr); err != nil {
+		return nil, fmt.Errorf("distributed: error decoding response: %w", err)
+	}
+	return &tr, nil
+}
+
+// NewDurableObject returns the handler of the Durable Object class used by Limiter.
+//
+//	durableobject.Register("RateLimiter", func(ctx context.Context, state *durableobject.State) durableobject.DurableObject {
+//		return distributed.NewDurableObject()
+//	})
+func NewDurableObject() http.Handler {
+	return &durableObject{now: time.Now}
+}
+
+type durableObject struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	// tokens and refilledAt are the state of TokenBucket.
+	tokens     float64
+	refilledAt time.Time
+	// windowStart, previous and current are the state of SlidingWindow.
+	windowStart time.Time
+	previous    int
+	current     int
+}
+
+func (o *durableObject) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	var tr takeRequest
+	if err := json.NewDecoder(req.Body).Decode(&tr); err != nil || tr.Limit <= 0 || tr.PeriodMs <= 0 || tr.N <= 0 {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	o.mu.Lock()
+	var res *takeResponse
+	if tr.Algorithm == SlidingWindow {
+		res = o.takeSlidingWindow(&tr, o.now())
+	} else {
+		res = o.takeTokenBucket(&tr, o.now())
+	}
+	o.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (o *durableObject) takeTokenBucket(tr *takeRequest, now time.Time) *takeResponse {
+	period := time.Duration(tr.PeriodMs) * time.Millisecond
+	burst := float64(tr.Burst)
+	if burst <= 0 {
+		burst = float64(tr.Limit)
+	}
+	// tokens per second.
+	rate := float64(tr.Limit) / period.Seconds()
+	if o.refilledAt.IsZero() {
+		o.tokens = burst
+	} else {
+		o.tokens = math.Min(burst, o.tokens+now.Sub(o.refilledAt).Seconds()*rate)
+	}
+	o.refilledAt = now
+	available := int(o.tokens)
+	if available < tr.N {
+		wait := (float64(tr.N) - o.tokens) / rate
+		return &takeResponse{Remaining: available, RetryAfterMs: int64(math.Ceil(wait * 1000))}
+	}
+	o.tokens -= float64(tr.N)
+	return &takeResponse{Allowed: true, Remaining: int(o.tokens)}
+}
+
+func (o *durableObject) takeSlidingWindow(tr *takeRequest, now time.Time) *takeResponse {
+	period := time.Duration(tr.PeriodMs) * time.Millisecond
+	if o.windowStart.IsZero() {
+		o.windowStart = now.Truncate(period)
+	}
+	switch elapsed := now.Sub(o.windowStart); {
+	case elapsed >= 2*period:
+		o.windowStart = now.Truncate(period)
+		o.previous, o.current = 0, 0
+	case elapsed >= period:
+		o.windowStart = o.windowStart.Add(period)
+		o.previous, o.current = o.current, 0
+	}
+	elapsed := now.Sub(o.windowStart)
+	weight := 1 - float64(elapsed)/float64(period)
+	used := int(math.Ceil(float64(o.previous)*weight)) + o.current
+	available := tr.Limit - used
+	if available < 0 {
+		available = 0
+	}
+	if available < tr.N {
+		// the count of the previous window decreases as the window slides, and is cleared at the end of the current window.
+		return &takeResponse{Remaining: available, RetryAfterMs: (period - elapsed).Milliseconds() + 1}
+	}
+	o.current += tr.N
+	return &takeResponse{Allowed: true, Remaining: available - tr.N}
+}
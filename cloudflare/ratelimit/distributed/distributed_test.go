@@ -0,0 +1,132 @@
+package distributed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+// testClock is the clock shared by the limiter and the objects.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestNamespace(clock *testClock, fetches *int) binding.DurableObjectNamespace {
+	return bindingtest.NewDurableObjectNamespace(func(id binding.DurableObjectID) http.Handler {
+		o := NewDurableObject().(*durableObject)
+		o.now = clock.Now
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			*fetches++
+			o.ServeHTTP(w, req)
+		})
+	})
+}
+
+func allowed(t *testing.T, l *Limiter, key string, n int) int {
+	t.Helper()
+	count := 0
+	for i := 0; i < n; i++ {
+		res, err := l.Allow(context.Background(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed {
+			count++
+		}
+	}
+	return count
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	var fetches int
+	l := NewLimiter(newTestNamespace(clock, &fetches), &Options{Limit: 10, Period: 10 * time.Second, Burst: 5, Now: clock.Now})
+	if got := allowed(t, l, "a", 8); got != 5 {
+		t.Errorf("allowed = %d, want burst 5", got)
+	}
+	res, _ := l.Allow(context.Background(), "a")
+	if res.Allowed || res.RetryAfter != time.Second {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	// the object counts each request, so denials are not cached by the limiter.
+	before := fetches
+	allowed(t, l, "a", 3)
+	if fetches != before+3 {
+		t.Errorf("fetches while denied = %d, want 3", fetches-before)
+	}
+	clock.now = clock.now.Add(2 * time.Second)
+	if got := allowed(t, l, "a", 3); got != 2 {
+		t.Errorf("allowed after refill = %d, want 2", got)
+	}
+	if got := allowed(t, l, "b", 1); got != 1 {
+		t.Errorf("other keys must not be limited")
+	}
+}
+
+func TestShared(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	var fetches int
+	ns := newTestNamespace(clock, &fetches)
+	opts := &Options{Limit: 10, Period: time.Minute, Now: clock.Now}
+	// limiters of different requests share the counter of the object.
+	first, second := NewLimiter(ns, opts), NewLimiter(ns, opts)
+	if got := allowed(t, first, "a", 6) + allowed(t, second, "a", 6); got != 10 {
+		t.Errorf("allowed = %d, want 10", got)
+	}
+	res, err := second.AllowN(context.Background(), "b", 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.Remaining != 10 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	// the clock starts at the beginning of the window.
+	clock := &testClock{now: time.Unix(1699999980, 0)}
+	var fetches int
+	l := NewLimiter(newTestNamespace(clock, &fetches), &Options{Algorithm: SlidingWindow, Limit: 10, Period: time.Minute, Now: clock.Now})
+	if got := allowed(t, l, "a", 15); got != 10 {
+		t.Errorf("allowed = %d, want 10", got)
+	}
+	// half of the previous window is counted in the middle of the next window.
+	clock.now = clock.now.Add(90 * time.Second)
+	if got := allowed(t, l, "a", 10); got != 5 {
+		t.Errorf("allowed = %d, want 5", got)
+	}
+	clock.now = clock.now.Add(2 * time.Minute)
+	if got := allowed(t, l, "a", 15); got != 10 {
+		t.Errorf("allowed = %d, want 10", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	var fetches int
+	l := NewLimiter(newTestNamespace(clock, &fetches), &Options{Limit: 1, Period: 30 * time.Second, Now: clock.Now})
+	h := Middleware(l, nil)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("CF-Connecting-IP", "203.0.113.1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := serve(); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("unexpected response: %d %v", rec.Code, rec.Header())
+	}
+	rec := serve()
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Errorf("unexpected response: %d %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,56 @@
+package distributed
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/ratelimit"
+)
+
+// MiddlewareOptions represents the options of Middleware.
+type MiddlewareOptions struct {
+	// KeyFunc returns the key of the request to be limited. The default value is `ratelimit.KeyByIP`.
+	// if KeyFunc returns an empty string, the request is not limited.
+	KeyFunc func(req *http.Request) string
+	// CostFunc returns the number of tokens of the request. The default is 1.
+	CostFunc func(req *http.Request) int
+}
+
+// Middleware returns the middleware which rejects requests exceeding the limit of l with 429 Too Many Requests.
+//   - The Retry-After header and `RateLimit-Remaining` header are set by the result.
+//   - if the limiter fails (e.g. the Durable Object can't be reached), the request is passed to the handler.
+func Middleware(l *Limiter, opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOptions{}
+	}
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = ratelimit.KeyByIP
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := keyFunc(req)
+			if key == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			cost := 1
+			if opts.CostFunc != nil {
+				cost = opts.CostFunc(req)
+			}
+			res, err := l.AllowN(req.Context(), key, cost)
+			if err != nil {
+				next.ServeHTTP(w, req)
+				return
+			}
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			if res.Allowed {
+				next.ServeHTTP(w, req)
+				return
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int((res.RetryAfter+time.Second-1)/time.Second)))
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+//go:build js && wasm
+
+package ratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MiddlewareOptions represents the options of Middleware.
+type MiddlewareOptions struct {
+	// Binding is the variable name of the rate limiter binding. Binding is ignored if Limiter is set.
+	Binding string
+	// Limiter is used instead of the binding, e.g. a fake in tests.
+	Limiter Limiter
+	// KeyFunc returns the key of the request to be limited. The default value is KeyByIP.
+	// if KeyFunc returns an empty string, the request is not limited.
+	KeyFunc func(req *http.Request) string
+	// Period is the period of the rate limiter, which is sent as the Retry-After header. The default value is 60 seconds.
+	Period time.Duration
+}
+
+const defaultPeriod = 60 * time.Second
+
+// Middleware returns the middleware which rejects requests exceeding the limit with 429 Too Many Requests.
+//   - if the rate limiter fails (e.g. the binding is not found), the request is passed to the handler.
+//   - This panics if neither Binding nor Limiter is set.
+func Middleware(opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOptions{}
+	}
+	if opts.Binding == "" && opts.Limiter == nil {
+		panic("ratelimit: Binding or Limiter of MiddlewareOptions must be set")
+	}
+	keyFunc := opts.KeyFunc
+	if keyFunc == nil {
+		keyFunc = KeyByIP
+	}
+	period := opts.Period
+	if period == 0 {
+		period = defaultPeriod
+	}
+	retryAfter := strconv.Itoa(int(period / time.Second))
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			key := keyFunc(req)
+			if key == "" {
+				next.ServeHTTP(w, req)
+				return
+			}
+			limiter := opts.Limiter
+			if limiter == nil {
+				rl, err := NewRateLimiter(req.Context(), opts.Binding)
+				if err != nil {
+					next.ServeHTTP(w, req)
+					return
+				}
+				limiter = rl
+			}
+			outcome, err := limiter.Limit(key)
+			if err != nil || outcome.Success {
+				next.ServeHTTP(w, req)
+				return
+			}
+			w.Header().Set("Retry-After", retryAfter)
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+//go:build js && wasm
+
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeLimiter map[string]int
+
+func (l fakeLimiter) Limit(key string) (*Outcome, error) {
+	l[key]--
+	return &Outcome{Success: l[key] >= 0}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	limiter := fakeLimiter{"key1": 1}
+	h := Middleware(&MiddlewareOptions{
+		Limiter: limiter,
+		KeyFunc: KeyByHeader("X-API-Key"),
+	})(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "key1")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("request %d: want %d, got %d", i, want, rec.Code)
+		}
+		if want == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "60" {
+			t.Errorf("unexpected Retry-After: %q", rec.Header().Get("Retry-After"))
+		}
+	}
+}
+
+func TestMiddleware_withoutLimiter(t *testing.T) {
+	for name, opts := range map[string]*MiddlewareOptions{
+		"nil options":   nil,
+		"empty options": {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Middleware must panic without the limiter")
+				}
+			}()
+			Middleware(opts)
+		})
+	}
+}
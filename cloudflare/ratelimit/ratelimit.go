@@ -0,0 +1,41 @@
+// Package ratelimit provides the binding of the Rate Limiting API.
+//   - https://developers.cloudflare.com/workers/runtime-apis/bindings/rate-limit/
+package ratelimit
+
+import (
+	"net"
+	"net/http"
+)
+
+// Outcome represents the result of Limit.
+type Outcome struct {
+	// Success reports whether the request is within the limit.
+	// if Success is false, the request exceeds the limit and should be rejected.
+	Success bool
+}
+
+// Limiter counts requests by keys. It is implemented by *RateLimiter.
+type Limiter interface {
+	Limit(key string) (*Outcome, error)
+}
+
+// KeyByIP returns the IP address of the client as the key.
+//   - The IP address is read from the CF-Connecting-IP header, which is set by Cloudflare.
+func KeyByIP(req *http.Request) string {
+	if ip := req.Header.Get("CF-Connecting-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// KeyByHeader returns KeyFunc which uses the value of the header as the key, e.g. API keys.
+//   - Requests without the header are not limited.
+func KeyByHeader(name string) func(req *http.Request) string {
+	return func(req *http.Request) string {
+		return req.Header.Get(name)
+	}
+}
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestKeyByIP(t *testing.T) {
+	tests := map[string]struct {
+		header     http.Header
+		remoteAddr string
+		want       string
+	}{
+		"CF-Connecting-IP": {
+			header:     http.Header{"Cf-Connecting-Ip": {"203.0.113.1"}},
+			remoteAddr: "192.0.2.1:1234",
+			want:       "203.0.113.1",
+		},
+		"remote address with port": {
+			header:     http.Header{},
+			remoteAddr: "192.0.2.1:1234",
+			want:       "192.0.2.1",
+		},
+		"remote address without port": {
+			header:     http.Header{},
+			remoteAddr: "192.0.2.1",
+			want:       "192.0.2.1",
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			req := &http.Request{Header: tc.header, RemoteAddr: tc.remoteAddr}
+			if got := KeyByIP(req); got != tc.want {
+				t.Errorf("want %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestKeyByHeader(t *testing.T) {
+	keyFunc := KeyByHeader("X-API-Key")
+	req := &http.Request{Header: http.Header{"X-Api-Key": {"key1"}}}
+	if got := keyFunc(req); got != "key1" {
+		t.Errorf("want key1, got %s", got)
+	}
+	if got := keyFunc(&http.Request{Header: http.Header{}}); got != "" {
+		t.Errorf("want empty key, got %s", got)
+	}
+}
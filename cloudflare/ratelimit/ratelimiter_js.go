@@ -0,0 +1,48 @@
+//go:build js && wasm
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// RateLimiter represents the binding of a rate limiter.
+type RateLimiter struct {
+	instance js.Value
+}
+
+// NewRateLimiter returns RateLimiter for given variable name.
+//   - variable name must be defined in wrangler.toml as unsafe.bindings's name with the type `ratelimit`.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewRateLimiter(ctx context.Context, varName string) (*RateLimiter, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &RateLimiter{instance: inst}, nil
+}
+
+// Limit counts a request for the key, and reports whether the key is within the limit.
+//   - The limit and period are configured in wrangler.toml.
+//   - Counters are local to the Cloudflare location, and are eventually consistent.
+func (r *RateLimiter) Limit(key string) (*Outcome, error) {
+	opts := jsutil.NewObject()
+	opts.Set("key", key)
+	promise, err := jsutil.TryCall(r.instance, "limit", opts)
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return &Outcome{
+		Success: v.Get("success").Bool(),
+	}, nil
+}
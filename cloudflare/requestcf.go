@@ -0,0 +1,110 @@
+package cloudflare
+
+import (
+	"context"
+	"strconv"
+)
+
+// IncomingRequestCF represents the `cf` property of incoming requests, which has the information of the client given by Cloudflare.
+//   - Fields are empty when the runtime doesn't provide them, e.g. geolocation of requests from private networks.
+//     `wrangler dev` gives the properties of the machine running it.
+//   - https://developers.cloudflare.com/workers/runtime-apis/request/#incomingrequestcfproperties
+type IncomingRequestCF struct {
+	// ASN is the ASN of the client, e.g. 395747.
+	ASN int `json:"asn"`
+	// ASOrganization is the organization of the ASN, e.g. `Google Cloud`.
+	ASOrganization string `json:"asOrganization"`
+	// Colo is the IATA code of the data center which received the request, e.g. `DFW`.
+	Colo string `json:"colo"`
+	// Continent is the continent code of the client, e.g. `NA`.
+	Continent string `json:"continent"`
+	// Country is the ISO 3166-1 Alpha 2 code of the country of the client, e.g. `US`.
+	Country string `json:"country"`
+	// IsEUCountry is `1` if the country is a member of the European Union, and empty otherwise.
+	IsEUCountry string `json:"isEUCountry"`
+	// City is the city of the client, e.g. `Austin`.
+	City string `json:"city"`
+	// Region is the name of the ISO 3166-2 region of the client, e.g. `Texas`.
+	Region string `json:"region"`
+	// RegionCode is the ISO 3166-2 code of the region without the country, e.g. `TX`.
+	RegionCode string `json:"regionCode"`
+	// PostalCode is the postal code of the client, e.g. `78701`.
+	PostalCode string `json:"postalCode"`
+	// MetroCode is the Nielsen Designated Market Area code of the client (only in the US), e.g. `635`.
+	MetroCode string `json:"metroCode"`
+	// Latitude is the latitude of the client, e.g. `30.27130`. Use Coordinates to get them as numbers.
+	Latitude string `json:"latitude"`
+	// Longitude is the longitude of the client, e.g. `-97.74260`.
+	Longitude string `json:"longitude"`
+	// Timezone is the IANA timezone of the client, e.g. `America/Chicago`.
+	Timezone string `json:"timezone"`
+	// HTTPProtocol is the HTTP protocol of the request, e.g. `HTTP/2`.
+	HTTPProtocol string `json:"httpProtocol"`
+	// TLSVersion is the TLS version of the request, e.g. `TLSv1.3`. it's empty for requests over plain HTTP.
+	TLSVersion string `json:"tlsVersion"`
+	// ClientTCPRTT is the round-trip time of the TCP connection to the client in milliseconds.
+	ClientTCPRTT int `json:"clientTcpRtt"`
+	// BotManagement is the result of Bot Management. it's nil if Bot Management is not enabled for the zone.
+	BotManagement *BotManagement `json:"botManagement"`
+	// ThreatScore is the threat score of the IP address of the client from 0 (not a threat) to 100. it's deprecated by Cloudflare,
+	// and always 0 for new zones; use the score of BotManagement instead.
+	ThreatScore int `json:"threatScore"`
+}
+
+// BotManagement represents the result of Bot Management of the request.
+//   - https://developers.cloudflare.com/bots/reference/bot-management-variables/
+type BotManagement struct {
+	// Score is the likelihood that the request came from a human from 1 (bot) to 99 (human). it's 0 if the request is not scored.
+	Score int `json:"score"`
+	// VerifiedBot reports whether the request came from a known good bot, e.g. crawlers of search engines.
+	VerifiedBot bool `json:"verifiedBot"`
+	// StaticResource reports whether the request is for a static resource, which is not scored.
+	StaticResource bool `json:"staticResource"`
+	// CorporateProxy reports whether the request came from a known corporate proxy or VPN.
+	CorporateProxy bool `json:"corporateProxy"`
+	// JA3Hash is the JA3 fingerprint of the TLS client. it's empty for requests over plain HTTP.
+	JA3Hash string `json:"ja3Hash"`
+	// JA4 is the JA4 fingerprint of the TLS client. it's empty for requests over plain HTTP.
+	JA4 string `json:"ja4"`
+	// JA4Signals are the statistics of requests from the JA4 fingerprint across Cloudflare, e.g. `h2h3_ratio_1h`.
+	JA4Signals map[string]float64 `json:"ja4Signals"`
+	// DetectionIDs are the IDs of heuristics which detected the request as a bot.
+	DetectionIDs []int `json:"detectionIds"`
+	// JSDetection is the result of JavaScript detections.
+	JSDetection struct {
+		// Passed reports whether the client passed JavaScript detections.
+		Passed bool `json:"passed"`
+	} `json:"jsDetection"`
+}
+
+// InEU reports whether the country of the client is a member of the European Union.
+func (cf *IncomingRequestCF) InEU() bool {
+	return cf.IsEUCountry == "1"
+}
+
+// Coordinates returns the latitude and the longitude of the client.
+//   - ok is false if the coordinates are not given or malformed.
+func (cf *IncomingRequestCF) Coordinates() (lat, lon float64, ok bool) {
+	lat, err := strconv.ParseFloat(cf.Latitude, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lon, err = strconv.ParseFloat(cf.Longitude, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lon, true
+}
+
+type incomingRequestCFKey struct{}
+
+// NewIncomingRequestCFContext returns the context with the `cf` property, which is returned by GetIncomingRequestCF
+// instead of the property of the request, e.g. to test handlers.
+func NewIncomingRequestCFContext(ctx context.Context, cf *IncomingRequestCF) context.Context {
+	return context.WithValue(ctx, incomingRequestCFKey{}, cf)
+}
+
+func incomingRequestCFFromContext(ctx context.Context) (*IncomingRequestCF, bool) {
+	cf, ok := ctx.Value(incomingRequestCFKey{}).(*IncomingRequestCF)
+	return cf, ok
+}
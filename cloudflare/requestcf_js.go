@@ -0,0 +1,32 @@
+//go:build js && wasm
+
+package cloudflare
+
+import (
+	"context"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// GetIncomingRequestCF returns the `cf` property of the request of the context.
+//   - ok is false if the context is not of the fetch event, or the request doesn't have the property (e.g. in tests).
+//   - The property is converted on each call, so the result should be reused in the request.
+func GetIncomingRequestCF(ctx context.Context) (cf *IncomingRequestCF, ok bool) {
+	if cf, ok := incomingRequestCFFromContext(ctx); ok {
+		return cf, true
+	}
+	reqObj, ok := runtimecontext.ExtractRequest(ctx)
+	if !ok {
+		return nil, false
+	}
+	v := reqObj.Get("cf")
+	if v.IsUndefined() || v.IsNull() {
+		return nil, false
+	}
+	cf = &IncomingRequestCF{}
+	if err := jsutil.Decode(v, cf); err != nil {
+		return nil, false
+	}
+	return cf, true
+}
@@ -0,0 +1,11 @@
+//go:build !(js && wasm)
+
+package cloudflare
+
+import "context"
+
+// GetIncomingRequestCF returns the `cf` property given by NewIncomingRequestCFContext, since requests of non-wasm builds
+// don't have the property.
+func GetIncomingRequestCF(ctx context.Context) (cf *IncomingRequestCF, ok bool) {
+	return incomingRequestCFFromContext(ctx)
+}
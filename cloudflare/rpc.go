@@ -0,0 +1,144 @@
+//go:build js && wasm
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// RPCMethod represents a method which can be called by Workers RPC.
+//   - args and the result are converted by the structured clone rules. See `jsutil.ToJSValue` and `jsutil.ToGoValue` for details.
+//   - if RPCMethod returns an error, the caller receives an error with the message.
+//   - https://developers.cloudflare.com/workers/runtime-apis/rpc/
+type RPCMethod func(ctx context.Context, args []any) (any, error)
+
+// RPCError represents an error thrown by the RPC method on the callee side.
+//   - Name is the name of the JavaScript error, e.g. `Error`, `TypeError` or `RangeError`.
+//   - errors returned by RPCMethod implemented in Go have the name `Error`.
+type RPCError struct {
+	Name    string
+	Message string
+}
+
+func (e *RPCError) Error() string {
+	return "rpc: " + e.Name + ": " + e.Message
+}
+
+// toRPCError converts the value which the RPC call is rejected with into error.
+func toRPCError(v js.Value) error {
+	if v.Type() != js.TypeObject || !v.InstanceOf(jsutil.ErrorClass()) {
+		// String converts undefined and null unlike toString, but throws for objects which can't be converted to primitives.
+		msg, err := jsutil.TryCall(jsutil.Global, "String", v)
+		if err != nil {
+			return &RPCError{Name: "Error", Message: "rejected with " + v.Type().String()}
+		}
+		return &RPCError{Name: "Error", Message: msg.String()}
+	}
+	return &RPCError{
+		Name:    v.Get("name").String(),
+		Message: v.Get("message").String(),
+	}
+}
+
+// RPCPromise represents the result of an RPC call which is not awaited yet.
+//   - Methods and properties of the result can be called through RPCPromise without waiting for the result.
+//     These calls are sent with the first call at once (promise pipelining), which saves round trips.
+//   - https://developers.cloudflare.com/workers/runtime-apis/rpc/#promise-pipelining
+type RPCPromise struct {
+	val js.Value
+}
+
+// Await waits for the result of the call, and returns the result converted to Go value.
+//   - if the call is rejected, returns *RPCError.
+func (p *RPCPromise) Await() (any, error) {
+	v, err := awaitRPC(p.val)
+	if err != nil {
+		return nil, err
+	}
+	return jsutil.ToGoValue(v), nil
+}
+
+// AwaitInto waits for the result of the call, and decodes the result into out by `jsutil.Decode`.
+//   - This is useful to decode results into structs. field names can be changed by `json` tag.
+func (p *RPCPromise) AwaitInto(out any) error {
+	v, err := awaitRPC(p.val)
+	if err != nil {
+		return err
+	}
+	if err := jsutil.Decode(v, out); err != nil {
+		return fmt.Errorf("rpc: error decoding result: %w", err)
+	}
+	return nil
+}
+
+// Get returns the property of the result without waiting for the result.
+func (p *RPCPromise) Get(property string) *RPCPromise {
+	return &RPCPromise{val: p.val.Get(property)}
+}
+
+// Call calls the method of the result with args, and awaits its result.
+func (p *RPCPromise) Call(method string, args ...any) (any, error) {
+	return callRPC(p.val, method, args)
+}
+
+// Pipeline calls the method of the result with args without waiting for the result.
+func (p *RPCPromise) Pipeline(method string, args ...any) (*RPCPromise, error) {
+	return invokeRPC(p.val, method, args)
+}
+
+// Call calls the RPC method of the Durable Object with args.
+//   - The Durable Object class must define the method as a public method.
+//     Durable Objects implemented in Go define RPC methods by `durableobject.RPCHandler`.
+//   - args are converted to JavaScript values by the same rules as Durable Object storage's Put.
+//   - The result is converted to Go value by the same rules as Durable Object storage's Get.
+//   - if the method throws an error, returns *RPCError.
+//
+// https://developers.cloudflare.com/durable-objects/best-practices/create-durable-object-stubs-and-send-requests/#invoke-rpc-methods
+func (s *DurableObjectStub) Call(method string, args ...any) (any, error) {
+	return callRPC(s.val, method, args)
+}
+
+// Pipeline calls the RPC method of the Durable Object with args without waiting for the result.
+func (s *DurableObjectStub) Pipeline(method string, args ...any) (*RPCPromise, error) {
+	return invokeRPC(s.val, method, args)
+}
+
+// invokeRPC calls the RPC method of the stub, and returns the result without awaiting.
+func invokeRPC(stub js.Value, method string, args []any) (*RPCPromise, error) {
+	jsArgs := make([]any, len(args))
+	for i, arg := range args {
+		v, err := jsutil.ToJSValue(arg)
+		if err != nil {
+			return nil, fmt.Errorf("error converting args[%d]: %w", i, err)
+		}
+		jsArgs[i] = v
+	}
+	promise, err := jsutil.TryCall(stub, method, jsArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCPromise{val: promise}, nil
+}
+
+// callRPC calls the RPC method of the stub and awaits its result.
+func callRPC(stub js.Value, method string, args []any) (any, error) {
+	p, err := invokeRPC(stub, method, args)
+	if err != nil {
+		return nil, err
+	}
+	return p.Await()
+}
+
+// awaitRPC awaits the result of the RPC call. unlike `jsutil.AwaitPromise`, the rejected value is kept as *RPCError.
+func awaitRPC(promise js.Value) (js.Value, error) {
+	// promise can be a thenable of RPC stubs, so it's resolved to a Promise first.
+	v, fulfilled := jsutil.AwaitSettled(jsutil.PromiseClass().Call("resolve", promise))
+	if !fulfilled {
+		return js.Value{}, toRPCError(v)
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+// Package secretsstore provides the binding of secrets in Secrets Store.
+//   - https://developers.cloudflare.com/secrets-store/
+package secretsstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	// ErrSecretNotFound is returned when the secret doesn't exist in the store.
+	ErrSecretNotFound = errors.New("secretsstore: secret not found")
+	// ErrForbidden is returned when the worker isn't allowed to access the secret.
+	ErrForbidden = errors.New("secretsstore: access to secret forbidden")
+)
+
+// Error represents an error returned by Secrets Store.
+//   - if the error is classified, errors.Is reports true for the corresponding Err* variable.
+type Error struct {
+	// Message is the error message returned by Secrets Store.
+	Message string
+	kind    error
+}
+
+func (e *Error) Error() string {
+	return "secretsstore: " + e.Message
+}
+
+// Unwrap returns the classified Err* variable, or nil if the error is not classified.
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// errorKinds is a list of substrings of Secrets Store error messages and corresponding error kinds.
+var errorKinds = []struct {
+	substr string
+	kind   error
+}{
+	{"not found", ErrSecretNotFound},
+	{"does not exist", ErrSecretNotFound},
+	{"forbidden", ErrForbidden},
+	{"permission", ErrForbidden},
+	{"not authorized", ErrForbidden},
+	{"unauthorized", ErrForbidden},
+}
+
+// toError converts the error occurred on Secrets Store call into *Error.
+func toError(err error) error {
+	msg := strings.TrimPrefix(err.Error(), "failed on promise: ")
+	msg = strings.TrimPrefix(msg, "Error: ")
+	lower := strings.ToLower(msg)
+	e := &Error{Message: msg}
+	for _, k := range errorKinds {
+		if strings.Contains(lower, k.substr) {
+			e.kind = k.kind
+			break
+		}
+	}
+	return e
+}
+
+// Secret represents the binding of a secret in Secrets Store.
+//   - Unlike environment variables, the value is fetched from the store when Get is called.
+type Secret struct {
+	instance js.Value
+	mu       sync.Mutex
+	value    string
+	cached   bool
+}
+
+// NewSecret returns Secret for given variable name.
+//   - variable name must be defined in wrangler.toml as secrets_store_secrets's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewSecret(ctx context.Context, varName string) (*Secret, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Secret{instance: inst}, nil
+}
+
+// Get returns the value of the secret.
+//   - The value is cached in Secret after the first successful call, so Secret should be reused in a request.
+//     Errors are not cached, and the next call fetches the value again.
+//   - if the secret is missing or forbidden, returns *Error which matches ErrSecretNotFound or ErrForbidden.
+func (s *Secret) Get() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached {
+		return s.value, nil
+	}
+	promise, err := jsutil.TryCall(s.instance, "get")
+	if err != nil {
+		return "", toError(err)
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return "", toError(err)
+	}
+	s.value, s.cached = v.String(), true
+	return s.value, nil
+}
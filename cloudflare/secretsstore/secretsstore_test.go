@@ -0,0 +1,53 @@
+package secretsstore
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestToError(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		wantKind error
+		wantMsg  string
+	}{
+		"not found": {
+			err:      errors.New("failed on promise: Error: Secret not found"),
+			wantKind: ErrSecretNotFound,
+			wantMsg:  "Secret not found",
+		},
+		"forbidden": {
+			err:      errors.New("failed on promise: Error: Forbidden"),
+			wantKind: ErrForbidden,
+			wantMsg:  "Forbidden",
+		},
+		"unclassified": {
+			err:     errors.New("failed on promise: Error: internal error"),
+			wantMsg: "internal error",
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			err := toError(tc.err)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("want *Error, got %T", err)
+			}
+			if e.Message != tc.wantMsg {
+				t.Errorf("want message %q, got %q", tc.wantMsg, e.Message)
+			}
+			if tc.wantKind == nil {
+				if e.Unwrap() != nil {
+					t.Errorf("want unclassified error, got %v", e.Unwrap())
+				}
+				return
+			}
+			if !errors.Is(err, tc.wantKind) {
+				t.Errorf("want %v, got %v", tc.wantKind, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+//go:build js && wasm
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jshttp"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Service represents the service binding, which sends requests and RPC calls to another worker.
+//   - https://developers.cloudflare.com/workers/runtime-apis/bindings/service-bindings/
+type Service struct {
+	instance js.Value
+}
+
+// NewService returns Service for given variable name.
+//   - variable name must be defined in wrangler.toml as services's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewService(ctx context.Context, varName string) (*Service, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Service{instance: inst}, nil
+}
+
+// Fetch sends the request to the fetch handler of the worker.
+func (s *Service) Fetch(req *http.Request) (*http.Response, error) {
+	promise, err := jsutil.TryCall(s.instance, "fetch", jshttp.ToJSRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	jsRes, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return jshttp.ToResponse(jsRes)
+}
+
+// HTTPClient returns *http.Client which sends requests to the fetch handler of the worker.
+func (s *Service) HTTPClient(redirect fetch.RedirectMode) *http.Client {
+	return fetch.NewClient(fetch.WithBinding(s.instance)).HTTPClient(redirect)
+}
+
+// Call calls the RPC method of the worker with args, and awaits its result.
+//   - The worker must define the method in WorkerEntrypoint (or the default export).
+//   - args and the result are converted by the same rules as `DurableObjectStub.Call`.
+//   - if the method throws an error, returns *RPCError.
+//   - https://developers.cloudflare.com/workers/runtime-apis/rpc/
+func (s *Service) Call(method string, args ...any) (any, error) {
+	return callRPC(s.instance, method, args)
+}
+
+// Pipeline calls the RPC method of the worker with args without waiting for the result.
+//   - Methods of the result can be called through RPCPromise by promise pipelining.
+func (s *Service) Pipeline(method string, args ...any) (*RPCPromise, error) {
+	return invokeRPC(s.instance, method, args)
+}
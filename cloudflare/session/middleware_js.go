@@ -0,0 +1,335 @@
+//go:build js && wasm
+
+package session
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+const (
+	// DefaultCookieName is the name of the cookie of sessions.
+	DefaultCookieName = "session"
+	// DefaultMaxAge is the duration for which sessions are kept without requests.
+	DefaultMaxAge = 24 * time.Hour
+	// maxCookieSize is the maximum size of cookies accepted by browsers.
+	maxCookieSize = 4096
+	// ivSize is the size of the IV of AES-GCM.
+	ivSize = 12
+)
+
+// Options represents the options of Middleware.
+type Options struct {
+	// Keys are the secret keys of cookies. The first key signs or encrypts cookies, and all keys are tried to read them,
+	// so keys can be rotated by adding the new key to the head. Keys should be random bytes of at least 32 bytes.
+	Keys [][]byte
+	// Store stores the data of sessions. if Store is nil, the data is encrypted and stored in the cookie,
+	// which must be smaller than 4 KiB.
+	Store Store
+	// CookieName is the name of the cookie. The default is DefaultCookieName.
+	CookieName string
+	// MaxAge is the duration for which sessions are kept without requests. The default is DefaultMaxAge.
+	MaxAge time.Duration
+	// Path is the path of the cookie. The default is `/`.
+	Path string
+	// Domain is the domain of the cookie. The default is the host of the request.
+	Domain string
+	// SameSite is the SameSite attribute of the cookie. The default is http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// Insecure disables the Secure attribute of the cookie, e.g. for `wrangler dev` on http.
+	Insecure bool
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Middleware returns the middleware which loads the session of requests, and saves it before the response is written.
+//   - The session can be obtained by FromContext in the handler. Sessions are created if the request doesn't have valid cookies.
+//   - Cookies of new sessions are set only when the data is set, so requests without sessions (e.g. of bots) don't create them.
+//   - Expiry is extended when the session is modified, or less than half of MaxAge remains.
+//   - Errors of saving sessions are logged, since the response is already being written.
+//   - This panics if Keys is empty.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	if opts == nil || len(opts.Keys) == 0 {
+		panic("session: Keys of Options must be set")
+	}
+	m := &manager{opts: *opts}
+	if m.opts.CookieName == "" {
+		m.opts.CookieName = DefaultCookieName
+	}
+	if m.opts.MaxAge <= 0 {
+		m.opts.MaxAge = DefaultMaxAge
+	}
+	if m.opts.Path == "" {
+		m.opts.Path = "/"
+	}
+	if m.opts.SameSite == 0 {
+		m.opts.SameSite = http.SameSiteLaxMode
+	}
+	if m.opts.Now == nil {
+		m.opts.Now = time.Now
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := m.importKeys(); err != nil {
+				log.Printf("%v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			s, err := m.load(req)
+			if err != nil {
+				log.Printf("%v", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			sw := &responseWriter{ResponseWriter: w, save: func() {
+				if err := m.save(req.Context(), w, s); err != nil {
+					log.Printf("%v", err)
+				}
+			}}
+			next.ServeHTTP(sw, req.WithContext(context.WithValue(req.Context(), sessionKey{}, s)))
+			sw.commit()
+		})
+	}
+}
+
+// manager loads and saves sessions with the keys.
+type manager struct {
+	opts Options
+
+	once sync.Once
+	// signKeys are HMAC keys to sign IDs of sessions stored in Store.
+	signKeys []*webcrypto.Key
+	// encryptKeys are AES-GCM keys to encrypt sessions stored in cookies.
+	encryptKeys []*webcrypto.Key
+	err         error
+}
+
+// importKeys imports the keys at the first request, so Middleware can be created at the start up of the worker.
+func (m *manager) importKeys() error {
+	m.once.Do(func() {
+		for _, k := range m.opts.Keys {
+			if m.opts.Store != nil {
+				key, err := webcrypto.ImportKey(webcrypto.FormatRaw, k, &webcrypto.HMAC{Hash: webcrypto.SHA256}, false, webcrypto.UsageSign, webcrypto.UsageVerify)
+				if err != nil {
+					m.err = fmt.Errorf("session: error importing key: %w", err)
+					return
+				}
+				m.signKeys = append(m.signKeys, key)
+				continue
+			}
+			// keys of any size are derived to AES-256 keys.
+			raw, err := webcrypto.Digest(webcrypto.SHA256, k)
+			if err != nil {
+				m.err = fmt.Errorf("session: error deriving key: %w", err)
+				return
+			}
+			key, err := webcrypto.ImportKey(webcrypto.FormatRaw, raw, &webcrypto.AESGCM{}, false, webcrypto.UsageEncrypt, webcrypto.UsageDecrypt)
+			if err != nil {
+				m.err = fmt.Errorf("session: error importing key: %w", err)
+				return
+			}
+			m.encryptKeys = append(m.encryptKeys, key)
+		}
+	})
+	return m.err
+}
+
+// load returns the session of the cookie, or the new session if the cookie is not valid.
+func (m *manager) load(req *http.Request) (*Session, error) {
+	s, err := newSession()
+	if err != nil {
+		return nil, err
+	}
+	c, err := req.Cookie(m.opts.CookieName)
+	if err != nil || c.Value == "" {
+		return s, nil
+	}
+	now := m.opts.Now()
+	if m.opts.Store == nil {
+		data, ok := m.decrypt(c.Value)
+		if !ok {
+			return s, nil
+		}
+		rec, ok := decodeRecord(data, now)
+		if !ok || rec.ID == "" {
+			return s, nil
+		}
+		return &Session{id: rec.ID, rec: rec}, nil
+	}
+	id, ok := m.verify(c.Value)
+	if !ok {
+		return s, nil
+	}
+	data, err := m.opts.Store.Load(req.Context(), id)
+	if err != nil {
+		return nil, fmt.Errorf("session: error loading session: %w", err)
+	}
+	if data == nil {
+		return s, nil
+	}
+	rec, ok := decodeRecord(data, now)
+	if !ok {
+		return s, nil
+	}
+	return &Session{id: id, rec: rec}, nil
+}
+
+// save writes the cookie of the session, and stores the session in Store.
+func (m *manager) save(ctx context.Context, w http.ResponseWriter, s *Session) error {
+	store := m.opts.Store
+	if store != nil && s.oldID != "" {
+		if err := store.Delete(ctx, s.oldID); err != nil {
+			return fmt.Errorf("session: error deleting session: %w", err)
+		}
+	}
+	if s.destroyed {
+		if s.isNew {
+			return nil
+		}
+		http.SetCookie(w, m.cookie("", -1))
+		if store == nil {
+			return nil
+		}
+		if err := store.Delete(ctx, s.id); err != nil {
+			return fmt.Errorf("session: error deleting session: %w", err)
+		}
+		return nil
+	}
+	now := m.opts.Now()
+	if s.isNew && s.empty() {
+		return nil
+	}
+	if !s.modified && !s.isNew && time.Unix(s.rec.ExpiresAt, 0).Sub(now) > m.opts.MaxAge/2 {
+		return nil
+	}
+	s.rec.ExpiresAt = now.Add(m.opts.MaxAge).Unix()
+	maxAge := int(m.opts.MaxAge / time.Second)
+	if store != nil {
+		if err := store.Save(ctx, s.id, s.encode(), m.opts.MaxAge); err != nil {
+			return fmt.Errorf("session: error saving session: %w", err)
+		}
+		value, err := m.sign(s.id)
+		if err != nil {
+			return err
+		}
+		http.SetCookie(w, m.cookie(value, maxAge))
+		return nil
+	}
+	s.rec.ID = s.id
+	value, err := m.encrypt(s.encode())
+	if err != nil {
+		return err
+	}
+	c := m.cookie(value, maxAge)
+	if len(c.String()) > maxCookieSize {
+		return errCookieTooLarge
+	}
+	http.SetCookie(w, c)
+	return nil
+}
+
+var errCookieTooLarge = errors.New("session: cookie is too large; use Store for large sessions")
+
+func (m *manager) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.opts.CookieName,
+		Value:    value,
+		Path:     m.opts.Path,
+		Domain:   m.opts.Domain,
+		MaxAge:   maxAge,
+		Secure:   !m.opts.Insecure,
+		HttpOnly: true,
+		SameSite: m.opts.SameSite,
+	}
+}
+
+// sign returns the ID with the signature: `id.signature`.
+func (m *manager) sign(id string) (string, error) {
+	sig, err := webcrypto.Sign(&webcrypto.HMAC{Hash: webcrypto.SHA256}, m.signKeys[0], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("session: error signing cookie: %w", err)
+	}
+	return id + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// verify returns the ID of the signed value, and reports whether the signature is valid by any key.
+func (m *manager) verify(value string) (string, bool) {
+	id, encoded, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	for _, key := range m.signKeys {
+		if ok, err := webcrypto.Verify(&webcrypto.HMAC{Hash: webcrypto.SHA256}, key, sig, []byte(id)); err == nil && ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// encrypt returns the encrypted data: `base64url(iv || ciphertext)`. The name of the cookie is authenticated,
+// so cookies can't be replayed as cookies of other names.
+func (m *manager) encrypt(data []byte) (string, error) {
+	iv := make([]byte, ivSize)
+	if _, err := webcrypto.Read(iv); err != nil {
+		return "", err
+	}
+	ciphertext, err := webcrypto.Encrypt(&webcrypto.AESGCM{IV: iv, AdditionalData: []byte(m.opts.CookieName)}, m.encryptKeys[0], data)
+	if err != nil {
+		return "", fmt.Errorf("session: error encrypting cookie: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(append(iv, ciphertext...)), nil
+}
+
+// decrypt returns the data of the encrypted value, and reports whether it's decrypted by any key.
+func (m *manager) decrypt(value string) ([]byte, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) <= ivSize {
+		return nil, false
+	}
+	iv, ciphertext := b[:ivSize], b[ivSize:]
+	for _, key := range m.encryptKeys {
+		data, err := webcrypto.Decrypt(&webcrypto.AESGCM{IV: iv, AdditionalData: []byte(m.opts.CookieName)}, key, ciphertext)
+		if err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// responseWriter is http.ResponseWriter which saves the session before the header is written.
+type responseWriter struct {
+	http.ResponseWriter
+	save      func()
+	committed bool
+}
+
+// commit saves the session once.
+func (w *responseWriter) commit() {
+	if !w.committed {
+		w.committed = true
+		w.save()
+	}
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(data)
+}
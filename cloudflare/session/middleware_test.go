@@ -0,0 +1,153 @@
+//go:build js && wasm
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+// client sends requests to the handler with the cookies of the previous responses.
+type client struct {
+	t       *testing.T
+	handler http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func (c *client) do(path string) *httptest.ResponseRecorder {
+	c.t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "https://example.com"+path, nil)
+	for _, cookie := range c.cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.MaxAge < 0 {
+			delete(c.cookies, cookie.Name)
+			continue
+		}
+		c.cookies[cookie.Name] = cookie
+	}
+	return rec
+}
+
+func testHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s, ok := FromContext(req.Context())
+		if !ok {
+			t.Fatal("session is not in the context")
+		}
+		switch req.URL.Path {
+		case "/login":
+			if err := s.Regenerate(); err != nil {
+				t.Fatal(err)
+			}
+			s.Set("user", "alice")
+			s.AddFlash("notice", "welcome")
+		case "/logout":
+			s.Destroy()
+		}
+		w.Write([]byte(s.Get("user") + ";" + strings.Join(s.Flashes("notice"), ",")))
+	})
+}
+
+func testMiddleware(t *testing.T, opts *Options) {
+	c := &client{t: t, handler: Middleware(opts)(testHandler(t)), cookies: map[string]*http.Cookie{}}
+	if rec := c.do("/"); rec.Body.String() != ";" || len(c.cookies) != 0 {
+		t.Fatalf("anonymous: %q, cookies %v", rec.Body.String(), c.cookies)
+	}
+	if rec := c.do("/login"); rec.Body.String() != "alice;welcome" {
+		t.Fatalf("login: %q", rec.Body.String())
+	}
+	cookie := c.cookies[DefaultCookieName]
+	if cookie == nil || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode || cookie.MaxAge != int(DefaultMaxAge/time.Second) {
+		t.Fatalf("cookie: %+v", cookie)
+	}
+	if rec := c.do("/"); rec.Body.String() != "alice;" {
+		t.Fatalf("after login: %q", rec.Body.String())
+	}
+
+	tampered := *c.cookies[DefaultCookieName]
+	if tampered.Value[0] == 'x' {
+		tampered.Value = "y" + tampered.Value[1:]
+	} else {
+		tampered.Value = "x" + tampered.Value[1:]
+	}
+	forged := &client{t: t, handler: c.handler, cookies: map[string]*http.Cookie{DefaultCookieName: &tampered}}
+	if rec := forged.do("/"); rec.Body.String() != ";" {
+		t.Fatalf("tampered cookie: %q", rec.Body.String())
+	}
+
+	if rec := c.do("/logout"); rec.Body.String() != ";" || len(c.cookies) != 0 {
+		t.Fatalf("logout: %q, cookies %v", rec.Body.String(), c.cookies)
+	}
+}
+
+func TestMiddlewareCookieStore(t *testing.T) {
+	testMiddleware(t, &Options{Keys: [][]byte{[]byte("secret")}})
+}
+
+func TestMiddlewareKVStore(t *testing.T) {
+	kv := bindingtest.NewKV()
+	testMiddleware(t, &Options{Keys: [][]byte{[]byte("secret")}, Store: KVStore(kv, "session:")})
+	res, err := kv.List(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Keys) != 0 {
+		t.Errorf("sessions must be deleted by Regenerate and Destroy: %v", res.Keys)
+	}
+}
+
+func TestMiddlewareKeyRotation(t *testing.T) {
+	for name, store := range map[string]Store{"cookie": nil, "kv": KVStore(bindingtest.NewKV(), "")} {
+		t.Run(name, func(t *testing.T) {
+			old := Middleware(&Options{Keys: [][]byte{[]byte("old")}, Store: store})(testHandler(t))
+			c := &client{t: t, handler: old, cookies: map[string]*http.Cookie{}}
+			c.do("/login")
+
+			c.handler = Middleware(&Options{Keys: [][]byte{[]byte("new"), []byte("old")}, Store: store})(testHandler(t))
+			if rec := c.do("/"); rec.Body.String() != "alice;" {
+				t.Fatalf("cookie of the old key: %q", rec.Body.String())
+			}
+			c.handler = Middleware(&Options{Keys: [][]byte{[]byte("new")}, Store: store})(testHandler(t))
+			if rec := c.do("/"); rec.Body.String() != ";" {
+				t.Fatalf("cookie of the removed key: %q", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestMiddlewareSlidingExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	handler := Middleware(&Options{
+		Keys:   [][]byte{[]byte("secret")},
+		MaxAge: time.Hour,
+		Now:    func() time.Time { return now },
+	})(testHandler(t))
+	c := &client{t: t, handler: handler, cookies: map[string]*http.Cookie{}}
+	c.do("/login")
+
+	now = now.Add(10 * time.Minute)
+	if rec := c.do("/"); len(rec.Result().Cookies()) != 0 {
+		t.Error("cookie must not be refreshed while more than half of MaxAge remains")
+	}
+	now = now.Add(40 * time.Minute)
+	if rec := c.do("/"); len(rec.Result().Cookies()) != 1 {
+		t.Error("cookie must be refreshed after half of MaxAge")
+	}
+	now = now.Add(50 * time.Minute)
+	if rec := c.do("/"); rec.Body.String() != "alice;" {
+		t.Fatalf("refreshed session: %q", rec.Body.String())
+	}
+	now = now.Add(2 * time.Hour)
+	if rec := c.do("/"); rec.Body.String() != ";" {
+		t.Fatalf("expired session: %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,162 @@
+// Package session provides cookie-based sessions for net/http handlers.
+//
+// Middleware loads the session of the request, which is obtained by FromContext in the handler,
+// and saves it before the response is written.
+//   - Without Store, the data of the session is encrypted by AES-GCM and stored in the cookie.
+//   - With Store (KVStore or DurableObjectStore), the data is stored on the server, and the cookie holds the ID signed by HMAC.
+//   - Cookies are protected by the Web Crypto API of the runtime. Multiple keys can be given to rotate them.
+//   - Expiry is sliding: sessions expire after MaxAge without requests.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Session represents the session of the request.
+//   - Session is not safe for concurrent use.
+type Session struct {
+	id  string
+	rec record
+	// isNew reports whether the session is created by the request.
+	isNew     bool
+	modified  bool
+	destroyed bool
+	// oldID is the ID of the session before Regenerate, which is deleted from the store.
+	oldID string
+}
+
+// record is the data of the session encoded as JSON.
+type record struct {
+	// ID is the ID of the session stored in the cookie. It's empty for sessions stored in Store.
+	ID      string              `json:"i,omitempty"`
+	Values  map[string]string   `json:"v,omitempty"`
+	Flashes map[string][]string `json:"f,omitempty"`
+	// ExpiresAt is the time in seconds since the Unix epoch.
+	ExpiresAt int64 `json:"e"`
+}
+
+func newSession() (*Session, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{id: id, isNew: true}, nil
+}
+
+// ID returns the ID of the session. Sessions stored in cookies also have IDs, which are not sent to the client.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsNew reports whether the session is created by the request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get returns the value of the key. if the key is not set, returns an empty string.
+func (s *Session) Get(key string) string {
+	return s.rec.Values[key]
+}
+
+// Lookup returns the value of the key, and reports whether the key is set.
+func (s *Session) Lookup(key string) (string, bool) {
+	v, ok := s.rec.Values[key]
+	return v, ok
+}
+
+// Set sets the value of the key.
+func (s *Session) Set(key, value string) {
+	if s.rec.Values == nil {
+		s.rec.Values = map[string]string{}
+	}
+	s.rec.Values[key] = value
+	s.modified = true
+}
+
+// Delete deletes the key.
+func (s *Session) Delete(key string) {
+	if _, ok := s.rec.Values[key]; ok {
+		delete(s.rec.Values, key)
+		s.modified = true
+	}
+}
+
+// AddFlash adds the flash message to the key, which is removed when it's read by Flashes, e.g. in the next request.
+func (s *Session) AddFlash(key, message string) {
+	if s.rec.Flashes == nil {
+		s.rec.Flashes = map[string][]string{}
+	}
+	s.rec.Flashes[key] = append(s.rec.Flashes[key], message)
+	s.modified = true
+}
+
+// Flashes returns the flash messages of the key, and removes them.
+func (s *Session) Flashes(key string) []string {
+	messages, ok := s.rec.Flashes[key]
+	if !ok {
+		return nil
+	}
+	delete(s.rec.Flashes, key)
+	s.modified = true
+	return messages
+}
+
+// Regenerate changes the ID of the session with the data kept. Call Regenerate when the privilege changes
+// (e.g. after login), which prevents session fixation.
+func (s *Session) Regenerate() error {
+	id, err := newID()
+	if err != nil {
+		return err
+	}
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.id
+	}
+	s.id = id
+	s.modified = true
+	return nil
+}
+
+// Destroy deletes the data of the session, and expires the cookie (e.g. on logout).
+func (s *Session) Destroy() {
+	s.rec = record{}
+	s.destroyed = true
+}
+
+// empty reports whether the session has no data.
+func (s *Session) empty() bool {
+	return len(s.rec.Values) == 0 && len(s.rec.Flashes) == 0
+}
+
+func (s *Session) encode() []byte {
+	b, _ := json.Marshal(&s.rec)
+	return b
+}
+
+// decodeRecord decodes the data of the session, and reports whether it's valid and not expired.
+func decodeRecord(b []byte, now time.Time) (record, bool) {
+	var rec record
+	if err := json.Unmarshal(b, &rec); err != nil || now.Unix() >= rec.ExpiresAt {
+		return record{}, false
+	}
+	return rec, true
+}
+
+type sessionKey struct{}
+
+// FromContext returns the session of the request loaded by Middleware.
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(sessionKey{}).(*Session)
+	return s, ok
+}
+
+func newID() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
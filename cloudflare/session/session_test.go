@@ -0,0 +1,171 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding"
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+func TestSession(t *testing.T) {
+	s, err := newSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.IsNew() || s.ID() == "" {
+		t.Fatalf("new session: IsNew %v, ID %q", s.IsNew(), s.ID())
+	}
+	if !s.empty() || s.modified {
+		t.Fatal("new session must be empty and not modified")
+	}
+	s.Set("user", "alice")
+	if got := s.Get("user"); got != "alice" {
+		t.Errorf("Get: %q", got)
+	}
+	if _, ok := s.Lookup("missing"); ok {
+		t.Error("Lookup of missing key must be false")
+	}
+	s.AddFlash("notice", "saved")
+	s.AddFlash("notice", "again")
+
+	if _, ok := decodeRecord(s.encode(), time.Unix(0, 0)); ok {
+		t.Fatal("record without expiry must be expired")
+	}
+	s.rec.ExpiresAt = 100
+	rec, ok := decodeRecord(s.encode(), time.Unix(99, 0))
+	if !ok {
+		t.Fatal("record must be valid")
+	}
+	loaded := &Session{id: s.ID(), rec: rec}
+	if got := loaded.Flashes("notice"); len(got) != 2 || got[0] != "saved" || got[1] != "again" {
+		t.Errorf("Flashes: %v", got)
+	}
+	if !loaded.modified {
+		t.Error("reading flashes must modify the session")
+	}
+	if got := loaded.Flashes("notice"); got != nil {
+		t.Errorf("flashes must be removed after read: %v", got)
+	}
+	if _, ok := decodeRecord(s.encode(), time.Unix(100, 0)); ok {
+		t.Error("record must be expired at ExpiresAt")
+	}
+
+	loaded.Delete("user")
+	if !loaded.empty() {
+		t.Error("session must be empty")
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	s := &Session{id: "old", rec: record{Values: map[string]string{"user": "alice"}}}
+	if err := s.Regenerate(); err != nil {
+		t.Fatal(err)
+	}
+	if s.ID() == "old" || s.oldID != "old" || s.Get("user") != "alice" {
+		t.Fatalf("Regenerate: ID %q, oldID %q, user %q", s.ID(), s.oldID, s.Get("user"))
+	}
+	if err := s.Regenerate(); err != nil {
+		t.Fatal(err)
+	}
+	if s.oldID != "old" {
+		t.Errorf("the stored ID must be kept to be deleted: %q", s.oldID)
+	}
+
+	n, err := newSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Regenerate(); err != nil {
+		t.Fatal(err)
+	}
+	if n.oldID != "" {
+		t.Errorf("new sessions are not stored: %q", n.oldID)
+	}
+}
+
+func TestKVStore(t *testing.T) {
+	ctx := context.Background()
+	kv := bindingtest.NewKV()
+	store := KVStore(kv, "session:")
+	testStore(t, ctx, store)
+	if err := store.Save(ctx, "id", []byte("data"), time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := kv.GetString("session:id", nil); v != "data" {
+		t.Errorf("stored value: %q", v)
+	}
+}
+
+type memoryStorage struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *memoryStorage) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key], nil
+}
+
+func (s *memoryStorage) Put(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	return nil
+}
+
+func (s *memoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+func TestDurableObjectStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	ns := bindingtest.NewDurableObjectNamespace(func(id binding.DurableObjectID) http.Handler {
+		o := NewDurableObject(&memoryStorage{values: map[string]string{}}).(*durableObject)
+		o.now = func() time.Time { return now }
+		return o
+	})
+	store := DurableObjectStore(ns)
+	testStore(t, ctx, store)
+
+	if err := store.Save(ctx, "expiring", []byte("data"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if data, err := store.Load(ctx, "expiring"); err != nil || data != nil {
+		t.Errorf("expired session: %q, %v", data, err)
+	}
+}
+
+func testStore(t *testing.T, ctx context.Context, store Store) {
+	t.Helper()
+	if data, err := store.Load(ctx, "a"); err != nil || data != nil {
+		t.Fatalf("Load of missing session: %q, %v", data, err)
+	}
+	if err := store.Save(ctx, "a", []byte(`{"v":{"k":"1"}}`), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, "b", []byte(`{"v":{"k":"2"}}`), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Load(ctx, "a"); err != nil || string(data) != `{"v":{"k":"1"}}` {
+		t.Fatalf("Load: %q, %v", data, err)
+	}
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Load(ctx, "a"); err != nil || data != nil {
+		t.Fatalf("Load of deleted session: %q, %v", data, err)
+	}
+	if data, err := store.Load(ctx, "b"); err != nil || string(data) != `{"v":{"k":"2"}}` {
+		t.Fatalf("Load of other session: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,181 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/binding"
+)
+
+// Store stores the data of sessions on the server.
+type Store interface {
+	// Load returns the data of the session. if the session is not found, returns nil without error.
+	Load(ctx context.Context, id string) ([]byte, error)
+	// Save stores the data of the session, which expires after ttl.
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// KVStore returns Store which stores sessions in the KV namespace with the prefix of keys (e.g. `session:`).
+//   - KV is eventually consistent, so changes may not be visible in other data centers for up to 60 seconds.
+//     Use DurableObjectStore if sessions must be consistent.
+func KVStore(kv binding.KV, prefix string) Store {
+	return &kvStore{kv: kv, prefix: prefix}
+}
+
+type kvStore struct {
+	kv     binding.KV
+	prefix string
+}
+
+// minKVExpirationTTL is the minimum expirationTtl of KV.
+const minKVExpirationTTL = 60
+
+func (s *kvStore) Load(ctx context.Context, id string) ([]byte, error) {
+	v, err := s.kv.GetString(s.prefix+id, nil)
+	if err != nil || v == "" {
+		return nil, err
+	}
+	return []byte(v), nil
+}
+
+func (s *kvStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	ttlSeconds := int(ttl / time.Second)
+	if ttlSeconds < minKVExpirationTTL {
+		ttlSeconds = minKVExpirationTTL
+	}
+	return s.kv.PutString(s.prefix+id, string(data), &cloudflare.KVNamespacePutOptions{ExpirationTTL: ttlSeconds})
+}
+
+func (s *kvStore) Delete(ctx context.Context, id string) error {
+	return s.kv.Delete(s.prefix + id)
+}
+
+// DurableObjectStore returns Store which stores sessions in Durable Objects of the namespace, one object per session.
+//   - The class of the namespace must be implemented by NewDurableObject.
+func DurableObjectStore(ns binding.DurableObjectNamespace) Store {
+	return &durableObjectStore{ns: ns}
+}
+
+type durableObjectStore struct {
+	ns binding.DurableObjectNamespace
+}
+
+func (s *durableObjectStore) fetch(ctx context.Context, id, method string, body []byte) ([]byte, error) {
+	stub, err := s.ns.Get(s.ns.IdFromName(id))
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "https://session/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	res, err := stub.Fetch(req)
+	if err != nil {
+		return nil, fmt.Errorf("session: error fetching Durable Object: %w", err)
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(res.Body)
+	case http.StatusNotFound:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("session: unexpected status of Durable Object: %s", res.Status)
+}
+
+func (s *durableObjectStore) Load(ctx context.Context, id string) ([]byte, error) {
+	return s.fetch(ctx, id, http.MethodGet, nil)
+}
+
+func (s *durableObjectStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	b, _ := json.Marshal(&storedSession{Data: data, ExpiresAt: time.Now().Add(ttl).UnixMilli()})
+	_, err := s.fetch(ctx, id, http.MethodPut, b)
+	return err
+}
+
+func (s *durableObjectStore) Delete(ctx context.Context, id string) error {
+	_, err := s.fetch(ctx, id, http.MethodDelete, nil)
+	return err
+}
+
+// storedSession is the session stored by the object.
+type storedSession struct {
+	Data []byte `json:"data"`
+	// ExpiresAt is the time in milliseconds since the Unix epoch.
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// Storage persists the session in the object. Use DurableObjectStorage to adapt *durableobject.Storage.
+type Storage interface {
+	// Get returns the value of the key. if the key doesn't exist, returns an empty string.
+	Get(key string) (string, error)
+	Put(key, value string) error
+	Delete(key string) error
+}
+
+// storageKey is the key of the session in the storage.
+const storageKey = "session"
+
+// NewDurableObject returns the handler of the Durable Object class used by DurableObjectStore.
+//
+//   - Expired sessions are deleted when they are loaded.
+//
+//     durableobject.Register("Session", func(ctx context.Context, state *durableobject.State) durableobject.DurableObject {
+//     return session.NewDurableObject(session.DurableObjectStorage(state.Storage()))
+//     })
+func NewDurableObject(storage Storage) http.Handler {
+	return &durableObject{storage: storage, now: time.Now}
+}
+
+type durableObject struct {
+	mu      sync.Mutex
+	storage Storage
+	now     func() time.Time
+}
+
+func (o *durableObject) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	switch req.Method {
+	case http.MethodGet:
+		v, err := o.storage.Get(storageKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var s storedSession
+		if v == "" || json.Unmarshal([]byte(v), &s) != nil || o.now().UnixMilli() >= s.ExpiresAt {
+			if v != "" {
+				_ = o.storage.Delete(storageKey)
+			}
+			http.NotFound(w, req)
+			return
+		}
+		w.Write(s.Data)
+	case http.MethodPut:
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := o.storage.Put(storageKey, string(b)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodDelete:
+		if err := o.storage.Delete(storageKey); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
@@ -1,3 +1,5 @@
+//go:build js && wasm
+
 package cloudflare
 
 import (
@@ -16,6 +18,8 @@ type Dialer struct {
 	connect js.Value
 	opts    *SocketOptions
 	ctx     context.Context
+	// addr overrides the address to dial if it's not empty, e.g. the endpoint of Hyperdrive.
+	addr string
 }
 
 type SocketOptions struct {
@@ -47,6 +51,9 @@ func (d *Dialer) Dial(ctx context.Context, network, addr string) (net.Conn, erro
 			optionsObj.Set("secureTransport", d.opts.SecureTransport)
 		}
 	}
+	if d.addr != "" {
+		addr = d.addr
+	}
 	sock := &TCPSocket{}
 	sock.socket = d.connect.Invoke(addr, optionsObj)
 	sock.options = d.opts
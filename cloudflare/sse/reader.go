@@ -0,0 +1,151 @@
+// Package sse provides the client of Server-Sent Events (`text/event-stream`) for consuming streaming APIs
+// (e.g. LLMs and firehoses) from workers.
+//   - Reader parses events from a body, e.g. of the response of fetch.
+//   - Stream connects to the server, and reconnects with `Last-Event-ID` when the connection is lost.
+//   - https://html.spec.whatwg.org/multipage/server-sent-events.html
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event represents a dispatched event.
+type Event struct {
+	// ID is the last event ID, which is kept from preceding events if the event doesn't have `id` field.
+	ID string
+	// Type is the type of the event given by `event` field. The default is `message`.
+	Type string
+	// Data is the data of the event. Multiple `data` fields are joined by LF.
+	Data string
+	// Retry is the reconnection time given by `retry` field of the event. Retry is 0 if the event doesn't have it.
+	Retry time.Duration
+}
+
+// Reader parses events from the event stream.
+type Reader struct {
+	r *bufio.Reader
+	// skipLF is set when the last line ended with CR, so LF of CRLF is skipped without waiting for the next byte.
+	skipLF  bool
+	started bool
+	line    []byte
+	lastID  string
+	retry   time.Duration
+}
+
+// NewReader returns Reader which reads the event stream from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// LastEventID returns the last event ID given by the stream.
+func (r *Reader) LastEventID() string {
+	return r.lastID
+}
+
+// Retry returns the last reconnection time given by the stream. Retry is 0 if the stream hasn't given it.
+func (r *Reader) Retry() time.Duration {
+	return r.retry
+}
+
+// ReadEvent reads the next event.
+//   - Comments and events without data are skipped, while their `id` and `retry` fields are applied.
+//   - io.EOF is returned at the end of the stream. The incomplete event at the end is discarded.
+func (r *Reader) ReadEvent() (*Event, error) {
+	var (
+		data    strings.Builder
+		hasData bool
+		typ     string
+		retry   time.Duration
+	)
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			if !hasData {
+				typ, retry = "", 0
+				continue
+			}
+			if typ == "" {
+				typ = "message"
+			}
+			return &Event{ID: r.lastID, Type: typ, Data: data.String(), Retry: retry}, nil
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			value = bytes.TrimPrefix(value, []byte(" "))
+		}
+		switch string(field) {
+		case "event":
+			typ = string(value)
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.Write(value)
+			hasData = true
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				r.lastID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil && ms <= uint64(maxRetryMillis) {
+				retry = time.Duration(ms) * time.Millisecond
+				r.retry = retry
+			}
+		}
+	}
+}
+
+// maxRetryMillis is the maximum `retry` field which can be represented as time.Duration.
+const maxRetryMillis = int64(1<<63-1) / int64(time.Millisecond)
+
+// readLine reads a line terminated by CRLF, LF or CR. The returned line is valid until the next call.
+func (r *Reader) readLine() ([]byte, error) {
+	r.line = r.line[:0]
+	for {
+		c, err := r.r.ReadByte()
+		if err != nil {
+			// the incomplete line at the end is discarded as well as the incomplete event.
+			return nil, err
+		}
+		if r.skipLF {
+			r.skipLF = false
+			if c == '\n' {
+				continue
+			}
+		}
+		if !r.started {
+			r.started = true
+			if c == 0xEF && isBOMRest(r.r) {
+				// skip UTF-8 BOM at the start of the stream.
+				_, _ = r.r.Discard(2)
+				continue
+			}
+		}
+		switch c {
+		case '\r':
+			r.skipLF = true
+			return r.line, nil
+		case '\n':
+			return r.line, nil
+		}
+		r.line = append(r.line, c)
+	}
+}
+
+// isBOMRest reports whether the following bytes are the rest of UTF-8 BOM.
+func isBOMRest(r *bufio.Reader) bool {
+	b, _ := r.Peek(2)
+	return bytes.Equal(b, []byte{0xBB, 0xBF})
+}
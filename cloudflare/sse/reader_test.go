@@ -0,0 +1,45 @@
+package sse
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	input := "\xEF\xBB\xBF: comment\n" +
+		"data: first\n\n" +
+		"event: update\r\nid: 1\r\ndata:  two\r\ndata: lines\r\n\r\n" +
+		"retry: 1500\rdata\r\r" +
+		"id: 2\n\n" +
+		"data: {\"a\":1}\nunknown: field\n\n" +
+		"id\ndata: no id\n\n" +
+		"data: incomplete"
+	r := NewReader(strings.NewReader(input))
+	var got []Event
+	for {
+		event, err := r.ReadEvent()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, *event)
+	}
+	want := []Event{
+		{Type: "message", Data: "first"},
+		{ID: "1", Type: "update", Data: " two\nlines"},
+		{ID: "1", Type: "message", Data: "", Retry: 1500 * time.Millisecond},
+		{ID: "2", Type: "message", Data: `{"a":1}`},
+		{Type: "message", Data: "no id"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+	if r.Retry() != 1500*time.Millisecond {
+		t.Errorf("want retry 1.5s, got %v", r.Retry())
+	}
+}
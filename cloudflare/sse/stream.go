@@ -0,0 +1,200 @@
+package sse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+)
+
+// DefaultRetry is the reconnection time used until the server gives `retry` field.
+const DefaultRetry = 3 * time.Second
+
+// DefaultMaxRetries is the number of consecutive reconnections used when Options.MaxRetries is 0.
+const DefaultMaxRetries = 3
+
+// Options represents the options of Connect.
+type Options struct {
+	// Client sends requests, e.g. `fetch.NewClient().HTTPClient(fetch.RedirectModeFollow)`. The default is http.DefaultClient.
+	Client *http.Client
+	// Retry is the reconnection time used until the server gives `retry` field. The default is DefaultRetry.
+	Retry time.Duration
+	// MaxRetries is the number of consecutive reconnections. The count is reset when an event is received.
+	// The default is DefaultMaxRetries. if MaxRetries is negative, the stream is not reconnected.
+	MaxRetries int
+}
+
+// Stream represents the event stream which reconnects when the connection is lost.
+type Stream struct {
+	req     *http.Request
+	client  *http.Client
+	retry   time.Duration
+	max     int
+	retries int
+	body    io.ReadCloser
+	reader  *Reader
+	lastID  string
+	event   *Event
+	err     error
+	done    bool
+}
+
+// ErrUnexpectedResponse is returned when the server responds with the status other than 200 or without `text/event-stream`.
+var ErrUnexpectedResponse = errors.New("sse: unexpected response")
+
+// Connect sends req and returns the stream of the response.
+//   - Accept and Cache-Control headers are set to req. Reconnections are sent with `Last-Event-ID` header.
+//   - Requests with bodies must have GetBody to be reconnected (e.g. created by http.NewRequest).
+//   - The stream is closed when the context of req is done.
+func Connect(req *http.Request, opts *Options) (*Stream, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	s := &Stream{
+		req:    req,
+		client: opts.Client,
+		retry:  opts.Retry,
+		max:    opts.MaxRetries,
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	if s.retry <= 0 {
+		s.retry = DefaultRetry
+	}
+	if s.max == 0 {
+		s.max = DefaultMaxRetries
+	}
+	stop, err := s.connect(req)
+	if err != nil {
+		return nil, err
+	}
+	if stop {
+		s.body, s.done = http.NoBody, true
+	}
+	return s, nil
+}
+
+// connect sends the request, and reports whether the server tells to stop reconnections by `204 No Content` response.
+func (s *Stream) connect(req *http.Request) (stop bool, err error) {
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	if res.StatusCode == http.StatusNoContent {
+		res.Body.Close()
+		return true, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if res.StatusCode != http.StatusOK || mediaType != "text/event-stream" {
+		res.Body.Close()
+		return false, fmt.Errorf("%w: %s %q", ErrUnexpectedResponse, res.Status, res.Header.Get("Content-Type"))
+	}
+	s.body = res.Body
+	reader := NewReader(res.Body)
+	reader.lastID = s.lastID
+	if s.reader != nil {
+		reader.retry = s.reader.retry
+	}
+	s.reader = reader
+	return false, nil
+}
+
+// reconnect waits for the reconnection time, and sends the request again.
+func (s *Stream) reconnect() (stop bool, err error) {
+	ctx := s.req.Context()
+	retry := s.retry
+	if r := s.reader.Retry(); r > 0 {
+		retry = r
+	}
+	t := time.NewTimer(retry)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return true, ctx.Err()
+	case <-t.C:
+	}
+	req := s.req.Clone(ctx)
+	if s.req.GetBody != nil {
+		body, err := s.req.GetBody()
+		if err != nil {
+			return true, err
+		}
+		req.Body = body
+	}
+	if s.lastID != "" {
+		req.Header.Set("Last-Event-ID", s.lastID)
+	}
+	return s.connect(req)
+}
+
+// canReconnect reports whether the request can be sent again.
+func (s *Stream) canReconnect() bool {
+	return s.max > 0 && s.retries < s.max && (s.req.Body == nil || s.req.Body == http.NoBody || s.req.GetBody != nil)
+}
+
+// Next advances the stream to the next event, and reports whether the event exists.
+//   - Next returns false when the stream is closed or on error. Check Err after the iteration.
+func (s *Stream) Next() bool {
+	for !s.done && s.err == nil {
+		event, err := s.reader.ReadEvent()
+		s.lastID = s.reader.LastEventID()
+		if err == nil {
+			s.event = event
+			s.retries = 0
+			return true
+		}
+		s.body.Close()
+		if ctxErr := s.req.Context().Err(); ctxErr != nil {
+			s.err = ctxErr
+			return false
+		}
+		for {
+			if !s.canReconnect() {
+				if !errors.Is(err, io.EOF) {
+					s.err = err
+				}
+				s.done = true
+				return false
+			}
+			s.retries++
+			var stop bool
+			stop, err = s.reconnect()
+			if err == nil && !stop {
+				break
+			}
+			if stop || errors.Is(err, ErrUnexpectedResponse) {
+				// the server tells to stop, or fails the connection.
+				s.err = err
+				s.done = true
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// Event returns the current event of the stream.
+func (s *Stream) Event() *Event {
+	return s.event
+}
+
+// Err returns the error occurred in Next. Err is nil if the server closed the stream.
+func (s *Stream) Err() error {
+	return s.err
+}
+
+// LastEventID returns the last event ID given by the server.
+func (s *Stream) LastEventID() string {
+	return s.lastID
+}
+
+// Close closes the current connection, and stops reconnections.
+func (s *Stream) Close() error {
+	s.done = true
+	return s.body.Close()
+}
@@ -0,0 +1,127 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func eventStream(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": {"text/event-stream; charset=utf-8"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestStreamReconnect(t *testing.T) {
+	var lastIDs []string
+	responses := []*http.Response{
+		eventStream(http.StatusOK, "retry: 1\nid: 1\ndata: a\n\ndata: lost"),
+		nil,
+		eventStream(http.StatusOK, "id: 2\ndata: b\n\n"),
+		eventStream(http.StatusNoContent, ""),
+	}
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("unexpected Accept: %q", req.Header.Get("Accept"))
+		}
+		lastIDs = append(lastIDs, req.Header.Get("Last-Event-ID"))
+		res := responses[0]
+		responses = responses[1:]
+		if res == nil {
+			return nil, errors.New("connection refused")
+		}
+		return res, nil
+	})}
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/events", nil)
+	s, err := Connect(req, &Options{Client: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var data []string
+	for s.Next() {
+		data = append(data, s.Event().Data)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(data, ",") != "a,b" {
+		t.Errorf("want a,b, got %q", data)
+	}
+	if strings.Join(lastIDs, ",") != ",1,1,2" {
+		t.Errorf("want Last-Event-IDs ,1,1,2, got %q", lastIDs)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	tests := map[string]struct {
+		responses []*http.Response
+		opts      *Options
+		wantErr   bool
+		connects  int
+	}{
+		"unexpected status": {
+			responses: []*http.Response{eventStream(http.StatusOK, "data: a"), eventStream(http.StatusInternalServerError, "")},
+			opts:      &Options{Retry: 1},
+			wantErr:   true,
+			connects:  2,
+		},
+		"no reconnection": {
+			responses: []*http.Response{eventStream(http.StatusOK, "data: a")},
+			opts:      &Options{MaxRetries: -1},
+			connects:  1,
+		},
+		"max retries": {
+			responses: []*http.Response{eventStream(http.StatusOK, "data: a"), nil, nil},
+			opts:      &Options{Retry: 1, MaxRetries: 2},
+			wantErr:   true,
+			connects:  3,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var connects int
+			tt.opts.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				res := tt.responses[connects]
+				connects++
+				if res == nil {
+					return nil, errors.New("connection refused")
+				}
+				return res, nil
+			})}
+			req, _ := http.NewRequest(http.MethodGet, "https://example.com/events", nil)
+			s, err := Connect(req, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for s.Next() {
+			}
+			if (s.Err() != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", s.Err())
+			}
+			if connects != tt.connects {
+				t.Errorf("want %d connections, got %d", tt.connects, connects)
+			}
+		})
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "https://example.com/events", nil)
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: http.NoBody}, nil
+	})}
+	if _, err := Connect(req, &Options{Client: client}); !errors.Is(err, ErrUnexpectedResponse) {
+		t.Errorf("want ErrUnexpectedResponse, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Package timing provides the monotonic clock based on performance.now of the Workers runtime.
+//   - To mitigate Spectre, the clock of Cloudflare Workers doesn't advance while the worker is executing code.
+//     It only advances when the worker performs I/O (e.g. fetch or awaiting bindings).
+//     So measured durations reflect time spent on I/O, and CPU-bound work is measured as 0.
+//   - https://developers.cloudflare.com/workers/runtime-apis/performance/
+package timing
+
+import (
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	performance = jsutil.LazyGlobal("performance")
+	// start is used as the origin of Now when performance.now is not available.
+	start = time.Now()
+)
+
+// Now returns the current time of the monotonic clock, relative to the start of the worker.
+//   - if performance.now is not available, the monotonic clock of Go is used.
+func Now() time.Duration {
+	perf := performance()
+	if perf.Type() != js.TypeObject || perf.Get("now").Type() != js.TypeFunction {
+		return time.Since(start)
+	}
+	return time.Duration(perf.Call("now").Float() * float64(time.Millisecond))
+}
+
+// Stopwatch measures the elapsed time from its start.
+type Stopwatch struct {
+	start time.Duration
+}
+
+// Start returns Stopwatch started at the current time.
+func Start() Stopwatch {
+	return Stopwatch{start: Now()}
+}
+
+// Elapsed returns the elapsed time from the start of the Stopwatch.
+//   - The result is never negative.
+func (s Stopwatch) Elapsed() time.Duration {
+	d := Now() - s.start
+	if d < 0 {
+		return 0
+	}
+	return d
+}
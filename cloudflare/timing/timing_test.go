@@ -0,0 +1,18 @@
+package timing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopwatch(t *testing.T) {
+	s := Start()
+	time.Sleep(20 * time.Millisecond)
+	d := s.Elapsed()
+	if d < 10*time.Millisecond {
+		t.Errorf("want elapsed time >= 10ms, got %v", d)
+	}
+	if d2 := s.Elapsed(); d2 < d {
+		t.Errorf("clock went backwards: %v < %v", d2, d)
+	}
+}
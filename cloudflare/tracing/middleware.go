@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Middleware returns the middleware which starts server spans of requests served by the handler.
+//   - The trace of the traceparent header of the request is continued.
+//   - The span is named `METHOD /path`. It can be renamed by `SpanFromContext(req.Context()).SetName`.
+//   - Responses with status 5xx and panics of the handler set the status of the span to StatusError.
+//   - Spans are exported by Flush after the response has been sent, by `cloudflare.WaitUntil`.
+func Middleware(t *Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			ctx, span := t.Start(Extract(req.Context(), req.Header), req.Method+" "+req.URL.Path, SpanKindServer)
+			span.SetAttribute("http.request.method", req.Method)
+			span.SetAttribute("url.full", req.URL.String())
+			span.SetAttribute("url.path", req.URL.Path)
+			if ua := req.UserAgent(); ua != "" {
+				span.SetAttribute("user_agent.original", ua)
+			}
+			if ray := req.Header.Get("Cf-Ray"); ray != "" {
+				span.SetAttribute("cf.ray", ray)
+			}
+			rec := &recorder{ResponseWriter: w}
+			defer func() {
+				if r := recover(); r != nil {
+					span.RecordError(fmt.Errorf("panic: %v", r))
+					span.End()
+					t.flushAfterResponse(ctx)
+					panic(r)
+				}
+				status := rec.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				span.SetAttribute("http.response.status_code", status)
+				if status >= 500 {
+					span.SetStatus(StatusError, http.StatusText(status))
+				}
+				span.End()
+				t.flushAfterResponse(ctx)
+			}()
+			next.ServeHTTP(rec, req.WithContext(ctx))
+		})
+	}
+}
+
+// recorder is http.ResponseWriter which records the status code of the response.
+type recorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,212 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const scopeName = "github.com/syumai/workers/cloudflare/tracing"
+
+// OTLPExporter is the Exporter which sends spans by OTLP/HTTP with JSON encoding.
+//   - https://opentelemetry.io/docs/specs/otlp/#otlphttp
+type OTLPExporter struct {
+	endpoint   string
+	header     http.Header
+	httpClient *http.Client
+}
+
+// OTLPOption is a type that represents an optional function of NewOTLPExporter.
+type OTLPOption func(*OTLPExporter)
+
+// WithHeader adds the header to requests sent to the endpoint, e.g. API keys of tracing backends.
+func WithHeader(key, value string) OTLPOption {
+	return func(e *OTLPExporter) {
+		e.header.Add(key, value)
+	}
+}
+
+// WithHTTPClient changes the HTTP client used to send requests.
+//   - The default client sends requests by the Fetch API of Workers.
+func WithHTTPClient(c *http.Client) OTLPOption {
+	return func(e *OTLPExporter) {
+		e.httpClient = c
+	}
+}
+
+// NewOTLPExporter returns OTLPExporter which sends spans to the endpoint.
+//   - endpoint is the full URL of the traces endpoint, e.g. `https://otel.example.com/v1/traces`.
+func NewOTLPExporter(endpoint string, opts ...OTLPOption) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint:   endpoint,
+		header:     http.Header{},
+		httpClient: &http.Client{Transport: defaultTransport()},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Export sends the spans to the endpoint.
+func (e *OTLPExporter) Export(ctx context.Context, resource []Attribute, spans []*SpanData) error {
+	body, err := json.Marshal(encodeTraces(resource, spans))
+	if err != nil {
+		return fmt.Errorf("tracing: error encoding spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range e.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("tracing: unexpected status %d from the OTLP endpoint: %s", res.StatusCode, b)
+	}
+	return nil
+}
+
+// The types below are the JSON encoding of ExportTraceServiceRequest of OTLP.
+//   - https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/trace/v1/trace.proto
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Flags             uint32         `json:"flags,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code,omitempty"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func encodeTraces(resource []Attribute, spans []*SpanData) *otlpTraces {
+	encoded := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Flags:             uint32(s.TraceFlags),
+			Name:              s.Name,
+			Kind:              s.Kind,
+			StartTimeUnixNano: unixNano(s.Start),
+			EndTimeUnixNano:   unixNano(s.End),
+			Attributes:        encodeAttributes(s.Attributes),
+			Status:            otlpStatus{Code: s.Status, Message: s.StatusMessage},
+		}
+		if s.ParentSpanID.IsValid() {
+			encoded[i].ParentSpanID = s.ParentSpanID.String()
+		}
+		for _, ev := range s.Events {
+			encoded[i].Events = append(encoded[i].Events, otlpEvent{
+				TimeUnixNano: unixNano(ev.Time),
+				Name:         ev.Name,
+				Attributes:   encodeAttributes(ev.Attributes),
+			})
+		}
+	}
+	return &otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: encodeAttributes(resource)},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: scopeName},
+				Spans: encoded,
+			}},
+		}},
+	}
+}
+
+func encodeAttributes(attrs []Attribute) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, len(attrs))
+	for i, attr := range attrs {
+		kvs[i] = otlpKeyValue{Key: attr.Key, Value: encodeValue(attr.Value)}
+	}
+	return kvs
+}
+
+func encodeValue(v any) otlpAnyValue {
+	switch v := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &v}
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return otlpAnyValue{IntValue: &s}
+	case int64:
+		s := strconv.FormatInt(v, 10)
+		return otlpAnyValue{IntValue: &s}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	}
+	s := fmt.Sprint(v)
+	return otlpAnyValue{StringValue: &s}
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
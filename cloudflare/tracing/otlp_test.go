@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOTLPExporter_Export(t *testing.T) {
+	var (
+		gotHeader http.Header
+		gotBody   map[string]any
+	)
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		gotHeader = req.Header
+		if err := json.NewDecoder(req.Body).Decode(&gotBody); err != nil {
+			t.Errorf("error decoding body: %v", err)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}")), Header: http.Header{}}
+	})}
+	e := NewOTLPExporter("https://otel.example.com/v1/traces", WithHTTPClient(client), WithHeader("X-Api-Key", "secret"))
+	span := &SpanData{
+		Name:       "GET /",
+		Kind:       SpanKindServer,
+		TraceID:    TraceID{1},
+		SpanID:     SpanID{2},
+		TraceFlags: 1,
+		Start:      time.Unix(1, 0),
+		End:        time.Unix(2, 0),
+		Attributes: []Attribute{{Key: "http.response.status_code", Value: 200}, {Key: "cached", Value: true}},
+		Status:     StatusError,
+	}
+	if err := e.Export(context.Background(), []Attribute{{Key: "service.name", Value: "api"}}, []*SpanData{span}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if gotHeader.Get("Content-Type") != "application/json" || gotHeader.Get("X-Api-Key") != "secret" {
+		t.Errorf("unexpected header: %v", gotHeader)
+	}
+	b, _ := json.Marshal(gotBody)
+	want := `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},` +
+		`"scopeSpans":[{"scope":{"name":"github.com/syumai/workers/cloudflare/tracing"},"spans":[{` +
+		`"attributes":[{"key":"http.response.status_code","value":{"intValue":"200"}},{"key":"cached","value":{"boolValue":true}}],` +
+		`"endTimeUnixNano":"2000000000","flags":1,"kind":2,"name":"GET /","spanId":"0200000000000000",` +
+		`"startTimeUnixNano":"1000000000","status":{"code":2},"traceId":"01000000000000000000000000000000"}]}]}]}`
+	if string(b) != want {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", b, want)
+	}
+}
+
+func TestOTLPExporter_ExportError(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader("invalid key")), Header: http.Header{}}
+	})}
+	e := NewOTLPExporter("https://otel.example.com/v1/traces", WithHTTPClient(client))
+	err := e.Export(context.Background(), nil, []*SpanData{{Name: "GET /"}})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("want error with status 401, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const flagSampled = 0x01
+
+// ErrInvalidTraceparent is returned by ParseTraceparent when the value is not a valid traceparent header.
+var ErrInvalidTraceparent = errors.New("tracing: invalid traceparent")
+
+// SpanContext represents the IDs of a span propagated between services.
+//   - https://www.w3.org/TR/trace-context/
+type SpanContext struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	TraceFlags byte
+}
+
+// IsValid reports whether both of the IDs are valid.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent returns the value of the traceparent header of the SpanContext.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.TraceFlags})
+}
+
+// ParseTraceparent parses the value of the traceparent header.
+//   - Versions other than `00` are accepted as long as the first four fields are valid.
+func ParseTraceparent(v string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	var (
+		sc    SpanContext
+		flags [1]byte
+	)
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	sc.TraceFlags = flags[0]
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex s into dst, and reports whether s has exactly the length of dst.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+type remoteKey struct{}
+
+// ContextWithRemoteSpanContext returns the context which holds the SpanContext received from another service.
+//   - Spans started with the context become children of the remote span.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// SpanContextFromContext returns the SpanContext of the span held by ctx, or the remote SpanContext held by ctx.
+//   - if ctx holds neither of them, returns the invalid SpanContext.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// Extract returns the context which holds the SpanContext of the traceparent header.
+//   - if the header is missing or invalid, ctx is returned as is.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	sc, err := ParseTraceparent(header.Get("Traceparent"))
+	if err != nil {
+		return ctx
+	}
+	return ContextWithRemoteSpanContext(ctx, sc)
+}
+
+// Inject sets the traceparent header of the SpanContext held by ctx.
+//   - if ctx doesn't hold a valid SpanContext, the header is not changed.
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	header.Set("Traceparent", sc.Traceparent())
+}
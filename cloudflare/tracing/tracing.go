@@ -0,0 +1,342 @@
+// Package tracing provides OpenTelemetry compatible tracing of workers, and the exporter of spans by OTLP/HTTP.
+//   - Middleware starts server spans of incoming requests, and exports spans after the response has been sent
+//     by `cloudflare.WaitUntil`.
+//   - Transport starts client spans of subrequests, and propagates the trace by the traceparent header.
+//   - Calls of bindings can be traced by Trace.
+//   - Since the clock of Workers advances only on I/O, time spent on computation is not included in durations of spans.
+//   - https://opentelemetry.io/docs/specs/otlp/
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// TraceID is the ID of a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the ID in lowercase hex.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID is the ID of a span.
+type SpanID [8]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the ID in lowercase hex.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanKind represents the kind of a span. The values are the same as OTLP.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = iota + 1
+	SpanKindServer
+	SpanKindClient
+	SpanKindProducer
+	SpanKindConsumer
+)
+
+// StatusCode represents the status of a span. The values are the same as OTLP.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = iota
+	StatusOK
+	StatusError
+)
+
+// Attribute represents an attribute of spans and resources.
+//   - Value must be string, bool, int, int64 or float64. Values of other types are exported as strings by fmt.Sprint.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Event represents an event which happened during a span.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// SpanData represents the recorded data of an ended span.
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	TraceID      TraceID
+	SpanID       SpanID
+	ParentSpanID SpanID
+	// TraceFlags are the flags of the trace propagated by the traceparent header.
+	TraceFlags    byte
+	Start         time.Time
+	End           time.Time
+	Attributes    []Attribute
+	Events        []Event
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Exporter exports ended spans.
+type Exporter interface {
+	// Export exports spans with the attributes of the resource which produced them.
+	Export(ctx context.Context, resource []Attribute, spans []*SpanData) error
+}
+
+// Options represents the options of NewTracer.
+type Options struct {
+	// ServiceName is the `service.name` attribute of the resource. The default value is `worker`.
+	ServiceName string
+	// ResourceAttributes are added to the resource, e.g. `service.version` or `deployment.environment`.
+	ResourceAttributes []Attribute
+	// MaxQueueSize is the maximum number of ended spans kept until Flush. The default value is 2048.
+	// Spans ended after the queue is full are dropped.
+	MaxQueueSize int
+	// ErrorHandler is called with errors of exports in Middleware.
+	// if ErrorHandler is nil, errors are written by the standard logger.
+	ErrorHandler func(err error)
+}
+
+const defaultMaxQueueSize = 2048
+
+// Tracer records spans, and exports them by the Exporter on Flush.
+//   - Ended spans are kept in memory of the isolate until Flush is called.
+type Tracer struct {
+	exporter     Exporter
+	resource     []Attribute
+	maxQueueSize int
+	errorHandler func(err error)
+
+	mu      sync.Mutex
+	pending []*SpanData
+}
+
+// NewTracer returns Tracer which exports spans by the exporter.
+func NewTracer(exporter Exporter, opts *Options) *Tracer {
+	if opts == nil {
+		opts = &Options{}
+	}
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = "worker"
+	}
+	maxQueueSize := opts.MaxQueueSize
+	if maxQueueSize == 0 {
+		maxQueueSize = defaultMaxQueueSize
+	}
+	errorHandler := opts.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = func(err error) {
+			log.Printf("tracing: %v", err)
+		}
+	}
+	resource := append([]Attribute{{Key: "service.name", Value: serviceName}}, opts.ResourceAttributes...)
+	return &Tracer{
+		exporter:     exporter,
+		resource:     resource,
+		maxQueueSize: maxQueueSize,
+		errorHandler: errorHandler,
+	}
+}
+
+// Start starts the span which is a child of the span or the remote parent held by ctx.
+//   - The returned context holds the span, so spans started with it become its children.
+//   - The span must be ended by End.
+func (t *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	data := &SpanData{
+		Name:   name,
+		Kind:   kind,
+		SpanID: newSpanID(),
+		Start:  time.Now(),
+	}
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		data.TraceID = parent.TraceID
+		data.ParentSpanID = parent.SpanID
+		data.TraceFlags = parent.TraceFlags
+	} else {
+		data.TraceID = newTraceID()
+		data.TraceFlags = flagSampled
+	}
+	span := &Span{tracer: t, data: data}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// Flush exports the ended spans which have not been exported yet.
+//   - Spans are removed from the queue even if the export fails.
+func (t *Tracer) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	if err := t.exporter.Export(ctx, t.resource, spans); err != nil {
+		return fmt.Errorf("error exporting %d spans: %w", len(spans), err)
+	}
+	return nil
+}
+
+func (t *Tracer) enqueue(data *SpanData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) >= t.maxQueueSize {
+		return
+	}
+	t.pending = append(t.pending, data)
+}
+
+// Span represents an operation in a trace.
+//   - Methods of nil *Span do nothing, so spans returned by Trace without a tracer can be used safely.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  *SpanData
+	ended bool
+}
+
+// SpanContext returns the IDs of the span propagated to other services.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID, TraceFlags: s.data.TraceFlags}
+}
+
+// SetName changes the name of the span.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute sets the attribute of the span. The attribute of the same key is overwritten.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, attr := range s.data.Attributes {
+		if attr.Key == key {
+			s.data.Attributes[i].Value = value
+			return
+		}
+	}
+	s.data.Attributes = append(s.data.Attributes, Attribute{Key: key, Value: value})
+}
+
+// AddEvent adds the event to the span.
+func (s *Span) AddEvent(name string, attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Events = append(s.data.Events, Event{Name: name, Time: time.Now(), Attributes: attrs})
+}
+
+// RecordError adds the `exception` event of the error, and sets the status of the span to StatusError.
+//   - if err is nil, RecordError does nothing.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.AddEvent("exception",
+		Attribute{Key: "exception.type", Value: fmt.Sprintf("%T", err)},
+		Attribute{Key: "exception.message", Value: err.Error()},
+	)
+	s.SetStatus(StatusError, err.Error())
+}
+
+// SetStatus sets the status of the span. The message is used only for StatusError.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Status = code
+	s.data.StatusMessage = ""
+	if code == StatusError {
+		s.data.StatusMessage = message
+	}
+}
+
+// End ends the span and queues it to be exported by Flush.
+//   - Calls of End after the first call are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	s.mu.Unlock()
+	s.tracer.enqueue(s.data)
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span held by ctx. if ctx doesn't hold a span, returns nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Trace starts the span of the name with the tracer of the span held by ctx, and ends it after fn returns.
+//   - This is useful to trace calls of bindings, e.g. `tracing.Trace(ctx, "kv.get", tracing.SpanKindClient, ...)`.
+//   - The error returned by fn is recorded to the span, and returned as is.
+//   - if ctx doesn't hold a span (e.g. the request is not served by Middleware), fn is called with a nil span.
+func Trace(ctx context.Context, name string, kind SpanKind, fn func(ctx context.Context, span *Span) error) error {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return fn(ctx, nil)
+	}
+	ctx, span := parent.tracer.Start(ctx, name, kind)
+	defer span.End()
+	err := fn(ctx, span)
+	span.RecordError(err)
+	return err
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
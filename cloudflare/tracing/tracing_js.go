@@ -0,0 +1,31 @@
+//go:build js && wasm
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare"
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+func defaultTransport() http.RoundTripper {
+	return fetch.NewClient().HTTPClient(fetch.RedirectModeFollow).Transport
+}
+
+// flushAfterResponse exports spans by Flush under `cloudflare.WaitUntil`.
+//   - if ctx doesn't hold the runtime context (e.g. in tests), spans are exported before returning.
+func (t *Tracer) flushAfterResponse(ctx context.Context) {
+	flush := func() {
+		if err := t.Flush(ctx); err != nil {
+			t.errorHandler(err)
+		}
+	}
+	if _, ok := runtimecontext.Extract(ctx); !ok {
+		flush()
+		return
+	}
+	cloudflare.WaitUntil(ctx, flush)
+}
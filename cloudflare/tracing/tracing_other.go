@@ -0,0 +1,19 @@
+//go:build !(js && wasm)
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+)
+
+func defaultTransport() http.RoundTripper {
+	return http.DefaultTransport
+}
+
+// flushAfterResponse exports spans by Flush before returning, since there is no `waitUntil` outside of Workers.
+func (t *Tracer) flushAfterResponse(ctx context.Context) {
+	if err := t.Flush(ctx); err != nil {
+		t.errorHandler(err)
+	}
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeExporter struct {
+	resource []Attribute
+	spans    []*SpanData
+}
+
+func (e *fakeExporter) Export(ctx context.Context, resource []Attribute, spans []*SpanData) error {
+	e.resource = resource
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+type roundTripFunc func(req *http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req), nil
+}
+
+func attribute(s *SpanData, key string) any {
+	for _, attr := range s.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return nil
+}
+
+func TestParseTraceparent(t *testing.T) {
+	const v = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	sc, err := ParseTraceparent(v)
+	if err != nil {
+		t.Fatalf("ParseTraceparent() error = %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || sc.TraceFlags != 1 {
+		t.Errorf("unexpected SpanContext: %+v", sc)
+	}
+	if got := sc.Traceparent(); got != v {
+		t.Errorf("Traceparent() = %s, want %s", got, v)
+	}
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceparent(invalid); !errors.Is(err, ErrInvalidTraceparent) {
+			t.Errorf("ParseTraceparent(%q) error = %v, want ErrInvalidTraceparent", invalid, err)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	exp := &fakeExporter{}
+	tracer := NewTracer(exp, &Options{ServiceName: "api"})
+	var downstream string
+	client := &http.Client{Transport: &Transport{Base: roundTripFunc(func(req *http.Request) *http.Response {
+		downstream = req.Header.Get("Traceparent")
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("")), Header: http.Header{}}
+	})}}
+	h := Middleware(tracer)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		req, _ = http.NewRequestWithContext(req.Context(), http.MethodGet, "https://backend.example.com/items", nil)
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		Trace(req.Context(), "kv.get", SpanKindClient, func(ctx context.Context, span *Span) error {
+			return errors.New("not found")
+		})
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "https://example.com/items", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(exp.spans) != 3 {
+		t.Fatalf("want 3 exported spans, got %d", len(exp.spans))
+	}
+	fetchSpan, kvSpan, serverSpan := exp.spans[0], exp.spans[1], exp.spans[2]
+	if serverSpan.Kind != SpanKindServer || serverSpan.Name != "GET /items" {
+		t.Errorf("unexpected server span: %+v", serverSpan)
+	}
+	if serverSpan.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || serverSpan.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("server span doesn't continue the trace: %+v", serverSpan)
+	}
+	if serverSpan.Status != StatusError || attribute(serverSpan, "http.response.status_code") != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status of server span: %+v", serverSpan)
+	}
+	for _, child := range []*SpanData{fetchSpan, kvSpan} {
+		if child.Kind != SpanKindClient || child.TraceID != serverSpan.TraceID || child.ParentSpanID != serverSpan.SpanID {
+			t.Errorf("%s is not a client span of the server span: %+v", child.Name, child)
+		}
+	}
+	if want := (SpanContext{TraceID: fetchSpan.TraceID, SpanID: fetchSpan.SpanID, TraceFlags: 1}).Traceparent(); downstream != want {
+		t.Errorf("want traceparent %s, got %s", want, downstream)
+	}
+	if fetchSpan.Status != StatusError || attribute(fetchSpan, "http.response.status_code") != http.StatusNotFound {
+		t.Errorf("unexpected status of fetch span: %+v", fetchSpan)
+	}
+	if kvSpan.Status != StatusError || len(kvSpan.Events) != 1 || kvSpan.Events[0].Name != "exception" {
+		t.Errorf("error is not recorded to kv span: %+v", kvSpan)
+	}
+	if exp.resource[0] != (Attribute{Key: "service.name", Value: "api"}) {
+		t.Errorf("unexpected resource: %+v", exp.resource)
+	}
+}
+
+func TestTraceWithoutSpan(t *testing.T) {
+	called := false
+	err := Trace(context.Background(), "kv.get", SpanKindClient, func(ctx context.Context, span *Span) error {
+		called = true
+		span.SetAttribute("key", "value")
+		span.End()
+		return nil
+	})
+	if err != nil || !called {
+		t.Errorf("want fn to be called without error, got called=%v, err=%v", called, err)
+	}
+}
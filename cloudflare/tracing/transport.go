@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"net/http"
+)
+
+// Transport is http.RoundTripper which starts client spans of subrequests.
+//   - Spans are children of the span held by the context of the request. if the context doesn't hold a span,
+//     the request is sent without a span.
+//   - The traceparent header is added to the request, so the trace is continued by the server.
+type Transport struct {
+	// Base is the RoundTripper which sends requests. The default value sends requests by the Fetch API of Workers.
+	Base http.RoundTripper
+}
+
+// RoundTrip sends the request by Base with the client span.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = defaultTransport()
+	}
+	parent := SpanFromContext(req.Context())
+	if parent == nil {
+		return base.RoundTrip(req)
+	}
+	ctx, span := parent.tracer.Start(req.Context(), req.Method+" "+req.URL.Host, SpanKindClient)
+	defer span.End()
+	span.SetAttribute("http.request.method", req.Method)
+	span.SetAttribute("url.full", req.URL.String())
+	span.SetAttribute("server.address", req.URL.Hostname())
+
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+	res, err := base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttribute("http.response.status_code", res.StatusCode)
+	if res.StatusCode >= 400 {
+		span.SetStatus(StatusError, http.StatusText(res.StatusCode))
+	}
+	return res, nil
+}
@@ -0,0 +1,128 @@
+package turnstile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ResponseField is the name of the form field given by the widget.
+const ResponseField = "cf-turnstile-response"
+
+// defaultMaxBodySize is the maximum size of JSON bodies read to find the token.
+const defaultMaxBodySize = 1 << 20
+
+// MiddlewareOptions represents the options of Middleware.
+type MiddlewareOptions struct {
+	// Field is the name of the form field or the JSON property of the token. The default is ResponseField.
+	Field string
+	// Header is the name of the header of the token, which is used when the body doesn't have the token (e.g. for fetch of SPAs).
+	// The default is `CF-Turnstile-Response`.
+	Header string
+	// MaxBodySize is the maximum size of JSON bodies in bytes. The default is 1 MiB.
+	MaxBodySize int64
+	// OnError writes the response when the token is not valid. The default responds 403 Forbidden,
+	// or 502 Bad Gateway if the siteverify endpoint can't be reached.
+	OnError func(w http.ResponseWriter, req *http.Request, err error)
+}
+
+type responseKey struct{}
+
+// FromContext returns the response of the siteverify endpoint validated by Middleware.
+func FromContext(ctx context.Context) (*Response, bool) {
+	res, ok := ctx.Value(responseKey{}).(*Response)
+	return res, ok
+}
+
+// Middleware returns the middleware which validates the Turnstile token of requests by v.
+//   - The token is read from the form field of form requests (urlencoded or multipart), the property of JSON requests, or the header.
+//   - Forms are parsed by the middleware, so the handler can read them by FormValue. JSON bodies are given to the handler as they are.
+//   - The response of the siteverify endpoint can be obtained by FromContext in the handler.
+func Middleware(v *Verifier, opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	if opts == nil {
+		opts = &MiddlewareOptions{}
+	}
+	field := opts.Field
+	if field == "" {
+		field = ResponseField
+	}
+	header := opts.Header
+	if header == "" {
+		header = "CF-Turnstile-Response"
+	}
+	maxBodySize := opts.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxBodySize
+	}
+	onError := opts.OnError
+	if onError == nil {
+		onError = defaultOnError
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token, err := tokenFromRequest(req, field, maxBodySize)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if token == "" {
+				token = req.Header.Get(header)
+			}
+			res, err := v.Verify(req.Context(), token, &VerifyOptions{RemoteIP: req.Header.Get("CF-Connecting-IP")})
+			if err != nil {
+				onError(w, req, err)
+				return
+			}
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), responseKey{}, res)))
+		})
+	}
+}
+
+func defaultOnError(w http.ResponseWriter, req *http.Request, err error) {
+	if IsInvalid(err) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+}
+
+// tokenFromRequest returns the token of the field in the body. The JSON body is restored for the handler.
+func tokenFromRequest(req *http.Request, field string, maxBodySize int64) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if err := req.ParseForm(); err != nil {
+			return "", err
+		}
+		return req.PostForm.Get(field), nil
+	case "multipart/form-data":
+		if err := req.ParseMultipartForm(32 << 20); err != nil {
+			return "", err
+		}
+		return req.PostFormValue(field), nil
+	case "application/json":
+		b, err := io.ReadAll(io.LimitReader(req.Body, maxBodySize+1))
+		if err != nil {
+			return "", err
+		}
+		if int64(len(b)) > maxBodySize {
+			return "", errBodyTooLarge
+		}
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		var body map[string]json.RawMessage
+		if json.Unmarshal(b, &body) != nil {
+			// the handler reports malformed bodies.
+			return "", nil
+		}
+		var token string
+		_ = json.Unmarshal(body[field], &token)
+		return token, nil
+	}
+	return "", nil
+}
+
+var errBodyTooLarge = errors.New("turnstile: request body is too large")
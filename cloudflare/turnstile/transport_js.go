@@ -0,0 +1,13 @@
+//go:build js && wasm
+
+package turnstile
+
+import (
+	"net/http"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+)
+
+func defaultTransport() http.RoundTripper {
+	return fetch.NewClient().HTTPClient(fetch.RedirectModeFollow).Transport
+}
@@ -0,0 +1,9 @@
+//go:build !(js && wasm)
+
+package turnstile
+
+import "net/http"
+
+func defaultTransport() http.RoundTripper {
+	return http.DefaultTransport
+}
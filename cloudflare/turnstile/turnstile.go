@@ -0,0 +1,227 @@
+// Package turnstile validates Turnstile tokens by the siteverify endpoint.
+//   - Verifier validates tokens given by the widget, and Middleware validates tokens of form or JSON requests.
+//   - Requests to the endpoint are sent by fetch of the runtime.
+//   - https://developers.cloudflare.com/turnstile/get-started/server-side-validation/
+package turnstile
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SiteverifyURL is the URL of the siteverify endpoint.
+const SiteverifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+// maxTokenLength is the maximum length of tokens accepted by the siteverify endpoint.
+const maxTokenLength = 2048
+
+// ErrorCode represents the error code returned by the siteverify endpoint.
+//   - https://developers.cloudflare.com/turnstile/get-started/server-side-validation/#error-codes
+type ErrorCode string
+
+const (
+	ErrorMissingInputSecret   ErrorCode = "missing-input-secret"
+	ErrorInvalidInputSecret   ErrorCode = "invalid-input-secret"
+	ErrorMissingInputResponse ErrorCode = "missing-input-response"
+	ErrorInvalidInputResponse ErrorCode = "invalid-input-response"
+	ErrorBadRequest           ErrorCode = "bad-request"
+	// ErrorTimeoutOrDuplicate is returned when the token is expired (after 300 seconds) or already validated.
+	ErrorTimeoutOrDuplicate ErrorCode = "timeout-or-duplicate"
+	ErrorInternalError      ErrorCode = "internal-error"
+)
+
+// Error is returned when the token is not valid.
+type Error struct {
+	// Codes are the error codes returned by the siteverify endpoint, or given by Verifier (e.g. the mismatch of the action).
+	Codes []ErrorCode
+}
+
+func (e *Error) Error() string {
+	codes := make([]string, len(e.Codes))
+	for i, c := range e.Codes {
+		codes[i] = string(c)
+	}
+	return "turnstile: token is not valid: " + strings.Join(codes, ", ")
+}
+
+// Has reports whether the error has the code.
+func (e *Error) Has(code ErrorCode) bool {
+	for _, c := range e.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Error codes given by Verifier when the token is valid but not for the expected action or hostname.
+const (
+	ErrorActionMismatch   ErrorCode = "action-mismatch"
+	ErrorHostnameMismatch ErrorCode = "hostname-mismatch"
+)
+
+// Response represents the response of the siteverify endpoint.
+type Response struct {
+	Success bool `json:"success"`
+	// ChallengeTS is the time when the challenge was solved.
+	ChallengeTS time.Time `json:"challenge_ts"`
+	// Hostname is the hostname of the site where the challenge was solved.
+	Hostname   string      `json:"hostname"`
+	ErrorCodes []ErrorCode `json:"error-codes"`
+	// Action is the action given to the widget.
+	Action string `json:"action"`
+	// CData is the customer data given to the widget.
+	CData string `json:"cdata"`
+}
+
+// Options represents the options of Verifier.
+type Options struct {
+	// Client sends requests to the siteverify endpoint. The default client sends requests by fetch.
+	Client *http.Client
+	// URL is the URL of the siteverify endpoint. The default is SiteverifyURL.
+	URL string
+	// Action is the expected action of tokens. if Action is not empty, tokens of other actions are rejected with ErrorActionMismatch.
+	Action string
+	// Hostname is the expected hostname of tokens. if Hostname is not empty, tokens of other hostnames are rejected with ErrorHostnameMismatch.
+	Hostname string
+	// Retries is the number of retries when the request to the endpoint fails (e.g. network errors or `internal-error`).
+	// Retries are sent with the same idempotency key, so the token is not rejected as duplicate.
+	Retries int
+}
+
+// Verifier validates tokens with the secret key of the widget.
+type Verifier struct {
+	secret string
+	opts   Options
+}
+
+// NewVerifier returns Verifier with the secret key of the widget.
+func NewVerifier(secret string, opts *Options) *Verifier {
+	v := &Verifier{secret: secret}
+	if opts != nil {
+		v.opts = *opts
+	}
+	if v.opts.Client == nil {
+		v.opts.Client = &http.Client{Transport: defaultTransport()}
+	}
+	if v.opts.URL == "" {
+		v.opts.URL = SiteverifyURL
+	}
+	return v
+}
+
+// VerifyOptions represents the options of Verify.
+type VerifyOptions struct {
+	// RemoteIP is the IP address of the visitor, e.g. the `CF-Connecting-IP` header.
+	RemoteIP string
+	// IdempotencyKey is the UUID which makes the validation of the same token repeatable.
+	// if IdempotencyKey is empty and Retries of Options is not 0, the key is generated.
+	IdempotencyKey string
+}
+
+// Verify validates the token by the siteverify endpoint.
+//   - if the token is not valid, returns *Error with the error codes, with the response.
+//   - Tokens can be validated only once unless the same IdempotencyKey is given.
+func (v *Verifier) Verify(ctx context.Context, token string, opts *VerifyOptions) (*Response, error) {
+	if opts == nil {
+		opts = &VerifyOptions{}
+	}
+	if token == "" {
+		return nil, &Error{Codes: []ErrorCode{ErrorMissingInputResponse}}
+	}
+	if len(token) > maxTokenLength {
+		return nil, &Error{Codes: []ErrorCode{ErrorInvalidInputResponse}}
+	}
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if opts.RemoteIP != "" {
+		form.Set("remoteip", opts.RemoteIP)
+	}
+	idempotencyKey := opts.IdempotencyKey
+	if idempotencyKey == "" && v.opts.Retries > 0 {
+		key, err := newUUID()
+		if err != nil {
+			return nil, err
+		}
+		idempotencyKey = key
+	}
+	if idempotencyKey != "" {
+		form.Set("idempotency_key", idempotencyKey)
+	}
+	var (
+		res *Response
+		err error
+	)
+	for i := 0; i <= v.opts.Retries; i++ {
+		res, err = v.post(ctx, form)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err == nil && !(len(res.ErrorCodes) == 1 && res.ErrorCodes[0] == ErrorInternalError) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !res.Success {
+		return res, &Error{Codes: res.ErrorCodes}
+	}
+	var codes []ErrorCode
+	if v.opts.Action != "" && res.Action != v.opts.Action {
+		codes = append(codes, ErrorActionMismatch)
+	}
+	if v.opts.Hostname != "" && res.Hostname != v.opts.Hostname {
+		codes = append(codes, ErrorHostnameMismatch)
+	}
+	if len(codes) > 0 {
+		return res, &Error{Codes: codes}
+	}
+	return res, nil
+}
+
+func (v *Verifier) post(ctx context.Context, form url.Values) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.opts.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpRes, err := v.opts.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("turnstile: error sending request: %w", err)
+	}
+	defer httpRes.Body.Close()
+	if httpRes.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("turnstile: unexpected status: %s", httpRes.Status)
+	}
+	var res Response
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("turnstile: error decoding response: %w", err)
+	}
+	return &res, nil
+}
+
+// newUUID returns a random UUID (version 4).
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
+
+// IsInvalid reports whether err is returned because the token is not valid, rather than failures of the request.
+func IsInvalid(err error) bool {
+	var e *Error
+	return errors.As(err, &e)
+}
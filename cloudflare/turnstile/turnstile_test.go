@@ -0,0 +1,133 @@
+package turnstile
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// fakeSiteverify responds to the tokens by the responses, and records the forms of requests.
+func fakeSiteverify(responses map[string]string, forms *[]url.Values) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		form, _ := url.ParseQuery(string(b))
+		*forms = append(*forms, form)
+		body, ok := responses[form.Get("response")]
+		if !ok {
+			body = `{"success":false,"error-codes":["invalid-input-response"]}`
+		}
+		if body == "fail" {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+	})}
+}
+
+func TestVerify(t *testing.T) {
+	var forms []url.Values
+	client := fakeSiteverify(map[string]string{
+		"ok":       `{"success":true,"hostname":"example.com","action":"login","challenge_ts":"2024-01-02T03:04:05.000Z"}`,
+		"other":    `{"success":true,"hostname":"other.com","action":"signup"}`,
+		"internal": `{"success":false,"error-codes":["internal-error"]}`,
+	}, &forms)
+	v := NewVerifier("secret", &Options{Client: client, Action: "login", Hostname: "example.com"})
+	ctx := context.Background()
+
+	res, err := v.Verify(ctx, "ok", &VerifyOptions{RemoteIP: "192.0.2.1", IdempotencyKey: "key"})
+	if err != nil || !res.Success || res.ChallengeTS.Year() != 2024 {
+		t.Fatalf("Verify(ok) = %+v, %v", res, err)
+	}
+	if f := forms[0]; f.Get("secret") != "secret" || f.Get("remoteip") != "192.0.2.1" || f.Get("idempotency_key") != "key" {
+		t.Errorf("unexpected form: %v", f)
+	}
+
+	var e *Error
+	if _, err := v.Verify(ctx, "other", nil); !errors.As(err, &e) || !e.Has(ErrorActionMismatch) || !e.Has(ErrorHostnameMismatch) {
+		t.Errorf("Verify(other) = %v", err)
+	}
+	if _, err := v.Verify(ctx, "invalid", nil); !errors.As(err, &e) || !e.Has(ErrorInvalidInputResponse) {
+		t.Errorf("Verify(invalid) = %v", err)
+	}
+	if _, err := v.Verify(ctx, "", nil); !errors.As(err, &e) || !e.Has(ErrorMissingInputResponse) {
+		t.Errorf("Verify(empty) = %v", err)
+	}
+}
+
+func TestVerify_Retries(t *testing.T) {
+	var forms []url.Values
+	client := fakeSiteverify(map[string]string{"internal": `{"success":false,"error-codes":["internal-error"]}`, "fail": "fail"}, &forms)
+	v := NewVerifier("secret", &Options{Client: client, Retries: 2})
+	_, err := v.Verify(context.Background(), "internal", nil)
+	if !IsInvalid(err) {
+		t.Errorf("want *Error, got %v", err)
+	}
+	if len(forms) != 3 {
+		t.Fatalf("want 3 requests, got %d", len(forms))
+	}
+	key := forms[0].Get("idempotency_key")
+	if len(key) != 36 || forms[1].Get("idempotency_key") != key || forms[2].Get("idempotency_key") != key {
+		t.Errorf("retries must have the same idempotency key, got %v", forms)
+	}
+
+	forms = nil
+	if _, err := v.Verify(context.Background(), "fail", nil); err == nil || IsInvalid(err) {
+		t.Errorf("want request error, got %v", err)
+	}
+	if len(forms) != 3 {
+		t.Errorf("want 3 requests, got %d", len(forms))
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var forms []url.Values
+	client := fakeSiteverify(map[string]string{"ok": `{"success":true}`}, &forms)
+	h := Middleware(NewVerifier("secret", &Options{Client: client}), nil)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := FromContext(req.Context()); !ok {
+			t.Error("response is not in the context")
+		}
+		b, _ := io.ReadAll(req.Body)
+		io.WriteString(w, req.FormValue("name")+string(b))
+	}))
+	tests := map[string]struct {
+		contentType string
+		body        string
+		header      string
+		wantStatus  int
+		wantBody    string
+	}{
+		"form":      {contentType: "application/x-www-form-urlencoded", body: "name=a&cf-turnstile-response=ok", wantStatus: http.StatusOK, wantBody: "a"},
+		"json":      {contentType: "application/json", body: `{"cf-turnstile-response":"ok"}`, wantStatus: http.StatusOK, wantBody: `{"cf-turnstile-response":"ok"}`},
+		"header":    {contentType: "text/plain", body: "b", header: "ok", wantStatus: http.StatusOK, wantBody: "b"},
+		"invalid":   {contentType: "application/x-www-form-urlencoded", body: "cf-turnstile-response=invalid", wantStatus: http.StatusForbidden},
+		"no token":  {contentType: "application/json", body: `{}`, wantStatus: http.StatusForbidden},
+		"too large": {contentType: "application/json", body: strings.Repeat(" ", defaultMaxBodySize+1), wantStatus: http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.header != "" {
+				req.Header.Set("CF-Turnstile-Response", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("want %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.wantBody {
+				t.Errorf("want %q, got %q", tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package urlpattern
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Mux is http.Handler which routes requests by URLPattern.
+//   - Patterns are matched in the order of registration, and the first matched handler is used.
+//   - if no pattern matches, responds 404 Not Found.
+type Mux struct {
+	routes []*route
+	// NotFound is the handler used when no pattern matches. if NotFound is nil, http.NotFound is used.
+	NotFound http.Handler
+}
+
+type route struct {
+	method  string
+	pattern *Pattern
+	handler http.Handler
+}
+
+// NewMux returns a new Mux.
+func NewMux() *Mux {
+	return &Mux{}
+}
+
+// Handle registers the handler for the pattern.
+//   - pattern is a pathname pattern (e.g. `/users/:id`) optionally prefixed by a method and a space (e.g. `GET /users/:id`).
+//   - Handle panics if the pattern is invalid.
+func (m *Mux) Handle(pattern string, handler http.Handler) {
+	var method string
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		method, pattern = pattern[:i], strings.TrimLeft(pattern[i+1:], " ")
+	}
+	p, err := NewFromInit(&Init{Pathname: pattern}, nil)
+	if err != nil {
+		panic("urlpattern: invalid pattern " + pattern + ": " + err.Error())
+	}
+	m.HandlePattern(method, p, handler)
+}
+
+// HandleFunc registers the handler function for the pattern. See Handle for details.
+func (m *Mux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// HandlePattern registers the handler for the compiled pattern, which can match any URL components.
+//   - if method is empty, the route matches any method.
+func (m *Mux) HandlePattern(method string, p *Pattern, handler http.Handler) {
+	m.routes = append(m.routes, &route{method: method, pattern: p, handler: handler})
+}
+
+func (m *Mux) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	url := req.URL.String()
+	for _, r := range m.routes {
+		if r.method != "" && r.method != req.Method {
+			continue
+		}
+		var result *Result
+		if req.URL.IsAbs() {
+			result = r.pattern.Exec(url)
+		} else {
+			result = r.pattern.ExecInit(&Init{Pathname: req.URL.EscapedPath(), Search: req.URL.RawQuery})
+		}
+		if result == nil {
+			continue
+		}
+		ctx := context.WithValue(req.Context(), resultKey{}, result)
+		r.handler.ServeHTTP(w, req.WithContext(ctx))
+		return
+	}
+	if m.NotFound != nil {
+		m.NotFound.ServeHTTP(w, req)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+type resultKey struct{}
+
+// ResultFromContext returns the matched result of the request routed by Mux.
+//   - if the request was not routed by Mux, returns nil.
+func ResultFromContext(ctx context.Context) *Result {
+	result, _ := ctx.Value(resultKey{}).(*Result)
+	return result
+}
+
+// Param returns the value of the named group of pathname in the request routed by Mux.
+//   - if the group doesn't exist, returns empty string.
+func Param(req *http.Request, name string) string {
+	result := ResultFromContext(req.Context())
+	if result == nil {
+		return ""
+	}
+	return result.Pathname.Groups[name]
+}
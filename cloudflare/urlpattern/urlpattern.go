@@ -0,0 +1,200 @@
+// Package urlpattern provides URLPattern of the Workers runtime.
+//   - Matching is done natively by the runtime, so complex patterns don't need to be compiled and matched on Wasm.
+//   - https://developers.cloudflare.com/workers/runtime-apis/web-standards/#urlpattern
+package urlpattern
+
+import (
+	"errors"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// ErrNotSupported is returned when URLPattern is not available on the runtime.
+var ErrNotSupported = errors.New("urlpattern: URLPattern is not supported by the runtime")
+
+// Init represents components of a pattern. Empty components match any value, except that
+// components before the first specified component are inherited from BaseURL.
+//   - https://developer.mozilla.org/docs/Web/API/URLPattern/URLPattern
+type Init struct {
+	Protocol string
+	Username string
+	Password string
+	Hostname string
+	Port     string
+	Pathname string
+	Search   string
+	Hash     string
+	BaseURL  string
+}
+
+func (init *Init) toJS() js.Value {
+	obj := jsutil.NewObject()
+	for key, value := range map[string]string{
+		"protocol": init.Protocol,
+		"username": init.Username,
+		"password": init.Password,
+		"hostname": init.Hostname,
+		"port":     init.Port,
+		"pathname": init.Pathname,
+		"search":   init.Search,
+		"hash":     init.Hash,
+		"baseURL":  init.BaseURL,
+	} {
+		if value != "" {
+			obj.Set(key, value)
+		}
+	}
+	return obj
+}
+
+// Options represents the options of patterns.
+type Options struct {
+	// IgnoreCase makes the matching case-insensitive.
+	IgnoreCase bool
+}
+
+func (opts *Options) toJS() js.Value {
+	if opts == nil {
+		return js.Undefined()
+	}
+	obj := jsutil.NewObject()
+	obj.Set("ignoreCase", opts.IgnoreCase)
+	return obj
+}
+
+// Pattern represents URLPattern.
+type Pattern struct {
+	instance js.Value
+}
+
+// New returns Pattern compiled from the pattern string (e.g. `https://*.example.com/books/:id`).
+//   - if the pattern is relative, baseURL is used to resolve it. baseURL can be empty for absolute patterns.
+//   - if the pattern is invalid, returns error.
+func New(pattern, baseURL string, opts *Options) (*Pattern, error) {
+	if baseURL == "" {
+		return newPattern(pattern, opts.toJS())
+	}
+	return newPattern(pattern, baseURL, opts.toJS())
+}
+
+// NewFromInit returns Pattern compiled from the components.
+//   - if any component is invalid, returns error.
+func NewFromInit(init *Init, opts *Options) (*Pattern, error) {
+	return newPattern(init.toJS(), opts.toJS())
+}
+
+var urlPatternClass = jsutil.LazyGlobal("URLPattern")
+
+func newPattern(args ...any) (*Pattern, error) {
+	class := urlPatternClass()
+	if class.Type() != js.TypeFunction {
+		return nil, ErrNotSupported
+	}
+	inst, err := jsutil.TryNew(class, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{instance: inst}, nil
+}
+
+// Protocol returns the normalized protocol pattern.
+func (p *Pattern) Protocol() string { return p.instance.Get("protocol").String() }
+
+// Username returns the normalized username pattern.
+func (p *Pattern) Username() string { return p.instance.Get("username").String() }
+
+// Password returns the normalized password pattern.
+func (p *Pattern) Password() string { return p.instance.Get("password").String() }
+
+// Hostname returns the normalized hostname pattern.
+func (p *Pattern) Hostname() string { return p.instance.Get("hostname").String() }
+
+// Port returns the normalized port pattern.
+func (p *Pattern) Port() string { return p.instance.Get("port").String() }
+
+// Pathname returns the normalized pathname pattern.
+func (p *Pattern) Pathname() string { return p.instance.Get("pathname").String() }
+
+// Search returns the normalized search pattern.
+func (p *Pattern) Search() string { return p.instance.Get("search").String() }
+
+// Hash returns the normalized hash pattern.
+func (p *Pattern) Hash() string { return p.instance.Get("hash").String() }
+
+// Test reports whether the URL matches the pattern.
+//   - if the URL is invalid, returns false.
+func (p *Pattern) Test(url string) bool {
+	return p.Exec(url) != nil
+}
+
+// Exec matches the URL with the pattern, and returns the result.
+//   - if the URL doesn't match or is invalid, returns nil.
+func (p *Pattern) Exec(url string) *Result {
+	return p.exec(url)
+}
+
+// ExecInit matches the URL components with the pattern, and returns the result.
+// Unspecified components are matched as empty strings.
+//   - if the components don't match, returns nil.
+func (p *Pattern) ExecInit(init *Init) *Result {
+	return p.exec(init.toJS())
+}
+
+func (p *Pattern) exec(input any) *Result {
+	v, err := jsutil.TryCall(p.instance, "exec", input)
+	if err != nil || v.IsNull() || v.IsUndefined() {
+		return nil
+	}
+	return toResult(v)
+}
+
+// ComponentResult represents the matched result of a URL component.
+type ComponentResult struct {
+	// Input is the value of the matched component.
+	Input string
+	// Groups are values of named groups (e.g. `:id`) and indexed wildcards (e.g. `0`).
+	// Optional groups which didn't match are omitted.
+	Groups map[string]string
+}
+
+// Result represents the matched result of URLPattern.
+type Result struct {
+	Protocol ComponentResult
+	Username ComponentResult
+	Password ComponentResult
+	Hostname ComponentResult
+	Port     ComponentResult
+	Pathname ComponentResult
+	Search   ComponentResult
+	Hash     ComponentResult
+}
+
+func toResult(v js.Value) *Result {
+	return &Result{
+		Protocol: toComponentResult(v.Get("protocol")),
+		Username: toComponentResult(v.Get("username")),
+		Password: toComponentResult(v.Get("password")),
+		Hostname: toComponentResult(v.Get("hostname")),
+		Port:     toComponentResult(v.Get("port")),
+		Pathname: toComponentResult(v.Get("pathname")),
+		Search:   toComponentResult(v.Get("search")),
+		Hash:     toComponentResult(v.Get("hash")),
+	}
+}
+
+func toComponentResult(v js.Value) ComponentResult {
+	groups := map[string]string{}
+	groupsObj := v.Get("groups")
+	keys := jsutil.ObjectClass().Call("keys", groupsObj)
+	for i := 0; i < keys.Length(); i++ {
+		key := keys.Index(i).String()
+		if value := groupsObj.Get(key); value.Type() == js.TypeString {
+			groups[key] = value.String()
+		}
+	}
+	return ComponentResult{
+		Input:  v.Get("input").String(),
+		Groups: groups,
+	}
+}
@@ -0,0 +1,257 @@
+// Package vectorize provides the binding of Vectorize indexes.
+//   - https://developers.cloudflare.com/vectorize/
+package vectorize
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Index represents the binding of a Vectorize index.
+//   - https://developers.cloudflare.com/vectorize/reference/client-api/
+type Index struct {
+	instance js.Value
+}
+
+// NewIndex returns Index for given variable name.
+//   - variable name must be defined in wrangler.toml as vectorize's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewIndex(ctx context.Context, varName string) (*Index, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Index{instance: inst}, nil
+}
+
+// Vector represents a vector stored in the index.
+type Vector struct {
+	ID     string
+	Values []float32
+	// Namespace is the namespace of the vector. Queries can be restricted to a namespace.
+	Namespace string
+	// Metadata is the metadata of the vector. values must be string, numbers, bool or nested objects of them.
+	Metadata map[string]any
+}
+
+func (v *Vector) toJS() (js.Value, error) {
+	obj := jsutil.NewObject()
+	obj.Set("id", v.ID)
+	obj.Set("values", jsutil.Float32SliceToJS(v.Values))
+	if v.Namespace != "" {
+		obj.Set("namespace", v.Namespace)
+	}
+	if v.Metadata != nil {
+		metadata, err := jsutil.ToJSValue(v.Metadata)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("error converting metadata of %s: %w", v.ID, err)
+		}
+		obj.Set("metadata", metadata)
+	}
+	return obj, nil
+}
+
+func toVector(obj js.Value) *Vector {
+	v := &Vector{
+		ID: obj.Get("id").String(),
+	}
+	if values := obj.Get("values"); !values.IsUndefined() && !values.IsNull() {
+		v.Values = jsutil.ToFloat32Slice(values)
+	}
+	if ns := obj.Get("namespace"); ns.Type() == js.TypeString {
+		v.Namespace = ns.String()
+	}
+	if metadata, ok := jsutil.ToGoValue(obj.Get("metadata")).(map[string]any); ok {
+		v.Metadata = metadata
+	}
+	return v
+}
+
+// MutationResult represents the result of mutations.
+//   - Mutations are applied asynchronously. MutationID can be compared with IndexInfo's ProcessedUpToMutation.
+type MutationResult struct {
+	MutationID string
+}
+
+func toMutationResult(obj js.Value) *MutationResult {
+	res := &MutationResult{}
+	if id := obj.Get("mutationId"); id.Type() == js.TypeString {
+		res.MutationID = id.String()
+	}
+	return res
+}
+
+// Insert inserts the vectors into the index.
+//   - vectors which have the same IDs as existing vectors are ignored.
+func (i *Index) Insert(vectors []*Vector) (*MutationResult, error) {
+	return i.mutate("insert", vectors)
+}
+
+// Upsert inserts the vectors into the index, overwriting vectors which have the same IDs.
+func (i *Index) Upsert(vectors []*Vector) (*MutationResult, error) {
+	return i.mutate("upsert", vectors)
+}
+
+func (i *Index) mutate(method string, vectors []*Vector) (*MutationResult, error) {
+	arr := jsutil.ArrayClass().New(len(vectors))
+	for idx, v := range vectors {
+		obj, err := v.toJS()
+		if err != nil {
+			return nil, err
+		}
+		arr.SetIndex(idx, obj)
+	}
+	v, err := jsutil.AwaitPromise(i.instance.Call(method, arr))
+	if err != nil {
+		return nil, err
+	}
+	return toMutationResult(v), nil
+}
+
+// ReturnMetadata represents which metadata are returned by Query.
+type ReturnMetadata string
+
+const (
+	ReturnMetadataNone    ReturnMetadata = "none"
+	ReturnMetadataIndexed ReturnMetadata = "indexed"
+	ReturnMetadataAll     ReturnMetadata = "all"
+)
+
+// QueryOptions represents the options of Query.
+type QueryOptions struct {
+	// TopK is the number of matches. The default value is 5.
+	TopK int
+	// Namespace restricts matches to the namespace.
+	Namespace string
+	// ReturnValues includes the values of vectors in matches.
+	ReturnValues bool
+	// ReturnMetadata is which metadata are included in matches. The default value is ReturnMetadataNone.
+	ReturnMetadata ReturnMetadata
+	// Filter is the metadata filter of matches, e.g. `{"genre": {"$eq": "drama"}}`.
+	//   - https://developers.cloudflare.com/vectorize/reference/metadata-filtering/
+	Filter map[string]any
+}
+
+func (opts *QueryOptions) toJS() (js.Value, error) {
+	if opts == nil {
+		return js.Undefined(), nil
+	}
+	obj := jsutil.NewObject()
+	if opts.TopK != 0 {
+		obj.Set("topK", opts.TopK)
+	}
+	if opts.Namespace != "" {
+		obj.Set("namespace", opts.Namespace)
+	}
+	if opts.ReturnValues {
+		obj.Set("returnValues", true)
+	}
+	if opts.ReturnMetadata != "" {
+		obj.Set("returnMetadata", string(opts.ReturnMetadata))
+	}
+	if opts.Filter != nil {
+		filter, err := jsutil.ToJSValue(opts.Filter)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("error converting filter: %w", err)
+		}
+		obj.Set("filter", filter)
+	}
+	return obj, nil
+}
+
+// Match represents a vector matched by Query.
+type Match struct {
+	Vector
+	// Score is the similarity score of the vector.
+	Score float64
+}
+
+// QueryResult represents the result of Query.
+type QueryResult struct {
+	Count   int
+	Matches []*Match
+}
+
+// Query returns vectors near the given vector.
+func (i *Index) Query(vector []float32, opts *QueryOptions) (*QueryResult, error) {
+	optsObj, err := opts.toJS()
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsutil.AwaitPromise(i.instance.Call("query", jsutil.Float32SliceToJS(vector), optsObj))
+	if err != nil {
+		return nil, err
+	}
+	matchesArr := v.Get("matches")
+	res := &QueryResult{
+		Count:   v.Get("count").Int(),
+		Matches: make([]*Match, matchesArr.Length()),
+	}
+	for idx := range res.Matches {
+		m := matchesArr.Index(idx)
+		res.Matches[idx] = &Match{
+			Vector: *toVector(m),
+			Score:  m.Get("score").Float(),
+		}
+	}
+	return res, nil
+}
+
+// GetByIDs returns the vectors of the IDs.
+func (i *Index) GetByIDs(ids []string) ([]*Vector, error) {
+	v, err := jsutil.AwaitPromise(i.instance.Call("getByIds", toJSStringArray(ids)))
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]*Vector, v.Length())
+	for idx := range vectors {
+		vectors[idx] = toVector(v.Index(idx))
+	}
+	return vectors, nil
+}
+
+// DeleteByIDs deletes the vectors of the IDs.
+func (i *Index) DeleteByIDs(ids []string) (*MutationResult, error) {
+	v, err := jsutil.AwaitPromise(i.instance.Call("deleteByIds", toJSStringArray(ids)))
+	if err != nil {
+		return nil, err
+	}
+	return toMutationResult(v), nil
+}
+
+// IndexInfo represents the information of the index.
+type IndexInfo struct {
+	VectorCount int
+	Dimensions  int
+	// ProcessedUpToMutation is the ID of the last mutation applied to the index.
+	ProcessedUpToMutation string
+}
+
+// Describe returns the information of the index.
+func (i *Index) Describe() (*IndexInfo, error) {
+	v, err := jsutil.AwaitPromise(i.instance.Call("describe"))
+	if err != nil {
+		return nil, err
+	}
+	info := &IndexInfo{
+		VectorCount: v.Get("vectorCount").Int(),
+		Dimensions:  v.Get("dimensions").Int(),
+	}
+	if m := v.Get("processedUpToMutation"); m.Type() == js.TypeString {
+		info.ProcessedUpToMutation = m.String()
+	}
+	return info, nil
+}
+
+func toJSStringArray(strs []string) js.Value {
+	arr := jsutil.ArrayClass().New(len(strs))
+	for i, s := range strs {
+		arr.SetIndex(i, s)
+	}
+	return arr
+}
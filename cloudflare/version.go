@@ -0,0 +1,46 @@
+//go:build js && wasm
+
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// VersionMetadata represents the version of the deployed worker.
+//   - https://developers.cloudflare.com/workers/runtime-apis/bindings/version-metadata/
+type VersionMetadata struct {
+	// ID is the ID of the version.
+	ID string
+	// Tag is the tag of the version. Tag is empty if the version is not tagged.
+	Tag string
+	// Timestamp is the time when the version was created.
+	Timestamp time.Time
+}
+
+// NewVersionMetadata returns VersionMetadata for given variable name.
+//   - variable name must be defined in wrangler.toml as version_metadata's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewVersionMetadata(ctx context.Context, varName string) (*VersionMetadata, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	m := &VersionMetadata{
+		ID:  jsutil.MaybeString(inst.Get("id")),
+		Tag: jsutil.MaybeString(inst.Get("tag")),
+	}
+	if ts := jsutil.MaybeString(inst.Get("timestamp")); ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing timestamp of version metadata: %w", err)
+		}
+		m.Timestamp = t
+	}
+	return m, nil
+}
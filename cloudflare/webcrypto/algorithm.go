@@ -0,0 +1,159 @@
+package webcrypto
+
+import (
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Hash represents the hash algorithm.
+type Hash string
+
+const (
+	SHA1   Hash = "SHA-1"
+	SHA256 Hash = "SHA-256"
+	SHA384 Hash = "SHA-384"
+	SHA512 Hash = "SHA-512"
+	// MD5 is only available for Digest on Cloudflare Workers. It must not be used for security purposes.
+	MD5 Hash = "MD5"
+)
+
+// Algorithm represents the algorithm and its parameters of key operations.
+//   - The same value can be used to import, generate and use keys. Parameters which are not used by the operation are ignored.
+//   - https://developer.mozilla.org/docs/Web/API/SubtleCrypto
+type Algorithm interface {
+	toJS() js.Value
+}
+
+// HMAC represents the HMAC algorithm.
+type HMAC struct {
+	Hash Hash
+	// Length is the length of the key in bits. if Length is 0, the block size of the hash is used.
+	Length int
+}
+
+func (a *HMAC) toJS() js.Value {
+	obj := newAlgorithm("HMAC")
+	setString(obj, "hash", string(a.Hash))
+	setInt(obj, "length", a.Length)
+	return obj
+}
+
+// ECDSA represents the ECDSA algorithm.
+type ECDSA struct {
+	// Hash is the hash algorithm used to sign.
+	Hash Hash
+	// NamedCurve is the curve of keys: `P-256`, `P-384` or `P-521`.
+	NamedCurve string
+}
+
+func (a *ECDSA) toJS() js.Value {
+	obj := newAlgorithm("ECDSA")
+	setString(obj, "hash", string(a.Hash))
+	setString(obj, "namedCurve", a.NamedCurve)
+	return obj
+}
+
+// Ed25519 represents the Ed25519 algorithm.
+type Ed25519 struct{}
+
+func (a *Ed25519) toJS() js.Value {
+	return newAlgorithm("Ed25519")
+}
+
+// RSASSAPKCS1v15 represents the RSASSA-PKCS1-v1_5 algorithm.
+type RSASSAPKCS1v15 struct {
+	Hash Hash
+	// ModulusLength is the length of generated keys in bits. This is only used by GenerateKeyPair.
+	ModulusLength int
+}
+
+func (a *RSASSAPKCS1v15) toJS() js.Value {
+	return newRSAAlgorithm("RSASSA-PKCS1-v1_5", a.Hash, a.ModulusLength)
+}
+
+// RSAPSS represents the RSA-PSS algorithm.
+type RSAPSS struct {
+	Hash Hash
+	// SaltLength is the length of the salt in bytes, which is used to sign.
+	SaltLength int
+	// ModulusLength is the length of generated keys in bits. This is only used by GenerateKeyPair.
+	ModulusLength int
+}
+
+func (a *RSAPSS) toJS() js.Value {
+	obj := newRSAAlgorithm("RSA-PSS", a.Hash, a.ModulusLength)
+	obj.Set("saltLength", a.SaltLength)
+	return obj
+}
+
+// RSAOAEP represents the RSA-OAEP algorithm.
+type RSAOAEP struct {
+	Hash Hash
+	// Label is the label of encryption.
+	Label []byte
+	// ModulusLength is the length of generated keys in bits. This is only used by GenerateKeyPair.
+	ModulusLength int
+}
+
+func (a *RSAOAEP) toJS() js.Value {
+	obj := newRSAAlgorithm("RSA-OAEP", a.Hash, a.ModulusLength)
+	if a.Label != nil {
+		obj.Set("label", toUint8Array(a.Label))
+	}
+	return obj
+}
+
+// AESGCM represents the AES-GCM algorithm.
+type AESGCM struct {
+	// IV is the initialization vector of encryption. It must be unique for each encryption with the same key, and 12 bytes is recommended.
+	IV []byte
+	// AdditionalData is the data which is authenticated but not encrypted.
+	AdditionalData []byte
+	// TagLength is the length of the authentication tag in bits. The default value is 128.
+	TagLength int
+	// Length is the length of generated keys in bits: 128, 192 or 256. This is only used by GenerateKey.
+	Length int
+}
+
+func (a *AESGCM) toJS() js.Value {
+	obj := newAlgorithm("AES-GCM")
+	if a.IV != nil {
+		obj.Set("iv", toUint8Array(a.IV))
+	}
+	if a.AdditionalData != nil {
+		obj.Set("additionalData", toUint8Array(a.AdditionalData))
+	}
+	setInt(obj, "tagLength", a.TagLength)
+	setInt(obj, "length", a.Length)
+	return obj
+}
+
+func newAlgorithm(name string) js.Value {
+	obj := jsutil.NewObject()
+	obj.Set("name", name)
+	return obj
+}
+
+func newRSAAlgorithm(name string, hash Hash, modulusLength int) js.Value {
+	obj := newAlgorithm(name)
+	setString(obj, "hash", string(hash))
+	if modulusLength > 0 {
+		obj.Set("modulusLength", modulusLength)
+		// 65537
+		obj.Set("publicExponent", toUint8Array([]byte{0x01, 0x00, 0x01}))
+	}
+	return obj
+}
+
+func setString(obj js.Value, key, value string) {
+	if value != "" {
+		obj.Set(key, value)
+	}
+}
+
+func setInt(obj js.Value, key string, value int) {
+	if value > 0 {
+		obj.Set(key, value)
+	}
+}
@@ -0,0 +1,93 @@
+package webcrypto
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+const (
+	// randBufferSize is the number of random bytes fetched by one call of getRandomValues.
+	randBufferSize = 4096
+	// maxRandomValuesSize is the maximum size accepted by getRandomValues.
+	maxRandomValuesSize = 65536
+)
+
+// Reader is a cryptographically secure random number generator backed by crypto.getRandomValues.
+//   - Random bytes are fetched in batches, so small reads (e.g. UUIDs and nonces) don't cross the JavaScript boundary on every call.
+//   - Reader is safe for concurrent use, and can be used in place of crypto/rand.Reader.
+var Reader io.Reader = &randReader{off: randBufferSize}
+
+type randReader struct {
+	mu  sync.Mutex
+	buf [randBufferSize]byte
+	// off is the offset of unused bytes in buf.
+	off int
+	// jsBuf is Uint8Array reused to fill buf.
+	jsBuf js.Value
+}
+
+func (r *randReader) Read(b []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int
+	for n < len(b) {
+		if r.off == len(r.buf) {
+			if len(b)-n >= len(r.buf) {
+				// large reads bypass the buffer.
+				size := len(b) - n
+				if size > maxRandomValuesSize {
+					size = maxRandomValuesSize
+				}
+				if err := fillRandom(jsutil.NewUint8Array(size), b[n:n+size]); err != nil {
+					return n, err
+				}
+				n += size
+				continue
+			}
+			if r.jsBuf.IsUndefined() {
+				r.jsBuf = jsutil.NewUint8Array(len(r.buf))
+			}
+			if err := fillRandom(r.jsBuf, r.buf[:]); err != nil {
+				return n, err
+			}
+			r.off = 0
+		}
+		c := copy(b[n:], r.buf[r.off:])
+		// consumed bytes must not remain in memory.
+		for i := r.off; i < r.off+c; i++ {
+			r.buf[i] = 0
+		}
+		r.off += c
+		n += c
+	}
+	return n, nil
+}
+
+// fillRandom fills ua with random values, and copies them into b.
+func fillRandom(ua js.Value, b []byte) error {
+	if _, err := jsutil.TryCall(crypto(), "getRandomValues", ua); err != nil {
+		return fmt.Errorf("webcrypto: getRandomValues failed: %w", err)
+	}
+	js.CopyBytesToGo(b, ua)
+	return nil
+}
+
+// Read fills b with cryptographically secure random bytes from Reader.
+func Read(b []byte) (int, error) {
+	return io.ReadFull(Reader, b)
+}
+
+// RandomUUID returns a random UUID (version 4) generated from Reader.
+func RandomUUID() (string, error) {
+	var b [16]byte
+	if _, err := Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
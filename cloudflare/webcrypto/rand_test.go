@@ -0,0 +1,62 @@
+package webcrypto
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	for _, size := range []int{0, 1, 16, randBufferSize - 1, randBufferSize, randBufferSize + 1, maxRandomValuesSize + 10} {
+		b := make([]byte, size)
+		n, err := Read(b)
+		if err != nil {
+			t.Fatalf("size %d: unexpected error: %v", size, err)
+		}
+		if n != size {
+			t.Errorf("size %d: want %d bytes, got %d", size, size, n)
+		}
+		if size >= 16 && bytes.Equal(b, make([]byte, size)) {
+			t.Errorf("size %d: got all zero bytes", size)
+		}
+	}
+}
+
+func TestReadUnique(t *testing.T) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b := make([]byte, 16)
+				if _, err := Read(b); err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				mu.Lock()
+				if seen[string(b)] {
+					t.Errorf("duplicated random bytes: %x", b)
+				}
+				seen[string(b)] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRandomUUID(t *testing.T) {
+	id, err := RandomUUID()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !uuidPattern.MatchString(id) {
+		t.Errorf("invalid UUID: %s", id)
+	}
+}
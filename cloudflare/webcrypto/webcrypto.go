@@ -0,0 +1,217 @@
+// Package webcrypto provides the Web Crypto API (crypto.subtle) of Cloudflare Workers.
+//   - Cryptographic operations are done natively by the runtime, which is much faster than pure Go implementations on Wasm.
+//   - https://developers.cloudflare.com/workers/runtime-apis/web-crypto/
+package webcrypto
+
+import (
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+var (
+	crypto = jsutil.LazyGlobal("crypto")
+	subtle = jsutil.LazyGlobal("crypto", "subtle")
+)
+
+// KeyUsage represents the operation which the key can be used for.
+type KeyUsage string
+
+const (
+	UsageEncrypt    KeyUsage = "encrypt"
+	UsageDecrypt    KeyUsage = "decrypt"
+	UsageSign       KeyUsage = "sign"
+	UsageVerify     KeyUsage = "verify"
+	UsageDeriveKey  KeyUsage = "deriveKey"
+	UsageDeriveBits KeyUsage = "deriveBits"
+	UsageWrapKey    KeyUsage = "wrapKey"
+	UsageUnwrapKey  KeyUsage = "unwrapKey"
+)
+
+// KeyFormat represents the format of imported and exported keys.
+type KeyFormat string
+
+const (
+	// FormatRaw is the raw bytes of secret keys or EC public keys.
+	FormatRaw KeyFormat = "raw"
+	// FormatPKCS8 is the DER encoded PKCS #8 of private keys.
+	FormatPKCS8 KeyFormat = "pkcs8"
+	// FormatSPKI is the DER encoded SubjectPublicKeyInfo of public keys.
+	FormatSPKI KeyFormat = "spki"
+	// FormatJWK is the JSON Web Key encoded as JSON.
+	FormatJWK KeyFormat = "jwk"
+)
+
+// Key represents CryptoKey of the Web Crypto API.
+type Key struct {
+	instance js.Value
+}
+
+// Type returns the type of the key: `secret`, `private` or `public`.
+func (k *Key) Type() string {
+	return k.instance.Get("type").String()
+}
+
+// Extractable reports whether the key can be exported.
+func (k *Key) Extractable() bool {
+	return k.instance.Get("extractable").Bool()
+}
+
+// AlgorithmName returns the name of the algorithm of the key.
+func (k *Key) AlgorithmName() string {
+	return k.instance.Get("algorithm").Get("name").String()
+}
+
+// Usages returns the usages of the key.
+func (k *Key) Usages() []KeyUsage {
+	arr := k.instance.Get("usages")
+	usages := make([]KeyUsage, arr.Length())
+	for i := range usages {
+		usages[i] = KeyUsage(arr.Index(i).String())
+	}
+	return usages
+}
+
+// KeyPair represents the pair of a public key and a private key.
+type KeyPair struct {
+	PublicKey  *Key
+	PrivateKey *Key
+}
+
+// Digest returns the digest of data.
+func Digest(hash Hash, data []byte) ([]byte, error) {
+	v, err := call("digest", string(hash), toUint8Array(data))
+	if err != nil {
+		return nil, err
+	}
+	return toBytes(v), nil
+}
+
+// ImportKey imports the key of the format.
+//   - keyData of FormatJWK must be JSON encoded JWK.
+func ImportKey(format KeyFormat, keyData []byte, algorithm Algorithm, extractable bool, usages ...KeyUsage) (*Key, error) {
+	var data js.Value
+	if format == FormatJWK {
+		var err error
+		if data, err = jsutil.TryCall(jsutil.JSONClass(), "parse", string(keyData)); err != nil {
+			return nil, fmt.Errorf("webcrypto: JWK must be valid JSON")
+		}
+	} else {
+		data = toUint8Array(keyData)
+	}
+	v, err := call("importKey", string(format), data, algorithm.toJS(), extractable, usagesToJS(usages))
+	if err != nil {
+		return nil, err
+	}
+	return &Key{instance: v}, nil
+}
+
+// ExportKey exports the key in the format. The key must be extractable.
+//   - Keys of FormatJWK are exported as JSON encoded JWK.
+func ExportKey(format KeyFormat, key *Key) ([]byte, error) {
+	v, err := call("exportKey", string(format), key.instance)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatJWK {
+		return []byte(jsutil.JSONClass().Call("stringify", v).String()), nil
+	}
+	return toBytes(v), nil
+}
+
+// GenerateKey generates a secret key, e.g. for HMAC and AES-GCM.
+func GenerateKey(algorithm Algorithm, extractable bool, usages ...KeyUsage) (*Key, error) {
+	v, err := call("generateKey", algorithm.toJS(), extractable, usagesToJS(usages))
+	if err != nil {
+		return nil, err
+	}
+	return &Key{instance: v}, nil
+}
+
+// GenerateKeyPair generates a key pair, e.g. for ECDSA, Ed25519 and RSA.
+//   - RSA algorithms require ModulusLength.
+func GenerateKeyPair(algorithm Algorithm, extractable bool, usages ...KeyUsage) (*KeyPair, error) {
+	v, err := call("generateKey", algorithm.toJS(), extractable, usagesToJS(usages))
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		PublicKey:  &Key{instance: v.Get("publicKey")},
+		PrivateKey: &Key{instance: v.Get("privateKey")},
+	}, nil
+}
+
+// Sign signs data with the key. algorithm must be the same kind of algorithm as the key.
+func Sign(algorithm Algorithm, key *Key, data []byte) ([]byte, error) {
+	v, err := call("sign", algorithm.toJS(), key.instance, toUint8Array(data))
+	if err != nil {
+		return nil, err
+	}
+	return toBytes(v), nil
+}
+
+// Verify reports whether the signature of data is valid for the key.
+//   - Comparison of HMAC signatures is done in constant time.
+func Verify(algorithm Algorithm, key *Key, signature, data []byte) (bool, error) {
+	v, err := call("verify", algorithm.toJS(), key.instance, toUint8Array(signature), toUint8Array(data))
+	if err != nil {
+		return false, err
+	}
+	return v.Bool(), nil
+}
+
+// Encrypt encrypts data with the key.
+//   - The ciphertext of AESGCM includes the authentication tag at the end.
+func Encrypt(algorithm Algorithm, key *Key, data []byte) ([]byte, error) {
+	v, err := call("encrypt", algorithm.toJS(), key.instance, toUint8Array(data))
+	if err != nil {
+		return nil, err
+	}
+	return toBytes(v), nil
+}
+
+// Decrypt decrypts data with the key.
+//   - if the ciphertext of AESGCM is not authentic, returns an error.
+func Decrypt(algorithm Algorithm, key *Key, data []byte) ([]byte, error) {
+	v, err := call("decrypt", algorithm.toJS(), key.instance, toUint8Array(data))
+	if err != nil {
+		return nil, err
+	}
+	return toBytes(v), nil
+}
+
+// call calls the method of crypto.subtle, and awaits the result.
+func call(method string, args ...any) (js.Value, error) {
+	promise, err := jsutil.TryCall(subtle(), method, args...)
+	if err != nil {
+		return js.Value{}, fmt.Errorf("webcrypto: %s failed: %w", method, err)
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return js.Value{}, fmt.Errorf("webcrypto: %s failed: %w", method, err)
+	}
+	return v, nil
+}
+
+func usagesToJS(usages []KeyUsage) js.Value {
+	arr := jsutil.ArrayClass().New(len(usages))
+	for i, u := range usages {
+		arr.SetIndex(i, string(u))
+	}
+	return arr
+}
+
+func toUint8Array(b []byte) js.Value {
+	ua := jsutil.NewUint8Array(len(b))
+	js.CopyBytesToJS(ua, b)
+	return ua
+}
+
+// toBytes copies ArrayBuffer into []byte.
+func toBytes(v js.Value) []byte {
+	ua := jsutil.Uint8ArrayClass().New(v)
+	b := make([]byte, ua.Get("byteLength").Int())
+	js.CopyBytesToGo(b, ua)
+	return b
+}
@@ -0,0 +1,114 @@
+package webcrypto
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	data := []byte("hello, world")
+	got, err := Digest(SHA256, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := sha256.Sum256(data)
+	if !bytes.Equal(got, want[:]) {
+		t.Errorf("want %x, got %x", want, got)
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	secret := []byte("secret")
+	data := []byte("hello, world")
+	key, err := ImportKey(FormatRaw, secret, &HMAC{Hash: SHA256}, false, UsageSign, UsageVerify)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sig, err := Sign(&HMAC{}, key, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(data)
+	if want := mac.Sum(nil); !bytes.Equal(sig, want) {
+		t.Errorf("want %x, got %x", want, sig)
+	}
+	ok, err := Verify(&HMAC{}, key, sig, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Errorf("want valid signature")
+	}
+	ok, err = Verify(&HMAC{}, key, sig, []byte("tampered"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ok {
+		t.Errorf("want invalid signature for tampered data")
+	}
+}
+
+func TestAESGCM(t *testing.T) {
+	key, err := GenerateKey(&AESGCM{Length: 256}, true, UsageEncrypt, UsageDecrypt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, err := ExportKey(FormatRaw, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(raw) != 32 {
+		t.Errorf("want 32 bytes key, got %d", len(raw))
+	}
+	alg := &AESGCM{IV: make([]byte, 12), AdditionalData: []byte("aad")}
+	plaintext := []byte("hello, world")
+	ciphertext, err := Encrypt(alg, key, plaintext)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := Decrypt(alg, key, ciphertext)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("want %s, got %s", plaintext, got)
+	}
+	ciphertext[0] ^= 0xff
+	if _, err := Decrypt(alg, key, ciphertext); err == nil {
+		t.Errorf("want error for tampered ciphertext")
+	}
+}
+
+func TestECDSA(t *testing.T) {
+	pair, err := GenerateKeyPair(&ECDSA{NamedCurve: "P-256"}, true, UsageSign, UsageVerify)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data := []byte("hello, world")
+	alg := &ECDSA{Hash: SHA256}
+	sig, err := Sign(alg, pair.PrivateKey, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ok, err := Verify(alg, pair.PublicKey, sig, data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Errorf("want valid signature")
+	}
+	jwk, err := ExportKey(FormatJWK, pair.PublicKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	imported, err := ImportKey(FormatJWK, jwk, &ECDSA{NamedCurve: "P-256"}, true, UsageVerify)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imported.Type() != "public" {
+		t.Errorf("want public key, got %s", imported.Type())
+	}
+}
@@ -0,0 +1,160 @@
+// Package webhook verifies signatures of webhook requests.
+//
+// Verifier verifies the signature of the request given by the Scheme (e.g. Stripe, GitHub and Standard Webhooks),
+// with the secrets or the public keys, and Middleware rejects requests with invalid signatures.
+//   - Signatures are verified by the Web Crypto API of the runtime.
+//   - Multiple secrets can be given to rotate them. The signature is valid if it's signed by one of them.
+//   - The body is buffered once in the signed payload, and the handler reads the same buffer.
+package webhook
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoSignature is returned when the request doesn't have signatures of the scheme.
+	ErrNoSignature = errors.New("webhook: no signature")
+	// ErrInvalidSignature is returned when no signatures of the request are valid.
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrTimestampOutOfTolerance is returned when the timestamp of the request is too old or too new, which prevents replay attacks.
+	ErrTimestampOutOfTolerance = errors.New("webhook: timestamp is out of tolerance")
+	// ErrBodyTooLarge is returned when the body of the request exceeds MaxBodySize.
+	ErrBodyTooLarge = errors.New("webhook: body is too large")
+)
+
+// Algorithm represents the algorithm of signatures.
+type Algorithm int
+
+const (
+	HMACSHA256 Algorithm = iota
+	HMACSHA512
+	Ed25519
+)
+
+// Signature represents the signatures given by the headers of the request.
+type Signature struct {
+	// Signatures are the decoded signatures. The request is valid if one of them is valid.
+	Signatures [][]byte
+	// Timestamp is the time when the request is signed. It's zero if the scheme doesn't sign timestamps.
+	Timestamp time.Time
+	// Prefix precedes the body in the signed payload, e.g. `{timestamp}.` of Stripe.
+	Prefix []byte
+}
+
+// Scheme represents how requests are signed.
+type Scheme struct {
+	Algorithm Algorithm
+	// Parse returns the signatures of the request from the headers.
+	// if the request doesn't have signatures, Parse should return the error which wraps ErrNoSignature.
+	Parse func(header http.Header) (*Signature, error)
+}
+
+// GitHub is the scheme of GitHub webhooks, which signs the body by HMAC-SHA256 in the `X-Hub-Signature-256` header.
+//   - GitHub doesn't sign timestamps, so the tolerance is not applied.
+//   - https://docs.github.com/webhooks/using-webhooks/validating-webhook-deliveries
+var GitHub = &Scheme{
+	Algorithm: HMACSHA256,
+	Parse: func(header http.Header) (*Signature, error) {
+		v := header.Get("X-Hub-Signature-256")
+		if v == "" {
+			return nil, ErrNoSignature
+		}
+		sig, err := hex.DecodeString(strings.TrimPrefix(v, "sha256="))
+		if err != nil || !strings.HasPrefix(v, "sha256=") {
+			return nil, fmt.Errorf("%w: malformed X-Hub-Signature-256", ErrInvalidSignature)
+		}
+		return &Signature{Signatures: [][]byte{sig}}, nil
+	},
+}
+
+// Stripe is the scheme of Stripe webhooks, which signs `{timestamp}.{body}` by HMAC-SHA256 in the `Stripe-Signature` header.
+//   - https://docs.stripe.com/webhooks#verify-manually
+var Stripe = &Scheme{
+	Algorithm: HMACSHA256,
+	Parse: func(header http.Header) (*Signature, error) {
+		v := header.Get("Stripe-Signature")
+		if v == "" {
+			return nil, ErrNoSignature
+		}
+		s := &Signature{}
+		var ts string
+		for _, item := range strings.Split(v, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "t":
+				ts = value
+			case "v1":
+				if sig, err := hex.DecodeString(value); err == nil {
+					s.Signatures = append(s.Signatures, sig)
+				}
+			}
+		}
+		t, err := parseUnix(ts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: malformed timestamp of Stripe-Signature", ErrInvalidSignature)
+		}
+		if len(s.Signatures) == 0 {
+			return nil, fmt.Errorf("%w: no v1 signatures in Stripe-Signature", ErrNoSignature)
+		}
+		s.Timestamp = t
+		s.Prefix = []byte(ts + ".")
+		return s, nil
+	},
+}
+
+// StandardWebhooks returns the scheme of Standard Webhooks (e.g. Svix), which signs `{id}.{timestamp}.{body}`
+// in the `webhook-signature` header.
+//   - HMACSHA256 (`v1` signatures) and Ed25519 (`v1a` signatures) are supported.
+//   - Secrets of HMAC are given as `whsec_` followed by base64. Decode the base64 part for Options.Secrets.
+//   - https://github.com/standard-webhooks/standard-webhooks/blob/main/spec/standard-webhooks.md
+func StandardWebhooks(alg Algorithm) *Scheme {
+	version := "v1"
+	if alg == Ed25519 {
+		version = "v1a"
+	}
+	return &Scheme{
+		Algorithm: alg,
+		Parse: func(header http.Header) (*Signature, error) {
+			id, ts, v := header.Get("webhook-id"), header.Get("webhook-timestamp"), header.Get("webhook-signature")
+			if v == "" {
+				return nil, ErrNoSignature
+			}
+			t, err := parseUnix(ts)
+			if err != nil || id == "" {
+				return nil, fmt.Errorf("%w: malformed webhook-id or webhook-timestamp", ErrInvalidSignature)
+			}
+			s := &Signature{Timestamp: t, Prefix: []byte(id + "." + ts + ".")}
+			for _, item := range strings.Fields(v) {
+				ver, value, ok := strings.Cut(item, ",")
+				if !ok || ver != version {
+					continue
+				}
+				if sig, err := base64.StdEncoding.DecodeString(value); err == nil {
+					s.Signatures = append(s.Signatures, sig)
+				}
+			}
+			if len(s.Signatures) == 0 {
+				return nil, fmt.Errorf("%w: no %s signatures in webhook-signature", ErrNoSignature, version)
+			}
+			return s, nil
+		},
+	}
+}
+
+func parseUnix(s string) (time.Time, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
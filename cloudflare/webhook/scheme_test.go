@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGitHub(t *testing.T) {
+	s, err := GitHub.Parse(http.Header{"X-Hub-Signature-256": {"sha256=0102ff"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Signatures) != 1 || string(s.Signatures[0]) != "\x01\x02\xff" || s.Prefix != nil || !s.Timestamp.IsZero() {
+		t.Errorf("unexpected signature: %+v", s)
+	}
+	if _, err := GitHub.Parse(http.Header{}); !errors.Is(err, ErrNoSignature) {
+		t.Errorf("want ErrNoSignature, got %v", err)
+	}
+	for _, v := range []string{"sha1=0102", "sha256=zz"} {
+		if _, err := GitHub.Parse(http.Header{"X-Hub-Signature-256": {v}}); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%q: want ErrInvalidSignature, got %v", v, err)
+		}
+	}
+}
+
+func TestStripe(t *testing.T) {
+	s, err := Stripe.Parse(http.Header{"Stripe-Signature": {"t=1700000000,v1=0a0b,v0=ffff,v1=0c"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Signatures) != 2 || string(s.Signatures[1]) != "\x0c" {
+		t.Errorf("unexpected signatures: %q", s.Signatures)
+	}
+	if s.Timestamp.Unix() != 1700000000 || string(s.Prefix) != "1700000000." {
+		t.Errorf("unexpected timestamp or prefix: %v %q", s.Timestamp, s.Prefix)
+	}
+	if _, err := Stripe.Parse(http.Header{"Stripe-Signature": {"t=1700000000,v0=ffff"}}); !errors.Is(err, ErrNoSignature) {
+		t.Errorf("want ErrNoSignature, got %v", err)
+	}
+	if _, err := Stripe.Parse(http.Header{"Stripe-Signature": {"v1=0a0b"}}); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("want ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestStandardWebhooks(t *testing.T) {
+	header := http.Header{}
+	header.Set("webhook-id", "msg_1")
+	header.Set("webhook-timestamp", "1700000000")
+	header.Set("webhook-signature", "v1,AQI= v1a,AwQ= v1,BQY=")
+	s, err := StandardWebhooks(HMACSHA256).Parse(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Signatures) != 2 || string(s.Signatures[0]) != "\x01\x02" || string(s.Signatures[1]) != "\x05\x06" {
+		t.Errorf("unexpected signatures: %q", s.Signatures)
+	}
+	if string(s.Prefix) != "msg_1.1700000000." || s.Timestamp.Unix() != 1700000000 {
+		t.Errorf("unexpected prefix or timestamp: %q %v", s.Prefix, s.Timestamp)
+	}
+	s, err = StandardWebhooks(Ed25519).Parse(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Signatures) != 1 || string(s.Signatures[0]) != "\x03\x04" {
+		t.Errorf("unexpected signatures: %q", s.Signatures)
+	}
+	header.Del("webhook-id")
+	if _, err := StandardWebhooks(HMACSHA256).Parse(header); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("want ErrInvalidSignature, got %v", err)
+	}
+}
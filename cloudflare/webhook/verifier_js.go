@@ -0,0 +1,172 @@
+//go:build js && wasm
+
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+const (
+	// DefaultTolerance is the allowed difference between the timestamp of the request and the current time.
+	DefaultTolerance = 5 * time.Minute
+	// DefaultMaxBodySize is the maximum size of bodies in bytes.
+	DefaultMaxBodySize = 1 << 20
+)
+
+// Options represents the options of Verifier and Middleware.
+type Options struct {
+	// Scheme is how requests are signed, e.g. GitHub, Stripe or StandardWebhooks(HMACSHA256).
+	Scheme *Scheme
+	// Secrets are the secrets of HMAC. To rotate the secret, give both the new secret and the old one until the old one is revoked.
+	Secrets [][]byte
+	// PublicKeys are the raw public keys (32 bytes) of Ed25519.
+	PublicKeys [][]byte
+	// Tolerance is the allowed difference of timestamps. The default is DefaultTolerance, and negative values disable the check.
+	Tolerance time.Duration
+	// MaxBodySize is the maximum size of bodies in bytes. The default is DefaultMaxBodySize.
+	MaxBodySize int64
+	// Now returns the current time. The default is time.Now.
+	Now func() time.Time
+}
+
+// Verifier verifies signatures of requests with the keys imported by the Web Crypto API.
+//   - Verifier should be shared by requests, so the keys are imported only once.
+type Verifier struct {
+	opts      Options
+	algorithm webcrypto.Algorithm
+	keys      []*webcrypto.Key
+}
+
+// NewVerifier imports the keys, and returns Verifier.
+//   - if Scheme is nil or no keys are given, returns an error.
+func NewVerifier(opts *Options) (*Verifier, error) {
+	if opts == nil || opts.Scheme == nil {
+		return nil, errors.New("webhook: Scheme of Options must be set")
+	}
+	v := &Verifier{opts: *opts}
+	if v.opts.Tolerance == 0 {
+		v.opts.Tolerance = DefaultTolerance
+	}
+	if v.opts.MaxBodySize <= 0 {
+		v.opts.MaxBodySize = DefaultMaxBodySize
+	}
+	if v.opts.Now == nil {
+		v.opts.Now = time.Now
+	}
+	var keys [][]byte
+	switch opts.Scheme.Algorithm {
+	case HMACSHA256:
+		v.algorithm, keys = &webcrypto.HMAC{Hash: webcrypto.SHA256}, opts.Secrets
+	case HMACSHA512:
+		v.algorithm, keys = &webcrypto.HMAC{Hash: webcrypto.SHA512}, opts.Secrets
+	case Ed25519:
+		v.algorithm, keys = &webcrypto.Ed25519{}, opts.PublicKeys
+	default:
+		return nil, fmt.Errorf("webhook: unknown algorithm %d", opts.Scheme.Algorithm)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("webhook: no secrets or public keys are given for the algorithm of Scheme")
+	}
+	for i, b := range keys {
+		key, err := webcrypto.ImportKey(webcrypto.FormatRaw, b, v.algorithm, false, webcrypto.UsageVerify)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: error importing key %d: %w", i, err)
+		}
+		v.keys = append(v.keys, key)
+	}
+	return v, nil
+}
+
+// Verify verifies the signatures of the header for the body.
+//   - if no signatures are valid, returns the error which wraps ErrInvalidSignature or ErrTimestampOutOfTolerance.
+func (v *Verifier) Verify(header http.Header, body []byte) error {
+	sig, err := v.opts.Scheme.Parse(header)
+	if err != nil {
+		return err
+	}
+	payload := make([]byte, 0, len(sig.Prefix)+len(body))
+	payload = append(append(payload, sig.Prefix...), body...)
+	return v.verify(sig, payload)
+}
+
+// VerifyRequest verifies the signatures of the request, and replaces its body so that the handler can read it.
+//   - The body is read into the signed payload, and the handler reads the same buffer without copying.
+//   - if the body exceeds MaxBodySize, returns ErrBodyTooLarge.
+func (v *Verifier) VerifyRequest(req *http.Request) error {
+	sig, err := v.opts.Scheme.Parse(req.Header)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if req.ContentLength > 0 && req.ContentLength <= v.opts.MaxBodySize {
+		buf.Grow(len(sig.Prefix) + int(req.ContentLength))
+	}
+	buf.Write(sig.Prefix)
+	if req.Body != nil {
+		n, err := buf.ReadFrom(io.LimitReader(req.Body, v.opts.MaxBodySize+1))
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("webhook: error reading body: %w", err)
+		}
+		if n > v.opts.MaxBodySize {
+			return ErrBodyTooLarge
+		}
+	}
+	payload := buf.Bytes()
+	if err := v.verify(sig, payload); err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(payload[len(sig.Prefix):]))
+	return nil
+}
+
+func (v *Verifier) verify(sig *Signature, payload []byte) error {
+	if v.opts.Tolerance > 0 && !sig.Timestamp.IsZero() {
+		d := v.opts.Now().Sub(sig.Timestamp)
+		if d > v.opts.Tolerance || d < -v.opts.Tolerance {
+			return ErrTimestampOutOfTolerance
+		}
+	}
+	for _, s := range sig.Signatures {
+		for _, key := range v.keys {
+			ok, err := webcrypto.Verify(v.algorithm, key, s, payload)
+			if err != nil {
+				return fmt.Errorf("webhook: error verifying signature: %w", err)
+			}
+			if ok {
+				return nil
+			}
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// Middleware returns the middleware which rejects requests without valid signatures with 401 Unauthorized.
+//   - Bodies larger than MaxBodySize are rejected with 413 Request Entity Too Large.
+//   - This panics if the options are invalid.
+func Middleware(opts *Options) func(http.Handler) http.Handler {
+	v, err := NewVerifier(opts)
+	if err != nil {
+		panic(err)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if err := v.VerifyRequest(req); err != nil {
+				if errors.Is(err, ErrBodyTooLarge) {
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
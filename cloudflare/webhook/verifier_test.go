@@ -0,0 +1,134 @@
+//go:build js && wasm
+
+package webhook
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/webcrypto"
+)
+
+func signHMAC(t *testing.T, secret, payload []byte) []byte {
+	t.Helper()
+	alg := &webcrypto.HMAC{Hash: webcrypto.SHA256}
+	key, err := webcrypto.ImportKey(webcrypto.FormatRaw, secret, alg, false, webcrypto.UsageSign)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := webcrypto.Sign(alg, key, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestVerifyRequestGitHub(t *testing.T) {
+	oldSecret, newSecret := []byte("old"), []byte("new")
+	v, err := NewVerifier(&Options{Scheme: GitHub, Secrets: [][]byte{newSecret, oldSecret}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"action":"opened"}`
+	for _, secret := range [][]byte{oldSecret, newSecret} {
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(signHMAC(t, secret, []byte(body))))
+		if err := v.VerifyRequest(req); err != nil {
+			t.Fatalf("secret %q: %v", secret, err)
+		}
+		b, _ := io.ReadAll(req.Body)
+		if string(b) != body {
+			t.Errorf("body = %q", b)
+		}
+	}
+	req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(body+" "))
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(signHMAC(t, newSecret, []byte(body))))
+	if err := v.VerifyRequest(req); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("want ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestVerifyStripeTolerance(t *testing.T) {
+	secret := []byte("whsec_test")
+	now := time.Unix(1700000000, 0)
+	v, err := NewVerifier(&Options{Scheme: Stripe, Secrets: [][]byte{secret}, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(`{"id":"evt_1"}`)
+	header := func(at time.Time) http.Header {
+		ts := strconv.FormatInt(at.Unix(), 10)
+		sig := signHMAC(t, secret, append([]byte(ts+"."), body...))
+		return http.Header{"Stripe-Signature": {"t=" + ts + ",v1=" + hex.EncodeToString(sig)}}
+	}
+	if err := v.Verify(header(now.Add(-time.Minute)), body); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := v.Verify(header(now.Add(-10*time.Minute)), body); !errors.Is(err, ErrTimestampOutOfTolerance) {
+		t.Errorf("want ErrTimestampOutOfTolerance, got %v", err)
+	}
+}
+
+func TestMiddlewareStandardWebhooksEd25519(t *testing.T) {
+	pair, err := webcrypto.GenerateKeyPair(&webcrypto.Ed25519{}, true, webcrypto.UsageSign, webcrypto.UsageVerify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := webcrypto.ExportKey(webcrypto.FormatRaw, pair.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Middleware(&Options{Scheme: StandardWebhooks(Ed25519), PublicKeys: [][]byte{pub}, MaxBodySize: 64})(
+		http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			io.Copy(w, req.Body)
+		}))
+	newRequest := func(body string) *http.Request {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		sig, err := webcrypto.Sign(&webcrypto.Ed25519{}, pair.PrivateKey, []byte("msg_1."+ts+"."+body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "https://example.com/", strings.NewReader(body))
+		req.Header.Set("webhook-id", "msg_1")
+		req.Header.Set("webhook-timestamp", ts)
+		req.Header.Set("webhook-signature", "v1a,"+base64.StdEncoding.EncodeToString(sig))
+		return req
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest("hello"))
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Errorf("unexpected response: %d %q", rec.Code, rec.Body)
+	}
+
+	req := newRequest("hello")
+	req.Header.Set("webhook-id", "msg_2")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, newRequest(strings.Repeat("a", 65)))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+}
+
+func TestNewVerifierErrors(t *testing.T) {
+	if _, err := NewVerifier(nil); err == nil {
+		t.Error("want error for nil options")
+	}
+	if _, err := NewVerifier(&Options{Scheme: StandardWebhooks(Ed25519), Secrets: [][]byte{[]byte("s")}}); err == nil {
+		t.Error("want error without public keys")
+	}
+}
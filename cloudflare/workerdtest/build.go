@@ -0,0 +1,78 @@
+package workerdtest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// assetsGenPackage is the package of the command which generates the JavaScript assets of the worker.
+const assetsGenPackage = "github.com/syumai/workers/cmd/workers-assets-gen"
+
+// build builds the wasm and the JavaScript assets of the worker into the build directory under dir.
+//   - The assets generator is built in the module of the worker, so the assets match the version of this module used by the worker.
+func build(ctx context.Context, o *Options, dir string) error {
+	pkgDir := o.Dir
+	if pkgDir == "" {
+		pkgDir = "."
+	}
+	pkgDir, err := filepath.Abs(pkgDir)
+	if err != nil {
+		return err
+	}
+	if _, err := lookPath("go"); err != nil {
+		return err
+	}
+	assetsGen := filepath.Join(dir, "workers-assets-gen")
+	if err := run(ctx, o, pkgDir, hostEnv(), "go", "build", "-o", assetsGen, assetsGenPackage); err != nil {
+		return err
+	}
+	if err := run(ctx, o, dir, nil, assetsGen, "-mode="+string(o.Mode)); err != nil {
+		return err
+	}
+	wasmPath := filepath.Join(dir, "build", "app.wasm")
+	switch o.Mode {
+	case ModeGo:
+		env := append(hostEnv(), "GOOS=js", "GOARCH=wasm")
+		return run(ctx, o, pkgDir, env, "go", "build", "-o", wasmPath, ".")
+	case ModeTinygo:
+		if _, err := lookPath("tinygo"); err != nil {
+			return err
+		}
+		return run(ctx, o, pkgDir, nil, "tinygo", "build", "-o", wasmPath, "-target", "wasm", "-no-debug", ".")
+	default:
+		return fmt.Errorf("workerdtest: unsupported mode: %s", o.Mode)
+	}
+}
+
+// hostEnv returns the environment without GOOS and GOARCH, which may be set for the tests.
+func hostEnv() []string {
+	var env []string
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GOOS=") && !strings.HasPrefix(kv, "GOARCH=") {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
+
+// run runs the command in dir. if env is nil, the environment of the current process is used.
+//   - The output of the command is written to o.Output, and included in the error when the command fails.
+func run(ctx context.Context, o *Options, dir string, env []string, name string, args ...string) error {
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	o.Output.Write(out.Bytes())
+	if err != nil {
+		return fmt.Errorf("workerdtest: %s %s failed: %w\n%s", filepath.Base(name), args[0], err, out.Bytes())
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package workerdtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// entryModule is the main module of the worker generated by workers-assets-gen.
+const entryModule = "worker.mjs"
+
+// listModules returns the names of the modules in the build directory. The entry module comes first.
+func listModules(buildDir string) ([]string, error) {
+	entries, err := os.ReadDir(buildDir)
+	if err != nil {
+		return nil, err
+	}
+	modules := []string{entryModule}
+	for _, e := range entries {
+		name := e.Name()
+		switch filepath.Ext(name) {
+		case ".mjs", ".js", ".wasm":
+			if name != entryModule {
+				modules = append(modules, name)
+			}
+		}
+	}
+	return modules, nil
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// workerdConfig returns the workerd configuration in Cap'n Proto text format.
+//   - addr is the address of the HTTP socket of the worker.
+//   - kvAddrs are the addresses of the HTTP servers of KV namespaces, keyed by the binding names.
+//   - https://github.com/cloudflare/workerd/blob/main/src/workerd/server/workerd.capnp
+func workerdConfig(o *Options, addr string, modules []string, kvAddrs map[string]string) string {
+	var b strings.Builder
+	b.WriteString("using Workerd = import \"/workerd/workerd.capnp\";\n\n")
+	b.WriteString("const config :Workerd.Config = (\n  services = [\n")
+	b.WriteString("    (name = \"main\", worker = .worker),\n")
+	for _, name := range sortedKeys(kvAddrs) {
+		fmt.Fprintf(&b, "    (name = %s, external = (address = %s, http = ())),\n", strconv.Quote("kv:"+name), strconv.Quote(kvAddrs[name]))
+	}
+	b.WriteString("  ],\n")
+	fmt.Fprintf(&b, "  sockets = [(name = \"http\", address = %s, http = (), service = \"main\")],\n);\n\n", strconv.Quote(addr))
+
+	b.WriteString("const worker :Workerd.Worker = (\n  modules = [\n")
+	for _, name := range modules {
+		kind := "esModule"
+		if filepath.Ext(name) == ".wasm" {
+			kind = "wasm"
+		}
+		fmt.Fprintf(&b, "    (name = %s, %s = embed %s),\n", strconv.Quote(name), kind, strconv.Quote("build/"+name))
+	}
+	b.WriteString("  ],\n")
+	fmt.Fprintf(&b, "  compatibilityDate = %s,\n", strconv.Quote(o.CompatibilityDate))
+	b.WriteString("  compatibilityFlags = [")
+	for i, flag := range o.CompatibilityFlags {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(flag))
+	}
+	b.WriteString("],\n  bindings = [\n")
+	for _, name := range sortedKeys(o.Vars) {
+		fmt.Fprintf(&b, "    (name = %s, text = %s),\n", strconv.Quote(name), strconv.Quote(o.Vars[name]))
+	}
+	for _, name := range sortedKeys(kvAddrs) {
+		fmt.Fprintf(&b, "    (name = %s, kvNamespace = %s),\n", strconv.Quote(name), strconv.Quote("kv:"+name))
+	}
+	b.WriteString("  ],\n);\n")
+	return b.String()
+}
+
+// wranglerConfig returns the wrangler configuration in TOML.
+//   - IDs of the bindings are their names, since they are only used by the local simulators.
+func wranglerConfig(o *Options) string {
+	var b strings.Builder
+	b.WriteString("name = \"workerdtest\"\n")
+	fmt.Fprintf(&b, "main = %s\n", strconv.Quote("build/"+entryModule))
+	fmt.Fprintf(&b, "compatibility_date = %s\n", strconv.Quote(o.CompatibilityDate))
+	b.WriteString("compatibility_flags = [")
+	for i, flag := range o.CompatibilityFlags {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Quote(flag))
+	}
+	b.WriteString("]\n")
+	if len(o.Vars) > 0 {
+		b.WriteString("\n[vars]\n")
+		for _, name := range sortedKeys(o.Vars) {
+			fmt.Fprintf(&b, "%s = %s\n", strconv.Quote(name), strconv.Quote(o.Vars[name]))
+		}
+	}
+	for _, name := range o.KVNamespaces {
+		fmt.Fprintf(&b, "\n[[kv_namespaces]]\nbinding = %s\nid = %s\n", strconv.Quote(name), strconv.Quote(name))
+	}
+	for _, name := range o.R2Buckets {
+		fmt.Fprintf(&b, "\n[[r2_buckets]]\nbinding = %s\nbucket_name = %s\n", strconv.Quote(name), strconv.Quote(strings.ToLower(name)))
+	}
+	for _, name := range o.D1Databases {
+		fmt.Fprintf(&b, "\n[[d1_databases]]\nbinding = %s\ndatabase_name = %s\ndatabase_id = %s\n", strconv.Quote(name), strconv.Quote(name), strconv.Quote(name))
+	}
+	return b.String()
+}
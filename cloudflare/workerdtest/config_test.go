@@ -0,0 +1,51 @@
+package workerdtest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWorkerdConfig(t *testing.T) {
+	o := &Options{
+		CompatibilityDate:  "2024-01-01",
+		CompatibilityFlags: []string{"nodejs_compat"},
+		Vars:               map[string]string{"B": "2", "A": "1"},
+	}
+	got := workerdConfig(o, "127.0.0.1:8080", []string{"worker.mjs", "app.wasm"}, map[string]string{"KV": "127.0.0.1:9000"})
+	for _, want := range []string{
+		`(name = "kv:KV", external = (address = "127.0.0.1:9000", http = ())),`,
+		`sockets = [(name = "http", address = "127.0.0.1:8080", http = (), service = "main")],`,
+		`(name = "worker.mjs", esModule = embed "build/worker.mjs"),`,
+		`(name = "app.wasm", wasm = embed "build/app.wasm"),`,
+		`compatibilityDate = "2024-01-01",`,
+		`compatibilityFlags = ["nodejs_compat"],`,
+		"(name = \"A\", text = \"1\"),\n    (name = \"B\", text = \"2\"),",
+		`(name = "KV", kvNamespace = "kv:KV"),`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config doesn't contain %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestWranglerConfig(t *testing.T) {
+	o := &Options{
+		CompatibilityDate: "2024-01-01",
+		Vars:              map[string]string{"A": "1"},
+		KVNamespaces:      []string{"KV"},
+		R2Buckets:         []string{"BUCKET"},
+		D1Databases:       []string{"DB"},
+	}
+	got := wranglerConfig(o)
+	for _, want := range []string{
+		`main = "build/worker.mjs"`,
+		"[vars]\n\"A\" = \"1\"",
+		"[[kv_namespaces]]\nbinding = \"KV\"\nid = \"KV\"",
+		"[[r2_buckets]]\nbinding = \"BUCKET\"\nbucket_name = \"bucket\"",
+		"[[d1_databases]]\nbinding = \"DB\"",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config doesn't contain %s:\n%s", want, got)
+		}
+	}
+}
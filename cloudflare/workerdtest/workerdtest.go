@@ -0,0 +1,302 @@
+// Package workerdtest provides the harness to test workers built by this module on the local runtime.
+//   - The harness builds the wasm of the worker, generates the configuration, and launches workerd (or wrangler dev) as a subprocess.
+//     Go tests can make real HTTP requests to URL of the Worker.
+//   - The harness runs on the host (not js/wasm), and requires the go command (or tinygo) and workerd (or wrangler) in PATH.
+//   - In RuntimeWorkerd, KV namespaces are served by the in-memory fakes of the bindingtest package.
+//     In RuntimeWrangler, bindings are served by the local simulators of wrangler dev.
+//
+// Example:
+//
+//	func TestWorker(t *testing.T) {
+//		w := workerdtest.Run(t, &workerdtest.Options{
+//			Dir:          ".",
+//			Vars:         map[string]string{"GREETING": "hello"},
+//			KVNamespaces: []string{"COUNTER"},
+//		})
+//		res, err := http.Get(w.URL + "/")
+//		// ...
+//	}
+package workerdtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/binding/bindingtest"
+)
+
+// Runtime represents the local runtime which runs the worker.
+type Runtime string
+
+const (
+	// RuntimeWorkerd runs the worker by the workerd command.
+	RuntimeWorkerd Runtime = "workerd"
+	// RuntimeWrangler runs the worker by `wrangler dev`.
+	RuntimeWrangler Runtime = "wrangler"
+)
+
+// Mode represents the compiler of the worker.
+type Mode string
+
+const (
+	// ModeGo builds the worker by the go command.
+	ModeGo Mode = "go"
+	// ModeTinygo builds the worker by the tinygo command.
+	ModeTinygo Mode = "tinygo"
+)
+
+const (
+	defaultCompatibilityDate = "2024-09-23"
+	defaultStartTimeout      = time.Minute
+)
+
+var (
+	// ErrCommandNotFound is returned when a command required to build or run the worker is not found in PATH.
+	ErrCommandNotFound = errors.New("workerdtest: command not found")
+	// ErrUnsupportedBinding is returned when the binding is not supported by the runtime.
+	ErrUnsupportedBinding = errors.New("workerdtest: binding is not supported by the runtime")
+)
+
+// Options represents the options of Start.
+type Options struct {
+	// Dir is the directory of the main package of the worker. if Dir is empty, the current directory is used.
+	Dir string
+	// Mode is the compiler of the worker. if Mode is empty, ModeGo is used.
+	Mode Mode
+	// Runtime is the local runtime which runs the worker. if Runtime is empty, RuntimeWorkerd is used.
+	Runtime Runtime
+	// CompatibilityDate is the compatibility date of the worker. if CompatibilityDate is empty, 2024-09-23 is used.
+	CompatibilityDate string
+	// CompatibilityFlags are the compatibility flags of the worker.
+	CompatibilityFlags []string
+	// Vars are the environment variables of the worker.
+	Vars map[string]string
+	// KVNamespaces are the binding names of KV namespaces.
+	KVNamespaces []string
+	// R2Buckets are the binding names of R2 buckets. Only RuntimeWrangler supports R2 buckets.
+	R2Buckets []string
+	// D1Databases are the binding names of D1 databases. Only RuntimeWrangler supports D1 databases.
+	D1Databases []string
+	// Output receives the logs of the build and the runtime. if Output is nil, the logs are discarded.
+	Output io.Writer
+	// StartTimeout is the timeout to wait for the worker to get ready. if StartTimeout is 0, 1 minute is used.
+	StartTimeout time.Duration
+}
+
+// Worker represents the worker running on the local runtime.
+type Worker struct {
+	// URL is the base URL of the worker, e.g. `http://127.0.0.1:12345`.
+	URL string
+
+	dir  string
+	kvs  map[string]*bindingtest.KV
+	cmd  *exec.Cmd
+	done chan error
+	// closers are closed after the runtime exits.
+	closers []io.Closer
+}
+
+// Run starts the worker by Start, and stops it when the test finishes.
+//   - if a required command is not found, the test is skipped.
+//   - if the worker fails to start, the test fails.
+func Run(t testing.TB, opts *Options) *Worker {
+	t.Helper()
+	w, err := Start(context.Background(), opts)
+	if errors.Is(err, ErrCommandNotFound) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		w.Close()
+	})
+	return w
+}
+
+// Start builds the worker and launches the runtime, then waits for the worker to get ready.
+//   - The context is used only while building and starting the worker.
+//   - Close must be called to stop the runtime and remove the build directory.
+func Start(ctx context.Context, opts *Options) (*Worker, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	o := *opts
+	if o.Mode == "" {
+		o.Mode = ModeGo
+	}
+	if o.Runtime == "" {
+		o.Runtime = RuntimeWorkerd
+	}
+	if o.CompatibilityDate == "" {
+		o.CompatibilityDate = defaultCompatibilityDate
+	}
+	if o.Output == nil {
+		o.Output = io.Discard
+	}
+	if o.StartTimeout == 0 {
+		o.StartTimeout = defaultStartTimeout
+	}
+	if o.Runtime == RuntimeWorkerd && (len(o.R2Buckets) > 0 || len(o.D1Databases) > 0) {
+		return nil, fmt.Errorf("%w: R2 buckets and D1 databases require %s", ErrUnsupportedBinding, RuntimeWrangler)
+	}
+	if _, err := lookPath(string(o.Runtime)); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "workerdtest")
+	if err != nil {
+		return nil, err
+	}
+	w := &Worker{dir: dir, kvs: map[string]*bindingtest.KV{}, done: make(chan error, 1)}
+	if err := w.start(ctx, &o); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Worker) start(ctx context.Context, o *Options) error {
+	if err := build(ctx, o, w.dir); err != nil {
+		return err
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	w.URL = "http://" + l.Addr().String()
+	switch o.Runtime {
+	case RuntimeWorkerd:
+		err = w.startWorkerd(o, l)
+	case RuntimeWrangler:
+		// wrangler listens on the port by itself.
+		l.Close()
+		err = w.startWrangler(o, l.Addr().(*net.TCPAddr).Port)
+	default:
+		l.Close()
+		err = fmt.Errorf("workerdtest: unsupported runtime: %s", o.Runtime)
+	}
+	if err != nil {
+		return err
+	}
+	go func() {
+		w.done <- w.cmd.Wait()
+	}()
+	return w.waitReady(ctx, o.StartTimeout)
+}
+
+func (w *Worker) startWorkerd(o *Options, l net.Listener) error {
+	kvAddrs := map[string]string{}
+	for _, name := range o.KVNamespaces {
+		kv := bindingtest.NewKV()
+		kvListener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			l.Close()
+			return err
+		}
+		srv := &http.Server{Handler: kv}
+		go srv.Serve(kvListener)
+		w.closers = append(w.closers, srv)
+		w.kvs[name] = kv
+		kvAddrs[name] = kvListener.Addr().String()
+	}
+	modules, err := listModules(filepath.Join(w.dir, "build"))
+	if err != nil {
+		l.Close()
+		return err
+	}
+	configPath := filepath.Join(w.dir, "config.capnp")
+	if err := os.WriteFile(configPath, []byte(workerdConfig(o, l.Addr().String(), modules, kvAddrs)), 0o644); err != nil {
+		l.Close()
+		return err
+	}
+	// the listener is passed to workerd, so the port is not taken by others before workerd starts.
+	f, err := l.(*net.TCPListener).File()
+	l.Close()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w.cmd = exec.Command("workerd", "serve", configPath, "--socket-fd", "http=3")
+	w.cmd.ExtraFiles = []*os.File{f}
+	w.cmd.Stdout = o.Output
+	w.cmd.Stderr = o.Output
+	return w.cmd.Start()
+}
+
+func (w *Worker) startWrangler(o *Options, port int) error {
+	configPath := filepath.Join(w.dir, "wrangler.toml")
+	if err := os.WriteFile(configPath, []byte(wranglerConfig(o)), 0o644); err != nil {
+		return err
+	}
+	w.cmd = exec.Command("wrangler", "dev", "--config", configPath, "--ip", "127.0.0.1", "--port", strconv.Itoa(port),
+		"--persist-to", filepath.Join(w.dir, "state"))
+	w.cmd.Dir = w.dir
+	w.cmd.Stdout = o.Output
+	w.cmd.Stderr = o.Output
+	return w.cmd.Start()
+}
+
+// waitReady polls the worker until it responds.
+func (w *Worker) waitReady(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, w.URL, nil)
+		if err != nil {
+			return err
+		}
+		if res, err := http.DefaultClient.Do(req); err == nil {
+			res.Body.Close()
+			return nil
+		}
+		select {
+		case err := <-w.done:
+			// keep the result of Wait for Close.
+			w.done <- err
+			return fmt.Errorf("workerdtest: %s exited before getting ready: %v", filepath.Base(w.cmd.Path), err)
+		case <-ctx.Done():
+			return fmt.Errorf("workerdtest: worker didn't get ready: %w", ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// KV returns the in-memory KV namespace of the binding name, which can be used to set up and inspect the values.
+//   - if the binding is not found or the runtime is RuntimeWrangler, returns nil.
+func (w *Worker) KV(name string) *bindingtest.KV {
+	return w.kvs[name]
+}
+
+// Close stops the runtime and removes the build directory.
+func (w *Worker) Close() error {
+	if w.cmd != nil && w.cmd.Process != nil {
+		w.cmd.Process.Kill()
+		<-w.done
+		w.cmd = nil
+	}
+	for _, c := range w.closers {
+		c.Close()
+	}
+	w.closers = nil
+	return os.RemoveAll(w.dir)
+}
+
+// lookPath returns the path of the command, or ErrCommandNotFound.
+func lookPath(name string) (string, error) {
+	p, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCommandNotFound, name)
+	}
+	return p, nil
+}
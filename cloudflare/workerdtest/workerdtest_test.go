@@ -0,0 +1,33 @@
+package workerdtest
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	w := Run(t, &Options{
+		Dir:          "testdata/worker",
+		Vars:         map[string]string{"GREETING": "hello"},
+		KVNamespaces: []string{"COUNTER"},
+	})
+	if err := w.KV("COUNTER").PutString("count", "41", nil); err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Get(w.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, _ := io.ReadAll(res.Body)
+	if got, want := string(b), "hello 42"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+	if got, _ := w.KV("COUNTER").GetString("count", nil); got != "42" {
+		t.Errorf("count in KV = %q, want 42", got)
+	}
+}
@@ -0,0 +1,210 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/syumai/workers/cloudflare/internal/cfruntimecontext"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Binding represents the binding of a Workflow, which manages its instances.
+//   - https://developers.cloudflare.com/workflows/build/workers-api/#workflow
+type Binding struct {
+	instance js.Value
+}
+
+// NewBinding returns Binding for given variable name.
+//   - variable name must be defined in wrangler.toml as workflows's binding.
+//   - if the given variable name doesn't exist on runtime context, returns error.
+//   - This function panics when a runtime context is not found.
+func NewBinding(ctx context.Context, varName string) (*Binding, error) {
+	inst := cfruntimecontext.GetRuntimeContextEnv(ctx).Get(varName)
+	if inst.IsUndefined() {
+		return nil, fmt.Errorf("%s is undefined", varName)
+	}
+	return &Binding{instance: inst}, nil
+}
+
+// CreateOptions represents the options of Create.
+type CreateOptions struct {
+	// ID is the ID of the instance. if ID is empty, a random ID is generated.
+	ID string
+	// Params is the payload of the event passed to the instance. See `jsutil.ToJSValue` for the conversion rules.
+	Params any
+}
+
+func (opts *CreateOptions) toJS() (js.Value, error) {
+	if opts == nil {
+		return js.Undefined(), nil
+	}
+	obj := jsutil.NewObject()
+	if opts.ID != "" {
+		obj.Set("id", opts.ID)
+	}
+	if opts.Params != nil {
+		params, err := jsutil.ToJSValue(opts.Params)
+		if err != nil {
+			return js.Value{}, fmt.Errorf("workflows: error converting params: %w", err)
+		}
+		obj.Set("params", params)
+	}
+	return obj, nil
+}
+
+// Create creates a new instance of the Workflow.
+func (b *Binding) Create(opts *CreateOptions) (*Instance, error) {
+	optsObj, err := opts.toJS()
+	if err != nil {
+		return nil, err
+	}
+	return b.awaitInstance(func() js.Value {
+		return b.instance.Call("create", optsObj)
+	})
+}
+
+// CreateBatch creates new instances of the Workflow at once.
+func (b *Binding) CreateBatch(opts []*CreateOptions) ([]*Instance, error) {
+	arr := jsutil.ArrayClass().New(len(opts))
+	for i, o := range opts {
+		obj, err := o.toJS()
+		if err != nil {
+			return nil, fmt.Errorf("opts[%d]: %w", i, err)
+		}
+		arr.SetIndex(i, obj)
+	}
+	promise, err := jsutil.TryCall(b.instance, "createBatch", arr)
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]*Instance, v.Length())
+	for i := range instances {
+		instances[i] = &Instance{instance: v.Index(i)}
+	}
+	return instances, nil
+}
+
+// Get returns the existing instance of the ID.
+func (b *Binding) Get(id string) (*Instance, error) {
+	return b.awaitInstance(func() js.Value {
+		return b.instance.Call("get", id)
+	})
+}
+
+func (b *Binding) awaitInstance(call func() js.Value) (*Instance, error) {
+	var promise js.Value
+	if err := jsutil.Try(func() {
+		promise = call()
+	}); err != nil {
+		return nil, err
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	return &Instance{instance: v}, nil
+}
+
+// InstanceStatus represents the status of an instance.
+type InstanceStatus string
+
+const (
+	InstanceStatusQueued          InstanceStatus = "queued"
+	InstanceStatusRunning         InstanceStatus = "running"
+	InstanceStatusPaused          InstanceStatus = "paused"
+	InstanceStatusErrored         InstanceStatus = "errored"
+	InstanceStatusTerminated      InstanceStatus = "terminated"
+	InstanceStatusComplete        InstanceStatus = "complete"
+	InstanceStatusWaiting         InstanceStatus = "waiting"
+	InstanceStatusWaitingForPause InstanceStatus = "waitingForPause"
+	InstanceStatusUnknown         InstanceStatus = "unknown"
+)
+
+// InstanceDetails represents the status of an instance and its result.
+type InstanceDetails struct {
+	Status InstanceStatus
+	// Error is the error message of the instance when Status is InstanceStatusErrored.
+	Error string
+	// Output is the result of the Workflow converted to Go value when Status is InstanceStatusComplete.
+	Output any
+}
+
+// Instance represents an instance of the Workflow.
+//   - https://developers.cloudflare.com/workflows/build/workers-api/#workflowinstance
+type Instance struct {
+	instance js.Value
+}
+
+// ID returns the ID of the instance.
+func (i *Instance) ID() string {
+	return i.instance.Get("id").String()
+}
+
+// Status returns the status of the instance.
+func (i *Instance) Status() (*InstanceDetails, error) {
+	v, err := i.call("status")
+	if err != nil {
+		return nil, err
+	}
+	details := &InstanceDetails{
+		Status: InstanceStatus(v.Get("status").String()),
+		Output: jsutil.ToGoValue(v.Get("output")),
+	}
+	if errObj := v.Get("error"); errObj.Type() == js.TypeString {
+		details.Error = errObj.String()
+	} else if errObj.Type() == js.TypeObject {
+		details.Error = jsutil.MaybeString(errObj.Get("message"))
+	}
+	return details, nil
+}
+
+// Pause pauses the instance.
+func (i *Instance) Pause() error {
+	_, err := i.call("pause")
+	return err
+}
+
+// Resume resumes the paused instance.
+func (i *Instance) Resume() error {
+	_, err := i.call("resume")
+	return err
+}
+
+// Terminate terminates the instance. Terminated instances can't be resumed.
+func (i *Instance) Terminate() error {
+	_, err := i.call("terminate")
+	return err
+}
+
+// Restart restarts the instance from the beginning.
+func (i *Instance) Restart() error {
+	_, err := i.call("restart")
+	return err
+}
+
+// SendEvent sends the event to the instance waiting for the event of the type by `Step.WaitForEvent`.
+//   - payload is converted to JavaScript value by `jsutil.ToJSValue`.
+func (i *Instance) SendEvent(eventType string, payload any) error {
+	payloadObj, err := jsutil.ToJSValue(payload)
+	if err != nil {
+		return fmt.Errorf("workflows: error converting payload: %w", err)
+	}
+	event := jsutil.NewObject()
+	event.Set("type", eventType)
+	event.Set("payload", payloadObj)
+	_, err = i.call("sendEvent", event)
+	return err
+}
+
+func (i *Instance) call(method string, args ...any) (js.Value, error) {
+	promise, err := jsutil.TryCall(i.instance, method, args...)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.AwaitPromise(promise)
+}
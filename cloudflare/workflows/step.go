@@ -0,0 +1,224 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Step represents the steps of the workflow instance.
+//   - https://developers.cloudflare.com/workflows/build/workers-api/#workflowstep
+type Step struct {
+	instance               js.Value
+	nonRetryableErrorClass js.Value
+}
+
+// Backoff represents the backoff strategy of retries.
+type Backoff string
+
+const (
+	BackoffConstant    Backoff = "constant"
+	BackoffLinear      Backoff = "linear"
+	BackoffExponential Backoff = "exponential"
+)
+
+// RetryConfig represents the retries of a step.
+type RetryConfig struct {
+	// Limit is the maximum number of retries. if Limit is 0, the step is not retried.
+	Limit int
+	// Delay is the delay before the first retry.
+	Delay time.Duration
+	// Backoff is how the delay grows. The default value is BackoffExponential.
+	Backoff Backoff
+}
+
+// StepConfig represents the options of Do.
+//   - if StepConfig is nil, the default config of Workflows (5 retries with exponential backoff, 10 minutes timeout) is used.
+type StepConfig struct {
+	Retries *RetryConfig
+	// Timeout is the timeout of each attempt of the step.
+	Timeout time.Duration
+}
+
+func (cfg *StepConfig) toJS() js.Value {
+	obj := jsutil.NewObject()
+	if cfg.Retries != nil {
+		retries := jsutil.NewObject()
+		retries.Set("limit", cfg.Retries.Limit)
+		retries.Set("delay", cfg.Retries.Delay.Milliseconds())
+		if cfg.Retries.Backoff != "" {
+			retries.Set("backoff", string(cfg.Retries.Backoff))
+		}
+		obj.Set("retries", retries)
+	}
+	if cfg.Timeout > 0 {
+		obj.Set("timeout", cfg.Timeout.Milliseconds())
+	}
+	return obj
+}
+
+// NonRetryableError represents the error which fails the step without retries.
+type NonRetryableError struct {
+	Err error
+}
+
+func (e *NonRetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NonRetryableError) Unwrap() error {
+	return e.Err
+}
+
+// NonRetryable wraps err to fail the step without retries.
+func NonRetryable(err error) error {
+	return &NonRetryableError{Err: err}
+}
+
+// StepFunc runs a step and returns its result.
+//   - The result is persisted by the structured clone rules. See `jsutil.ToJSValue` for the conversion rules.
+//   - if StepFunc returns an error, the step is retried by RetryConfig. Wrap the error by NonRetryable to stop retries.
+type StepFunc func(ctx context.Context) (any, error)
+
+// Do runs fn as the step of the name, and returns the result converted to Go value.
+//   - The name must be unique in the workflow, since the persisted result is looked up by the name.
+//   - if the step has completed before, fn is not called and the persisted result is returned.
+func (s *Step) Do(ctx context.Context, name string, cfg *StepConfig, fn StepFunc) (any, error) {
+	v, err := s.do(ctx, name, cfg, fn)
+	if err != nil {
+		return nil, err
+	}
+	return jsutil.ToGoValue(v), nil
+}
+
+// DoInto runs fn as the step of the name like Do, and decodes the result into out by `jsutil.Decode`.
+//   - This is useful to restore structs from persisted results.
+func (s *Step) DoInto(ctx context.Context, name string, cfg *StepConfig, fn StepFunc, out any) error {
+	v, err := s.do(ctx, name, cfg, fn)
+	if err != nil {
+		return err
+	}
+	return unmarshalJS(v, out)
+}
+
+func (s *Step) do(ctx context.Context, name string, cfg *StepConfig, fn StepFunc) (js.Value, error) {
+	// the callback can be called multiple times by retries, so it is released after the step is settled.
+	cb := js.FuncOf(func(_ js.Value, _ []js.Value) any {
+		return s.runAsPromise(func() (js.Value, error) {
+			result, err := fn(ctx)
+			if err != nil {
+				return js.Value{}, err
+			}
+			return jsutil.ToJSValue(result)
+		})
+	})
+	defer cb.Release()
+	var promise js.Value
+	if err := jsutil.Try(func() {
+		if cfg == nil {
+			promise = s.instance.Call("do", name, cb)
+			return
+		}
+		promise = s.instance.Call("do", name, cfg.toJS(), cb)
+	}); err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.AwaitPromise(promise)
+}
+
+// runAsPromise is `jsutil.RunAsPromise` which rejects with NonRetryableError of Workflows for NonRetryableError.
+func (s *Step) runAsPromise(fn func() (js.Value, error)) js.Value {
+	promise, resolve, reject := jsutil.NewPromiseWithResolvers()
+	go func() {
+		v, err := fn()
+		if err == nil {
+			resolve.Invoke(v)
+			return
+		}
+		var nonRetryable *NonRetryableError
+		if errors.As(err, &nonRetryable) && !s.nonRetryableErrorClass.IsUndefined() {
+			reject.Invoke(s.nonRetryableErrorClass.New(err.Error()))
+			return
+		}
+		reject.Invoke(jsutil.ErrorClass().New(err.Error()))
+	}()
+	return promise
+}
+
+// Sleep pauses the instance for the duration. The instance doesn't consume resources while sleeping.
+func (s *Step) Sleep(name string, d time.Duration) error {
+	return s.await(func() js.Value {
+		return s.instance.Call("sleep", name, d.Milliseconds())
+	})
+}
+
+// SleepUntil pauses the instance until the time.
+func (s *Step) SleepUntil(name string, t time.Time) error {
+	return s.await(func() js.Value {
+		return s.instance.Call("sleepUntil", name, jsutil.TimeToDate(t))
+	})
+}
+
+func (s *Step) await(call func() js.Value) error {
+	var promise js.Value
+	if err := jsutil.Try(func() {
+		promise = call()
+	}); err != nil {
+		return err
+	}
+	_, err := jsutil.AwaitPromise(promise)
+	return err
+}
+
+// WaitForEventOptions represents the options of WaitForEvent.
+type WaitForEventOptions struct {
+	// Type is the type of the event to wait for.
+	Type string
+	// Timeout is the maximum duration to wait. The default value is 24 hours.
+	Timeout time.Duration
+}
+
+// ReceivedEvent represents the event sent to the instance.
+type ReceivedEvent struct {
+	payload js.Value
+	Type    string
+	// Payload is the payload of the event converted to Go value.
+	Payload   any
+	Timestamp time.Time
+}
+
+// Unmarshal decodes the payload into v by `jsutil.Decode`.
+func (e *ReceivedEvent) Unmarshal(v any) error {
+	return unmarshalJS(e.payload, v)
+}
+
+// WaitForEvent pauses the instance until the event of the type is sent to the instance.
+//   - if no event is sent within the timeout, returns an error.
+func (s *Step) WaitForEvent(name string, opts *WaitForEventOptions) (*ReceivedEvent, error) {
+	optsObj := jsutil.NewObject()
+	optsObj.Set("type", opts.Type)
+	if opts.Timeout > 0 {
+		optsObj.Set("timeout", opts.Timeout.Milliseconds())
+	}
+	promise, err := jsutil.TryCall(s.instance, "waitForEvent", name, optsObj)
+	if err != nil {
+		return nil, err
+	}
+	v, err := jsutil.AwaitPromise(promise)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := jsutil.MaybeDate(v.Get("timestamp"))
+	if err != nil {
+		return nil, err
+	}
+	return &ReceivedEvent{
+		payload:   v.Get("payload"),
+		Type:      jsutil.MaybeString(v.Get("type")),
+		Payload:   jsutil.ToGoValue(v.Get("payload")),
+		Timestamp: timestamp,
+	}, nil
+}
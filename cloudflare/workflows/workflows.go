@@ -0,0 +1,100 @@
+// Package workflows provides the way to implement Cloudflare Workflows in Go.
+//   - https://developers.cloudflare.com/workflows/
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/internal/jsutil"
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// Event represents the event which triggered the workflow instance.
+type Event struct {
+	payload js.Value
+	// Payload is the params of the instance converted to Go value. See `jsutil.ToGoValue` for the conversion rules.
+	Payload any
+	// Timestamp is the time when the instance was created.
+	Timestamp time.Time
+	// InstanceID is the ID of the instance.
+	InstanceID string
+}
+
+func newEvent(obj js.Value) (*Event, error) {
+	timestamp, err := jsutil.MaybeDate(obj.Get("timestamp"))
+	if err != nil {
+		return nil, fmt.Errorf("error converting timestamp: %w", err)
+	}
+	return &Event{
+		payload:    obj.Get("payload"),
+		Payload:    jsutil.ToGoValue(obj.Get("payload")),
+		Timestamp:  timestamp,
+		InstanceID: jsutil.MaybeString(obj.Get("instanceId")),
+	}, nil
+}
+
+// Unmarshal decodes the payload into v by `jsutil.Decode`. field names of structs can be changed by `json` tag.
+func (e *Event) Unmarshal(v any) error {
+	return unmarshalJS(e.payload, v)
+}
+
+// unmarshalJS decodes JavaScript value into v.
+func unmarshalJS(value js.Value, v any) error {
+	if err := jsutil.Decode(value, v); err != nil {
+		return fmt.Errorf("workflows: error decoding value: %w", err)
+	}
+	return nil
+}
+
+// Workflow runs the steps of a workflow instance.
+//   - Workflow is called again from the beginning when the instance is resumed (e.g. after sleeps or failures),
+//     so any side effects must be done in steps. Completed steps return the persisted result without running again.
+//   - The result is persisted as the output of the instance.
+//   - ctx holds the environment of the worker, so bindings can be accessed by functions in the cloudflare package.
+type Workflow func(ctx context.Context, event *Event, step *Step) (any, error)
+
+var workflows = map[string]Workflow{}
+
+// Register registers the Workflow with the class name.
+//   - The class name must also be given to workers-assets-gen (e.g. `-workflow MyWorkflow`), so the class is exported.
+//     The class name is referred by class_name of workflows in wrangler.toml.
+//   - Register must be called before `workers.Serve` (or other functions which start the worker).
+func Register(className string, wf Workflow) {
+	workflows[className] = wf
+	registerRunWorkflow()
+}
+
+func runWorkflow(className string, eventObj, stepObj, runtimeCtxObj js.Value) (js.Value, error) {
+	wf, ok := workflows[className]
+	if !ok {
+		return js.Value{}, fmt.Errorf("workflows: %s is not registered", className)
+	}
+	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
+	event, err := newEvent(eventObj)
+	if err != nil {
+		return js.Value{}, err
+	}
+	step := &Step{
+		instance:               stepObj,
+		nonRetryableErrorClass: runtimeCtxObj.Get("NonRetryableError"),
+	}
+	result, err := wf(ctx, event, step)
+	if err != nil {
+		return js.Value{}, err
+	}
+	return jsutil.ToJSValue(result)
+}
+
+// registerRunWorkflow sets runWorkflow called by the JavaScript side.
+var registerRunWorkflow = jsutil.LazyRegister("runWorkflow", func(_ js.Value, args []js.Value) any {
+	if len(args) != 4 {
+		panic(fmt.Errorf("invalid number of arguments given to runWorkflow: %d", len(args)))
+	}
+	className, eventObj, stepObj, runtimeCtxObj := args[0].String(), args[1], args[2], args[3]
+	return jsutil.RunAsPromise(func() (js.Value, error) {
+		return runWorkflow(className, eventObj, stepObj, runtimeCtxObj)
+	})
+})
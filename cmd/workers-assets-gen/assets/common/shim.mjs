@@ -1,11 +1,52 @@
 import "./polyfill_performance.js";
 import "./wasm_exec.js";
 import { connect } from 'cloudflare:sockets';
+// classes of cloudflare:workers are read from the namespace when they are used,
+// so workers which don't export Go classes run on runtimes without some of them.
+import * as workers from 'cloudflare:workers';
 
 const go = new Go();
 
+// throwOnError wraps a synchronous callback implemented in Go.
+// The Error returned from the callback is thrown, since Go callbacks can't throw errors.
+globalThis.throwOnError = (fn) => (...args) => {
+  const result = fn(...args);
+  if (result instanceof Error) {
+    throw result;
+  }
+  return result;
+};
+
+// tryCall calls fn with thisArg and args, and returns the result or the exception thrown.
+// TinyGo can't recover from panics caused by JavaScript exceptions, so they are caught here instead.
+globalThis.tryCall = (fn, thisArg, args) => {
+  try {
+    return { ok: true, value: Reflect.apply(fn, thisArg, args) };
+  } catch (e) {
+    return { ok: false, error: e };
+  }
+};
+
 let mod;
 
+// optionalModules holds values of modules which are not available on all runtimes.
+// They are imported dynamically, so the missing modules don't fail loading the worker.
+let optionalModules;
+
+async function loadOptionalModules() {
+  if (!optionalModules) {
+    const [email, workflows] = await Promise.all([
+      import('cloudflare:email').catch(() => ({})),
+      import('cloudflare:workflows').catch(() => ({})),
+    ]);
+    optionalModules = {
+      EmailMessage: email.EmailMessage,
+      NonRetryableError: workflows.NonRetryableError,
+    };
+  }
+  return optionalModules;
+}
+
 export function init(m) {
   mod = m;
 }
@@ -16,7 +57,7 @@ async function run() {
   });
   const instance = new WebAssembly.Instance(mod, go.importObject);
   go.run(instance);
-  await readyPromise;
+  await Promise.all([readyPromise, loadOptionalModules()]);
 }
 
 function createRuntimeContext(env, ctx) {
@@ -24,6 +65,7 @@ function createRuntimeContext(env, ctx) {
     env,
     ctx,
     connect,
+    ...optionalModules,
   }
 }
 
@@ -37,9 +79,104 @@ export async function scheduled(event, env, ctx) {
   return runScheduler(event, createRuntimeContext(env, ctx));
 }
 
+export async function queue(batch, env, ctx) {
+  await run();
+  return handleQueue(batch, createRuntimeContext(env, ctx));
+}
+
+export async function email(message, env, ctx) {
+  await run();
+  return handleEmail(message, createRuntimeContext(env, ctx));
+}
+
 // onRequest handles request to Cloudflare Pages
 export async function onRequest(ctx) {
   await run();
   const { request, env } = ctx;
   return handleRequest(request, createRuntimeContext(env, ctx));
-}
\ No newline at end of file
+}
+
+// createDurableObjectClass creates a Durable Object class implemented in Go.
+// The class must be registered in Go by `durableobject.Register` with the same className.
+// rpcMethods are defined as methods of the class, so they can be called by Workers RPC.
+export function createDurableObjectClass(className, rpcMethods = []) {
+  const cls = class extends workers.DurableObject {
+    constructor(state, env) {
+      super(state, env);
+      this.state = state;
+      this.env = env;
+    }
+
+    // instance lazily creates the Go side's instance of the Durable Object.
+    instance() {
+      if (!this.instancePromise) {
+        this.instancePromise = run().then(() =>
+          newDurableObject(className, this.state, createRuntimeContext(this.env, this.state))
+        );
+      }
+      return this.instancePromise;
+    }
+
+    async fetch(req) {
+      const instance = await this.instance();
+      return instance.fetch(req);
+    }
+
+    async alarm(alarmInfo) {
+      const instance = await this.instance();
+      return instance.alarm(alarmInfo);
+    }
+
+    async webSocketMessage(ws, message) {
+      const instance = await this.instance();
+      return instance.webSocketMessage(ws, message);
+    }
+
+    async webSocketClose(ws, code, reason, wasClean) {
+      const instance = await this.instance();
+      return instance.webSocketClose(ws, code, reason, wasClean);
+    }
+
+    async webSocketError(ws, error) {
+      const instance = await this.instance();
+      return instance.webSocketError(ws, error);
+    }
+  };
+  for (const method of rpcMethods) {
+    cls.prototype[method] = async function (...args) {
+      const instance = await this.instance();
+      return instance.call(method, args);
+    };
+  }
+  return cls;
+}
+
+// createWorkerEntrypointClass creates a named entrypoint class implemented in Go.
+// The entrypoint must be registered in Go by `entrypoint.Register` with the same name.
+// rpcMethods are defined as methods of the class, so they can be called by service bindings.
+export function createWorkerEntrypointClass(name, rpcMethods = []) {
+  const cls = class extends workers.WorkerEntrypoint {
+    async fetch(req) {
+      await run();
+      return handleEntrypointRequest(name, req, createRuntimeContext(this.env, this.ctx));
+    }
+  };
+  for (const method of rpcMethods) {
+    cls.prototype[method] = async function (...args) {
+      await run();
+      return callEntrypoint(name, method, args, createRuntimeContext(this.env, this.ctx));
+    };
+  }
+  return cls;
+}
+
+// createWorkflowClass creates a Workflow class implemented in Go.
+// The workflow must be registered in Go by `workflows.Register` with the same className.
+export function createWorkflowClass(className) {
+  return class extends workers.WorkflowEntrypoint {
+    async run(event, step) {
+      await run();
+      return runWorkflow(className, event, step, createRuntimeContext(this.env, this.ctx));
+    }
+  };
+}
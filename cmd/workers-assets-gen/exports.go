@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// stringsFlag is a flag.Value which can be specified multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// classExport represents a class implemented in Go and exported from worker.mjs.
+type classExport struct {
+	name string
+	// rpcMethods are names of RPC methods defined on the class.
+	rpcMethods []string
+}
+
+// reservedMethodNames are names of methods which are already defined on the generated classes.
+var reservedMethodNames = map[string]bool{
+	"constructor":      true,
+	"instance":         true,
+	"fetch":            true,
+	"alarm":            true,
+	"webSocketMessage": true,
+	"webSocketClose":   true,
+	"webSocketError":   true,
+}
+
+// parseClassExport parses a class spec of the form `ClassName` or `ClassName:method1,method2`.
+func parseClassExport(spec string) (*classExport, error) {
+	name, methods, hasMethods := strings.Cut(spec, ":")
+	if !isValidClassName(name) {
+		return nil, fmt.Errorf("invalid class name: %q", name)
+	}
+	class := &classExport{name: name}
+	if !hasMethods {
+		return class, nil
+	}
+	for _, method := range strings.Split(methods, ",") {
+		if !isValidClassName(method) || reservedMethodNames[method] {
+			return nil, fmt.Errorf("invalid method name of %s: %q", name, method)
+		}
+		class.rpcMethods = append(class.rpcMethods, method)
+	}
+	return class, nil
+}
+
+// isValidClassName reports whether the name can be used as a JavaScript class name.
+func isValidClassName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || c == '$':
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// appendWorkerExports appends exports of classes implemented in Go to worker.mjs.
+func appendWorkerExports(cfg *config) error {
+	if len(cfg.durableObjects) == 0 && len(cfg.entrypoints) == 0 && len(cfg.workflows) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(path.Join(buildDirPath, "worker.mjs"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var b strings.Builder
+	if len(cfg.durableObjects) > 0 {
+		b.WriteString("\n\n// Durable Objects\n")
+		writeClassExports(&b, "createDurableObjectClass", cfg.durableObjects)
+	}
+	if len(cfg.entrypoints) > 0 {
+		b.WriteString("\n\n// Entrypoints\n")
+		writeClassExports(&b, "createWorkerEntrypointClass", cfg.entrypoints)
+	}
+	if len(cfg.workflows) > 0 {
+		b.WriteString("\n\n// Workflows\n")
+		writeClassExports(&b, "createWorkflowClass", cfg.workflows)
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeClassExports writes exports of the classes created by the factory function of shim.mjs.
+func writeClassExports(b *strings.Builder, factory string, classes []*classExport) {
+	for _, class := range classes {
+		if len(class.rpcMethods) == 0 {
+			fmt.Fprintf(b, "export const %s = imports.%s(%q);\n", class.name, factory, class.name)
+			continue
+		}
+		methods := make([]string, len(class.rpcMethods))
+		for i, m := range class.rpcMethods {
+			methods[i] = strconv.Quote(m)
+		}
+		fmt.Fprintf(b, "export const %s = imports.%s(%q, [%s]);\n", class.name, factory, class.name, strings.Join(methods, ", "))
+	}
+}
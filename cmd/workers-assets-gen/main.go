@@ -20,33 +20,78 @@ const (
 )
 
 func main() {
-	var mode string
+	var (
+		mode           string
+		durableObjects stringsFlag
+		entrypoints    stringsFlag
+		workflows      stringsFlag
+	)
 	flag.StringVar(&mode, "mode", string(ModeTinygo), `build mode: tinygo or go`)
+	flag.Var(&durableObjects, "durable-object", `class name of Durable Object implemented in Go, optionally followed by RPC method names (e.g. Counter:increment,get). can be specified multiple times`)
+	flag.Var(&entrypoints, "entrypoint", `name of WorkerEntrypoint implemented in Go, optionally followed by RPC method names (e.g. Admin:getUser). can be specified multiple times`)
+	flag.Var(&workflows, "workflow", `class name of Workflow implemented in Go. can be specified multiple times`)
 	flag.Parse()
 	if !Mode(mode).IsValid() {
 		flag.PrintDefaults()
 		os.Exit(1)
 		return
 	}
-	if err := runMain(Mode(mode)); err != nil {
+	cfg := &config{
+		mode: Mode(mode),
+	}
+	for _, spec := range durableObjects {
+		class, err := parseClassExport(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "err: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.durableObjects = append(cfg.durableObjects, class)
+	}
+	for _, spec := range entrypoints {
+		class, err := parseClassExport(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "err: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.entrypoints = append(cfg.entrypoints, class)
+	}
+	for _, name := range workflows {
+		if !isValidClassName(name) {
+			fmt.Fprintf(os.Stderr, "err: invalid class name: %q\n", name)
+			os.Exit(1)
+		}
+		cfg.workflows = append(cfg.workflows, &classExport{name: name})
+	}
+	if err := runMain(cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "err: %v", err)
 		os.Exit(1)
 	}
 }
 
-func runMain(mode Mode) error {
+// config represents the configuration of generated assets.
+type config struct {
+	mode           Mode
+	durableObjects []*classExport
+	entrypoints    []*classExport
+	workflows      []*classExport
+}
+
+func runMain(cfg *config) error {
 	if err := os.RemoveAll(buildDirPath); err != nil {
 		return err
 	}
 	if err := os.MkdirAll(buildDirPath, os.ModePerm); err != nil {
 		return err
 	}
-	if err := copyWasmExecJS(mode); err != nil {
+	if err := copyWasmExecJS(cfg.mode); err != nil {
 		return err
 	}
 	if err := copyCommonAssets(); err != nil {
 		return err
 	}
+	if err := appendWorkerExports(cfg); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultConfigPaths are paths of the wrangler configuration searched in order.
+var defaultConfigPaths = []string{"wrangler.toml", "wrangler.jsonc", "wrangler.json"}
+
+// binding represents an entry of binding lists in the wrangler configuration.
+type binding struct {
+	Binding string `json:"binding"`
+	// Name is used by the bindings of Durable Objects instead of Binding.
+	Name string `json:"name"`
+}
+
+func (b *binding) name() string {
+	if b.Binding != "" {
+		return b.Binding
+	}
+	return b.Name
+}
+
+// wranglerConfig represents the bindings in the wrangler configuration.
+//   - https://developers.cloudflare.com/workers/wrangler/configuration/#bindings
+type wranglerConfig struct {
+	Vars         map[string]any `json:"vars"`
+	KVNamespaces []*binding     `json:"kv_namespaces"`
+	R2Buckets    []*binding     `json:"r2_buckets"`
+	D1Databases  []*binding     `json:"d1_databases"`
+	Queues       struct {
+		Producers []*binding `json:"producers"`
+	} `json:"queues"`
+	Services       []*binding `json:"services"`
+	DurableObjects struct {
+		Bindings []*binding `json:"bindings"`
+	} `json:"durable_objects"`
+	Hyperdrive []*binding `json:"hyperdrive"`
+	Vectorize  []*binding `json:"vectorize"`
+	AI         *binding   `json:"ai"`
+	// Env is the configuration of environments. Bindings are not inherited from the top level.
+	Env map[string]json.RawMessage `json:"env"`
+}
+
+// findConfigPath returns the first existing path of defaultConfigPaths.
+func findConfigPath() (string, error) {
+	for _, p := range defaultConfigPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("wrangler configuration is not found: %s", strings.Join(defaultConfigPaths, ", "))
+}
+
+// loadConfig loads the wrangler configuration from path.
+//   - if envName is not empty, the configuration of the environment is returned.
+func loadConfig(path, envName string) (*wranglerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(data, filepath.Ext(path) == ".toml", envName)
+}
+
+// parseConfig parses the wrangler configuration written in TOML or JSONC.
+func parseConfig(data []byte, isTOML bool, envName string) (*wranglerConfig, error) {
+	if isTOML {
+		m, err := parseTOML(data)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(m); err != nil {
+			return nil, err
+		}
+	} else {
+		data = stripJSONC(data)
+	}
+	var cfg wranglerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error decoding wrangler configuration: %w", err)
+	}
+	if envName == "" {
+		return &cfg, nil
+	}
+	envData, ok := cfg.Env[envName]
+	if !ok {
+		return nil, fmt.Errorf("environment %s is not defined", envName)
+	}
+	var envCfg wranglerConfig
+	if err := json.Unmarshal(envData, &envCfg); err != nil {
+		return nil, fmt.Errorf("error decoding environment %s: %w", envName, err)
+	}
+	return &envCfg, nil
+}
+
+// stripJSONC removes comments and trailing commas from JSONC, so it can be decoded as JSON.
+func stripJSONC(data []byte) []byte {
+	return removeTrailingCommas(removeComments(data))
+}
+
+// skipString returns the index of the closing quote of the string starting at i.
+func skipString(data []byte, i int) int {
+	for i++; i < len(data) && data[i] != '"'; i++ {
+		if data[i] == '\\' {
+			i++
+		}
+	}
+	if i >= len(data) {
+		return len(data) - 1
+	}
+	return i
+}
+
+func removeComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			end := skipString(data, i)
+			out = append(out, data[i:end+1]...)
+			i = end
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/') {
+				i++
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func removeTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '"':
+			end := skipString(data, i)
+			out = append(out, data[i:end+1]...)
+			i = end
+		case ',':
+			// drop the comma if the next significant character closes the object or array.
+			j := i + 1
+			for j < len(data) && strings.IndexByte(" \t\r\n", data[j]) >= 0 {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
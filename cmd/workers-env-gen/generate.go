@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// accessorKind represents the kind of bindings and the code of their accessors.
+type accessorKind struct {
+	// label describes the binding in doc comments.
+	label string
+	// imports are import paths used by the accessor.
+	imports []string
+	// returnType is the return type of the accessor.
+	returnType string
+	// body is the body of the accessor. %[1]q is replaced by the name of the binding,
+	// and %[2]s by the name of the field if fieldType is set.
+	body string
+	// note is the additional line of the doc comment of the accessor.
+	note string
+	// fieldType is the type of the field of Env which holds the value of the accessor, e.g. to open the value once.
+	fieldType string
+	// decl is the declaration used by fields of fieldType, which is written once.
+	decl string
+}
+
+var (
+	kindVar = &accessorKind{
+		label:      "the environment variable",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "string",
+		body:       "return cloudflare.Getenv(e.ctx, %q)",
+	}
+	kindSecret = &accessorKind{
+		label:      "the secret",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "string",
+		body:       "return cloudflare.Getenv(e.ctx, %q)",
+	}
+	kindKV = &accessorKind{
+		label:      "the KV namespace bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "(*cloudflare.KVNamespace, error)",
+		body:       "return cloudflare.NewKVNamespace(e.ctx, %q)",
+	}
+	kindR2 = &accessorKind{
+		label:      "the R2 bucket bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "(*cloudflare.R2Bucket, error)",
+		body:       "return cloudflare.NewR2Bucket(e.ctx, %q)",
+	}
+	kindD1 = &accessorKind{
+		label:      "*sql.DB of the D1 database bound to",
+		imports:    []string{"database/sql", "sync", "github.com/syumai/workers/cloudflare/d1"},
+		returnType: "(*sql.DB, error)",
+		body: `e.%[2]s.once.Do(func() {
+	c, err := d1.OpenConnector(e.ctx, %[1]q)
+	if err != nil {
+		e.%[2]s.err = err
+		return
+	}
+	e.%[2]s.db = sql.OpenDB(c)
+})
+return e.%[2]s.db, e.%[2]s.err`,
+		note:      "The *sql.DB is opened once per Env, so it can be called for each query.",
+		fieldType: "d1Database",
+		decl: `// d1Database holds *sql.DB of the D1 database, which is opened when it's used first.
+type d1Database struct {
+	once sync.Once
+	db   *sql.DB
+	err  error
+}`,
+	}
+	kindQueue = &accessorKind{
+		label:      "the producer of the queue bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare/queues"},
+		returnType: "(*queues.Producer, error)",
+		body:       "return queues.NewProducer(e.ctx, %q)",
+	}
+	kindService = &accessorKind{
+		label:      "the service bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "(*cloudflare.Service, error)",
+		body:       "return cloudflare.NewService(e.ctx, %q)",
+	}
+	kindDurableObject = &accessorKind{
+		label:      "the Durable Object namespace bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "(*cloudflare.DurableObjectNamespace, error)",
+		body:       "return cloudflare.NewDurableObjectNamespace(e.ctx, %q)",
+	}
+	kindHyperdrive = &accessorKind{
+		label:      "the Hyperdrive configuration bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare"},
+		returnType: "(*cloudflare.Hyperdrive, error)",
+		body:       "return cloudflare.NewHyperdrive(e.ctx, %q)",
+	}
+	kindVectorize = &accessorKind{
+		label:      "the Vectorize index bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare/vectorize"},
+		returnType: "(*vectorize.Index, error)",
+		body:       "return vectorize.NewIndex(e.ctx, %q)",
+	}
+	kindAI = &accessorKind{
+		label:      "Workers AI bound to",
+		imports:    []string{"github.com/syumai/workers/cloudflare/ai"},
+		returnType: "(*ai.AI, error)",
+		body:       "return ai.NewAI(e.ctx, %q)",
+	}
+)
+
+// accessor represents a method of the generated Env.
+type accessor struct {
+	kind    *accessorKind
+	binding string
+	method  string
+}
+
+// field returns the name of the field of Env used by the accessor.
+func (a *accessor) field() string {
+	return strings.ToLower(a.kind.fieldType[:1]) + a.kind.fieldType[1:] + a.method
+}
+
+// collectAccessors returns accessors of bindings in the configuration and the secrets.
+//   - Non-string vars are skipped, and reported as warnings.
+func collectAccessors(cfg *wranglerConfig, secrets []string) (accessors []*accessor, warnings []string) {
+	add := func(kind *accessorKind, name string) {
+		if name != "" {
+			accessors = append(accessors, &accessor{kind: kind, binding: name})
+		}
+	}
+	varNames := make([]string, 0, len(cfg.Vars))
+	for name := range cfg.Vars {
+		varNames = append(varNames, name)
+	}
+	sort.Strings(varNames)
+	for _, name := range varNames {
+		if _, ok := cfg.Vars[name].(string); !ok {
+			warnings = append(warnings, fmt.Sprintf("var %s is skipped since it is not a string", name))
+			continue
+		}
+		add(kindVar, name)
+	}
+	for _, name := range secrets {
+		add(kindSecret, name)
+	}
+	addBindings := func(kind *accessorKind, bindings []*binding) {
+		for _, b := range bindings {
+			add(kind, b.name())
+		}
+	}
+	addBindings(kindKV, cfg.KVNamespaces)
+	addBindings(kindR2, cfg.R2Buckets)
+	addBindings(kindD1, cfg.D1Databases)
+	addBindings(kindQueue, cfg.Queues.Producers)
+	addBindings(kindService, cfg.Services)
+	addBindings(kindDurableObject, cfg.DurableObjects.Bindings)
+	addBindings(kindHyperdrive, cfg.Hyperdrive)
+	addBindings(kindVectorize, cfg.Vectorize)
+	if cfg.AI != nil {
+		add(kindAI, cfg.AI.name())
+	}
+	return accessors, warnings
+}
+
+// generate generates the source of the typed Env.
+func generate(pkg, configPath string, accessors []*accessor) ([]byte, error) {
+	methods := map[string]string{}
+	importSet := map[string]bool{"context": true}
+	for _, a := range accessors {
+		a.method = methodName(a.binding)
+		if a.method == "" {
+			return nil, fmt.Errorf("binding name %q can't be converted to a method name", a.binding)
+		}
+		if other, ok := methods[a.method]; ok {
+			return nil, fmt.Errorf("bindings %s and %s have the same method name %s", other, a.binding, a.method)
+		}
+		methods[a.method] = a.binding
+		for _, imp := range a.kind.imports {
+			importSet[imp] = true
+		}
+	}
+	imports := make([]string, 0, len(importSet))
+	for imp := range importSet {
+		imports = append(imports, imp)
+	}
+	// standard packages are sorted before others.
+	sort.Slice(imports, func(i, j int) bool {
+		if std1, std2 := !strings.Contains(imports[i], "."), !strings.Contains(imports[j], "."); std1 != std2 {
+			return std1
+		}
+		return imports[i] < imports[j]
+	})
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by workers-env-gen from %s; DO NOT EDIT.\n\n", configPath)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	for i, imp := range imports {
+		// standard packages are separated from others.
+		if i > 0 && !strings.Contains(imports[i-1], ".") && strings.Contains(imp, ".") {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "\t%q\n", imp)
+	}
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, "// Env provides typed accessors of the bindings defined in %s.\n", configPath)
+	b.WriteString("type Env struct {\n\tctx context.Context\n")
+	decls := map[string]bool{}
+	for _, a := range accessors {
+		if a.kind.fieldType != "" {
+			fmt.Fprintf(&b, "\t%s %s\n", a.field(), a.kind.fieldType)
+		}
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("// NewEnv returns Env for the context of the request.\n")
+	b.WriteString("func NewEnv(ctx context.Context) *Env {\n\treturn &Env{ctx: ctx}\n}\n")
+	for _, a := range accessors {
+		fmt.Fprintf(&b, "\n// %s returns %s %s.\n", a.method, a.kind.label, a.binding)
+		if a.kind.note != "" {
+			fmt.Fprintf(&b, "//   - %s\n", a.kind.note)
+		}
+		fmt.Fprintf(&b, "func (e *Env) %s() %s {\n", a.method, a.kind.returnType)
+		if a.kind.fieldType != "" {
+			fmt.Fprintf(&b, a.kind.body, a.binding, a.field())
+		} else {
+			fmt.Fprintf(&b, a.kind.body, a.binding)
+		}
+		b.WriteString("\n}\n")
+	}
+	for _, a := range accessors {
+		if a.kind.decl != "" && !decls[a.kind.decl] {
+			decls[a.kind.decl] = true
+			fmt.Fprintf(&b, "\n%s\n", a.kind.decl)
+		}
+	}
+	return format.Source(b.Bytes())
+}
+
+// initialisms are words written in upper case in method names.
+var initialisms = map[string]bool{
+	"AI": true, "API": true, "D1": true, "DB": true, "DO": true, "HTML": true, "HTTP": true, "ID": true,
+	"JSON": true, "JWT": true, "KV": true, "R2": true, "SQL": true, "URL": true, "UUID": true,
+}
+
+// methodName converts the binding name (e.g. `MY_KV` and `myBucket`) to the exported method name (e.g. `MyKV` and `MyBucket`).
+//   - if the name has no letters or digits, returns empty string.
+func methodName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		upper := strings.ToUpper(w)
+		switch {
+		case initialisms[upper]:
+			b.WriteString(upper)
+		case upper == w:
+			b.WriteString(upper[:1] + strings.ToLower(w[1:]))
+		default:
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	s := b.String()
+	if s != "" && unicode.IsDigit(rune(s[0])) {
+		s = "Binding" + s
+	}
+	return s
+}
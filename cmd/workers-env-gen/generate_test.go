@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfigJSONC(t *testing.T) {
+	src := `{
+  // comment
+  "name": "app", /* block */
+  "vars": { "URL": "https://example.com/*not a comment*/", "COUNT": 1, },
+  "r2_buckets": [{ "binding": "BUCKET" },],
+  "env": { "prod": { "kv_namespaces": [{ "binding": "PROD_KV" }] } },
+}`
+	cfg, err := parseConfig([]byte(src), false, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Vars["URL"]; got != "https://example.com/*not a comment*/" {
+		t.Errorf("unexpected var: %v", got)
+	}
+	if len(cfg.R2Buckets) != 1 || cfg.R2Buckets[0].name() != "BUCKET" {
+		t.Errorf("unexpected r2 buckets: %v", cfg.R2Buckets)
+	}
+	envCfg, err := parseConfig([]byte(src), false, "prod")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(envCfg.KVNamespaces) != 1 || len(envCfg.R2Buckets) != 0 {
+		t.Errorf("bindings must not be inherited from the top level: %+v", envCfg)
+	}
+	if _, err := parseConfig([]byte(src), false, "missing"); err == nil {
+		t.Errorf("want error for missing environment")
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := `
+[vars]
+API_HOST = "https://example.com"
+LIMITS = { max = 10 }
+
+[[kv_namespaces]]
+binding = "MY_KV"
+
+[[d1_databases]]
+binding = "DB"
+
+[durable_objects]
+bindings = [{ name = "COUNTER", class_name = "Counter" }]
+`
+	cfg, err := parseConfig([]byte(src), true, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	accessors, warnings := collectAccessors(cfg, []string{"API_TOKEN"})
+	if len(warnings) != 1 {
+		t.Errorf("want a warning for non-string var, got %v", warnings)
+	}
+	out, err := generate("app", "wrangler.toml", accessors)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"package app\n",
+		"\t\"sync\"\n\n\t\"github.com/syumai/workers/cloudflare\"\n",
+		"func (e *Env) APIHost() string {\n\treturn cloudflare.Getenv(e.ctx, \"API_HOST\")",
+		"func (e *Env) APIToken() string {",
+		"func (e *Env) MyKV() (*cloudflare.KVNamespace, error) {",
+		"//   - The *sql.DB is opened once per Env, so it can be called for each query.\nfunc (e *Env) DB() (*sql.DB, error) {\n\te.d1DatabaseDB.once.Do(func() {\n\t\tc, err := d1.OpenConnector(e.ctx, \"DB\")",
+		"\td1DatabaseDB d1Database\n",
+		"type d1Database struct {",
+		"func (e *Env) Counter() (*cloudflare.DurableObjectNamespace, error) {",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("generated code doesn't contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "Limits") {
+		t.Errorf("non-string var must be skipped:\n%s", out)
+	}
+}
+
+func TestGenerateConflict(t *testing.T) {
+	accessors := []*accessor{
+		{kind: kindKV, binding: "MY_KV"},
+		{kind: kindR2, binding: "my-kv"},
+	}
+	if _, err := generate("app", "wrangler.toml", accessors); err == nil {
+		t.Errorf("want error for conflicting method names")
+	}
+}
+
+func TestMethodName(t *testing.T) {
+	tests := map[string]string{
+		"MY_KV":        "MyKV",
+		"myBucket":     "MyBucket",
+		"API_URL":      "APIURL",
+		"auth-service": "AuthService",
+		"DB":           "DB",
+		"2FA_STORE":    "Binding2faStore",
+		"___":          "",
+	}
+	for name, want := range tests {
+		if got := methodName(name); got != want {
+			t.Errorf("methodName(%q): want %q, got %q", name, want, got)
+		}
+	}
+}
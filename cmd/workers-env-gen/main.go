@@ -0,0 +1,78 @@
+// workers-env-gen generates the typed Env from the wrangler configuration (wrangler.toml, wrangler.jsonc or wrangler.json).
+// The generated Env has accessors of vars, secrets, and bindings of KV, R2, D1, Queues, services, Durable Objects,
+// Hyperdrive, Vectorize and Workers AI.
+//
+// Usage:
+//
+//	//go:generate go run github.com/syumai/workers/cmd/workers-env-gen
+//
+// then, bindings can be used without their names:
+//
+//	env := NewEnv(req.Context())
+//	kv, err := env.MyKV()
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// stringsFlag is a flag.Value which can be specified multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func main() {
+	var (
+		configPath string
+		envName    string
+		output     string
+		pkg        string
+		secrets    stringsFlag
+	)
+	flag.StringVar(&configPath, "config", "", `path of the wrangler configuration. wrangler.toml, wrangler.jsonc and wrangler.json are searched by default`)
+	flag.StringVar(&envName, "env", "", `name of the environment to generate the bindings for`)
+	flag.StringVar(&output, "o", "env_gen.go", `path of the generated file`)
+	flag.StringVar(&pkg, "package", os.Getenv("GOPACKAGE"), `package name of the generated file. $GOPACKAGE (set by go generate) is used by default`)
+	flag.Var(&secrets, "secret", `name of the secret, which isn't written in the wrangler configuration. can be specified multiple times`)
+	flag.Parse()
+	if pkg == "" {
+		pkg = "main"
+	}
+	if err := run(configPath, envName, output, pkg, secrets); err != nil {
+		fmt.Fprintf(os.Stderr, "err: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, envName, output, pkg string, secrets []string) error {
+	if configPath == "" {
+		p, err := findConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = p
+	}
+	cfg, err := loadConfig(configPath, envName)
+	if err != nil {
+		return err
+	}
+	accessors, warnings := collectAccessors(cfg, secrets)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	src, err := generate(pkg, configPath, accessors)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// parseTOML parses the TOML document into a map.
+// This is a minimal parser which supports the subset of TOML used by wrangler.toml:
+// tables, arrays of tables, dotted keys, strings, integers, floats, booleans, arrays and inline tables.
+// Dates and times are returned as strings.
+func parseTOML(data []byte) (map[string]any, error) {
+	p := &tomlParser{src: string(data), line: 1, root: map[string]any{}}
+	p.current = p.root
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("toml: line %d: %w", p.line, err)
+	}
+	return p.root, nil
+}
+
+type tomlParser struct {
+	src  string
+	pos  int
+	line int
+	root map[string]any
+	// current is the table which key/value pairs are added to.
+	current map[string]any
+}
+
+func (p *tomlParser) parse() error {
+	for {
+		p.skipSpaceAndComments(true)
+		if p.eof() {
+			return nil
+		}
+		var err error
+		if p.peek() == '[' {
+			err = p.parseTableHeader()
+		} else {
+			err = p.parseKeyValue(p.current)
+		}
+		if err != nil {
+			return err
+		}
+		if err := p.expectLineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *tomlParser) hasPrefix(s string) bool {
+	return strings.HasPrefix(p.src[p.pos:], s)
+}
+
+// skipSpaceAndComments skips spaces and comments. if newlines is true, newlines are also skipped.
+func (p *tomlParser) skipSpaceAndComments(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) expectLineEnd() error {
+	p.skipSpaceAndComments(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return fmt.Errorf("unexpected character %q after value", p.peek())
+	}
+	return nil
+}
+
+func (p *tomlParser) parseTableHeader() error {
+	isArray := p.hasPrefix("[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	p.skipSpaceAndComments(false)
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaceAndComments(false)
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !p.hasPrefix(closing) {
+		return fmt.Errorf("expected %s after table name", closing)
+	}
+	p.pos += len(closing)
+	parent, err := tableAt(p.root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if !isArray {
+		p.current, err = tableAt(parent, []string{last})
+		return err
+	}
+	table := map[string]any{}
+	switch v := parent[last].(type) {
+	case nil:
+		parent[last] = []any{table}
+	case []any:
+		parent[last] = append(v, table)
+	default:
+		return fmt.Errorf("key %s is not an array of tables", last)
+	}
+	p.current = table
+	return nil
+}
+
+// tableAt returns the table at the path of keys in m. Missing tables are created.
+// if the value at the path is an array of tables, its last element is used.
+func tableAt(m map[string]any, keys []string) (map[string]any, error) {
+	for _, key := range keys {
+		switch v := m[key].(type) {
+		case nil:
+			child := map[string]any{}
+			m[key] = child
+			m = child
+		case map[string]any:
+			m = v
+		case []any:
+			if len(v) == 0 {
+				return nil, fmt.Errorf("key %s is not a table", key)
+			}
+			child, ok := v[len(v)-1].(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("key %s is not a table", key)
+			}
+			m = child
+		default:
+			return nil, fmt.Errorf("key %s is not a table", key)
+		}
+	}
+	return m, nil
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]any) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpaceAndComments(false)
+	if p.peek() != '=' {
+		return fmt.Errorf("expected = after key %s", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipSpaceAndComments(false)
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	parent, err := tableAt(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, ok := parent[last]; ok {
+		return fmt.Errorf("duplicate key %s", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKey parses a bare, quoted or dotted key.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+	for {
+		var key string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case c == '\'':
+			s, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("invalid key character %q", c)
+			}
+			key = p.src[start:p.pos]
+		}
+		keys = append(keys, key)
+		p.skipSpaceAndComments(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+		p.skipSpaceAndComments(false)
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		if p.hasPrefix(`"""`) {
+			return p.parseMultilineBasicString()
+		}
+		return p.parseBasicString()
+	case c == '\'':
+		if p.hasPrefix("'''") {
+			return p.parseMultilineLiteralString()
+		}
+		return p.parseLiteralString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	case p.hasPrefix("true"):
+		p.pos += len("true")
+		return true, nil
+	case p.hasPrefix("false"):
+		p.pos += len("false")
+		return false, nil
+	}
+	return p.parseScalar()
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	p.pos += 3
+	p.skipNewline()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("unterminated multi-line string")
+		}
+		if p.hasPrefix(`"""`) {
+			p.pos += 3
+			return b.String(), nil
+		}
+		c := p.peek()
+		switch {
+		case c == '\\' && p.isLineEndingBackslash():
+			// a line ending backslash trims the newline and following whitespace.
+			p.pos++
+			for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+				if p.peek() == '\n' {
+					p.line++
+				}
+				p.pos++
+			}
+		case c == '\\':
+			if err := p.parseEscape(&b); err != nil {
+				return "", err
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// isLineEndingBackslash reports whether the backslash at the current position is followed only by whitespace until the newline.
+func (p *tomlParser) isLineEndingBackslash() bool {
+	for i := p.pos + 1; i < len(p.src); i++ {
+		switch p.src[i] {
+		case ' ', '\t', '\r':
+		case '\n':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func (p *tomlParser) parseEscape(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return fmt.Errorf("unterminated escape sequence")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return fmt.Errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return fmt.Errorf("invalid unicode escape")
+		}
+		b.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	p.pos++
+	start := p.pos
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if p.peek() == '\'' {
+			s := p.src[start:p.pos]
+			p.pos++
+			return s, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.pos += 3
+	p.skipNewline()
+	end := strings.Index(p.src[p.pos:], "'''")
+	if end < 0 {
+		return "", fmt.Errorf("unterminated multi-line string")
+	}
+	s := p.src[p.pos : p.pos+end]
+	p.line += strings.Count(s, "\n")
+	p.pos += end + 3
+	return s, nil
+}
+
+// skipNewline skips a newline immediately following the opening delimiter of multi-line strings.
+func (p *tomlParser) skipNewline() {
+	if p.hasPrefix("\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.hasPrefix("\n") {
+		p.pos++
+		p.line++
+	}
+}
+
+func (p *tomlParser) parseArray() ([]any, error) {
+	p.pos++
+	arr := []any{}
+	for {
+		p.skipSpaceAndComments(true)
+		if p.peek() == ']' {
+			p.pos++
+			return arr, nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+		p.skipSpaceAndComments(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return arr, nil
+		default:
+			return nil, fmt.Errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (map[string]any, error) {
+	p.pos++
+	table := map[string]any{}
+	for {
+		// newlines in inline tables are allowed by TOML 1.1, and accepted by wrangler.
+		p.skipSpaceAndComments(true)
+		if p.peek() == '}' {
+			p.pos++
+			return table, nil
+		}
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpaceAndComments(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, fmt.Errorf("expected , or } in inline table")
+		}
+	}
+}
+
+// parseScalar parses integers, floats, dates and times.
+func (p *tomlParser) parseScalar() (any, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte(" \t\r\n,]}#", p.peek()) < 0 {
+		p.pos++
+	}
+	// dates and times may contain a space between the date and the time.
+	if p.hasPrefix(" ") && isDate(p.src[start:p.pos]) && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]) {
+		p.pos++
+		for !p.eof() && strings.IndexByte(" \t\r\n,]}#", p.peek()) < 0 {
+			p.pos++
+		}
+	}
+	token := p.src[start:p.pos]
+	if token == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	if isDate(token) || strings.Count(token, ":") == 2 {
+		return token, nil
+	}
+	s := strings.ReplaceAll(token, "_", "")
+	if i, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return i, nil
+	}
+	switch s {
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return nil, fmt.Errorf("unsupported float value %s", token)
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, nil
+	}
+	return nil, fmt.Errorf("invalid value %s", token)
+}
+
+func isDate(s string) bool {
+	return len(s) >= 10 && s[4] == '-' && s[7] == '-' && isDigit(s[0])
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
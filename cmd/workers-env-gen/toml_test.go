@@ -0,0 +1,93 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	src := `
+# comment
+name = "app" # trailing comment
+compatibility_date = 2024-09-01
+workers_dev = true
+port = 8_787
+ratio = 0.5
+"quoted key" = 'C:\path'
+escaped = "tab\tand \u00e9"
+multi = """
+line1
+line2"""
+site.bucket = "./public"
+tags = [
+  "a",
+  "b", # comment
+]
+
+[vars]
+API_HOST = "https://example.com"
+FLAGS = { beta = true, nested = { level = 1 } }
+
+[[kv_namespaces]]
+binding = "KV1"
+
+[[kv_namespaces]]
+binding = "KV2"
+
+[kv_namespaces.extra]
+note = "belongs to KV2"
+
+[env.staging]
+vars = { API_HOST = "https://staging.example.com" }
+`
+	got, err := parseTOML([]byte(src))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"name":               "app",
+		"compatibility_date": "2024-09-01",
+		"workers_dev":        true,
+		"port":               int64(8787),
+		"ratio":              0.5,
+		"quoted key":         `C:\path`,
+		"escaped":            "tab\tand é",
+		"multi":              "line1\nline2",
+		"site":               map[string]any{"bucket": "./public"},
+		"tags":               []any{"a", "b"},
+		"vars": map[string]any{
+			"API_HOST": "https://example.com",
+			"FLAGS":    map[string]any{"beta": true, "nested": map[string]any{"level": int64(1)}},
+		},
+		"kv_namespaces": []any{
+			map[string]any{"binding": "KV1"},
+			map[string]any{"binding": "KV2", "extra": map[string]any{"note": "belongs to KV2"}},
+		},
+		"env": map[string]any{
+			"staging": map[string]any{
+				"vars": map[string]any{"API_HOST": "https://staging.example.com"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v\ngot  %#v", want, got)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := map[string]string{
+		"duplicate key":       "a = 1\na = 2",
+		"unterminated string": `a = "abc`,
+		"missing value":       "a =",
+		"garbage after value": `a = "x" y`,
+		"invalid escape":      `a = "\q"`,
+		"table over value":    "a = 1\n[a]",
+	}
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseTOML([]byte(src)); err == nil {
+				t.Errorf("want error for %q", src)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+// Package encodebody defines the internal header which marks bodies of responses as already encoded.
+package encodebody
+
+// Header is set to responses whose bodies are really encoded by Content-Encoding (e.g. compressed by Connect handlers),
+// so they are sent with `encodeBody: "manual"` instead of being compressed again by the runtime.
+//   - Bodies of other responses with Content-Encoding (e.g. proxied from fetch) are already decoded by the runtime,
+//     so the runtime must encode them.
+//   - The header is removed before the response is sent.
+//   - https://developers.cloudflare.com/workers/runtime-apis/response/#parameters
+const Header = "X-Workers-Encode-Body"
+
+// Manual is the value of Header.
+const Manual = "manual"
@@ -0,0 +1,39 @@
+package jshttp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"syscall/js"
+
+	"github.com/syumai/workers/internal/runtimecontext"
+)
+
+// ServeJSRequest serves JavaScript sides Request with http.Handler and returns JavaScript sides Response.
+//   - runtimeCtxObj is set to the context of the request, so the handler can access bindings.
+//     reqObj is also set, so the handler can read the `cf` property of the request.
+//   - This function returns when the handler starts streaming the response body or returns.
+//     if the handler writes a small body and returns without blocking, the body is sent without ReadableStream.
+func ServeJSRequest(handler http.Handler, reqObj js.Value, runtimeCtxObj js.Value) (js.Value, error) {
+	req, err := ToRequest(reqObj)
+	if err != nil {
+		return js.Value{}, err
+	}
+	ctx := runtimecontext.New(context.Background(), runtimeCtxObj)
+	ctx = runtimecontext.WithRequest(ctx, reqObj)
+	req = req.WithContext(ctx)
+	reader, writer := io.Pipe()
+	w := &ResponseWriter{
+		HeaderValue: http.Header{},
+		StatusCode:  http.StatusOK,
+		Reader:      reader,
+		Writer:      writer,
+		ReadyCh:     make(chan struct{}),
+	}
+	go func() {
+		defer w.Finish()
+		handler.ServeHTTP(w, req)
+	}()
+	<-w.ReadyCh
+	return w.ToJSResponse(), nil
+}
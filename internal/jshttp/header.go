@@ -4,22 +4,30 @@ import (
 	"net/http"
 	"strings"
 	"syscall/js"
+	"unicode/utf8"
 
 	"github.com/syumai/workers/internal/jsutil"
 )
 
+// headerSep separates names and values of headers serialized as a string.
+// Names and values of headers can't contain line feeds, so this never appears in them.
+const headerSep = "\n"
+
 // ToHeader converts JavaScript sides Headers to http.Header.
 //   - Headers: https://developer.mozilla.org/docs/Web/API/Headers
+//   - All headers are serialized to a string on JavaScript side, so the number of calls doesn't depend on the number of headers.
+//   - Common names and values are interned, so proxy-style workers don't allocate identical strings for each request.
 func ToHeader(headers js.Value) http.Header {
-	entries := jsutil.ArrayFrom(headers.Call("entries"))
-	headerLen := entries.Length()
+	serialized := jsutil.ArrayFrom(headers).Call("flat").Call("join", headerSep).String()
 	h := http.Header{}
-	for i := 0; i < headerLen; i++ {
-		entry := entries.Index(i)
-		key := entry.Index(0).String()
-		values := entry.Index(1).String()
-		for _, value := range strings.Split(values, ",") {
-			h.Add(key, value)
+	if serialized == "" {
+		return h
+	}
+	fields := strings.Split(serialized, headerSep)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key := canonicalHeaderName(fields[i])
+		for _, value := range strings.Split(fields[i+1], ",") {
+			h[key] = append(h[key], internValue(value))
 		}
 	}
 	return h
@@ -27,12 +35,57 @@ func ToHeader(headers js.Value) http.Header {
 
 // ToJSHeader converts http.Header to JavaScript sides Headers.
 //   - Headers: https://developer.mozilla.org/docs/Web/API/Headers
+//   - All headers are passed to JavaScript side as a JSON string of entries, so the number of calls doesn't depend on the number of headers.
 func ToJSHeader(header http.Header) js.Value {
-	h := jsutil.HeadersClass.New()
+	if len(header) == 0 {
+		return jsutil.HeadersClass().New()
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	first := true
 	for key, values := range header {
 		for _, value := range values {
-			h.Call("append", key, value)
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			b.WriteByte('[')
+			writeJSONString(&b, key)
+			b.WriteByte(',')
+			writeJSONString(&b, value)
+			b.WriteByte(']')
 		}
 	}
-	return h
+	b.WriteByte(']')
+	entries := jsutil.JSONClass().Call("parse", b.String())
+	return jsutil.HeadersClass().New(entries)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// writeJSONString writes s as a JSON string. invalid UTF-8 is replaced with U+FFFD as js.ValueOf does.
+func writeJSONString(b *strings.Builder, s string) {
+	b.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case c < 0x20:
+				b.WriteString(`\u00`)
+				b.WriteByte(hexDigits[c>>4])
+				b.WriteByte(hexDigits[c&0xF])
+			default:
+				b.WriteByte(c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		b.WriteRune(r)
+		i += size
+	}
+	b.WriteByte('"')
 }
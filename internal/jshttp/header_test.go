@@ -0,0 +1,74 @@
+package jshttp
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+func TestHeaderConversion(t *testing.T) {
+	tests := map[string]http.Header{
+		"empty": {},
+		"values": {
+			"Content-Type": {"text/plain; charset=utf-8"},
+			"X-Quoted":     {`a "quoted" \ value`},
+			"X-Unicode":    {"café"},
+			"X-Empty":      {""},
+		},
+	}
+	for name, header := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ToHeader(ToJSHeader(header))
+			if !reflect.DeepEqual(got, header) {
+				t.Errorf("want %v, got %v", header, got)
+			}
+		})
+	}
+}
+
+func TestToHeaderInterning(t *testing.T) {
+	h := jsutil.HeadersClass().New()
+	h.Call("append", "content-type", "application/json")
+	h.Call("append", "x-custom-header", "application/json")
+	got := ToHeader(h)
+	want := http.Header{
+		"Content-Type":    {"application/json"},
+		"X-Custom-Header": {"application/json"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if n := testing.AllocsPerRun(10, func() { canonicalHeaderName("content-type") }); n != 0 {
+		t.Errorf("want common names to be returned without allocations, got %v allocs", n)
+	}
+}
+
+func benchmarkHeader() http.Header {
+	return http.Header{
+		"Accept":          {"*/*"},
+		"Accept-Encoding": {"gzip, deflate, br"},
+		"Content-Type":    {"application/json"},
+		"Cookie":          {"session=0123456789abcdef"},
+		"User-Agent":      {"Mozilla/5.0 (X11; Linux x86_64)"},
+		"X-Forwarded-For": {"192.0.2.1"},
+		"X-Request-Id":    {"f81d4fae-7dec-11d0-a765-00a0c91e6bf6"},
+	}
+}
+
+func BenchmarkToHeader(b *testing.B) {
+	h := ToJSHeader(benchmarkHeader())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToHeader(h)
+	}
+}
+
+func BenchmarkToJSHeader(b *testing.B) {
+	h := benchmarkHeader()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ToJSHeader(h)
+	}
+}
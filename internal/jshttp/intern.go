@@ -0,0 +1,139 @@
+package jshttp
+
+import (
+	"net/http"
+	"net/textproto"
+	"sync"
+)
+
+// commonHeaderNames are canonical names of headers which appear in most requests and responses.
+var commonHeaderNames = []string{
+	"Accept",
+	"Accept-Encoding",
+	"Accept-Language",
+	"Accept-Ranges",
+	"Age",
+	"Authorization",
+	"Cache-Control",
+	"Cdn-Loop",
+	"Cf-Connecting-Ip",
+	"Cf-Ipcountry",
+	"Cf-Ray",
+	"Cf-Visitor",
+	"Connection",
+	"Content-Disposition",
+	"Content-Encoding",
+	"Content-Language",
+	"Content-Length",
+	"Content-Type",
+	"Cookie",
+	"Date",
+	"Etag",
+	"Expires",
+	"Host",
+	"If-Modified-Since",
+	"If-None-Match",
+	"Last-Modified",
+	"Location",
+	"Origin",
+	"Pragma",
+	"Range",
+	"Referer",
+	"Server",
+	"Set-Cookie",
+	"Transfer-Encoding",
+	"Upgrade",
+	"User-Agent",
+	"Vary",
+	"Via",
+	"X-Forwarded-For",
+	"X-Forwarded-Proto",
+	"X-Real-Ip",
+	"X-Requested-With",
+}
+
+// commonValues are values of headers and methods which are repeated across requests.
+var commonValues = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+	"*/*",
+	"0",
+	"application/json",
+	"application/json; charset=utf-8",
+	"application/octet-stream",
+	"application/x-www-form-urlencoded",
+	"br",
+	"bytes",
+	"chunked",
+	"close",
+	"gzip",
+	"gzip, br",
+	"gzip, deflate",
+	"gzip, deflate, br",
+	"https",
+	"keep-alive",
+	"max-age=0",
+	"no-cache",
+	"no-store",
+	"text/html",
+	"text/html; charset=utf-8",
+	"text/plain",
+	"text/plain; charset=utf-8",
+	"websocket",
+}
+
+var (
+	internOnce sync.Once
+	// headerNames maps names of headers given by JavaScript side (lower case) to the canonical names.
+	headerNames map[string]string
+	values      map[string]string
+)
+
+// initIntern builds the tables on the first use, so they don't cost on cold starts of workers without HTTP handlers.
+func initIntern() {
+	headerNames = make(map[string]string, len(commonHeaderNames)*2)
+	values = make(map[string]string, len(commonValues))
+	for _, name := range commonHeaderNames {
+		headerNames[name] = name
+		headerNames[lowerASCII(name)] = name
+	}
+	for _, v := range commonValues {
+		values[v] = v
+	}
+}
+
+// lowerASCII returns s in lower case. s must consist of ASCII characters.
+func lowerASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+// canonicalHeaderName returns the canonical name of the header.
+//   - Common names are returned without allocating a new string.
+func canonicalHeaderName(name string) string {
+	internOnce.Do(initIntern)
+	if canonical, ok := headerNames[name]; ok {
+		return canonical
+	}
+	return textproto.CanonicalMIMEHeaderKey(name)
+}
+
+// internValue returns the shared string equal to v if v is a common value.
+//   - Values split from the serialized headers share the memory of them, so interned values don't keep it alive.
+func internValue(v string) string {
+	internOnce.Do(initIntern)
+	if interned, ok := values[v]; ok {
+		return interned
+	}
+	return v
+}
@@ -17,12 +17,13 @@ func ToBody(streamOrNull js.Value) io.ReadCloser {
 	if streamOrNull.IsNull() {
 		return nil
 	}
-	sr := streamOrNull.Call("getReader")
-	return io.NopCloser(jsutil.ConvertStreamReaderToReader(sr))
+	return io.NopCloser(jsutil.ConvertBodyStreamToReader(streamOrNull))
 }
 
 // ToRequest converts JavaScript sides Request to *http.Request.
 //   - Request: https://developer.mozilla.org/docs/Web/API/Request
+//   - Headers are copied at once instead of being looked up on the first access, since http.Header is a map
+//     which handlers read and write directly. ToHeader copies them by a constant number of calls.
 func ToRequest(req js.Value) (*http.Request, error) {
 	reqUrl, err := url.Parse(req.Get("url").String())
 	if err != nil {
@@ -33,7 +34,7 @@ func ToRequest(req js.Value) (*http.Request, error) {
 	// ignore err
 	contentLength, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
 	return &http.Request{
-		Method:           req.Get("method").String(),
+		Method:           internValue(req.Get("method").String()),
 		URL:              reqUrl,
 		Header:           header,
 		Body:             ToBody(req.Get("body")),
@@ -50,10 +51,10 @@ func ToJSRequest(req *http.Request) js.Value {
 	jsReqOptions.Set("method", req.Method)
 	jsReqOptions.Set("headers", ToJSHeader(req.Header))
 	jsReqBody := js.Undefined()
-	if req.Body != nil {
+	if req.Body != nil && req.Body != http.NoBody {
 		jsReqBody = jsutil.ConvertReaderToReadableStream(req.Body)
 	}
 	jsReqOptions.Set("body", jsReqBody)
-	jsReq := jsutil.RequestClass.New(req.URL.String(), jsReqOptions)
+	jsReq := jsutil.RequestClass().New(req.URL.String(), jsReqOptions)
 	return jsReq
 }
@@ -4,29 +4,33 @@ import (
 	"io"
 	"net/http"
 	"strconv"
-	"strings"
 	"syscall/js"
 
+	"github.com/syumai/workers/internal/encodebody"
 	"github.com/syumai/workers/internal/jsutil"
 )
 
 // ToResponse converts JavaScript sides Response to *http.Response.
 //   - Response: https://developer.mozilla.org/docs/Web/API/Response
+//   - The body is streamed from the ReadableStream of the Response, so it's never buffered as a whole.
+//   - if Content-Length header is not given, ContentLength is -1 (unknown).
 func ToResponse(res js.Value) (*http.Response, error) {
 	status := res.Get("status").Int()
-	promise := res.Call("text")
-	body, err := jsutil.AwaitPromise(promise)
+	header := ToHeader(res.Get("headers"))
+	contentLength, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
 	if err != nil {
-		return nil, err
+		contentLength = -1
+	}
+	body := ToBody(res.Get("body"))
+	if body == nil {
+		body = http.NoBody
+		contentLength = 0
 	}
-	header := ToHeader(res.Get("headers"))
-	contentLength, _ := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
-
 	return &http.Response{
 		Status:        strconv.Itoa(status) + " " + res.Get("statusText").String(),
 		StatusCode:    status,
 		Header:        header,
-		Body:          io.NopCloser(strings.NewReader(body.String())),
+		Body:          body,
 		ContentLength: contentLength,
 	}, nil
 }
@@ -36,23 +40,66 @@ func ToJSResponse(res *http.Response) js.Value {
 	return newJSResponse(res.StatusCode, res.Header, res.Body)
 }
 
-// newJSResponse creates JavaScript sides Response class object.
-//   - Response: https://developer.mozilla.org/docs/Web/API/Response
+// newJSResponse creates JavaScript sides Response class object whose body is streamed from body.
 func newJSResponse(statusCode int, headers http.Header, body io.ReadCloser) js.Value {
+	return newJSResponseWithBody(statusCode, headers, func() js.Value {
+		return jsutil.ConvertReaderToReadableStream(body)
+	})
+}
+
+// newJSSmallResponse creates JavaScript sides Response class object whose body is b.
+//   - b is copied to JavaScript side at once, so ReadableStream is not created for small responses.
+func newJSSmallResponse(statusCode int, headers http.Header, b []byte) js.Value {
+	return newJSResponseWithBody(statusCode, headers, func() js.Value {
+		if len(b) == 0 {
+			return jsutil.Null
+		}
+		ua := jsutil.NewUint8Array(len(b))
+		js.CopyBytesToJS(ua, b)
+		return ua
+	})
+}
+
+// newJSResponseWithBody creates JavaScript sides Response class object.
+//   - Response: https://developer.mozilla.org/docs/Web/API/Response
+//   - body is not called for statuses which don't have bodies.
+func newJSResponseWithBody(statusCode int, headers http.Header, body func() js.Value) js.Value {
 	status := statusCode
 	if status == 0 {
 		status = http.StatusOK
 	}
-	respInit := jsutil.NewObject()
-	respInit.Set("status", status)
-	respInit.Set("statusText", http.StatusText(status))
-	respInit.Set("headers", ToJSHeader(headers))
+	respInit := newResponseInit(status, headers)
 	if status == http.StatusSwitchingProtocols ||
 		status == http.StatusNoContent ||
 		status == http.StatusResetContent ||
 		status == http.StatusNotModified {
-		return jsutil.ResponseClass.New(jsutil.Null, respInit)
+		return jsutil.ResponseClass().New(jsutil.Null, respInit)
+	}
+	return jsutil.ResponseClass().New(body(), respInit)
+}
+
+// newResponseInit creates ResponseInit of the status and the headers.
+//   - Bodies with Content-Encoding are encoded by the runtime, since bodies fetched by the runtime are already decoded.
+//     Only bodies marked by encodebody.Header are sent as they are.
+func newResponseInit(status int, headers http.Header) js.Value {
+	respInit := jsutil.NewObject()
+	respInit.Set("status", status)
+	respInit.Set("statusText", http.StatusText(status))
+	if headers.Get(encodebody.Header) == encodebody.Manual {
+		headers = headers.Clone()
+		headers.Del(encodebody.Header)
+		respInit.Set("encodeBody", "manual")
 	}
-	readableStream := jsutil.ConvertReaderToReadableStream(body)
-	return jsutil.ResponseClass.New(readableStream, respInit)
+	respInit.Set("headers", ToJSHeader(headers))
+	return respInit
+}
+
+// newJSWebSocketResponse creates JavaScript sides Response class object which accepts the WebSocket upgrade.
+//   - https://developers.cloudflare.com/workers/runtime-apis/websockets/
+func newJSWebSocketResponse(headers http.Header, webSocket js.Value) js.Value {
+	respInit := jsutil.NewObject()
+	respInit.Set("status", http.StatusSwitchingProtocols)
+	respInit.Set("headers", ToJSHeader(headers))
+	respInit.Set("webSocket", webSocket)
+	return jsutil.ResponseClass().New(jsutil.Null, respInit)
 }
@@ -0,0 +1,246 @@
+package jshttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/syumai/workers/internal/encodebody"
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// patternReader reads n bytes of a repeated pattern without holding them.
+type patternReader struct {
+	n int64
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > r.n {
+		p = p[:r.n]
+	}
+	for i := range p {
+		p[i] = byte(i)
+	}
+	r.n -= int64(len(p))
+	return len(p), nil
+}
+
+// peakHeapWriter discards written bytes, and samples the heap in use.
+type peakHeapWriter struct {
+	written int64
+	peak    uint64
+}
+
+func (w *peakHeapWriter) Write(p []byte) (int, error) {
+	const sampleInterval = 16 << 20
+	if w.written/sampleInterval != (w.written+int64(len(p)))/sampleInterval {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		if m.HeapInuse > w.peak {
+			w.peak = m.HeapInuse
+		}
+	}
+	w.written += int64(len(p))
+	return len(p), nil
+}
+
+func TestResponseStreaming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping streaming of a large body in short mode")
+	}
+	const size = 256 << 20
+	const maxHeap = 64 << 20
+	jsRes := ToJSResponse(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(&patternReader{n: size}),
+	})
+	res, err := ToResponse(jsRes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.ContentLength != -1 {
+		t.Errorf("want unknown content length, got %d", res.ContentLength)
+	}
+	w := &peakHeapWriter{}
+	if _, err := io.Copy(w, res.Body); err != nil {
+		t.Fatal(err)
+	}
+	if w.written != size {
+		t.Errorf("want %d bytes, got %d", size, w.written)
+	}
+	if w.peak > maxHeap {
+		t.Errorf("want body to be streamed, got peak heap %d bytes", w.peak)
+	}
+}
+
+func TestResponseWithoutBody(t *testing.T) {
+	jsRes := ToJSResponse(&http.Response{
+		StatusCode: http.StatusNoContent,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+	})
+	res, err := ToResponse(jsRes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Body != http.NoBody || res.ContentLength != 0 {
+		t.Errorf("want no body, got %v (%d)", res.Body, res.ContentLength)
+	}
+}
+
+func TestServeJSRequestStreaming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping streaming of a large body in short mode")
+	}
+	const size = 256 << 20
+	const maxHeap = 64 << 20
+	init := jsutil.NewObject()
+	init.Set("method", http.MethodPost)
+	init.Set("body", jsutil.ConvertReaderToReadableStream(io.NopCloser(&patternReader{n: size})))
+	// Node.js requires duplex to send a ReadableStream.
+	init.Set("duplex", "half")
+	reqObj := jsutil.RequestClass().New("https://example.com/", init)
+	echo := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, err := io.Copy(w, req.Body); err != nil {
+			t.Error(err)
+		}
+	})
+	jsRes, err := ServeJSRequest(echo, reqObj, js.Null())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ToResponse(jsRes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	w := &peakHeapWriter{}
+	if _, err := io.Copy(w, res.Body); err != nil {
+		t.Fatal(err)
+	}
+	if w.written != size {
+		t.Errorf("want %d bytes, got %d", size, w.written)
+	}
+	if w.peak > maxHeap {
+		t.Errorf("want bodies to be streamed, got peak heap %d bytes", w.peak)
+	}
+}
+
+func serveAndRead(t *testing.T, handler http.HandlerFunc) (*http.Response, string) {
+	t.Helper()
+	reqObj := jsutil.RequestClass().New("https://example.com/")
+	jsRes, err := ServeJSRequest(handler, reqObj, js.Null())
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := ToResponse(jsRes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(b)
+}
+
+func TestSmallResponse(t *testing.T) {
+	var w *ResponseWriter
+	res, body := serveAndRead(t, func(rw http.ResponseWriter, req *http.Request) {
+		w = rw.(*ResponseWriter)
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusCreated)
+		io.WriteString(rw, `{"ok":`)
+		io.WriteString(rw, `true}`)
+	})
+	if res.StatusCode != http.StatusCreated || body != `{"ok":true}` {
+		t.Errorf("unexpected response: %d %q", res.StatusCode, body)
+	}
+	if w.state != responseFinished {
+		t.Errorf("want small response to be sent at once, got state %v", w.state)
+	}
+}
+
+func TestStreamingResponse(t *testing.T) {
+	tests := map[string]http.HandlerFunc{
+		"large body": func(w http.ResponseWriter, req *http.Request) {
+			w.Write(bytes.Repeat([]byte("a"), maxSmallResponseSize+1))
+		},
+		"blocking handler": func(w http.ResponseWriter, req *http.Request) {
+			io.WriteString(w, "first,")
+			// the body written so far is sent while the handler waits.
+			if _, err := jsutil.AwaitPromise(jsutil.PromiseClass().Call("resolve")); err != nil {
+				t.Error(err)
+			}
+			io.WriteString(w, "second")
+		},
+		"flush": func(w http.ResponseWriter, req *http.Request) {
+			io.WriteString(w, "first,")
+			w.(http.Flusher).Flush()
+			io.WriteString(w, "second")
+		},
+	}
+	for name, handler := range tests {
+		t.Run(name, func(t *testing.T) {
+			var w *ResponseWriter
+			_, body := serveAndRead(t, func(rw http.ResponseWriter, req *http.Request) {
+				w = rw.(*ResponseWriter)
+				handler(rw, req)
+			})
+			if w.state != responseStreaming {
+				t.Errorf("want streaming response, got state %v", w.state)
+			}
+			if name != "large body" && body != "first,second" {
+				t.Errorf("unexpected body %q", body)
+			}
+		})
+	}
+}
+
+func TestResponseEncodeBody(t *testing.T) {
+	proxied := newResponseInit(http.StatusOK, http.Header{"Content-Encoding": {"gzip"}})
+	if v := proxied.Get("encodeBody"); !v.IsUndefined() {
+		t.Errorf("want decoded bodies to be encoded by the runtime, got encodeBody %v", v)
+	}
+	encoded := newResponseInit(http.StatusOK, http.Header{"Content-Encoding": {"gzip"}, encodebody.Header: {encodebody.Manual}})
+	if v := encoded.Get("encodeBody"); v.IsUndefined() || v.String() != "manual" {
+		t.Errorf("want encoded bodies to be sent as they are, got encodeBody %v", v)
+	}
+	if encoded.Get("headers").Call("has", encodebody.Header).Bool() {
+		t.Error("want the internal header to be removed")
+	}
+}
+
+func TestProxiedResponse(t *testing.T) {
+	// bodies fetched by the runtime are decoded, but keep Content-Encoding.
+	upstream := ToJSResponse(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Encoding": {"gzip"}, "Content-Type": {"text/plain"}},
+		Body:       io.NopCloser(strings.NewReader("decoded body")),
+	})
+	res, body := serveAndRead(t, func(w http.ResponseWriter, req *http.Request) {
+		fetched, err := ToResponse(upstream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fetched.Body.Close()
+		for k, v := range fetched.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(fetched.StatusCode)
+		io.Copy(w, fetched.Body)
+	})
+	if body != "decoded body" || res.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("unexpected response: %q, %v", body, res.Header)
+	}
+}
@@ -1,12 +1,28 @@
 package jshttp
 
 import (
+	"bytes"
 	"io"
 	"net/http"
 	"sync"
 	"syscall/js"
 )
 
+// maxSmallResponseSize is the maximum size of bodies which are sent without ReadableStream.
+const maxSmallResponseSize = 16 << 10
+
+// responseState represents how the body of ResponseWriter is sent.
+type responseState int
+
+const (
+	// responseBuffering indicates that the body written so far is held by ResponseWriter.
+	responseBuffering responseState = iota
+	// responseStreaming indicates that the body is streamed through the pipe.
+	responseStreaming
+	// responseFinished indicates that the handler returned while buffering, so the body is sent at once.
+	responseFinished
+)
+
 type ResponseWriter struct {
 	HeaderValue http.Header
 	StatusCode  int
@@ -14,9 +30,22 @@ type ResponseWriter struct {
 	Writer      *io.PipeWriter
 	ReadyCh     chan struct{}
 	Once        sync.Once
+	// WebSocket is the client side of WebSocketPair which is returned with `101 Switching Protocols` response.
+	WebSocket js.Value
+
+	// writeMu orders writes to the pipe. it's held while writing, which blocks until JavaScript side reads.
+	writeMu sync.Mutex
+	// stateMu guards state, buf and flushScheduled.
+	stateMu        sync.Mutex
+	state          responseState
+	buf            []byte
+	flushScheduled bool
 }
 
-var _ http.ResponseWriter = &ResponseWriter{}
+var (
+	_ http.ResponseWriter = &ResponseWriter{}
+	_ http.Flusher        = &ResponseWriter{}
+)
 
 // Ready indicates that ResponseWriter is ready to be converted to Response.
 func (w *ResponseWriter) Ready() {
@@ -25,11 +54,85 @@ func (w *ResponseWriter) Ready() {
 	})
 }
 
+// Write writes data to the body.
+//   - Small bodies are held until the handler returns, so they are sent without ReadableStream.
+//     The held body is flushed when the handler blocks (e.g. waits for I/O), when it exceeds maxSmallResponseSize,
+//     or when Flush is called, so streaming responses are still sent as they are written.
 func (w *ResponseWriter) Write(data []byte) (n int, err error) {
-	w.Ready()
+	if len(data) == 0 {
+		// empty writes to the pipe block until JavaScript side reads nothing, which stalls the stream.
+		return 0, nil
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	w.stateMu.Lock()
+	switch w.state {
+	case responseBuffering:
+		if len(w.buf)+len(data) <= maxSmallResponseSize {
+			w.buf = append(w.buf, data...)
+			schedule := !w.flushScheduled
+			w.flushScheduled = true
+			w.stateMu.Unlock()
+			if schedule {
+				// this runs when the handler blocks, since goroutines are switched only then on WebAssembly.
+				go w.Flush()
+			}
+			return len(data), nil
+		}
+		buf := w.startStreaming()
+		w.stateMu.Unlock()
+		if len(buf) > 0 {
+			if _, err := w.Writer.Write(buf); err != nil {
+				return 0, err
+			}
+		}
+	case responseFinished:
+		w.stateMu.Unlock()
+		return 0, io.ErrClosedPipe
+	default:
+		w.stateMu.Unlock()
+	}
 	return w.Writer.Write(data)
 }
 
+// Flush sends the body written so far, and streams the rest of the body.
+func (w *ResponseWriter) Flush() {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	w.stateMu.Lock()
+	if w.state != responseBuffering {
+		w.stateMu.Unlock()
+		return
+	}
+	buf := w.startStreaming()
+	w.stateMu.Unlock()
+	if len(buf) > 0 {
+		_, _ = w.Writer.Write(buf)
+	}
+}
+
+// startStreaming switches to streaming, and returns the body held so far. stateMu must be held.
+func (w *ResponseWriter) startStreaming() []byte {
+	w.state = responseStreaming
+	buf := w.buf
+	w.buf = nil
+	w.Ready()
+	return buf
+}
+
+// Finish indicates that the handler returned.
+func (w *ResponseWriter) Finish() {
+	w.writeMu.Lock()
+	w.stateMu.Lock()
+	if w.state == responseBuffering {
+		w.state = responseFinished
+	}
+	w.stateMu.Unlock()
+	w.writeMu.Unlock()
+	w.Ready()
+	w.Writer.Close()
+}
+
 func (w *ResponseWriter) Header() http.Header {
 	return w.HeaderValue
 }
@@ -41,5 +144,25 @@ func (w *ResponseWriter) WriteHeader(statusCode int) {
 // ToJSResponse converts *ResponseWriter to JavaScript sides Response.
 //   - Response: https://developer.mozilla.org/docs/Web/API/Response
 func (w *ResponseWriter) ToJSResponse() js.Value {
+	if !w.WebSocket.IsUndefined() {
+		return newJSWebSocketResponse(w.HeaderValue, w.WebSocket)
+	}
+	w.stateMu.Lock()
+	state, buf := w.state, w.buf
+	if state == responseBuffering {
+		// Ready was called without writing the body (e.g. by middlewares), so the held body precedes the pipe.
+		w.state = responseStreaming
+		w.buf = nil
+	}
+	w.stateMu.Unlock()
+	switch state {
+	case responseFinished:
+		return newJSSmallResponse(w.StatusCode, w.HeaderValue, buf)
+	case responseBuffering:
+		return newJSResponse(w.StatusCode, w.HeaderValue, struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), w.Reader), w.Reader})
+	}
 	return newJSResponse(w.StatusCode, w.HeaderValue, w.Reader)
 }
@@ -0,0 +1,82 @@
+package jsutil
+
+import (
+	"fmt"
+	"sync"
+	"syscall/js"
+)
+
+// awaiter holds the result of a promise awaited by a goroutine.
+//   - awaiters are pooled, and done is buffered, so settlement doesn't block the JavaScript side.
+type awaiter struct {
+	done      chan struct{}
+	value     js.Value
+	fulfilled bool
+}
+
+var (
+	awaitersMu    sync.Mutex
+	awaiters      = map[int]*awaiter{}
+	lastAwaiterID int
+	awaiterPool   = sync.Pool{
+		New: func() any { return &awaiter{done: make(chan struct{}, 1)} },
+	}
+
+	settleOnce sync.Once
+	// settle is called with (id, fulfilled, value) by all awaited promises, so no js.Func is allocated for each await.
+	settle js.Func
+)
+
+func initSettle() {
+	settle = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		id := args[0].Int()
+		awaitersMu.Lock()
+		a := awaiters[id]
+		delete(awaiters, id)
+		awaitersMu.Unlock()
+		if a == nil {
+			return js.Undefined()
+		}
+		a.fulfilled = args[1].Bool()
+		if len(args) > 2 {
+			a.value = args[2]
+		} else {
+			a.value = js.Undefined()
+		}
+		a.done <- struct{}{}
+		return js.Undefined()
+	})
+}
+
+// awaitSettled awaits promiseVal, and returns the fulfilled value or the rejected reason. endAwait is called when it's settled.
+func awaitSettled(promiseVal js.Value, endAwait func()) (value js.Value, fulfilled bool) {
+	defer endAwait()
+	settleOnce.Do(initSettle)
+	a := awaiterPool.Get().(*awaiter)
+	awaitersMu.Lock()
+	lastAwaiterID++
+	id := lastAwaiterID
+	awaiters[id] = a
+	awaitersMu.Unlock()
+	promiseVal.Call("then", settle.Call("bind", Null, id, true), settle.Call("bind", Null, id, false))
+	<-a.done
+	value, fulfilled = a.value, a.fulfilled
+	a.value = js.Value{}
+	awaiterPool.Put(a)
+	return value, fulfilled
+}
+
+// AwaitSettled awaits the promise, and returns the fulfilled value or the rejected reason as it is.
+//   - This is useful to convert rejected values (e.g. errors of RPC) by callers.
+func AwaitSettled(promiseVal js.Value) (value js.Value, fulfilled bool) {
+	return awaitSettled(promiseVal, BeginAwait())
+}
+
+// awaitPromise awaits promiseVal, and calls endAwait when it's settled.
+func awaitPromise(promiseVal js.Value, endAwait func()) (js.Value, error) {
+	value, fulfilled := awaitSettled(promiseVal, endAwait)
+	if !fulfilled {
+		return js.Value{}, fmt.Errorf("failed on promise: %s", value.Call("toString").String())
+	}
+	return value, nil
+}
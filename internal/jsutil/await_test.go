@@ -0,0 +1,40 @@
+package jsutil
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestAwaitPromise(t *testing.T) {
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				v, err := AwaitPromise(PromiseClass().Call("resolve", i))
+				if err != nil || v.Int() != i {
+					t.Errorf("want %d, got %v, %v", i, v, err)
+				}
+				return
+			}
+			if _, err := AwaitPromise(PromiseClass().Call("reject", ErrorClass().New("rejected"))); err == nil {
+				t.Error("want error of rejected promise")
+			}
+		}(i)
+	}
+	wg.Wait()
+	awaitersMu.Lock()
+	defer awaitersMu.Unlock()
+	if len(awaiters) != 0 {
+		t.Errorf("want settled awaiters to be released, got %d", len(awaiters))
+	}
+}
+
+func TestAwaitSettled(t *testing.T) {
+	v, fulfilled := AwaitSettled(PromiseClass().Call("reject", "reason"))
+	if fulfilled || v.String() != "reason" {
+		t.Errorf("want rejected reason, got %v, %v", v, fulfilled)
+	}
+}
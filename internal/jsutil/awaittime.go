@@ -0,0 +1,114 @@
+package jsutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// AwaitTracker measures the time in which Go was waiting for at least one JavaScript promise.
+//   - Time in which multiple promises were awaited at once is counted once.
+//   - Methods of nil *AwaitTracker return zero values.
+type AwaitTracker struct {
+	mu       sync.Mutex
+	inFlight int
+	start    time.Time
+	total    time.Duration
+	count    int
+}
+
+// Begin marks the start of waiting for a JavaScript promise, and returns the function which marks the end.
+func (t *AwaitTracker) Begin() (end func()) {
+	if t == nil {
+		return func() {}
+	}
+	t.mu.Lock()
+	if t.inFlight == 0 {
+		t.start = time.Now()
+	}
+	t.inFlight++
+	t.count++
+	t.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.inFlight--
+			if t.inFlight == 0 {
+				t.total += time.Since(t.start)
+			}
+		})
+	}
+}
+
+// Total returns the total time in which Go was waiting for at least one JavaScript promise.
+func (t *AwaitTracker) Total() time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	total := t.total
+	if t.inFlight > 0 {
+		total += time.Since(t.start)
+	}
+	return total
+}
+
+// Count returns the number of promises awaited.
+func (t *AwaitTracker) Count() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count
+}
+
+// isolateAwait tracks awaits which are not bound to a context.
+var isolateAwait AwaitTracker
+
+// BeginAwait marks the start of waiting for a JavaScript promise which is not bound to a context,
+// and returns the function which marks the end.
+//   - Functions which wait for promises must call this or BeginAwaitContext, so the time is tracked.
+func BeginAwait() (end func()) {
+	return isolateAwait.Begin()
+}
+
+// IsolateAwaitTracker returns the AwaitTracker of promises which are not bound to a context.
+func IsolateAwaitTracker() *AwaitTracker {
+	return &isolateAwait
+}
+
+// AwaitTime returns the total time in which Go was waiting for promises which are not bound to a context.
+//   - All requests handled by the instance share this time.
+func AwaitTime() time.Duration {
+	return isolateAwait.Total()
+}
+
+type awaitTrackerKey struct{}
+
+// WithAwaitTracker returns the context which holds a new AwaitTracker. if ctx already holds one, returns ctx.
+//   - runtimecontext.New calls this, so each event has its own AwaitTracker.
+func WithAwaitTracker(ctx context.Context) context.Context {
+	if AwaitTrackerFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, awaitTrackerKey{}, &AwaitTracker{})
+}
+
+// AwaitTrackerFromContext returns the AwaitTracker held by ctx. if ctx doesn't hold one, returns nil.
+func AwaitTrackerFromContext(ctx context.Context) *AwaitTracker {
+	t, _ := ctx.Value(awaitTrackerKey{}).(*AwaitTracker)
+	return t
+}
+
+// BeginAwaitContext marks the start of waiting for a JavaScript promise on behalf of ctx.
+//   - The time is tracked by the AwaitTracker held by ctx. if ctx doesn't hold one, this is the same as BeginAwait.
+func BeginAwaitContext(ctx context.Context) (end func()) {
+	if t := AwaitTrackerFromContext(ctx); t != nil {
+		return t.Begin()
+	}
+	return BeginAwait()
+}
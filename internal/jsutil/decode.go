@@ -0,0 +1,223 @@
+package jsutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+var (
+	anyType   = reflect.TypeOf((*any)(nil)).Elem()
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// Decode decodes JavaScript value into the value pointed by out, without serializing the value to JSON.
+// The decoding rules are the reverse of ToJSValue, and similar to encoding/json:
+//   - null and undefined set nil to pointers, interfaces, maps and slices, and leave other values unchanged.
+//   - Boolean, String, Number -> bool, string, numbers (non-integral numbers can't be decoded into integers)
+//   - Uint8Array, ArrayBuffer and base64 String -> []byte
+//   - Date and RFC 3339 String -> time.Time
+//   - Array -> slices and arrays
+//   - Object -> maps with string keys, and structs. field names can be changed by `json` tag, and are matched case-insensitively.
+//     Fields of embedded structs are promoted.
+//   - any values -> the result of ToGoValue
+//   - values implementing json.Unmarshaler (e.g. json.RawMessage) are decoded from the JSON stringified on JavaScript side.
+func Decode(v js.Value, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", out)
+	}
+	return decodeValue(v, rv.Elem())
+}
+
+func decodeValue(v js.Value, rv reflect.Value) error {
+	if rv.Kind() != reflect.Pointer && rv.Type() != timeType && rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(jsonUnmarshaler); ok {
+			return decodeUnmarshaler(v, u)
+		}
+	}
+	if v.IsNull() || v.IsUndefined() {
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			rv.Set(reflect.Zero(rv.Type()))
+		}
+		return nil
+	}
+	switch rv.Type() {
+	case anyType:
+		if goV := ToGoValue(v); goV != nil {
+			rv.Set(reflect.ValueOf(goV))
+		}
+		return nil
+	case timeType:
+		return decodeTime(v, rv)
+	case bytesType:
+		return decodeBytes(v, rv)
+	}
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeValue(v, rv.Elem())
+	case reflect.Bool:
+		if v.Type() != js.TypeBoolean {
+			return typeError(v, rv)
+		}
+		rv.SetBool(v.Bool())
+	case reflect.String:
+		if v.Type() != js.TypeString {
+			return typeError(v, rv)
+		}
+		rv.SetString(v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() != js.TypeNumber {
+			return typeError(v, rv)
+		}
+		f := v.Float()
+		if f != math.Trunc(f) || rv.OverflowInt(int64(f)) {
+			return fmt.Errorf("number %v overflows %s", f, rv.Type())
+		}
+		rv.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Type() != js.TypeNumber {
+			return typeError(v, rv)
+		}
+		f := v.Float()
+		if f < 0 || f != math.Trunc(f) || rv.OverflowUint(uint64(f)) {
+			return fmt.Errorf("number %v overflows %s", f, rv.Type())
+		}
+		rv.SetUint(uint64(f))
+	case reflect.Float32, reflect.Float64:
+		if v.Type() != js.TypeNumber {
+			return typeError(v, rv)
+		}
+		rv.SetFloat(v.Float())
+	case reflect.Slice:
+		if !ArrayClass().Call("isArray", v).Bool() {
+			return typeError(v, rv)
+		}
+		s := reflect.MakeSlice(rv.Type(), v.Length(), v.Length())
+		for i := 0; i < s.Len(); i++ {
+			if err := decodeValue(v.Index(i), s.Index(i)); err != nil {
+				return fmt.Errorf("error decoding index %d: %w", i, err)
+			}
+		}
+		rv.Set(s)
+	case reflect.Array:
+		if !ArrayClass().Call("isArray", v).Bool() {
+			return typeError(v, rv)
+		}
+		for i := 0; i < rv.Len() && i < v.Length(); i++ {
+			if err := decodeValue(v.Index(i), rv.Index(i)); err != nil {
+				return fmt.Errorf("error decoding index %d: %w", i, err)
+			}
+		}
+	case reflect.Map:
+		if v.Type() != js.TypeObject || rv.Type().Key().Kind() != reflect.String {
+			return typeError(v, rv)
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		keys := ObjectClass().Call("keys", v)
+		for i := 0; i < keys.Length(); i++ {
+			k := keys.Index(i).String()
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err := decodeValue(v.Get(k), ev); err != nil {
+				return fmt.Errorf("error decoding value of %q: %w", k, err)
+			}
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), ev)
+		}
+	case reflect.Struct:
+		if v.Type() != js.TypeObject {
+			return typeError(v, rv)
+		}
+		return decodeStruct(v, rv)
+	default:
+		return typeError(v, rv)
+	}
+	return nil
+}
+
+func decodeStruct(v js.Value, rv reflect.Value) error {
+	var keys []string
+	for _, f := range structFields(rv.Type()) {
+		name := f.name
+		fv := v.Get(name)
+		if fv.IsUndefined() {
+			// fall back to case-insensitive match like encoding/json.
+			if keys == nil {
+				keys = toStrings(ObjectClass().Call("keys", v))
+			}
+			for _, k := range keys {
+				if strings.EqualFold(k, name) {
+					fv = v.Get(k)
+					break
+				}
+			}
+		}
+		if fv.IsUndefined() {
+			continue
+		}
+		field, ok := fieldByIndex(rv, f.index, true)
+		if !ok {
+			continue
+		}
+		if err := decodeValue(fv, field); err != nil {
+			return fmt.Errorf("error decoding field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func decodeTime(v js.Value, rv reflect.Value) error {
+	switch {
+	case v.Type() == js.TypeString:
+		t, err := time.Parse(time.RFC3339Nano, v.String())
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(t))
+	case v.Type() == js.TypeObject && v.InstanceOf(DateClass()):
+		t, _ := DateToTime(v)
+		rv.Set(reflect.ValueOf(t))
+	default:
+		return typeError(v, rv)
+	}
+	return nil
+}
+
+func decodeBytes(v js.Value, rv reflect.Value) error {
+	if v.Type() == js.TypeString {
+		b, err := base64.StdEncoding.DecodeString(v.String())
+		if err != nil {
+			return err
+		}
+		rv.SetBytes(b)
+		return nil
+	}
+	b, ok := ToGoValue(v).([]byte)
+	if !ok {
+		return typeError(v, rv)
+	}
+	rv.SetBytes(b)
+	return nil
+}
+
+func toStrings(arr js.Value) []string {
+	s := make([]string, arr.Length())
+	for i := range s {
+		s[i] = arr.Index(i).String()
+	}
+	return s
+}
+
+func typeError(v js.Value, rv reflect.Value) error {
+	return fmt.Errorf("cannot decode %s into %s", v.Type(), rv.Type())
+}
@@ -0,0 +1,162 @@
+package jsutil
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	type item struct {
+		Name  string `json:"name"`
+		Count int
+		Tags  []string          `json:"tags"`
+		Attrs map[string]string `json:"attrs"`
+		Data  []byte            `json:"data"`
+		At    time.Time         `json:"at"`
+		Next  *item             `json:"next"`
+		Any   any               `json:"any"`
+	}
+	at := time.UnixMilli(1700000000000)
+	src := map[string]any{
+		"name":  "a",
+		"count": 2,
+		"tags":  []any{"x", "y"},
+		"attrs": map[string]any{"k": "v"},
+		"data":  []byte("abc"),
+		"at":    at,
+		"next":  map[string]any{"name": "b", "data": "YWJj", "at": "2023-11-14T22:13:20Z"},
+		"any":   map[string]any{"n": 1},
+	}
+	v, err := ToJSValue(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got item
+	if err := Decode(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := item{
+		Name:  "a",
+		Count: 2,
+		Tags:  []string{"x", "y"},
+		Attrs: map[string]string{"k": "v"},
+		Data:  []byte("abc"),
+		At:    at,
+		Next:  &item{Name: "b", Data: []byte("abc"), At: at.UTC()},
+		Any:   map[string]any{"n": float64(1)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	tests := map[string]struct {
+		v   any
+		out any
+	}{
+		"string into int":     {v: "1", out: new(int)},
+		"fraction into int":   {v: 1.5, out: new(int)},
+		"negative into uint":  {v: -1, out: new(uint)},
+		"overflow":            {v: 300, out: new(uint8)},
+		"number into struct":  {v: 1, out: new(struct{})},
+		"object into slice":   {v: map[string]any{}, out: new([]int)},
+		"nested field":        {v: map[string]any{"a": "x"}, out: new(struct{ A int })},
+		"non-pointer":         {v: 1, out: 0},
+		"invalid base64":      {v: "!", out: new([]byte)},
+		"invalid time string": {v: "x", out: new(time.Time)},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			v, err := ToJSValue(tc.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Decode(v, tc.out); err == nil {
+				t.Error("Decode() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestDecode_Null(t *testing.T) {
+	p := &struct{ A int }{A: 1}
+	n := 1
+	if err := Decode(Null, &p); err != nil || p != nil {
+		t.Errorf("Decode(null) into pointer = %v, %v", p, err)
+	}
+	if err := Decode(Null, &n); err != nil || n != 1 {
+		t.Errorf("Decode(null) into int = %v, %v, want unchanged", n, err)
+	}
+}
+
+// upper is marshaled to JSON as the upper case string.
+type upper string
+
+func (u upper) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(u)))
+}
+
+func (u *upper) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*u = upper(strings.ToLower(s))
+	return nil
+}
+
+type EmbeddedBase struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type embeddingItem struct {
+	*EmbeddedBase
+	// Name is shallower than EmbeddedBase.Name, so it is used.
+	Name  string          `json:"name"`
+	Code  upper           `json:"code"`
+	Raw   json.RawMessage `json:"raw"`
+	Empty []string        `json:"empty,omitempty"`
+	Zero  struct{}        `json:"zero,omitempty"`
+}
+
+func TestJSONCompatibility(t *testing.T) {
+	src := &embeddingItem{
+		EmbeddedBase: &EmbeddedBase{ID: "1", Name: "base"},
+		Name:         "item",
+		Code:         "abc",
+		Raw:          json.RawMessage(`{"n":[1,2]}`),
+		Empty:        []string{},
+	}
+	v, err := ToJSValue(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Global.Get("JSON").Call("stringify", v).String()
+	// the same as encoding/json.
+	want, _ := json.Marshal(src)
+	if got != string(want) {
+		t.Errorf("ToJSValue() = %s, want %s", got, want)
+	}
+
+	var decoded embeddingItem
+	if err := Decode(v, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.EmbeddedBase == nil || decoded.ID != "1" || decoded.Name != "item" || decoded.Code != "abc" {
+		t.Errorf("unexpected fields: %+v", decoded)
+	}
+	if string(decoded.Raw) != `{"n":[1,2]}` {
+		t.Errorf("Raw = %s", decoded.Raw)
+	}
+
+	var raw json.RawMessage
+	if err := Decode(Null, &raw); err != nil || string(raw) != "null" {
+		t.Errorf("Decode(null) into json.RawMessage = %s, %v", raw, err)
+	}
+}
@@ -0,0 +1,42 @@
+package jsutil
+
+import (
+	"sync"
+	"syscall/js"
+)
+
+var (
+	featuresMu sync.Mutex
+	features   = map[string]bool{}
+)
+
+// HasGlobal reports whether the global property of the path (e.g. "scheduler", "wait") is defined.
+//   - Results are cached, since globals of the runtime don't change after the startup.
+func HasGlobal(path ...string) bool {
+	key := ""
+	for _, p := range path {
+		key += "." + p
+	}
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	if ok, cached := features[key]; cached {
+		return ok
+	}
+	v := Global
+	for _, p := range path {
+		if v.Type() != js.TypeObject && v.Type() != js.TypeFunction {
+			v = js.Undefined()
+			break
+		}
+		v = v.Get(p)
+	}
+	ok := !v.IsUndefined() && !v.IsNull()
+	features[key] = ok
+	return ok
+}
+
+// SupportsBYOBReader reports whether ReadableStreamBYOBReader is available.
+//   - Byte streams (e.g. bodies of requests) can be read by BYOB readers into reused buffers.
+func SupportsBYOBReader() bool {
+	return HasGlobal("ReadableStreamBYOBReader")
+}
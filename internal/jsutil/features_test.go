@@ -0,0 +1,73 @@
+package jsutil
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestHasGlobal(t *testing.T) {
+	if !HasGlobal("Promise", "resolve") {
+		t.Error("Promise.resolve must be defined")
+	}
+	for _, path := range [][]string{{"NoSuchGlobal"}, {"NoSuchGlobal", "child"}, {"Promise", "noSuchMethod"}} {
+		if HasGlobal(path...) {
+			t.Errorf("%v must not be defined", path)
+		}
+	}
+}
+
+func TestConvertBodyStreamToReader(t *testing.T) {
+	newStream := Global.Get("Function").New("type", `
+		const chunks = ["hello, ", "byte ", "stream"];
+		return new ReadableStream({
+			type: type || undefined,
+			pull(controller) {
+				const chunk = chunks.shift();
+				if (chunk === undefined) {
+					controller.close();
+					// a pending read of BYOB readers is resolved as done by responding 0 bytes.
+					controller.byobRequest?.respond(0);
+					return;
+				}
+				controller.enqueue(new TextEncoder().encode(chunk));
+			},
+		});`)
+	for _, typ := range []any{"bytes", nil} {
+		r := ConvertBodyStreamToReader(newStream.Invoke(typ))
+		if _, isBYOB := r.(*byobReaderToReader); isBYOB != (typ != nil && SupportsBYOBReader()) {
+			t.Errorf("type %v: unexpected reader %T", typ, r)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("type %v: %v", typ, err)
+		}
+		if got, want := string(b), "hello, byte stream"; got != want {
+			t.Errorf("type %v: want %q, got %q", typ, want, got)
+		}
+	}
+}
+
+func TestBYOBReaderShortBuffer(t *testing.T) {
+	if !SupportsBYOBReader() {
+		t.Skip("ReadableStreamBYOBReader is not available")
+	}
+	stream := Global.Get("Function").New(`
+		return new Response("0123456789").body;`).Invoke()
+	r := ConvertBodyStreamToReader(stream)
+	var sb strings.Builder
+	p := make([]byte, 3)
+	for {
+		n, err := r.Read(p)
+		sb.Write(p[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := sb.String(); got != "0123456789" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,193 @@
+package jsutil
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall/js"
+)
+
+// jsonMarshaler and jsonUnmarshaler are json.Marshaler and json.Unmarshaler.
+// They are defined here to honour custom marshaling of values (e.g. json.RawMessage) without linking encoding/json.
+type jsonMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+type jsonUnmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+// marshalerToJSValue converts the value implementing json.Marshaler by parsing its JSON on JavaScript side.
+func marshalerToJSValue(m jsonMarshaler) (js.Value, error) {
+	if rv := reflect.ValueOf(m); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return Null, nil
+	}
+	b, err := m.MarshalJSON()
+	if err != nil {
+		return js.Value{}, err
+	}
+	v, err := TryCall(JSONClass(), "parse", string(b))
+	if err != nil {
+		return js.Value{}, fmt.Errorf("invalid JSON from MarshalJSON of %T: %w", m, err)
+	}
+	return v, nil
+}
+
+// decodeUnmarshaler decodes JavaScript value by the UnmarshalJSON method of u, with the JSON stringified on JavaScript side.
+func decodeUnmarshaler(v js.Value, u jsonUnmarshaler) error {
+	if v.IsUndefined() {
+		return nil
+	}
+	s, err := TryCall(JSONClass(), "stringify", v)
+	if err != nil {
+		return err
+	}
+	if s.Type() != js.TypeString {
+		return fmt.Errorf("cannot decode %s into %T", v.Type(), u)
+	}
+	return u.UnmarshalJSON([]byte(s.String()))
+}
+
+// structField is the field of a struct which is converted to the property of the name.
+type structField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+}
+
+// structFields returns fields of the struct type in the same manner as encoding/json.
+//   - Fields of embedded structs without names in `json` tag are promoted to the struct.
+//   - if fields of the same name exist, the shallowest one is used. Among them, the tagged one is used.
+//     if the field is still ambiguous, all of them are ignored.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	visited := map[reflect.Type]bool{}
+	type entry struct {
+		t     reflect.Type
+		index []int
+	}
+	current := []entry{{t: t}}
+	for len(current) > 0 {
+		var next []entry
+		var level []structField
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				index := append(append([]int(nil), e.index...), i)
+				tag := f.Tag.Get("json")
+				ft := f.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if f.Anonymous && ft.Kind() == reflect.Struct && tag != "-" && strings.Split(tag, ",")[0] == "" {
+					next = append(next, entry{t: ft, index: index})
+					continue
+				}
+				if !f.IsExported() {
+					continue
+				}
+				name, omitEmpty, skip := fieldName(f)
+				if skip {
+					continue
+				}
+				level = append(level, structField{
+					name:      name,
+					index:     index,
+					omitEmpty: omitEmpty,
+					tagged:    strings.Split(tag, ",")[0] != "",
+				})
+			}
+		}
+		for _, f := range dominantFields(level) {
+			if !hasField(fields, f.name) {
+				fields = append(fields, f)
+			}
+		}
+		current = next
+	}
+	// properties are ordered by the position of fields like encoding/json.
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// dominantFields removes ambiguous fields of the same depth.
+func dominantFields(level []structField) []structField {
+	var result []structField
+	for i, f := range level {
+		dominant := true
+		for j, g := range level {
+			if i == j || g.name != f.name {
+				continue
+			}
+			if f.tagged == g.tagged || g.tagged {
+				dominant = false
+				break
+			}
+		}
+		if dominant {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func hasField(fields []structField, name string) bool {
+	for _, f := range fields {
+		if f.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldByIndex returns the field of the index. if alloc is true, nil pointers of embedded structs are allocated.
+// if alloc is false and the field is unreachable by nil pointers, ok is false.
+func fieldByIndex(rv reflect.Value, index []int, alloc bool) (v reflect.Value, ok bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				// pointers to unexported embedded structs can't be allocated, like encoding/json.
+				if !alloc || !rv.CanSet() {
+					return reflect.Value{}, false
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// isEmptyValue reports whether the value is omitted by `omitempty` in the same manner as encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package jsutil
+
+import (
+	"encoding/binary"
+	"math"
+	"syscall/js"
+)
+
+var Float32ArrayClass = LazyGlobal("Float32Array")
+
+// Float32SliceToJS converts []float32 to JavaScript side's Float32Array by copying bytes at once.
+func Float32SliceToJS(s []float32) js.Value {
+	b := make([]byte, len(s)*4)
+	for i, f := range s {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(f))
+	}
+	ua := NewUint8Array(len(b))
+	js.CopyBytesToJS(ua, b)
+	return Float32ArrayClass().New(ua.Get("buffer"))
+}
+
+// ToFloat32Slice converts JavaScript side's number Array or Float32Array to []float32.
+//   - the values are copied at once through Float32Array's buffer, instead of getting each element.
+func ToFloat32Slice(v js.Value) []float32 {
+	if !v.InstanceOf(Float32ArrayClass()) {
+		v = Float32ArrayClass().New(v)
+	}
+	ua := Uint8ArrayClass().New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+	b := make([]byte, ua.Get("byteLength").Int())
+	js.CopyBytesToGo(b, ua)
+	s := make([]float32, len(b)/4)
+	for i := range s {
+		s[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+	}
+	return s
+}
@@ -0,0 +1,23 @@
+package jsutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFloat32Slice(t *testing.T) {
+	want := []float32{0, 1.5, -2.25, 3.4e38}
+	got := ToFloat32Slice(Float32SliceToJS(want))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToFloat32Slice() = %v, want %v", got, want)
+	}
+}
+
+func TestToFloat32Slice_array(t *testing.T) {
+	arr := ArrayClass().New(0.5, 1, 2)
+	got := ToFloat32Slice(arr)
+	want := []float32{0.5, 1, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToFloat32Slice() = %v, want %v", got, want)
+	}
+}
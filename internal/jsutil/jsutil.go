@@ -1,71 +1,109 @@
 package jsutil
 
 import (
-	"fmt"
+	"context"
+	"sync"
 	"syscall/js"
 	"time"
 )
 
 var (
-	Global              = js.Global()
-	ObjectClass         = Global.Get("Object")
-	PromiseClass        = Global.Get("Promise")
-	RequestClass        = Global.Get("Request")
-	ResponseClass       = Global.Get("Response")
-	HeadersClass        = Global.Get("Headers")
-	ArrayClass          = Global.Get("Array")
-	Uint8ArrayClass     = Global.Get("Uint8Array")
-	ErrorClass          = Global.Get("Error")
-	ReadableStreamClass = Global.Get("ReadableStream")
-	DateClass           = Global.Get("Date")
-	Null                = js.ValueOf(nil)
+	Global = js.Global()
+	Null   = js.ValueOf(nil)
+)
+
+// JavaScript classes are looked up on the first use to reduce the cost of cold starts.
+var (
+	ObjectClass         = LazyGlobal("Object")
+	PromiseClass        = LazyGlobal("Promise")
+	RequestClass        = LazyGlobal("Request")
+	ResponseClass       = LazyGlobal("Response")
+	HeadersClass        = LazyGlobal("Headers")
+	ArrayClass          = LazyGlobal("Array")
+	Uint8ArrayClass     = LazyGlobal("Uint8Array")
+	ErrorClass          = LazyGlobal("Error")
+	ReadableStreamClass = LazyGlobal("ReadableStream")
+	DateClass           = LazyGlobal("Date")
+	JSONClass           = LazyGlobal("JSON")
+	StringFunc          = LazyGlobal("String")
+	reflectConstruct    = LazyGlobal("Reflect", "construct")
 )
 
 func NewObject() js.Value {
-	return ObjectClass.New()
+	return ObjectClass().New()
 }
 
 func NewUint8Array(size int) js.Value {
-	return Uint8ArrayClass.New(size)
+	return Uint8ArrayClass().New(size)
 }
 
 func NewPromise(fn js.Func) js.Value {
-	return PromiseClass.New(fn)
+	return PromiseClass().New(fn)
+}
+
+var (
+	resolversMu          sync.Mutex
+	capturedResolve      js.Value
+	capturedReject       js.Value
+	captureResolversOnce sync.Once
+	captureResolvers     js.Func
+)
+
+// NewPromiseWithResolvers returns a pending Promise with the functions which resolve and reject it.
+//   - Promise.withResolvers is used if it's available. Otherwise, a long-lived executor captures the functions,
+//     so no js.Func is allocated for each Promise.
+func NewPromiseWithResolvers() (promise, resolve, reject js.Value) {
+	if HasGlobal("Promise", "withResolvers") {
+		r := PromiseClass().Call("withResolvers")
+		return r.Get("promise"), r.Get("resolve"), r.Get("reject")
+	}
+	// The executor is called synchronously by the constructor, so the captured functions belong to this Promise.
+	captureResolversOnce.Do(func() {
+		captureResolvers = js.FuncOf(func(_ js.Value, args []js.Value) any {
+			capturedResolve, capturedReject = args[0], args[1]
+			return js.Undefined()
+		})
+	})
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	promise = PromiseClass().New(captureResolvers)
+	resolve, reject = capturedResolve, capturedReject
+	capturedResolve, capturedReject = js.Value{}, js.Value{}
+	return promise, resolve, reject
+}
+
+// RunAsPromise runs fn in a new goroutine and returns a Promise settled with the result of fn.
+//   - if fn returns an error, the Promise is rejected with an Error which has the error message.
+func RunAsPromise(fn func() (js.Value, error)) js.Value {
+	promise, resolve, reject := NewPromiseWithResolvers()
+	go func() {
+		v, err := fn()
+		if err != nil {
+			reject.Invoke(ErrorClass().New(err.Error()))
+			return
+		}
+		resolve.Invoke(v)
+	}()
+	return promise
 }
 
 // ArrayFrom calls Array.from to given argument and returns result Array.
 func ArrayFrom(v js.Value) js.Value {
-	return ArrayClass.Call("from", v)
+	return ArrayClass().Call("from", v)
 }
 
 func AwaitPromise(promiseVal js.Value) (js.Value, error) {
-	resultCh := make(chan js.Value)
-	errCh := make(chan error)
-	var then, catch js.Func
-	then = js.FuncOf(func(_ js.Value, args []js.Value) any {
-		defer then.Release()
-		result := args[0]
-		resultCh <- result
-		return js.Undefined()
-	})
-	catch = js.FuncOf(func(_ js.Value, args []js.Value) any {
-		defer catch.Release()
-		result := args[0]
-		errCh <- fmt.Errorf("failed on promise: %s", result.Call("toString").String())
-		return js.Undefined()
-	})
-	promiseVal.Call("then", then).Call("catch", catch)
-	select {
-	case result := <-resultCh:
-		return result, nil
-	case err := <-errCh:
-		return js.Value{}, err
-	}
+	return awaitPromise(promiseVal, BeginAwait())
+}
+
+// AwaitPromiseContext is the same as AwaitPromise, but the waiting time is tracked on behalf of ctx.
+func AwaitPromiseContext(ctx context.Context, promiseVal js.Value) (js.Value, error) {
+	return awaitPromise(promiseVal, BeginAwaitContext(ctx))
 }
 
 // StrRecordToMap converts JavaScript side's Record<string, string> into map[string]string.
 func StrRecordToMap(v js.Value) map[string]string {
-	entries := ObjectClass.Call("entries", v)
+	entries := ObjectClass().Call("entries", v)
 	entriesLen := entries.Get("length").Int()
 	result := make(map[string]string, entriesLen)
 	for i := 0; i < entriesLen; i++ {
@@ -101,5 +139,5 @@ func DateToTime(v js.Value) (time.Time, error) {
 
 // TimeToDate converts Go side's time.Time into Date object.
 func TimeToDate(t time.Time) js.Value {
-	return DateClass.New(t.UnixMilli())
+	return DateClass().New(t.UnixMilli())
 }
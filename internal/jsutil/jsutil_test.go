@@ -0,0 +1,52 @@
+package jsutil
+
+import (
+	"errors"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// withoutPromiseWithResolvers makes NewPromiseWithResolvers use the executor which captures the functions.
+func withoutPromiseWithResolvers(t *testing.T) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	orig, cached := features[".Promise.withResolvers"]
+	features[".Promise.withResolvers"] = false
+	t.Cleanup(func() {
+		featuresMu.Lock()
+		defer featuresMu.Unlock()
+		if cached {
+			features[".Promise.withResolvers"] = orig
+		} else {
+			delete(features, ".Promise.withResolvers")
+		}
+	})
+}
+
+func TestRunAsPromise(t *testing.T) {
+	tests := map[string]func(t *testing.T){
+		"default":                       func(*testing.T) {},
+		"without Promise.withResolvers": withoutPromiseWithResolvers,
+	}
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			setup(t)
+			v, err := AwaitPromise(RunAsPromise(func() (js.Value, error) {
+				return js.ValueOf("ok"), nil
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != "ok" {
+				t.Errorf("want ok, got %q", v.String())
+			}
+			_, err = AwaitPromise(RunAsPromise(func() (js.Value, error) {
+				return js.Value{}, errors.New("failed")
+			}))
+			if err == nil || !strings.Contains(err.Error(), "failed") {
+				t.Errorf("want rejection with the error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package jsutil
+
+import (
+	"sync"
+	"syscall/js"
+)
+
+var (
+	lazyMu        sync.Mutex
+	lazyValues    []func() js.Value
+	lazyCallbacks []func()
+)
+
+// LazyGlobal returns the function which looks up the global property by the path (e.g. "crypto", "subtle")
+// on the first call, and returns the cached value after that.
+//   - Lookups are deferred to the first use, so imported but unused classes don't cost on cold starts.
+//   - Warmup looks up all values returned by LazyGlobal at once.
+func LazyGlobal(path ...string) func() js.Value {
+	var (
+		once sync.Once
+		v    js.Value
+	)
+	get := func() js.Value {
+		once.Do(func() {
+			v = Global
+			for _, p := range path {
+				v = v.Get(p)
+			}
+		})
+		return v
+	}
+	lazyMu.Lock()
+	lazyValues = append(lazyValues, get)
+	lazyMu.Unlock()
+	return get
+}
+
+// LazyRegister returns the function which sets fn as the global function of the name called by the JavaScript side
+// (e.g. `handleQueue` called by shim.mjs). fn is set only on the first call of the returned function.
+//   - Packages call the returned function when their handlers are registered (e.g. queues.ConsumeNonBlock),
+//     so workers which don't use a package don't pay for js.FuncOf and the global on cold starts.
+//   - Warmup sets all functions returned by LazyRegister at once.
+func LazyRegister(name string, fn func(this js.Value, args []js.Value) any) (register func()) {
+	var once sync.Once
+	register = func() {
+		once.Do(func() {
+			Global.Set(name, js.FuncOf(fn))
+		})
+	}
+	lazyMu.Lock()
+	lazyCallbacks = append(lazyCallbacks, register)
+	lazyMu.Unlock()
+	return register
+}
+
+// Warmup looks up all values returned by LazyGlobal, and sets all callbacks of LazyRegister.
+func Warmup() {
+	lazyMu.Lock()
+	values := append([]func() js.Value(nil), lazyValues...)
+	callbacks := append([]func(){}, lazyCallbacks...)
+	lazyMu.Unlock()
+	for _, get := range values {
+		get()
+	}
+	for _, register := range callbacks {
+		register()
+	}
+}
@@ -0,0 +1,43 @@
+package jsutil
+
+import (
+	"syscall/js"
+	"testing"
+)
+
+func TestLazyGlobal(t *testing.T) {
+	obj := NewObject()
+	obj.Set("value", "first")
+	Global.Set("lazyTestObject", obj)
+	t.Cleanup(func() {
+		Global.Delete("lazyTestObject")
+	})
+
+	get := LazyGlobal("lazyTestObject", "value")
+	Warmup()
+	obj.Set("value", "second")
+	if got := get().String(); got != "first" {
+		t.Errorf("want value looked up by Warmup, got %q", got)
+	}
+}
+
+func TestLazyRegister(t *testing.T) {
+	t.Cleanup(func() {
+		Global.Delete("lazyTestCallback")
+	})
+	register := LazyRegister("lazyTestCallback", func(js.Value, []js.Value) any {
+		return "called"
+	})
+	if !Global.Get("lazyTestCallback").IsUndefined() {
+		t.Fatal("the callback must not be set before the registration")
+	}
+	register()
+	fn := Global.Get("lazyTestCallback")
+	register()
+	if !Global.Get("lazyTestCallback").Equal(fn) {
+		t.Error("the callback must be set only once")
+	}
+	if got := fn.Invoke().String(); got != "called" {
+		t.Errorf("unexpected result: %q", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"sync"
 	"syscall/js"
 )
 
@@ -42,52 +43,54 @@ func ConvertReadableStreamToReader(sr js.Value) io.Reader {
 // streamReaderToReader implements io.Reader sourced from ReadableStreamDefaultReader.
 //   - ReadableStreamDefaultReader: https://developer.mozilla.org/en-US/docs/Web/API/ReadableStreamDefaultReader
 //   - This implementation is based on: https://deno.land/std@0.139.0/streams/conversion.ts#L76
+//   - The next chunk is read in advance while the consumer processes the previous one,
+//     so the latency of reads overlaps with processing on Go side.
 type streamReaderToReader struct {
 	buf          bytes.Buffer
 	streamReader js.Value
+	// pending is the read started in advance. This is undefined if no read is in flight.
+	pending js.Value
+	err     error
 }
 
 // Read reads bytes from ReadableStreamDefaultReader.
 func (sr *streamReaderToReader) Read(p []byte) (n int, err error) {
 	if sr.buf.Len() == 0 {
-		promise := sr.streamReader.Call("read")
-		resultCh := make(chan js.Value)
-		errCh := make(chan error)
-		var then, catch js.Func
-		then = js.FuncOf(func(_ js.Value, args []js.Value) any {
-			defer then.Release()
-			result := args[0]
-			if result.Get("done").Bool() {
-				errCh <- io.EOF
-				return js.Undefined()
-			}
-			resultCh <- result.Get("value")
-			return js.Undefined()
-		})
-		catch = js.FuncOf(func(_ js.Value, args []js.Value) any {
-			defer catch.Release()
-			result := args[0]
-			errCh <- fmt.Errorf("JavaScript error on read: %s", result.Call("toString").String())
-			return js.Undefined()
-		})
-		promise.Call("then", then).Call("catch", catch)
-		select {
-		case result := <-resultCh:
-			chunk := make([]byte, result.Get("byteLength").Int())
-			_ = js.CopyBytesToGo(chunk, result)
-			// The length written is always the same as the length of chunk, so it can be discarded.
-			//   - https://pkg.go.dev/bytes#Buffer.Write
-			_, err := sr.buf.Write(chunk)
-			if err != nil {
-				return 0, err
-			}
-		case err := <-errCh:
+		if sr.err != nil {
+			return 0, sr.err
+		}
+		if err := sr.fill(); err != nil {
+			sr.err = err
 			return 0, err
 		}
 	}
 	return sr.buf.Read(p)
 }
 
+// fill awaits the pending read, and starts the next read before copying the chunk into buf.
+func (sr *streamReaderToReader) fill() error {
+	promise := sr.pending
+	if promise.IsUndefined() {
+		promise = sr.streamReader.Call("read")
+	}
+	sr.pending = js.Undefined()
+	result, fulfilled := awaitSettled(promise, BeginAwait())
+	if !fulfilled {
+		return fmt.Errorf("JavaScript error on read: %s", result.Call("toString").String())
+	}
+	if result.Get("done").Bool() {
+		return io.EOF
+	}
+	sr.pending = sr.streamReader.Call("read")
+	value := result.Get("value")
+	chunk := make([]byte, value.Get("byteLength").Int())
+	_ = js.CopyBytesToGo(chunk, value)
+	// The length written is always the same as the length of chunk, so it can be discarded.
+	//   - https://pkg.go.dev/bytes#Buffer.Write
+	_, err := sr.buf.Write(chunk)
+	return err
+}
+
 // ConvertStreamReaderToReader converts ReadableStreamDefaultReader to io.Reader.
 func ConvertStreamReaderToReader(sr js.Value) io.Reader {
 	return &streamReaderToReader{
@@ -95,6 +98,103 @@ func ConvertStreamReaderToReader(sr js.Value) io.Reader {
 	}
 }
 
+// byobReaderToReader implements io.Reader sourced from ReadableStreamBYOBReader.
+//   - ReadableStreamBYOBReader: https://developer.mozilla.org/en-US/docs/Web/API/ReadableStreamBYOBReader
+//   - Buffers are transferred to the stream on each read and returned with the result, so they are reused
+//     instead of allocating a chunk for each read.
+//   - The next chunk is read in advance into another buffer while the consumer processes the previous one,
+//     so the latency of reads overlaps with processing on Go side.
+type byobReaderToReader struct {
+	streamReader js.Value
+	// bufs are ArrayBuffers which are not used by reads.
+	bufs []js.Value
+	// pending is the read started in advance. This is undefined if no read is in flight.
+	pending js.Value
+	// rest is the part of the last chunk which didn't fit into p of Read.
+	rest    []byte
+	restBuf []byte
+	err     error
+}
+
+// read starts reading into a free buffer.
+func (br *byobReaderToReader) read() js.Value {
+	var buf js.Value
+	if n := len(br.bufs); n > 0 {
+		buf = br.bufs[n-1]
+		br.bufs = br.bufs[:n-1]
+	} else {
+		buf = ArrayBufferClass().New(defaultChunkSize)
+	}
+	return br.streamReader.Call("read", Uint8ArrayClass().New(buf))
+}
+
+// Read reads bytes from ReadableStreamBYOBReader.
+func (br *byobReaderToReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(br.rest) > 0 {
+		n = copy(p, br.rest)
+		br.rest = br.rest[n:]
+		return n, nil
+	}
+	if br.err != nil {
+		return 0, br.err
+	}
+	promise := br.pending
+	if promise.IsUndefined() {
+		promise = br.read()
+	}
+	br.pending = js.Undefined()
+	result, fulfilled := awaitSettled(promise, BeginAwait())
+	if !fulfilled {
+		br.err = fmt.Errorf("JavaScript error on read: %s", result.Call("toString").String())
+		return 0, br.err
+	}
+	done := result.Get("done").Bool()
+	if !done {
+		br.pending = br.read()
+	}
+	value := result.Get("value")
+	if value.IsUndefined() {
+		br.err = io.EOF
+		return 0, br.err
+	}
+	size := value.Get("byteLength").Int()
+	n = js.CopyBytesToGo(p, value)
+	if n < size {
+		if br.restBuf == nil {
+			br.restBuf = make([]byte, defaultChunkSize)
+		}
+		br.rest = br.restBuf[:js.CopyBytesToGo(br.restBuf, value.Call("subarray", n))]
+	}
+	br.bufs = append(br.bufs, value.Get("buffer"))
+	if done {
+		br.err = io.EOF
+		if n == 0 {
+			return 0, io.EOF
+		}
+	}
+	return n, nil
+}
+
+// ConvertBodyStreamToReader converts ReadableStream of the body of Request or Response to io.Reader.
+//   - Byte streams are read by ReadableStreamBYOBReader if it is available. Other streams are read by
+//     ReadableStreamDefaultReader.
+//   - Byte streams created by user code must call `controller.byobRequest.respond(0)` after `controller.close()`,
+//     otherwise the pending read of the BYOB reader is never resolved. Bodies created by the runtime do it.
+func ConvertBodyStreamToReader(stream js.Value) io.Reader {
+	if SupportsBYOBReader() {
+		opts := NewObject()
+		opts.Set("mode", "byob")
+		// getReader throws TypeError if the stream is not a byte stream.
+		if sr, err := TryCall(stream, "getReader", opts); err == nil {
+			return &byobReaderToReader{streamReader: sr}
+		}
+	}
+	return ConvertStreamReaderToReader(stream.Call("getReader"))
+}
+
 // readerToReadableStream implements ReadableStream sourced from io.ReadCloser.
 //   - ReadableStream: https://developer.mozilla.org/docs/Web/API/ReadableStream
 //   - This implementation is based on: https://deno.land/std@0.139.0/streams/conversion.ts#L230
@@ -103,9 +203,9 @@ type readerToReadableStream struct {
 	chunkBuf []byte
 }
 
-// Pull implements ReadableStream's pull method.
+// Pull implements ReadableStream's pull method. done is true if the stream is closed or errored.
 //   - https://developer.mozilla.org/en-US/docs/Web/API/ReadableStream/ReadableStream#pull
-func (rs *readerToReadableStream) Pull(controller js.Value) error {
+func (rs *readerToReadableStream) Pull(controller js.Value) (done bool, err error) {
 	n, err := rs.reader.Read(rs.chunkBuf)
 	if n != 0 {
 		ua := NewUint8Array(n)
@@ -116,20 +216,17 @@ func (rs *readerToReadableStream) Pull(controller js.Value) error {
 	// When the call happens, `io.ErrClosedPipe` should be ignored.
 	if err == io.EOF || err == io.ErrClosedPipe {
 		controller.Call("close")
-		if err := rs.reader.Close(); err != nil {
-			return err
-		}
-		return nil
+		return true, rs.reader.Close()
 	}
 	if err != nil {
-		jsErr := ErrorClass.New(err.Error())
+		jsErr := ErrorClass().New(err.Error())
 		controller.Call("error", jsErr)
 		if err := rs.reader.Close(); err != nil {
-			return err
+			return true, err
 		}
-		return err
+		return true, err
 	}
-	return nil
+	return false, nil
 }
 
 // Cancel implements ReadableStream's cancel method.
@@ -141,36 +238,77 @@ func (rs *readerToReadableStream) Cancel() error {
 // https://deno.land/std@0.139.0/streams/conversion.ts#L5
 const defaultChunkSize = 16_640
 
+var (
+	streamsMu    sync.Mutex
+	streams      = map[int]*readerToReadableStream{}
+	lastStreamID int
+
+	streamCallbacksOnce sync.Once
+	streamPull          js.Func
+	streamCancel        js.Func
+)
+
+// streamIDKey is the key of the underlying source which holds the ID of readerToReadableStream.
+const streamIDKey = "goStreamID"
+
+// underlyingStream returns readerToReadableStream of the underlying source.
+//   - ReadableStream calls `pull` and `cancel` with the underlying source as `this`.
+//   - if release is true, the stream is removed, since no more `pull` is called for it.
+func underlyingStream(source js.Value, release bool) *readerToReadableStream {
+	id := source.Get(streamIDKey).Int()
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	stream := streams[id]
+	if release {
+		delete(streams, id)
+	}
+	return stream
+}
+
+// initStreamCallbacks creates `pull` and `cancel` shared by all readerToReadableStream,
+// so no js.Func is allocated for each stream.
+func initStreamCallbacks() {
+	streamPull = js.FuncOf(func(this js.Value, args []js.Value) any {
+		stream := underlyingStream(this, false)
+		if stream == nil {
+			return js.Undefined()
+		}
+		done, err := stream.Pull(args[0])
+		if done {
+			underlyingStream(this, true)
+		}
+		if err != nil {
+			return PromiseClass().Call("reject", ErrorClass().New(err.Error()))
+		}
+		return js.Undefined()
+	})
+	streamCancel = js.FuncOf(func(this js.Value, _ []js.Value) any {
+		stream := underlyingStream(this, true)
+		if stream == nil {
+			return js.Undefined()
+		}
+		if err := stream.Cancel(); err != nil {
+			panic(err)
+		}
+		return js.Undefined()
+	})
+}
+
 // ConvertReaderToReadableStream converts io.ReadCloser to ReadableStream.
 func ConvertReaderToReadableStream(reader io.ReadCloser) js.Value {
+	streamCallbacksOnce.Do(initStreamCallbacks)
 	stream := &readerToReadableStream{
 		reader:   reader,
 		chunkBuf: make([]byte, defaultChunkSize),
 	}
+	streamsMu.Lock()
+	lastStreamID++
+	id := lastStreamID
+	streams[id] = stream
+	streamsMu.Unlock()
 	rsInit := NewObject()
-	rsInit.Set("pull", js.FuncOf(func(_ js.Value, args []js.Value) any {
-		var cb js.Func
-		cb = js.FuncOf(func(this js.Value, pArgs []js.Value) any {
-			defer cb.Release()
-			resolve := pArgs[0]
-			reject := pArgs[1]
-			controller := args[0]
-			err := stream.Pull(controller)
-			if err != nil {
-				reject.Invoke(ErrorClass.New(err.Error()))
-				return js.Undefined()
-			}
-			resolve.Invoke()
-			return js.Undefined()
-		})
-		return NewPromise(cb)
-	}))
-	rsInit.Set("cancel", js.FuncOf(func(js.Value, []js.Value) any {
-		err := stream.Cancel()
-		if err != nil {
-			panic(err)
-		}
-		return js.Undefined()
-	}))
-	return ReadableStreamClass.New(rsInit)
+	rsInit.Set(streamIDKey, id)
+	rsInit.Set("pull", streamPull)
+	rsInit.Set("cancel", streamCancel)
+	return ReadableStreamClass().New(rsInit)
 }
@@ -0,0 +1,91 @@
+package jsutil
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestReadableStreamRoundTrip(t *testing.T) {
+	want := bytes.Repeat([]byte("0123456789"), defaultChunkSize/5)
+	stream := ConvertReaderToReadableStream(io.NopCloser(bytes.NewReader(want)))
+	got, err := io.ReadAll(ConvertStreamReaderToReader(stream.Call("getReader")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("want %d bytes, got %d bytes", len(want), len(got))
+	}
+	streamsMu.Lock()
+	defer streamsMu.Unlock()
+	if len(streams) != 0 {
+		t.Errorf("want closed streams to be released, got %d", len(streams))
+	}
+}
+
+func TestStreamReaderPrefetch(t *testing.T) {
+	newStream := Global.Get("Function").New("type", "state", `
+		return new ReadableStream({
+			type: type || undefined,
+			pull(controller) {
+				state.pulls++;
+				if (state.pulls > 3) {
+					controller.close();
+					controller.byobRequest?.respond(0);
+					return;
+				}
+				controller.enqueue(new TextEncoder().encode("chunk"));
+			},
+		}, { highWaterMark: 0 });`)
+	for _, typ := range []any{"bytes", nil} {
+		state := NewObject()
+		state.Set("pulls", 0)
+		r := ConvertBodyStreamToReader(newStream.Invoke(typ, state))
+		p := make([]byte, 5)
+		if _, err := r.Read(p); err != nil {
+			t.Fatalf("type %v: %v", typ, err)
+		}
+		// the read of the next chunk is started before the first chunk is returned.
+		// pull of default streams is called asynchronously, so a microtask is awaited.
+		if _, err := AwaitPromise(PromiseClass().Call("resolve")); err != nil {
+			t.Fatal(err)
+		}
+		if pulls := state.Get("pulls").Int(); pulls != 2 {
+			t.Errorf("type %v: want the next chunk to be read in advance, got %d pulls", typ, pulls)
+		}
+		rest, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("type %v: %v", typ, err)
+		}
+		if got := string(p) + string(rest); got != "chunkchunkchunk" {
+			t.Errorf("type %v: got %q", typ, got)
+		}
+	}
+}
+
+func BenchmarkReadableStreamRoundTrip(b *testing.B) {
+	data := bytes.Repeat([]byte("0123456789"), 1<<16)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stream := ConvertReaderToReadableStream(io.NopCloser(bytes.NewReader(data)))
+		if _, err := io.Copy(io.Discard, ConvertBodyStreamToReader(stream)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBYOBReader(b *testing.B) {
+	if !SupportsBYOBReader() {
+		b.Skip("ReadableStreamBYOBReader is not available")
+	}
+	newBody := Global.Get("Function").New("size", `return new Response(new Uint8Array(size)).body;`)
+	const size = 1 << 20
+	b.SetBytes(size)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := io.Copy(io.Discard, ConvertBodyStreamToReader(newBody.Invoke(size))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
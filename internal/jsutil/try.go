@@ -0,0 +1,63 @@
+package jsutil
+
+import (
+	"fmt"
+	"syscall/js"
+)
+
+// Try calls fn and returns JavaScript exception thrown in fn as error.
+//   - syscall/js panics with js.Error when a JavaScript function throws an exception.
+//   - panics other than js.Error are not recovered.
+//   - TinyGo doesn't support recover on WebAssembly, so the exception aborts the program there.
+//     Use TryCall and TryNew for calls which may throw.
+func Try(fn func()) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		jsErr, ok := r.(js.Error)
+		if !ok {
+			panic(r)
+		}
+		err = toError(jsErr.Value)
+	}()
+	fn()
+	return nil
+}
+
+// TryCall calls the method of v with args, and returns JavaScript exception thrown by the method as error.
+//   - The exception is caught by `tryCall` defined in shim.mjs, so TryCall works on TinyGo.
+//   - if `tryCall` is not defined (e.g. in tests running on Node.js), TryCall falls back to Try.
+func TryCall(v js.Value, method string, args ...any) (js.Value, error) {
+	return tryApply(func() js.Value { return v.Call(method, args...) }, v.Get(method), v, args)
+}
+
+// TryNew calls the constructor with args, and returns JavaScript exception thrown by the constructor as error.
+//   - See TryCall for the way to catch the exception.
+func TryNew(class js.Value, args ...any) (js.Value, error) {
+	return tryApply(func() js.Value { return class.New(args...) }, reflectConstruct(), Null, []any{class, args})
+}
+
+// tryApply calls fn with thisArg and args by `tryCall`, or calls fallback by Try if `tryCall` is not defined.
+//   - `tryCall` is looked up on each call instead of being cached, since it's defined by shim.mjs (or tests) and can be absent.
+func tryApply(fallback func() js.Value, fn, thisArg js.Value, args []any) (js.Value, error) {
+	tryCall := Global.Get("tryCall")
+	if tryCall.Type() != js.TypeFunction {
+		var v js.Value
+		err := Try(func() {
+			v = fallback()
+		})
+		return v, err
+	}
+	result := tryCall.Invoke(fn, thisArg, args)
+	if !result.Get("ok").Bool() {
+		return js.Value{}, toError(result.Get("error"))
+	}
+	return result.Get("value"), nil
+}
+
+// toError converts the thrown JavaScript value to error.
+func toError(v js.Value) error {
+	return fmt.Errorf("JavaScript error: %s", StringFunc().Invoke(v).String())
+}
@@ -0,0 +1,68 @@
+package jsutil
+
+import (
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+// installTryCall defines `tryCall` of shim.mjs, which is not defined on Node.js.
+func installTryCall(t *testing.T) {
+	t.Helper()
+	fn := Global.Get("Function").New("fn", "thisArg", "args", `
+		try {
+			return { ok: true, value: Reflect.apply(fn, thisArg, args) };
+		} catch (e) {
+			return { ok: false, error: e };
+		}`)
+	Global.Set("tryCall", fn)
+	t.Cleanup(func() {
+		Global.Delete("tryCall")
+	})
+}
+
+func TestTryCall(t *testing.T) {
+	for name, setup := range map[string]func(*testing.T){
+		"with tryCall": installTryCall,
+		"fallback":     func(*testing.T) {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			setup(t)
+			v, err := TryCall(Global.Get("JSON"), "stringify", map[string]any{"a": 1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != `{"a":1}` {
+				t.Errorf("result = %s", v.String())
+			}
+			_, err = TryCall(Global.Get("JSON"), "parse", "{")
+			if err == nil || !strings.HasPrefix(err.Error(), "JavaScript error: SyntaxError") {
+				t.Errorf("err = %v, want SyntaxError", err)
+			}
+		})
+	}
+}
+
+func TestTryNew(t *testing.T) {
+	for name, setup := range map[string]func(*testing.T){
+		"with tryCall": installTryCall,
+		"fallback":     func(*testing.T) {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			setup(t)
+			v, err := TryNew(Uint8ArrayClass(), 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.Length() != 3 {
+				t.Errorf("length = %d, want 3", v.Length())
+			}
+			if _, err := TryNew(Global.Get("URL"), "invalid"); err == nil {
+				t.Error("new URL(invalid): want error")
+			}
+			if v, err := TryNew(Global.Get("Date")); err != nil || v.Type() != js.TypeObject {
+				t.Errorf("new Date() = %v, %v", v, err)
+			}
+		})
+	}
+}
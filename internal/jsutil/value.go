@@ -0,0 +1,218 @@
+package jsutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+var (
+	ArrayBufferClass = LazyGlobal("ArrayBuffer")
+	MapClass         = LazyGlobal("Map")
+)
+
+// ToJSValue converts Go value to JavaScript value which can be passed through structured clone.
+// The conversion rules are:
+//   - nil -> null
+//   - js.Value -> the value itself
+//   - bool, string, numbers -> Boolean, String, Number
+//   - []byte -> Uint8Array
+//   - time.Time and *time.Time -> Date
+//   - slices and arrays -> Array
+//   - maps with string keys -> Object
+//   - structs -> Object which has exported fields. field names can be changed by `json` tag.
+//     Fields of embedded structs are promoted, and `omitempty` omits empty values in the same manner as encoding/json.
+//   - values implementing json.Marshaler (e.g. json.RawMessage) -> the value parsed from their JSON
+//   - pointers -> the value pointed by the pointer (nil pointer is converted to null)
+func ToJSValue(v any) (js.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return Null, nil
+	case js.Value:
+		return v, nil
+	case js.Func:
+		return v.Value, nil
+	case bool, string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return js.ValueOf(v), nil
+	case []byte:
+		ua := NewUint8Array(len(v))
+		js.CopyBytesToJS(ua, v)
+		return ua, nil
+	case time.Time:
+		return TimeToDate(v), nil
+	case *time.Time:
+		// *time.Time implements json.Marshaler, so it must be handled before jsonMarshaler.
+		if v == nil {
+			return Null, nil
+		}
+		return TimeToDate(*v), nil
+	case jsonMarshaler:
+		return marshalerToJSValue(v)
+	case map[string]any:
+		obj := NewObject()
+		for k, e := range v {
+			ev, err := ToJSValue(e)
+			if err != nil {
+				return js.Value{}, fmt.Errorf("error converting value of %q: %w", k, err)
+			}
+			obj.Set(k, ev)
+		}
+		return obj, nil
+	case []any:
+		arr := ArrayClass().New(len(v))
+		for i, e := range v {
+			ev, err := ToJSValue(e)
+			if err != nil {
+				return js.Value{}, fmt.Errorf("error converting index %d: %w", i, err)
+			}
+			arr.SetIndex(i, ev)
+		}
+		return arr, nil
+	}
+	return reflectToJSValue(reflect.ValueOf(v))
+}
+
+// reflectToJSValue converts Go value which is not handled by ToJSValue's type switch.
+func reflectToJSValue(rv reflect.Value) (js.Value, error) {
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return Null, nil
+		}
+		return ToJSValue(rv.Elem().Interface())
+	case reflect.Bool:
+		return js.ValueOf(rv.Bool()), nil
+	case reflect.String:
+		return js.ValueOf(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return js.ValueOf(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return js.ValueOf(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return js.ValueOf(rv.Float()), nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return Null, nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return ToJSValue(rv.Bytes())
+		}
+		fallthrough
+	case reflect.Array:
+		arr := ArrayClass().New(rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			ev, err := ToJSValue(rv.Index(i).Interface())
+			if err != nil {
+				return js.Value{}, fmt.Errorf("error converting index %d: %w", i, err)
+			}
+			arr.SetIndex(i, ev)
+		}
+		return arr, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return js.Value{}, fmt.Errorf("unsupported map key type: %s", rv.Type().Key())
+		}
+		if rv.IsNil() {
+			return Null, nil
+		}
+		obj := NewObject()
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			ev, err := ToJSValue(iter.Value().Interface())
+			if err != nil {
+				return js.Value{}, fmt.Errorf("error converting value of %q: %w", k, err)
+			}
+			obj.Set(k, ev)
+		}
+		return obj, nil
+	case reflect.Struct:
+		obj := NewObject()
+		for _, f := range structFields(rv.Type()) {
+			fv, ok := fieldByIndex(rv, f.index, false)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			ev, err := ToJSValue(fv.Interface())
+			if err != nil {
+				return js.Value{}, fmt.Errorf("error converting field %s: %w", f.name, err)
+			}
+			obj.Set(f.name, ev)
+		}
+		return obj, nil
+	}
+	return js.Value{}, fmt.Errorf("unsupported type: %s", rv.Type())
+}
+
+// fieldName returns the property name of struct field based on `json` tag.
+func fieldName(f reflect.StructField) (name string, omitEmpty bool, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	return name, strings.Contains(opts, "omitempty"), false
+}
+
+// ToGoValue converts JavaScript value to Go value.
+// The conversion rules are:
+//   - null, undefined -> nil
+//   - Boolean, String, Number -> bool, string, float64
+//   - Uint8Array, ArrayBuffer and other ArrayBufferViews -> []byte
+//   - Date -> time.Time
+//   - Array -> []any
+//   - Map -> map[string]any (keys are converted to string)
+//   - other objects -> map[string]any which has own enumerable properties
+func ToGoValue(v js.Value) any {
+	switch v.Type() {
+	case js.TypeUndefined, js.TypeNull:
+		return nil
+	case js.TypeBoolean:
+		return v.Bool()
+	case js.TypeString:
+		return v.String()
+	case js.TypeNumber:
+		return v.Float()
+	case js.TypeObject:
+		switch {
+		case v.InstanceOf(Uint8ArrayClass()):
+			b := make([]byte, v.Get("byteLength").Int())
+			js.CopyBytesToGo(b, v)
+			return b
+		case v.InstanceOf(ArrayBufferClass()):
+			return ToGoValue(Uint8ArrayClass().New(v))
+		case ArrayBufferClass().Call("isView", v).Bool():
+			return ToGoValue(Uint8ArrayClass().New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength")))
+		case v.InstanceOf(DateClass()):
+			t, _ := DateToTime(v)
+			return t
+		case ArrayClass().Call("isArray", v).Bool():
+			result := make([]any, v.Length())
+			for i := range result {
+				result[i] = ToGoValue(v.Index(i))
+			}
+			return result
+		case v.InstanceOf(MapClass()):
+			entries := ArrayFrom(v.Call("entries"))
+			result := make(map[string]any, entries.Length())
+			for i := 0; i < entries.Length(); i++ {
+				entry := entries.Index(i)
+				result[entry.Index(0).Call("toString").String()] = ToGoValue(entry.Index(1))
+			}
+			return result
+		}
+		entries := ObjectClass().Call("entries", v)
+		result := make(map[string]any, entries.Length())
+		for i := 0; i < entries.Length(); i++ {
+			entry := entries.Index(i)
+			result[entry.Index(0).String()] = ToGoValue(entry.Index(1))
+		}
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package jsutil
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToJSValue_ToGoValue(t *testing.T) {
+	type nested struct {
+		Name    string `json:"name"`
+		Count   int
+		Skipped string `json:"-"`
+		Empty   string `json:"empty,omitempty"`
+		private string
+	}
+	now := time.UnixMilli(time.Now().UnixMilli())
+	tests := map[string]struct {
+		v    any
+		want any
+	}{
+		"nil": {
+			v:    nil,
+			want: nil,
+		},
+		"bool": {
+			v:    true,
+			want: true,
+		},
+		"string": {
+			v:    "hello",
+			want: "hello",
+		},
+		"int": {
+			v:    42,
+			want: float64(42),
+		},
+		"float": {
+			v:    1.5,
+			want: 1.5,
+		},
+		"bytes": {
+			v:    []byte("abc"),
+			want: []byte("abc"),
+		},
+		"time": {
+			v:    now,
+			want: now,
+		},
+		"time pointer": {
+			v:    &now,
+			want: now,
+		},
+		"nil time pointer": {
+			v:    (*time.Time)(nil),
+			want: nil,
+		},
+		"slice": {
+			v:    []string{"a", "b"},
+			want: []any{"a", "b"},
+		},
+		"map": {
+			v:    map[string]any{"a": 1, "b": []any{"c", nil}},
+			want: map[string]any{"a": float64(1), "b": []any{"c", nil}},
+		},
+		"struct": {
+			v:    &nested{Name: "n", Count: 1, Skipped: "s", private: "p"},
+			want: map[string]any{"name": "n", "Count": float64(1)},
+		},
+		"nil pointer": {
+			v:    (*nested)(nil),
+			want: nil,
+		},
+	}
+	for name, tc := range tests {
+		name := name
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			jsV, err := ToJSValue(tc.v)
+			if err != nil {
+				t.Fatalf("ToJSValue() error = %v", err)
+			}
+			got := ToGoValue(jsV)
+			if gotTime, ok := got.(time.Time); ok {
+				if !gotTime.Equal(tc.want.(time.Time)) {
+					t.Errorf("ToGoValue() = %v, want %v", got, tc.want)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("ToGoValue() = %#v, want %#v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestToJSValue_unsupported(t *testing.T) {
+	if _, err := ToJSValue(map[int]string{1: "a"}); err == nil {
+		t.Error("ToJSValue() error = nil, want error")
+	}
+	if _, err := ToJSValue(make(chan int)); err == nil {
+		t.Error("ToJSValue() error = nil, want error")
+	}
+}
@@ -4,16 +4,28 @@ import (
 	"context"
 	"errors"
 	"syscall/js"
+
+	"github.com/syumai/workers/internal/jsutil"
 )
 
 type runtimeCtxKey struct{}
 
+// New returns the context which holds runtime context object.
+//   - The context also holds a new jsutil.AwaitTracker, so waiting time of the event is tracked separately from other events.
 func New(ctx context.Context, runtimeCtxObj js.Value) context.Context {
+	ctx = jsutil.WithAwaitTracker(ctx)
 	return context.WithValue(ctx, runtimeCtxKey{}, runtimeCtxObj)
 }
 
 var ErrRuntimeContextNotFound = errors.New("runtime context was not found")
 
+// Extract extracts runtime context object from context.
+//   - ok is false when runtime context object was not found.
+func Extract(ctx context.Context) (v js.Value, ok bool) {
+	v, ok = ctx.Value(runtimeCtxKey{}).(js.Value)
+	return v, ok
+}
+
 // MustExtract extracts runtime context object from context.
 // This function panics when runtime context object was not found.
 func MustExtract(ctx context.Context) js.Value {
@@ -23,3 +35,18 @@ func MustExtract(ctx context.Context) js.Value {
 	}
 	return v
 }
+
+type requestObjKey struct{}
+
+// WithRequest returns the context which holds JavaScript side Request of the fetch event.
+//   - The Request is kept instead of its properties, so properties (e.g. `cf`) are converted only when they are used.
+func WithRequest(ctx context.Context, reqObj js.Value) context.Context {
+	return context.WithValue(ctx, requestObjKey{}, reqObj)
+}
+
+// ExtractRequest extracts JavaScript side Request of the fetch event from context.
+//   - ok is false when the context is not of the fetch event.
+func ExtractRequest(ctx context.Context) (v js.Value, ok bool) {
+	v, ok = ctx.Value(requestObjKey{}).(js.Value)
+	return v, ok
+}
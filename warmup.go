@@ -0,0 +1,12 @@
+package workers
+
+import (
+	"github.com/syumai/workers/internal/jsutil"
+)
+
+// Warmup looks up JavaScript classes and globals used by this module, and sets the callbacks of events at once.
+//   - These are looked up lazily on the first use and the callbacks are set when their handlers are registered by default, so simple requests don't pay for unused ones on cold starts.
+//   - Warmup is optional. Call it at the start of main function to move the cost out of the handling of the first request.
+func Warmup() {
+	jsutil.Warmup()
+}